@@ -5,8 +5,204 @@ DELIMITER $$
 DROP FUNCTION IF EXISTS _pb_util_snake_to_lower_camel $$
 CREATE FUNCTION _pb_util_snake_to_lower_camel(s TEXT) RETURNS TEXT DETERMINISTIC
 BEGIN
-	-- TODO: implement
-	RETURN s;
+	DECLARE result TEXT DEFAULT '';
+	DECLARE i INT DEFAULT 1;
+	DECLARE c TEXT;
+	DECLARE capitalize_next BOOLEAN DEFAULT FALSE;
+
+	IF s IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	-- Same as ToJsonName() in protoc: drop underscores and capitalize the following letter
+	WHILE i <= CHAR_LENGTH(s) DO
+		SET c = SUBSTRING(s, i, 1);
+		IF c = '_' THEN
+			SET capitalize_next = TRUE;
+		ELSEIF capitalize_next THEN
+			SET result = CONCAT(result, UPPER(c));
+			SET capitalize_next = FALSE;
+		ELSE
+			SET result = CONCAT(result, c);
+		END IF;
+		SET i = i + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper procedure to get a string field as JSON with the given UTF-8 validation mode (see _pb_util_check_utf8).
+-- JSON text cannot hold ill-formed UTF-8, so 'passthrough' replaces ill-formed sequences in the same way as 'replace'.
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_string_field_as_json $$
+CREATE PROCEDURE _pb_wire_json_get_string_field_as_json(IN wire_json JSON, IN field_number INT, IN is_repeated BOOLEAN, IN has_field_presence BOOLEAN, IN utf8_validation TEXT, OUT field_json_value JSON)
+BEGIN
+	DECLARE elements JSON;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE element_count INT;
+
+	IF utf8_validation = 'passthrough' THEN
+		SET utf8_validation = 'replace';
+	END IF;
+
+	IF is_repeated THEN
+		SET elements = pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json, field_number);
+		SET element_count = JSON_LENGTH(elements);
+		SET field_json_value = JSON_ARRAY();
+		WHILE element_index < element_count DO
+			SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', CONVERT(_pb_util_check_utf8(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))), utf8_validation) USING utf8mb4));
+			SET element_index = element_index + 1;
+		END WHILE;
+	ELSE
+		SET field_json_value = JSON_QUOTE(CONVERT(_pb_util_check_utf8(pb_wire_json_get_bytes_field(wire_json, field_number, IF(has_field_presence, NULL, _binary X'')), utf8_validation) USING utf8mb4));
+	END IF;
+END $$
+
+-- Helper function to format the bit pattern of a finite float in the same way as protojson: the shortest decimal that
+-- rounds back to the same float, using exponent notation below 1e-6 and from 1e21. Unlike widening the float to a
+-- DOUBLE, this does not print digits that are only significant for doubles (e.g. 0.10000000149011612 for 0.1).
+DROP FUNCTION IF EXISTS _pb_util_format_shortest_float $$
+CREATE FUNCTION _pb_util_format_shortest_float(bits INT UNSIGNED) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE sign TEXT;
+	DECLARE biased_exponent INT;
+	DECLARE fraction INT;
+	DECLARE significand INT;
+	DECLARE binary_exponent INT;
+	DECLARE magnitude DOUBLE;
+	DECLARE lower_bound DOUBLE;
+	DECLARE upper_bound DOUBLE;
+	DECLARE is_even BOOLEAN;
+	DECLARE digit_count INT DEFAULT 1;
+	DECLARE first_digit_exponent INT;
+	DECLARE mantissa BIGINT;
+	DECLARE exponent INT; -- decimal exponent of the last digit of mantissa
+	DECLARE candidate DOUBLE;
+	DECLARE attempt INT;
+	DECLARE digits TEXT;
+
+	SET sign = IF(bits >> 31 = 0, '', '-');
+	SET biased_exponent = (bits >> 23) & 0xFF;
+	SET fraction = bits & 0x7FFFFF;
+
+	IF biased_exponent = 0 THEN -- zero or subnormal number
+		SET significand = fraction;
+		SET binary_exponent = -149;
+	ELSE -- normal number
+		SET significand = fraction | 0x800000;
+		SET binary_exponent = biased_exponent - 150;
+	END IF;
+
+	IF significand = 0 THEN
+		RETURN CONCAT(sign, '0');
+	END IF;
+
+	-- All of these are exact in DOUBLE. Any decimal between the bounds rounds to this float, and so do the bounds
+	-- themselves if the significand is even (round half to even). The gap below a power of two is half as wide.
+	SET magnitude = significand * POW(2, binary_exponent);
+	SET upper_bound = (significand * 2 + 1) * POW(2, binary_exponent - 1);
+	IF fraction = 0 AND biased_exponent > 1 THEN
+		SET lower_bound = (significand * 4 - 1) * POW(2, binary_exponent - 2);
+	ELSE
+		SET lower_bound = (significand * 2 - 1) * POW(2, binary_exponent - 1);
+	END IF;
+	SET is_even = significand % 2 = 0;
+
+	-- Try the nearest decimal with 1, 2, ... significant digits; 9 digits always round-trip.
+	SET first_digit_exponent = FLOOR(LOG10(magnitude));
+	WHILE digits IS NULL DO
+		SET exponent = first_digit_exponent - digit_count + 1;
+		SET mantissa = ROUND(IF(exponent < 0, magnitude * POW(10, -exponent), magnitude / POW(10, exponent)));
+		SET attempt = 0;
+		WHILE digits IS NULL AND attempt < 2 DO
+			SET candidate = CAST(CONCAT(mantissa, 'e', exponent) AS DOUBLE);
+			IF (candidate > lower_bound AND candidate < upper_bound)
+				OR (is_even AND (candidate = lower_bound OR candidate = upper_bound))
+				OR digit_count >= 17 THEN
+				SET digits = CAST(mantissa AS CHAR);
+			ELSE
+				-- Near a power of two the bounds are asymmetric, so the neighbor on the other side may still round-trip
+				SET mantissa = mantissa + IF(candidate < magnitude, 1, -1);
+			END IF;
+			SET attempt = attempt + 1;
+		END WHILE;
+		SET digit_count = digit_count + 1;
+	END WHILE;
+
+	-- Drop trailing zeros
+	WHILE RIGHT(digits, 1) = '0' DO
+		SET digits = LEFT(digits, CHAR_LENGTH(digits) - 1);
+		SET exponent = exponent + 1;
+	END WHILE;
+	SET digit_count = CHAR_LENGTH(digits);
+	SET first_digit_exponent = exponent + digit_count - 1;
+
+	-- float32(1e-6) and float32(1e21)
+	IF magnitude < 9.999999974752427e-07 OR magnitude >= 1.0000000200408773e+21 THEN
+		RETURN CONCAT(sign, LEFT(digits, 1), IF(digit_count > 1, CONCAT('.', SUBSTRING(digits, 2)), ''),
+			'e', IF(first_digit_exponent < 0, CONCAT('-', -first_digit_exponent), CONCAT('+', LPAD(first_digit_exponent, 2, '0'))));
+	ELSEIF exponent >= 0 THEN
+		RETURN CONCAT(sign, digits, REPEAT('0', exponent));
+	ELSEIF first_digit_exponent >= 0 THEN
+		RETURN CONCAT(sign, LEFT(digits, first_digit_exponent + 1), '.', SUBSTRING(digits, first_digit_exponent + 2));
+	ELSE
+		RETURN CONCAT(sign, '0.', REPEAT('0', -first_digit_exponent - 1), digits);
+	END IF;
+END $$
+
+-- Helper function to convert the bit pattern of a float (bit_size = 32) or double (bit_size = 64) to JSON in the same
+-- way as protojson. NaN and infinities become the strings "NaN", "Infinity" and "-Infinity".
+DROP FUNCTION IF EXISTS _pb_util_floating_point_bits_to_json $$
+CREATE FUNCTION _pb_util_floating_point_bits_to_json(bits BIGINT UNSIGNED, bit_size INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE double_value DOUBLE;
+
+	IF bits IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	IF bit_size = 32 THEN
+		IF ((bits >> 23) & 0xFF) = 0xFF THEN
+			RETURN JSON_QUOTE(IF((bits & 0x7FFFFF) <> 0, 'NaN', IF((bits >> 31) = 0, 'Infinity', '-Infinity')));
+		ELSEIF bits = 0x80000000 THEN
+			RETURN CAST(_pb_util_reinterpret_uint32_as_float(bits) AS JSON); -- keeps the sign of -0
+		END IF;
+		RETURN CAST(_pb_util_format_shortest_float(bits) AS JSON);
+	END IF;
+
+	IF ((bits >> 52) & 0x7FF) = 0x7FF THEN
+		RETURN JSON_QUOTE(IF((bits & 0xFFFFFFFFFFFFF) <> 0, 'NaN', IF((bits >> 63) = 0, 'Infinity', '-Infinity')));
+	END IF;
+
+	-- MySQL already prints a DOUBLE in JSON as the shortest decimal that round-trips, but adds '.0' to integral values
+	-- (e.g. 3.0 where protojson writes 3). Integral values that fit in a BIGINT (UNSIGNED) are written as integers instead.
+	-- Negative zero stays a DOUBLE to keep its sign.
+	SET double_value = _pb_util_reinterpret_uint64_as_double(bits);
+	IF bits <> 0x8000000000000000 AND double_value = FLOOR(double_value) THEN
+		IF ABS(double_value) < 9223372036854775808 THEN
+			RETURN CAST(CAST(double_value AS SIGNED) AS JSON);
+		ELSEIF double_value > 0 AND double_value < 18446744073709551616 THEN
+			RETURN CAST(CAST(double_value AS UNSIGNED) AS JSON);
+		END IF;
+	END IF;
+	RETURN CAST(double_value AS JSON);
+END $$
+
+-- Helper function to convert a JSON array of float (bit_size = 32) or double (bit_size = 64) bit patterns with _pb_util_floating_point_bits_to_json
+DROP FUNCTION IF EXISTS _pb_util_floating_point_bits_array_to_json $$
+CREATE FUNCTION _pb_util_floating_point_bits_array_to_json(bits_array JSON, bit_size INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE element_count INT;
+
+	SET result = JSON_ARRAY();
+	SET element_count = JSON_LENGTH(bits_array);
+	WHILE element_index < element_count DO
+		SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_floating_point_bits_to_json(CAST(JSON_UNQUOTE(JSON_EXTRACT(bits_array, CONCAT('$[', element_index, ']'))) AS UNSIGNED), bit_size));
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN result;
 END $$
 
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_primitive_field_as_json $$
@@ -18,15 +214,15 @@ BEGIN
 	CASE field_type
 	WHEN 1 THEN -- double
 		IF is_repeated THEN
-			SET field_json_value = pb_wire_json_get_repeated_double_field_as_json_array(wire_json, field_number);
+			SET field_json_value = _pb_util_floating_point_bits_array_to_json(pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number), 64);
 		ELSE
-			SET field_json_value = CAST(pb_wire_json_get_double_field(wire_json, field_number, IF(has_field_presence, NULL, 0)) AS JSON);
+			SET field_json_value = _pb_util_floating_point_bits_to_json(pb_wire_json_get_fixed64_field(wire_json, field_number, IF(has_field_presence, NULL, 0)), 64);
 		END IF;
 	WHEN 2 THEN -- float
 		IF is_repeated THEN
-			SET field_json_value = pb_wire_json_get_repeated_float_field_as_json_array(wire_json, field_number);
+			SET field_json_value = _pb_util_floating_point_bits_array_to_json(pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number), 32);
 		ELSE
-			SET field_json_value = CAST(pb_wire_json_get_float_field(wire_json, field_number, IF(has_field_presence, NULL, 0)) AS JSON);
+			SET field_json_value = _pb_util_floating_point_bits_to_json(pb_wire_json_get_fixed32_field(wire_json, field_number, IF(has_field_presence, NULL, 0)), 32);
 		END IF;
 	WHEN 3 THEN -- int64
 		IF is_repeated THEN
@@ -95,11 +291,7 @@ BEGIN
 			END IF;
 		END IF;
 	WHEN 9 THEN -- string
-		IF is_repeated THEN
-			SET field_json_value = pb_wire_json_get_repeated_string_field_as_json_array(wire_json, field_number);
-		ELSE
-			SET field_json_value = JSON_QUOTE(pb_wire_json_get_string_field(wire_json, field_number, IF(has_field_presence, NULL, '')));
-		END IF;
+		CALL _pb_wire_json_get_string_field_as_json(wire_json, field_number, is_repeated, has_field_presence, 'strict', field_json_value);
 	WHEN 12 THEN -- bytes
 		IF is_repeated THEN
 			SET field_json_value = pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json, field_number);
@@ -303,7 +495,7 @@ BEGIN
 	DECLARE wire_type INT;
 	DECLARE field_number INT;
 	DECLARE result JSON;
-	DECLARE uint_value BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 
 	SET result = JSON_OBJECT();
@@ -329,7 +521,7 @@ BEGIN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
 			CASE field_number
 			WHEN 3 THEN -- string_value
-				SET result = JSON_QUOTE(CONVERT(bytes_value USING utf8mb4));
+				SET result = JSON_QUOTE(CONVERT(_pb_util_check_utf8(bytes_value, 'strict') USING utf8mb4));
 			WHEN 5 THEN -- struct_value
 				SET result = _pb_wire_json_decode_wkt_struct_as_json(pb_message_to_wire_json(bytes_value));
 			WHEN 6 THEN -- list_value
@@ -339,7 +531,11 @@ BEGIN
 			SET uint_value = CAST(JSON_EXTRACT(element, '$.v') AS UNSIGNED);
 			CASE field_number
 			WHEN 2 THEN -- double_value
-				SET result = CAST(_pb_util_reinterpret_uint64_as_double(uint_value) AS JSON);
+				-- Same as protojson, which has no JSON representation for a Value of NaN or infinity
+				IF ((uint_value >> 52) & 0x7FF) = 0x7FF THEN
+					CALL _pb_signal_error('PB_NON_FINITE_VALUE', '_pb_wire_json_decode_wkt_value_as_json', 'number_value must be finite');
+				END IF;
+				SET result = _pb_util_floating_point_bits_to_json(uint_value, 64);
 			END CASE;
 		END CASE;
 
@@ -427,7 +623,7 @@ BEGIN
 
 		CASE wire_type
 		WHEN 2 THEN -- LEN
-			SET string_value = CONVERT(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v'))) USING utf8mb4);
+			SET string_value = CONVERT(_pb_util_check_utf8(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v'))), 'strict') USING utf8mb4);
 			CASE field_number
 			WHEN 1 THEN -- values
 				SET result = CONCAT(result, sep, string_value);
@@ -458,9 +654,9 @@ BEGIN
 	WHEN '.google.protobuf.Empty' THEN
 		RETURN JSON_OBJECT();
 	WHEN '.google.protobuf.DoubleValue' THEN
-		RETURN CAST(pb_wire_json_get_double_field(wire_json, 1, 0.0) AS JSON);
+		RETURN _pb_util_floating_point_bits_to_json(pb_wire_json_get_fixed64_field(wire_json, 1, 0), 64);
 	WHEN '.google.protobuf.FloatValue' THEN
-		RETURN CAST(pb_wire_json_get_float_field(wire_json, 1, 0.0) AS JSON);
+		RETURN _pb_util_floating_point_bits_to_json(pb_wire_json_get_fixed32_field(wire_json, 1, 0), 32);
 	WHEN '.google.protobuf.Int64Value' THEN
 		IF as_number_json THEN
 			RETURN CAST(pb_wire_json_get_int64_field(wire_json, 1, 0) AS JSON);
@@ -492,188 +688,295 @@ END $$
 
 DELIMITER $$
 
+-- Type index entries
+--
+-- The type index at $[2] of the descriptor set JSON maps each type name to [kind, file path, type path, syntax], where
+-- syntax is the syntax of the file defining the type. Entries of enums (kind 14) also carry the values of the enum as
+-- {"n": {number: name}, "v": {name: number}}, so converting many rows with the same schema finds them without walking the
+-- enum descriptor again. Both are precomputed by pb_build_descriptor_set_json and protoc-gen-descriptor_set_json, and they
+-- are computed here instead when a descriptor set JSON built without them is given.
+
 -- Helper function to get message descriptor from descriptor set JSON
 DROP FUNCTION IF EXISTS _pb_get_message_descriptor $$
 CREATE FUNCTION _pb_get_message_descriptor(descriptor_set_json JSON, type_name TEXT) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE type_index JSON;
 	DECLARE type_paths JSON;
-	DECLARE kind INT;
-	DECLARE file_path TEXT;
-	DECLARE type_path TEXT;
-	
-	-- Get type index (element 2)
-	SET type_index = JSON_EXTRACT(descriptor_set_json, '$[2]');
-	
-	-- Get paths for the type
-	SET type_paths = JSON_EXTRACT(type_index, CONCAT('$."', type_name, '"'));
-	
-	IF type_paths IS NULL THEN
-		RETURN NULL;
-	END IF;
-	
-	-- Extract kind, file path and type path
-	SET kind = JSON_EXTRACT(type_paths, '$[0]');
-	SET file_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[1]'));
-	SET type_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]'));
-	
+
+	-- Get [kind, file path, type path, ...] from the type index (element 2)
+	SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name)));
+
 	-- Verify this is a message type (kind = 11)
-	IF kind <> 11 THEN
+	IF type_paths IS NULL OR JSON_EXTRACT(type_paths, '$[0]') <> 11 THEN
 		RETURN NULL;
 	END IF;
-	
-	-- Return the message descriptor
-	RETURN JSON_EXTRACT(descriptor_set_json, type_path);
+
+	RETURN JSON_EXTRACT(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]')));
 END $$
 
 -- Helper function to get enum descriptor from descriptor set JSON
 DROP FUNCTION IF EXISTS _pb_get_enum_descriptor $$
 CREATE FUNCTION _pb_get_enum_descriptor(descriptor_set_json JSON, type_name TEXT) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE type_index JSON;
 	DECLARE type_paths JSON;
-	DECLARE kind INT;
-	DECLARE file_path TEXT;
-	DECLARE type_path TEXT;
-	
-	-- Get type index (element 2)
-	SET type_index = JSON_EXTRACT(descriptor_set_json, '$[2]');
-	
-	-- Get paths for the type
-	SET type_paths = JSON_EXTRACT(type_index, CONCAT('$."', type_name, '"'));
-	
-	IF type_paths IS NULL THEN
-		RETURN NULL;
-	END IF;
-	
-	-- Extract kind, file path and type path
-	SET kind = JSON_EXTRACT(type_paths, '$[0]');
-	SET file_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[1]'));
-	SET type_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]'));
-	
+
+	-- Get [kind, file path, type path, ...] from the type index (element 2)
+	SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name)));
+
 	-- Verify this is an enum type (kind = 14)
-	IF kind <> 14 THEN
+	IF type_paths IS NULL OR JSON_EXTRACT(type_paths, '$[0]') <> 14 THEN
 		RETURN NULL;
 	END IF;
-	
-	-- Return the enum descriptor
-	RETURN JSON_EXTRACT(descriptor_set_json, type_path);
+
+	RETURN JSON_EXTRACT(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]')));
 END $$
 
--- Helper procedure to convert enum value to JSON using descriptor set
-DROP PROCEDURE IF EXISTS _pb_enum_to_json $$
-CREATE PROCEDURE _pb_enum_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN enum_value_number INT, OUT result JSON)
-proc: BEGIN
-	DECLARE enum_descriptor JSON;
+-- Helper function to build {"n": {number: name}, "v": {name: number}} from the values of an EnumDescriptorProto.
+-- When several names share a number (allow_alias), the first one is used for the number, as in protojson.
+DROP FUNCTION IF EXISTS _pb_build_enum_map $$
+CREATE FUNCTION _pb_build_enum_map(enum_descriptor JSON) RETURNS JSON DETERMINISTIC
+BEGIN
 	DECLARE enum_values JSON;
-	DECLARE enum_value JSON;
 	DECLARE enum_count INT;
-	DECLARE enum_index INT;
-	DECLARE current_number INT;
-	DECLARE current_name TEXT;
-	
-	SET enum_descriptor = _pb_get_enum_descriptor(descriptor_set_json, full_type_name);
-	
-	IF enum_descriptor IS NULL THEN
-		SET result = NULL;
-		LEAVE proc;
-	END IF;
-	
+	DECLARE enum_index INT DEFAULT 0;
+	DECLARE enum_name TEXT;
+	DECLARE enum_number INT;
+	DECLARE names JSON;
+	DECLARE numbers JSON;
+
+	SET names = JSON_OBJECT();
+	SET numbers = JSON_OBJECT();
+
 	-- Get enum values array (field 2 in EnumDescriptorProto)
 	SET enum_values = JSON_EXTRACT(enum_descriptor, '$."2"');
-	
-	IF enum_values IS NULL THEN
-		SET result = NULL;
-		LEAVE proc;
-	END IF;
-	
-	SET enum_count = JSON_LENGTH(enum_values);
-	SET enum_index = 0;
-	
-	-- Find enum value by number
+	SET enum_count = COALESCE(JSON_LENGTH(enum_values), 0);
 	WHILE enum_index < enum_count DO
-		SET enum_value = JSON_EXTRACT(enum_values, CONCAT('$[', enum_index, ']'));
-		SET current_number = JSON_EXTRACT(enum_value, '$."2"'); -- number field
-		
-		IF current_number = enum_value_number THEN
-			SET current_name = JSON_UNQUOTE(JSON_EXTRACT(enum_value, '$."1"')); -- name field
-			SET result = JSON_QUOTE(current_name);
-			LEAVE proc;
-		END IF;
-		
+		SET enum_name = JSON_UNQUOTE(JSON_EXTRACT(enum_values, CONCAT('$[', enum_index, ']."1"'))); -- name
+		SET enum_number = JSON_EXTRACT(enum_values, CONCAT('$[', enum_index, ']."2"')); -- number
+		SET names = JSON_INSERT(names, CONCAT('$."', enum_number, '"'), enum_name);
+		SET numbers = JSON_INSERT(numbers, CONCAT('$.', JSON_QUOTE(enum_name)), enum_number);
 		SET enum_index = enum_index + 1;
 	END WHILE;
-	
-	-- If not found, return null
-	IF result IS NULL THEN
-		SET result = NULL;
+
+	RETURN JSON_OBJECT('n', names, 'v', numbers);
+END $$
+
+-- Helper function to get the values of an enum as {"n": {number: name}, "v": {name: number}}
+DROP FUNCTION IF EXISTS _pb_get_enum_map $$
+CREATE FUNCTION _pb_get_enum_map(descriptor_set_json JSON, type_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE type_paths JSON;
+	DECLARE enum_map JSON;
+
+	-- Get [kind, file path, type path, syntax, enum map] from the type index (element 2)
+	SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name)));
+
+	-- Verify this is an enum type (kind = 14)
+	IF type_paths IS NULL OR JSON_EXTRACT(type_paths, '$[0]') <> 14 THEN
+		RETURN NULL;
+	END IF;
+
+	SET enum_map = JSON_EXTRACT(type_paths, '$[4]');
+	IF enum_map IS NULL THEN
+		SET enum_map = _pb_build_enum_map(JSON_EXTRACT(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]'))));
 	END IF;
+	RETURN enum_map;
+END $$
+
+-- Helper procedure to convert enum value to JSON using descriptor set
+DROP PROCEDURE IF EXISTS _pb_enum_to_json $$
+CREATE PROCEDURE _pb_enum_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN enum_value_number INT, OUT result JSON)
+BEGIN
+	-- Unknown enum types and values are returned as NULL
+	SET result = JSON_EXTRACT(_pb_get_enum_map(descriptor_set_json, full_type_name), CONCAT('$.n."', enum_value_number, '"'));
 END $$
 
 -- Helper function to get file descriptor for a type
 DROP FUNCTION IF EXISTS _pb_get_file_descriptor $$
 CREATE FUNCTION _pb_get_file_descriptor(descriptor_set_json JSON, type_name TEXT) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE type_index JSON;
-	DECLARE type_paths JSON;
 	DECLARE file_path TEXT;
-	
-	-- Get type index (element 2)
-	SET type_index = JSON_EXTRACT(descriptor_set_json, '$[2]');
-	
-	-- Get paths for the type
-	SET type_paths = JSON_EXTRACT(type_index, CONCAT('$."', type_name, '"'));
-	
-	IF type_paths IS NULL THEN
+
+	-- Get the file path from [kind, file path, type path, ...] in the type index (element 2)
+	SET file_path = JSON_UNQUOTE(JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name), '[1]')));
+
+	IF file_path IS NULL THEN
 		RETURN NULL;
 	END IF;
-	
-	-- Extract file path (now at index 1)
-	SET file_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[1]'));
-	
+
 	-- Return the file descriptor
 	RETURN JSON_EXTRACT(descriptor_set_json, file_path);
 END $$
 
--- Main procedure for converting protobuf message to JSON using descriptor set
-DROP PROCEDURE IF EXISTS _pb_message_to_json $$
-CREATE PROCEDURE _pb_message_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN as_number_json BOOLEAN, OUT result JSON)
-proc: BEGIN
-	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
-	
-	DECLARE message_text TEXT;
-	DECLARE message_descriptor JSON;
-	DECLARE file_descriptor JSON;
+-- Helper function to get the syntax ('proto2', 'proto3' or 'editions') of the file defining a type
+DROP FUNCTION IF EXISTS _pb_get_file_syntax $$
+CREATE FUNCTION _pb_get_file_syntax(descriptor_set_json JSON, type_name TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE type_paths JSON;
 	DECLARE syntax TEXT;
-	DECLARE wire_json JSON;
+
+	-- Get [kind, file path, type path, syntax, ...] from the type index (element 2)
+	SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name)));
+
+	SET syntax = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[3]'));
+	IF syntax IS NULL THEN
+		-- Only the file path is needed, so the file descriptor is not extracted as a whole
+		SET syntax = JSON_UNQUOTE(JSON_EXTRACT(descriptor_set_json, CONCAT(JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[1]')), '."12"'))); -- syntax field
+	END IF;
+	IF syntax IS NULL THEN
+		SET syntax = 'proto2'; -- default
+	END IF;
+
+	RETURN syntax;
+END $$
+
+-- Helper function to get the default UTF-8 validation mode of the string fields in a message type: 'strict' for proto3 and
+-- 'passthrough' for proto2. Editions use the utf8_validation feature of the innermost of the message, its enclosing
+-- messages and the file that sets it (VERIFY by default). Features set on a field override this (see _pb_get_field_utf8_validation).
+DROP FUNCTION IF EXISTS _pb_get_utf8_validation $$
+CREATE FUNCTION _pb_get_utf8_validation(descriptor_set_json JSON, type_name TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE utf8_validation_feature INT;
+	DECLARE scope_name TEXT;
+	DECLARE type_paths JSON;
+
+	CASE _pb_get_file_syntax(descriptor_set_json, type_name)
+	WHEN 'proto3' THEN
+		RETURN 'strict';
+	WHEN 'editions' THEN
+		-- Walk out from the message through its enclosing messages; the first one that sets the feature wins
+		SET scope_name = type_name;
+		SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(scope_name)));
+		WHILE utf8_validation_feature IS NULL AND JSON_EXTRACT(type_paths, '$[0]') = 11 DO
+			SET utf8_validation_feature = JSON_EXTRACT(descriptor_set_json, CONCAT(JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]')), '."7"."12"."4"')); -- options.features.utf8_validation
+			SET scope_name = SUBSTRING(scope_name, 1, CHAR_LENGTH(scope_name) - CHAR_LENGTH(SUBSTRING_INDEX(scope_name, '.', -1)) - 1);
+			SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(scope_name)));
+		END WHILE;
+		IF utf8_validation_feature IS NULL THEN
+			SET utf8_validation_feature = JSON_EXTRACT(_pb_get_file_descriptor(descriptor_set_json, type_name), '$."8"."50"."4"'); -- options.features.utf8_validation
+		END IF;
+		RETURN IF(utf8_validation_feature = 3, 'passthrough', 'strict'); -- NONE
+	ELSE
+		RETURN 'passthrough';
+	END CASE;
+END $$
+
+-- Helper function to get the UTF-8 validation mode of a string field, given the default of the message type containing it
+DROP FUNCTION IF EXISTS _pb_get_field_utf8_validation $$
+CREATE FUNCTION _pb_get_field_utf8_validation(field_descriptor JSON, default_utf8_validation TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE utf8_validation_feature INT;
+
+	SET utf8_validation_feature = JSON_EXTRACT(field_descriptor, '$."8"."21"."4"'); -- options.features.utf8_validation
+	CASE utf8_validation_feature
+	WHEN 2 THEN -- VERIFY
+		RETURN 'strict';
+	WHEN 3 THEN -- NONE
+		RETURN 'passthrough';
+	ELSE
+		RETURN default_utf8_validation;
+	END CASE;
+END $$
+
+-- Helper function to find a field descriptor by its name or json_name
+DROP FUNCTION IF EXISTS _pb_get_field_descriptor_by_name $$
+CREATE FUNCTION _pb_get_field_descriptor_by_name(message_descriptor JSON, field_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
 	DECLARE fields JSON;
+	DECLARE field_descriptor JSON;
 	DECLARE field_count INT;
 	DECLARE field_index INT;
-	DECLARE field_descriptor JSON;
-	
+	DECLARE name TEXT;
+	DECLARE json_name TEXT;
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+	SET field_index = 0;
+
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET json_name = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."10"')), _pb_util_snake_to_lower_camel(name)); -- json_name
+		IF BINARY name = BINARY field_name OR BINARY json_name = BINARY field_name THEN
+			RETURN field_descriptor;
+		END IF;
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	RETURN NULL;
+END $$
+
+-- Helper function to read a boolean option of pb_message_to_json_with_options(). Missing options are false.
+DROP FUNCTION IF EXISTS _pb_json_options_get_boolean $$
+CREATE FUNCTION _pb_json_options_get_boolean(options JSON, option_name TEXT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	RETURN COALESCE(JSON_EXTRACT(options, CONCAT('$.', option_name)) = CAST('true' AS JSON), FALSE);
+END $$
+
+-- Helper function to re-encode base64 JSON strings (or arrays of them) holding bytes values as base64url or hex
+DROP FUNCTION IF EXISTS _pb_json_encode_bytes $$
+CREATE FUNCTION _pb_json_encode_bytes(json_value JSON, bytes_encoding TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE encoded TEXT;
+
+	IF json_value IS NULL OR bytes_encoding = 'base64' THEN
+		RETURN json_value;
+	END IF;
+
+	IF JSON_TYPE(json_value) = 'ARRAY' THEN
+		SET result = JSON_ARRAY();
+		SET element_count = JSON_LENGTH(json_value);
+		WHILE element_index < element_count DO
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_json_encode_bytes(JSON_EXTRACT(json_value, CONCAT('$[', element_index, ']')), bytes_encoding));
+			SET element_index = element_index + 1;
+		END WHILE;
+		RETURN result;
+	END IF;
+
+	SET encoded = JSON_UNQUOTE(json_value);
+	CASE bytes_encoding
+	WHEN 'base64url' THEN
+		RETURN JSON_QUOTE(REPLACE(REPLACE(REPLACE(encoded, '\n', ''), '+', '-'), '/', '_'));
+	WHEN 'hex' THEN
+		RETURN JSON_QUOTE(LOWER(HEX(FROM_BASE64(encoded))));
+	ELSE
+		RETURN json_value;
+	END CASE;
+END $$
+
+-- Helper procedure to convert a single field of a message to JSON using its field descriptor.
+-- Nested messages are not converted here. Each of them is left as a JSON null placeholder and described in
+-- nested_messages as {"p": <JSON path of the placeholder relative to the field value>, "t": <type name>,
+-- "b": <base64-encoded message>, "f": <field number>}, to be filled in by _pb_json_resolve_nested_messages.
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_field_as_partial_json $$
+CREATE PROCEDURE _pb_wire_json_get_field_as_partial_json(IN descriptor_set_json JSON, IN syntax TEXT, IN default_utf8_validation TEXT, IN wire_json JSON, IN field_descriptor JSON, IN as_number_json BOOLEAN, IN options JSON, OUT field_json_value JSON, OUT nested_messages JSON)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+
 	-- Field properties
 	DECLARE field_number INT;
 	DECLARE field_name TEXT;
 	DECLARE field_label INT;
 	DECLARE field_type INT;
 	DECLARE field_type_name TEXT;
-	DECLARE json_name TEXT;
 	DECLARE proto3_optional BOOLEAN;
 	DECLARE oneof_index INT;
-	DECLARE default_value TEXT;
-	
+
 	-- Processing variables
 	DECLARE is_repeated BOOLEAN;
 	DECLARE has_field_presence BOOLEAN;
-	DECLARE field_json_value JSON;
-	DECLARE json_field_name TEXT;
+	DECLARE utf8_validation TEXT;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE nested_json_value JSON;
 	DECLARE elements JSON;
 	DECLARE element JSON;
 	DECLARE element_count INT;
 	DECLARE element_index INT;
-	
+
 	-- Map handling
 	DECLARE is_map BOOLEAN;
 	DECLARE map_entry_descriptor JSON;
@@ -683,216 +986,406 @@ proc: BEGIN
 	DECLARE map_value_type INT;
 	DECLARE map_value_type_name TEXT;
 	DECLARE map_key JSON;
+	DECLARE map_key_path TEXT;
 	DECLARE map_value JSON;
-	
-	-- Oneof handling
-	DECLARE oneofs JSON;
-	DECLARE oneof_priority INT;
-	DECLARE oneof_priority_prev INT;
-	
-	SET @@SESSION.max_sp_recursion_depth = 255;
-	
-	IF buf IS NULL THEN
-		SET result = NULL;
-		LEAVE proc;
-	END IF;
-	
-	-- Handle well-known types first
-	IF full_type_name LIKE '.google.protobuf.%' THEN
-		SET result = _pb_wire_json_decode_wkt_as_json(pb_message_to_wire_json(buf), full_type_name, as_number_json);
-		IF result IS NOT NULL THEN
-			LEAVE proc;
-		END IF;
+
+	-- Options
+	DECLARE use_enum_numbers BOOLEAN;
+	DECLARE int64_as_number BOOLEAN;
+	DECLARE bytes_encoding TEXT;
+
+	-- Report decoder errors in this field (or in messages nested in it) with the field path from this message
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_prefix_error_field_path(message_text, JSON_EXTRACT(field_descriptor, '$."3"'));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET use_enum_numbers = as_number_json OR _pb_json_options_get_boolean(options, 'UseEnumNumbers');
+	SET int64_as_number = as_number_json OR _pb_json_options_get_boolean(options, 'Int64AsNumber');
+	SET bytes_encoding = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(options, '$.BytesEncoding')), 'base64');
+	SET nested_messages = JSON_ARRAY();
+
+	-- Extract field properties from FieldDescriptorProto
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+	SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+	SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+
+	SET is_repeated = (field_label = 3); -- LABEL_REPEATED
+
+	-- Check if this is a map field
+	SET is_map = FALSE;
+	IF field_type = 11 AND field_type_name IS NOT NULL THEN -- TYPE_MESSAGE
+		SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+		SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
 	END IF;
-	
-	-- Get message descriptor
-	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
-	
-	IF message_descriptor IS NULL THEN
-		SET message_text = CONCAT('_pb_message_to_json: message type `', full_type_name, '` not found in descriptor set');
+
+	-- Determine field presence
+	SET has_field_presence =
+		(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+		OR (syntax = 'proto3'
+			AND (
+				(field_label = 1 AND proto3_optional) -- proto3 optional
+				OR (field_label <> 3 AND field_type = 11) -- message fields
+				OR (oneof_index IS NOT NULL) -- oneof fields
+			));
+
+	SET utf8_validation = _pb_get_field_utf8_validation(field_descriptor, default_utf8_validation);
+
+	CASE field_type
+	WHEN 10 THEN -- TYPE_GROUP (unsupported)
+		SET message_text = CONCAT('_pb_message_to_json: unsupported field_type `', field_type, '` for field `', field_name, '` (', field_number, ').');
 		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+
+	WHEN 11 THEN -- TYPE_MESSAGE
+		IF is_map THEN
+			-- Handle map fields
+			SET elements = pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			SET field_json_value = JSON_OBJECT();
+
+			-- Get map key/value field descriptors
+			SET map_key_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]'); -- first field (key)
+			SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]'); -- second field (value)
+			SET map_key_type = JSON_EXTRACT(map_key_field, '$."5"');
+			SET map_value_type = JSON_EXTRACT(map_value_field, '$."5"');
+			SET map_value_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
+
+			WHILE element_index < element_count DO
+				SET element = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))));
+				IF map_key_type = 9 THEN -- string
+					CALL _pb_wire_json_get_string_field_as_json(element, 1, FALSE, FALSE, utf8_validation, map_key);
+				ELSE
+					CALL _pb_wire_json_get_primitive_field_as_json(element, 1, map_key_type, FALSE, FALSE, as_number_json, map_key);
+				END IF;
+
+				IF JSON_TYPE(map_key) = 'STRING' THEN
+					SET map_key_path = CONCAT('.', map_key);
+				ELSE
+					SET map_key_path = CONCAT('."', map_key, '"');
+				END IF;
+
+				IF map_value_type = 11 THEN -- message
+					SET bytes_value = pb_wire_json_get_message_field(element, 2, NULL);
+					IF bytes_value IS NULL THEN
+						SET map_value = NULL;
+					ELSE
+						SET map_value = CAST('null' AS JSON);
+						SET nested_messages = JSON_ARRAY_APPEND(nested_messages, '$', JSON_OBJECT('p', map_key_path, 't', map_value_type_name, 'b', TO_BASE64(bytes_value), 'f', field_number));
+					END IF;
+				ELSEIF map_value_type = 14 THEN -- enum
+					IF use_enum_numbers THEN
+						SET map_value = CAST(pb_wire_json_get_enum_field(element, 2, NULL) AS JSON);
+					ELSE
+						CALL _pb_enum_to_json(descriptor_set_json, map_value_type_name, pb_wire_json_get_enum_field(element, 2, NULL), map_value);
+					END IF;
+				ELSEIF map_value_type = 9 THEN -- string
+					CALL _pb_wire_json_get_string_field_as_json(element, 2, FALSE, TRUE, utf8_validation, map_value);
+				ELSE
+					CALL _pb_wire_json_get_primitive_field_as_json(element, 2, map_value_type, FALSE, TRUE, int64_as_number, map_value);
+					IF map_value_type = 12 THEN -- bytes
+						SET map_value = _pb_json_encode_bytes(map_value, bytes_encoding);
+					END IF;
+				END IF;
+
+				SET field_json_value = JSON_SET(field_json_value, CONCAT('$', map_key_path), map_value);
+
+				SET element_index = element_index + 1;
+			END WHILE;
+
+		ELSEIF is_repeated THEN
+			-- Handle repeated message fields
+			SET elements = pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			SET field_json_value = JSON_ARRAY();
+
+			WHILE element_index < element_count DO
+				SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', CAST('null' AS JSON));
+				SET nested_messages = JSON_ARRAY_APPEND(nested_messages, '$', JSON_OBJECT('p', CONCAT('[', element_index, ']'), 't', field_type_name, 'b', JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')), 'f', field_number));
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSE
+			-- Handle singular message fields
+			SET bytes_value = pb_wire_json_get_message_field(wire_json, field_number, NULL);
+			IF bytes_value IS NULL THEN
+				SET field_json_value = NULL;
+			ELSE
+				SET field_json_value = CAST('null' AS JSON);
+				SET nested_messages = JSON_ARRAY_APPEND(nested_messages, '$', JSON_OBJECT('p', '', 't', field_type_name, 'b', TO_BASE64(bytes_value), 'f', field_number));
+			END IF;
+		END IF;
+
+	WHEN 14 THEN -- TYPE_ENUM
+		IF is_repeated THEN
+			SET elements = pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			SET field_json_value = JSON_ARRAY();
+
+			WHILE element_index < element_count DO
+				SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+				IF use_enum_numbers THEN
+					SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', CAST(element AS JSON));
+				ELSE
+					CALL _pb_enum_to_json(descriptor_set_json, field_type_name, element, nested_json_value);
+					SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', nested_json_value);
+				END IF;
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSE
+			IF use_enum_numbers THEN
+				SET field_json_value = CAST(pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0)) AS JSON);
+			ELSE
+				CALL _pb_enum_to_json(descriptor_set_json, field_type_name, pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0)), field_json_value);
+			END IF;
+		END IF;
+
+	WHEN 9 THEN -- TYPE_STRING
+		CALL _pb_wire_json_get_string_field_as_json(wire_json, field_number, is_repeated, has_field_presence, utf8_validation, field_json_value);
+
+	ELSE
+		-- Handle primitive types using existing function
+		CALL _pb_wire_json_get_primitive_field_as_json(wire_json, field_number, field_type, is_repeated, has_field_presence, int64_as_number, field_json_value);
+		IF field_type = 12 THEN -- bytes
+			SET field_json_value = _pb_json_encode_bytes(field_json_value, bytes_encoding);
+		END IF;
+	END CASE;
+END $$
+
+-- Helper function to get the fields of a wire JSON object that are not declared in the message descriptor
+DROP FUNCTION IF EXISTS _pb_wire_json_get_unknown_fields $$
+CREATE FUNCTION _pb_wire_json_get_unknown_fields(message_descriptor JSON, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	WHILE field_index < field_count DO
+		SET wire_json = JSON_REMOVE(wire_json, CONCAT('$."', JSON_EXTRACT(fields, CONCAT('$[', field_index, ']."3"')), '"')); -- number
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	RETURN wire_json;
+END $$
+
+-- Helper procedure to convert a single message in wire JSON format to JSON, without converting the messages nested in it.
+-- Nested messages are left as placeholders and returned in nested_messages, with paths relative to the result
+-- (see _pb_wire_json_get_field_as_partial_json). repeated_element_count is the number of repeated field elements and
+-- map entries in the result.
+DROP PROCEDURE IF EXISTS _pb_wire_json_message_to_partial_json $$
+CREATE PROCEDURE _pb_wire_json_message_to_partial_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN wire_json JSON, IN as_number_json BOOLEAN, IN options JSON, OUT result JSON, OUT nested_messages JSON, OUT repeated_element_count INT)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+	
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE default_utf8_validation TEXT;
+	DECLARE fields JSON;
+	DECLARE field_map JSON;
+	DECLARE field_numbers JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT;
+	DECLARE field_descriptor JSON;
+	DECLARE unknown_fields JSON;
+	
+	-- Field properties
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE json_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE oneof_index INT;
+	DECLARE has_field_presence BOOLEAN;
+	
+	-- Processing variables
+	DECLARE field_json_value JSON;
+	DECLARE field_nested_messages JSON;
+	DECLARE json_field_name TEXT;
+	DECLARE is_populated BOOLEAN;
+	DECLARE elements JSON;
+	DECLARE element JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	
+	-- Oneof handling
+	DECLARE oneofs JSON;
+	DECLARE oneof_priority INT;
+	DECLARE oneof_priority_prev INT;
+
+	-- Options
+	DECLARE use_proto_names BOOLEAN;
+	DECLARE emit_unpopulated BOOLEAN;
+	DECLARE emit_default_values BOOLEAN;
+	DECLARE emit_unknown_fields BOOLEAN;
+	
+	SET nested_messages = JSON_ARRAY();
+	SET repeated_element_count = 0;
+	
+	-- Handle well-known types first
+	IF full_type_name LIKE '.google.protobuf.%' THEN
+		SET result = _pb_wire_json_decode_wkt_as_json(wire_json, full_type_name, as_number_json OR _pb_json_options_get_boolean(options, 'Int64AsNumber'));
+		IF result IS NOT NULL THEN
+			IF full_type_name = '.google.protobuf.BytesValue' THEN
+				SET result = _pb_json_encode_bytes(result, COALESCE(JSON_UNQUOTE(JSON_EXTRACT(options, '$.BytesEncoding')), 'base64'));
+			END IF;
+			LEAVE proc;
+		END IF;
 	END IF;
+
+	SET use_proto_names = _pb_json_options_get_boolean(options, 'UseProtoNames');
+	SET emit_unpopulated = _pb_json_options_get_boolean(options, 'EmitUnpopulated');
+	SET emit_default_values = _pb_json_options_get_boolean(options, 'EmitDefaultValues');
+	SET emit_unknown_fields = _pb_json_options_get_boolean(options, 'EmitUnknownFields');
 	
-	-- Get file descriptor to determine syntax
-	SET file_descriptor = _pb_get_file_descriptor(descriptor_set_json, full_type_name);
-	SET syntax = JSON_UNQUOTE(JSON_EXTRACT(file_descriptor, '$."12"')); -- syntax field
-	IF syntax IS NULL THEN
-		SET syntax = 'proto2'; -- default
+	-- Get message descriptor
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', '_pb_message_to_json', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
 	END IF;
 	
+	-- Get file descriptor to determine syntax
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET default_utf8_validation = _pb_get_utf8_validation(descriptor_set_json, full_type_name);
+	
 	SET result = JSON_OBJECT();
 	SET oneofs = JSON_OBJECT();
-	SET wire_json = pb_message_to_wire_json(buf);
-	
-	-- Get fields array (field 2 in DescriptorProto)
-	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET unknown_fields = JSON_OBJECT();
 	
-	IF fields IS NOT NULL THEN
+	-- Map field numbers to field descriptors (fields are field 2 in DescriptorProto)
+	SET fields = COALESCE(JSON_EXTRACT(message_descriptor, '$."2"'), JSON_ARRAY());
+	SELECT COALESCE(JSON_OBJECTAGG(number, descriptor), JSON_OBJECT())
+	INTO field_map
+	FROM JSON_TABLE(fields, '$[*]' COLUMNS (
+		number INT PATH '$."3"',
+		descriptor JSON PATH '$'
+	)) jt;
+
+	-- Visit the fields present on the wire, followed by the unpopulated fields if they are to be emitted.
+	-- Each field is converted from its own wire elements rather than from the whole wire_json.
+	SET field_numbers = JSON_KEYS(wire_json);
+	IF emit_unpopulated OR emit_default_values THEN
 		SET field_count = JSON_LENGTH(fields);
 		SET field_index = 0;
-		
 		WHILE field_index < field_count DO
-			SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
-			
-			-- Extract field properties from FieldDescriptorProto
-			SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
-			SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
-			SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
-			SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
-			SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
-			SET json_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."10"')); -- json_name
-			SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
-			SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
-			SET default_value = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."7"')); -- default_value
-			
-			SET is_repeated = (field_label = 3); -- LABEL_REPEATED
-			
-			-- Check if this is a map field
-			SET is_map = FALSE;
-			IF field_type = 11 AND field_type_name IS NOT NULL THEN -- TYPE_MESSAGE
-				SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
-				SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+			SET field_number = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']."3"'));
+			IF NOT JSON_CONTAINS_PATH(wire_json, 'one', CONCAT('$."', field_number, '"')) THEN
+				SET field_numbers = JSON_ARRAY_APPEND(field_numbers, '$', CAST(field_number AS CHAR));
 			END IF;
+			SET field_index = field_index + 1;
+		END WHILE;
+	END IF;
+
+	SET field_count = JSON_LENGTH(field_numbers);
+	SET field_index = 0;
+
+	l1: WHILE field_index < field_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_index, ']')));
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		SET field_descriptor = JSON_EXTRACT(field_map, CONCAT('$."', field_number, '"'));
+		SET field_index = field_index + 1;
+
+		IF field_descriptor IS NULL THEN
+			-- Unknown field
+			IF emit_unknown_fields THEN
+				SET unknown_fields = JSON_SET(unknown_fields, CONCAT('$."', field_number, '"'), elements);
+			END IF;
+			ITERATE l1;
+		END IF;
+		
+		-- Extract field properties from FieldDescriptorProto
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET json_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."10"')); -- json_name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+
+		SET has_field_presence =
+			(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+			OR (syntax = 'proto3'
+				AND (
+					(field_label = 1 AND proto3_optional) -- proto3 optional
+					OR (field_label <> 3 AND field_type = 11) -- message fields
+					OR (oneof_index IS NOT NULL) -- oneof fields
+				));
+		
+		CALL _pb_wire_json_get_field_as_partial_json(descriptor_set_json, syntax, default_utf8_validation, IF(elements IS NULL, JSON_OBJECT(), JSON_OBJECT(CAST(field_number AS CHAR), elements)), field_descriptor, as_number_json, options, field_json_value, field_nested_messages);
+
+		-- A field is populated if it is set, or for fields without presence, if it has a non-default value
+		IF field_label = 3 THEN -- LABEL_REPEATED
+			SET is_populated = COALESCE(JSON_LENGTH(field_json_value) > 0, FALSE);
+		ELSEIF has_field_presence THEN
+			SET is_populated = elements IS NOT NULL;
+		ELSE
+			SET is_populated = elements IS NOT NULL AND JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, '].v'))) NOT IN ('0', '');
+		END IF;
+
+		-- Unpopulated fields are emitted in the same way as protojson's EmitUnpopulated and EmitDefaultValues options
+		IF NOT is_populated THEN
+			IF oneof_index IS NOT NULL OR NOT (emit_unpopulated OR emit_default_values) THEN
+				SET field_json_value = NULL;
+			ELSEIF field_label <> 3 AND has_field_presence THEN -- proto2 scalar or singular message fields
+				SET field_json_value = IF(emit_unpopulated, CAST('null' AS JSON), NULL);
+			END IF;
+		END IF;
+		
+		-- Add field to result if it has a value
+		IF field_json_value IS NOT NULL THEN
+			IF as_number_json THEN
+				SET json_field_name = CAST(field_number AS CHAR);
+			ELSEIF use_proto_names THEN
+				SET json_field_name = field_name;
+			ELSE
+				SET json_field_name = IF(json_name IS NOT NULL, json_name, _pb_util_snake_to_lower_camel(field_name));
+			END IF;
+
+			IF field_label = 3 THEN -- LABEL_REPEATED
+				SET repeated_element_count = repeated_element_count + JSON_LENGTH(field_json_value);
+			END IF;
+
+			-- Nested messages are placed under the field name. Those in a oneof member that is later overwritten are
+			-- skipped by _pb_json_resolve_nested_messages, as their placeholders never make it into the result.
+			SET element_count = JSON_LENGTH(field_nested_messages);
+			SET element_index = 0;
+			WHILE element_index < element_count DO
+				SET element = JSON_EXTRACT(field_nested_messages, CONCAT('$[', element_index, ']'));
+				SET element = JSON_SET(element, '$.p', CONCAT('."', json_field_name, '"', JSON_UNQUOTE(JSON_EXTRACT(element, '$.p'))));
+				SET nested_messages = JSON_ARRAY_APPEND(nested_messages, '$', element);
+				SET element_index = element_index + 1;
+			END WHILE;
 			
-			-- Determine field presence
-			SET has_field_presence =
-				(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
-				OR (syntax = 'proto3'
-					AND (
-						(field_label = 1 AND proto3_optional) -- proto3 optional
-						OR (field_label <> 3 AND field_type = 11) -- message fields
-						OR (oneof_index IS NOT NULL) -- oneof fields
-					));
-			
-			CASE field_type
-			WHEN 10 THEN -- TYPE_GROUP (unsupported)
-				SET message_text = CONCAT('_pb_message_to_json: unsupported field_type `', field_type, '` for field `', field_name, '` (', field_number, ').');
-				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
-			
-			WHEN 11 THEN -- TYPE_MESSAGE
-				IF is_map THEN
-					-- Handle map fields
-					SET elements = pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
-					SET element_count = JSON_LENGTH(elements);
-					SET element_index = 0;
-					SET field_json_value = JSON_OBJECT();
-					
-					-- Get map key/value field descriptors
-					SET map_key_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]'); -- first field (key)
-					SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]'); -- second field (value)
-					SET map_key_type = JSON_EXTRACT(map_key_field, '$."5"');
-					SET map_value_type = JSON_EXTRACT(map_value_field, '$."5"');
-					SET map_value_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
-					
-					WHILE element_index < element_count DO
-						SET element = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))));
-						CALL _pb_wire_json_get_primitive_field_as_json(element, 1, map_key_type, FALSE, FALSE, as_number_json, map_key);
-						
-						IF map_value_type = 11 THEN -- message
-							CALL _pb_message_to_json(descriptor_set_json, map_value_type_name, pb_wire_json_get_message_field(element, 2, NULL), as_number_json, map_value);
-						ELSEIF map_value_type = 14 THEN -- enum
-							IF as_number_json THEN
-								SET map_value = CAST(pb_wire_json_get_enum_field(element, 2, NULL) AS JSON);
-							ELSE
-								CALL _pb_enum_to_json(descriptor_set_json, map_value_type_name, pb_wire_json_get_enum_field(element, 2, NULL), map_value);
-							END IF;
-						ELSE
-							CALL _pb_wire_json_get_primitive_field_as_json(element, 2, map_value_type, FALSE, TRUE, as_number_json, map_value);
-						END IF;
-						
-						IF JSON_TYPE(map_key) = 'STRING' THEN
-							SET field_json_value = JSON_SET(field_json_value, CONCAT('$.', map_key), map_value);
-						ELSE
-							SET field_json_value = JSON_SET(field_json_value, CONCAT('$."', map_key, '"'), map_value);
-						END IF;
-						
-						SET element_index = element_index + 1;
-					END WHILE;
-					
-				ELSEIF is_repeated THEN
-					-- Handle repeated message fields
-					SET element_count = pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
-					SET element_index = 0;
-					SET field_json_value = JSON_ARRAY();
-					
-					WHILE element_index < element_count DO
-						SET bytes_value = pb_wire_json_get_repeated_message_field_element(wire_json, field_number, element_index);
-						CALL _pb_message_to_json(descriptor_set_json, field_type_name, bytes_value, as_number_json, nested_json_value);
-						SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', nested_json_value);
-						SET element_index = element_index + 1;
-					END WHILE;
-				ELSE
-					-- Handle singular message fields
-					SET bytes_value = pb_wire_json_get_message_field(wire_json, field_number, NULL);
-					IF bytes_value IS NULL THEN
-						SET field_json_value = NULL;
-					ELSE
-						CALL _pb_message_to_json(descriptor_set_json, field_type_name, bytes_value, as_number_json, nested_json_value);
-						SET field_json_value = nested_json_value;
-					END IF;
-				END IF;
-			
-			WHEN 14 THEN -- TYPE_ENUM
-				IF is_repeated THEN
-					SET elements = pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
-					SET element_count = JSON_LENGTH(elements);
-					SET element_index = 0;
-					SET field_json_value = JSON_ARRAY();
-					
-					WHILE element_index < element_count DO
-						SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
-						IF as_number_json THEN
-							SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', CAST(element AS JSON));
-						ELSE
-							CALL _pb_enum_to_json(descriptor_set_json, field_type_name, element, nested_json_value);
-							SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', nested_json_value);
-						END IF;
-						SET element_index = element_index + 1;
-					END WHILE;
-				ELSE
-					IF as_number_json THEN
-						SET field_json_value = CAST(pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0)) AS JSON);
-					ELSE
-						CALL _pb_enum_to_json(descriptor_set_json, field_type_name, pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0)), field_json_value);
-					END IF;
+			IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+				-- Handle oneof fields; the field that appears last on the wire wins
+				SET oneof_priority = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements)-1, '].i'));
+				SET oneof_priority_prev = JSON_EXTRACT(oneofs, CONCAT('$."', oneof_index, '".i'));
+				
+				IF oneof_priority_prev IS NULL OR oneof_priority_prev < oneof_priority THEN
+					SET oneofs = JSON_SET(oneofs, CONCAT('$."', oneof_index, '"'), JSON_OBJECT('i', oneof_priority, 'v', JSON_OBJECT(json_field_name, field_json_value)));
 				END IF;
-			
 			ELSE
-				-- Handle primitive types using existing function
-				CALL _pb_wire_json_get_primitive_field_as_json(wire_json, field_number, field_type, is_repeated, has_field_presence, as_number_json, field_json_value);
-			END CASE;
-			
-			-- Add field to result if it has a value
-			IF field_json_value IS NOT NULL THEN
+				-- Regular field
 				IF as_number_json THEN
-					SET json_field_name = CAST(field_number AS CHAR);
+					-- For number JSON format, field names are numeric and need to be quoted in JSON paths
+					SET result = JSON_SET(result, CONCAT('$."', json_field_name, '"'), field_json_value);
 				ELSE
-					SET json_field_name = IF(json_name IS NOT NULL, json_name, _pb_util_snake_to_lower_camel(field_name));
-				END IF;
-				
-				IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
-					-- Handle oneof fields
-					SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
-					SET oneof_priority = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements)-1, '].i'));
-					SET oneof_priority_prev = JSON_EXTRACT(oneofs, CONCAT('$."', oneof_index, '".i'));
-					
-					IF oneof_priority_prev IS NULL OR oneof_priority_prev < oneof_priority THEN
-						SET oneofs = JSON_SET(oneofs, CONCAT('$."', oneof_index, '"'), JSON_OBJECT('i', oneof_priority, 'v', JSON_OBJECT(json_field_name, field_json_value)));
-					END IF;
-				ELSE
-					-- Regular field
-					IF as_number_json THEN
-						-- For number JSON format, field names are numeric and need to be quoted in JSON paths
-						SET result = JSON_SET(result, CONCAT('$."', json_field_name, '"'), field_json_value);
-					ELSE
-						SET result = JSON_SET(result, CONCAT('$.', json_field_name), field_json_value);
-					END IF;
+					SET result = JSON_SET(result, CONCAT('$.', json_field_name), field_json_value);
 				END IF;
 			END IF;
-			
-			SET field_index = field_index + 1;
-		END WHILE;
-	END IF;
+		END IF;
+	END WHILE;
 	
 	-- Add oneof fields to result
 	SET elements = JSON_EXTRACT(oneofs, '$.*.v');
@@ -904,6 +1397,167 @@ proc: BEGIN
 		SET result = JSON_MERGE(result, field_json_value);
 		SET element_index = element_index + 1;
 	END WHILE;
+
+	-- Add unknown fields as wire JSON under a reserved key
+	IF emit_unknown_fields AND JSON_LENGTH(unknown_fields) > 0 THEN
+		SET result = JSON_SET(result, '$."@unknown"', unknown_fields);
+	END IF;
+END $$
+
+-- Helper procedure to fill in the placeholders of nested messages in a partially converted JSON value.
+-- The messages are converted one at a time from an explicit work stack instead of by recursive calls, so the
+-- conversion does not depend on max_sp_recursion_depth. Instead, it is bounded by the MaxDepth (default 100),
+-- MaxOutputSize and MaxElements options.
+DROP PROCEDURE IF EXISTS _pb_json_resolve_nested_messages $$
+CREATE PROCEDURE _pb_json_resolve_nested_messages(IN descriptor_set_json JSON, IN nested_messages JSON, IN as_number_json BOOLEAN, IN options JSON, INOUT result JSON)
+BEGIN
+	DECLARE message_text TEXT;
+	DECLARE stack JSON;
+	DECLARE stack_length INT;
+	DECLARE nested_message JSON;
+	DECLARE nested_message_count INT;
+	DECLARE nested_message_index INT;
+	DECLARE resolved_paths JSON;
+
+	-- Current message
+	DECLARE message_path TEXT;
+	DECLARE message_depth INT;
+	DECLARE message_field_path JSON;
+	DECLARE message_wire_json JSON;
+	DECLARE message_json JSON;
+	DECLARE message_nested_messages JSON;
+	DECLARE message_element_count INT;
+	DECLARE field_path_index INT;
+
+	-- Limits
+	DECLARE max_depth INT;
+	DECLARE max_output_size BIGINT;
+	DECLARE max_elements BIGINT;
+	DECLARE output_size BIGINT;
+	DECLARE element_count BIGINT DEFAULT 0;
+
+	SET max_depth = COALESCE(JSON_EXTRACT(options, '$.MaxDepth'), 100);
+	SET max_output_size = JSON_EXTRACT(options, '$.MaxOutputSize');
+	SET max_elements = JSON_EXTRACT(options, '$.MaxElements');
+	SET output_size = COALESCE(LENGTH(result), 0);
+
+	-- Stack entries have the same keys as nested_messages, with "p" made absolute, "d" for the nesting depth
+	-- and "f" replaced by the path of field numbers from the outermost message for error messages.
+	SET stack = JSON_ARRAY();
+	SET resolved_paths = JSON_ARRAY();
+	SET nested_message_count = JSON_LENGTH(nested_messages);
+	SET nested_message_index = 0;
+	WHILE nested_message_index < nested_message_count DO
+		SET nested_message = JSON_EXTRACT(nested_messages, CONCAT('$[', nested_message_index, ']'));
+		SET nested_message = JSON_SET(nested_message,
+			'$.p', CONCAT('$', JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.p'))),
+			'$.d', 1,
+			'$.f', IF(JSON_TYPE(JSON_EXTRACT(nested_message, '$.f')) IN ('INTEGER', 'UNSIGNED INTEGER'), JSON_ARRAY(JSON_EXTRACT(nested_message, '$.f')), JSON_ARRAY()));
+		SET stack = JSON_ARRAY_APPEND(stack, '$', nested_message);
+		SET nested_message_index = nested_message_index + 1;
+	END WHILE;
+
+	WHILE JSON_LENGTH(stack) > 0 DO
+		SET stack_length = JSON_LENGTH(stack);
+		SET nested_message = JSON_EXTRACT(stack, CONCAT('$[', stack_length - 1, ']'));
+		SET stack = JSON_REMOVE(stack, CONCAT('$[', stack_length - 1, ']'));
+
+		SET message_path = JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.p'));
+		SET message_depth = JSON_EXTRACT(nested_message, '$.d');
+		SET message_field_path = JSON_EXTRACT(nested_message, '$.f');
+
+		-- Skip messages whose placeholder was dropped, e.g. members of a oneof that is set again later on the wire.
+		-- Also skip messages whose placeholder was already filled in. Entries of a map with duplicate keys share the
+		-- same path, and the last one on the wire is popped first, so it wins as in protojson.
+		IF message_path = '$' OR (JSON_CONTAINS_PATH(result, 'one', message_path) AND NOT JSON_CONTAINS(resolved_paths, JSON_QUOTE(message_path))) THEN
+			IF message_depth > max_depth THEN
+				CALL _pb_signal_error('PB_DEPTH_LIMIT_EXCEEDED', '_pb_message_to_json', CONCAT('message nesting depth exceeds MaxDepth (', max_depth, ')'));
+			END IF;
+
+			message_block: BEGIN
+				-- Report decoder errors with the field path from the outermost message
+				DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007'
+				BEGIN
+					GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+					SET field_path_index = JSON_LENGTH(message_field_path);
+					WHILE field_path_index > 0 DO
+						SET field_path_index = field_path_index - 1;
+						SET message_text = _pb_util_prefix_error_field_path(message_text, JSON_EXTRACT(message_field_path, CONCAT('$[', field_path_index, ']')));
+					END WHILE;
+					RESIGNAL SET MESSAGE_TEXT = message_text;
+				END;
+
+				IF JSON_CONTAINS_PATH(nested_message, 'one', '$.w') THEN
+					SET message_wire_json = JSON_EXTRACT(nested_message, '$.w');
+				ELSE
+					SET message_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.b'))));
+				END IF;
+				CALL _pb_wire_json_message_to_partial_json(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.t')), message_wire_json, as_number_json, options, message_json, message_nested_messages, message_element_count);
+			END message_block;
+
+			-- The 'null' placeholder being replaced is 4 bytes long
+			SET output_size = output_size + LENGTH(message_json) - IF(result IS NULL, 0, 4);
+			IF output_size > max_output_size THEN
+				CALL _pb_signal_error('PB_OUTPUT_SIZE_LIMIT_EXCEEDED', '_pb_message_to_json', CONCAT('JSON output exceeds MaxOutputSize (', max_output_size, ' bytes)'));
+			END IF;
+			SET element_count = element_count + message_element_count;
+			IF element_count > max_elements THEN
+				CALL _pb_signal_error('PB_ELEMENT_LIMIT_EXCEEDED', '_pb_message_to_json', CONCAT('number of repeated field elements and map entries exceeds MaxElements (', max_elements, ')'));
+			END IF;
+
+			IF message_path = '$' THEN
+				SET result = message_json;
+			ELSE
+				SET result = JSON_REPLACE(result, message_path, message_json);
+				SET resolved_paths = JSON_ARRAY_APPEND(resolved_paths, '$', message_path);
+			END IF;
+
+			SET nested_message_count = JSON_LENGTH(message_nested_messages);
+			SET nested_message_index = 0;
+			WHILE nested_message_index < nested_message_count DO
+				SET nested_message = JSON_EXTRACT(message_nested_messages, CONCAT('$[', nested_message_index, ']'));
+				SET nested_message = JSON_SET(nested_message,
+					'$.p', CONCAT(message_path, JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.p'))),
+					'$.d', message_depth + 1,
+					'$.f', JSON_ARRAY_APPEND(message_field_path, '$', JSON_EXTRACT(nested_message, '$.f')));
+				SET stack = JSON_ARRAY_APPEND(stack, '$', nested_message);
+				SET nested_message_index = nested_message_index + 1;
+			END WHILE;
+		END IF;
+	END WHILE;
+END $$
+
+-- Helper procedure to convert a single field of a message to JSON using its field descriptor, including nested messages
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_field_as_json $$
+CREATE PROCEDURE _pb_wire_json_get_field_as_json(IN descriptor_set_json JSON, IN syntax TEXT, IN default_utf8_validation TEXT, IN wire_json JSON, IN field_descriptor JSON, IN as_number_json BOOLEAN, IN options JSON, OUT field_json_value JSON)
+BEGIN
+	DECLARE nested_messages JSON;
+
+	CALL _pb_wire_json_get_field_as_partial_json(descriptor_set_json, syntax, default_utf8_validation, wire_json, field_descriptor, as_number_json, options, field_json_value, nested_messages);
+	IF field_json_value IS NOT NULL THEN
+		CALL _pb_json_resolve_nested_messages(descriptor_set_json, nested_messages, as_number_json, options, field_json_value);
+	END IF;
+END $$
+
+-- Main procedure for converting a message in wire JSON format to JSON using descriptor set
+DROP PROCEDURE IF EXISTS _pb_wire_json_to_json $$
+CREATE PROCEDURE _pb_wire_json_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN wire_json JSON, IN as_number_json BOOLEAN, IN options JSON, OUT result JSON)
+BEGIN
+	SET result = NULL;
+	IF wire_json IS NOT NULL THEN
+		CALL _pb_json_resolve_nested_messages(descriptor_set_json, JSON_ARRAY(JSON_OBJECT('p', '', 't', full_type_name, 'w', wire_json)), as_number_json, options, result);
+	END IF;
+END $$
+
+-- Main procedure for converting protobuf message to JSON using descriptor set
+DROP PROCEDURE IF EXISTS _pb_message_to_json $$
+CREATE PROCEDURE _pb_message_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN as_number_json BOOLEAN, IN options JSON, OUT result JSON)
+BEGIN
+	IF buf IS NULL THEN
+		SET result = NULL;
+	ELSE
+		CALL _pb_wire_json_to_json(descriptor_set_json, full_type_name, pb_message_to_wire_json(buf), as_number_json, options, result);
+	END IF;
 END $$
 
 -- Public function interface
@@ -911,7 +1565,67 @@ DROP FUNCTION IF EXISTS pb_message_to_json $$
 CREATE FUNCTION pb_message_to_json(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_message_to_json(descriptor_set_json, type_name, message, FALSE, result);
+	CALL _pb_message_to_json(descriptor_set_json, type_name, message, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface that also emits fields not declared in the schema, as wire JSON under the "@unknown" key
+DROP FUNCTION IF EXISTS pb_message_to_json_with_unknown_fields $$
+CREATE FUNCTION pb_message_to_json_with_unknown_fields(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_to_json(descriptor_set_json, type_name, message, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE, 'EmitUnknownFields', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface with protojson-style marshal options
+DROP FUNCTION IF EXISTS pb_message_to_json_with_options $$
+CREATE FUNCTION pb_message_to_json_with_options(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, options JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE option_names JSON;
+	DECLARE option_count INT;
+	DECLARE option_index INT DEFAULT 0;
+	DECLARE option_name TEXT;
+	DECLARE option_value JSON;
+	DECLARE result JSON;
+
+	SET options = COALESCE(options, JSON_OBJECT());
+	IF JSON_TYPE(options) <> 'OBJECT' THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = 'pb_message_to_json_with_options: options must be a JSON object';
+	END IF;
+
+	SET option_names = JSON_KEYS(options);
+	SET option_count = JSON_LENGTH(option_names);
+	WHILE option_index < option_count DO
+		SET option_name = JSON_UNQUOTE(JSON_EXTRACT(option_names, CONCAT('$[', option_index, ']')));
+		SET option_value = JSON_EXTRACT(options, CONCAT('$.', JSON_QUOTE(option_name)));
+		CASE
+		WHEN option_name IN ('UseProtoNames', 'EmitUnpopulated', 'EmitDefaultValues', 'UseEnumNumbers', 'Int64AsNumber', 'EmitUnknownFields') THEN
+			IF JSON_TYPE(option_value) <> 'BOOLEAN' THEN
+				SET message_text = CONCAT('pb_message_to_json_with_options: option `', option_name, '` must be a boolean');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+		WHEN option_name = 'BytesEncoding' THEN
+			IF JSON_TYPE(option_value) <> 'STRING' OR JSON_UNQUOTE(option_value) NOT IN ('base64', 'base64url', 'hex') THEN
+				SET message_text = CONCAT('pb_message_to_json_with_options: unsupported BytesEncoding ', option_value);
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+		WHEN option_name IN ('MaxDepth', 'MaxOutputSize', 'MaxElements') THEN
+			IF JSON_TYPE(option_value) NOT IN ('INTEGER', 'UNSIGNED INTEGER') OR option_value < 0 THEN
+				SET message_text = CONCAT('pb_message_to_json_with_options: option `', option_name, '` must be a non-negative integer');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+		ELSE
+			SET message_text = CONCAT('pb_message_to_json_with_options: unknown option `', option_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END CASE;
+		SET option_index = option_index + 1;
+	END WHILE;
+
+	CALL _pb_message_to_json(descriptor_set_json, type_name, message, FALSE, options, result);
 	RETURN result;
 END $$
 
@@ -920,6 +1634,4201 @@ DROP FUNCTION IF EXISTS _pb_message_to_number_json $$
 CREATE FUNCTION _pb_message_to_number_json(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_message_to_json(descriptor_set_json, type_name, message, TRUE, result);
+	CALL _pb_message_to_json(descriptor_set_json, type_name, message, TRUE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
 	RETURN result;
 END $$
+
+-- Public function interface for messages kept in wire JSON format, without serializing them to a message first
+DROP FUNCTION IF EXISTS pb_wire_json_to_json $$
+CREATE FUNCTION pb_wire_json_to_json(descriptor_set_json JSON, type_name TEXT, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_wire_json_to_json(descriptor_set_json, type_name, pb_wire_json_to_v1(wire_json), FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface for protonumberjson format from wire JSON
+DROP FUNCTION IF EXISTS pb_wire_json_to_number_json $$
+CREATE FUNCTION pb_wire_json_to_number_json(descriptor_set_json JSON, type_name TEXT, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_wire_json_to_json(descriptor_set_json, type_name, pb_wire_json_to_v1(wire_json), TRUE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface for converting every message in a length-delimited stream (see pb_delimited_count) to JSON
+DROP FUNCTION IF EXISTS pb_delimited_as_json_array $$
+CREATE FUNCTION pb_delimited_as_json_array(descriptor_set_json JSON, type_name TEXT, stream LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE stream_length BIGINT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE element_length BIGINT;
+	DECLARE element_json JSON;
+	DECLARE result JSON;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset of the failing length prefix to decoder errors. Errors in the messages themselves are already
+	-- annotated with the offset in the message and left as is.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, NULL);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	IF stream IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET result = JSON_ARRAY();
+	SET stream_length = LENGTH(stream);
+	WHILE offset < stream_length DO
+		CALL _pb_delimited_read_element_header(stream, stream_length, offset, element_length);
+		CALL _pb_message_to_json(descriptor_set_json, type_name, SUBSTRING(stream, offset + 1, element_length), FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), element_json);
+		SET result = JSON_ARRAY_APPEND(result, '$', element_json);
+		SET offset = offset + element_length;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Procedure for extracting the JSON value at a field name path such as 'order.items[2].price' or 'labels["env"]'
+DROP PROCEDURE IF EXISTS _pb_message_get_json_by_path $$
+CREATE PROCEDURE _pb_message_get_json_by_path(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN path TEXT, OUT result JSON)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segments JSON;
+	DECLARE segment JSON;
+	DECLARE segment_count INT;
+	DECLARE segment_index INT;
+	DECLARE field_token TEXT;
+	DECLARE repeated_index INT;
+	DECLARE map_key TEXT;
+
+	DECLARE current_type_name TEXT;
+	DECLARE current_message LONGBLOB;
+	DECLARE message_descriptor JSON;
+	DECLARE field_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE wire_json JSON;
+	DECLARE field_json_value JSON;
+
+	-- Field properties
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE is_repeated BOOLEAN;
+	DECLARE is_map BOOLEAN;
+
+	-- Map handling
+	DECLARE map_entry_descriptor JSON;
+	DECLARE map_key_type INT;
+	DECLARE map_value_field JSON;
+	DECLARE entry_count INT;
+	DECLARE found_entry LONGBLOB;
+
+	IF message IS NULL OR path IS NULL THEN
+		SET result = NULL;
+		LEAVE proc;
+	END IF;
+
+	SET segments = _pb_util_parse_field_path(path);
+	SET segment_count = JSON_LENGTH(segments);
+
+	IF segment_count = 0 THEN
+		CALL _pb_message_to_json(descriptor_set_json, full_type_name, message, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+		LEAVE proc;
+	END IF;
+
+	SET current_type_name = full_type_name;
+	SET current_message = message;
+	SET segment_index = 0;
+
+	WHILE segment_index < segment_count DO
+		SET segment = JSON_EXTRACT(segments, CONCAT('$[', segment_index, ']'));
+		SET field_token = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.f'));
+		SET repeated_index = JSON_EXTRACT(segment, '$.i');
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.k'));
+
+		SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, current_type_name);
+		IF message_descriptor IS NULL THEN
+			CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_get_json_by_path', CONCAT('message type `', current_type_name, '` not found in descriptor set'));
+		END IF;
+
+		SET field_descriptor = _pb_get_field_descriptor_by_name(message_descriptor, field_token);
+		IF field_descriptor IS NULL THEN
+			SET message_text = CONCAT('pb_message_get_json_by_path: field `', field_token, '` not found in message type `', current_type_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET is_repeated = (field_label = 3); -- LABEL_REPEATED
+
+		SET is_map = FALSE;
+		IF field_type = 11 AND is_repeated THEN -- TYPE_MESSAGE
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		IF is_map AND repeated_index IS NOT NULL THEN
+			-- Unquoted integer subscripts on map fields are integer keys
+			SET map_key = CAST(repeated_index AS CHAR);
+			SET repeated_index = NULL;
+		END IF;
+
+		IF map_key IS NOT NULL AND NOT is_map THEN
+			SET message_text = CONCAT('pb_message_get_json_by_path: field `', field_token, '` is not a map field in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+		IF repeated_index IS NOT NULL AND (NOT is_repeated OR is_map) THEN
+			SET message_text = CONCAT('pb_message_get_json_by_path: field `', field_token, '` is not a repeated field in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET wire_json = pb_message_to_wire_json(current_message);
+
+		IF segment_index = segment_count - 1 THEN
+			-- Last segment: convert the field and pick the requested element
+			SET syntax = _pb_get_file_syntax(descriptor_set_json, current_type_name);
+			CALL _pb_wire_json_get_field_as_json(descriptor_set_json, syntax, _pb_get_utf8_validation(descriptor_set_json, current_type_name), wire_json, field_descriptor, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), field_json_value);
+
+			IF map_key IS NOT NULL THEN
+				SET result = JSON_EXTRACT(field_json_value, CONCAT('$.', JSON_QUOTE(map_key)));
+			ELSEIF repeated_index IS NOT NULL THEN
+				IF repeated_index < 0 THEN
+					SET repeated_index = JSON_LENGTH(field_json_value) + repeated_index;
+				END IF;
+				IF repeated_index < 0 THEN
+					SET result = NULL;
+				ELSE
+					SET result = JSON_EXTRACT(field_json_value, CONCAT('$[', repeated_index, ']'));
+				END IF;
+			ELSE
+				SET result = field_json_value;
+			END IF;
+			LEAVE proc;
+		END IF;
+
+		-- Intermediate segment: descend into a nested message
+		IF is_map THEN
+			SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+			SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]');
+			IF map_key IS NULL OR JSON_EXTRACT(map_value_field, '$."5"') <> 11 THEN
+				SET message_text = CONCAT('pb_message_get_json_by_path: cannot descend into map field `', field_token, '` in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET found_entry = _pb_wire_json_get_map_entry(wire_json, field_number, map_key_type, map_key);
+			IF found_entry IS NULL THEN
+				SET result = NULL;
+				LEAVE proc;
+			END IF;
+
+			SET current_message = pb_message_get_message_field(found_entry, 2, _binary '');
+			SET current_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
+		ELSEIF field_type <> 11 THEN
+			SET message_text = CONCAT('pb_message_get_json_by_path: field `', field_token, '` is not a message field in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		ELSEIF is_repeated THEN
+			IF repeated_index IS NULL THEN
+				SET message_text = CONCAT('pb_message_get_json_by_path: repeated field `', field_token, '` requires an index in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET entry_count = pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
+			IF repeated_index < 0 THEN
+				SET repeated_index = entry_count + repeated_index;
+			END IF;
+			IF repeated_index < 0 OR repeated_index >= entry_count THEN
+				SET result = NULL;
+				LEAVE proc;
+			END IF;
+
+			SET current_message = pb_wire_json_get_repeated_message_field_element(wire_json, field_number, repeated_index);
+			SET current_type_name = field_type_name;
+		ELSE
+			-- An absent message is read as an empty message so that default values are returned
+			SET current_message = pb_wire_json_get_message_field(wire_json, field_number, _binary '');
+			SET current_type_name = field_type_name;
+		END IF;
+
+		SET segment_index = segment_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_get_json_by_path $$
+CREATE FUNCTION pb_message_get_json_by_path(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, path TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_get_json_by_path(descriptor_set_json, type_name, message, path, result);
+	RETURN result;
+END $$
+
+-- Helper function to decode C-style escape sequences used by FieldDescriptorProto.default_value of bytes fields
+-- and by string literals in the protobuf text format
+DROP FUNCTION IF EXISTS _pb_util_c_unescape $$
+CREATE FUNCTION _pb_util_c_unescape(s LONGTEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB DEFAULT _binary '';
+	DECLARE i INT DEFAULT 1;
+	DECLARE c TEXT;
+	DECLARE digits TEXT;
+	DECLARE code_point INT;
+	DECLARE low_surrogate INT;
+
+	IF s IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	WHILE i <= CHAR_LENGTH(s) DO
+		SET c = SUBSTRING(s, i, 1);
+		IF c = '\\' AND i < CHAR_LENGTH(s) THEN
+			SET i = i + 1;
+			SET c = SUBSTRING(s, i, 1);
+			-- Escape letters are case-sensitive, so compare them in binary rather than with the case-insensitive collation
+			CASE
+			WHEN BINARY c = 'n' THEN SET result = CONCAT(result, _binary X'0A');
+			WHEN BINARY c = 'r' THEN SET result = CONCAT(result, _binary X'0D');
+			WHEN BINARY c = 't' THEN SET result = CONCAT(result, _binary X'09');
+			WHEN BINARY c = 'a' THEN SET result = CONCAT(result, _binary X'07');
+			WHEN BINARY c = 'b' THEN SET result = CONCAT(result, _binary X'08');
+			WHEN BINARY c = 'f' THEN SET result = CONCAT(result, _binary X'0C');
+			WHEN BINARY c = 'v' THEN SET result = CONCAT(result, _binary X'0B');
+			WHEN BINARY c IN ('u', 'U') THEN
+				SET digits = REGEXP_SUBSTR(SUBSTRING(s, i + 1, IF(BINARY c = 'u', 4, 8)), '^[0-9A-Fa-f]+');
+				SET code_point = CONV(digits, 16, 10);
+				SET i = i + CHAR_LENGTH(digits);
+				-- A high surrogate is combined with the following \uXXXX low surrogate
+				IF code_point BETWEEN 0xD800 AND 0xDBFF AND BINARY SUBSTRING(s, i + 1, 2) = '\\u' THEN
+					SET low_surrogate = CONV(REGEXP_SUBSTR(SUBSTRING(s, i + 3, 4), '^[0-9A-Fa-f]{4}'), 16, 10);
+					IF low_surrogate BETWEEN 0xDC00 AND 0xDFFF THEN
+						SET code_point = 0x10000 + ((code_point - 0xD800) << 10) + (low_surrogate - 0xDC00);
+						SET i = i + 6;
+					END IF;
+				END IF;
+				SET result = CONCAT(result, COALESCE(CAST(CONVERT(CHAR(code_point USING utf32) USING utf8mb4) AS BINARY), _binary ''));
+			WHEN BINARY c = 'x' THEN
+				SET digits = REGEXP_SUBSTR(SUBSTRING(s, i + 1, 2), '^[0-9A-Fa-f]+');
+				SET result = CONCAT(result, UNHEX(LPAD(digits, 2, '0')));
+				SET i = i + CHAR_LENGTH(digits);
+			WHEN c BETWEEN '0' AND '7' THEN
+				SET digits = REGEXP_SUBSTR(SUBSTRING(s, i, 3), '^[0-7]+');
+				SET result = CONCAT(result, UNHEX(LPAD(CONV(digits, 8, 16), 2, '0')));
+				SET i = i + CHAR_LENGTH(digits) - 1;
+			ELSE
+				SET result = CONCAT(result, CAST(c AS BINARY));
+			END CASE;
+		ELSE
+			SET result = CONCAT(result, CAST(c AS BINARY));
+		END IF;
+		SET i = i + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper procedure to get the JSON representation of the default value of a field
+DROP PROCEDURE IF EXISTS _pb_get_field_default_as_json $$
+CREATE PROCEDURE _pb_get_field_default_as_json(IN descriptor_set_json JSON, IN field_descriptor JSON, OUT result JSON)
+BEGIN
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE default_value TEXT;
+
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET default_value = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."7"')); -- default_value
+
+	CASE
+	WHEN field_type = 11 THEN -- message
+		CALL _pb_message_to_json(descriptor_set_json, field_type_name, _binary '', FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	WHEN field_type = 14 THEN -- enum
+		IF default_value IS NULL THEN
+			-- The first value of the enum is the default
+			SET default_value = JSON_UNQUOTE(JSON_EXTRACT(_pb_get_enum_descriptor(descriptor_set_json, field_type_name), '$."2"[0]."1"'));
+		END IF;
+		SET result = JSON_QUOTE(default_value);
+	WHEN field_type IN (1, 2) THEN -- double, float
+		CASE default_value
+		WHEN 'inf' THEN SET result = JSON_QUOTE('Infinity');
+		WHEN '-inf' THEN SET result = JSON_QUOTE('-Infinity');
+		WHEN 'nan' THEN SET result = JSON_QUOTE('NaN');
+		ELSE SET result = CAST(CAST(COALESCE(default_value, '0') AS DOUBLE) AS JSON);
+		END CASE;
+	WHEN field_type IN (3, 4, 6, 16, 18) THEN -- 64-bit integers
+		SET result = JSON_QUOTE(COALESCE(default_value, '0'));
+	WHEN field_type IN (5, 7, 13, 15, 17) THEN -- 32-bit integers
+		SET result = CAST(COALESCE(default_value, '0') AS JSON);
+	WHEN field_type = 8 THEN -- bool
+		SET result = CAST(((default_value = 'true') IS TRUE) AS JSON);
+	WHEN field_type = 9 THEN -- string
+		SET result = JSON_QUOTE(COALESCE(default_value, ''));
+	WHEN field_type = 12 THEN -- bytes
+		SET result = JSON_QUOTE(TO_BASE64(COALESCE(_pb_util_c_unescape(default_value), _binary '')));
+	ELSE
+		SET result = NULL;
+	END CASE;
+END $$
+
+-- Helper function to convert an enum value given as a JSON name or number into its number
+DROP FUNCTION IF EXISTS _pb_enum_from_json $$
+CREATE FUNCTION _pb_enum_from_json(descriptor_set_json JSON, full_type_name TEXT, json_value JSON) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE enum_name TEXT;
+	DECLARE enum_number JSON;
+
+	IF JSON_TYPE(json_value) <> 'STRING' THEN
+		RETURN CAST(json_value AS SIGNED);
+	END IF;
+
+	SET enum_name = JSON_UNQUOTE(json_value);
+	SET enum_number = JSON_EXTRACT(_pb_get_enum_map(descriptor_set_json, full_type_name), CONCAT('$.v.', JSON_QUOTE(enum_name)));
+	IF enum_number IS NOT NULL THEN
+		RETURN enum_number;
+	END IF;
+
+	SET message_text = CONCAT('_pb_enum_from_json: unknown value `', enum_name, '` for enum `', full_type_name, '`');
+	SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+END $$
+
+-- Helper function to determine whether a repeated field is written in packed encoding
+DROP FUNCTION IF EXISTS _pb_is_field_packed $$
+CREATE FUNCTION _pb_is_field_packed(syntax TEXT, field_descriptor JSON) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE field_type INT;
+	DECLARE packed_option JSON;
+
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	IF field_type IN (9, 10, 11, 12) THEN -- string, group, message, bytes
+		RETURN FALSE;
+	END IF;
+
+	SET packed_option = JSON_EXTRACT(field_descriptor, '$."8"."2"'); -- options.packed
+	IF packed_option IS NOT NULL THEN
+		RETURN packed_option = CAST('true' AS JSON);
+	END IF;
+
+	-- Scalar numeric fields are packed by default since proto3
+	RETURN syntax <> 'proto2';
+END $$
+
+-- Helper function to set a singular field value given as JSON
+DROP FUNCTION IF EXISTS _pb_message_set_singular_field_from_json $$
+CREATE FUNCTION _pb_message_set_singular_field_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE text_value LONGTEXT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET text_value = JSON_UNQUOTE(json_value);
+
+	IF field_type IN (9, 11, 12) AND JSON_TYPE(json_value) <> 'STRING' THEN
+		SET message_text = CONCAT('pb_message_set_field: expected a JSON string for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '`, but got ', JSON_TYPE(json_value));
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_set_double_field(message, field_number, CAST(text_value AS DOUBLE));
+	WHEN 2 THEN RETURN pb_message_set_float_field(message, field_number, CAST(text_value AS FLOAT));
+	WHEN 3 THEN RETURN pb_message_set_int64_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 4 THEN RETURN pb_message_set_uint64_field(message, field_number, CAST(text_value AS UNSIGNED));
+	WHEN 5 THEN RETURN pb_message_set_int32_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 6 THEN RETURN pb_message_set_fixed64_field(message, field_number, CAST(text_value AS UNSIGNED));
+	WHEN 7 THEN RETURN pb_message_set_fixed32_field(message, field_number, CAST(text_value AS UNSIGNED));
+	WHEN 8 THEN RETURN pb_message_set_bool_field(message, field_number, text_value = 'true' OR (text_value <> 'false' AND CAST(text_value AS SIGNED) <> 0));
+	WHEN 9 THEN RETURN pb_message_set_string_field(message, field_number, text_value);
+	WHEN 11 THEN RETURN pb_message_set_message_field(message, field_number, FROM_BASE64(text_value));
+	WHEN 12 THEN RETURN pb_message_set_bytes_field(message, field_number, FROM_BASE64(text_value));
+	WHEN 13 THEN RETURN pb_message_set_uint32_field(message, field_number, CAST(text_value AS UNSIGNED));
+	WHEN 14 THEN RETURN pb_message_set_enum_field(message, field_number, _pb_enum_from_json(descriptor_set_json, field_type_name, json_value));
+	WHEN 15 THEN RETURN pb_message_set_sfixed32_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 16 THEN RETURN pb_message_set_sfixed64_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 17 THEN RETURN pb_message_set_sint32_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 18 THEN RETURN pb_message_set_sint64_field(message, field_number, CAST(text_value AS SIGNED));
+	ELSE
+		SET message_text = CONCAT('pb_message_set_field: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to set a repeated (non-map) field from a JSON array
+DROP FUNCTION IF EXISTS _pb_message_set_repeated_field_from_json $$
+CREATE FUNCTION _pb_message_set_repeated_field_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, use_packed BOOLEAN, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE enum_numbers JSON;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_set_repeated_double_field(message, field_number, json_value, use_packed);
+	WHEN 2 THEN RETURN pb_message_set_repeated_float_field(message, field_number, json_value, use_packed);
+	WHEN 3 THEN RETURN pb_message_set_repeated_int64_field(message, field_number, json_value, use_packed);
+	WHEN 4 THEN RETURN pb_message_set_repeated_uint64_field(message, field_number, json_value, use_packed);
+	WHEN 5 THEN RETURN pb_message_set_repeated_int32_field(message, field_number, json_value, use_packed);
+	WHEN 6 THEN RETURN pb_message_set_repeated_fixed64_field(message, field_number, json_value, use_packed);
+	WHEN 7 THEN RETURN pb_message_set_repeated_fixed32_field(message, field_number, json_value, use_packed);
+	WHEN 8 THEN RETURN pb_message_set_repeated_bool_field(message, field_number, json_value, use_packed);
+	WHEN 9 THEN RETURN pb_message_set_repeated_string_field(message, field_number, json_value);
+	WHEN 11 THEN RETURN pb_message_set_repeated_message_field(message, field_number, json_value);
+	WHEN 12 THEN RETURN pb_message_set_repeated_bytes_field(message, field_number, json_value);
+	WHEN 13 THEN RETURN pb_message_set_repeated_uint32_field(message, field_number, json_value, use_packed);
+	WHEN 14 THEN
+		-- Enum values may be given by name
+		SET enum_numbers = JSON_ARRAY();
+		SET element_count = JSON_LENGTH(json_value);
+		SET element_index = 0;
+		WHILE element_index < element_count DO
+			SET enum_numbers = JSON_ARRAY_APPEND(enum_numbers, '$', _pb_enum_from_json(descriptor_set_json, field_type_name, JSON_EXTRACT(json_value, CONCAT('$[', element_index, ']'))));
+			SET element_index = element_index + 1;
+		END WHILE;
+		RETURN pb_message_set_repeated_enum_field(message, field_number, enum_numbers, use_packed);
+	WHEN 15 THEN RETURN pb_message_set_repeated_sfixed32_field(message, field_number, json_value, use_packed);
+	WHEN 16 THEN RETURN pb_message_set_repeated_sfixed64_field(message, field_number, json_value, use_packed);
+	WHEN 17 THEN RETURN pb_message_set_repeated_sint32_field(message, field_number, json_value, use_packed);
+	WHEN 18 THEN RETURN pb_message_set_repeated_sint64_field(message, field_number, json_value, use_packed);
+	ELSE
+		SET message_text = CONCAT('pb_message_set_field: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to build a map entry message from a key given as text and a value given as JSON
+DROP FUNCTION IF EXISTS _pb_message_new_map_entry_from_json $$
+CREATE FUNCTION _pb_message_new_map_entry_from_json(descriptor_set_json JSON, map_entry_descriptor JSON, map_key TEXT, value_json JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE map_key_field JSON;
+	DECLARE map_key_json JSON;
+	DECLARE entry LONGBLOB;
+
+	SET map_key_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]');
+	IF JSON_EXTRACT(map_key_field, '$."5"') = 8 THEN -- bool
+		SET map_key_json = CAST(((map_key = 'true') IS TRUE) AS JSON);
+	ELSE
+		SET map_key_json = JSON_QUOTE(map_key);
+	END IF;
+
+	SET entry = _pb_message_set_singular_field_from_json(descriptor_set_json, pb_message_new(), map_key_field, map_key_json);
+	IF value_json IS NOT NULL THEN
+		SET entry = _pb_message_set_singular_field_from_json(descriptor_set_json, entry, JSON_EXTRACT(map_entry_descriptor, '$."2"[1]'), value_json);
+	END IF;
+
+	RETURN entry;
+END $$
+
+-- Helper function to set a map field from a JSON object
+DROP FUNCTION IF EXISTS _pb_message_set_map_field_from_json $$
+CREATE FUNCTION _pb_message_set_map_field_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE field_number INT;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE map_keys JSON;
+	DECLARE map_key TEXT;
+	DECLARE key_count INT;
+	DECLARE key_index INT;
+	DECLARE entry LONGBLOB;
+	DECLARE result LONGBLOB;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')));
+
+	SET result = pb_wire_json_to_message(_pb_wire_json_clear_field(pb_message_to_wire_json(message), field_number));
+	SET map_keys = JSON_KEYS(json_value);
+	SET key_count = JSON_LENGTH(map_keys);
+	SET key_index = 0;
+
+	WHILE key_index < key_count DO
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(map_keys, CONCAT('$[', key_index, ']')));
+		SET entry = _pb_message_new_map_entry_from_json(descriptor_set_json, map_entry_descriptor, map_key, JSON_EXTRACT(json_value, CONCAT('$.', JSON_QUOTE(map_key))));
+		SET result = pb_message_add_repeated_message_field_element(result, field_number, entry);
+		SET key_index = key_index + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper function to look up a field descriptor by name, signaling an error if it does not exist
+DROP FUNCTION IF EXISTS _pb_get_field_descriptor_by_type_and_name $$
+CREATE FUNCTION _pb_get_field_descriptor_by_type_and_name(descriptor_set_json JSON, type_name TEXT, field_name TEXT, func_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE field_descriptor JSON;
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', func_name, CONCAT('message type `', type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET field_descriptor = _pb_get_field_descriptor_by_name(message_descriptor, field_name);
+	IF field_descriptor IS NULL THEN
+		SET message_text = CONCAT(func_name, ': field `', field_name, '` not found in message type `', type_name, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	RETURN field_descriptor;
+END $$
+
+-- Procedure for reading a single field by name as JSON, with default values applied
+DROP PROCEDURE IF EXISTS _pb_message_get_field $$
+CREATE PROCEDURE _pb_message_get_field(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN field_name TEXT, OUT result JSON)
+proc: BEGIN
+	DECLARE field_descriptor JSON;
+
+	IF message IS NULL THEN
+		SET result = NULL;
+		LEAVE proc;
+	END IF;
+
+	SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, full_type_name, field_name, 'pb_message_get_field');
+
+	CALL _pb_wire_json_get_field_as_json(descriptor_set_json, _pb_get_file_syntax(descriptor_set_json, full_type_name), _pb_get_utf8_validation(descriptor_set_json, full_type_name), pb_message_to_wire_json(message), field_descriptor, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+
+	IF result IS NULL THEN
+		CALL _pb_get_field_default_as_json(descriptor_set_json, field_descriptor, result);
+	END IF;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_get_field $$
+CREATE FUNCTION pb_message_get_field(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, field_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_get_field(descriptor_set_json, type_name, message, field_name, result);
+	RETURN result;
+END $$
+
+-- Sets a single field by name from a JSON value. A JSON null clears the field.
+DROP FUNCTION IF EXISTS pb_message_set_field $$
+CREATE FUNCTION pb_message_set_field(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, field_name TEXT, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE is_map BOOLEAN;
+	DECLARE wire_json JSON;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, type_name, field_name, 'pb_message_set_field');
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+	SET wire_json = _pb_wire_json_clear_field(pb_message_to_wire_json(message), field_number);
+
+	IF json_value IS NULL OR JSON_TYPE(json_value) = 'NULL' THEN
+		RETURN pb_wire_json_to_message(wire_json);
+	END IF;
+
+	-- Setting a member of a oneof clears the other members
+	SET wire_json = _pb_wire_json_clear_oneof_members(descriptor_set_json, type_name, wire_json, field_descriptor);
+
+	SET message = pb_wire_json_to_message(wire_json);
+
+	IF field_label = 3 THEN -- LABEL_REPEATED
+		SET is_map = field_type = 11 AND COALESCE(CAST(JSON_EXTRACT(_pb_get_message_descriptor(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"'))), '$."7"."7"') AS UNSIGNED), FALSE);
+		IF is_map THEN
+			IF JSON_TYPE(json_value) <> 'OBJECT' THEN
+				SET message_text = CONCAT('pb_message_set_field: expected a JSON object for map field `', field_name, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			RETURN _pb_message_set_map_field_from_json(descriptor_set_json, message, field_descriptor, json_value);
+		END IF;
+
+		IF JSON_TYPE(json_value) <> 'ARRAY' THEN
+			SET message_text = CONCAT('pb_message_set_field: expected a JSON array for repeated field `', field_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+		RETURN _pb_message_set_repeated_field_from_json(descriptor_set_json, message, field_descriptor, _pb_is_field_packed(_pb_get_file_syntax(descriptor_set_json, type_name), field_descriptor), json_value);
+	END IF;
+
+	RETURN _pb_message_set_singular_field_from_json(descriptor_set_json, message, field_descriptor, json_value);
+END $$
+
+-- Helper function to find the positions of map entries with the given key within the wire JSON elements of a map field
+DROP FUNCTION IF EXISTS _pb_wire_json_find_map_entries $$
+CREATE FUNCTION _pb_wire_json_find_map_entries(wire_json JSON, field_number INT, map_key_type INT, map_key TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE entry_wire_json JSON;
+	DECLARE map_key_json JSON;
+	DECLARE positions JSON;
+
+	SET positions = JSON_ARRAY();
+	SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET element_count = COALESCE(JSON_LENGTH(elements), 0);
+	SET element_index = 0;
+
+	WHILE element_index < element_count DO
+		SET entry_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, '].v')))));
+		CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 1, map_key_type, FALSE, FALSE, FALSE, map_key_json);
+		IF BINARY JSON_UNQUOTE(map_key_json) = BINARY map_key THEN
+			SET positions = JSON_ARRAY_APPEND(positions, '$', element_index);
+		END IF;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN positions;
+END $$
+
+-- Helper function to get the map entry with the given key. Later entries win, as in the wire format.
+DROP FUNCTION IF EXISTS _pb_wire_json_get_map_entry $$
+CREATE FUNCTION _pb_wire_json_get_map_entry(wire_json JSON, field_number INT, map_key_type INT, map_key TEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE positions JSON;
+
+	SET positions = _pb_wire_json_find_map_entries(wire_json, field_number, map_key_type, map_key);
+	IF JSON_LENGTH(positions) = 0 THEN
+		RETURN NULL;
+	END IF;
+
+	RETURN FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"[', JSON_EXTRACT(positions, '$[last]'), '].v'))));
+END $$
+
+-- Helper function to replace (or remove, if entry is NULL) the map entry with the given key.
+-- The last existing entry is replaced in place and duplicates are removed. A new entry is appended if none exists.
+DROP FUNCTION IF EXISTS _pb_wire_json_set_map_entry $$
+CREATE FUNCTION _pb_wire_json_set_map_entry(wire_json JSON, field_number INT, map_key_type INT, map_key TEXT, entry LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE positions JSON;
+	DECLARE position_index INT;
+
+	SET positions = _pb_wire_json_find_map_entries(wire_json, field_number, map_key_type, map_key);
+	SET position_index = JSON_LENGTH(positions) - 1;
+
+	IF entry IS NOT NULL THEN
+		IF position_index < 0 THEN
+			RETURN pb_wire_json_add_repeated_message_field_element(wire_json, field_number, entry);
+		END IF;
+		SET wire_json = JSON_SET(wire_json, CONCAT(field_path, '[', JSON_EXTRACT(positions, CONCAT('$[', position_index, ']')), '].v'), TO_BASE64(entry));
+		SET position_index = position_index - 1;
+	END IF;
+
+	-- Remove from the end so that the remaining positions stay valid
+	WHILE position_index >= 0 DO
+		SET wire_json = JSON_REMOVE(wire_json, CONCAT(field_path, '[', JSON_EXTRACT(positions, CONCAT('$[', position_index, ']')), ']'));
+		SET position_index = position_index - 1;
+	END WHILE;
+
+	IF JSON_LENGTH(wire_json, field_path) = 0 THEN
+		SET wire_json = JSON_REMOVE(wire_json, field_path);
+	END IF;
+
+	RETURN wire_json;
+END $$
+
+-- Helper function to replace the value of a singular length-delimited field, keeping its position in the message
+DROP FUNCTION IF EXISTS _pb_wire_json_replace_len_field $$
+CREATE FUNCTION _pb_wire_json_replace_len_field(wire_json JSON, field_number INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE element_index INT;
+
+	SET element_index = JSON_LENGTH(wire_json, field_path) - 1;
+	IF element_index IS NULL OR element_index < 0 THEN
+		RETURN _pb_wire_json_set_len_field(wire_json, field_number, value);
+	END IF;
+
+	-- The last occurrence is replaced in place and the earlier ones are removed
+	SET wire_json = JSON_SET(wire_json, CONCAT(field_path, '[', element_index, '].v'), TO_BASE64(value));
+	SET element_index = element_index - 1;
+	WHILE element_index >= 0 DO
+		SET wire_json = JSON_REMOVE(wire_json, CONCAT(field_path, '[', element_index, ']'));
+		SET element_index = element_index - 1;
+	END WHILE;
+
+	RETURN wire_json;
+END $$
+
+-- Helper function to get a singular message field with all of its occurrences merged.
+-- Concatenating serialized messages is equivalent to merging them, so this returns NULL if the field is absent.
+DROP FUNCTION IF EXISTS _pb_wire_json_get_merged_message_field $$
+CREATE FUNCTION _pb_wire_json_get_merged_message_field(wire_json JSON, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE result LONGBLOB;
+
+	SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET element_count = COALESCE(JSON_LENGTH(elements), 0);
+
+	WHILE element_index < element_count DO
+		IF JSON_EXTRACT(elements, CONCAT('$[', element_index, '].t')) = 2 THEN -- LEN
+			SET result = CONCAT(COALESCE(result, _binary ''), FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, '].v')))));
+		END IF;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper function to append wire elements (e.g. taken from another message) to a field, renumbering them to come last
+DROP FUNCTION IF EXISTS _pb_wire_json_append_elements $$
+CREATE FUNCTION _pb_wire_json_append_elements(wire_json JSON, field_number INT, elements JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE next_index INT;
+
+	SET element_count = COALESCE(JSON_LENGTH(elements), 0);
+	IF element_count = 0 THEN
+		RETURN wire_json;
+	END IF;
+
+	SET next_index = _pb_wire_json_get_next_index(wire_json);
+	IF NOT JSON_CONTAINS_PATH(wire_json, 'one', field_path) THEN
+		SET wire_json = JSON_SET(wire_json, field_path, JSON_ARRAY());
+	END IF;
+
+	WHILE element_index < element_count DO
+		SET wire_json = JSON_ARRAY_APPEND(wire_json, field_path, JSON_SET(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')), '$.i', next_index, '$.n', field_number));
+		SET next_index = next_index + 1;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN wire_json;
+END $$
+
+-- Helper function to clear all members of the oneof that the given field belongs to. Does nothing for other fields.
+DROP FUNCTION IF EXISTS _pb_wire_json_clear_oneof_members $$
+CREATE FUNCTION _pb_wire_json_clear_oneof_members(descriptor_set_json JSON, type_name TEXT, wire_json JSON, field_descriptor JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE oneof_index INT;
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+
+	SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+	IF oneof_index IS NULL OR COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE) THEN -- proto3_optional
+		RETURN wire_json;
+	END IF;
+
+	SET fields = JSON_EXTRACT(_pb_get_message_descriptor(descriptor_set_json, type_name), '$."2"');
+	SET field_count = JSON_LENGTH(fields);
+	WHILE field_index < field_count DO
+		IF JSON_EXTRACT(fields, CONCAT('$[', field_index, ']."9"')) = oneof_index THEN
+			SET wire_json = _pb_wire_json_clear_field(wire_json, JSON_EXTRACT(fields, CONCAT('$[', field_index, ']."3"')));
+		END IF;
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	RETURN wire_json;
+END $$
+
+-- Helper function to count the elements of a repeated field using its field descriptor
+DROP FUNCTION IF EXISTS _pb_message_get_repeated_field_count $$
+CREATE FUNCTION _pb_message_get_repeated_field_count(message LONGBLOB, field_descriptor JSON) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_get_repeated_double_field_count(message, field_number);
+	WHEN 2 THEN RETURN pb_message_get_repeated_float_field_count(message, field_number);
+	WHEN 3 THEN RETURN pb_message_get_repeated_int64_field_count(message, field_number);
+	WHEN 4 THEN RETURN pb_message_get_repeated_uint64_field_count(message, field_number);
+	WHEN 5 THEN RETURN pb_message_get_repeated_int32_field_count(message, field_number);
+	WHEN 6 THEN RETURN pb_message_get_repeated_fixed64_field_count(message, field_number);
+	WHEN 7 THEN RETURN pb_message_get_repeated_fixed32_field_count(message, field_number);
+	WHEN 8 THEN RETURN pb_message_get_repeated_bool_field_count(message, field_number);
+	WHEN 9 THEN RETURN pb_message_get_repeated_string_field_count(message, field_number);
+	WHEN 11 THEN RETURN pb_message_get_repeated_message_field_count(message, field_number);
+	WHEN 12 THEN RETURN pb_message_get_repeated_bytes_field_count(message, field_number);
+	WHEN 13 THEN RETURN pb_message_get_repeated_uint32_field_count(message, field_number);
+	WHEN 14 THEN RETURN pb_message_get_repeated_enum_field_count(message, field_number);
+	WHEN 15 THEN RETURN pb_message_get_repeated_sfixed32_field_count(message, field_number);
+	WHEN 16 THEN RETURN pb_message_get_repeated_sfixed64_field_count(message, field_number);
+	WHEN 17 THEN RETURN pb_message_get_repeated_sint32_field_count(message, field_number);
+	WHEN 18 THEN RETURN pb_message_get_repeated_sint64_field_count(message, field_number);
+	ELSE
+		SET message_text = CONCAT('_pb_message_get_repeated_field_count: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to set an element of a repeated field from a JSON value
+DROP FUNCTION IF EXISTS _pb_message_set_repeated_field_element_from_json $$
+CREATE FUNCTION _pb_message_set_repeated_field_element_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, repeated_index INT, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE text_value LONGTEXT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET text_value = JSON_UNQUOTE(json_value);
+
+	IF field_type IN (9, 11, 12) AND JSON_TYPE(json_value) <> 'STRING' THEN
+		SET message_text = CONCAT('pb_message_set_by_path: expected a JSON string for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '`, but got ', JSON_TYPE(json_value));
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_set_repeated_double_field_element(message, field_number, repeated_index, CAST(text_value AS DOUBLE));
+	WHEN 2 THEN RETURN pb_message_set_repeated_float_field_element(message, field_number, repeated_index, CAST(text_value AS FLOAT));
+	WHEN 3 THEN RETURN pb_message_set_repeated_int64_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 4 THEN RETURN pb_message_set_repeated_uint64_field_element(message, field_number, repeated_index, CAST(text_value AS UNSIGNED));
+	WHEN 5 THEN RETURN pb_message_set_repeated_int32_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 6 THEN RETURN pb_message_set_repeated_fixed64_field_element(message, field_number, repeated_index, CAST(text_value AS UNSIGNED));
+	WHEN 7 THEN RETURN pb_message_set_repeated_fixed32_field_element(message, field_number, repeated_index, CAST(text_value AS UNSIGNED));
+	WHEN 8 THEN RETURN pb_message_set_repeated_bool_field_element(message, field_number, repeated_index, text_value = 'true' OR (text_value <> 'false' AND CAST(text_value AS SIGNED) <> 0));
+	WHEN 9 THEN RETURN pb_message_set_repeated_string_field_element(message, field_number, repeated_index, text_value);
+	WHEN 11 THEN RETURN pb_message_set_repeated_message_field_element(message, field_number, repeated_index, FROM_BASE64(text_value));
+	WHEN 12 THEN RETURN pb_message_set_repeated_bytes_field_element(message, field_number, repeated_index, FROM_BASE64(text_value));
+	WHEN 13 THEN RETURN pb_message_set_repeated_uint32_field_element(message, field_number, repeated_index, CAST(text_value AS UNSIGNED));
+	WHEN 14 THEN RETURN pb_message_set_repeated_enum_field_element(message, field_number, repeated_index, _pb_enum_from_json(descriptor_set_json, field_type_name, json_value));
+	WHEN 15 THEN RETURN pb_message_set_repeated_sfixed32_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 16 THEN RETURN pb_message_set_repeated_sfixed64_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 17 THEN RETURN pb_message_set_repeated_sint32_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 18 THEN RETURN pb_message_set_repeated_sint64_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	ELSE
+		SET message_text = CONCAT('pb_message_set_by_path: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to append an element to a repeated field from a JSON value
+DROP FUNCTION IF EXISTS _pb_message_add_repeated_field_element_from_json $$
+CREATE FUNCTION _pb_message_add_repeated_field_element_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, use_packed BOOLEAN, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE text_value LONGTEXT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET text_value = JSON_UNQUOTE(json_value);
+
+	IF field_type IN (9, 11, 12) AND JSON_TYPE(json_value) <> 'STRING' THEN
+		SET message_text = CONCAT('pb_message_patch: expected a JSON string for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '`, but got ', JSON_TYPE(json_value));
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_add_repeated_double_field_element(message, field_number, CAST(text_value AS DOUBLE), use_packed);
+	WHEN 2 THEN RETURN pb_message_add_repeated_float_field_element(message, field_number, CAST(text_value AS FLOAT), use_packed);
+	WHEN 3 THEN RETURN pb_message_add_repeated_int64_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 4 THEN RETURN pb_message_add_repeated_uint64_field_element(message, field_number, CAST(text_value AS UNSIGNED), use_packed);
+	WHEN 5 THEN RETURN pb_message_add_repeated_int32_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 6 THEN RETURN pb_message_add_repeated_fixed64_field_element(message, field_number, CAST(text_value AS UNSIGNED), use_packed);
+	WHEN 7 THEN RETURN pb_message_add_repeated_fixed32_field_element(message, field_number, CAST(text_value AS UNSIGNED), use_packed);
+	WHEN 8 THEN RETURN pb_message_add_repeated_bool_field_element(message, field_number, text_value = 'true' OR (text_value <> 'false' AND CAST(text_value AS SIGNED) <> 0), use_packed);
+	WHEN 9 THEN RETURN pb_message_add_repeated_string_field_element(message, field_number, text_value);
+	WHEN 11 THEN RETURN pb_message_add_repeated_message_field_element(message, field_number, FROM_BASE64(text_value));
+	WHEN 12 THEN RETURN pb_message_add_repeated_bytes_field_element(message, field_number, FROM_BASE64(text_value));
+	WHEN 13 THEN RETURN pb_message_add_repeated_uint32_field_element(message, field_number, CAST(text_value AS UNSIGNED), use_packed);
+	WHEN 14 THEN RETURN pb_message_add_repeated_enum_field_element(message, field_number, _pb_enum_from_json(descriptor_set_json, field_type_name, json_value), use_packed);
+	WHEN 15 THEN RETURN pb_message_add_repeated_sfixed32_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 16 THEN RETURN pb_message_add_repeated_sfixed64_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 17 THEN RETURN pb_message_add_repeated_sint32_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 18 THEN RETURN pb_message_add_repeated_sint64_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	ELSE
+		SET message_text = CONCAT('pb_message_patch: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to remove an element of a repeated field
+DROP FUNCTION IF EXISTS _pb_message_remove_repeated_field_element $$
+CREATE FUNCTION _pb_message_remove_repeated_field_element(message LONGBLOB, field_descriptor JSON, repeated_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_remove_repeated_double_field_element(message, field_number, repeated_index);
+	WHEN 2 THEN RETURN pb_message_remove_repeated_float_field_element(message, field_number, repeated_index);
+	WHEN 3 THEN RETURN pb_message_remove_repeated_int64_field_element(message, field_number, repeated_index);
+	WHEN 4 THEN RETURN pb_message_remove_repeated_uint64_field_element(message, field_number, repeated_index);
+	WHEN 5 THEN RETURN pb_message_remove_repeated_int32_field_element(message, field_number, repeated_index);
+	WHEN 6 THEN RETURN pb_message_remove_repeated_fixed64_field_element(message, field_number, repeated_index);
+	WHEN 7 THEN RETURN pb_message_remove_repeated_fixed32_field_element(message, field_number, repeated_index);
+	WHEN 8 THEN RETURN pb_message_remove_repeated_bool_field_element(message, field_number, repeated_index);
+	WHEN 9 THEN RETURN pb_message_remove_repeated_string_field_element(message, field_number, repeated_index);
+	WHEN 11 THEN RETURN pb_message_remove_repeated_message_field_element(message, field_number, repeated_index);
+	WHEN 12 THEN RETURN pb_message_remove_repeated_bytes_field_element(message, field_number, repeated_index);
+	WHEN 13 THEN RETURN pb_message_remove_repeated_uint32_field_element(message, field_number, repeated_index);
+	WHEN 14 THEN RETURN pb_message_remove_repeated_enum_field_element(message, field_number, repeated_index);
+	WHEN 15 THEN RETURN pb_message_remove_repeated_sfixed32_field_element(message, field_number, repeated_index);
+	WHEN 16 THEN RETURN pb_message_remove_repeated_sfixed64_field_element(message, field_number, repeated_index);
+	WHEN 17 THEN RETURN pb_message_remove_repeated_sint32_field_element(message, field_number, repeated_index);
+	WHEN 18 THEN RETURN pb_message_remove_repeated_sint64_field_element(message, field_number, repeated_index);
+	ELSE
+		SET message_text = CONCAT('pb_message_clear_by_path: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper procedure to allow the recursive CALLs that schema-aware functions use for nested messages. Returns the caller's
+-- max_sp_recursion_depth, which must be put back with _pb_restore_recursion_depth, also when an error is raised.
+DROP PROCEDURE IF EXISTS _pb_raise_recursion_depth $$
+CREATE PROCEDURE _pb_raise_recursion_depth(OUT saved_recursion_depth INT)
+BEGIN
+	SET saved_recursion_depth = @@SESSION.max_sp_recursion_depth;
+	SET @@SESSION.max_sp_recursion_depth = 255;
+END $$
+
+-- Helper procedure to put back the max_sp_recursion_depth returned by _pb_raise_recursion_depth.
+-- Does nothing if it was not raised yet.
+DROP PROCEDURE IF EXISTS _pb_restore_recursion_depth $$
+CREATE PROCEDURE _pb_restore_recursion_depth(IN saved_recursion_depth INT)
+BEGIN
+	IF saved_recursion_depth IS NOT NULL THEN
+		SET @@SESSION.max_sp_recursion_depth = saved_recursion_depth;
+	END IF;
+END $$
+
+-- Helper procedure to limit the nesting depth of messages handled by recursive CALLs, where the top-level message is at
+-- depth 1. The limit is the default MaxDepth of pb_message_to_json.
+DROP PROCEDURE IF EXISTS _pb_check_message_depth $$
+CREATE PROCEDURE _pb_check_message_depth(IN message_depth INT, IN function_name TEXT)
+BEGIN
+	IF message_depth > 100 THEN
+		CALL _pb_signal_error('PB_DEPTH_LIMIT_EXCEEDED', function_name, 'message nesting depth exceeds 100');
+	END IF;
+END $$
+
+-- Recursive procedure for setting or clearing the value at a field name path.
+-- Only the length-delimited fields enclosing the target are rewritten; everything else is kept as-is.
+-- With allow_append, an index equal to the element count in the last segment appends a new element.
+DROP PROCEDURE IF EXISTS _pb_message_set_by_path $$
+CREATE PROCEDURE _pb_message_set_by_path(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN path TEXT, IN segments JSON, IN segment_index INT, IN json_value JSON, IN is_clear BOOLEAN, IN allow_append BOOLEAN, OUT result LONGBLOB)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE func_name TEXT;
+	DECLARE segment JSON;
+	DECLARE field_token TEXT;
+	DECLARE repeated_index INT;
+	DECLARE map_key TEXT;
+	DECLARE is_leaf BOOLEAN;
+
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE is_repeated BOOLEAN;
+	DECLARE is_map BOOLEAN;
+
+	DECLARE map_entry_descriptor JSON;
+	DECLARE map_key_type INT;
+	DECLARE map_value_field JSON;
+	DECLARE wire_json JSON;
+	DECLARE entry LONGBLOB;
+	DECLARE child LONGBLOB;
+	DECLARE element_count INT;
+
+	SET func_name = IF(is_clear, 'pb_message_clear_by_path', 'pb_message_set_by_path');
+
+	-- The message at segment_index is nested segment_index levels below the top-level message
+	CALL _pb_check_message_depth(segment_index + 1, func_name);
+
+	SET segment = JSON_EXTRACT(segments, CONCAT('$[', segment_index, ']'));
+	SET field_token = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.f'));
+	SET repeated_index = JSON_EXTRACT(segment, '$.i');
+	SET map_key = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.k'));
+	SET is_leaf = (segment_index = JSON_LENGTH(segments) - 1);
+
+	SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, full_type_name, field_token, func_name);
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET is_repeated = (field_label = 3); -- LABEL_REPEATED
+
+	SET is_map = FALSE;
+	IF field_type = 11 AND is_repeated THEN -- TYPE_MESSAGE
+		SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+		SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+	END IF;
+
+	IF is_map AND repeated_index IS NOT NULL THEN
+		-- Unquoted integer subscripts on map fields are integer keys
+		SET map_key = CAST(repeated_index AS CHAR);
+		SET repeated_index = NULL;
+	END IF;
+
+	IF map_key IS NOT NULL AND NOT is_map THEN
+		SET message_text = CONCAT(func_name, ': field `', field_token, '` is not a map field in path `', path, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+	IF repeated_index IS NOT NULL AND (NOT is_repeated OR is_map) THEN
+		SET message_text = CONCAT(func_name, ': field `', field_token, '` is not a repeated field in path `', path, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	IF is_map THEN
+		SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+		SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]');
+	END IF;
+
+	IF repeated_index IS NOT NULL THEN
+		SET element_count = _pb_message_get_repeated_field_count(message, field_descriptor);
+		IF repeated_index < 0 THEN
+			SET repeated_index = element_count + repeated_index;
+		END IF;
+		IF (repeated_index < 0 OR repeated_index >= element_count) AND NOT (allow_append AND is_leaf AND repeated_index = element_count) THEN
+			IF is_clear THEN
+				-- Nothing to clear
+				SET result = message;
+				LEAVE proc;
+			END IF;
+			SET message_text = CONCAT(func_name, ': index out of range for field `', field_token, '` in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+	END IF;
+
+	IF is_leaf THEN
+		IF map_key IS NOT NULL THEN
+			SET wire_json = pb_message_to_wire_json(message);
+			IF is_clear THEN
+				SET entry = NULL;
+			ELSE
+				SET entry = _pb_message_new_map_entry_from_json(descriptor_set_json, map_entry_descriptor, map_key, json_value);
+			END IF;
+			SET result = pb_wire_json_to_message(_pb_wire_json_set_map_entry(wire_json, field_number, map_key_type, map_key, entry));
+		ELSEIF repeated_index IS NOT NULL THEN
+			IF is_clear THEN
+				SET result = _pb_message_remove_repeated_field_element(message, field_descriptor, repeated_index);
+			ELSEIF repeated_index = element_count THEN
+				SET result = _pb_message_add_repeated_field_element_from_json(descriptor_set_json, message, field_descriptor, _pb_is_field_packed(_pb_get_file_syntax(descriptor_set_json, full_type_name), field_descriptor), json_value);
+			ELSE
+				SET result = _pb_message_set_repeated_field_element_from_json(descriptor_set_json, message, field_descriptor, repeated_index, json_value);
+			END IF;
+		ELSE
+			SET result = pb_message_set_field(descriptor_set_json, full_type_name, message, field_token, IF(is_clear, NULL, json_value));
+		END IF;
+		LEAVE proc;
+	END IF;
+
+	-- Intermediate segment: update the nested message and write it back to its enclosing field
+	IF is_map THEN
+		IF map_key IS NULL OR JSON_EXTRACT(map_value_field, '$."5"') <> 11 THEN
+			SET message_text = CONCAT(func_name, ': cannot descend into map field `', field_token, '` in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET wire_json = pb_message_to_wire_json(message);
+		SET entry = _pb_wire_json_get_map_entry(wire_json, field_number, map_key_type, map_key);
+		IF entry IS NULL THEN
+			IF is_clear THEN
+				SET result = message;
+				LEAVE proc;
+			END IF;
+			SET entry = _pb_message_new_map_entry_from_json(descriptor_set_json, map_entry_descriptor, map_key, NULL);
+		END IF;
+
+		CALL _pb_message_set_by_path(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"')), pb_message_get_message_field(entry, 2, _binary ''), path, segments, segment_index + 1, json_value, is_clear, allow_append, child);
+		SET entry = pb_message_set_message_field(entry, 2, child);
+		SET result = pb_wire_json_to_message(_pb_wire_json_set_map_entry(wire_json, field_number, map_key_type, map_key, entry));
+	ELSEIF field_type <> 11 THEN
+		SET message_text = CONCAT(func_name, ': field `', field_token, '` is not a message field in path `', path, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	ELSEIF is_repeated THEN
+		IF repeated_index IS NULL THEN
+			SET message_text = CONCAT(func_name, ': repeated field `', field_token, '` requires an index in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		CALL _pb_message_set_by_path(descriptor_set_json, field_type_name, pb_message_get_repeated_message_field_element(message, field_number, repeated_index), path, segments, segment_index + 1, json_value, is_clear, allow_append, child);
+		SET result = pb_message_set_repeated_message_field_element(message, field_number, repeated_index, child);
+	ELSE
+		IF is_clear AND NOT pb_message_has_message_field(message, field_number) THEN
+			SET result = message;
+			LEAVE proc;
+		END IF;
+
+		CALL _pb_message_set_by_path(descriptor_set_json, field_type_name, pb_message_get_message_field(message, field_number, _binary ''), path, segments, segment_index + 1, json_value, is_clear, allow_append, child);
+		IF pb_message_has_message_field(message, field_number) THEN
+			SET result = pb_wire_json_to_message(_pb_wire_json_replace_len_field(pb_message_to_wire_json(message), field_number, child));
+		ELSE
+			-- Missing intermediate messages are created (which also clears other members of a oneof)
+			SET result = pb_message_set_field(descriptor_set_json, full_type_name, message, field_token, JSON_QUOTE(TO_BASE64(child)));
+		END IF;
+	END IF;
+END $$
+
+DROP FUNCTION IF EXISTS _pb_message_set_or_clear_by_path $$
+CREATE FUNCTION _pb_message_set_or_clear_by_path(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, path TEXT, json_value JSON, is_clear BOOLEAN) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segments JSON;
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL OR path IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET segments = _pb_util_parse_field_path(path);
+	IF JSON_LENGTH(segments) = 0 THEN
+		SET message_text = CONCAT(IF(is_clear, 'pb_message_clear_by_path', 'pb_message_set_by_path'), ': path must not be empty');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_set_by_path(descriptor_set_json, type_name, message, path, segments, 0, json_value, is_clear, FALSE, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Sets the value at a field name path such as 'config.retry.backoff.max_ms', creating missing intermediate messages
+DROP FUNCTION IF EXISTS pb_message_set_by_path $$
+CREATE FUNCTION pb_message_set_by_path(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, path TEXT, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_set_or_clear_by_path(descriptor_set_json, type_name, message, path, json_value, FALSE);
+END $$
+
+-- Clears the value at a field name path. Repeated elements and map entries addressed by the path are removed.
+DROP FUNCTION IF EXISTS pb_message_clear_by_path $$
+CREATE FUNCTION pb_message_clear_by_path(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, path TEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_set_or_clear_by_path(descriptor_set_json, type_name, message, path, NULL, TRUE);
+END $$
+
+DELIMITER $$
+
+-- Helper function to quote a string or bytes value for the protobuf text format.
+-- Escapes are compatible with the output of google.golang.org/protobuf/encoding/prototext:
+-- valid UTF-8 is kept as is (except C1 control characters), and invalid bytes are written as \xHH.
+DROP FUNCTION IF EXISTS _pb_util_text_quote $$
+CREATE FUNCTION _pb_util_text_quote(value LONGBLOB) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB DEFAULT _binary '"';
+	DECLARE value_length INT;
+	DECLARE i INT DEFAULT 1;
+	DECLARE j INT;
+	DECLARE b INT;
+	DECLARE continuation_byte INT;
+	DECLARE sequence_length INT;
+	DECLARE code_point INT;
+	DECLARE min_second_byte INT;
+	DECLARE max_second_byte INT;
+	DECLARE is_valid BOOLEAN;
+
+	IF value IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET value_length = LENGTH(value);
+
+	WHILE i <= value_length DO
+		SET b = ASCII(SUBSTRING(value, i, 1));
+		IF b < 0x80 THEN
+			CASE
+			WHEN b = 0x22 THEN SET result = CONCAT(result, _binary '\\"');
+			WHEN b = 0x5C THEN SET result = CONCAT(result, _binary '\\\\');
+			WHEN b = 0x0A THEN SET result = CONCAT(result, _binary '\\n');
+			WHEN b = 0x0D THEN SET result = CONCAT(result, _binary '\\r');
+			WHEN b = 0x09 THEN SET result = CONCAT(result, _binary '\\t');
+			WHEN b < 0x20 OR b = 0x7F THEN SET result = CONCAT(result, _binary '\\x', LOWER(LPAD(HEX(b), 2, '0')));
+			ELSE SET result = CONCAT(result, SUBSTRING(value, i, 1));
+			END CASE;
+			SET i = i + 1;
+		ELSE
+			-- Decode a UTF-8 sequence; bytes that do not form a valid sequence are escaped one by one
+			SET sequence_length = CASE
+				WHEN b BETWEEN 0xC2 AND 0xDF THEN 2
+				WHEN b BETWEEN 0xE0 AND 0xEF THEN 3
+				WHEN b BETWEEN 0xF0 AND 0xF4 THEN 4
+				ELSE 0
+			END;
+			SET code_point = b & CASE sequence_length WHEN 2 THEN 0x1F WHEN 3 THEN 0x0F ELSE 0x07 END;
+			SET min_second_byte = CASE b WHEN 0xE0 THEN 0xA0 WHEN 0xF0 THEN 0x90 ELSE 0x80 END; -- reject overlong encodings
+			SET max_second_byte = CASE b WHEN 0xED THEN 0x9F WHEN 0xF4 THEN 0x8F ELSE 0xBF END; -- reject surrogates and values above U+10FFFF
+			SET is_valid = sequence_length > 0 AND i + sequence_length - 1 <= value_length;
+			SET j = 1;
+			WHILE is_valid AND j < sequence_length DO
+				SET continuation_byte = ASCII(SUBSTRING(value, i + j, 1));
+				IF continuation_byte < IF(j = 1, min_second_byte, 0x80) OR continuation_byte > IF(j = 1, max_second_byte, 0xBF) THEN
+					SET is_valid = FALSE;
+				ELSE
+					SET code_point = (code_point << 6) | (continuation_byte & 0x3F);
+				END IF;
+				SET j = j + 1;
+			END WHILE;
+
+			IF NOT is_valid THEN
+				SET result = CONCAT(result, _binary '\\x', LOWER(HEX(b)));
+				SET i = i + 1;
+			ELSEIF code_point <= 0x9F THEN
+				SET result = CONCAT(result, _binary '\\u', LOWER(LPAD(HEX(code_point), 4, '0')));
+				SET i = i + sequence_length;
+			ELSE
+				SET result = CONCAT(result, SUBSTRING(value, i, sequence_length));
+				SET i = i + sequence_length;
+			END IF;
+		END IF;
+	END WHILE;
+
+	RETURN CONVERT(CONCAT(result, _binary '"') USING utf8mb4);
+END $$
+
+-- Helper function to format the bit pattern of a float (bit_size = 32) or double (bit_size = 64) for the text format
+DROP FUNCTION IF EXISTS _pb_util_format_text_floating_point $$
+CREATE FUNCTION _pb_util_format_text_floating_point(bits BIGINT UNSIGNED, bit_size INT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE float_value FLOAT;
+
+	IF bit_size = 32 THEN
+		IF ((bits >> 23) & 0xFF) = 0xFF THEN
+			RETURN IF((bits & 0x7FFFFF) <> 0, 'nan', IF((bits >> 31) = 0, 'inf', '-inf'));
+		END IF;
+		SET float_value = _pb_util_reinterpret_uint32_as_float(bits);
+		RETURN CAST(float_value AS CHAR);
+	END IF;
+
+	IF ((bits >> 52) & 0x7FF) = 0x7FF THEN
+		RETURN IF((bits & 0xFFFFFFFFFFFFF) <> 0, 'nan', IF((bits >> 63) = 0, 'inf', '-inf'));
+	END IF;
+	RETURN CAST(_pb_util_reinterpret_uint64_as_double(bits) AS CHAR);
+END $$
+
+-- Helper function to get all values of a field as a JSON array without the schema-specific interpretation.
+-- Float and double values are returned as their IEEE 754 bit patterns so that NaN and infinities are preserved.
+DROP FUNCTION IF EXISTS _pb_wire_json_get_repeated_field_as_raw_json_array $$
+CREATE FUNCTION _pb_wire_json_get_repeated_field_as_raw_json_array(wire_json JSON, field_number INT, field_type INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number); -- double
+	WHEN 2 THEN RETURN pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number); -- float
+	WHEN 3 THEN RETURN pb_wire_json_get_repeated_int64_field_as_json_array(wire_json, field_number);
+	WHEN 4 THEN RETURN pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json, field_number);
+	WHEN 5 THEN RETURN pb_wire_json_get_repeated_int32_field_as_json_array(wire_json, field_number);
+	WHEN 6 THEN RETURN pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number);
+	WHEN 7 THEN RETURN pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number);
+	WHEN 8 THEN RETURN pb_wire_json_get_repeated_bool_field_as_json_array(wire_json, field_number);
+	WHEN 9 THEN RETURN pb_wire_json_get_repeated_string_field_as_json_array(wire_json, field_number);
+	WHEN 11 THEN RETURN pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+	WHEN 12 THEN RETURN pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json, field_number);
+	WHEN 13 THEN RETURN pb_wire_json_get_repeated_uint32_field_as_json_array(wire_json, field_number);
+	WHEN 14 THEN RETURN pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+	WHEN 15 THEN RETURN pb_wire_json_get_repeated_sfixed32_field_as_json_array(wire_json, field_number);
+	WHEN 16 THEN RETURN pb_wire_json_get_repeated_sfixed64_field_as_json_array(wire_json, field_number);
+	WHEN 17 THEN RETURN pb_wire_json_get_repeated_sint32_field_as_json_array(wire_json, field_number);
+	WHEN 18 THEN RETURN pb_wire_json_get_repeated_sint64_field_as_json_array(wire_json, field_number);
+	ELSE
+		SET message_text = CONCAT('_pb_wire_json_get_repeated_field_as_raw_json_array: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to format a scalar value returned by _pb_wire_json_get_repeated_field_as_raw_json_array for the text format
+DROP FUNCTION IF EXISTS _pb_util_format_text_value $$
+CREATE FUNCTION _pb_util_format_text_value(descriptor_set_json JSON, field_type INT, field_type_name TEXT, raw_value JSON) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE enum_name JSON;
+
+	CASE field_type
+	WHEN 1 THEN RETURN _pb_util_format_text_floating_point(CAST(JSON_UNQUOTE(raw_value) AS UNSIGNED), 64);
+	WHEN 2 THEN RETURN _pb_util_format_text_floating_point(CAST(JSON_UNQUOTE(raw_value) AS UNSIGNED), 32);
+	WHEN 8 THEN RETURN IF(JSON_UNQUOTE(raw_value) IN ('1', 'true'), 'true', 'false');
+	WHEN 9 THEN RETURN _pb_util_text_quote(CAST(JSON_UNQUOTE(raw_value) AS BINARY));
+	WHEN 12 THEN RETURN _pb_util_text_quote(FROM_BASE64(JSON_UNQUOTE(raw_value)));
+	WHEN 14 THEN
+		-- Unknown enum values are written as numbers
+		CALL _pb_enum_to_json(descriptor_set_json, field_type_name, CAST(JSON_UNQUOTE(raw_value) AS SIGNED), enum_name);
+		RETURN COALESCE(JSON_UNQUOTE(enum_name), JSON_UNQUOTE(raw_value));
+	ELSE
+		RETURN JSON_UNQUOTE(raw_value);
+	END CASE;
+END $$
+
+-- Procedure for converting a protobuf message to the text format. indent is the indentation of the current
+-- nesting level for the multi-line format, or NULL for the single-line format. message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_to_text $$
+CREATE PROCEDURE _pb_message_to_text(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN indent TEXT, IN message_depth INT, OUT result LONGTEXT)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE is_map_entry BOOLEAN;
+	DECLARE wire_json JSON;
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT;
+	DECLARE field_descriptor JSON;
+
+	-- Field properties
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE oneof_index INT;
+
+	-- Processing variables
+	DECLARE has_field_presence BOOLEAN;
+	DECLARE is_selected BOOLEAN;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE zero_value JSON;
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE value_text LONGTEXT;
+	DECLARE nested_text LONGTEXT;
+
+	-- Oneof handling
+	DECLARE oneofs JSON;
+	DECLARE oneof_priority INT;
+
+	IF buf IS NULL THEN
+		SET result = NULL;
+		LEAVE proc;
+	END IF;
+
+	CALL _pb_check_message_depth(message_depth, IF(indent IS NULL, 'pb_message_to_single_line_text', 'pb_message_to_text'));
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', '_pb_message_to_text', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET is_map_entry = COALESCE(CAST(JSON_EXTRACT(message_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+	SET wire_json = pb_message_to_wire_json(buf);
+	SET fields = COALESCE(JSON_EXTRACT(message_descriptor, '$."2"'), JSON_ARRAY());
+	SET field_count = JSON_LENGTH(fields);
+	SET result = '';
+
+	-- Find the member of each oneof that was set last
+	SET oneofs = JSON_OBJECT();
+	SET field_index = 0;
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+
+		IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+			SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+			IF elements IS NOT NULL THEN
+				SET oneof_priority = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, '].i'));
+				IF COALESCE(JSON_EXTRACT(oneofs, CONCAT('$."', oneof_index, '".i')) < oneof_priority, TRUE) THEN
+					SET oneofs = JSON_SET(oneofs, CONCAT('$."', oneof_index, '"'), JSON_OBJECT('i', oneof_priority, 'n', field_number));
+				END IF;
+			END IF;
+		END IF;
+
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	-- Fields are written in the declaration order, as prototext does
+	SET field_index = 0;
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+
+		-- Extract field properties from FieldDescriptorProto
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+
+		IF field_type = 10 THEN -- TYPE_GROUP (unsupported)
+			SET message_text = CONCAT('_pb_message_to_text: unsupported field_type `', field_type, '` for field `', field_name, '` (', field_number, ').');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET has_field_presence =
+			(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+			OR (syntax = 'proto3'
+				AND (
+					(field_label = 1 AND proto3_optional) -- proto3 optional
+					OR (field_label <> 3 AND field_type = 11) -- message fields
+					OR (oneof_index IS NOT NULL) -- oneof fields
+				));
+
+		IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+			SET is_selected = JSON_EXTRACT(oneofs, CONCAT('$."', oneof_index, '".n')) = field_number;
+		ELSE
+			SET is_selected = TRUE;
+		END IF;
+
+		IF is_selected THEN
+			IF field_label = 3 THEN -- LABEL_REPEATED (including maps, written as repeated entry messages)
+				SET elements = _pb_wire_json_get_repeated_field_as_raw_json_array(wire_json, field_number, field_type);
+			ELSEIF field_type = 11 THEN
+				-- Occurrences of a singular message field are merged
+				SET bytes_value = _pb_wire_json_get_merged_message_field(wire_json, field_number);
+				SET elements = IF(bytes_value IS NULL, JSON_ARRAY(), JSON_ARRAY(TO_BASE64(bytes_value)));
+			ELSE
+				-- The last occurrence of a singular scalar field wins
+				SET elements = _pb_wire_json_get_repeated_field_as_raw_json_array(wire_json, field_number, field_type);
+				IF JSON_LENGTH(elements) > 0 THEN
+					SET elements = JSON_ARRAY(JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, ']')));
+				END IF;
+			END IF;
+
+			SET zero_value = IF(field_type IN (9, 11, 12), JSON_QUOTE(''), CAST(0 AS JSON));
+
+			-- Map entries always have both the key and the value written
+			IF is_map_entry AND JSON_LENGTH(elements) = 0 THEN
+				SET elements = JSON_ARRAY(zero_value);
+			END IF;
+
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+
+			WHILE element_index < element_count DO
+				IF field_type = 11 THEN
+					CALL _pb_message_to_text(descriptor_set_json, field_type_name, FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))), IF(indent IS NULL, NULL, CONCAT(indent, '  ')), message_depth + 1, nested_text);
+					IF indent IS NULL THEN
+						SET value_text = CONCAT('{', nested_text, '}');
+					ELSEIF nested_text = '' THEN
+						SET value_text = '{}';
+					ELSE
+						SET value_text = CONCAT('{\n', nested_text, indent, '}');
+					END IF;
+				ELSE
+					SET value_text = _pb_util_format_text_value(descriptor_set_json, field_type, field_type_name, JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')));
+
+					-- Fields without presence are omitted when they have the default value
+					IF field_label <> 3 AND NOT has_field_presence AND NOT is_map_entry
+						AND BINARY value_text = BINARY _pb_util_format_text_value(descriptor_set_json, field_type, field_type_name, zero_value) THEN
+						SET value_text = NULL;
+					END IF;
+				END IF;
+
+				IF value_text IS NOT NULL THEN
+					IF indent IS NULL THEN
+						SET result = CONCAT(result, IF(result = '', '', ' '), field_name, ':', value_text);
+					ELSE
+						SET result = CONCAT(result, indent, field_name, ': ', value_text, '\n');
+					END IF;
+				END IF;
+
+				SET element_index = element_index + 1;
+			END WHILE;
+		END IF;
+
+		SET field_index = field_index + 1;
+	END WHILE;
+END $$
+
+-- Converts a protobuf message to the multi-line text format
+DROP FUNCTION IF EXISTS pb_message_to_text $$
+CREATE FUNCTION pb_message_to_text(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE result LONGTEXT;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_to_text(descriptor_set_json, type_name, message, '', 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Converts a protobuf message to the single-line text format
+DROP FUNCTION IF EXISTS pb_message_to_single_line_text $$
+CREATE FUNCTION pb_message_to_single_line_text(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE result LONGTEXT;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_to_text(descriptor_set_json, type_name, message, NULL, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Helper function to skip whitespace and # comments in the text format. Returns the position of the next token.
+DROP FUNCTION IF EXISTS _pb_text_skip_whitespace $$
+CREATE FUNCTION _pb_text_skip_whitespace(text LONGTEXT, pos INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE text_length INT;
+	DECLARE c INT;
+	DECLARE newline_pos INT;
+
+	SET text_length = CHAR_LENGTH(text);
+
+	WHILE pos <= text_length DO
+		SET c = ASCII(SUBSTRING(text, pos, 1));
+		IF c IN (0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x20) THEN
+			SET pos = pos + 1;
+		ELSEIF c = 0x23 THEN -- '#' starts a comment that runs to the end of the line
+			SET newline_pos = LOCATE('\n', text, pos);
+			SET pos = IF(newline_pos = 0, text_length + 1, newline_pos + 1);
+		ELSE
+			RETURN pos;
+		END IF;
+	END WHILE;
+
+	RETURN pos;
+END $$
+
+-- Helper procedure to read the next token of the text format.
+-- token_type is one of 'EOF', 'PUNCT', 'IDENT', 'NUMBER' and 'STRING'. Strings are returned unescaped,
+-- with adjacent string literals concatenated. A leading '-' is included in NUMBER and IDENT tokens.
+DROP PROCEDURE IF EXISTS _pb_text_next_token $$
+CREATE PROCEDURE _pb_text_next_token(IN text LONGTEXT, INOUT pos INT, OUT token_type TEXT, OUT token LONGBLOB)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE text_length INT;
+	DECLARE c TEXT;
+	DECLARE quote_char TEXT;
+	DECLARE start_pos INT;
+	DECLARE end_pos INT;
+	DECLARE sign TEXT DEFAULT '';
+	DECLARE matched TEXT;
+
+	SET text_length = CHAR_LENGTH(text);
+	SET pos = _pb_text_skip_whitespace(text, pos);
+
+	IF pos > text_length THEN
+		SET token_type = 'EOF';
+		SET token = NULL;
+		LEAVE proc;
+	END IF;
+
+	SET c = SUBSTRING(text, pos, 1);
+
+	IF c IN (':', '{', '}', '<', '>', '[', ']', ',', ';') THEN
+		SET token_type = 'PUNCT';
+		SET token = CAST(c AS BINARY);
+		SET pos = pos + 1;
+		LEAVE proc;
+	END IF;
+
+	IF c IN ('"', '\'') THEN
+		SET token_type = 'STRING';
+		SET token = _binary '';
+		WHILE c IN ('"', '\'') DO
+			SET quote_char = c;
+			SET start_pos = pos + 1;
+			SET end_pos = start_pos;
+			WHILE SUBSTRING(text, end_pos, 1) <> quote_char DO
+				IF end_pos > text_length OR SUBSTRING(text, end_pos, 1) = '\n' THEN
+					SET message_text = CONCAT('pb_text_to_message: unterminated string at position ', pos);
+					SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+				END IF;
+				SET end_pos = end_pos + IF(SUBSTRING(text, end_pos, 1) = '\\', 2, 1);
+			END WHILE;
+			SET token = CONCAT(token, _pb_util_c_unescape(SUBSTRING(text, start_pos, end_pos - start_pos)));
+			SET pos = _pb_text_skip_whitespace(text, end_pos + 1);
+			SET c = SUBSTRING(text, pos, 1);
+		END WHILE;
+		LEAVE proc;
+	END IF;
+
+	IF c = '-' THEN
+		SET sign = '-';
+		SET pos = _pb_text_skip_whitespace(text, pos + 1);
+	END IF;
+
+	SET matched = REGEXP_SUBSTR(SUBSTRING(text, pos, 1024), '^(0[xX][0-9A-Fa-f]+|([0-9]+[.]?[0-9]*|[.][0-9]+)([eE][+-]?[0-9]+)?[fF]?)');
+	IF matched IS NOT NULL THEN
+		SET token_type = 'NUMBER';
+	ELSE
+		SET matched = REGEXP_SUBSTR(SUBSTRING(text, pos, 1024), '^[A-Za-z_][A-Za-z0-9_]*');
+		SET token_type = 'IDENT';
+	END IF;
+
+	IF matched IS NULL THEN
+		SET message_text = CONCAT('pb_text_to_message: unexpected character `', SUBSTRING(text, pos, 1), '` at position ', pos);
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	SET token = CAST(CONCAT(sign, matched) AS BINARY);
+	SET pos = pos + CHAR_LENGTH(matched);
+END $$
+
+-- Helper function to convert a scalar token of the text format into the JSON value accepted by the setters.
+-- Float and double values are converted into their IEEE 754 bit patterns. Returns NULL if the token is not valid for the field type.
+DROP FUNCTION IF EXISTS _pb_text_token_to_json $$
+CREATE FUNCTION _pb_text_token_to_json(field_type INT, token_type TEXT, token LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE token_text TEXT;
+	DECLARE is_negative BOOLEAN;
+	DECLARE unsigned_text TEXT;
+	DECLARE digits TEXT;
+
+	IF token_type = 'STRING' THEN
+		CASE field_type
+		WHEN 9 THEN RETURN JSON_QUOTE(CONVERT(token USING utf8mb4));
+		WHEN 12 THEN RETURN JSON_QUOTE(TO_BASE64(token));
+		ELSE RETURN NULL;
+		END CASE;
+	END IF;
+
+	IF field_type IN (9, 12) OR token_type NOT IN ('IDENT', 'NUMBER') THEN
+		RETURN NULL;
+	END IF;
+
+	SET token_text = CONVERT(token USING utf8mb4);
+	SET is_negative = token_text LIKE '-%';
+	SET unsigned_text = IF(is_negative, SUBSTRING(token_text, 2), token_text);
+
+	IF field_type = 8 THEN -- bool
+		IF BINARY token_text IN ('true', 'True', 't', '1') THEN
+			RETURN CAST('true' AS JSON);
+		ELSEIF BINARY token_text IN ('false', 'False', 'f', '0') THEN
+			RETURN CAST('false' AS JSON);
+		END IF;
+		RETURN NULL;
+	END IF;
+
+	IF field_type = 14 AND token_type = 'IDENT' THEN -- enum value name
+		RETURN IF(is_negative, NULL, JSON_QUOTE(token_text));
+	END IF;
+
+	IF field_type IN (1, 2) THEN -- double, float
+		IF token_type = 'IDENT' THEN
+			IF LOWER(unsigned_text) IN ('inf', 'infinity') THEN
+				RETURN CAST(CASE
+					WHEN field_type = 1 THEN IF(is_negative, 18442240474082181120, 9218868437227405312) -- 0xFFF0000000000000, 0x7FF0000000000000
+					ELSE IF(is_negative, 4286578688, 2139095040) -- 0xFF800000, 0x7F800000
+				END AS JSON);
+			ELSEIF LOWER(unsigned_text) = 'nan' THEN
+				RETURN CAST(IF(field_type = 1, 9221120237041090560, 2143289344) AS JSON); -- 0x7FF8000000000000, 0x7FC00000
+			END IF;
+			RETURN NULL;
+		END IF;
+
+		IF unsigned_text LIKE '0x%' THEN
+			RETURN NULL;
+		END IF;
+		SET token_text = REGEXP_REPLACE(token_text, '[fF]$', '');
+		IF field_type = 1 THEN
+			RETURN CAST(_pb_util_reinterpret_double_as_uint64(CAST(token_text AS DOUBLE)) AS JSON);
+		END IF;
+		RETURN CAST(_pb_util_reinterpret_float_as_uint32(CAST(token_text AS FLOAT)) AS JSON);
+	END IF;
+
+	-- Integers (and enum values given by number) in decimal, hexadecimal or octal
+	IF token_type <> 'NUMBER' THEN
+		RETURN NULL;
+	END IF;
+
+	IF unsigned_text REGEXP '^0[xX][0-9A-Fa-f]+$' THEN
+		SET digits = CONV(SUBSTRING(unsigned_text, 3), 16, 10);
+	ELSEIF unsigned_text REGEXP '^0[0-7]+$' THEN
+		SET digits = CONV(unsigned_text, 8, 10);
+	ELSEIF unsigned_text REGEXP '^[0-9]+$' THEN
+		SET digits = unsigned_text;
+	ELSE
+		RETURN NULL;
+	END IF;
+
+	RETURN CAST(CONCAT(IF(is_negative, '-', ''), digits) AS JSON);
+END $$
+
+-- Helper procedure to parse the value of a field starting at the given (already read) token. message_depth is the depth
+-- of the message containing the field.
+DROP PROCEDURE IF EXISTS _pb_text_parse_field_value $$
+CREATE PROCEDURE _pb_text_parse_field_value(IN descriptor_set_json JSON, IN field_descriptor JSON, IN text LONGTEXT, INOUT pos INT, IN token_type TEXT, IN token LONGBLOB, IN token_pos INT, IN message_depth INT, OUT value_json JSON)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_type INT;
+	DECLARE nested_message LONGBLOB;
+
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+	IF field_type = 11 THEN -- TYPE_MESSAGE
+		IF token_type <> 'PUNCT' OR token NOT IN ('{', '<') THEN
+			SET message_text = CONCAT('pb_text_to_message: expected `{` for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '` at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+		CALL _pb_text_to_message(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')), text, pos, IF(token = '{', '}', '>'), message_depth + 1, nested_message);
+		SET value_json = JSON_QUOTE(TO_BASE64(nested_message));
+	ELSE
+		SET value_json = _pb_text_token_to_json(field_type, token_type, token);
+		IF value_json IS NULL THEN
+			SET message_text = CONCAT('pb_text_to_message: invalid value `', COALESCE(CONVERT(token USING utf8mb4), 'end of input'), '` for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '` at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+	END IF;
+END $$
+
+-- Procedure for parsing the fields of a message in the text format, up to close_delimiter ('}' or '>') or the end of input (NULL).
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_text_to_message $$
+CREATE PROCEDURE _pb_text_to_message(IN descriptor_set_json JSON, IN full_type_name TEXT, IN text LONGTEXT, INOUT pos INT, IN close_delimiter TEXT, IN message_depth INT, OUT result LONGBLOB)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE token_type TEXT;
+	DECLARE token LONGBLOB;
+	DECLARE token_pos INT;
+	DECLARE field_name TEXT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE value_json JSON;
+	DECLARE is_list_end BOOLEAN;
+
+	-- Values of repeated fields, collected as {"<field_number>": {"d": <field_descriptor>, "v": [<values>]}}
+	DECLARE repeated_fields JSON;
+	DECLARE repeated_field JSON;
+	DECLARE repeated_field_path TEXT;
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_text_to_message');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_text_to_message', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET result = pb_message_new();
+	SET repeated_fields = JSON_OBJECT();
+
+	parse_loop: LOOP
+		SET token_pos = _pb_text_skip_whitespace(text, pos);
+		CALL _pb_text_next_token(text, pos, token_type, token);
+
+		IF token_type = 'EOF' THEN
+			IF close_delimiter IS NOT NULL THEN
+				SET message_text = CONCAT('pb_text_to_message: unexpected end of input, expected `', close_delimiter, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			LEAVE parse_loop;
+		END IF;
+
+		IF token_type = 'PUNCT' AND token = close_delimiter THEN
+			LEAVE parse_loop;
+		END IF;
+
+		IF token_type = 'PUNCT' AND token = '[' THEN
+			SET message_text = CONCAT('pb_text_to_message: extension and Any expansion syntax is not supported at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		IF token_type <> 'IDENT' OR token LIKE '-%' THEN
+			SET message_text = CONCAT('pb_text_to_message: unexpected token `', CONVERT(token USING utf8mb4), '` at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_name = CONVERT(token USING utf8mb4);
+		SET field_descriptor = _pb_get_field_descriptor_by_name(message_descriptor, field_name);
+		IF field_descriptor IS NULL THEN
+			SET message_text = CONCAT('pb_text_to_message: field `', field_name, '` not found in message type `', full_type_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+		IF field_type = 10 THEN -- TYPE_GROUP (unsupported)
+			SET message_text = CONCAT('pb_text_to_message: unsupported field_type `', field_type, '` for field `', field_name, '` (', field_number, ').');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		-- The ':' separator is optional before a message value
+		SET token_pos = _pb_text_skip_whitespace(text, pos);
+		CALL _pb_text_next_token(text, pos, token_type, token);
+		IF token_type = 'PUNCT' AND token = ':' THEN
+			SET token_pos = _pb_text_skip_whitespace(text, pos);
+			CALL _pb_text_next_token(text, pos, token_type, token);
+		ELSEIF field_type <> 11 THEN
+			SET message_text = CONCAT('pb_text_to_message: expected `:` after field `', field_name, '` at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		IF field_label = 3 THEN -- LABEL_REPEATED
+			SET repeated_field_path = CONCAT('$."', field_number, '"');
+			IF NOT JSON_CONTAINS_PATH(repeated_fields, 'one', repeated_field_path) THEN
+				-- Float and double values are set as their bit patterns through the fixed64 and fixed32 setters
+				SET repeated_fields = JSON_SET(repeated_fields, repeated_field_path, JSON_OBJECT(
+					'd', CASE field_type
+						WHEN 1 THEN JSON_SET(field_descriptor, '$."5"', 6)
+						WHEN 2 THEN JSON_SET(field_descriptor, '$."5"', 7)
+						ELSE field_descriptor
+					END,
+					'v', JSON_ARRAY()));
+			END IF;
+
+			IF token_type = 'PUNCT' AND token = '[' THEN
+				-- List syntax: name: [value, value, ...]
+				SET token_pos = _pb_text_skip_whitespace(text, pos);
+				CALL _pb_text_next_token(text, pos, token_type, token);
+				SET is_list_end = token_type = 'PUNCT' AND token = ']';
+				WHILE NOT is_list_end DO
+					CALL _pb_text_parse_field_value(descriptor_set_json, field_descriptor, text, pos, token_type, token, token_pos, message_depth, value_json);
+					SET repeated_fields = JSON_ARRAY_APPEND(repeated_fields, CONCAT(repeated_field_path, '.v'), value_json);
+
+					SET token_pos = _pb_text_skip_whitespace(text, pos);
+					CALL _pb_text_next_token(text, pos, token_type, token);
+					IF token_type = 'PUNCT' AND token = ']' THEN
+						SET is_list_end = TRUE;
+					ELSEIF token_type = 'PUNCT' AND token = ',' THEN
+						SET token_pos = _pb_text_skip_whitespace(text, pos);
+						CALL _pb_text_next_token(text, pos, token_type, token);
+					ELSE
+						SET message_text = CONCAT('pb_text_to_message: expected `,` or `]` at position ', token_pos);
+						SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+					END IF;
+				END WHILE;
+			ELSE
+				CALL _pb_text_parse_field_value(descriptor_set_json, field_descriptor, text, pos, token_type, token, token_pos, message_depth, value_json);
+				SET repeated_fields = JSON_ARRAY_APPEND(repeated_fields, CONCAT(repeated_field_path, '.v'), value_json);
+			END IF;
+		ELSE
+			CALL _pb_text_parse_field_value(descriptor_set_json, field_descriptor, text, pos, token_type, token, token_pos, message_depth, value_json);
+			SET result = _pb_message_set_singular_field_from_json(descriptor_set_json, result,
+				CASE field_type
+					WHEN 1 THEN JSON_SET(field_descriptor, '$."5"', 6)
+					WHEN 2 THEN JSON_SET(field_descriptor, '$."5"', 7)
+					ELSE field_descriptor
+				END,
+				value_json);
+		END IF;
+
+		-- Fields may optionally be separated by ',' or ';'
+		SET token_pos = pos;
+		CALL _pb_text_next_token(text, pos, token_type, token);
+		IF NOT (token_type = 'PUNCT' AND token IN (',', ';')) THEN
+			SET pos = token_pos;
+		END IF;
+	END LOOP;
+
+	SET field_numbers = JSON_KEYS(repeated_fields);
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	SET field_number_index = 0;
+	WHILE field_number_index < field_number_count DO
+		SET repeated_field = JSON_EXTRACT(repeated_fields, CONCAT('$.', JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']'))));
+		SET field_descriptor = JSON_EXTRACT(repeated_field, '$.d');
+		SET result = _pb_message_set_repeated_field_from_json(descriptor_set_json, result, field_descriptor, _pb_is_field_packed(syntax, field_descriptor), JSON_EXTRACT(repeated_field, '$.v'));
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+END $$
+
+-- Parses a protobuf message from the text format
+DROP FUNCTION IF EXISTS pb_text_to_message $$
+CREATE FUNCTION pb_text_to_message(descriptor_set_json JSON, type_name TEXT, text LONGTEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE pos INT DEFAULT 1;
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF text IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_text_to_message(descriptor_set_json, type_name, text, pos, NULL, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+DELIMITER $$
+
+-- Helper function to get the wire type used by a field type
+DROP FUNCTION IF EXISTS _pb_field_type_to_wire_type $$
+CREATE FUNCTION _pb_field_type_to_wire_type(field_type INT) RETURNS INT DETERMINISTIC
+BEGIN
+	CASE
+	WHEN field_type IN (3, 4, 5, 8, 13, 14, 17, 18) THEN -- int64, uint64, int32, bool, uint32, enum, sint32, sint64
+		RETURN 0; -- VARINT
+	WHEN field_type IN (1, 6, 16) THEN -- double, fixed64, sfixed64
+		RETURN 1; -- I64
+	WHEN field_type IN (9, 11, 12) THEN -- string, message, bytes
+		RETURN 2; -- LEN
+	WHEN field_type = 10 THEN -- group
+		RETURN 3; -- SGROUP
+	WHEN field_type IN (2, 7, 15) THEN -- float, fixed32, sfixed32
+		RETURN 5; -- I32
+	ELSE
+		RETURN NULL;
+	END CASE;
+END $$
+
+-- Helper function to determine whether an enum is closed, i.e. values not declared in the enum are not allowed.
+-- Enums in proto2 files are closed, enums in proto3 files are open, and editions use the enum_type feature.
+DROP FUNCTION IF EXISTS _pb_is_enum_closed $$
+CREATE FUNCTION _pb_is_enum_closed(descriptor_set_json JSON, enum_type_name TEXT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE syntax TEXT;
+	DECLARE enum_type_feature INT;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, enum_type_name);
+	CASE syntax
+	WHEN 'proto2' THEN
+		RETURN TRUE;
+	WHEN 'editions' THEN
+		SET enum_type_feature = COALESCE(
+			JSON_EXTRACT(_pb_get_enum_descriptor(descriptor_set_json, enum_type_name), '$."3"."7"."2"'), -- options.features.enum_type
+			JSON_EXTRACT(_pb_get_file_descriptor(descriptor_set_json, enum_type_name), '$."8"."50"."2"') -- options.features.enum_type
+		);
+		RETURN enum_type_feature = 2; -- CLOSED
+	ELSE
+		RETURN FALSE;
+	END CASE;
+END $$
+
+-- Helper function to check whether a number is declared in an enum
+DROP FUNCTION IF EXISTS _pb_enum_has_value $$
+CREATE FUNCTION _pb_enum_has_value(descriptor_set_json JSON, enum_type_name TEXT, enum_value_number BIGINT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE enum_descriptor JSON;
+
+	SET enum_descriptor = _pb_get_enum_descriptor(descriptor_set_json, enum_type_name);
+	IF enum_descriptor IS NULL THEN
+		RETURN TRUE;
+	END IF;
+
+	RETURN COALESCE(JSON_CONTAINS(JSON_EXTRACT(enum_descriptor, '$."2"[*]."2"'), CAST(enum_value_number AS JSON)), FALSE); -- value[*].number
+END $$
+
+-- Helper function to append a segment to a field path
+DROP FUNCTION IF EXISTS _pb_util_join_field_path $$
+CREATE FUNCTION _pb_util_join_field_path(path TEXT, segment TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	IF path = '' THEN
+		RETURN segment;
+	END IF;
+	RETURN CONCAT(path, '.', segment);
+END $$
+
+-- Helper function to build a single problem reported by pb_message_validate()
+DROP FUNCTION IF EXISTS _pb_message_validation_problem $$
+CREATE FUNCTION _pb_message_validation_problem(path TEXT, code TEXT, message TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN JSON_OBJECT('path', path, 'code', code, 'message', message);
+END $$
+
+-- Helper function to strip the `function_name: ` prefix and the error code suffix from an error message raised by the wire format readers
+DROP FUNCTION IF EXISTS _pb_util_strip_error_prefix $$
+CREATE FUNCTION _pb_util_strip_error_prefix(message_text TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	IF message_text LIKE '% (PB\_%)' THEN
+		SET message_text = LEFT(message_text, CHAR_LENGTH(message_text) - CHAR_LENGTH(SUBSTRING_INDEX(message_text, ' (PB_', -1)) - 5);
+	END IF;
+	IF message_text LIKE '\_pb\_%: %' OR message_text LIKE 'pb\_%: %' THEN
+		RETURN SUBSTRING(message_text, LOCATE(': ', message_text) + 2);
+	END IF;
+	RETURN message_text;
+END $$
+
+-- Helper procedure to validate a message against its descriptor, appending problems to the given JSON array.
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_validate $$
+CREATE PROCEDURE _pb_message_validate(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN path TEXT, IN message_depth INT, INOUT problems JSON)
+proc: BEGIN
+	DECLARE message_descriptor JSON;
+	DECLARE wire_json JSON;
+	DECLARE error_message TEXT;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE field_path TEXT;
+	DECLARE element_path TEXT;
+	DECLARE is_repeated BOOLEAN;
+	DECLARE is_required BOOLEAN;
+	DECLARE is_closed_enum BOOLEAN;
+	DECLARE expected_wire_type INT;
+	DECLARE known_field_numbers JSON;
+
+	-- Wire elements
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE element JSON;
+	DECLARE wire_type INT;
+	DECLARE value_index INT;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE merged_message LONGBLOB;
+	DECLARE value_size INT;
+
+	-- Unknown fields
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT;
+
+	-- Reported instead of raising PB_DEPTH_LIMIT_EXCEEDED, since bad input never raises errors here
+	IF message_depth > 100 THEN
+		SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(path, 'DEPTH_LIMIT_EXCEEDED', 'message nesting depth exceeds 100'));
+		LEAVE proc;
+	END IF;
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(path, 'UNKNOWN_MESSAGE_TYPE', CONCAT('message type `', full_type_name, '` not found in descriptor set')));
+		LEAVE proc;
+	END IF;
+
+	-- Malformed wire data makes the rest of the message unreadable, so it is reported alone
+	BEGIN
+		DECLARE EXIT HANDLER FOR SQLEXCEPTION
+		BEGIN
+			GET DIAGNOSTICS CONDITION 1 error_message = MESSAGE_TEXT;
+		END;
+		CALL _pb_message_to_wire_json(buf, wire_json);
+	END;
+
+	IF error_message IS NOT NULL THEN
+		SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(path, 'MALFORMED_WIRE_DATA', _pb_util_strip_error_prefix(error_message)));
+		LEAVE proc;
+	END IF;
+
+	SET known_field_numbers = JSON_ARRAY();
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+	SET field_index = 0;
+
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET is_repeated = JSON_EXTRACT(field_descriptor, '$."4"') = 3; -- label = LABEL_REPEATED
+		SET is_required = JSON_EXTRACT(field_descriptor, '$."4"') = 2 -- label = LABEL_REQUIRED
+			OR COALESCE(JSON_EXTRACT(field_descriptor, '$."8"."21"."1"') = 3, FALSE); -- options.features.field_presence = LEGACY_REQUIRED
+		SET field_path = _pb_util_join_field_path(path, field_name);
+		SET known_field_numbers = JSON_ARRAY_APPEND(known_field_numbers, '$', field_number);
+
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		SET element_count = COALESCE(JSON_LENGTH(elements), 0);
+
+		IF element_count = 0 AND is_required THEN
+			SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(field_path, 'MISSING_REQUIRED_FIELD', CONCAT('required field `', field_name, '` is missing')));
+		END IF;
+
+		SET expected_wire_type = _pb_field_type_to_wire_type(field_type);
+		SET is_closed_enum = field_type = 14 AND _pb_is_enum_closed(descriptor_set_json, field_type_name);
+		SET merged_message = NULL;
+		SET value_index = 0;
+		SET element_index = 0;
+
+		WHILE element_index < element_count DO
+			SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+			SET wire_type = JSON_EXTRACT(element, '$.t');
+			SET element_path = IF(is_repeated, CONCAT(field_path, '[', value_index, ']'), field_path);
+
+			IF wire_type = expected_wire_type THEN
+				IF field_type = 9 THEN -- string
+					IF NOT _pb_util_is_valid_utf8(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')))) THEN
+						SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(element_path, 'INVALID_UTF8', CONCAT('string field `', field_name, '` contains invalid UTF-8')));
+					END IF;
+				ELSEIF field_type = 11 THEN -- message
+					SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
+					IF is_repeated THEN
+						CALL _pb_message_validate(descriptor_set_json, field_type_name, bytes_value, element_path, message_depth + 1, problems);
+					ELSE
+						-- Occurrences of a singular message field are merged, so they are validated together
+						SET merged_message = CONCAT(COALESCE(merged_message, _binary ''), bytes_value);
+					END IF;
+				ELSEIF field_type = 14 THEN -- enum
+					IF is_closed_enum AND NOT _pb_enum_has_value(descriptor_set_json, field_type_name, _pb_util_reinterpret_uint64_as_int64(JSON_EXTRACT(element, '$.v'))) THEN
+						SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(element_path, 'ENUM_VALUE_OUT_OF_RANGE', CONCAT('value ', _pb_util_reinterpret_uint64_as_int64(JSON_EXTRACT(element, '$.v')), ' is not declared in enum `', field_type_name, '`')));
+					END IF;
+				END IF;
+				SET value_index = value_index + 1;
+
+			ELSEIF wire_type = 2 AND is_repeated AND expected_wire_type IN (0, 1, 5) THEN
+				-- Packed repeated field
+				SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
+				IF expected_wire_type = 0 THEN
+					SET error_message = NULL;
+					BEGIN
+						DECLARE EXIT HANDLER FOR SQLEXCEPTION
+						BEGIN
+							GET DIAGNOSTICS CONDITION 1 error_message = MESSAGE_TEXT;
+						END;
+						SET packed_offset = 0;
+						WHILE packed_offset < LENGTH(bytes_value) DO
+							CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+							IF is_closed_enum AND NOT _pb_enum_has_value(descriptor_set_json, field_type_name, _pb_util_reinterpret_uint64_as_int64(uint_value)) THEN
+								SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(CONCAT(field_path, '[', value_index, ']'), 'ENUM_VALUE_OUT_OF_RANGE', CONCAT('value ', _pb_util_reinterpret_uint64_as_int64(uint_value), ' is not declared in enum `', field_type_name, '`')));
+							END IF;
+							SET value_index = value_index + 1;
+						END WHILE;
+					END;
+					IF error_message IS NOT NULL THEN
+						SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(field_path, 'MALFORMED_WIRE_DATA', CONCAT('packed field `', field_name, '`: ', _pb_util_strip_error_prefix(error_message))));
+					END IF;
+				ELSE
+					SET value_size = IF(expected_wire_type = 1, 8, 4);
+					IF LENGTH(bytes_value) % value_size <> 0 THEN
+						SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(field_path, 'MALFORMED_WIRE_DATA', CONCAT('packed field `', field_name, '`: length ', LENGTH(bytes_value), ' is not a multiple of ', value_size)));
+					END IF;
+					SET value_index = value_index + LENGTH(bytes_value) DIV value_size;
+				END IF;
+
+			ELSE
+				SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(element_path, 'WIRE_TYPE_MISMATCH', CONCAT('field `', field_name, '` expects wire type ', _pb_wire_type_name(expected_wire_type), ' but got ', _pb_wire_type_name(wire_type))));
+				SET value_index = value_index + 1;
+			END IF;
+
+			SET element_index = element_index + 1;
+		END WHILE;
+
+		IF merged_message IS NOT NULL THEN
+			CALL _pb_message_validate(descriptor_set_json, field_type_name, merged_message, field_path, message_depth + 1, problems);
+		END IF;
+
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	-- Field numbers present on the wire but not declared in the message type
+	SET field_numbers = JSON_KEYS(wire_json);
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	SET field_number_index = 0;
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		IF field_number = 0 THEN
+			SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(path, 'MALFORMED_WIRE_DATA', 'invalid field number 0'));
+		ELSEIF NOT JSON_CONTAINS(known_field_numbers, CAST(field_number AS JSON)) THEN
+			SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(_pb_util_join_field_path(path, field_number), 'UNKNOWN_FIELD', CONCAT('field number ', field_number, ' is not declared in message type `', full_type_name, '`')));
+		END IF;
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+END $$
+
+-- Validates a message against its schema and returns a JSON array of problems; an empty array means the message is valid.
+-- Each problem is an object {"path": ..., "code": ..., "message": ...}. This function does not raise errors for bad input.
+DROP FUNCTION IF EXISTS pb_message_validate $$
+CREATE FUNCTION pb_message_validate(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE problems JSON;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET problems = JSON_ARRAY();
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_validate(descriptor_set_json, type_name, message, '', 1, problems);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN problems;
+END $$
+
+DELIMITER $$
+
+-- Helper function to get the paths of a field mask as a JSON array of strings.
+-- The mask can be the JSON representation of google.protobuf.FieldMask (e.g. "a.b,c"), a JSON array of paths,
+-- a JSON object {"paths": [...]}, or a serialized google.protobuf.FieldMask message.
+DROP FUNCTION IF EXISTS _pb_field_mask_get_paths $$
+CREATE FUNCTION _pb_field_mask_get_paths(mask LONGBLOB, func_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE mask_text LONGTEXT;
+	DECLARE mask_json JSON;
+	DECLARE paths JSON;
+	DECLARE path TEXT;
+	DECLARE remaining TEXT;
+
+	SET mask_text = CONVERT(mask USING utf8mb4);
+	IF JSON_VALID(mask_text) THEN
+		SET mask_json = CAST(mask_text AS JSON);
+		IF JSON_TYPE(mask_json) = 'OBJECT' THEN
+			SET mask_json = COALESCE(JSON_EXTRACT(mask_json, '$.paths'), JSON_ARRAY());
+		END IF;
+	ELSE
+		SET mask_json = _pb_wire_json_decode_wkt_field_mask_as_json(pb_message_to_wire_json(mask));
+	END IF;
+
+	CASE JSON_TYPE(mask_json)
+	WHEN 'ARRAY' THEN
+		RETURN mask_json;
+	WHEN 'STRING' THEN
+		SET paths = JSON_ARRAY();
+		SET remaining = JSON_UNQUOTE(mask_json);
+		WHILE remaining <> '' DO
+			SET path = TRIM(SUBSTRING_INDEX(remaining, ',', 1));
+			IF path <> '' THEN
+				SET paths = JSON_ARRAY_APPEND(paths, '$', path);
+			END IF;
+			SET remaining = IF(LOCATE(',', remaining) > 0, SUBSTRING(remaining, LOCATE(',', remaining) + 1), '');
+		END WHILE;
+		RETURN paths;
+	ELSE
+		SET message_text = CONCAT(func_name, ': invalid field mask `', LEFT(mask_text, 100), '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to turn field mask paths into a tree of field names, resolved against the schema.
+-- For example, paths ["a.b", "a.c", "d"] become {"a": {"b": true, "c": true}, "d": true}, where true selects the whole field.
+-- Paths may use either proto field names or JSON names. Only singular message fields can have sub-paths.
+-- A path can have at most 99 fields, since the tree of a longer path would exceed the maximum depth of a JSON document.
+DROP FUNCTION IF EXISTS _pb_field_mask_to_tree $$
+CREATE FUNCTION _pb_field_mask_to_tree(descriptor_set_json JSON, type_name TEXT, paths JSON, func_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE tree JSON;
+	DECLARE path_count INT;
+	DECLARE path_index INT DEFAULT 0;
+	DECLARE path TEXT;
+	DECLARE remaining TEXT;
+	DECLARE segment TEXT;
+	DECLARE segment_count INT;
+	DECLARE is_last BOOLEAN;
+	DECLARE current_type_name TEXT;
+	DECLARE node_path TEXT;
+	DECLARE node JSON;
+	DECLARE field_descriptor JSON;
+
+	SET tree = JSON_OBJECT();
+	SET path_count = JSON_LENGTH(paths);
+
+	WHILE path_index < path_count DO
+		SET path = JSON_UNQUOTE(JSON_EXTRACT(paths, CONCAT('$[', path_index, ']')));
+		SET remaining = path;
+		SET current_type_name = type_name;
+		SET node_path = '$';
+		SET segment_count = 0;
+
+		l1: WHILE TRUE DO
+			SET segment_count = segment_count + 1;
+			IF segment_count > 99 THEN
+				CALL _pb_signal_error('PB_DEPTH_LIMIT_EXCEEDED', func_name, 'field mask path has more than 99 fields');
+			END IF;
+
+			SET segment = SUBSTRING_INDEX(remaining, '.', 1);
+			SET is_last = LOCATE('.', remaining) = 0;
+			SET remaining = SUBSTRING(remaining, CHAR_LENGTH(segment) + 2);
+
+			SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, current_type_name, segment, func_name);
+			SET node_path = CONCAT(node_path, '."', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '"'); -- name
+			SET node = JSON_EXTRACT(tree, node_path);
+
+			IF node = CAST('true' AS JSON) THEN
+				-- The whole field is already selected by a shorter path
+				LEAVE l1;
+			END IF;
+
+			IF is_last THEN
+				SET tree = JSON_SET(tree, node_path, CAST('true' AS JSON));
+				LEAVE l1;
+			END IF;
+
+			IF JSON_EXTRACT(field_descriptor, '$."5"') <> 11 OR JSON_EXTRACT(field_descriptor, '$."4"') = 3 THEN -- not a singular message field
+				SET message_text = CONCAT(func_name, ': field `', segment, '` in path `', path, '` is not a singular message field');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			IF node IS NULL THEN
+				SET tree = JSON_SET(tree, node_path, JSON_OBJECT());
+			END IF;
+			SET current_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		END WHILE;
+
+		SET path_index = path_index + 1;
+	END WHILE;
+
+	RETURN tree;
+END $$
+
+-- Helper procedure to keep only the fields selected by a field mask tree
+DROP PROCEDURE IF EXISTS _pb_message_apply_field_mask $$
+CREATE PROCEDURE _pb_message_apply_field_mask(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN tree JSON, OUT result LONGBLOB)
+BEGIN
+	DECLARE wire_json JSON;
+	DECLARE result_wire_json JSON;
+	DECLARE field_names JSON;
+	DECLARE field_name_count INT;
+	DECLARE field_name_index INT DEFAULT 0;
+	DECLARE field_name TEXT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE subtree JSON;
+	DECLARE elements JSON;
+	DECLARE last_element JSON;
+	DECLARE sub_message LONGBLOB;
+
+	SET wire_json = pb_message_to_wire_json(message);
+	SET result_wire_json = JSON_OBJECT();
+
+	SET field_names = JSON_KEYS(tree);
+	SET field_name_count = JSON_LENGTH(field_names);
+
+	WHILE field_name_index < field_name_count DO
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_names, CONCAT('$[', field_name_index, ']')));
+		SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, full_type_name, field_name, 'pb_message_apply_field_mask');
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET subtree = JSON_EXTRACT(tree, CONCAT('$."', field_name, '"'));
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+
+		IF elements IS NOT NULL THEN
+			IF JSON_TYPE(subtree) = 'BOOLEAN' THEN
+				SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), elements);
+			ELSE
+				-- The message is kept at the position of its last occurrence, with only the selected sub-fields
+				CALL _pb_message_apply_field_mask(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')), _pb_wire_json_get_merged_message_field(wire_json, field_number), subtree, sub_message);
+				SET last_element = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, ']'));
+				SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), JSON_ARRAY(JSON_SET(last_element, '$.v', TO_BASE64(sub_message))));
+			END IF;
+		END IF;
+
+		SET field_name_index = field_name_index + 1;
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(result_wire_json);
+END $$
+
+-- Returns a copy of the message with only the fields selected by the field mask
+DROP FUNCTION IF EXISTS pb_message_apply_field_mask $$
+CREATE FUNCTION pb_message_apply_field_mask(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, mask LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL OR mask IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_apply_field_mask(descriptor_set_json, type_name, message,
+		_pb_field_mask_to_tree(descriptor_set_json, type_name, _pb_field_mask_get_paths(mask, 'pb_message_apply_field_mask'), 'pb_message_apply_field_mask'),
+		result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Helper procedure to merge the fields selected by a field mask tree from source into target
+DROP PROCEDURE IF EXISTS _pb_message_merge_with_field_mask $$
+CREATE PROCEDURE _pb_message_merge_with_field_mask(IN descriptor_set_json JSON, IN full_type_name TEXT, IN target LONGBLOB, IN source LONGBLOB, IN tree JSON, OUT result LONGBLOB)
+BEGIN
+	DECLARE target_wire_json JSON;
+	DECLARE source_wire_json JSON;
+	DECLARE field_names JSON;
+	DECLARE field_name_count INT;
+	DECLARE field_name_index INT DEFAULT 0;
+	DECLARE field_name TEXT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE subtree JSON;
+	DECLARE source_elements JSON;
+	DECLARE target_message LONGBLOB;
+	DECLARE source_message LONGBLOB;
+	DECLARE sub_message LONGBLOB;
+
+	SET target_wire_json = pb_message_to_wire_json(target);
+	SET source_wire_json = pb_message_to_wire_json(source);
+
+	SET field_names = JSON_KEYS(tree);
+	SET field_name_count = JSON_LENGTH(field_names);
+
+	WHILE field_name_index < field_name_count DO
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_names, CONCAT('$[', field_name_index, ']')));
+		SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, full_type_name, field_name, 'pb_message_merge_with_field_mask');
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET subtree = JSON_EXTRACT(tree, CONCAT('$."', field_name, '"'));
+		SET source_elements = JSON_EXTRACT(source_wire_json, CONCAT('$."', field_number, '"'));
+
+		IF JSON_TYPE(subtree) = 'BOOLEAN' THEN
+			IF field_label = 3 THEN
+				-- Repeated fields are appended; map entries with the same key are overwritten on read
+				SET target_wire_json = _pb_wire_json_append_elements(target_wire_json, field_number, source_elements);
+			ELSEIF field_type = 11 THEN
+				-- Message fields are merged recursively
+				SET source_message = _pb_wire_json_get_merged_message_field(source_wire_json, field_number);
+				IF source_message IS NOT NULL THEN
+					SET target_message = _pb_wire_json_get_merged_message_field(target_wire_json, field_number);
+					SET target_wire_json = _pb_wire_json_clear_oneof_members(descriptor_set_json, full_type_name, target_wire_json, field_descriptor);
+					SET target_wire_json = _pb_wire_json_set_len_field(target_wire_json, field_number, CONCAT(COALESCE(target_message, _binary ''), source_message));
+				END IF;
+			ELSE
+				-- Scalar fields are replaced, or cleared if they are not set in source
+				SET target_wire_json = _pb_wire_json_clear_field(target_wire_json, field_number);
+				IF source_elements IS NOT NULL THEN
+					SET target_wire_json = _pb_wire_json_clear_oneof_members(descriptor_set_json, full_type_name, target_wire_json, field_descriptor);
+					SET target_wire_json = _pb_wire_json_append_elements(target_wire_json, field_number, JSON_ARRAY(JSON_EXTRACT(source_elements, CONCAT('$[', JSON_LENGTH(source_elements) - 1, ']'))));
+				END IF;
+			END IF;
+		ELSE
+			SET target_message = _pb_wire_json_get_merged_message_field(target_wire_json, field_number);
+			SET source_message = _pb_wire_json_get_merged_message_field(source_wire_json, field_number);
+			IF target_message IS NOT NULL OR source_message IS NOT NULL THEN
+				CALL _pb_message_merge_with_field_mask(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')), COALESCE(target_message, _binary ''), COALESCE(source_message, _binary ''), subtree, sub_message);
+				IF source_message IS NOT NULL THEN
+					SET target_wire_json = _pb_wire_json_clear_oneof_members(descriptor_set_json, full_type_name, target_wire_json, field_descriptor);
+				END IF;
+				SET target_wire_json = _pb_wire_json_replace_len_field(target_wire_json, field_number, sub_message);
+			END IF;
+		END IF;
+
+		SET field_name_index = field_name_index + 1;
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(target_wire_json);
+END $$
+
+-- Merges the fields selected by the field mask from source into target, following the update semantics of google.protobuf.FieldMask
+DROP FUNCTION IF EXISTS pb_message_merge_with_field_mask $$
+CREATE FUNCTION pb_message_merge_with_field_mask(descriptor_set_json JSON, type_name TEXT, target LONGBLOB, source LONGBLOB, mask LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF target IS NULL OR source IS NULL OR mask IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_merge_with_field_mask(descriptor_set_json, type_name, target, source,
+		_pb_field_mask_to_tree(descriptor_set_json, type_name, _pb_field_mask_get_paths(mask, 'pb_message_merge_with_field_mask'), 'pb_message_merge_with_field_mask'),
+		result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Converts a message to JSON, rendering only the fields selected by the field mask
+DROP FUNCTION IF EXISTS pb_message_to_json_with_field_mask $$
+CREATE FUNCTION pb_message_to_json_with_field_mask(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, mask LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN pb_message_to_json(descriptor_set_json, type_name, pb_message_apply_field_mask(descriptor_set_json, type_name, message, mask));
+END $$
+
+DELIMITER $$
+
+-- Helper function to renumber wire elements so that they are serialized in order, starting at start_index
+DROP FUNCTION IF EXISTS _pb_wire_json_renumber_elements $$
+CREATE FUNCTION _pb_wire_json_renumber_elements(elements JSON, start_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+
+	SET element_count = JSON_LENGTH(elements);
+	WHILE element_index < element_count DO
+		SET elements = JSON_SET(elements, CONCAT('$[', element_index, '].i'), start_index + element_index);
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN elements;
+END $$
+
+-- Helper function to get the member of each oneof that is set last on the wire, as {"<oneof_index>": <field_number>}
+DROP FUNCTION IF EXISTS _pb_wire_json_get_oneof_winners $$
+CREATE FUNCTION _pb_wire_json_get_oneof_winners(message_descriptor JSON, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE winners JSON;
+	DECLARE last_indexes JSON;
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE oneof_index INT;
+	DECLARE elements JSON;
+	DECLARE last_index INT;
+
+	SET winners = JSON_OBJECT();
+	SET last_indexes = JSON_OBJECT();
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+
+		IF oneof_index IS NOT NULL AND NOT COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE) AND elements IS NOT NULL THEN -- proto3_optional
+			SET last_index = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, '].i'));
+			IF JSON_EXTRACT(last_indexes, CONCAT('$."', oneof_index, '"')) IS NULL OR JSON_EXTRACT(last_indexes, CONCAT('$."', oneof_index, '"')) < last_index THEN
+				SET last_indexes = JSON_SET(last_indexes, CONCAT('$."', oneof_index, '"'), last_index);
+				SET winners = JSON_SET(winners, CONCAT('$."', oneof_index, '"'), field_number);
+			END IF;
+		END IF;
+
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	RETURN winners;
+END $$
+
+-- Helper function to get a text that identifies the key of a serialized map entry.
+-- Entries with equal keys get the same identity, including entries without a key (which have the default key).
+DROP FUNCTION IF EXISTS _pb_message_get_map_entry_key_identity $$
+CREATE FUNCTION _pb_message_get_map_entry_key_identity(entry LONGBLOB, key_type INT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE key_elements JSON;
+
+	SET key_elements = JSON_EXTRACT(pb_message_to_wire_json(entry), '$."1"');
+	IF key_elements IS NULL THEN
+		RETURN IF(key_type IN (9, 12), '', '0'); -- string and bytes are base64-encoded on the wire
+	END IF;
+
+	RETURN JSON_UNQUOTE(JSON_EXTRACT(key_elements, CONCAT('$[', JSON_LENGTH(key_elements) - 1, '].v')));
+END $$
+
+-- Helper function to merge the wire elements of two map fields, replacing entries of a with entries of b that have the same key
+DROP FUNCTION IF EXISTS _pb_wire_json_merge_map_entries $$
+CREATE FUNCTION _pb_wire_json_merge_map_entries(elements JSON, key_type INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	DECLARE positions JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE element JSON;
+	DECLARE key_path TEXT;
+	DECLARE position INT;
+
+	SET result = JSON_ARRAY();
+	SET positions = JSON_OBJECT();
+	SET element_count = JSON_LENGTH(elements);
+
+	WHILE element_index < element_count DO
+		SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+		SET key_path = CONCAT('$."k', _pb_message_get_map_entry_key_identity(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v'))), key_type), '"');
+		SET position = JSON_EXTRACT(positions, key_path);
+		IF position IS NULL THEN
+			SET positions = JSON_SET(positions, key_path, JSON_LENGTH(result));
+			SET result = JSON_ARRAY_APPEND(result, '$', element);
+		ELSE
+			-- The later entry replaces the earlier one, at the position where the key first appeared
+			SET result = JSON_SET(result, CONCAT('$[', position, ']'), element);
+		END IF;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper procedure to merge message b into message a
+DROP PROCEDURE IF EXISTS _pb_message_merge $$
+CREATE PROCEDURE _pb_message_merge(IN descriptor_set_json JSON, IN full_type_name TEXT, IN a LONGBLOB, IN b LONGBLOB, IN message_depth INT, OUT result LONGBLOB)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE wire_json_a JSON;
+	DECLARE wire_json_b JSON;
+	DECLARE result_wire_json JSON;
+	DECLARE next_index INT DEFAULT 0;
+	DECLARE oneof_winners_a JSON;
+	DECLARE oneof_winners_b JSON;
+	DECLARE known_field_numbers JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE oneof_index INT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE has_implicit_presence BOOLEAN;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE winner_a INT;
+	DECLARE winner_b INT;
+
+	-- Wire elements
+	DECLARE elements_a JSON;
+	DECLARE elements_b JSON;
+	DECLARE elements JSON;
+	DECLARE last_element JSON;
+	DECLARE sub_message LONGBLOB;
+
+	-- Unknown fields
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT DEFAULT 0;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_merge');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_merge', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET wire_json_a = pb_message_to_wire_json(a);
+	SET wire_json_b = pb_message_to_wire_json(b);
+	SET oneof_winners_a = _pb_wire_json_get_oneof_winners(message_descriptor, wire_json_a);
+	SET oneof_winners_b = _pb_wire_json_get_oneof_winners(message_descriptor, wire_json_b);
+	SET result_wire_json = JSON_OBJECT();
+	SET known_field_numbers = JSON_ARRAY();
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	l1: WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET field_index = field_index + 1;
+
+		SET known_field_numbers = JSON_ARRAY_APPEND(known_field_numbers, '$', field_number);
+		SET elements_a = JSON_EXTRACT(wire_json_a, CONCAT('$."', field_number, '"'));
+		SET elements_b = JSON_EXTRACT(wire_json_b, CONCAT('$."', field_number, '"'));
+
+		IF elements_a IS NULL AND elements_b IS NULL THEN
+			ITERATE l1;
+		END IF;
+
+		IF field_type = 10 THEN -- TYPE_GROUP
+			SET message_text = CONCAT('pb_message_merge: unsupported field_type `', field_type, '` for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		-- Only the member of a oneof that is set last survives; b takes precedence over a
+		IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+			SET winner_a = JSON_EXTRACT(oneof_winners_a, CONCAT('$."', oneof_index, '"'));
+			SET winner_b = JSON_EXTRACT(oneof_winners_b, CONCAT('$."', oneof_index, '"'));
+			IF COALESCE(winner_b, winner_a) <> field_number THEN
+				ITERATE l1;
+			END IF;
+			IF winner_a <> field_number THEN
+				SET elements_a = NULL;
+			END IF;
+		END IF;
+
+		IF field_label = 3 THEN -- LABEL_REPEATED
+			SET elements = JSON_MERGE_PRESERVE(COALESCE(elements_a, JSON_ARRAY()), COALESCE(elements_b, JSON_ARRAY()));
+			SET map_entry_descriptor = IF(field_type = 11, _pb_get_message_descriptor(descriptor_set_json, field_type_name), NULL);
+			IF COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE) THEN -- map_entry
+				SET elements = _pb_wire_json_merge_map_entries(elements, JSON_EXTRACT(_pb_get_field_descriptor_by_name(map_entry_descriptor, 'key'), '$."5"'));
+			END IF;
+
+		ELSEIF field_type = 11 THEN -- TYPE_MESSAGE
+			-- Messages are merged recursively, which also collapses multiple occurrences into one
+			CALL _pb_message_merge(descriptor_set_json, field_type_name,
+				COALESCE(_pb_wire_json_get_merged_message_field(wire_json_a, field_number), _binary ''),
+				COALESCE(_pb_wire_json_get_merged_message_field(wire_json_b, field_number), _binary ''),
+				message_depth + 1, sub_message);
+			SET elements = JSON_ARRAY(JSON_OBJECT('i', 0, 'n', field_number, 't', 2, 'v', TO_BASE64(sub_message)));
+
+		ELSE
+			-- The last occurrence of a scalar wins. A zero value in b does not overwrite a if the field has no presence.
+			SET has_implicit_presence = syntax = 'proto3' AND NOT proto3_optional AND oneof_index IS NULL;
+			SET last_element = JSON_EXTRACT(elements_b, CONCAT('$[', JSON_LENGTH(elements_b) - 1, ']'));
+			IF last_element IS NULL OR (has_implicit_presence AND elements_a IS NOT NULL AND JSON_UNQUOTE(JSON_EXTRACT(last_element, '$.v')) IN ('0', '')) THEN
+				SET last_element = JSON_EXTRACT(elements_a, CONCAT('$[', JSON_LENGTH(elements_a) - 1, ']'));
+			END IF;
+			SET elements = JSON_ARRAY(last_element);
+		END IF;
+
+		IF JSON_LENGTH(elements) > 0 THEN
+			SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), _pb_wire_json_renumber_elements(elements, next_index));
+			SET next_index = next_index + JSON_LENGTH(elements);
+		END IF;
+	END WHILE;
+
+	-- Unknown fields of both messages are kept as they are
+	SET field_numbers = JSON_KEYS(JSON_MERGE_PATCH(wire_json_a, wire_json_b));
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		IF NOT JSON_CONTAINS(known_field_numbers, CAST(field_number AS JSON)) THEN
+			SET elements = JSON_MERGE_PRESERVE(
+				COALESCE(JSON_EXTRACT(wire_json_a, CONCAT('$."', field_number, '"')), JSON_ARRAY()),
+				COALESCE(JSON_EXTRACT(wire_json_b, CONCAT('$."', field_number, '"')), JSON_ARRAY()));
+			SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), _pb_wire_json_renumber_elements(elements, next_index));
+			SET next_index = next_index + JSON_LENGTH(elements);
+		END IF;
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(result_wire_json);
+END $$
+
+-- Merges message b into message a with the semantics of MergeFrom, and returns a message without superseded duplicates
+DROP FUNCTION IF EXISTS pb_message_merge $$
+CREATE FUNCTION pb_message_merge(descriptor_set_json JSON, type_name TEXT, a LONGBLOB, b LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF a IS NULL OR b IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_merge(descriptor_set_json, type_name, a, b, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+DELIMITER $$
+
+-- Helper function to format a map key as a path subscript accepted by pb_message_set_by_path()
+DROP FUNCTION IF EXISTS _pb_util_format_map_key_subscript $$
+CREATE FUNCTION _pb_util_format_map_key_subscript(map_key TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	RETURN CONCAT('["', REPLACE(REPLACE(map_key, '\\', '\\\\'), '"', '\\"'), '"]');
+END $$
+
+-- Helper procedure to read a non-message field (or the value of a map entry) as JSON for pb_message_diff().
+-- Unlike _pb_enum_to_json(), enum values without a name are returned as numbers.
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_diff_value $$
+CREATE PROCEDURE _pb_wire_json_get_diff_value(IN descriptor_set_json JSON, IN wire_json JSON, IN field_number INT, IN field_type INT, IN field_type_name TEXT, IN is_repeated BOOLEAN, IN has_field_presence BOOLEAN, OUT result JSON)
+BEGIN
+	DECLARE enum_numbers JSON;
+	DECLARE enum_number INT;
+	DECLARE enum_json JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+
+	IF field_type = 14 THEN -- TYPE_ENUM
+		IF is_repeated THEN
+			SET enum_numbers = pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(enum_numbers);
+			SET result = JSON_ARRAY();
+			WHILE element_index < element_count DO
+				SET enum_number = JSON_EXTRACT(enum_numbers, CONCAT('$[', element_index, ']'));
+				CALL _pb_enum_to_json(descriptor_set_json, field_type_name, enum_number, enum_json);
+				SET result = JSON_ARRAY_APPEND(result, '$', COALESCE(enum_json, CAST(enum_number AS JSON)));
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSE
+			SET enum_number = pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0));
+			CALL _pb_enum_to_json(descriptor_set_json, field_type_name, enum_number, enum_json);
+			SET result = COALESCE(enum_json, CAST(enum_number AS JSON));
+		END IF;
+	ELSE
+		CALL _pb_wire_json_get_primitive_field_as_json(wire_json, field_number, field_type, is_repeated, has_field_presence, FALSE, result);
+	END IF;
+END $$
+
+-- Helper procedure to diff the entries of a map field by key. message_depth is the depth of the message containing the map.
+DROP PROCEDURE IF EXISTS _pb_message_diff_map $$
+CREATE PROCEDURE _pb_message_diff_map(IN descriptor_set_json JSON, IN map_entry_descriptor JSON, IN old_wire_json JSON, IN new_wire_json JSON, IN field_number INT, IN path TEXT, IN message_depth INT, INOUT diff JSON)
+BEGIN
+	DECLARE map_key_type INT;
+	DECLARE map_value_type INT;
+	DECLARE map_value_type_name TEXT;
+	DECLARE old_entries JSON;
+	DECLARE new_entries JSON;
+	DECLARE entries JSON;
+	DECLARE entry_wire_json JSON;
+	DECLARE entry_count INT;
+	DECLARE entry_index INT;
+	DECLARE map_key_json JSON;
+	DECLARE map_keys JSON;
+	DECLARE map_key TEXT;
+	DECLARE key_count INT;
+	DECLARE key_index INT DEFAULT 0;
+	DECLARE key_path TEXT;
+	DECLARE old_entry JSON;
+	DECLARE new_entry JSON;
+	DECLARE old_value JSON;
+	DECLARE new_value JSON;
+	DECLARE side INT DEFAULT 0;
+
+	SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+	SET map_value_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]."5"');
+	SET map_value_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_entry_descriptor, '$."2"[1]."6"'));
+
+	-- Index the entries of both messages by key. Later entries replace earlier ones with the same key.
+	WHILE side < 2 DO
+		SET entries = pb_wire_json_get_repeated_message_field_as_json_array(IF(side = 0, old_wire_json, new_wire_json), field_number);
+		SET entry_count = JSON_LENGTH(entries);
+		SET entry_index = 0;
+		SET map_keys = JSON_OBJECT();
+		WHILE entry_index < entry_count DO
+			SET entry_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(entries, CONCAT('$[', entry_index, ']')))));
+			CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 1, map_key_type, FALSE, FALSE, FALSE, map_key_json);
+			SET map_keys = JSON_SET(map_keys, CONCAT('$.', JSON_QUOTE(JSON_UNQUOTE(map_key_json))), entry_wire_json);
+			SET entry_index = entry_index + 1;
+		END WHILE;
+		IF side = 0 THEN
+			SET old_entries = map_keys;
+		ELSE
+			SET new_entries = map_keys;
+		END IF;
+		SET side = side + 1;
+	END WHILE;
+
+	-- Visit the keys of both messages
+	SET map_keys = JSON_KEYS(JSON_MERGE_PATCH(old_entries, new_entries));
+	SET key_count = JSON_LENGTH(map_keys);
+
+	WHILE key_index < key_count DO
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(map_keys, CONCAT('$[', key_index, ']')));
+		SET key_path = CONCAT(path, _pb_util_format_map_key_subscript(map_key));
+		SET old_entry = JSON_EXTRACT(old_entries, CONCAT('$.', JSON_QUOTE(map_key)));
+		SET new_entry = JSON_EXTRACT(new_entries, CONCAT('$.', JSON_QUOTE(map_key)));
+
+		IF map_value_type = 11 THEN -- TYPE_MESSAGE
+			IF new_entry IS NULL THEN
+				CALL _pb_message_to_json(descriptor_set_json, map_value_type_name, pb_wire_json_get_message_field(old_entry, 2, _binary ''), FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), old_value);
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'remove', old_value, NULL));
+			ELSE
+				IF old_entry IS NULL THEN
+					SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'add', NULL, JSON_OBJECT()));
+				END IF;
+				CALL _pb_message_diff(descriptor_set_json, map_value_type_name,
+					COALESCE(pb_wire_json_get_message_field(old_entry, 2, NULL), _binary ''),
+					pb_wire_json_get_message_field(new_entry, 2, _binary ''),
+					key_path, message_depth + 2, diff); -- the map entry counts as a message
+			END IF;
+		ELSE
+			SET old_value = NULL;
+			SET new_value = NULL;
+			IF old_entry IS NOT NULL THEN
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, old_entry, 2, map_value_type, map_value_type_name, FALSE, FALSE, old_value);
+			END IF;
+			IF new_entry IS NOT NULL THEN
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, new_entry, 2, map_value_type, map_value_type_name, FALSE, FALSE, new_value);
+			END IF;
+
+			IF old_value IS NULL THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'add', NULL, new_value));
+			ELSEIF new_value IS NULL THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'remove', old_value, NULL));
+			ELSEIF old_value <> new_value THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'replace', old_value, new_value));
+			END IF;
+		END IF;
+
+		SET key_index = key_index + 1;
+	END WHILE;
+END $$
+
+-- Helper procedure to append the differences between two messages of the same type to diff.
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_diff $$
+CREATE PROCEDURE _pb_message_diff(IN descriptor_set_json JSON, IN full_type_name TEXT, IN old_message LONGBLOB, IN new_message LONGBLOB, IN path TEXT, IN message_depth INT, INOUT diff JSON)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE old_wire_json JSON;
+	DECLARE new_wire_json JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE oneof_index INT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE is_repeated BOOLEAN;
+	DECLARE is_map BOOLEAN;
+	DECLARE has_field_presence BOOLEAN;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE field_path TEXT;
+	DECLARE element_path TEXT;
+
+	-- Values
+	DECLARE old_value JSON;
+	DECLARE new_value JSON;
+	DECLARE old_values JSON;
+	DECLARE new_values JSON;
+	DECLARE default_value JSON;
+	DECLARE old_sub_message LONGBLOB;
+	DECLARE new_sub_message LONGBLOB;
+	DECLARE old_count INT;
+	DECLARE new_count INT;
+	DECLARE element_index INT;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_diff');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_diff', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET old_wire_json = pb_message_to_wire_json(old_message);
+	SET new_wire_json = pb_message_to_wire_json(new_message);
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET field_index = field_index + 1;
+
+		SET is_repeated = (field_label = 3); -- LABEL_REPEATED
+		SET field_path = _pb_util_join_field_path(path, field_name);
+
+		SET is_map = FALSE;
+		IF field_type = 11 AND is_repeated THEN -- TYPE_MESSAGE
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		SET has_field_presence =
+			(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+			OR (syntax = 'proto3'
+				AND (
+					(field_label = 1 AND proto3_optional) -- proto3 optional
+					OR (field_label <> 3 AND field_type = 11) -- message fields
+					OR (oneof_index IS NOT NULL) -- oneof fields
+				));
+
+		IF field_type = 10 THEN -- TYPE_GROUP
+			SET message_text = CONCAT('pb_message_diff: unsupported field_type `', field_type, '` for field `', field_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+
+		ELSEIF is_map THEN
+			CALL _pb_message_diff_map(descriptor_set_json, map_entry_descriptor, old_wire_json, new_wire_json, field_number, field_path, message_depth, diff);
+
+		ELSEIF is_repeated THEN
+			IF field_type = 11 THEN -- TYPE_MESSAGE
+				SET old_count = pb_wire_json_get_repeated_message_field_count(old_wire_json, field_number);
+				SET new_count = pb_wire_json_get_repeated_message_field_count(new_wire_json, field_number);
+			ELSE
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, old_wire_json, field_number, field_type, field_type_name, TRUE, FALSE, old_values);
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, new_wire_json, field_number, field_type, field_type_name, TRUE, FALSE, new_values);
+				SET old_count = JSON_LENGTH(old_values);
+				SET new_count = JSON_LENGTH(new_values);
+			END IF;
+
+			-- Elements are compared by index
+			SET element_index = 0;
+			WHILE element_index < GREATEST(old_count, new_count) DO
+				SET element_path = CONCAT(field_path, '[', element_index, ']');
+				IF field_type = 11 THEN -- TYPE_MESSAGE
+					IF element_index >= old_count THEN
+						SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(element_path, 'add', NULL, JSON_OBJECT()));
+						SET old_sub_message = _binary '';
+					ELSE
+						SET old_sub_message = pb_wire_json_get_repeated_message_field_element(old_wire_json, field_number, element_index);
+					END IF;
+					IF element_index < new_count THEN
+						CALL _pb_message_diff(descriptor_set_json, field_type_name, old_sub_message, pb_wire_json_get_repeated_message_field_element(new_wire_json, field_number, element_index), element_path, message_depth + 1, diff);
+					END IF;
+				ELSE
+					SET old_value = JSON_EXTRACT(old_values, CONCAT('$[', element_index, ']'));
+					SET new_value = JSON_EXTRACT(new_values, CONCAT('$[', element_index, ']'));
+					IF old_value IS NULL THEN
+						SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(element_path, 'add', NULL, new_value));
+					ELSEIF new_value IS NOT NULL AND old_value <> new_value THEN
+						SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(element_path, 'replace', old_value, new_value));
+					END IF;
+				END IF;
+				SET element_index = element_index + 1;
+			END WHILE;
+
+			-- Surplus elements are removed from the end, so that the entries can be applied in order
+			SET element_index = old_count - 1;
+			WHILE element_index >= new_count DO
+				IF field_type = 11 THEN -- TYPE_MESSAGE
+					CALL _pb_message_to_json(descriptor_set_json, field_type_name, pb_wire_json_get_repeated_message_field_element(old_wire_json, field_number, element_index), FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), old_value);
+				ELSE
+					SET old_value = JSON_EXTRACT(old_values, CONCAT('$[', element_index, ']'));
+				END IF;
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(CONCAT(field_path, '[', element_index, ']'), 'remove', old_value, NULL));
+				SET element_index = element_index - 1;
+			END WHILE;
+
+		ELSEIF field_type = 11 THEN -- TYPE_MESSAGE
+			-- Occurrences of a singular message field are merged
+			SET old_sub_message = _pb_wire_json_get_merged_message_field(old_wire_json, field_number);
+			SET new_sub_message = _pb_wire_json_get_merged_message_field(new_wire_json, field_number);
+			IF new_sub_message IS NULL THEN
+				IF old_sub_message IS NOT NULL THEN
+					CALL _pb_message_to_json(descriptor_set_json, field_type_name, old_sub_message, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), old_value);
+					SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'remove', old_value, NULL));
+				END IF;
+			ELSE
+				IF old_sub_message IS NULL THEN
+					SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'add', NULL, JSON_OBJECT()));
+				END IF;
+				CALL _pb_message_diff(descriptor_set_json, field_type_name, COALESCE(old_sub_message, _binary ''), new_sub_message, field_path, message_depth + 1, diff);
+			END IF;
+
+		ELSE
+			CALL _pb_wire_json_get_diff_value(descriptor_set_json, old_wire_json, field_number, field_type, field_type_name, FALSE, has_field_presence, old_value);
+			CALL _pb_wire_json_get_diff_value(descriptor_set_json, new_wire_json, field_number, field_type, field_type_name, FALSE, has_field_presence, new_value);
+
+			-- Without presence, the default value means the field is not set
+			IF NOT has_field_presence THEN
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, JSON_OBJECT(), field_number, field_type, field_type_name, FALSE, FALSE, default_value);
+				IF old_value = default_value THEN
+					SET old_value = NULL;
+				END IF;
+				IF new_value = default_value THEN
+					SET new_value = NULL;
+				END IF;
+			END IF;
+
+			IF old_value IS NULL THEN
+				IF new_value IS NOT NULL THEN
+					SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'add', NULL, new_value));
+				END IF;
+			ELSEIF new_value IS NULL THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'remove', old_value, NULL));
+			ELSEIF old_value <> new_value THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'replace', old_value, new_value));
+			END IF;
+		END IF;
+	END WHILE;
+END $$
+
+-- Compares two messages of the same type and returns a JSON array of {path, op, old, new} entries
+DROP FUNCTION IF EXISTS pb_message_diff $$
+CREATE FUNCTION pb_message_diff(descriptor_set_json JSON, type_name TEXT, old_message LONGBLOB, new_message LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE diff JSON;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF old_message IS NULL OR new_message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET diff = JSON_ARRAY();
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_diff(descriptor_set_json, type_name, old_message, new_message, '', 1, diff);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN diff;
+END $$
+
+-- Applies a diff returned by pb_message_diff() to a message
+DROP FUNCTION IF EXISTS pb_message_patch $$
+CREATE FUNCTION pb_message_patch(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, diff JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE entry_count INT;
+	DECLARE entry_index INT DEFAULT 0;
+	DECLARE entry JSON;
+	DECLARE path TEXT;
+	DECLARE op TEXT;
+	DECLARE new_value JSON;
+	DECLARE segments JSON;
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL OR diff IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	SET entry_count = JSON_LENGTH(diff);
+	WHILE entry_index < entry_count DO
+		SET entry = JSON_EXTRACT(diff, CONCAT('$[', entry_index, ']'));
+		SET path = JSON_UNQUOTE(JSON_EXTRACT(entry, '$.path'));
+		SET op = JSON_UNQUOTE(JSON_EXTRACT(entry, '$.op'));
+		SET new_value = JSON_EXTRACT(entry, '$.new');
+
+		SET segments = _pb_util_parse_field_path(path);
+		IF JSON_LENGTH(segments) = 0 THEN
+			SET message_text = 'pb_message_patch: path must not be empty';
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		IF op = 'remove' THEN
+			CALL _pb_message_set_by_path(descriptor_set_json, type_name, message, path, segments, 0, NULL, TRUE, FALSE, result);
+		ELSEIF op = 'add' OR op = 'replace' THEN
+			-- Messages are added empty, and their fields are set by the entries that follow
+			IF JSON_TYPE(new_value) = 'OBJECT' THEN
+				IF JSON_LENGTH(new_value) > 0 THEN
+					SET message_text = CONCAT('pb_message_patch: message value at `', path, '` must be an empty object');
+					SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+				END IF;
+				SET new_value = JSON_QUOTE('');
+			END IF;
+			CALL _pb_message_set_by_path(descriptor_set_json, type_name, message, path, segments, 0, new_value, FALSE, op = 'add', result);
+		ELSE
+			SET message_text = CONCAT('pb_message_patch: unsupported op `', COALESCE(op, 'NULL'), '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET message = result;
+		SET entry_index = entry_index + 1;
+	END WHILE;
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+
+	RETURN message;
+END $$
+
+DELIMITER $$
+
+-- Helper function to normalize a varint to the value written by protobuf serializers for the field type.
+-- Parsers truncate 32-bit values and sign-extend int32 and enum values, so differently encoded varints can decode to the same value.
+DROP FUNCTION IF EXISTS _pb_util_canonicalize_varint $$
+CREATE FUNCTION _pb_util_canonicalize_varint(value BIGINT UNSIGNED, field_type INT) RETURNS BIGINT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE low32 BIGINT UNSIGNED;
+
+	SET low32 = value & 0xFFFFFFFF;
+	CASE
+	WHEN field_type = 8 THEN -- bool
+		RETURN IF(value <> 0, 1, 0);
+	WHEN field_type IN (5, 14) THEN -- int32, enum
+		RETURN IF(low32 > 0x7FFFFFFF, low32 | 0xFFFFFFFF00000000, low32);
+	WHEN field_type IN (13, 17) THEN -- uint32, sint32
+		RETURN low32;
+	ELSE
+		RETURN value;
+	END CASE;
+END $$
+
+-- Helper procedure to sort map entries (wire elements) by key: numerically for integer and bool keys, and by UTF-8 bytes for string keys
+DROP PROCEDURE IF EXISTS _pb_wire_json_sort_map_entries $$
+CREATE PROCEDURE _pb_wire_json_sort_map_entries(IN elements JSON, IN key_type INT, OUT result JSON)
+BEGIN
+	DECLARE done BOOLEAN DEFAULT FALSE;
+	DECLARE keyed_elements JSON;
+	DECLARE element JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE key_json JSON;
+
+	DECLARE element_cursor CURSOR FOR
+		SELECT e
+		FROM JSON_TABLE(
+			keyed_elements,
+			'$[*]' COLUMNS (
+				n DECIMAL(20, 0) PATH '$.n',
+				s LONGTEXT PATH '$.s',
+				e JSON PATH '$.e'
+			)
+		) jt
+		ORDER BY n, s;
+
+	DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = TRUE;
+
+	SET keyed_elements = JSON_ARRAY();
+	SET element_count = JSON_LENGTH(elements);
+	WHILE element_index < element_count DO
+		SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+		CALL _pb_wire_json_get_primitive_field_as_json(pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')))), 1, key_type, FALSE, FALSE, TRUE, key_json);
+		CASE key_type
+		WHEN 9 THEN -- string
+			SET keyed_elements = JSON_ARRAY_APPEND(keyed_elements, '$', JSON_OBJECT('s', HEX(JSON_UNQUOTE(key_json)), 'e', element));
+		WHEN 8 THEN -- bool
+			SET keyed_elements = JSON_ARRAY_APPEND(keyed_elements, '$', JSON_OBJECT('n', IF(key_json = CAST('true' AS JSON), 1, 0), 'e', element));
+		ELSE
+			SET keyed_elements = JSON_ARRAY_APPEND(keyed_elements, '$', JSON_OBJECT('n', key_json, 'e', element));
+		END CASE;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	SET result = JSON_ARRAY();
+	OPEN element_cursor;
+	read_loop: LOOP
+		FETCH element_cursor INTO element;
+		IF done THEN
+			LEAVE read_loop;
+		END IF;
+		SET result = JSON_ARRAY_APPEND(result, '$', element);
+	END LOOP;
+	CLOSE element_cursor;
+END $$
+
+-- Helper procedure to encode all values of a repeated scalar field, packed or not, as the payload of a single packed element
+DROP PROCEDURE IF EXISTS _pb_wire_json_pack_elements $$
+CREATE PROCEDURE _pb_wire_json_pack_elements(IN elements JSON, IN field_type INT, IN field_name TEXT, OUT payload LONGBLOB)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE expected_wire_type INT;
+	DECLARE element JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE wire_type INT;
+	DECLARE packed_values LONGBLOB;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE encoded LONGBLOB;
+
+	SET expected_wire_type = _pb_field_type_to_wire_type(field_type);
+	SET payload = _binary '';
+	SET element_count = JSON_LENGTH(elements);
+
+	WHILE element_index < element_count DO
+		SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+		SET wire_type = JSON_EXTRACT(element, '$.t');
+
+		IF wire_type = 2 THEN -- LEN (packed)
+			SET packed_values = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
+			IF expected_wire_type = 0 THEN
+				-- Varints are re-encoded so that they are normalized
+				SET packed_offset = 0;
+				WHILE packed_offset < LENGTH(packed_values) DO
+					CALL _pb_wire_read_varint_as_uint64(SUBSTRING(packed_values, packed_offset + 1, 10), packed_offset, uint_value);
+					CALL _pb_wire_write_varint(_pb_util_canonicalize_varint(uint_value, field_type), encoded);
+					SET payload = CONCAT(payload, encoded);
+				END WHILE;
+			ELSE
+				SET payload = CONCAT(payload, packed_values);
+			END IF;
+		ELSEIF wire_type = expected_wire_type THEN
+			SET uint_value = CAST(JSON_EXTRACT(element, '$.v') AS UNSIGNED);
+			CASE wire_type
+			WHEN 0 THEN CALL _pb_wire_write_varint(_pb_util_canonicalize_varint(uint_value, field_type), encoded);
+			WHEN 1 THEN CALL _pb_wire_write_i64(uint_value, encoded);
+			WHEN 5 THEN CALL _pb_wire_write_i32(uint_value, encoded);
+			END CASE;
+			SET payload = CONCAT(payload, encoded);
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', 'pb_message_canonicalize', CONCAT('unexpected wire type ', wire_type, ' for field `', field_name, '`'));
+		END IF;
+
+		SET element_index = element_index + 1;
+	END WHILE;
+END $$
+
+-- Helper procedure to convert a message into its canonical form. message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_canonicalize $$
+CREATE PROCEDURE _pb_message_canonicalize(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN message_depth INT, OUT result LONGBLOB)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE wire_json JSON;
+	DECLARE result_wire_json JSON;
+	DECLARE oneof_winners JSON;
+	DECLARE known_field_numbers JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE oneof_index INT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE has_field_presence BOOLEAN;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE is_map BOOLEAN;
+
+	-- Wire elements
+	DECLARE elements JSON;
+	DECLARE element JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE canonical_elements JSON;
+	DECLARE wire_type INT;
+	DECLARE value JSON;
+	DECLARE sub_message LONGBLOB;
+	DECLARE payload LONGBLOB;
+
+	-- Output
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT DEFAULT 0;
+	DECLARE next_index INT DEFAULT 0;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_canonicalize');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_canonicalize', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET wire_json = pb_message_to_wire_json(buf);
+	SET oneof_winners = _pb_wire_json_get_oneof_winners(message_descriptor, wire_json);
+	SET result_wire_json = JSON_OBJECT();
+	SET known_field_numbers = JSON_ARRAY();
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	l1: WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET field_index = field_index + 1;
+
+		SET known_field_numbers = JSON_ARRAY_APPEND(known_field_numbers, '$', field_number);
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		IF elements IS NULL THEN
+			ITERATE l1;
+		END IF;
+
+		IF field_type = 10 THEN -- TYPE_GROUP
+			SET message_text = CONCAT('pb_message_canonicalize: unsupported field_type `', field_type, '` for field `', field_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		-- Members of a oneof superseded by a later member are dropped
+		IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+			IF JSON_EXTRACT(oneof_winners, CONCAT('$."', oneof_index, '"')) <> field_number THEN
+				ITERATE l1;
+			END IF;
+		END IF;
+
+		SET has_field_presence =
+			(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+			OR (syntax = 'proto3'
+				AND (
+					(field_label = 1 AND proto3_optional) -- proto3 optional
+					OR (field_label <> 3 AND field_type = 11) -- message fields
+					OR (oneof_index IS NOT NULL) -- oneof fields
+				));
+
+		SET is_map = FALSE;
+		IF field_type = 11 AND field_label = 3 THEN -- repeated TYPE_MESSAGE
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		SET canonical_elements = JSON_ARRAY();
+
+		IF field_type = 11 AND field_label = 3 THEN -- repeated TYPE_MESSAGE
+			IF is_map THEN
+				SET elements = _pb_wire_json_merge_map_entries(elements, JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"'));
+			END IF;
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			WHILE element_index < element_count DO
+				SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+				CALL _pb_message_canonicalize(descriptor_set_json, field_type_name, FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v'))), message_depth + 1, sub_message);
+				SET canonical_elements = JSON_ARRAY_APPEND(canonical_elements, '$', JSON_OBJECT('i', 0, 'n', field_number, 't', 2, 'v', TO_BASE64(sub_message)));
+				SET element_index = element_index + 1;
+			END WHILE;
+			IF is_map THEN
+				CALL _pb_wire_json_sort_map_entries(canonical_elements, JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"'), canonical_elements);
+			END IF;
+
+		ELSEIF field_type = 11 THEN -- TYPE_MESSAGE
+			-- Occurrences of a singular message field are merged
+			CALL _pb_message_canonicalize(descriptor_set_json, field_type_name, _pb_wire_json_get_merged_message_field(wire_json, field_number), message_depth + 1, sub_message);
+			SET canonical_elements = JSON_ARRAY(JSON_OBJECT('i', 0, 'n', field_number, 't', 2, 'v', TO_BASE64(sub_message)));
+
+		ELSEIF field_label = 3 AND field_type IN (9, 12) THEN -- repeated string, bytes
+			SET canonical_elements = elements;
+
+		ELSEIF field_label = 3 THEN
+			-- Repeated scalar fields are always packed
+			CALL _pb_wire_json_pack_elements(elements, field_type, field_name, payload);
+			IF LENGTH(payload) > 0 THEN
+				SET canonical_elements = JSON_ARRAY(JSON_OBJECT('i', 0, 'n', field_number, 't', 2, 'v', TO_BASE64(payload)));
+			END IF;
+
+		ELSE
+			-- The last occurrence of a scalar wins
+			SET element = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, ']'));
+			SET wire_type = JSON_EXTRACT(element, '$.t');
+			IF wire_type <> _pb_field_type_to_wire_type(field_type) THEN
+				CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', 'pb_message_canonicalize', CONCAT('unexpected wire type ', wire_type, ' for field `', field_name, '`'));
+			END IF;
+			SET value = JSON_EXTRACT(element, '$.v');
+			IF wire_type = 0 THEN
+				SET value = CAST(_pb_util_canonicalize_varint(CAST(value AS UNSIGNED), field_type) AS JSON);
+			END IF;
+			-- Without presence, zero values are the same as not being set
+			IF has_field_presence OR JSON_UNQUOTE(value) NOT IN ('0', '') THEN
+				SET canonical_elements = JSON_ARRAY(JSON_OBJECT('i', 0, 'n', field_number, 't', wire_type, 'v', value));
+			END IF;
+		END IF;
+
+		IF JSON_LENGTH(canonical_elements) > 0 THEN
+			SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), canonical_elements);
+		END IF;
+	END WHILE;
+
+	-- Unknown fields are kept as they are
+	SET field_numbers = JSON_KEYS(wire_json);
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		IF NOT JSON_CONTAINS(known_field_numbers, CAST(field_number AS JSON)) THEN
+			SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"')));
+		END IF;
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+
+	-- Fields are written in ascending order of field numbers. JSON_KEYS() returns shorter keys first, so numeric keys come out in numeric order.
+	SET field_numbers = JSON_KEYS(result_wire_json);
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	SET field_number_index = 0;
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		SET elements = JSON_EXTRACT(result_wire_json, CONCAT('$."', field_number, '"'));
+		SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), _pb_wire_json_renumber_elements(elements, next_index));
+		SET next_index = next_index + JSON_LENGTH(elements);
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(result_wire_json);
+END $$
+
+-- Converts a message into a canonical form, so that semantically equal messages are serialized to the same bytes
+DROP FUNCTION IF EXISTS pb_message_canonicalize $$
+CREATE FUNCTION pb_message_canonicalize(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_canonicalize(descriptor_set_json, type_name, message, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Compares two messages for semantic equality by comparing their canonical forms
+DROP FUNCTION IF EXISTS pb_message_equals $$
+CREATE FUNCTION pb_message_equals(descriptor_set_json JSON, type_name TEXT, a LONGBLOB, b LONGBLOB) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	IF a IS NULL OR b IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	RETURN pb_message_canonicalize(descriptor_set_json, type_name, a) = pb_message_canonicalize(descriptor_set_json, type_name, b);
+END $$
+
+-- Returns a stable hash of the canonical form of a message, as a hex-encoded SHA-256 digest
+DROP FUNCTION IF EXISTS pb_message_fingerprint $$
+CREATE FUNCTION pb_message_fingerprint(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS CHAR(64) DETERMINISTIC
+BEGIN
+	RETURN SHA2(pb_message_canonicalize(descriptor_set_json, type_name, message), 256);
+END $$
+
+DELIMITER $$
+
+-- Helper procedure to collect the unknown fields of a message and its nested messages as {"path", "number"} objects.
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_get_unknown_field_numbers $$
+CREATE PROCEDURE _pb_message_get_unknown_field_numbers(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN path TEXT, IN message_depth INT, INOUT result JSON)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE wire_json JSON;
+	DECLARE unknown_field_numbers JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE field_path TEXT;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE is_map BOOLEAN;
+
+	-- Processing variables
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE entry_wire_json JSON;
+	DECLARE map_key JSON;
+	DECLARE sub_message LONGBLOB;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_get_unknown_field_numbers');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		-- Well-known types may be used without being included in the descriptor set
+		IF path <> '' AND full_type_name LIKE '.google.protobuf.%' THEN
+			LEAVE proc;
+		END IF;
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_get_unknown_field_numbers', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET wire_json = pb_message_to_wire_json(buf);
+
+	SET unknown_field_numbers = JSON_KEYS(_pb_wire_json_get_unknown_fields(message_descriptor, wire_json));
+	SET element_count = JSON_LENGTH(unknown_field_numbers);
+	SET element_index = 0;
+	WHILE element_index < element_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(unknown_field_numbers, CONCAT('$[', element_index, ']')));
+		SET result = JSON_ARRAY_APPEND(result, '$', JSON_OBJECT('path', path, 'number', field_number));
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	l1: WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET field_index = field_index + 1;
+
+		IF field_type <> 11 OR JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"')) IS NULL THEN -- TYPE_MESSAGE
+			ITERATE l1;
+		END IF;
+
+		SET field_path = _pb_util_join_field_path(path, field_name);
+
+		SET is_map = FALSE;
+		IF field_label = 3 THEN -- LABEL_REPEATED
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		IF is_map THEN
+			IF JSON_EXTRACT(map_entry_descriptor, '$."2"[1]."5"') <> 11 THEN -- value is not TYPE_MESSAGE
+				ITERATE l1;
+			END IF;
+			SET elements = pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			WHILE element_index < element_count DO
+				SET entry_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))));
+				SET sub_message = pb_wire_json_get_message_field(entry_wire_json, 2, NULL);
+				IF sub_message IS NOT NULL THEN
+					CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 1, JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"'), FALSE, FALSE, FALSE, map_key);
+					CALL _pb_message_get_unknown_field_numbers(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(map_entry_descriptor, '$."2"[1]."6"')), sub_message,
+						CONCAT(field_path, _pb_util_format_map_key_subscript(IF(JSON_TYPE(map_key) = 'STRING', JSON_UNQUOTE(map_key), CAST(map_key AS CHAR)))),
+						message_depth + 2, result); -- the map entry counts as a message
+				END IF;
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSEIF field_label = 3 THEN
+			SET element_count = pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
+			SET element_index = 0;
+			WHILE element_index < element_count DO
+				CALL _pb_message_get_unknown_field_numbers(descriptor_set_json, field_type_name, pb_wire_json_get_repeated_message_field_element(wire_json, field_number, element_index),
+					CONCAT(field_path, '[', element_index, ']'), message_depth + 1, result);
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSE
+			CALL _pb_message_get_unknown_field_numbers(descriptor_set_json, field_type_name, _pb_wire_json_get_merged_message_field(wire_json, field_number), field_path, message_depth + 1, result);
+		END IF;
+	END WHILE;
+END $$
+
+-- Returns the unknown fields of a message and its nested messages as a JSON array of {"path": ..., "number": ...} objects
+DROP FUNCTION IF EXISTS pb_message_get_unknown_field_numbers $$
+CREATE FUNCTION pb_message_get_unknown_field_numbers(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET result = JSON_ARRAY();
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_get_unknown_field_numbers(descriptor_set_json, type_name, message, '', 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Helper procedure to remove the unknown fields of a message and its nested messages.
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_discard_unknown $$
+CREATE PROCEDURE _pb_message_discard_unknown(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN message_depth INT, OUT result LONGBLOB)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE wire_json JSON;
+	DECLARE unknown_field_numbers JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+
+	-- Processing variables
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE sub_message LONGBLOB;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_discard_unknown');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		-- Well-known types may be used without being included in the descriptor set
+		IF message_depth > 1 AND full_type_name LIKE '.google.protobuf.%' THEN
+			SET result = buf;
+			LEAVE proc;
+		END IF;
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_discard_unknown', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET wire_json = pb_message_to_wire_json(buf);
+
+	SET unknown_field_numbers = JSON_KEYS(_pb_wire_json_get_unknown_fields(message_descriptor, wire_json));
+	SET element_count = JSON_LENGTH(unknown_field_numbers);
+	SET element_index = 0;
+	WHILE element_index < element_count DO
+		SET wire_json = JSON_REMOVE(wire_json, CONCAT('$.', JSON_EXTRACT(unknown_field_numbers, CONCAT('$[', element_index, ']'))));
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	l1: WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET field_index = field_index + 1;
+
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		IF field_type <> 11 OR elements IS NULL THEN -- TYPE_MESSAGE
+			ITERATE l1;
+		END IF;
+
+		-- Each occurrence is processed separately, so that occurrences of a singular field are still merged by parsers.
+		-- Map entries are messages too, so unknown fields in entries and their values are removed the same way.
+		SET element_count = JSON_LENGTH(elements);
+		SET element_index = 0;
+		WHILE element_index < element_count DO
+			CALL _pb_message_discard_unknown(descriptor_set_json, field_type_name, FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, '].v')))), message_depth + 1, sub_message);
+			SET elements = JSON_SET(elements, CONCAT('$[', element_index, '].v'), TO_BASE64(sub_message));
+			SET element_index = element_index + 1;
+		END WHILE;
+		SET wire_json = JSON_SET(wire_json, CONCAT('$."', field_number, '"'), elements);
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(wire_json);
+END $$
+
+-- Removes the fields that are not declared in the schema from a message and its nested messages
+DROP FUNCTION IF EXISTS pb_message_discard_unknown $$
+CREATE FUNCTION pb_message_discard_unknown(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_discard_unknown(descriptor_set_json, type_name, message, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+DELIMITER $$
+
+-- Helper function to quote an identifier, such as a table or column name, with backticks
+DROP FUNCTION IF EXISTS _pb_util_quote_identifier $$
+CREATE FUNCTION _pb_util_quote_identifier(identifier TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	RETURN CONCAT('`', REPLACE(identifier, '`', '``'), '`');
+END $$
+
+-- Helper procedure to add a row for a single element or map entry to the pb_exploded table.
+-- number_value holds the value as a JSON number (or the enum number), and json_value the value as in pb_message_to_json().
+DROP PROCEDURE IF EXISTS _pb_message_explode_add_row $$
+CREATE PROCEDURE _pb_message_explode_add_row(IN parent_id LONGBLOB, IN element_index INT, IN map_key TEXT, IN field_type INT, IN number_value JSON, IN json_value JSON, IN message_value LONGBLOB)
+BEGIN
+	INSERT INTO pb_exploded (parent_id, element_index, map_key, int_value, uint_value, double_value, bool_value, string_value, bytes_value, message_value, json_value)
+	VALUES (
+		parent_id,
+		element_index,
+		map_key,
+		IF(field_type IN (3, 5, 14, 15, 16, 17, 18), CAST(number_value AS SIGNED), NULL), -- int64, int32, enum, sfixed32, sfixed64, sint32, sint64
+		IF(field_type IN (4, 6, 7, 13), CAST(number_value AS UNSIGNED), NULL), -- uint64, fixed64, fixed32, uint32
+		IF(field_type IN (1, 2) AND JSON_TYPE(number_value) <> 'STRING', CAST(number_value AS DOUBLE), NULL), -- double, float; NaN and infinities are strings
+		IF(field_type = 8, json_value = CAST('true' AS JSON), NULL), -- bool
+		IF(field_type = 9, JSON_UNQUOTE(json_value), NULL), -- string
+		IF(field_type = 12, FROM_BASE64(JSON_UNQUOTE(json_value)), NULL), -- bytes
+		message_value,
+		json_value
+	);
+END $$
+
+-- Helper procedure to add the rows for the repeated or map field at the path of a single message to the pb_exploded table
+DROP PROCEDURE IF EXISTS _pb_message_explode_message $$
+CREATE PROCEDURE _pb_message_explode_message(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN path TEXT, IN segments JSON, IN parent_id LONGBLOB)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segment JSON;
+	DECLARE segment_count INT;
+	DECLARE segment_index INT DEFAULT 0;
+	DECLARE field_token TEXT;
+	DECLARE repeated_index INT;
+	DECLARE map_key TEXT;
+
+	DECLARE current_type_name TEXT;
+	DECLARE current_message LONGBLOB;
+	DECLARE message_descriptor JSON;
+	DECLARE field_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE utf8_validation TEXT;
+	DECLARE wire_json JSON;
+
+	-- Field properties
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE is_map BOOLEAN;
+
+	-- Map handling
+	DECLARE map_entry_descriptor JSON;
+	DECLARE map_key_type INT;
+	DECLARE map_value_field JSON;
+	DECLARE map_value_type INT;
+	DECLARE map_value_type_name TEXT;
+	DECLARE entry_wire_json JSON;
+	DECLARE found_entry LONGBLOB;
+	DECLARE map_key_json JSON;
+
+	-- Elements
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE number_values JSON;
+	DECLARE json_values JSON;
+	DECLARE number_value JSON;
+	DECLARE json_value JSON;
+	DECLARE message_value LONGBLOB;
+
+	SET current_type_name = full_type_name;
+	SET current_message = message;
+	SET segment_count = JSON_LENGTH(segments);
+
+	WHILE segment_index < segment_count DO
+		SET segment = JSON_EXTRACT(segments, CONCAT('$[', segment_index, ']'));
+		SET field_token = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.f'));
+		SET repeated_index = JSON_EXTRACT(segment, '$.i');
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.k'));
+
+		SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, current_type_name);
+		IF message_descriptor IS NULL THEN
+			CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_explode', CONCAT('message type `', current_type_name, '` not found in descriptor set'));
+		END IF;
+
+		SET field_descriptor = _pb_get_field_descriptor_by_name(message_descriptor, field_token);
+		IF field_descriptor IS NULL THEN
+			SET message_text = CONCAT('pb_message_explode: field `', field_token, '` not found in message type `', current_type_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+
+		SET is_map = FALSE;
+		IF field_type = 11 AND field_label = 3 THEN -- repeated TYPE_MESSAGE
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		IF is_map AND repeated_index IS NOT NULL THEN
+			-- Unquoted integer subscripts on map fields are integer keys
+			SET map_key = CAST(repeated_index AS CHAR);
+			SET repeated_index = NULL;
+		END IF;
+
+		SET wire_json = pb_message_to_wire_json(current_message);
+
+		IF segment_index = segment_count - 1 THEN
+			IF field_label <> 3 OR repeated_index IS NOT NULL OR map_key IS NOT NULL THEN
+				SET message_text = CONCAT('pb_message_explode: path `', path, '` does not end with a repeated or map field');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			IF field_type = 10 THEN -- TYPE_GROUP
+				SET message_text = CONCAT('pb_message_explode: unsupported field_type `', field_type, '` for field `', field_token, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET utf8_validation = _pb_get_utf8_validation(descriptor_set_json, current_type_name);
+
+			IF is_map THEN
+				SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+				SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]');
+				SET map_value_type = JSON_EXTRACT(map_value_field, '$."5"');
+				SET map_value_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
+
+				SET elements = _pb_wire_json_merge_map_entries(COALESCE(JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"')), JSON_ARRAY()), map_key_type);
+				SET element_count = JSON_LENGTH(elements);
+				SET element_index = 0;
+				WHILE element_index < element_count DO
+					SET entry_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, '].v')))));
+					CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 1, map_key_type, FALSE, FALSE, FALSE, map_key_json);
+
+					SET number_value = NULL;
+					SET message_value = NULL;
+					IF map_value_type = 11 THEN -- TYPE_MESSAGE
+						SET message_value = pb_wire_json_get_message_field(entry_wire_json, 2, _binary '');
+						CALL _pb_message_to_json(descriptor_set_json, map_value_type_name, message_value, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), json_value);
+					ELSE
+						IF map_value_type = 14 THEN -- TYPE_ENUM
+							SET number_value = CAST(pb_wire_json_get_enum_field(entry_wire_json, 2, 0) AS JSON);
+						ELSE
+							CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 2, map_value_type, FALSE, FALSE, TRUE, number_value);
+						END IF;
+						-- Map values have no presence, so the entry is read as proto3 to get the default for a missing value
+						CALL _pb_wire_json_get_field_as_json(descriptor_set_json, 'proto3', _pb_get_field_utf8_validation(field_descriptor, utf8_validation), entry_wire_json, map_value_field, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), json_value);
+					END IF;
+
+					CALL _pb_message_explode_add_row(parent_id, element_index, JSON_UNQUOTE(map_key_json), map_value_type, number_value, json_value, message_value);
+					SET element_index = element_index + 1;
+				END WHILE;
+			ELSE
+				SET syntax = _pb_get_file_syntax(descriptor_set_json, current_type_name);
+				CALL _pb_wire_json_get_field_as_json(descriptor_set_json, syntax, utf8_validation, wire_json, field_descriptor, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), json_values);
+
+				SET number_values = NULL;
+				IF field_type = 14 THEN -- TYPE_ENUM
+					SET number_values = pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+				ELSEIF field_type <> 11 THEN -- TYPE_MESSAGE
+					CALL _pb_wire_json_get_primitive_field_as_json(wire_json, field_number, field_type, TRUE, FALSE, TRUE, number_values);
+				END IF;
+
+				SET element_count = JSON_LENGTH(json_values);
+				SET element_index = 0;
+				WHILE element_index < element_count DO
+					SET json_value = JSON_EXTRACT(json_values, CONCAT('$[', element_index, ']'));
+					SET number_value = JSON_EXTRACT(number_values, CONCAT('$[', element_index, ']'));
+					SET message_value = NULL;
+					IF field_type = 11 THEN -- TYPE_MESSAGE
+						SET message_value = pb_wire_json_get_repeated_message_field_element(wire_json, field_number, element_index);
+					END IF;
+
+					CALL _pb_message_explode_add_row(parent_id, element_index, NULL, field_type, number_value, json_value, message_value);
+					SET element_index = element_index + 1;
+				END WHILE;
+			END IF;
+
+			LEAVE proc;
+		END IF;
+
+		-- Intermediate segment: descend into a nested message
+		IF is_map THEN
+			SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+			SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]');
+			IF map_key IS NULL OR JSON_EXTRACT(map_value_field, '$."5"') <> 11 THEN
+				SET message_text = CONCAT('pb_message_explode: cannot descend into map field `', field_token, '` in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET found_entry = _pb_wire_json_get_map_entry(wire_json, field_number, map_key_type, map_key);
+			IF found_entry IS NULL THEN
+				LEAVE proc;
+			END IF;
+
+			SET current_message = pb_message_get_message_field(found_entry, 2, _binary '');
+			SET current_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
+		ELSEIF field_type <> 11 THEN
+			SET message_text = CONCAT('pb_message_explode: field `', field_token, '` is not a message field in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		ELSEIF field_label = 3 THEN
+			IF repeated_index IS NULL THEN
+				SET message_text = CONCAT('pb_message_explode: repeated field `', field_token, '` requires an index in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET element_count = pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
+			IF repeated_index < 0 THEN
+				SET repeated_index = element_count + repeated_index;
+			END IF;
+			IF repeated_index < 0 OR repeated_index >= element_count THEN
+				LEAVE proc;
+			END IF;
+
+			SET current_message = pb_wire_json_get_repeated_message_field_element(wire_json, field_number, repeated_index);
+			SET current_type_name = field_type_name;
+		ELSE
+			SET current_message = _pb_wire_json_get_merged_message_field(wire_json, field_number);
+			IF current_message IS NULL THEN
+				LEAVE proc;
+			END IF;
+			SET current_type_name = field_type_name;
+		END IF;
+
+		SET segment_index = segment_index + 1;
+	END WHILE;
+END $$
+
+-- Fills the temporary table pb_exploded with one row per element of the repeated field, or entry of the map field, at path in the messages of a table.
+-- blob_table (optionally qualified as `db.table`) must have a single-column primary key, which is copied to the parent_id column.
+-- Each row has element_index (the position of the element or entry), map_key (for map fields), the typed value columns matching
+-- the field type (int_value, uint_value, double_value, bool_value, string_value, bytes_value or message_value) and json_value.
+DROP PROCEDURE IF EXISTS pb_message_explode $$
+CREATE PROCEDURE pb_message_explode(IN descriptor_set_json JSON, IN type_name TEXT, IN blob_table TEXT, IN blob_column TEXT, IN path TEXT)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segments JSON;
+	DECLARE table_schema_name TEXT;
+	DECLARE table_name TEXT;
+	DECLARE table_reference TEXT;
+	DECLARE primary_key_count INT;
+	DECLARE primary_key_column TEXT;
+	DECLARE done BOOLEAN DEFAULT FALSE;
+	DECLARE parent_id LONGBLOB;
+	DECLARE message LONGBLOB;
+
+	DECLARE source_cursor CURSOR FOR SELECT s.parent_id, s.message FROM _pb_explode_source s;
+	DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = TRUE;
+
+	IF _pb_get_message_descriptor(descriptor_set_json, type_name) IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_explode', CONCAT('message type `', type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET segments = _pb_util_parse_field_path(path);
+	IF JSON_LENGTH(segments) = 0 THEN
+		SET message_text = CONCAT('pb_message_explode: path `', path, '` does not end with a repeated or map field');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	IF LOCATE('.', blob_table) > 0 THEN
+		SET table_schema_name = SUBSTRING_INDEX(blob_table, '.', 1);
+		SET table_name = SUBSTRING(blob_table, LENGTH(table_schema_name) + 2);
+	ELSE
+		SET table_schema_name = DATABASE();
+		SET table_name = blob_table;
+	END IF;
+	SET table_reference = CONCAT(_pb_util_quote_identifier(table_schema_name), '.', _pb_util_quote_identifier(table_name));
+
+	SELECT COUNT(*), MIN(s.COLUMN_NAME) INTO primary_key_count, primary_key_column
+	FROM information_schema.STATISTICS s
+	WHERE s.TABLE_SCHEMA = table_schema_name AND s.TABLE_NAME = table_name AND s.INDEX_NAME = 'PRIMARY';
+	IF primary_key_count <> 1 THEN
+		SET message_text = CONCAT('pb_message_explode: table `', blob_table, '` must have a single-column primary key');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	-- The parent_id column takes the type of the primary key column
+	DROP TEMPORARY TABLE IF EXISTS pb_exploded;
+	SET @_pb_explode_statement = CONCAT('CREATE TEMPORARY TABLE pb_exploded AS SELECT ', _pb_util_quote_identifier(primary_key_column), ' AS parent_id FROM ', table_reference, ' LIMIT 0');
+	PREPARE _pb_explode_statement FROM @_pb_explode_statement;
+	EXECUTE _pb_explode_statement;
+	DEALLOCATE PREPARE _pb_explode_statement;
+
+	ALTER TABLE pb_exploded
+		ADD COLUMN element_index INT NOT NULL,
+		ADD COLUMN map_key TEXT,
+		ADD COLUMN int_value BIGINT,
+		ADD COLUMN uint_value BIGINT UNSIGNED,
+		ADD COLUMN double_value DOUBLE,
+		ADD COLUMN bool_value BOOLEAN,
+		ADD COLUMN string_value LONGTEXT,
+		ADD COLUMN bytes_value LONGBLOB,
+		ADD COLUMN message_value LONGBLOB,
+		ADD COLUMN json_value JSON;
+
+	DROP TEMPORARY TABLE IF EXISTS _pb_explode_source;
+	SET @_pb_explode_statement = CONCAT('CREATE TEMPORARY TABLE _pb_explode_source AS SELECT ', _pb_util_quote_identifier(primary_key_column), ' AS parent_id, ',
+		_pb_util_quote_identifier(blob_column), ' AS message FROM ', table_reference, ' WHERE ', _pb_util_quote_identifier(blob_column), ' IS NOT NULL');
+	PREPARE _pb_explode_statement FROM @_pb_explode_statement;
+	EXECUTE _pb_explode_statement;
+	DEALLOCATE PREPARE _pb_explode_statement;
+	SET @_pb_explode_statement = NULL;
+
+	OPEN source_cursor;
+	l1: LOOP
+		FETCH source_cursor INTO parent_id, message;
+		IF done THEN
+			LEAVE l1;
+		END IF;
+		CALL _pb_message_explode_message(descriptor_set_json, type_name, message, path, segments, parent_id);
+	END LOOP;
+	CLOSE source_cursor;
+
+	DROP TEMPORARY TABLE _pb_explode_source;
+END $$
@@ -2,6 +2,221 @@
 
 DELIMITER $$
 
+-- Decoder errors are raised with a distinct SQLSTATE and MYSQL_ERRNO per error code (see docs/function-reference.md).
+-- The message has the form '<function>: <detail> (<code>)'. Procedures that decode a whole message add the byte offset
+-- of the failing element and its field number, e.g. '(PB_TRUNCATED_VARINT, offset 3, field 2)'.
+DROP PROCEDURE IF EXISTS _pb_signal_error $$
+CREATE PROCEDURE _pb_signal_error(IN code TEXT, IN function_name TEXT, IN detail TEXT)
+BEGIN
+	DECLARE message_text TEXT DEFAULT CONCAT(function_name, ': ', detail, ' (', code, ')');
+
+	CASE code
+	WHEN 'PB_TRUNCATED_VARINT' THEN
+		SIGNAL SQLSTATE '45001' SET MYSQL_ERRNO = 45001, MESSAGE_TEXT = message_text;
+	WHEN 'PB_VARINT_OVERFLOW' THEN
+		SIGNAL SQLSTATE '45002' SET MYSQL_ERRNO = 45002, MESSAGE_TEXT = message_text;
+	WHEN 'PB_LENGTH_OUT_OF_BOUNDS' THEN
+		SIGNAL SQLSTATE '45003' SET MYSQL_ERRNO = 45003, MESSAGE_TEXT = message_text;
+	WHEN 'PB_UNSUPPORTED_WIRE_TYPE' THEN
+		SIGNAL SQLSTATE '45004' SET MYSQL_ERRNO = 45004, MESSAGE_TEXT = message_text;
+	WHEN 'PB_WIRE_TYPE_MISMATCH' THEN
+		SIGNAL SQLSTATE '45005' SET MYSQL_ERRNO = 45005, MESSAGE_TEXT = message_text;
+	WHEN 'PB_TYPE_NOT_FOUND' THEN
+		SIGNAL SQLSTATE '45006' SET MYSQL_ERRNO = 45006, MESSAGE_TEXT = message_text;
+	WHEN 'PB_INVALID_UTF8' THEN
+		SIGNAL SQLSTATE '45007' SET MYSQL_ERRNO = 45007, MESSAGE_TEXT = message_text;
+	WHEN 'PB_DEPTH_LIMIT_EXCEEDED' THEN
+		SIGNAL SQLSTATE '45008' SET MYSQL_ERRNO = 45008, MESSAGE_TEXT = message_text;
+	WHEN 'PB_OUTPUT_SIZE_LIMIT_EXCEEDED' THEN
+		SIGNAL SQLSTATE '45009' SET MYSQL_ERRNO = 45009, MESSAGE_TEXT = message_text;
+	WHEN 'PB_ELEMENT_LIMIT_EXCEEDED' THEN
+		SIGNAL SQLSTATE '45010' SET MYSQL_ERRNO = 45010, MESSAGE_TEXT = message_text;
+	WHEN 'PB_NON_FINITE_VALUE' THEN
+		SIGNAL SQLSTATE '45011' SET MYSQL_ERRNO = 45011, MESSAGE_TEXT = message_text;
+	ELSE
+		SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to add the byte offset and field number to the message of an error raised by _pb_signal_error.
+-- Messages that are already annotated, or not raised by _pb_signal_error, are returned as is.
+DROP FUNCTION IF EXISTS _pb_util_annotate_error $$
+CREATE FUNCTION _pb_util_annotate_error(message_text TEXT, byte_offset BIGINT, field_number INT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	IF message_text NOT LIKE '% (PB\_%)' OR LOCATE(', offset ', message_text) > 0 THEN
+		RETURN message_text;
+	END IF;
+	RETURN CONCAT(LEFT(message_text, CHAR_LENGTH(message_text) - 1), ', offset ', byte_offset, IF(field_number IS NULL, '', CONCAT(', field ', field_number)), ')');
+END $$
+
+-- Helper function to prepend the number of the enclosing field to the field path of an annotated error message,
+-- so that errors in nested messages are reported with the path from the outermost message (e.g. 'field 4.2').
+DROP FUNCTION IF EXISTS _pb_util_prefix_error_field_path $$
+CREATE FUNCTION _pb_util_prefix_error_field_path(message_text TEXT, field_number INT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	IF message_text NOT LIKE '% (PB\_%)' THEN
+		RETURN message_text;
+	END IF;
+	IF LOCATE(', field ', message_text) > 0 THEN
+		RETURN REPLACE(message_text, ', field ', CONCAT(', field ', field_number, '.'));
+	END IF;
+	RETURN CONCAT(LEFT(message_text, CHAR_LENGTH(message_text) - 1), ', field ', field_number, ')');
+END $$
+
+-- Helper function to check whether a byte string is well-formed UTF-8.
+-- Overlong encodings, surrogates and code points above U+10FFFF are rejected.
+DROP FUNCTION IF EXISTS _pb_util_is_valid_utf8 $$
+CREATE FUNCTION _pb_util_is_valid_utf8(value LONGBLOB) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value_length INT;
+	DECLARE i INT DEFAULT 1;
+	DECLARE j INT;
+	DECLARE b INT;
+	DECLARE continuation_byte INT;
+	DECLARE sequence_length INT;
+	DECLARE min_second_byte INT;
+	DECLARE max_second_byte INT;
+
+	IF value IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	-- Fast path: bytes that survive a round trip through utf8mb4 and utf32 are well-formed, since decoding replaces
+	-- ill-formed sequences with '?'. Going through utf32 makes sure that they are decoded even where MySQL copies a binary
+	-- string into utf8mb4 as is. Strings with 0xED, which may start a surrogate, are left to the loop below in case MySQL
+	-- decodes them, as are all strings that fail this check.
+	IF CONVERT(CONVERT(CONVERT(CONVERT(value USING utf8mb4) USING utf32) USING utf8mb4) USING binary) = value AND LOCATE(_binary X'ED', value) = 0 THEN
+		RETURN TRUE;
+	END IF;
+
+	SET value_length = LENGTH(value);
+
+	WHILE i <= value_length DO
+		SET b = ASCII(SUBSTRING(value, i, 1));
+		IF b < 0x80 THEN
+			SET i = i + 1;
+		ELSE
+			SET sequence_length = CASE
+				WHEN b BETWEEN 0xC2 AND 0xDF THEN 2
+				WHEN b BETWEEN 0xE0 AND 0xEF THEN 3
+				WHEN b BETWEEN 0xF0 AND 0xF4 THEN 4
+				ELSE 0
+			END;
+			IF sequence_length = 0 OR i + sequence_length - 1 > value_length THEN
+				RETURN FALSE;
+			END IF;
+
+			SET min_second_byte = CASE b WHEN 0xE0 THEN 0xA0 WHEN 0xF0 THEN 0x90 ELSE 0x80 END; -- reject overlong encodings
+			SET max_second_byte = CASE b WHEN 0xED THEN 0x9F WHEN 0xF4 THEN 0x8F ELSE 0xBF END; -- reject surrogates and values above U+10FFFF
+			SET j = 1;
+			WHILE j < sequence_length DO
+				SET continuation_byte = ASCII(SUBSTRING(value, i + j, 1));
+				IF continuation_byte < IF(j = 1, min_second_byte, 0x80) OR continuation_byte > IF(j = 1, max_second_byte, 0xBF) THEN
+					RETURN FALSE;
+				END IF;
+				SET j = j + 1;
+			END WHILE;
+			SET i = i + sequence_length;
+		END IF;
+	END WHILE;
+
+	RETURN TRUE;
+END $$
+
+-- Helper function to replace ill-formed sequences in a byte string with U+FFFD (EF BF BD). As recommended by the
+-- Unicode Standard, each maximal subpart of an ill-formed sequence is replaced by a single U+FFFD.
+DROP FUNCTION IF EXISTS _pb_util_replace_invalid_utf8 $$
+CREATE FUNCTION _pb_util_replace_invalid_utf8(value LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE value_length INT;
+	DECLARE i INT DEFAULT 1;
+	DECLARE j INT;
+	DECLARE b INT;
+	DECLARE continuation_byte INT;
+	DECLARE sequence_length INT;
+	DECLARE min_second_byte INT;
+	DECLARE max_second_byte INT;
+	DECLARE copied_until INT DEFAULT 1; -- bytes before this position are already in result
+	DECLARE result LONGBLOB DEFAULT _binary '';
+
+	IF value IS NULL OR _pb_util_is_valid_utf8(value) THEN
+		RETURN value;
+	END IF;
+
+	SET value_length = LENGTH(value);
+
+	WHILE i <= value_length DO
+		SET b = ASCII(SUBSTRING(value, i, 1));
+		IF b < 0x80 THEN
+			SET i = i + 1;
+		ELSE
+			SET sequence_length = CASE
+				WHEN b BETWEEN 0xC2 AND 0xDF THEN 2
+				WHEN b BETWEEN 0xE0 AND 0xEF THEN 3
+				WHEN b BETWEEN 0xF0 AND 0xF4 THEN 4
+				ELSE 0
+			END;
+
+			-- Count the lead byte and the continuation bytes that are valid so far
+			SET j = 1;
+			IF sequence_length > 0 THEN
+				SET min_second_byte = CASE b WHEN 0xE0 THEN 0xA0 WHEN 0xF0 THEN 0x90 ELSE 0x80 END; -- reject overlong encodings
+				SET max_second_byte = CASE b WHEN 0xED THEN 0x9F WHEN 0xF4 THEN 0x8F ELSE 0xBF END; -- reject surrogates and values above U+10FFFF
+				WHILE j < sequence_length AND i + j <= value_length DO
+					SET continuation_byte = ASCII(SUBSTRING(value, i + j, 1));
+					IF continuation_byte < IF(j = 1, min_second_byte, 0x80) OR continuation_byte > IF(j = 1, max_second_byte, 0xBF) THEN
+						SET sequence_length = -1; -- stop here
+					ELSE
+						SET j = j + 1;
+					END IF;
+				END WHILE;
+			END IF;
+
+			IF j = sequence_length THEN
+				SET i = i + j;
+			ELSE
+				SET result = CONCAT(result, SUBSTRING(value, copied_until, i - copied_until), _binary X'EFBFBD');
+				SET i = i + j;
+				SET copied_until = i;
+			END IF;
+		END IF;
+	END WHILE;
+
+	RETURN CONCAT(result, SUBSTRING(value, copied_until));
+END $$
+
+-- Helper function to apply a UTF-8 validation mode to the bytes of a string field: 'strict' raises PB_INVALID_UTF8 for
+-- ill-formed UTF-8, 'replace' replaces ill-formed sequences with U+FFFD and 'passthrough' keeps the bytes as they are.
+DROP FUNCTION IF EXISTS _pb_util_check_utf8 $$
+CREATE FUNCTION _pb_util_check_utf8(value LONGBLOB, validation_mode TEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	CASE validation_mode
+	WHEN 'strict' THEN
+		IF NOT _pb_util_is_valid_utf8(value) THEN
+			CALL _pb_signal_error('PB_INVALID_UTF8', '_pb_util_check_utf8', 'invalid UTF-8 in string field');
+		END IF;
+		RETURN value;
+	WHEN 'replace' THEN
+		RETURN _pb_util_replace_invalid_utf8(value);
+	ELSE
+		RETURN value;
+	END CASE;
+END $$
+
+-- Returns the bytes of a string field as text after applying the given UTF-8 validation mode ('strict', 'replace'
+-- or 'passthrough'). The schema-free string getters return the bytes as they are, so this is how to validate them.
+DROP FUNCTION IF EXISTS pb_utf8_check $$
+CREATE FUNCTION pb_utf8_check(value LONGBLOB, validation_mode TEXT) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE message_text TEXT;
+
+	IF validation_mode IS NULL OR validation_mode NOT IN ('strict', 'replace', 'passthrough') THEN
+		SET message_text = CONCAT('pb_utf8_check: validation_mode must be one of `strict`, `replace` or `passthrough`, but got `', COALESCE(validation_mode, 'NULL'), '`');
+		SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+	END IF;
+	RETURN CONVERT(_pb_util_check_utf8(value, validation_mode) USING utf8mb4);
+END $$
+
 DROP FUNCTION IF EXISTS _pb_util_bin_as_int32 $$
 CREATE FUNCTION _pb_util_bin_as_int32(b BLOB) RETURNS INT DETERMINISTIC
 BEGIN
@@ -30,7 +245,7 @@ BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
 	IF LENGTH(b) > 8 THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_util_bin_as_int64: value must not be longer than 4 bytes.';
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_util_bin_as_int64: value must not be longer than 8 bytes.';
 	END IF;
 
 	IF LPAD(b, 8, _binary X'00') & _binary X'8000000000000000' = _binary X'0000000000000000' THEN
@@ -46,180 +261,134 @@ BEGIN
 	RETURN CONV(HEX(b), 16, 10);
 END $$
 
+-- Wire format readers take a 0-based offset into the caller's buffer and advance it past the value they read.
+-- Stored program parameters are copied on every call, so instead of the whole buffer the caller passes only the bytes
+-- a reader can consume, i.e. SUBSTRING(buf, offset + 1, n) with n = 10 for VARINT, 4 for I32 and 8 for I64. This keeps
+-- the cost of a read independent of the buffer size, so that scanning a message is linear in its size.
+
 DROP PROCEDURE IF EXISTS _pb_wire_read_i32_as_uint32 $$
-CREATE PROCEDURE _pb_wire_read_i32_as_uint32(IN buf LONGBLOB, OUT value INT UNSIGNED, OUT tail LONGBLOB)
+CREATE PROCEDURE _pb_wire_read_i32_as_uint32(IN head VARBINARY(4), INOUT offset BIGINT, OUT value INT UNSIGNED)
 BEGIN
-	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
-
-	IF LENGTH(buf) < 4 THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_read_i32_as_uint32: Unexpected end of BLOB.';
+	IF LENGTH(head) < 4 THEN
+		CALL _pb_signal_error('PB_LENGTH_OUT_OF_BOUNDS', '_pb_wire_read_i32_as_uint32', 'Unexpected end of BLOB.');
 	END IF;
 
-	SET value = _pb_util_swap_endian_32(_pb_util_bin_as_uint32(LEFT(buf, 4)));
-	SET tail = SUBSTRING(buf, 5);
+	SET value = _pb_util_swap_endian_32(_pb_util_bin_as_uint32(head));
+	SET offset = offset + 4;
 END $$
 
 DROP PROCEDURE IF EXISTS _pb_wire_read_i64_as_uint64 $$
-CREATE PROCEDURE _pb_wire_read_i64_as_uint64(IN buf LONGBLOB, OUT value BIGINT UNSIGNED, OUT tail LONGBLOB)
-BEGIN
-	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
-
-	IF LENGTH(buf) < 8 THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_read_i64_as_uint64: Unexpected end of BLOB.';
-	END IF;
-
-	SET value = _pb_util_swap_endian_64(_pb_util_bin_as_uint64(LEFT(buf, 8)));
-	SET tail = SUBSTRING(buf, 9);
-END $$
-
-DROP PROCEDURE IF EXISTS _pb_wire_skip_i32 $$
-CREATE PROCEDURE _pb_wire_skip_i32(IN buf LONGBLOB, OUT tail LONGBLOB)
-BEGIN
-	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
-
-	IF LENGTH(buf) < 4 THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_skip_i32: Unexpected end of BLOB.';
-	END IF;
-
-	SET tail = SUBSTRING(buf, 5);
-END $$
-
-DROP PROCEDURE IF EXISTS _pb_wire_skip_i64 $$
-CREATE PROCEDURE _pb_wire_skip_i64(IN buf LONGBLOB, OUT tail LONGBLOB)
+CREATE PROCEDURE _pb_wire_read_i64_as_uint64(IN head VARBINARY(8), INOUT offset BIGINT, OUT value BIGINT UNSIGNED)
 BEGIN
-	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
-
-	IF LENGTH(buf) < 8 THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_skip_i64: Unexpected end of BLOB.';
+	IF LENGTH(head) < 8 THEN
+		CALL _pb_signal_error('PB_LENGTH_OUT_OF_BOUNDS', '_pb_wire_read_i64_as_uint64', 'Unexpected end of BLOB.');
 	END IF;
 
-	SET tail = SUBSTRING(buf, 9);
+	SET value = _pb_util_swap_endian_64(_pb_util_bin_as_uint64(head));
+	SET offset = offset + 8;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_wire_skip_len_type $$
-CREATE PROCEDURE _pb_wire_skip_len_type(IN buf LONGBLOB, OUT tail LONGBLOB)
+-- Reads the length prefix of a LEN value and advances the offset to the start of the payload. The payload itself is
+-- SUBSTRING(buf, offset + 1, value_length), which the caller extracts from its own buffer of length buf_length.
+DROP PROCEDURE IF EXISTS _pb_wire_read_len_type_header $$
+CREATE PROCEDURE _pb_wire_read_len_type_header(IN head VARBINARY(10), IN buf_length BIGINT, INOUT offset BIGINT, OUT value_length BIGINT)
 BEGIN
-	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
-
-	DECLARE len BIGINT;
+	DECLARE len BIGINT UNSIGNED;
 
-	SET tail = buf;
-	CALL _pb_wire_read_varint_as_uint64(tail, len, tail);
+	CALL _pb_wire_read_varint_as_uint64(head, offset, len);
 
-	IF LENGTH(tail) < len THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_skip_len_type: Unexpected end of BLOB.';
+	IF buf_length - offset < len THEN
+		CALL _pb_signal_error('PB_LENGTH_OUT_OF_BOUNDS', '_pb_wire_read_len_type', 'Unexpected end of BLOB.');
 	END IF;
 
-	SET tail = SUBSTRING(tail, len + 1);
+	SET value_length = len;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_wire_read_len_type $$
-CREATE PROCEDURE _pb_wire_read_len_type(IN buf LONGBLOB, OUT value LONGBLOB, OUT tail LONGBLOB)
+DROP PROCEDURE IF EXISTS _pb_wire_skip $$
+CREATE PROCEDURE _pb_wire_skip(IN head VARBINARY(10), IN buf_length BIGINT, IN wire_type INT, INOUT offset BIGINT)
 BEGIN
-	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
-
 	DECLARE len BIGINT;
 
-	SET tail = buf;
-	CALL _pb_wire_read_varint_as_uint64(tail, len, tail);
-
-	IF LENGTH(tail) < len THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_read_len_type: Unexpected end of BLOB.';
-	END IF;
-
-	SET value = LEFT(tail, len);
-	SET tail = SUBSTRING(tail, len + 1);
+	CASE wire_type
+	WHEN 0 THEN -- VARINT
+		CALL _pb_wire_skip_varint(head, offset);
+	WHEN 1 THEN -- I64
+		IF buf_length - offset < 8 THEN
+			CALL _pb_signal_error('PB_LENGTH_OUT_OF_BOUNDS', '_pb_wire_skip_i64', 'Unexpected end of BLOB.');
+		END IF;
+		SET offset = offset + 8;
+	WHEN 2 THEN -- LEN
+		CALL _pb_wire_read_len_type_header(head, buf_length, offset, len);
+		SET offset = offset + len;
+	WHEN 5 THEN -- I32
+		IF buf_length - offset < 4 THEN
+			CALL _pb_signal_error('PB_LENGTH_OUT_OF_BOUNDS', '_pb_wire_skip_i32', 'Unexpected end of BLOB.');
+		END IF;
+		SET offset = offset + 4;
+	ELSE
+		CALL _pb_signal_error('PB_UNSUPPORTED_WIRE_TYPE', '_pb_wire_skip', CONCAT('unknown wire_type (', wire_type, ')'));
+	END CASE;
 END $$
 
 DROP PROCEDURE IF EXISTS _pb_wire_skip_varint $$
-CREATE PROCEDURE _pb_wire_skip_varint(IN buf LONGBLOB, OUT tail LONGBLOB)
+CREATE PROCEDURE _pb_wire_skip_varint(IN head VARBINARY(10), INOUT offset BIGINT)
 BEGIN
-	DECLARE head INT;
-	DECLARE byte_index INT;
-	DECLARE buf_len INT;
-
-	SET byte_index = 0;
-	SET buf_len = LENGTH(buf);
+	DECLARE byte_index INT DEFAULT 0;
+	DECLARE head_len INT DEFAULT LENGTH(head);
 
 	l1: LOOP
-		IF byte_index >= buf_len THEN
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '_pb_wire_skip_varint: Unexpected end of BLOB.';
+		IF byte_index >= head_len THEN
+			IF head_len < 10 THEN
+				CALL _pb_signal_error('PB_TRUNCATED_VARINT', '_pb_wire_skip_varint', 'Unexpected end of BLOB.');
+			END IF;
+			CALL _pb_signal_error('PB_VARINT_OVERFLOW', '_pb_wire_skip_varint', 'Varint cannot exceed 10 bytes.');
 		END IF;
 
-		SET head = ORD(SUBSTRING(buf, byte_index + 1, 1));
 		SET byte_index = byte_index + 1;
 
-		IF (head & 0x80) = 0 THEN
+		IF (ORD(SUBSTRING(head, byte_index, 1)) & 0x80) = 0 THEN
 			LEAVE l1;
 		END IF;
-
-		IF byte_index > 10 THEN
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '_pb_wire_skip_varint: Varint cannot exceed 10 bytes.';
-		END IF;
 	END LOOP;
 
-	SET tail = SUBSTRING(buf, byte_index + 1);
-END $$
-
-DROP PROCEDURE IF EXISTS _pb_wire_skip $$
-CREATE PROCEDURE _pb_wire_skip(IN buf LONGBLOB, IN wire_type INT, OUT tail LONGBLOB)
-BEGIN
-	DECLARE dummy BIGINT UNSIGNED;
-	DECLARE message_text TEXT;
-
-	CASE wire_type
-	WHEN 0 THEN -- VARINT
-		CALL _pb_wire_skip_varint(buf, tail);
-	WHEN 1 THEN -- I64
-		CALL _pb_wire_skip_i64(buf, tail);
-	WHEN 2 THEN -- LEN
-		CALL _pb_wire_skip_len_type(buf, tail);
-	WHEN 5 THEN -- I32
-		CALL _pb_wire_skip_i32(buf, tail);
-	ELSE
-		SET message_text = CONCAT('_pb_wire_skip: unknown wire_type (', wire_type, ')');
-		SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
-	END CASE;
+	SET offset = offset + byte_index;
 END $$
 
 DROP PROCEDURE IF EXISTS _pb_wire_read_varint_as_uint64 $$
-CREATE PROCEDURE _pb_wire_read_varint_as_uint64(IN buf LONGBLOB, OUT value BIGINT UNSIGNED, OUT tail LONGBLOB)
+CREATE PROCEDURE _pb_wire_read_varint_as_uint64(IN head VARBINARY(10), INOUT offset BIGINT, OUT value BIGINT UNSIGNED)
 BEGIN
-	DECLARE head INT;
-	DECLARE byte_index INT;
-	DECLARE buf_len INT;
+	DECLARE head_byte INT;
+	DECLARE byte_index INT DEFAULT 0;
+	DECLARE head_len INT DEFAULT LENGTH(head);
 
 	SET value = 0;
-	SET byte_index = 0;
-	SET buf_len = LENGTH(buf);
 
 	l1: LOOP
-		IF byte_index >= buf_len THEN
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '_pb_wire_read_varint_as_uint64: Unexpected end of BLOB.';
+		IF byte_index >= head_len THEN
+			IF head_len < 10 THEN
+				CALL _pb_signal_error('PB_TRUNCATED_VARINT', '_pb_wire_read_varint_as_uint64', 'Unexpected end of BLOB.');
+			END IF;
+			CALL _pb_signal_error('PB_VARINT_OVERFLOW', '_pb_wire_read_varint_as_uint64', 'Varint cannot exceed 10 bytes.');
 		END IF;
 
-		SET head = ORD(SUBSTRING(buf, byte_index + 1, 1));
-		SET value = value + ((head & 0x7f) << (7 * byte_index));
+		SET head_byte = ORD(SUBSTRING(head, byte_index + 1, 1));
+		SET value = value + ((head_byte & 0x7f) << (7 * byte_index));
 		SET byte_index = byte_index + 1;
 
-		IF (head & 0x80) = 0 THEN
+		IF (head_byte & 0x80) = 0 THEN
 			LEAVE l1;
 		END IF;
-
-		IF byte_index > 10 THEN
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '_pb_wire_read_varint_as_uint64: Varint cannot exceed 10 bytes.';
-		END IF;
 	END LOOP;
 
-	SET tail = SUBSTRING(buf, byte_index + 1);
+	SET offset = offset + byte_index;
 END $$
 
 DROP FUNCTION IF EXISTS _pb_wire_read_varint_as_uint64 $$
 CREATE FUNCTION _pb_wire_read_varint_as_uint64(buf LONGBLOB) RETURNS BIGINT DETERMINISTIC
 BEGIN
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
 	DECLARE value BIGINT;
-	CALL _pb_wire_read_varint_as_uint64(buf, value, tail);
+	CALL _pb_wire_read_varint_as_uint64(LEFT(buf, 10), offset, value);
 	RETURN value;
 END $$
 
@@ -524,7 +693,7 @@ BEGIN
 	WHEN 4 THEN RETURN 'EGROUP';
 	WHEN 5 THEN RETURN 'I32';
 	ELSE
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_type_name: unsupported wire_type';
+		CALL _pb_signal_error('PB_UNSUPPORTED_WIRE_TYPE', '_pb_wire_type_name', CONCAT('unsupported wire_type (', num, ')'));
 	END CASE;
 END $$
 
@@ -534,39 +703,49 @@ BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE buf_length BIGINT DEFAULT LENGTH(buf);
+	DECLARE value_length BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 
-	SET tail = buf;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET field_count = 0;
 
-	WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	WHILE offset < buf_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, tag);
 
 		IF _pb_wire_get_field_number_from_tag(tag) = field_number AND _pb_wire_get_wire_type_from_tag(tag) <> 2 /* VARINT */ THEN
-			SET message_text = CONCAT('_pb_message_get_len_type_field: string or bytes value cannot be parsed from ', _pb_wire_type_name(_pb_wire_get_wire_type_from_tag(tag)), ' wire type.');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_len_type_field', CONCAT('string or bytes value cannot be parsed from ', _pb_wire_type_name(_pb_wire_get_wire_type_from_tag(tag)), ' wire type.'));
 		END IF;
 
 		CASE _pb_wire_get_wire_type_from_tag(tag)
 		WHEN 0 THEN -- VARINT
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, uint_value);
 		WHEN 1 THEN -- I64
-			CALL _pb_wire_skip_i64(tail, tail);
+			CALL _pb_wire_skip(SUBSTRING(buf, offset + 1, 10), buf_length, 1, offset);
 		WHEN 2 THEN -- LEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(buf, offset + 1, 10), buf_length, offset, value_length);
 			IF _pb_wire_get_field_number_from_tag(tag) = field_number THEN
 				IF repeated_index IS NULL OR repeated_index = field_count THEN
-					SET value = bytes_value;
+					SET value = SUBSTRING(buf, offset + 1, value_length);
 				END IF;
 				SET field_count = field_count + 1;
 			END IF;
+			SET offset = offset + value_length;
 		WHEN 5 THEN -- I32
-			CALL _pb_wire_skip_i32(tail, tail);
+			CALL _pb_wire_skip(SUBSTRING(buf, offset + 1, 10), buf_length, 5, offset);
 		ELSE
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_get_len_type_field: unsupported wire_type';
+			CALL _pb_signal_error('PB_UNSUPPORTED_WIRE_TYPE', '_pb_message_get_len_type_field', 'unsupported wire_type');
 		END CASE;
 	END WHILE;
 
@@ -582,41 +761,54 @@ BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE buf_length BIGINT DEFAULT LENGTH(buf);
+	DECLARE value_length BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE packed_value LONGBLOB;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE message_text TEXT;
 
-	SET tail = buf;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET field_count = 0;
 
-	WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	WHILE offset < buf_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, tag);
 
 		IF _pb_wire_get_field_number_from_tag(tag) = field_number AND _pb_wire_get_wire_type_from_tag(tag) <> 5 /* I32 */ AND (repeated_index IS NULL OR _pb_wire_get_wire_type_from_tag(tag) <> 2 /* LEN */) THEN
-			SET message_text = CONCAT('_pb_message_get_i32_field_as_uint32: I32 value cannot be parsed from ', _pb_wire_type_name(_pb_wire_get_wire_type_from_tag(tag)), ' wire type.');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_i32_field_as_uint32', CONCAT('I32 value cannot be parsed from ', _pb_wire_type_name(_pb_wire_get_wire_type_from_tag(tag)), ' wire type.'));
 		END IF;
 
 		CASE _pb_wire_get_wire_type_from_tag(tag)
 		WHEN 0 THEN -- VARINT
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, uint_value);
 		WHEN 1 THEN -- I64
-			CALL _pb_wire_skip_i64(tail, tail);
+			CALL _pb_wire_skip(SUBSTRING(buf, offset + 1, 10), buf_length, 1, offset);
 		WHEN 2 THEN -- LEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(buf, offset + 1, 10), buf_length, offset, value_length);
 			IF _pb_wire_get_field_number_from_tag(tag) = field_number AND repeated_index IS NOT NULL THEN
-				WHILE LENGTH(bytes_value) <> 0 DO
-					CALL _pb_wire_read_i32_as_uint32(bytes_value, uint_value, bytes_value);
+				SET bytes_value = SUBSTRING(buf, offset + 1, value_length);
+				SET packed_offset = 0;
+				WHILE packed_offset < LENGTH(bytes_value) DO
+					CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
 					IF repeated_index = field_count THEN
 						SET value = uint_value;
 					END IF;
 					SET field_count = field_count + 1;
 				END WHILE;
 			END IF;
+			SET offset = offset + value_length;
 		WHEN 5 THEN -- I32
-			CALL _pb_wire_read_i32_as_uint32(tail, uint_value, tail);
+			CALL _pb_wire_read_i32_as_uint32(SUBSTRING(buf, offset + 1, 4), offset, uint_value);
 			IF _pb_wire_get_field_number_from_tag(tag) = field_number THEN
 				IF repeated_index IS NULL OR repeated_index = field_count THEN
 					SET value = uint_value;
@@ -624,7 +816,7 @@ BEGIN
 				SET field_count = field_count + 1;
 			END IF;
 		ELSE
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_get_i32_field_as_uint32: unsupported wire_type';
+			CALL _pb_signal_error('PB_UNSUPPORTED_WIRE_TYPE', '_pb_message_get_i32_field_as_uint32', 'unsupported wire_type');
 		END CASE;
 	END WHILE;
 
@@ -640,28 +832,38 @@ BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE buf_length BIGINT DEFAULT LENGTH(buf);
+	DECLARE value_length BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE packed_value LONGBLOB;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE message_text TEXT;
 
-	SET tail = buf;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET field_count = 0;
 
-	WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	WHILE offset < buf_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, tag);
 
 		IF _pb_wire_get_field_number_from_tag(tag) = field_number AND _pb_wire_get_wire_type_from_tag(tag) <> 1 /* I64 */ AND (repeated_index IS NULL OR _pb_wire_get_wire_type_from_tag(tag) <> 2 /* LEN */) THEN
-			SET message_text = CONCAT('_pb_message_get_i64_field_as_uint64: I64 value cannot be parsed from ', _pb_wire_type_name(_pb_wire_get_wire_type_from_tag(tag)), ' wire type.');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_i64_field_as_uint64', CONCAT('I64 value cannot be parsed from ', _pb_wire_type_name(_pb_wire_get_wire_type_from_tag(tag)), ' wire type.'));
 		END IF;
 
 		CASE _pb_wire_get_wire_type_from_tag(tag)
 		WHEN 0 THEN -- VARINT
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, uint_value);
 		WHEN 1 THEN -- I64
-			CALL _pb_wire_read_i64_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_i64_as_uint64(SUBSTRING(buf, offset + 1, 8), offset, uint_value);
 			IF _pb_wire_get_field_number_from_tag(tag) = field_number THEN
 				IF repeated_index IS NULL OR repeated_index = field_count THEN
 					SET value = uint_value;
@@ -669,20 +871,23 @@ BEGIN
 				SET field_count = field_count + 1;
 			END IF;
 		WHEN 2 THEN -- LEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(buf, offset + 1, 10), buf_length, offset, value_length);
 			IF _pb_wire_get_field_number_from_tag(tag) = field_number AND repeated_index IS NOT NULL THEN
-				WHILE LENGTH(bytes_value) <> 0 DO
-					CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+				SET bytes_value = SUBSTRING(buf, offset + 1, value_length);
+				SET packed_offset = 0;
+				WHILE packed_offset < LENGTH(bytes_value) DO
+					CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 					IF repeated_index = field_count THEN
 						SET value = uint_value;
 					END IF;
 					SET field_count = field_count + 1;
 				END WHILE;
 			END IF;
+			SET offset = offset + value_length;
 		WHEN 5 THEN -- I32
-			CALL _pb_wire_skip_i32(tail, tail);
+			CALL _pb_wire_skip(SUBSTRING(buf, offset + 1, 10), buf_length, 5, offset);
 		ELSE
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_get_i64_field_as_uint64: unsupported wire_type';
+			CALL _pb_signal_error('PB_UNSUPPORTED_WIRE_TYPE', '_pb_message_get_i64_field_as_uint64', 'unsupported wire_type');
 		END CASE;
 	END WHILE;
 
@@ -698,29 +903,39 @@ BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE buf_length BIGINT DEFAULT LENGTH(buf);
+	DECLARE value_length BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = buf;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET field_count = 0;
 
-	WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	WHILE offset < buf_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number = field_number AND current_wire_type <> 0 /* VARINT */ AND (repeated_index IS NULL OR current_wire_type <> 2 /* LEN */) THEN
-			SET message_text = CONCAT('_pb_message_get_varint_field_as_uint64: uint32 or uint64 value cannot be parsed from ', _pb_wire_type_name(current_wire_type), ' wire type.');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_varint_field_as_uint64', CONCAT('uint32 or uint64 value cannot be parsed from ', _pb_wire_type_name(current_wire_type), ' wire type.'));
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN -- VARINT
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, uint_value);
 			IF current_field_number = field_number THEN
 				IF repeated_index IS NULL OR repeated_index = field_count THEN
 					SET value = uint_value;
@@ -728,24 +943,27 @@ BEGIN
 				SET field_count = field_count + 1;
 			END IF;
 		WHEN 1 THEN -- I64
-			CALL _pb_wire_skip_i64(tail, tail);
+			CALL _pb_wire_skip(SUBSTRING(buf, offset + 1, 10), buf_length, 1, offset);
 		WHEN 2 THEN -- LEN
 			IF current_field_number = field_number AND repeated_index IS NOT NULL THEN
-				CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-				WHILE LENGTH(bytes_value) <> 0 DO
-					CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+				CALL _pb_wire_read_len_type_header(SUBSTRING(buf, offset + 1, 10), buf_length, offset, value_length);
+				SET bytes_value = SUBSTRING(buf, offset + 1, value_length);
+				SET offset = offset + value_length;
+				SET packed_offset = 0;
+				WHILE packed_offset < LENGTH(bytes_value) DO
+					CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 					IF repeated_index = field_count THEN
 						SET value = uint_value;
 					END IF;
 					SET field_count = field_count + 1;
 				END WHILE;
 			ELSE
-				CALL _pb_wire_skip_len_type(tail, tail);
+				CALL _pb_wire_skip(SUBSTRING(buf, offset + 1, 10), buf_length, 2, offset);
 			END IF;
 		WHEN 5 THEN -- I32
-			CALL _pb_wire_skip_i32(tail, tail);
+			CALL _pb_wire_skip(SUBSTRING(buf, offset + 1, 10), buf_length, 5, offset);
 		ELSE
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_get_varint_field_as_uint64: unsupported wire_type';
+			CALL _pb_signal_error('PB_UNSUPPORTED_WIRE_TYPE', '_pb_message_get_varint_field_as_uint64', 'unsupported wire_type');
 		END CASE;
 	END WHILE;
 
@@ -755,154 +973,695 @@ BEGIN
 	END IF;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_message_to_wire_json $$
-CREATE PROCEDURE _pb_message_to_wire_json(IN buf LONGBLOB, OUT wire_json JSON)
+-- =============================================================================
+-- Message Index
+-- =============================================================================
+--
+-- pb_message_index(message) precomputes the location of every field so that repeated reads of a large message do
+-- not have to decode it from the beginning each time. The index is a LONGBLOB laid out as follows (integers are
+-- big-endian):
+--
+--   version (1 byte, always 0x01)
+--   entry count (4 bytes)
+--   entries (13 bytes each, sorted by field number and then by position in the message)
+--     field number (4 bytes), wire type (1 byte), value offset (4 bytes), value length (4 bytes)
+--   original message bytes
+--
+-- The value offset is relative to the start of the message bytes and points at the value itself: the varint, the
+-- fixed-width bytes, or the payload of a LEN field (after its length prefix).
+
+DROP FUNCTION IF EXISTS _pb_message_index_get_entry_count $$
+CREATE FUNCTION _pb_message_index_get_entry_count(head VARBINARY(5), index_length BIGINT) RETURNS INT DETERMINISTIC
 BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+	DECLARE entry_count INT;
 
-	DECLARE tag INT;
-	DECLARE field_number INT;
-	DECLARE wire_type INT;
-	DECLARE tail LONGBLOB;
-	DECLARE uint_value BIGINT UNSIGNED;
-	DECLARE bytes_value LONGBLOB;
-	DECLARE message_text TEXT;
-	DECLARE i INT;
-	DECLARE json_path TEXT;
-	DECLARE wire_element JSON;
+	IF LENGTH(head) < 5 OR ASCII(head) <> 1 THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_index_get_entry_count: invalid message index';
+	END IF;
 
-	SET wire_json = JSON_OBJECT();
-	SET tail = buf;
-	SET i = 0;
+	SET entry_count = _pb_util_bin_as_uint32(SUBSTRING(head, 2, 4));
+	IF index_length < 5 + entry_count * 13 THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_index_get_entry_count: invalid message index';
+	END IF;
 
-	WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	RETURN entry_count;
+END $$
 
-		SET field_number = _pb_wire_get_field_number_from_tag(tag);
-		SET wire_type = _pb_wire_get_wire_type_from_tag(tag);
+DROP PROCEDURE IF EXISTS _pb_message_index_read_entry $$
+CREATE PROCEDURE _pb_message_index_read_entry(IN entry VARBINARY(13), OUT field_number INT, OUT wire_type INT, OUT value_offset BIGINT, OUT value_length BIGINT)
+BEGIN
+	SET field_number = _pb_util_bin_as_uint32(SUBSTRING(entry, 1, 4));
+	SET wire_type = ASCII(SUBSTRING(entry, 5, 1));
+	SET value_offset = _pb_util_bin_as_uint32(SUBSTRING(entry, 6, 4));
+	SET value_length = _pb_util_bin_as_uint32(SUBSTRING(entry, 10, 4));
+END $$
 
-		CASE wire_type
-		WHEN 0 THEN -- VARINT
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
-			SET wire_element = JSON_OBJECT('i', i, 'n', field_number, 't', wire_type, 'v', uint_value);
-		WHEN 1 THEN -- I64
-			CALL _pb_wire_read_i64_as_uint64(tail, uint_value, tail);
-			SET wire_element = JSON_OBJECT('i', i, 'n', field_number, 't', wire_type, 'v', uint_value);
-		WHEN 2 THEN -- LEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			SET wire_element = JSON_OBJECT('i', i, 'n', field_number, 't', wire_type, 'v', TO_BASE64(bytes_value));
-		WHEN 5 THEN -- I32
-			CALL _pb_wire_read_i32_as_uint32(tail, uint_value, tail);
-			SET wire_element = JSON_OBJECT('i', i, 'n', field_number, 't', wire_type, 'v', uint_value);
-		ELSE
-			SET message_text = CONCAT('_pb_message_to_wire_json: unsupported wire type (', wire_type, ')');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
-		END CASE;
+-- Finds the first entry of field_number by binary search. The caller passes only the entries of the index, i.e.
+-- SUBSTRING(message_index, 6, entry_count * 13), so that the message bytes are not copied. entry_index is the number of
+-- entries if there is no such entry.
+DROP PROCEDURE IF EXISTS _pb_message_index_find_field $$
+CREATE PROCEDURE _pb_message_index_find_field(IN entries LONGBLOB, IN field_number INT, OUT entry_index INT)
+BEGIN
+	DECLARE high INT DEFAULT LENGTH(entries) DIV 13;
+	DECLARE middle INT;
 
-		SET json_path = CONCAT('$."', field_number, '"');
-		IF JSON_CONTAINS_PATH(wire_json, 'one', json_path) THEN
-			SET wire_json = JSON_ARRAY_APPEND(wire_json, json_path, wire_element);
+	SET entry_index = 0;
+
+	WHILE entry_index < high DO
+		SET middle = (entry_index + high) DIV 2;
+		IF _pb_util_bin_as_uint32(SUBSTRING(entries, middle * 13 + 1, 4)) < field_number THEN
+			SET entry_index = middle + 1;
 		ELSE
-			SET wire_json = JSON_SET(wire_json, json_path, JSON_ARRAY(wire_element));
+			SET high = middle;
 		END IF;
-
-		SET i = i + 1;
 	END WHILE;
 END $$
 
-DROP FUNCTION IF EXISTS pb_message_new $$
-CREATE FUNCTION pb_message_new() RETURNS LONGBLOB DETERMINISTIC
+-- Sorts index entries by field number, keeping entries of the same field in message order. Stored programs have no
+-- arrays or sort primitive, so the entries are put in a JSON array along with their field numbers and sorted by ORDER BY
+-- over JSON_TABLE, reading them back in order through a cursor. The ordinality column makes the sort stable. Entries are
+-- hex-encoded because JSON cannot hold binary strings. pb_message_index only calls this for messages whose fields are
+-- not already in field number order.
+DROP FUNCTION IF EXISTS _pb_message_index_sort_entries $$
+CREATE FUNCTION _pb_message_index_sort_entries(entries LONGBLOB, entry_count INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	-- Return an empty protobuf message
-	RETURN _binary X'';
-END $$
+	DECLARE done BOOLEAN DEFAULT FALSE;
+	DECLARE keyed_entries JSON DEFAULT JSON_ARRAY();
+	DECLARE entry_index INT DEFAULT 0;
+	DECLARE entry_hex TEXT;
+	DECLARE result LONGBLOB DEFAULT _binary '';
 
-DROP FUNCTION IF EXISTS pb_message_to_wire_json $$
-CREATE FUNCTION pb_message_to_wire_json(buf LONGBLOB) RETURNS JSON DETERMINISTIC
-BEGIN
-	DECLARE wire_json JSON;
-	CALL _pb_message_to_wire_json(buf, wire_json);
-	RETURN wire_json;
+	DECLARE entry_cursor CURSOR FOR
+		SELECT e
+		FROM JSON_TABLE(
+			keyed_entries,
+			'$[*]' COLUMNS (
+				o FOR ORDINALITY,
+				n BIGINT PATH '$.n',
+				e TEXT PATH '$.e'
+			)
+		) jt
+		ORDER BY n, o;
+
+	DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = TRUE;
+
+	WHILE entry_index < entry_count DO
+		SET keyed_entries = JSON_ARRAY_APPEND(keyed_entries, '$', JSON_OBJECT(
+			'n', _pb_util_bin_as_uint32(SUBSTRING(entries, entry_index * 13 + 1, 4)),
+			'e', HEX(SUBSTRING(entries, entry_index * 13 + 1, 13))
+		));
+		SET entry_index = entry_index + 1;
+	END WHILE;
+
+	OPEN entry_cursor;
+	l1: LOOP
+		FETCH entry_cursor INTO entry_hex;
+		IF done THEN
+			LEAVE l1;
+		END IF;
+		SET result = CONCAT(result, UNHEX(entry_hex));
+	END LOOP;
+	CLOSE entry_cursor;
+
+	RETURN result;
 END $$
 
-DROP PROCEDURE IF EXISTS pb_wire_json_as_table $$
-CREATE PROCEDURE pb_wire_json_as_table(IN wire_json JSON)
+DROP FUNCTION IF EXISTS pb_message_index $$
+CREATE FUNCTION pb_message_index(message LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	SELECT * FROM JSON_TABLE(JSON_EXTRACT(wire_json, '$.*[*]'), '$[*]' COLUMNS (i INT PATH '$.i', n INT PATH '$.n', t INT PATH '$.t', v JSON PATH '$.v')) jt;
+	DECLARE tag BIGINT;
+	DECLARE field_number INT;
+	DECLARE wire_type INT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE entries LONGBLOB DEFAULT _binary '';
+	DECLARE entry_count INT DEFAULT 0;
+	DECLARE last_field_number INT DEFAULT 0;
+	DECLARE is_sorted BOOLEAN DEFAULT TRUE;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
+		SET field_number = _pb_wire_get_field_number_from_tag(tag);
+		SET wire_type = _pb_wire_get_wire_type_from_tag(tag);
+
+		IF wire_type = 2 THEN -- LEN
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET value_offset = offset;
+			SET offset = offset + value_length;
+		ELSE
+			SET value_offset = offset;
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, wire_type, offset);
+			SET value_length = offset - value_offset;
+		END IF;
+
+		SET entries = CONCAT(entries, _pb_util_uint32_to_bin(field_number), CHAR(wire_type USING binary), _pb_util_uint32_to_bin(value_offset), _pb_util_uint32_to_bin(value_length));
+		SET entry_count = entry_count + 1;
+
+		IF field_number < last_field_number THEN
+			SET is_sorted = FALSE;
+		END IF;
+		SET last_field_number = field_number;
+	END WHILE;
+
+	-- Fields are usually serialized in field number order, in which case no sorting is needed
+	IF NOT is_sorted THEN
+		SET entries = _pb_message_index_sort_entries(entries, entry_count);
+	END IF;
+
+	RETURN CONCAT(_binary X'01', _pb_util_uint32_to_bin(entry_count), entries, message);
 END $$
 
-DROP PROCEDURE IF EXISTS pb_message_show_wire_format $$
-CREATE PROCEDURE pb_message_show_wire_format(IN buf LONGBLOB)
+DROP FUNCTION IF EXISTS pb_message_index_to_message $$
+CREATE FUNCTION pb_message_index_to_message(message_index LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	CALL pb_wire_json_as_table(pb_message_to_wire_json(buf));
+	DECLARE entry_count INT DEFAULT _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	RETURN SUBSTRING(message_index, 5 + entry_count * 13 + 1);
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_wire_json_get_varint_field_as_uint64 $$
-CREATE PROCEDURE _pb_wire_json_get_varint_field_as_uint64(IN wire_json JSON, IN field_number INT, IN repeated_index INT, OUT value BIGINT UNSIGNED, OUT field_count INT)
+DROP PROCEDURE IF EXISTS _pb_message_index_get_len_type_field $$
+CREATE PROCEDURE _pb_message_index_get_len_type_field(IN message_index LONGBLOB, IN field_number INT, IN repeated_index INT, OUT value LONGBLOB, OUT field_count INT)
 BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
 	DECLARE message_text TEXT;
-	DECLARE uint_value BIGINT UNSIGNED;
-	DECLARE bytes_value LONGBLOB;
-	DECLARE wire_type INT;
-	DECLARE wire_elements JSON;
-	DECLARE wire_element JSON;
-	DECLARE wire_element_index INT;
-	DECLARE wire_element_count INT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
 
 	SET field_count = 0;
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
-	SET wire_element_index = 0;
-	SET wire_element_count = JSON_LENGTH(wire_elements);
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
 
-	l1: WHILE wire_element_index < wire_element_count DO
-		SET wire_element = JSON_EXTRACT(wire_elements, CONCAT('$[', wire_element_index, ']'));
-		SET wire_type = JSON_EXTRACT(wire_element, '$.t');
+		IF current_wire_type <> 2 /* LEN */ THEN
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_len_type_field', CONCAT('string or bytes value cannot be parsed from ', _pb_wire_type_name(current_wire_type), ' wire type.'));
+		END IF;
 
-		CASE wire_type
-		WHEN 0 THEN -- VARINT
-			SET uint_value = CAST(JSON_EXTRACT(wire_element, '$.v') AS UNSIGNED);
-			IF repeated_index IS NULL OR repeated_index = field_count THEN
-				SET value = uint_value;
-			END IF;
-			SET field_count = field_count + 1;
-		WHEN 2 THEN -- LEN
-			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			IF repeated_index IS NULL THEN
-				SET message_text = CONCAT('_pb_wire_json_get_varint_field_as_uint64: unexpected wire_type (', wire_type, ')');
-				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
-			END IF;
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+		IF repeated_index IS NULL OR repeated_index = field_count THEN
+			SET value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+		END IF;
+		SET field_count = field_count + 1;
+		SET entry_index = entry_index + 1;
+	END WHILE;
+
+	-- Negative repeated_index is used when just counting the number of repeated elements.
+	IF repeated_index IS NOT NULL AND repeated_index >= 0 AND field_count <= repeated_index THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_index_get_len_type_field: repeated index out of range';
+	END IF;
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_message_index_get_i32_field_as_uint32 $$
+CREATE PROCEDURE _pb_message_index_get_i32_field_as_uint32(IN message_index LONGBLOB, IN field_number INT, IN repeated_index INT, OUT value INT UNSIGNED, OUT field_count INT)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET field_count = 0;
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+
+		IF current_wire_type <> 5 /* I32 */ AND (repeated_index IS NULL OR current_wire_type <> 2 /* LEN */) THEN
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_i32_field_as_uint32', CONCAT('I32 value cannot be parsed from ', _pb_wire_type_name(current_wire_type), ' wire type.'));
+		END IF;
+
+		IF current_wire_type = 5 THEN
+			IF repeated_index IS NULL OR repeated_index = field_count THEN
+				SET value = _pb_util_swap_endian_32(_pb_util_bin_as_uint32(SUBSTRING(message_index, message_start + value_offset + 1, 4)));
+			END IF;
+			SET field_count = field_count + 1;
+		ELSE
+			-- Packed elements have a fixed size, so the requested element is read without decoding the others
+			IF value_length % 4 <> 0 THEN
+				CALL _pb_signal_error('PB_LENGTH_OUT_OF_BOUNDS', '_pb_message_index_get_i32_field_as_uint32', 'Unexpected end of BLOB.');
+			END IF;
+			IF repeated_index >= field_count AND repeated_index < field_count + value_length DIV 4 THEN
+				SET value = _pb_util_swap_endian_32(_pb_util_bin_as_uint32(SUBSTRING(message_index, message_start + value_offset + (repeated_index - field_count) * 4 + 1, 4)));
+			END IF;
+			SET field_count = field_count + value_length DIV 4;
+		END IF;
+		SET entry_index = entry_index + 1;
+	END WHILE;
+
+	-- Negative repeated_index is used when just counting the number of repeated elements.
+	IF repeated_index IS NOT NULL AND repeated_index >= 0 AND field_count <= repeated_index THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_index_get_i32_field_as_uint32: repeated index out of range';
+	END IF;
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_message_index_get_i64_field_as_uint64 $$
+CREATE PROCEDURE _pb_message_index_get_i64_field_as_uint64(IN message_index LONGBLOB, IN field_number INT, IN repeated_index INT, OUT value BIGINT UNSIGNED, OUT field_count INT)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET field_count = 0;
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+
+		IF current_wire_type <> 1 /* I64 */ AND (repeated_index IS NULL OR current_wire_type <> 2 /* LEN */) THEN
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_i64_field_as_uint64', CONCAT('I64 value cannot be parsed from ', _pb_wire_type_name(current_wire_type), ' wire type.'));
+		END IF;
+
+		IF current_wire_type = 1 THEN
+			IF repeated_index IS NULL OR repeated_index = field_count THEN
+				SET value = _pb_util_swap_endian_64(_pb_util_bin_as_uint64(SUBSTRING(message_index, message_start + value_offset + 1, 8)));
+			END IF;
+			SET field_count = field_count + 1;
+		ELSE
+			-- Packed elements have a fixed size, so the requested element is read without decoding the others
+			IF value_length % 8 <> 0 THEN
+				CALL _pb_signal_error('PB_LENGTH_OUT_OF_BOUNDS', '_pb_message_index_get_i64_field_as_uint64', 'Unexpected end of BLOB.');
+			END IF;
+			IF repeated_index >= field_count AND repeated_index < field_count + value_length DIV 8 THEN
+				SET value = _pb_util_swap_endian_64(_pb_util_bin_as_uint64(SUBSTRING(message_index, message_start + value_offset + (repeated_index - field_count) * 8 + 1, 8)));
+			END IF;
+			SET field_count = field_count + value_length DIV 8;
+		END IF;
+		SET entry_index = entry_index + 1;
+	END WHILE;
+
+	-- Negative repeated_index is used when just counting the number of repeated elements.
+	IF repeated_index IS NOT NULL AND repeated_index >= 0 AND field_count <= repeated_index THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_index_get_i64_field_as_uint64: repeated index out of range';
+	END IF;
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_message_index_get_varint_field_as_uint64 $$
+CREATE PROCEDURE _pb_message_index_get_varint_field_as_uint64(IN message_index LONGBLOB, IN field_number INT, IN repeated_index INT, OUT value BIGINT UNSIGNED, OUT field_count INT)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET field_count = 0;
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+
+		IF current_wire_type <> 0 /* VARINT */ AND (repeated_index IS NULL OR current_wire_type <> 2 /* LEN */) THEN
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_varint_field_as_uint64', CONCAT('uint32 or uint64 value cannot be parsed from ', _pb_wire_type_name(current_wire_type), ' wire type.'));
+		END IF;
+
+		IF current_wire_type = 0 THEN
+			IF repeated_index IS NULL OR repeated_index = field_count THEN
+				SET value = _pb_wire_read_varint_as_uint64(SUBSTRING(message_index, message_start + value_offset + 1, value_length));
+			END IF;
+			SET field_count = field_count + 1;
+		ELSE
+			SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				IF repeated_index = field_count THEN
 					SET value = uint_value;
 				END IF;
 				SET field_count = field_count + 1;
 			END WHILE;
+		END IF;
+		SET entry_index = entry_index + 1;
+	END WHILE;
+
+	-- Negative repeated_index is used when just counting the number of repeated elements.
+	IF repeated_index IS NOT NULL AND repeated_index >= 0 AND field_count <= repeated_index THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_message_index_get_varint_field_as_uint64: repeated index out of range';
+	END IF;
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_message_to_wire_json $$
+CREATE PROCEDURE _pb_message_to_wire_json(IN buf LONGBLOB, OUT wire_json JSON)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE tag INT;
+	DECLARE field_number INT;
+	DECLARE wire_type INT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE buf_length BIGINT DEFAULT LENGTH(buf);
+	DECLARE value_length BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+	DECLARE i INT;
+	DECLARE json_path TEXT;
+	DECLARE wire_element JSON;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET wire_json = JSON_OBJECT();
+	SET i = 0;
+
+	WHILE offset < buf_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, tag);
+
+		SET field_number = _pb_wire_get_field_number_from_tag(tag);
+		SET wire_type = _pb_wire_get_wire_type_from_tag(tag);
+
+		CASE wire_type
+		WHEN 0 THEN -- VARINT
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(buf, offset + 1, 10), offset, uint_value);
+			SET wire_element = JSON_OBJECT('i', i, 'n', field_number, 't', wire_type, 'v', uint_value);
+		WHEN 1 THEN -- I64
+			CALL _pb_wire_read_i64_as_uint64(SUBSTRING(buf, offset + 1, 8), offset, uint_value);
+			SET wire_element = JSON_OBJECT('i', i, 'n', field_number, 't', wire_type, 'v', uint_value);
+		WHEN 2 THEN -- LEN
+			CALL _pb_wire_read_len_type_header(SUBSTRING(buf, offset + 1, 10), buf_length, offset, value_length);
+			SET bytes_value = SUBSTRING(buf, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET wire_element = JSON_OBJECT('i', i, 'n', field_number, 't', wire_type, 'v', TO_BASE64(bytes_value));
+		WHEN 5 THEN -- I32
+			CALL _pb_wire_read_i32_as_uint32(SUBSTRING(buf, offset + 1, 4), offset, uint_value);
+			SET wire_element = JSON_OBJECT('i', i, 'n', field_number, 't', wire_type, 'v', uint_value);
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_varint_field_as_uint64: unexpected wire_type (', wire_type, ')');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_UNSUPPORTED_WIRE_TYPE', '_pb_message_to_wire_json', CONCAT('unsupported wire type (', wire_type, ')'));
 		END CASE;
 
-		SET wire_element_index = wire_element_index + 1;
+		SET json_path = CONCAT('$."', field_number, '"');
+		IF JSON_CONTAINS_PATH(wire_json, 'one', json_path) THEN
+			SET wire_json = JSON_ARRAY_APPEND(wire_json, json_path, wire_element);
+		ELSE
+			SET wire_json = JSON_SET(wire_json, json_path, JSON_ARRAY(wire_element));
+		END IF;
+
+		SET i = i + 1;
 	END WHILE;
+END $$
 
-	-- Negative repeated_index is used when just counting the number of repeated elements.
-	IF repeated_index IS NOT NULL AND repeated_index >= 0 AND field_count <= repeated_index THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_json_get_varint_field_as_uint64: repeated index out of range';
+DROP FUNCTION IF EXISTS pb_message_new $$
+CREATE FUNCTION pb_message_new() RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	-- Return an empty protobuf message
+	RETURN _binary X'';
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_to_wire_json $$
+CREATE FUNCTION pb_message_to_wire_json(buf LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE wire_json JSON;
+	CALL _pb_message_to_wire_json(buf, wire_json);
+	RETURN wire_json;
+END $$
+
+-- Wire JSON v2
+--
+-- Version 1 of wire_json is an object {"<field_number>": [{"i": index, "n": field_number, "t": wire_type, "v": value}, ...]}.
+-- Version 2 is a compact layout [2, {"<field_number>": [element, ...]}] in which each element is a positional array,
+-- [index, value] for VARINT and [index, value, wire_type] for the other wire types. Values are encoded as in version 1.
+-- All pb_wire_json_* functions accept both versions and return the version they were given.
+
+-- Private: Check if wire_json uses the version 2 layout
+DROP FUNCTION IF EXISTS _pb_wire_json_is_v2 $$
+CREATE FUNCTION _pb_wire_json_is_v2(wire_json JSON) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	RETURN COALESCE(JSON_TYPE(wire_json) = 'ARRAY', FALSE);
+END $$
+
+-- Private: Return the JSON path to the element array of a field
+DROP FUNCTION IF EXISTS _pb_wire_json_get_field_path $$
+CREATE FUNCTION _pb_wire_json_get_field_path(wire_json JSON, field_number INT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	RETURN CONCAT(IF(_pb_wire_json_is_v2(wire_json), '$[1]."', '$."'), field_number, '"');
+END $$
+
+-- Private: Create an element in the layout of wire_json
+DROP FUNCTION IF EXISTS _pb_wire_json_new_element $$
+CREATE FUNCTION _pb_wire_json_new_element(wire_json JSON, element_index INT, field_number INT, wire_type INT, value JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	IF NOT _pb_wire_json_is_v2(wire_json) THEN
+		RETURN JSON_OBJECT('i', element_index, 'n', field_number, 't', wire_type, 'v', value);
+	ELSEIF wire_type = 0 THEN
+		RETURN JSON_ARRAY(element_index, value);
+	ELSE
+		RETURN JSON_ARRAY(element_index, value, wire_type);
 	END IF;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_wire_json_get_i64_field_as_uint64 $$
-CREATE PROCEDURE _pb_wire_json_get_i64_field_as_uint64(IN wire_json JSON, IN field_number INT, IN repeated_index INT, OUT value BIGINT UNSIGNED, OUT field_count INT)
+-- Private: Convert a version 2 element to a version 1 element
+DROP FUNCTION IF EXISTS _pb_wire_json_v2_element_to_v1 $$
+CREATE FUNCTION _pb_wire_json_v2_element_to_v1(element JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN JSON_OBJECT(
+		'i', JSON_EXTRACT(element, '$[0]'),
+		'n', field_number,
+		't', IF(JSON_LENGTH(element) > 2, CAST(JSON_EXTRACT(element, '$[2]') AS UNSIGNED), 0),
+		'v', JSON_EXTRACT(element, '$[1]'));
+END $$
+
+-- Private: Return the elements of a field as version 1 elements, or NULL if the field is not present
+DROP FUNCTION IF EXISTS _pb_wire_json_get_field_elements $$
+CREATE FUNCTION _pb_wire_json_get_field_elements(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE elements JSON;
+	DECLARE result JSON;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE element_count INT;
+
+	IF NOT _pb_wire_json_is_v2(wire_json) THEN
+		RETURN JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	END IF;
+
+	SET elements = JSON_EXTRACT(wire_json, CONCAT('$[1]."', field_number, '"'));
+	IF elements IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET result = JSON_ARRAY();
+	SET element_count = JSON_LENGTH(elements);
+	WHILE element_index < element_count DO
+		SET result = JSON_ARRAY_APPEND(result, '$', _pb_wire_json_v2_element_to_v1(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')), field_number));
+		SET element_index = element_index + 1;
+	END WHILE;
+	RETURN result;
+END $$
+
+-- Converts wire_json to the version 1 layout. Version 1 input is returned as is.
+DROP FUNCTION IF EXISTS pb_wire_json_to_v1 $$
+CREATE FUNCTION pb_wire_json_to_v1(wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_numbers JSON;
+	DECLARE field_number_index INT DEFAULT 0;
+	DECLARE field_number INT;
+	DECLARE result JSON;
+
+	IF NOT _pb_wire_json_is_v2(wire_json) THEN
+		RETURN wire_json;
+	END IF;
+
+	IF NOT (JSON_EXTRACT(wire_json, '$[0]') <=> CAST(2 AS JSON)) THEN
+		SET message_text = CONCAT('pb_wire_json_to_v1: unsupported wire_json version `', COALESCE(JSON_EXTRACT(wire_json, '$[0]'), 'NULL'), '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	SET result = JSON_OBJECT();
+	SET field_numbers = COALESCE(JSON_KEYS(JSON_EXTRACT(wire_json, '$[1]')), JSON_ARRAY());
+	WHILE field_number_index < JSON_LENGTH(field_numbers) DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		SET result = JSON_SET(result, CONCAT('$."', field_number, '"'), _pb_wire_json_get_field_elements(wire_json, field_number));
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+	RETURN result;
+END $$
+
+-- Converts wire_json to the compact version 2 layout. Version 2 input is returned as is.
+DROP FUNCTION IF EXISTS pb_wire_json_to_v2 $$
+CREATE FUNCTION pb_wire_json_to_v2(wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE field_numbers JSON;
+	DECLARE field_number_index INT DEFAULT 0;
+	DECLARE field_path TEXT;
+	DECLARE elements JSON;
+	DECLARE element JSON;
+	DECLARE element_index INT;
+	DECLARE element_count INT;
+	DECLARE fields JSON;
+
+	IF wire_json IS NULL OR _pb_wire_json_is_v2(wire_json) THEN
+		RETURN wire_json;
+	END IF;
+
+	SET fields = JSON_OBJECT();
+	SET field_numbers = JSON_KEYS(wire_json);
+	WHILE field_number_index < JSON_LENGTH(field_numbers) DO
+		SET field_path = CONCAT('$.', JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		SET elements = JSON_EXTRACT(wire_json, field_path);
+		SET element_count = JSON_LENGTH(elements);
+		SET element_index = 0;
+		WHILE element_index < element_count DO
+			SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+			IF JSON_EXTRACT(element, '$.t') = 0 THEN
+				SET element = JSON_ARRAY(JSON_EXTRACT(element, '$.i'), JSON_EXTRACT(element, '$.v'));
+			ELSE
+				SET element = JSON_ARRAY(JSON_EXTRACT(element, '$.i'), JSON_EXTRACT(element, '$.v'), JSON_EXTRACT(element, '$.t'));
+			END IF;
+			SET elements = JSON_SET(elements, CONCAT('$[', element_index, ']'), element);
+			SET element_index = element_index + 1;
+		END WHILE;
+		SET fields = JSON_SET(fields, field_path, elements);
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+	RETURN JSON_ARRAY(2, fields);
+END $$
+
+-- Private: Convert a version 1 result back to the version of the original wire_json
+DROP FUNCTION IF EXISTS _pb_wire_json_to_same_version $$
+CREATE FUNCTION _pb_wire_json_to_same_version(result JSON, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	IF _pb_wire_json_is_v2(wire_json) THEN
+		RETURN pb_wire_json_to_v2(result);
+	END IF;
+	RETURN result;
+END $$
+
+-- Returns the layout version of wire_json, 1 or 2
+DROP FUNCTION IF EXISTS pb_wire_json_get_version $$
+CREATE FUNCTION pb_wire_json_get_version(wire_json JSON) RETURNS INT DETERMINISTIC
+BEGIN
+	IF wire_json IS NULL THEN
+		RETURN NULL;
+	ELSEIF _pb_wire_json_is_v2(wire_json) THEN
+		RETURN CAST(JSON_EXTRACT(wire_json, '$[0]') AS UNSIGNED);
+	END IF;
+	RETURN 1;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_to_wire_json_v2 $$
+CREATE FUNCTION pb_message_to_wire_json_v2(buf LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN pb_wire_json_to_v2(pb_message_to_wire_json(buf));
+END $$
+
+DROP PROCEDURE IF EXISTS pb_wire_json_as_table $$
+CREATE PROCEDURE pb_wire_json_as_table(IN wire_json JSON)
+BEGIN
+	SET wire_json = pb_wire_json_to_v1(wire_json);
+	SELECT * FROM JSON_TABLE(JSON_EXTRACT(wire_json, '$.*[*]'), '$[*]' COLUMNS (i INT PATH '$.i', n INT PATH '$.n', t INT PATH '$.t', v JSON PATH '$.v')) jt;
+END $$
+
+DROP PROCEDURE IF EXISTS pb_message_show_wire_format $$
+CREATE PROCEDURE pb_message_show_wire_format(IN buf LONGBLOB)
+BEGIN
+	CALL pb_wire_json_as_table(pb_message_to_wire_json(buf));
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_varint_field_as_uint64 $$
+CREATE PROCEDURE _pb_wire_json_get_varint_field_as_uint64(IN wire_json JSON, IN field_number INT, IN repeated_index INT, OUT value BIGINT UNSIGNED, OUT field_count INT)
 BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -911,7 +1670,7 @@ BEGIN
 
 	SET field_count = 0;
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -920,7 +1679,7 @@ BEGIN
 		SET wire_type = JSON_EXTRACT(wire_element, '$.t');
 
 		CASE wire_type
-		WHEN 1 THEN -- I64
+		WHEN 0 THEN -- VARINT
 			SET uint_value = CAST(JSON_EXTRACT(wire_element, '$.v') AS UNSIGNED);
 			IF repeated_index IS NULL OR repeated_index = field_count THEN
 				SET value = uint_value;
@@ -929,19 +1688,18 @@ BEGIN
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
 			IF repeated_index IS NULL THEN
-				SET message_text = CONCAT('_pb_wire_json_get_i64_field_as_uint64: unexpected wire_type (', wire_type, ')');
-				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+				CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_varint_field_as_uint64', CONCAT('unexpected wire_type (', wire_type, ')'));
 			END IF;
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				IF repeated_index = field_count THEN
 					SET value = uint_value;
 				END IF;
 				SET field_count = field_count + 1;
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_i64_field_as_uint64: unexpected wire_type (', wire_type, ')');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_varint_field_as_uint64', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -949,18 +1707,19 @@ BEGIN
 
 	-- Negative repeated_index is used when just counting the number of repeated elements.
 	IF repeated_index IS NOT NULL AND repeated_index >= 0 AND field_count <= repeated_index THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_json_get_i64_field_as_uint64: repeated index out of range';
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_json_get_varint_field_as_uint64: repeated index out of range';
 	END IF;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_wire_json_get_i32_field_as_uint32 $$
-CREATE PROCEDURE _pb_wire_json_get_i32_field_as_uint32(IN wire_json JSON, IN field_number INT, IN repeated_index INT, OUT value INT UNSIGNED, OUT field_count INT)
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_i64_field_as_uint64 $$
+CREATE PROCEDURE _pb_wire_json_get_i64_field_as_uint64(IN wire_json JSON, IN field_number INT, IN repeated_index INT, OUT value BIGINT UNSIGNED, OUT field_count INT)
 BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -969,7 +1728,7 @@ BEGIN
 
 	SET field_count = 0;
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -978,7 +1737,7 @@ BEGIN
 		SET wire_type = JSON_EXTRACT(wire_element, '$.t');
 
 		CASE wire_type
-		WHEN 5 THEN -- I32
+		WHEN 1 THEN -- I64
 			SET uint_value = CAST(JSON_EXTRACT(wire_element, '$.v') AS UNSIGNED);
 			IF repeated_index IS NULL OR repeated_index = field_count THEN
 				SET value = uint_value;
@@ -987,19 +1746,18 @@ BEGIN
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
 			IF repeated_index IS NULL THEN
-				SET message_text = CONCAT('_pb_wire_json_get_i32_field_as_uint32: unexpected wire_type (', wire_type, ')');
-				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+				CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_i64_field_as_uint64', CONCAT('unexpected wire_type (', wire_type, ')'));
 			END IF;
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i32_as_uint32(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				IF repeated_index = field_count THEN
 					SET value = uint_value;
 				END IF;
 				SET field_count = field_count + 1;
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_i32_field_as_uint32: unexpected wire_type (', wire_type, ')');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_i64_field_as_uint64', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -1007,18 +1765,19 @@ BEGIN
 
 	-- Negative repeated_index is used when just counting the number of repeated elements.
 	IF repeated_index IS NOT NULL AND repeated_index >= 0 AND field_count <= repeated_index THEN
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_json_get_i32_field_as_uint32: repeated index out of range';
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_json_get_i64_field_as_uint64: repeated index out of range';
 	END IF;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_wire_json_get_len_type_field $$
-CREATE PROCEDURE _pb_wire_json_get_len_type_field(IN wire_json JSON, IN field_number INT, IN repeated_index INT, OUT value LONGBLOB, OUT field_count INT)
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_i32_field_as_uint32 $$
+CREATE PROCEDURE _pb_wire_json_get_i32_field_as_uint32(IN wire_json JSON, IN field_number INT, IN repeated_index INT, OUT value INT UNSIGNED, OUT field_count INT)
 BEGIN
 	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
 
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -1027,7 +1786,7 @@ BEGIN
 
 	SET field_count = 0;
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -1036,15 +1795,71 @@ BEGIN
 		SET wire_type = JSON_EXTRACT(wire_element, '$.t');
 
 		CASE wire_type
-		WHEN 2 THEN -- LEN
+		WHEN 5 THEN -- I32
+			SET uint_value = CAST(JSON_EXTRACT(wire_element, '$.v') AS UNSIGNED);
+			IF repeated_index IS NULL OR repeated_index = field_count THEN
+				SET value = uint_value;
+			END IF;
+			SET field_count = field_count + 1;
+		WHEN 2 THEN -- LEN
+			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
+			IF repeated_index IS NULL THEN
+				CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_i32_field_as_uint32', CONCAT('unexpected wire_type (', wire_type, ')'));
+			END IF;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
+				IF repeated_index = field_count THEN
+					SET value = uint_value;
+				END IF;
+				SET field_count = field_count + 1;
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_i32_field_as_uint32', CONCAT('unexpected wire_type (', wire_type, ')'));
+		END CASE;
+
+		SET wire_element_index = wire_element_index + 1;
+	END WHILE;
+
+	-- Negative repeated_index is used when just counting the number of repeated elements.
+	IF repeated_index IS NOT NULL AND repeated_index >= 0 AND field_count <= repeated_index THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = '_pb_wire_json_get_i32_field_as_uint32: repeated index out of range';
+	END IF;
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_len_type_field $$
+CREATE PROCEDURE _pb_wire_json_get_len_type_field(IN wire_json JSON, IN field_number INT, IN repeated_index INT, OUT value LONGBLOB, OUT field_count INT)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE wire_type INT;
+	DECLARE wire_elements JSON;
+	DECLARE wire_element JSON;
+	DECLARE wire_element_index INT;
+	DECLARE wire_element_count INT;
+
+	SET field_count = 0;
+
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
+	SET wire_element_index = 0;
+	SET wire_element_count = JSON_LENGTH(wire_elements);
+
+	l1: WHILE wire_element_index < wire_element_count DO
+		SET wire_element = JSON_EXTRACT(wire_elements, CONCAT('$[', wire_element_index, ']'));
+		SET wire_type = JSON_EXTRACT(wire_element, '$.t');
+
+		CASE wire_type
+		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
 			IF repeated_index IS NULL OR repeated_index = field_count THEN
 				SET value = bytes_value;
 			END IF;
 			SET field_count = field_count + 1;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_len_type_field: unexpected wire_type (', wire_type, ')');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_len_type_field', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -1203,8 +2018,7 @@ BEGIN
 			CALL _pb_wire_write_i32(uint_value, value_encoded);
 			SET message = CONCAT(message, value_encoded);
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_to_message: unsupported wire type (', wire_type, ')');
-			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_UNSUPPORTED_WIRE_TYPE', '_pb_wire_json_to_message', CONCAT('unsupported wire type (', wire_type, ')'));
 		END CASE;
 	END LOOP;
 
@@ -1223,7 +2037,7 @@ DROP FUNCTION IF EXISTS pb_wire_json_to_message $$
 CREATE FUNCTION pb_wire_json_to_message(wire_json JSON) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
 	DECLARE result LONGBLOB;
-	CALL _pb_wire_json_to_message(wire_json, result);
+	CALL _pb_wire_json_to_message(pb_wire_json_to_v1(wire_json), result);
 	RETURN result;
 END $$
 
@@ -1238,13 +2052,23 @@ BEGIN
 	DECLARE max_index INT;
 
 	-- Use the same JSON_TABLE pattern as pb_wire_json_as_table to get max index
-	SELECT COALESCE(MAX(i), -1) INTO max_index
-	FROM JSON_TABLE(
-		JSON_EXTRACT(wire_json, '$.*[*]'),
-		'$[*]' COLUMNS (
-			i INT PATH '$.i'
-		)
-	) jt;
+	IF _pb_wire_json_is_v2(wire_json) THEN
+		SELECT COALESCE(MAX(i), -1) INTO max_index
+		FROM JSON_TABLE(
+			JSON_EXTRACT(wire_json, '$[1].*[*]'),
+			'$[*]' COLUMNS (
+				i INT PATH '$[0]'
+			)
+		) jt;
+	ELSE
+		SELECT COALESCE(MAX(i), -1) INTO max_index
+		FROM JSON_TABLE(
+			JSON_EXTRACT(wire_json, '$.*[*]'),
+			'$[*]' COLUMNS (
+				i INT PATH '$.i'
+			)
+		) jt;
+	END IF;
 
 	RETURN max_index + 1;
 END $$
@@ -1258,7 +2082,7 @@ CREATE FUNCTION _pb_wire_json_set_field(
 	value JSON
 ) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE field_path TEXT DEFAULT _pb_wire_json_get_field_path(wire_json, field_number);
 	DECLARE next_index INT;
 	DECLARE new_element JSON;
 
@@ -1266,7 +2090,7 @@ BEGIN
 	SET next_index = _pb_wire_json_get_next_index(wire_json);
 
 	-- Create the new wire element
-	SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', wire_type, 'v', value);
+	SET new_element = _pb_wire_json_new_element(wire_json, next_index, field_number, wire_type, value);
 
 	-- Replace the entire field
 	RETURN JSON_SET(wire_json, field_path, JSON_ARRAY(new_element));
@@ -1281,7 +2105,7 @@ CREATE FUNCTION _pb_wire_json_add_repeated_field_element(
 	value JSON
 ) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE field_path TEXT DEFAULT _pb_wire_json_get_field_path(wire_json, field_number);
 	DECLARE next_index INT;
 	DECLARE new_element JSON;
 
@@ -1289,7 +2113,7 @@ BEGIN
 	SET next_index = _pb_wire_json_get_next_index(wire_json);
 
 	-- Create the new wire element
-	SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', wire_type, 'v', value);
+	SET new_element = _pb_wire_json_new_element(wire_json, next_index, field_number, wire_type, value);
 
 	-- If field doesn't exist, create it; otherwise append
 	IF NOT JSON_CONTAINS_PATH(wire_json, 'one', field_path) THEN
@@ -1303,10 +2127,305 @@ END $$
 DROP FUNCTION IF EXISTS _pb_wire_json_clear_field $$
 CREATE FUNCTION _pb_wire_json_clear_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE field_path TEXT DEFAULT _pb_wire_json_get_field_path(wire_json, field_number);
 	RETURN JSON_REMOVE(wire_json, field_path);
 END $$
 
+-- Private: Check if any element of a repeated field is encoded as a packed (LEN) element
+DROP FUNCTION IF EXISTS _pb_wire_json_is_packed_field $$
+CREATE FUNCTION _pb_wire_json_is_packed_field(wire_json JSON, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	RETURN COALESCE(JSON_CONTAINS(JSON_EXTRACT(wire_json, CONCAT(_pb_wire_json_get_field_path(wire_json, field_number), IF(_pb_wire_json_is_v2(wire_json), '[*][2]', '[*].t'))), '2'), FALSE);
+END $$
+
+-- Private: Return the indexes [start_index, end_index) of an array of element_count elements as a JSON array. Negative
+-- indices count from the end of the array and NULL end_index means the end of the array. Out-of-range indices are clamped.
+DROP FUNCTION IF EXISTS _pb_util_slice_indexes $$
+CREATE FUNCTION _pb_util_slice_indexes(element_count INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON DEFAULT JSON_ARRAY();
+	DECLARE i INT;
+
+	SET start_index = COALESCE(start_index, 0);
+	SET end_index = COALESCE(end_index, element_count);
+	IF start_index < 0 THEN
+		SET start_index = GREATEST(element_count + start_index, 0);
+	END IF;
+	IF end_index < 0 THEN
+		SET end_index = GREATEST(element_count + end_index, 0);
+	END IF;
+	SET end_index = LEAST(end_index, element_count);
+
+	SET i = start_index;
+	WHILE i < end_index DO
+		SET result = JSON_ARRAY_APPEND(result, '$', i);
+		SET i = i + 1;
+	END WHILE;
+	RETURN result;
+END $$
+
+-- Private: Return the indexes of an array of element_count elements in reverse order as a JSON array
+DROP FUNCTION IF EXISTS _pb_util_reverse_indexes $$
+CREATE FUNCTION _pb_util_reverse_indexes(element_count INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON DEFAULT JSON_ARRAY();
+	DECLARE i INT DEFAULT element_count - 1;
+
+	WHILE i >= 0 DO
+		SET result = JSON_ARRAY_APPEND(result, '$', i);
+		SET i = i - 1;
+	END WHILE;
+	RETURN result;
+END $$
+
+-- Private: Return the indexes of the elements of a JSON array in ascending order of the elements. key_type is
+-- 'integer', 'double' or 'string'. Strings are ordered by code point. Sort is stable.
+DROP FUNCTION IF EXISTS _pb_util_json_array_sort_indexes $$
+CREATE FUNCTION _pb_util_json_array_sort_indexes(value_array JSON, key_type TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE done BOOLEAN DEFAULT FALSE;
+	DECLARE keyed_elements JSON DEFAULT JSON_ARRAY();
+	DECLARE key_name TEXT;
+	DECLARE element_count INT DEFAULT JSON_LENGTH(value_array);
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE result JSON DEFAULT JSON_ARRAY();
+
+	DECLARE element_cursor CURSOR FOR
+		SELECT o - 1
+		FROM JSON_TABLE(
+			keyed_elements,
+			'$[*]' COLUMNS (
+				o FOR ORDINALITY,
+				n DECIMAL(20, 0) PATH '$.n',
+				d DOUBLE PATH '$.d',
+				s LONGTEXT PATH '$.s'
+			)
+		) jt
+		ORDER BY n, d, CAST(s AS BINARY), o; -- strings in code point order, regardless of the collation
+
+	DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = TRUE;
+
+	-- Only the key column for key_type is set, so that values are never converted to an incompatible column type
+	SET key_name = CASE key_type WHEN 'integer' THEN 'n' WHEN 'double' THEN 'd' ELSE 's' END;
+	WHILE element_index < element_count DO
+		SET keyed_elements = JSON_ARRAY_APPEND(keyed_elements, '$', JSON_OBJECT(key_name, JSON_EXTRACT(value_array, CONCAT('$[', element_index, ']'))));
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	OPEN element_cursor;
+	l1: LOOP
+		FETCH element_cursor INTO element_index;
+		IF done THEN
+			LEAVE l1;
+		END IF;
+		SET result = JSON_ARRAY_APPEND(result, '$', element_index);
+	END LOOP;
+	CLOSE element_cursor;
+
+	RETURN result;
+END $$
+
+-- Private: Return the indexes of the first occurrence of each distinct element of a JSON array
+DROP FUNCTION IF EXISTS _pb_util_json_array_distinct_indexes $$
+CREATE FUNCTION _pb_util_json_array_distinct_indexes(value_array JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE seen JSON DEFAULT JSON_ARRAY();
+	DECLARE result JSON DEFAULT JSON_ARRAY();
+	DECLARE element JSON;
+	DECLARE array_length INT DEFAULT JSON_LENGTH(value_array);
+	DECLARE i INT DEFAULT 0;
+
+	WHILE i < array_length DO
+		SET element = JSON_EXTRACT(value_array, CONCAT('$[', i, ']'));
+		IF NOT JSON_CONTAINS(seen, element) THEN
+			SET seen = JSON_ARRAY_APPEND(seen, '$', element);
+			SET result = JSON_ARRAY_APPEND(result, '$', i);
+		END IF;
+		SET i = i + 1;
+	END WHILE;
+	RETURN result;
+END $$
+
+-- Private: Rewrite the elements of a repeated field so that it holds the elements at indexes (a JSON array of element
+-- indexes, in the new order). The elements are written back to the places the field already occupies in the message,
+-- so the field keeps its position relative to the other fields: each occurrence of the field takes as many elements as
+-- it held before, in order, and occurrences left without elements are removed. wire_type is the wire type of an
+-- unpacked element; an occurrence of LEN wire type is a packed occurrence unless wire_type is LEN.
+DROP FUNCTION IF EXISTS _pb_message_rearrange_repeated_field $$
+CREATE FUNCTION _pb_message_rearrange_repeated_field(message LONGBLOB, field_number INT, wire_type INT, indexes JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE tag BIGINT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE current_wire_type INT;
+	DECLARE occurrence_start BIGINT;
+	DECLARE value_start BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_end BIGINT;
+	DECLARE element_start BIGINT;
+	DECLARE element_count INT;
+	-- [start, end) byte range of each element in message, i.e. the encoded value without the tag
+	DECLARE element_ranges JSON DEFAULT JSON_ARRAY();
+	-- [start, value_start, end, element_count, wire_type] of each occurrence of the field in message
+	DECLARE occurrences JSON DEFAULT JSON_ARRAY();
+	DECLARE occurrence JSON;
+	DECLARE occurrence_index INT DEFAULT 0;
+	DECLARE index_position INT DEFAULT 0;
+	DECLARE element_range JSON;
+	DECLARE payload LONGBLOB;
+	DECLARE encoded LONGBLOB;
+	DECLARE copied_end BIGINT DEFAULT 0;
+	DECLARE result LONGBLOB DEFAULT _binary '';
+
+	WHILE offset < message_length DO
+		SET occurrence_start = offset;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
+		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
+		SET value_start = offset;
+
+		IF _pb_wire_get_field_number_from_tag(tag) <> field_number THEN
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
+		ELSEIF current_wire_type = wire_type THEN
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
+			SET element_ranges = JSON_ARRAY_APPEND(element_ranges, '$', JSON_ARRAY(value_start, offset));
+			SET occurrences = JSON_ARRAY_APPEND(occurrences, '$', JSON_ARRAY(occurrence_start, value_start, offset, 1, current_wire_type));
+		ELSEIF current_wire_type = 2 THEN
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET packed_end = offset + value_length;
+			SET element_count = 0;
+			WHILE offset < packed_end DO
+				SET element_start = offset;
+				CALL _pb_wire_skip(SUBSTRING(message, offset + 1, LEAST(10, packed_end - offset)), packed_end, wire_type, offset);
+				SET element_ranges = JSON_ARRAY_APPEND(element_ranges, '$', JSON_ARRAY(element_start, offset));
+				SET element_count = element_count + 1;
+			END WHILE;
+			SET occurrences = JSON_ARRAY_APPEND(occurrences, '$', JSON_ARRAY(occurrence_start, value_start, offset, element_count, current_wire_type));
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_rearrange_repeated_field', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END IF;
+	END WHILE;
+
+	WHILE occurrence_index < JSON_LENGTH(occurrences) DO
+		SET occurrence = JSON_EXTRACT(occurrences, CONCAT('$[', occurrence_index, ']'));
+		SET occurrence_start = JSON_EXTRACT(occurrence, '$[0]');
+		SET value_start = JSON_EXTRACT(occurrence, '$[1]');
+		SET element_count = LEAST(JSON_EXTRACT(occurrence, '$[3]'), JSON_LENGTH(indexes) - index_position);
+		SET current_wire_type = JSON_EXTRACT(occurrence, '$[4]');
+
+		SET result = CONCAT(result, SUBSTRING(message, copied_end + 1, occurrence_start - copied_end));
+		IF element_count > 0 THEN
+			SET payload = _binary '';
+			WHILE element_count > 0 DO
+				SET element_range = JSON_EXTRACT(element_ranges, CONCAT('$[', JSON_EXTRACT(indexes, CONCAT('$[', index_position, ']')), ']'));
+				SET element_start = JSON_EXTRACT(element_range, '$[0]');
+				SET payload = CONCAT(payload, SUBSTRING(message, element_start + 1, JSON_EXTRACT(element_range, '$[1]') - element_start));
+				SET index_position = index_position + 1;
+				SET element_count = element_count - 1;
+			END WHILE;
+			IF current_wire_type <> wire_type THEN
+				CALL _pb_wire_write_len_type(payload, encoded);
+				SET payload = encoded;
+			END IF;
+			-- Keep the original tag of the occurrence
+			SET result = CONCAT(result, SUBSTRING(message, occurrence_start + 1, value_start - occurrence_start), payload);
+		END IF;
+		SET copied_end = JSON_EXTRACT(occurrence, '$[2]');
+		SET occurrence_index = occurrence_index + 1;
+	END WHILE;
+
+	RETURN CONCAT(result, SUBSTRING(message, copied_end + 1));
+END $$
+
+-- Private: Wire JSON version of _pb_message_rearrange_repeated_field. The elements keep their indexes ('i'), so the
+-- field keeps its position relative to the other fields.
+DROP FUNCTION IF EXISTS _pb_wire_json_rearrange_repeated_field $$
+CREATE FUNCTION _pb_wire_json_rearrange_repeated_field(wire_json JSON, field_number INT, wire_type INT, indexes JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE field_path TEXT DEFAULT _pb_wire_json_get_field_path(wire_json, field_number);
+	DECLARE elements JSON DEFAULT _pb_wire_json_get_field_elements(wire_json, field_number);
+	DECLARE element JSON;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE element_wire_type INT;
+	DECLARE element_count INT;
+	-- Values of the field as they appear in unpacked elements, i.e. numbers or base64 strings
+	DECLARE field_values JSON DEFAULT JSON_ARRAY();
+	-- Number of values held by each element
+	DECLARE element_counts JSON DEFAULT JSON_ARRAY();
+	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE encoded LONGBLOB;
+	DECLARE index_position INT DEFAULT 0;
+	DECLARE new_elements JSON DEFAULT JSON_ARRAY();
+
+	IF elements IS NULL THEN
+		RETURN wire_json;
+	END IF;
+
+	WHILE element_index < JSON_LENGTH(elements) DO
+		SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+		SET element_wire_type = JSON_EXTRACT(element, '$.t');
+		IF element_wire_type = wire_type THEN
+			SET field_values = JSON_ARRAY_APPEND(field_values, '$', JSON_EXTRACT(element, '$.v'));
+			SET element_count = 1;
+		ELSEIF element_wire_type = 2 THEN
+			SET packed_data = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
+			SET packed_offset = 0;
+			SET element_count = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CASE wire_type
+				WHEN 0 THEN -- VARINT
+					CALL _pb_wire_read_varint_as_uint64(SUBSTRING(packed_data, packed_offset + 1, 10), packed_offset, uint_value);
+				WHEN 1 THEN -- I64
+					CALL _pb_wire_read_i64_as_uint64(SUBSTRING(packed_data, packed_offset + 1, 8), packed_offset, uint_value);
+				ELSE -- I32
+					CALL _pb_wire_read_i32_as_uint32(SUBSTRING(packed_data, packed_offset + 1, 4), packed_offset, uint_value);
+				END CASE;
+				SET field_values = JSON_ARRAY_APPEND(field_values, '$', uint_value);
+				SET element_count = element_count + 1;
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_rearrange_repeated_field', CONCAT('unexpected wire_type (', element_wire_type, ')'));
+		END IF;
+		SET element_counts = JSON_ARRAY_APPEND(element_counts, '$', element_count);
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	SET element_index = 0;
+	WHILE element_index < JSON_LENGTH(elements) DO
+		SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+		SET element_count = LEAST(JSON_EXTRACT(element_counts, CONCAT('$[', element_index, ']')), JSON_LENGTH(indexes) - index_position);
+		IF element_count > 0 AND JSON_EXTRACT(element, '$.t') = wire_type THEN
+			SET new_elements = JSON_ARRAY_APPEND(new_elements, '$', _pb_wire_json_new_element(wire_json, JSON_EXTRACT(element, '$.i'), field_number, wire_type,
+				JSON_EXTRACT(field_values, CONCAT('$[', JSON_EXTRACT(indexes, CONCAT('$[', index_position, ']')), ']'))));
+			SET index_position = index_position + 1;
+		ELSEIF element_count > 0 THEN
+			SET packed_data = _binary '';
+			WHILE element_count > 0 DO
+				SET uint_value = CAST(JSON_EXTRACT(field_values, CONCAT('$[', JSON_EXTRACT(indexes, CONCAT('$[', index_position, ']')), ']')) AS UNSIGNED);
+				CASE wire_type
+				WHEN 0 THEN -- VARINT
+					CALL _pb_wire_write_varint(uint_value, encoded);
+				WHEN 1 THEN -- I64
+					CALL _pb_wire_write_i64(uint_value, encoded);
+				ELSE -- I32
+					CALL _pb_wire_write_i32(uint_value, encoded);
+				END CASE;
+				SET packed_data = CONCAT(packed_data, encoded);
+				SET index_position = index_position + 1;
+				SET element_count = element_count - 1;
+			END WHILE;
+			SET new_elements = JSON_ARRAY_APPEND(new_elements, '$', _pb_wire_json_new_element(wire_json, JSON_EXTRACT(element, '$.i'), field_number, 2, JSON_QUOTE(TO_BASE64(packed_data))));
+		END IF;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	IF JSON_LENGTH(new_elements) = 0 THEN
+		RETURN JSON_REMOVE(wire_json, field_path);
+	END IF;
+	RETURN JSON_SET(wire_json, field_path, new_elements);
+END $$
+
 -- Private: Set repeated VARINT field element at specific index
 DROP FUNCTION IF EXISTS _pb_wire_json_set_repeated_varint_field_element $$
 CREATE FUNCTION _pb_wire_json_set_repeated_varint_field_element(
@@ -1327,6 +2446,7 @@ BEGIN
 	DECLARE element_path TEXT;
 	-- Variables for packed field handling
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE new_packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_value BIGINT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
@@ -1349,8 +2469,9 @@ BEGIN
 			SET found_target = FALSE;
 
 			-- Decode packed varints and rebuild with modification
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_varint_as_uint64(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(packed_data, packed_offset + 1, 10), packed_offset, temp_value);
 
 				IF current_index = repeated_index THEN
 					-- Replace this element with new value
@@ -1383,8 +2504,7 @@ BEGIN
 			END IF;
 			SET current_index = current_index + 1;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_set_repeated_varint_field_element: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_set_repeated_varint_field_element', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET array_index = array_index + 1;
@@ -1414,6 +2534,7 @@ BEGIN
 	DECLARE element_path TEXT;
 	-- Variables for packed field handling
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE new_packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_value BIGINT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
@@ -1436,8 +2557,9 @@ BEGIN
 			SET found_target = FALSE;
 
 			-- Decode packed I64 values and rebuild with modification
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_i64_as_uint64(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(packed_data, packed_offset + 1, 8), packed_offset, temp_value);
 
 				IF current_index = repeated_index THEN
 					-- Replace this element with new value
@@ -1470,8 +2592,7 @@ BEGIN
 			END IF;
 			SET current_index = current_index + 1;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_set_repeated_i64_field_element: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_set_repeated_i64_field_element', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET array_index = array_index + 1;
@@ -1501,6 +2622,7 @@ BEGIN
 	DECLARE element_path TEXT;
 	-- Variables for packed field handling
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE new_packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_value INT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
@@ -1523,8 +2645,9 @@ BEGIN
 			SET found_target = FALSE;
 
 			-- Decode packed I32 values and rebuild with modification
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_i32_as_uint32(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(packed_data, packed_offset + 1, 4), packed_offset, temp_value);
 
 				IF current_index = repeated_index THEN
 					-- Replace this element with new value
@@ -1557,8 +2680,7 @@ BEGIN
 			END IF;
 			SET current_index = current_index + 1;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_set_repeated_i32_field_element: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_set_repeated_i32_field_element', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET array_index = array_index + 1;
@@ -1608,8 +2730,7 @@ BEGIN
 			END IF;
 			SET current_index = current_index + 1;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_set_repeated_len_field_element: unexpected wire_type (', element_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_set_repeated_len_field_element', CONCAT('unexpected wire_type (', element_wire_type, ')'));
 		END CASE;
 
 		SET array_index = array_index + 1;
@@ -1636,6 +2757,7 @@ BEGIN
 	DECLARE element_path TEXT;
 	-- Variables for packed field handling
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE new_packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_value BIGINT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
@@ -1663,8 +2785,9 @@ BEGIN
 			SET found_target = FALSE;
 
 			-- Decode packed varints and rebuild without target element
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_varint_as_uint64(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(packed_data, packed_offset + 1, 10), packed_offset, temp_value);
 
 				IF current_index = repeated_index THEN
 					-- Skip this element (remove it)
@@ -1739,6 +2862,7 @@ BEGIN
 	DECLARE element_path TEXT;
 	-- Variables for packed field handling
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE new_packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_value BIGINT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
@@ -1766,8 +2890,9 @@ BEGIN
 			SET found_target = FALSE;
 
 			-- Decode packed I64 values and rebuild without target element
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_i64_as_uint64(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(packed_data, packed_offset + 1, 8), packed_offset, temp_value);
 
 				IF current_index = repeated_index THEN
 					-- Skip this element (remove it)
@@ -1842,6 +2967,7 @@ BEGIN
 	DECLARE element_path TEXT;
 	-- Variables for packed field handling
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE new_packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_value INT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
@@ -1869,8 +2995,9 @@ BEGIN
 			SET found_target = FALSE;
 
 			-- Decode packed I32 values and rebuild without target element
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_i32_as_uint32(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(packed_data, packed_offset + 1, 4), packed_offset, temp_value);
 
 				IF current_index = repeated_index THEN
 					-- Skip this element (remove it)
@@ -1981,40 +3108,31 @@ BEGIN
 	SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'Index out of bounds';
 END $$
 
--- Private: Add to packed varint field
-DROP FUNCTION IF EXISTS _pb_wire_json_add_packed_varint_field $$
-CREATE FUNCTION _pb_wire_json_add_packed_varint_field(wire_json JSON, field_number INT, value BIGINT UNSIGNED) RETURNS JSON DETERMINISTIC
+-- Private: Append encoded values to the last element of a field if it is packed, or add a new packed element otherwise
+DROP FUNCTION IF EXISTS _pb_wire_json_append_packed_data $$
+CREATE FUNCTION _pb_wire_json_append_packed_data(wire_json JSON, field_number INT, packed_data LONGBLOB) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE field_path TEXT DEFAULT _pb_wire_json_get_field_path(wire_json, field_number);
 	DECLARE field_array JSON;
-	DECLARE packed_data LONGBLOB;
-	DECLARE new_varint LONGBLOB;
-	DECLARE last_element JSON;
-	DECLARE next_index INT;
+	DECLARE last_path TEXT;
 	DECLARE new_element JSON;
-	DECLARE last_index INT;
 
 	-- Get the field array (null if doesn't exist)
 	SET field_array = JSON_EXTRACT(wire_json, field_path);
 
-	-- Encode the new varint value
-	CALL _pb_wire_write_varint(value, new_varint);
-
 	-- Check if field exists and last element is LEN (wire type 2)
-	IF field_array IS NOT NULL THEN
-		SET last_index = JSON_LENGTH(field_array) - 1;
-		SET last_element = JSON_EXTRACT(field_array, CONCAT('$[', last_index, ']'));
-		IF JSON_EXTRACT(last_element, '$.t') = 2 THEN
+	IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
+		SET last_path = CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, ']');
+		IF JSON_EXTRACT(wire_json, CONCAT(last_path, IF(_pb_wire_json_is_v2(wire_json), '[2]', '.t'))) = 2 THEN
 			-- Append to existing packed data
-			SET packed_data = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(last_element, '$.v')));
-			SET packed_data = CONCAT(packed_data, new_varint);
-			RETURN JSON_SET(wire_json, CONCAT(field_path, '[', last_index, '].v'), TO_BASE64(packed_data));
+			SET last_path = CONCAT(last_path, IF(_pb_wire_json_is_v2(wire_json), '[1]', '.v'));
+			SET packed_data = CONCAT(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_json, last_path))), packed_data);
+			RETURN JSON_SET(wire_json, last_path, TO_BASE64(packed_data));
 		END IF;
 	END IF;
 
 	-- Create new packed element
-	SET next_index = _pb_wire_json_get_next_index(wire_json);
-	SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(new_varint));
+	SET new_element = _pb_wire_json_new_element(wire_json, _pb_wire_json_get_next_index(wire_json), field_number, 2, JSON_QUOTE(TO_BASE64(packed_data)));
 
 	IF field_array IS NULL THEN
 		RETURN JSON_SET(wire_json, field_path, JSON_ARRAY(new_element));
@@ -2023,88 +3141,37 @@ BEGIN
 	END IF;
 END $$
 
+-- Private: Add to packed varint field
+DROP FUNCTION IF EXISTS _pb_wire_json_add_packed_varint_field $$
+CREATE FUNCTION _pb_wire_json_add_packed_varint_field(wire_json JSON, field_number INT, value BIGINT UNSIGNED) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE new_varint LONGBLOB;
+
+	-- Encode the new varint value
+	CALL _pb_wire_write_varint(value, new_varint);
+	RETURN _pb_wire_json_append_packed_data(wire_json, field_number, new_varint);
+END $$
+
 -- Private: Add to packed I64 field
 DROP FUNCTION IF EXISTS _pb_wire_json_add_packed_i64_field $$
 CREATE FUNCTION _pb_wire_json_add_packed_i64_field(wire_json JSON, field_number INT, value BIGINT UNSIGNED) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE packed_data LONGBLOB;
 	DECLARE new_i64 LONGBLOB;
-	DECLARE last_element JSON;
-	DECLARE next_index INT;
-	DECLARE new_element JSON;
-	DECLARE last_index INT;
-
-	-- Get the field array (null if doesn't exist)
-	SET field_array = JSON_EXTRACT(wire_json, field_path);
 
 	-- Encode the new I64 value (8 bytes little-endian)
 	CALL _pb_wire_write_i64(value, new_i64);
-
-	-- Check if field exists and last element is LEN (wire type 2)
-	IF field_array IS NOT NULL THEN
-		SET last_index = JSON_LENGTH(field_array) - 1;
-		SET last_element = JSON_EXTRACT(field_array, CONCAT('$[', last_index, ']'));
-		IF JSON_EXTRACT(last_element, '$.t') = 2 THEN
-			-- Append to existing packed data
-			SET packed_data = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(last_element, '$.v')));
-			SET packed_data = CONCAT(packed_data, new_i64);
-			RETURN JSON_SET(wire_json, CONCAT(field_path, '[', last_index, '].v'), TO_BASE64(packed_data));
-		END IF;
-	END IF;
-
-	-- Create new packed element
-	SET next_index = _pb_wire_json_get_next_index(wire_json);
-	SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(new_i64));
-
-	IF field_array IS NULL THEN
-		RETURN JSON_SET(wire_json, field_path, JSON_ARRAY(new_element));
-	ELSE
-		RETURN JSON_ARRAY_APPEND(wire_json, field_path, new_element);
-	END IF;
+	RETURN _pb_wire_json_append_packed_data(wire_json, field_number, new_i64);
 END $$
 
 -- Private: Add to packed I32 field
 DROP FUNCTION IF EXISTS _pb_wire_json_add_packed_i32_field $$
 CREATE FUNCTION _pb_wire_json_add_packed_i32_field(wire_json JSON, field_number INT, value INT UNSIGNED) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE packed_data LONGBLOB;
 	DECLARE new_i32 LONGBLOB;
-	DECLARE last_element JSON;
-	DECLARE next_index INT;
-	DECLARE new_element JSON;
-	DECLARE last_index INT;
-
-	-- Get the field array (null if doesn't exist)
-	SET field_array = JSON_EXTRACT(wire_json, field_path);
 
 	-- Encode the new I32 value (4 bytes little-endian)
 	CALL _pb_wire_write_i32(value, new_i32);
-
-	-- Check if field exists and last element is LEN (wire type 2)
-	IF field_array IS NOT NULL THEN
-		SET last_index = JSON_LENGTH(field_array) - 1;
-		SET last_element = JSON_EXTRACT(field_array, CONCAT('$[', last_index, ']'));
-		IF JSON_EXTRACT(last_element, '$.t') = 2 THEN
-			-- Append to existing packed data
-			SET packed_data = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(last_element, '$.v')));
-			SET packed_data = CONCAT(packed_data, new_i32);
-			RETURN JSON_SET(wire_json, CONCAT(field_path, '[', last_index, '].v'), TO_BASE64(packed_data));
-		END IF;
-	END IF;
-
-	-- Create new packed element
-	SET next_index = _pb_wire_json_get_next_index(wire_json);
-	SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(new_i32));
-
-	IF field_array IS NULL THEN
-		RETURN JSON_SET(wire_json, field_path, JSON_ARRAY(new_element));
-	ELSE
-		RETURN JSON_ARRAY_APPEND(wire_json, field_path, new_element);
-	END IF;
+	RETURN _pb_wire_json_append_packed_data(wire_json, field_number, new_i32);
 END $$
 
 -- Private: Set VARINT field (int32, int64, uint32, uint64, sint32, sint64, enum, bool)
@@ -2197,6 +3264,7 @@ BEGIN
 	DECLARE inserted BOOLEAN DEFAULT FALSE;
 	-- Variables for processing elements
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE temp_value BIGINT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
 	DECLARE result_packed_data LONGBLOB DEFAULT '';
@@ -2232,8 +3300,9 @@ BEGIN
 		IF element_wire_type = 2 THEN
 			-- Packed field - process each packed value
 			SET packed_data = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_varint_as_uint64(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(packed_data, packed_offset + 1, 10), packed_offset, temp_value);
 
 				-- Check if we need to insert here
 				IF NOT inserted AND logical_position = repeated_index THEN
@@ -2339,6 +3408,7 @@ BEGIN
 	DECLARE inserted BOOLEAN DEFAULT FALSE;
 	-- Variables for processing elements
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE temp_value BIGINT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
 	DECLARE result_packed_data LONGBLOB DEFAULT '';
@@ -2374,8 +3444,9 @@ BEGIN
 		IF element_wire_type = 2 THEN
 			-- Packed field - process each packed value
 			SET packed_data = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_i64_as_uint64(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(packed_data, packed_offset + 1, 8), packed_offset, temp_value);
 
 				-- Check if we need to insert here
 				IF NOT inserted AND logical_position = repeated_index THEN
@@ -2481,6 +3552,7 @@ BEGIN
 	DECLARE inserted BOOLEAN DEFAULT FALSE;
 	-- Variables for processing elements
 	DECLARE packed_data LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE temp_value INT UNSIGNED;
 	DECLARE temp_encoded LONGBLOB;
 	DECLARE result_packed_data LONGBLOB DEFAULT '';
@@ -2516,8 +3588,9 @@ BEGIN
 		IF element_wire_type = 2 THEN
 			-- Packed field - process each packed value
 			SET packed_data = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
-			WHILE LENGTH(packed_data) > 0 DO
-				CALL _pb_wire_read_i32_as_uint32(packed_data, temp_value, packed_data);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(packed_data) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(packed_data, packed_offset + 1, 4), packed_offset, temp_value);
 
 				-- Check if we need to insert here
 				IF NOT inserted AND logical_position = repeated_index THEN
@@ -2669,35 +3742,602 @@ BEGIN
 	RETURN JSON_SET(wire_json, field_path, new_field_array);
 END $$
 
-DELIMITER $$
-
-DROP FUNCTION IF EXISTS pb_message_get_int32_field $$
-CREATE FUNCTION pb_message_get_int32_field(message LONGBLOB, field_number INT, default_value INT) RETURNS INT DETERMINISTIC
+-- Parses a field path such as 'order.items[2].price', 'labels["env"]', '6[sint32:-3]' or '4.2[-1].1' into a JSON
+-- array of segments. Each segment is an object holding the field token as "f" and, optionally, either a repeated
+-- index as "i" or a map key as "k". A map key written as `[type:value]`, where type is one of the map key types,
+-- also has its type name as "t".
+DROP FUNCTION IF EXISTS _pb_util_parse_field_path $$
+CREATE FUNCTION _pb_util_parse_field_path(path TEXT) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_message_get_varint_field_as_uint64(message, field_number, NULL, value, field_count);
-	IF field_count = 0 THEN
-		RETURN default_value;
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segments JSON;
+	DECLARE segment JSON;
+	DECLARE path_length INT;
+	DECLARE pos INT;
+	DECLARE c TEXT;
+	DECLARE quote_char TEXT;
+	DECLARE token TEXT;
+	DECLARE subscript TEXT;
+	DECLARE is_closed BOOLEAN;
+
+	IF path IS NULL THEN
+		RETURN NULL;
 	END IF;
-	RETURN _pb_util_reinterpret_uint64_as_int64(value);
-END $$
 
-DROP FUNCTION IF EXISTS pb_message_has_int32_field $$
-CREATE FUNCTION pb_message_has_int32_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
-BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_message_get_varint_field_as_uint64(message, field_number, NULL, value, field_count);
-	RETURN field_count > 0;
-END $$
+	SET segments = JSON_ARRAY();
+	SET path_length = CHAR_LENGTH(path);
+	SET pos = 1;
+
+	WHILE pos <= path_length DO
+		-- Field name or number
+		SET token = '';
+		SET c = SUBSTRING(path, pos, 1);
+		WHILE pos <= path_length AND c <> '.' AND c <> '[' DO
+			IF NOT (c REGEXP '^[A-Za-z0-9_]$') THEN
+				SET message_text = CONCAT('_pb_util_parse_field_path: unexpected character `', c, '` at position ', pos, ' in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			SET token = CONCAT(token, c);
+			SET pos = pos + 1;
+			SET c = SUBSTRING(path, pos, 1);
+		END WHILE;
 
-DROP FUNCTION IF EXISTS pb_message_get_repeated_int32_field_element $$
-CREATE FUNCTION pb_message_get_repeated_int32_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS INT DETERMINISTIC
-BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_message_get_varint_field_as_uint64(message, field_number, repeated_index, value, field_count);
+		IF token = '' THEN
+			SET message_text = CONCAT('_pb_util_parse_field_path: empty field name at position ', pos, ' in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET segment = JSON_OBJECT('f', token);
+
+		-- Optional repeated index or map key
+		IF c = '[' THEN
+			SET pos = pos + 1;
+			SET c = SUBSTRING(path, pos, 1);
+			SET subscript = '';
+			SET is_closed = FALSE;
+
+			IF c = '"' OR c = '''' THEN
+				SET quote_char = c;
+				SET pos = pos + 1;
+				WHILE pos <= path_length AND NOT is_closed DO
+					SET c = SUBSTRING(path, pos, 1);
+					IF c = '\\' AND pos < path_length THEN
+						SET pos = pos + 1;
+						SET subscript = CONCAT(subscript, SUBSTRING(path, pos, 1));
+					ELSEIF c = quote_char THEN
+						SET is_closed = TRUE;
+					ELSE
+						SET subscript = CONCAT(subscript, c);
+					END IF;
+					SET pos = pos + 1;
+				END WHILE;
+
+				IF NOT is_closed OR SUBSTRING(path, pos, 1) <> ']' THEN
+					SET message_text = CONCAT('_pb_util_parse_field_path: unterminated map key at position ', pos, ' in path `', path, '`');
+					SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+				END IF;
+				SET segment = JSON_SET(segment, '$.k', subscript);
+			ELSE
+				WHILE pos <= path_length AND NOT is_closed DO
+					SET c = SUBSTRING(path, pos, 1);
+					IF c = ']' THEN
+						SET is_closed = TRUE;
+					ELSE
+						SET subscript = CONCAT(subscript, c);
+						SET pos = pos + 1;
+					END IF;
+				END WHILE;
+
+				SET subscript = TRIM(subscript);
+				IF NOT is_closed OR subscript = '' THEN
+					SET message_text = CONCAT('_pb_util_parse_field_path: invalid subscript at position ', pos, ' in path `', path, '`');
+					SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+				END IF;
+
+				IF subscript REGEXP '^-?[0-9]+$' THEN
+					SET segment = JSON_SET(segment, '$.i', CAST(subscript AS SIGNED));
+				ELSEIF subscript REGEXP '^(int32|int64|uint32|uint64|sint32|sint64|fixed32|fixed64|sfixed32|sfixed64|bool|string):' THEN
+					SET segment = JSON_SET(segment, '$.t', SUBSTRING_INDEX(subscript, ':', 1), '$.k', TRIM(SUBSTRING(subscript, LOCATE(':', subscript) + 1)));
+				ELSE
+					SET segment = JSON_SET(segment, '$.k', subscript);
+				END IF;
+			END IF;
+
+			SET pos = pos + 1; -- skip ']'
+			SET c = SUBSTRING(path, pos, 1);
+		END IF;
+
+		SET segments = JSON_ARRAY_APPEND(segments, '$', segment);
+
+		-- Separator
+		IF pos <= path_length THEN
+			IF c <> '.' OR pos = path_length THEN
+				SET message_text = CONCAT('_pb_util_parse_field_path: unexpected character `', c, '` at position ', pos, ' in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			SET pos = pos + 1;
+		END IF;
+	END WHILE;
+
+	RETURN segments;
+END $$
+
+-- Private: Finds the last map entry of a map field whose key, decoded as map_key_type, matches the given text
+-- representation. An absent key is the default value of its type.
+DROP FUNCTION IF EXISTS _pb_message_find_map_entry $$
+CREATE FUNCTION _pb_message_find_map_entry(message LONGBLOB, field_number INT, map_key_type TEXT, map_key TEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE entry_count INT;
+	DECLARE entry_index INT;
+	DECLARE entry LONGBLOB;
+	DECLARE key_json JSON;
+	DECLARE key_text TEXT;
+	DECLARE found LONGBLOB;
+
+	SET entry_count = pb_message_get_repeated_message_field_count(message, field_number);
+	SET entry_index = 0;
+
+	WHILE entry_index < entry_count DO
+		SET entry = pb_message_get_repeated_message_field_element(message, field_number, entry_index);
+		SET key_json = _pb_message_get_field_element_as_json(entry, 1, map_key_type, NULL);
+		IF key_json IS NULL THEN
+			SET key_text = CASE map_key_type WHEN 'string' THEN '' WHEN 'bool' THEN 'false' ELSE '0' END;
+		ELSE
+			SET key_text = JSON_UNQUOTE(key_json);
+		END IF;
+
+		IF BINARY key_text = BINARY map_key THEN
+			SET found = entry;
+		END IF;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+
+	RETURN found;
+END $$
+
+-- Private: Returns a field value as JSON, decoding it according to the given type name.
+-- A NULL repeated_index selects the last occurrence, which is what a singular field reader sees.
+DROP FUNCTION IF EXISTS _pb_message_get_field_element_as_json $$
+CREATE FUNCTION _pb_message_get_field_element_as_json(message LONGBLOB, field_number INT, type_name TEXT, repeated_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE element_count INT;
+
+	CASE type_name
+	WHEN 'int32' THEN SET element_count = pb_message_get_repeated_int32_field_count(message, field_number);
+	WHEN 'int64' THEN SET element_count = pb_message_get_repeated_int64_field_count(message, field_number);
+	WHEN 'uint32' THEN SET element_count = pb_message_get_repeated_uint32_field_count(message, field_number);
+	WHEN 'uint64' THEN SET element_count = pb_message_get_repeated_uint64_field_count(message, field_number);
+	WHEN 'sint32' THEN SET element_count = pb_message_get_repeated_sint32_field_count(message, field_number);
+	WHEN 'sint64' THEN SET element_count = pb_message_get_repeated_sint64_field_count(message, field_number);
+	WHEN 'enum' THEN SET element_count = pb_message_get_repeated_enum_field_count(message, field_number);
+	WHEN 'bool' THEN SET element_count = pb_message_get_repeated_bool_field_count(message, field_number);
+	WHEN 'fixed32' THEN SET element_count = pb_message_get_repeated_fixed32_field_count(message, field_number);
+	WHEN 'sfixed32' THEN SET element_count = pb_message_get_repeated_sfixed32_field_count(message, field_number);
+	WHEN 'float' THEN SET element_count = pb_message_get_repeated_float_field_count(message, field_number);
+	WHEN 'fixed64' THEN SET element_count = pb_message_get_repeated_fixed64_field_count(message, field_number);
+	WHEN 'sfixed64' THEN SET element_count = pb_message_get_repeated_sfixed64_field_count(message, field_number);
+	WHEN 'double' THEN SET element_count = pb_message_get_repeated_double_field_count(message, field_number);
+	WHEN 'string' THEN SET element_count = pb_message_get_repeated_string_field_count(message, field_number);
+	WHEN 'bytes' THEN SET element_count = pb_message_get_repeated_bytes_field_count(message, field_number);
+	WHEN 'message' THEN SET element_count = pb_message_get_repeated_message_field_count(message, field_number);
+	ELSE
+		SET message_text = CONCAT('_pb_message_get_field_element_as_json: unsupported type name `', type_name, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+
+	IF element_count = 0 THEN
+		RETURN NULL;
+	END IF;
+
+	IF repeated_index IS NULL THEN
+		IF type_name = 'message' THEN
+			RETURN JSON_QUOTE(TO_BASE64(pb_message_get_message_field(message, field_number, NULL)));
+		END IF;
+		SET repeated_index = element_count - 1;
+	ELSEIF repeated_index < 0 THEN
+		SET repeated_index = element_count + repeated_index;
+	END IF;
+
+	IF repeated_index < 0 OR repeated_index >= element_count THEN
+		RETURN NULL;
+	END IF;
+
+	CASE type_name
+	WHEN 'int32' THEN RETURN CAST(pb_message_get_repeated_int32_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'int64' THEN RETURN CAST(pb_message_get_repeated_int64_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'uint32' THEN RETURN CAST(pb_message_get_repeated_uint32_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'uint64' THEN RETURN CAST(pb_message_get_repeated_uint64_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'sint32' THEN RETURN CAST(pb_message_get_repeated_sint32_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'sint64' THEN RETURN CAST(pb_message_get_repeated_sint64_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'enum' THEN RETURN CAST(pb_message_get_repeated_enum_field_element(message, field_number, repeated_index) AS JSON);
+	-- See https://bugs.mysql.com/bug.php?id=79813
+	WHEN 'bool' THEN RETURN CAST((pb_message_get_repeated_bool_field_element(message, field_number, repeated_index) IS TRUE) AS JSON);
+	WHEN 'fixed32' THEN RETURN CAST(pb_message_get_repeated_fixed32_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'sfixed32' THEN RETURN CAST(pb_message_get_repeated_sfixed32_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'float' THEN RETURN CAST(pb_message_get_repeated_float_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'fixed64' THEN RETURN CAST(pb_message_get_repeated_fixed64_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'sfixed64' THEN RETURN CAST(pb_message_get_repeated_sfixed64_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'double' THEN RETURN CAST(pb_message_get_repeated_double_field_element(message, field_number, repeated_index) AS JSON);
+	WHEN 'string' THEN RETURN JSON_QUOTE(pb_message_get_repeated_string_field_element(message, field_number, repeated_index));
+	WHEN 'bytes' THEN RETURN JSON_QUOTE(TO_BASE64(pb_message_get_repeated_bytes_field_element(message, field_number, repeated_index)));
+	WHEN 'message' THEN RETURN JSON_QUOTE(TO_BASE64(pb_message_get_repeated_message_field_element(message, field_number, repeated_index)));
+	END CASE;
+END $$
+
+-- Returns the JSON value at a field number path such as '4.2[0].1', decoding the last field according to
+-- type_name ('int32', 'string', 'bytes', 'message', ...). Returns NULL when a field along the path is absent.
+DROP FUNCTION IF EXISTS pb_message_get_by_number_path $$
+CREATE FUNCTION pb_message_get_by_number_path(message LONGBLOB, path TEXT, type_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segments JSON;
+	DECLARE segment JSON;
+	DECLARE segment_count INT;
+	DECLARE segment_index INT;
+	DECLARE field_token TEXT;
+	DECLARE field_number INT;
+	DECLARE repeated_index INT;
+	DECLARE map_key TEXT;
+	DECLARE map_key_type TEXT;
+	DECLARE element_count INT;
+	DECLARE current_message LONGBLOB;
+
+	IF message IS NULL OR path IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET segments = _pb_util_parse_field_path(path);
+	SET segment_count = JSON_LENGTH(segments);
+	IF segment_count = 0 THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = 'pb_message_get_by_number_path: path must not be empty';
+	END IF;
+
+	SET current_message = message;
+	SET segment_index = 0;
+
+	WHILE segment_index < segment_count DO
+		SET segment = JSON_EXTRACT(segments, CONCAT('$[', segment_index, ']'));
+		SET field_token = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.f'));
+		SET repeated_index = JSON_EXTRACT(segment, '$.i');
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.k'));
+		SET map_key_type = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(segment, '$.t')), 'string');
+
+		IF NOT (field_token REGEXP '^[0-9]+$') THEN
+			SET message_text = CONCAT('pb_message_get_by_number_path: `', field_token, '` is not a field number in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+		SET field_number = CAST(field_token AS UNSIGNED);
+
+		IF map_key IS NOT NULL THEN
+			-- Continue with the value field of the matching map entry
+			SET current_message = _pb_message_find_map_entry(current_message, field_number, map_key_type, map_key);
+			IF current_message IS NULL THEN
+				RETURN NULL;
+			END IF;
+			SET field_number = 2;
+		END IF;
+
+		IF segment_index = segment_count - 1 THEN
+			RETURN _pb_message_get_field_element_as_json(current_message, field_number, type_name, repeated_index);
+		END IF;
+
+		IF repeated_index IS NULL THEN
+			IF NOT pb_message_has_message_field(current_message, field_number) THEN
+				RETURN NULL;
+			END IF;
+			SET current_message = pb_message_get_message_field(current_message, field_number, NULL);
+		ELSE
+			SET element_count = pb_message_get_repeated_message_field_count(current_message, field_number);
+			IF repeated_index < 0 THEN
+				SET repeated_index = element_count + repeated_index;
+			END IF;
+			IF repeated_index < 0 OR repeated_index >= element_count THEN
+				RETURN NULL;
+			END IF;
+			SET current_message = pb_message_get_repeated_message_field_element(current_message, field_number, repeated_index);
+		END IF;
+
+		SET segment_index = segment_index + 1;
+	END WHILE;
+
+	RETURN NULL;
+END $$
+
+-- Private: Builds a single entry of the list returned by the diff functions
+DROP FUNCTION IF EXISTS _pb_diff_entry $$
+CREATE FUNCTION _pb_diff_entry(path TEXT, op TEXT, old_value JSON, new_value JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN JSON_OBJECT('path', path, 'op', op, 'old', old_value, 'new', new_value);
+END $$
+
+-- Compares two wire_json objects without a schema and returns a JSON array of {path, op, old, new} entries.
+-- Occurrences of each field number are compared by position. Paths look like '4[0]', and values are {"t": wire_type, "v": value}.
+DROP FUNCTION IF EXISTS pb_wire_json_diff $$
+CREATE FUNCTION pb_wire_json_diff(old_wire_json JSON, new_wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE diff JSON;
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT DEFAULT 0;
+	DECLARE field_number TEXT;
+	DECLARE old_elements JSON;
+	DECLARE new_elements JSON;
+	DECLARE old_count INT;
+	DECLARE new_count INT;
+	DECLARE element_index INT;
+	DECLARE old_value JSON;
+	DECLARE new_value JSON;
+
+	IF old_wire_json IS NULL OR new_wire_json IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET old_wire_json = pb_wire_json_to_v1(old_wire_json);
+	SET new_wire_json = pb_wire_json_to_v1(new_wire_json);
+	SET diff = JSON_ARRAY();
+
+	-- JSON_KEYS() orders numeric keys of the same length lexicographically, and shorter keys first, so field numbers come out in ascending order
+	SET field_numbers = JSON_KEYS(JSON_MERGE_PATCH(old_wire_json, new_wire_json));
+	SET field_number_count = JSON_LENGTH(field_numbers);
+
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		SET old_elements = COALESCE(JSON_EXTRACT(old_wire_json, CONCAT('$."', field_number, '"')), JSON_ARRAY());
+		SET new_elements = COALESCE(JSON_EXTRACT(new_wire_json, CONCAT('$."', field_number, '"')), JSON_ARRAY());
+		SET old_count = JSON_LENGTH(old_elements);
+		SET new_count = JSON_LENGTH(new_elements);
+
+		SET element_index = 0;
+		WHILE element_index < LEAST(old_count, new_count) DO
+			SET old_value = JSON_EXTRACT(old_elements, CONCAT('$[', element_index, ']'));
+			SET new_value = JSON_EXTRACT(new_elements, CONCAT('$[', element_index, ']'));
+			SET old_value = JSON_OBJECT('t', JSON_EXTRACT(old_value, '$.t'), 'v', JSON_EXTRACT(old_value, '$.v'));
+			SET new_value = JSON_OBJECT('t', JSON_EXTRACT(new_value, '$.t'), 'v', JSON_EXTRACT(new_value, '$.v'));
+			IF old_value <> new_value THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(CONCAT(field_number, '[', element_index, ']'), 'replace', old_value, new_value));
+			END IF;
+			SET element_index = element_index + 1;
+		END WHILE;
+
+		-- Extra occurrences in new are appended
+		WHILE element_index < new_count DO
+			SET new_value = JSON_EXTRACT(new_elements, CONCAT('$[', element_index, ']'));
+			SET new_value = JSON_OBJECT('t', JSON_EXTRACT(new_value, '$.t'), 'v', JSON_EXTRACT(new_value, '$.v'));
+			SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(CONCAT(field_number, '[', element_index, ']'), 'add', NULL, new_value));
+			SET element_index = element_index + 1;
+		END WHILE;
+
+		-- Extra occurrences in old are removed from the end, so that the entries can be applied in order
+		SET element_index = old_count - 1;
+		WHILE element_index >= new_count DO
+			SET old_value = JSON_EXTRACT(old_elements, CONCAT('$[', element_index, ']'));
+			SET old_value = JSON_OBJECT('t', JSON_EXTRACT(old_value, '$.t'), 'v', JSON_EXTRACT(old_value, '$.v'));
+			SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(CONCAT(field_number, '[', element_index, ']'), 'remove', old_value, NULL));
+			SET element_index = element_index - 1;
+		END WHILE;
+
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+
+	RETURN diff;
+END $$
+
+-- Applies a diff returned by pb_wire_json_diff() to a wire_json object
+DROP FUNCTION IF EXISTS pb_wire_json_patch $$
+CREATE FUNCTION pb_wire_json_patch(wire_json JSON, diff JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE entry_count INT;
+	DECLARE entry_index INT DEFAULT 0;
+	DECLARE entry JSON;
+	DECLARE path TEXT;
+	DECLARE op TEXT;
+	DECLARE new_value JSON;
+	DECLARE field_number INT;
+	DECLARE element_index INT;
+	DECLARE element_count INT;
+	DECLARE field_path TEXT;
+	DECLARE original_wire_json JSON DEFAULT wire_json;
+
+	IF wire_json IS NULL OR diff IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET wire_json = pb_wire_json_to_v1(wire_json);
+	SET entry_count = JSON_LENGTH(diff);
+	WHILE entry_index < entry_count DO
+		SET entry = JSON_EXTRACT(diff, CONCAT('$[', entry_index, ']'));
+		SET path = JSON_UNQUOTE(JSON_EXTRACT(entry, '$.path'));
+		SET op = JSON_UNQUOTE(JSON_EXTRACT(entry, '$.op'));
+		SET new_value = JSON_EXTRACT(entry, '$.new');
+
+		IF NOT (path REGEXP '^[0-9]+\\[[0-9]+\\]$') THEN
+			SET message_text = CONCAT('pb_wire_json_patch: invalid path `', COALESCE(path, 'NULL'), '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_number = CAST(SUBSTRING_INDEX(path, '[', 1) AS UNSIGNED);
+		SET element_index = CAST(SUBSTRING_INDEX(SUBSTRING_INDEX(path, '[', -1), ']', 1) AS UNSIGNED);
+		SET field_path = CONCAT('$."', field_number, '"');
+		SET element_count = COALESCE(JSON_LENGTH(JSON_EXTRACT(wire_json, field_path)), 0);
+
+		IF op = 'add' THEN
+			IF element_index <> element_count THEN
+				SET message_text = CONCAT('pb_wire_json_patch: cannot add `', path, '`, field ', field_number, ' has ', element_count, ' occurrences');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			IF element_count = 0 THEN
+				SET wire_json = JSON_SET(wire_json, field_path, JSON_ARRAY());
+			END IF;
+			SET wire_json = JSON_ARRAY_APPEND(wire_json, field_path, JSON_OBJECT('i', _pb_wire_json_get_next_index(wire_json), 'n', field_number, 't', JSON_EXTRACT(new_value, '$.t'), 'v', JSON_EXTRACT(new_value, '$.v')));
+		ELSEIF op = 'replace' OR op = 'remove' THEN
+			IF element_index >= element_count THEN
+				SET message_text = CONCAT('pb_wire_json_patch: cannot ', op, ' `', path, '`, field ', field_number, ' has ', element_count, ' occurrences');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			IF op = 'replace' THEN
+				SET wire_json = JSON_SET(wire_json,
+					CONCAT(field_path, '[', element_index, '].t'), JSON_EXTRACT(new_value, '$.t'),
+					CONCAT(field_path, '[', element_index, '].v'), JSON_EXTRACT(new_value, '$.v'));
+			ELSEIF element_count = 1 THEN
+				SET wire_json = JSON_REMOVE(wire_json, field_path);
+			ELSE
+				SET wire_json = JSON_REMOVE(wire_json, CONCAT(field_path, '[', element_index, ']'));
+			END IF;
+		ELSE
+			SET message_text = CONCAT('pb_wire_json_patch: unsupported op `', COALESCE(op, 'NULL'), '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+
+	RETURN _pb_wire_json_to_same_version(wire_json, original_wire_json);
+END $$
+
+-- Helper procedure to read the length prefix of the next message in a length-delimited stream (see pb_delimited_count)
+-- and advance the offset to the start of the message
+DROP PROCEDURE IF EXISTS _pb_delimited_read_element_header $$
+CREATE PROCEDURE _pb_delimited_read_element_header(IN stream LONGBLOB, IN stream_length BIGINT, INOUT offset BIGINT, OUT element_length BIGINT)
+BEGIN
+	DECLARE len BIGINT UNSIGNED;
+
+	CALL _pb_wire_read_varint_as_uint64(SUBSTRING(stream, offset + 1, 10), offset, len);
+
+	IF stream_length - offset < len THEN
+		CALL _pb_signal_error('PB_LENGTH_OUT_OF_BOUNDS', '_pb_delimited_read_element_header', 'Unexpected end of BLOB.');
+	END IF;
+
+	SET element_length = len;
+END $$
+
+-- Returns the number of messages in a length-delimited stream, i.e. messages that are each preceded by their length
+-- as a varint, as written by writeDelimitedTo() in Java.
+DROP FUNCTION IF EXISTS pb_delimited_count $$
+CREATE FUNCTION pb_delimited_count(stream LONGBLOB) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE stream_length BIGINT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE element_length BIGINT;
+	DECLARE element_count INT DEFAULT 0;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset of the failing length prefix to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, NULL);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	IF stream IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET stream_length = LENGTH(stream);
+	WHILE offset < stream_length DO
+		CALL _pb_delimited_read_element_header(stream, stream_length, offset, element_length);
+		SET offset = offset + element_length;
+		SET element_count = element_count + 1;
+	END WHILE;
+
+	RETURN element_count;
+END $$
+
+-- Returns the message at the zero-based element_index of a length-delimited stream (see pb_delimited_count)
+DROP FUNCTION IF EXISTS pb_delimited_get_element $$
+CREATE FUNCTION pb_delimited_get_element(stream LONGBLOB, element_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE stream_length BIGINT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE element_length BIGINT;
+	DECLARE current_index INT DEFAULT 0;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset of the failing length prefix to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, NULL);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	IF stream IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	IF element_index < 0 THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = 'pb_delimited_get_element: element index out of range';
+	END IF;
+
+	SET stream_length = LENGTH(stream);
+	WHILE offset < stream_length DO
+		CALL _pb_delimited_read_element_header(stream, stream_length, offset, element_length);
+		IF current_index = element_index THEN
+			RETURN SUBSTRING(stream, offset + 1, element_length);
+		END IF;
+		SET offset = offset + element_length;
+		SET current_index = current_index + 1;
+	END WHILE;
+
+	SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = 'pb_delimited_get_element: element index out of range';
+END $$
+
+-- Appends a message to a length-delimited stream (see pb_delimited_count). A NULL stream is treated as an empty stream.
+DROP FUNCTION IF EXISTS pb_delimited_append $$
+CREATE FUNCTION pb_delimited_append(stream LONGBLOB, message LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE encoded LONGBLOB;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_wire_write_len_type(message, encoded);
+	RETURN CONCAT(COALESCE(stream, _binary ''), encoded);
+END $$
+
+DELIMITER $$
+
+DROP FUNCTION IF EXISTS pb_message_get_int32_field $$
+CREATE FUNCTION pb_message_get_int32_field(message LONGBLOB, field_number INT, default_value INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_get_varint_field_as_uint64(message, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_has_int32_field $$
+CREATE FUNCTION pb_message_has_int32_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_get_varint_field_as_uint64(message, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_get_repeated_int32_field_element $$
+CREATE FUNCTION pb_message_get_repeated_int32_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_get_varint_field_as_uint64(message, field_number, repeated_index, value, field_count);
 	RETURN _pb_util_reinterpret_uint64_as_int64(value);
 END $$
 
@@ -4252,81 +5892,471 @@ BEGIN
 	RETURN pb_wire_json_to_message(pb_wire_json_set_repeated_message_field(pb_message_to_wire_json(message), field_number, value_array));
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_int32_field $$
-CREATE FUNCTION pb_wire_json_get_int32_field(wire_json JSON, field_number INT, default_value INT) RETURNS INT DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_int32_field $$
+CREATE FUNCTION pb_message_slice_repeated_int32_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, NULL, value, field_count);
-	IF field_count = 0 THEN
-		RETURN default_value;
-	END IF;
-	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_slice_indexes(pb_message_get_repeated_int32_field_count(message, field_number), start_index, end_index));
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_has_int32_field $$
-CREATE FUNCTION pb_wire_json_has_int32_field(wire_json JSON, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_int32_field $$
+CREATE FUNCTION pb_message_reverse_repeated_int32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, NULL, value, field_count);
-	RETURN field_count > 0;
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_reverse_indexes(pb_message_get_repeated_int32_field_count(message, field_number)));
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_int32_field_element $$
-CREATE FUNCTION pb_wire_json_get_repeated_int32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS INT DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_int32_field $$
+CREATE FUNCTION pb_message_sort_repeated_int32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, repeated_index, value, field_count);
-	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_sort_indexes(pb_message_get_repeated_int32_field_as_json_array(message, field_number), 'integer'));
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_int32_field_count $$
-CREATE FUNCTION pb_wire_json_get_repeated_int32_field_count(wire_json JSON, field_number INT) RETURNS INT DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_int32_field $$
+CREATE FUNCTION pb_message_distinct_repeated_int32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, -1, value, field_count);
-	RETURN field_count;
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_int32_field_as_json_array(message, field_number)));
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_int64_field $$
-CREATE FUNCTION pb_wire_json_get_int64_field(wire_json JSON, field_number INT, default_value BIGINT) RETURNS BIGINT DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_int64_field $$
+CREATE FUNCTION pb_message_slice_repeated_int64_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, NULL, value, field_count);
-	IF field_count = 0 THEN
-		RETURN default_value;
-	END IF;
-	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_slice_indexes(pb_message_get_repeated_int64_field_count(message, field_number), start_index, end_index));
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_has_int64_field $$
-CREATE FUNCTION pb_wire_json_has_int64_field(wire_json JSON, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_int64_field $$
+CREATE FUNCTION pb_message_reverse_repeated_int64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, NULL, value, field_count);
-	RETURN field_count > 0;
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_reverse_indexes(pb_message_get_repeated_int64_field_count(message, field_number)));
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_int64_field_element $$
-CREATE FUNCTION pb_wire_json_get_repeated_int64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS BIGINT DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_int64_field $$
+CREATE FUNCTION pb_message_sort_repeated_int64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, repeated_index, value, field_count);
-	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_sort_indexes(pb_message_get_repeated_int64_field_as_json_array(message, field_number), 'integer'));
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_int64_field_count $$
-CREATE FUNCTION pb_wire_json_get_repeated_int64_field_count(wire_json JSON, field_number INT) RETURNS INT DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_int64_field $$
+CREATE FUNCTION pb_message_distinct_repeated_int64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	DECLARE value BIGINT UNSIGNED;
-	DECLARE field_count INT;
-	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, -1, value, field_count);
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_int64_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_uint32_field $$
+CREATE FUNCTION pb_message_slice_repeated_uint32_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_slice_indexes(pb_message_get_repeated_uint32_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_uint32_field $$
+CREATE FUNCTION pb_message_reverse_repeated_uint32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_reverse_indexes(pb_message_get_repeated_uint32_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_uint32_field $$
+CREATE FUNCTION pb_message_sort_repeated_uint32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_sort_indexes(pb_message_get_repeated_uint32_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_uint32_field $$
+CREATE FUNCTION pb_message_distinct_repeated_uint32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_uint32_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_uint64_field $$
+CREATE FUNCTION pb_message_slice_repeated_uint64_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_slice_indexes(pb_message_get_repeated_uint64_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_uint64_field $$
+CREATE FUNCTION pb_message_reverse_repeated_uint64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_reverse_indexes(pb_message_get_repeated_uint64_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_uint64_field $$
+CREATE FUNCTION pb_message_sort_repeated_uint64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_sort_indexes(pb_message_get_repeated_uint64_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_uint64_field $$
+CREATE FUNCTION pb_message_distinct_repeated_uint64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_uint64_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_sint32_field $$
+CREATE FUNCTION pb_message_slice_repeated_sint32_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_slice_indexes(pb_message_get_repeated_sint32_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_sint32_field $$
+CREATE FUNCTION pb_message_reverse_repeated_sint32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_reverse_indexes(pb_message_get_repeated_sint32_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_sint32_field $$
+CREATE FUNCTION pb_message_sort_repeated_sint32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_sort_indexes(pb_message_get_repeated_sint32_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_sint32_field $$
+CREATE FUNCTION pb_message_distinct_repeated_sint32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_sint32_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_sint64_field $$
+CREATE FUNCTION pb_message_slice_repeated_sint64_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_slice_indexes(pb_message_get_repeated_sint64_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_sint64_field $$
+CREATE FUNCTION pb_message_reverse_repeated_sint64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_reverse_indexes(pb_message_get_repeated_sint64_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_sint64_field $$
+CREATE FUNCTION pb_message_sort_repeated_sint64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_sort_indexes(pb_message_get_repeated_sint64_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_sint64_field $$
+CREATE FUNCTION pb_message_distinct_repeated_sint64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_sint64_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_enum_field $$
+CREATE FUNCTION pb_message_slice_repeated_enum_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_slice_indexes(pb_message_get_repeated_enum_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_enum_field $$
+CREATE FUNCTION pb_message_reverse_repeated_enum_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_reverse_indexes(pb_message_get_repeated_enum_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_enum_field $$
+CREATE FUNCTION pb_message_sort_repeated_enum_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_sort_indexes(pb_message_get_repeated_enum_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_enum_field $$
+CREATE FUNCTION pb_message_distinct_repeated_enum_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_enum_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_bool_field $$
+CREATE FUNCTION pb_message_slice_repeated_bool_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_slice_indexes(pb_message_get_repeated_bool_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_bool_field $$
+CREATE FUNCTION pb_message_reverse_repeated_bool_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_reverse_indexes(pb_message_get_repeated_bool_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_bool_field $$
+CREATE FUNCTION pb_message_distinct_repeated_bool_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 0, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_bool_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_fixed32_field $$
+CREATE FUNCTION pb_message_slice_repeated_fixed32_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_slice_indexes(pb_message_get_repeated_fixed32_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_fixed32_field $$
+CREATE FUNCTION pb_message_reverse_repeated_fixed32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_reverse_indexes(pb_message_get_repeated_fixed32_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_fixed32_field $$
+CREATE FUNCTION pb_message_sort_repeated_fixed32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_json_array_sort_indexes(pb_message_get_repeated_fixed32_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_fixed32_field $$
+CREATE FUNCTION pb_message_distinct_repeated_fixed32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_fixed32_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_sfixed32_field $$
+CREATE FUNCTION pb_message_slice_repeated_sfixed32_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_slice_indexes(pb_message_get_repeated_sfixed32_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_sfixed32_field $$
+CREATE FUNCTION pb_message_reverse_repeated_sfixed32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_reverse_indexes(pb_message_get_repeated_sfixed32_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_sfixed32_field $$
+CREATE FUNCTION pb_message_sort_repeated_sfixed32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_json_array_sort_indexes(pb_message_get_repeated_sfixed32_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_sfixed32_field $$
+CREATE FUNCTION pb_message_distinct_repeated_sfixed32_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_sfixed32_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_float_field $$
+CREATE FUNCTION pb_message_slice_repeated_float_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_slice_indexes(pb_message_get_repeated_float_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_float_field $$
+CREATE FUNCTION pb_message_reverse_repeated_float_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_reverse_indexes(pb_message_get_repeated_float_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_float_field $$
+CREATE FUNCTION pb_message_sort_repeated_float_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_json_array_sort_indexes(pb_message_get_repeated_float_field_as_json_array(message, field_number), 'double'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_float_field $$
+CREATE FUNCTION pb_message_distinct_repeated_float_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 5, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_float_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_fixed64_field $$
+CREATE FUNCTION pb_message_slice_repeated_fixed64_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_slice_indexes(pb_message_get_repeated_fixed64_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_fixed64_field $$
+CREATE FUNCTION pb_message_reverse_repeated_fixed64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_reverse_indexes(pb_message_get_repeated_fixed64_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_fixed64_field $$
+CREATE FUNCTION pb_message_sort_repeated_fixed64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_json_array_sort_indexes(pb_message_get_repeated_fixed64_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_fixed64_field $$
+CREATE FUNCTION pb_message_distinct_repeated_fixed64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_fixed64_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_sfixed64_field $$
+CREATE FUNCTION pb_message_slice_repeated_sfixed64_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_slice_indexes(pb_message_get_repeated_sfixed64_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_sfixed64_field $$
+CREATE FUNCTION pb_message_reverse_repeated_sfixed64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_reverse_indexes(pb_message_get_repeated_sfixed64_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_sfixed64_field $$
+CREATE FUNCTION pb_message_sort_repeated_sfixed64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_json_array_sort_indexes(pb_message_get_repeated_sfixed64_field_as_json_array(message, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_sfixed64_field $$
+CREATE FUNCTION pb_message_distinct_repeated_sfixed64_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_sfixed64_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_double_field $$
+CREATE FUNCTION pb_message_slice_repeated_double_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_slice_indexes(pb_message_get_repeated_double_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_double_field $$
+CREATE FUNCTION pb_message_reverse_repeated_double_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_reverse_indexes(pb_message_get_repeated_double_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_double_field $$
+CREATE FUNCTION pb_message_sort_repeated_double_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_json_array_sort_indexes(pb_message_get_repeated_double_field_as_json_array(message, field_number), 'double'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_double_field $$
+CREATE FUNCTION pb_message_distinct_repeated_double_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 1, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_double_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_bytes_field $$
+CREATE FUNCTION pb_message_slice_repeated_bytes_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_slice_indexes(pb_message_get_repeated_bytes_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_bytes_field $$
+CREATE FUNCTION pb_message_reverse_repeated_bytes_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_reverse_indexes(pb_message_get_repeated_bytes_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_bytes_field $$
+CREATE FUNCTION pb_message_distinct_repeated_bytes_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_bytes_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_string_field $$
+CREATE FUNCTION pb_message_slice_repeated_string_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_slice_indexes(pb_message_get_repeated_string_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_string_field $$
+CREATE FUNCTION pb_message_reverse_repeated_string_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_reverse_indexes(pb_message_get_repeated_string_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_sort_repeated_string_field $$
+CREATE FUNCTION pb_message_sort_repeated_string_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_json_array_sort_indexes(pb_message_get_repeated_string_field_as_json_array(message, field_number), 'string'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_string_field $$
+CREATE FUNCTION pb_message_distinct_repeated_string_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_string_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_slice_repeated_message_field $$
+CREATE FUNCTION pb_message_slice_repeated_message_field(message LONGBLOB, field_number INT, start_index INT, end_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_slice_indexes(pb_message_get_repeated_message_field_count(message, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_reverse_repeated_message_field $$
+CREATE FUNCTION pb_message_reverse_repeated_message_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_reverse_indexes(pb_message_get_repeated_message_field_count(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_distinct_repeated_message_field $$
+CREATE FUNCTION pb_message_distinct_repeated_message_field(message LONGBLOB, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_rearrange_repeated_field(message, field_number, 2, _pb_util_json_array_distinct_indexes(pb_message_get_repeated_message_field_as_json_array(message, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_int32_field $$
+CREATE FUNCTION pb_wire_json_get_int32_field(wire_json JSON, field_number INT, default_value INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_has_int32_field $$
+CREATE FUNCTION pb_wire_json_has_int32_field(wire_json JSON, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_int32_field_element $$
+CREATE FUNCTION pb_wire_json_get_repeated_int32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_int32_field_count $$
+CREATE FUNCTION pb_wire_json_get_repeated_int32_field_count(wire_json JSON, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_int64_field $$
+CREATE FUNCTION pb_wire_json_get_int64_field(wire_json JSON, field_number INT, default_value BIGINT) RETURNS BIGINT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_has_int64_field $$
+CREATE FUNCTION pb_wire_json_has_int64_field(wire_json JSON, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_int64_field_element $$
+CREATE FUNCTION pb_wire_json_get_repeated_int64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS BIGINT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_int64_field_count $$
+CREATE FUNCTION pb_wire_json_get_repeated_int64_field_count(wire_json JSON, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_wire_json_get_varint_field_as_uint64(wire_json, field_number, -1, value, field_count);
 	RETURN field_count;
 END $$
 
@@ -4936,19 +6966,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_int32_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_int32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_int32_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_int32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_int32_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_int32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_varint_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_int32_field $$
@@ -4990,19 +7020,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_int64_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_int64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_int64_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_int64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_int64_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_int64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_varint_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_int64_field $$
@@ -5044,19 +7074,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_uint32_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_uint32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT UNSIGNED, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_varint_field_element(wire_json, field_number, repeated_index, value, use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value, use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_uint32_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_uint32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT UNSIGNED) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_varint_field_element(wire_json, field_number, repeated_index, value);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_uint32_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_uint32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_varint_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_uint32_field $$
@@ -5098,19 +7128,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_uint64_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_uint64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT UNSIGNED, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_varint_field_element(wire_json, field_number, repeated_index, value, use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value, use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_uint64_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_uint64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT UNSIGNED) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_varint_field_element(wire_json, field_number, repeated_index, value);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_uint64_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_uint64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_varint_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_uint64_field $$
@@ -5152,19 +7182,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_sint32_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_sint32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_sint64_as_uint64(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_sint64_as_uint64(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_sint32_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_sint32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_sint64_as_uint64(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_sint64_as_uint64(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_sint32_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_sint32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_varint_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_sint32_field $$
@@ -5206,19 +7236,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_sint64_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_sint64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_sint64_as_uint64(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_sint64_as_uint64(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_sint64_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_sint64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_sint64_as_uint64(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_sint64_as_uint64(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_sint64_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_sint64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_varint_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_sint64_field $$
@@ -5260,19 +7290,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_enum_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_enum_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_enum_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_enum_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_varint_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_enum_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_enum_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_varint_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_enum_field $$
@@ -5314,19 +7344,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_bool_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_bool_field_element(wire_json JSON, field_number INT, repeated_index INT, value BOOLEAN, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_varint_field_element(wire_json, field_number, repeated_index, IF(value, 1, 0), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, IF(value, 1, 0), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_bool_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_bool_field_element(wire_json JSON, field_number INT, repeated_index INT, value BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_varint_field_element(wire_json, field_number, repeated_index, IF(value, 1, 0));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, IF(value, 1, 0)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_bool_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_bool_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_varint_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_varint_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_bool_field $$
@@ -5368,19 +7398,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_fixed32_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_fixed32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT UNSIGNED, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_i32_field_element(wire_json, field_number, repeated_index, value, use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value, use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_fixed32_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_fixed32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT UNSIGNED) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_i32_field_element(wire_json, field_number, repeated_index, value);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_fixed32_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_fixed32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_i32_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_fixed32_field $$
@@ -5422,19 +7452,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_sfixed32_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_sfixed32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_i32_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int32_as_uint32(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int32_as_uint32(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_sfixed32_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_sfixed32_field_element(wire_json JSON, field_number INT, repeated_index INT, value INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_i32_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int32_as_uint32(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int32_as_uint32(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_sfixed32_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_sfixed32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_i32_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_sfixed32_field $$
@@ -5476,19 +7506,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_float_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_float_field_element(wire_json JSON, field_number INT, repeated_index INT, value FLOAT, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_i32_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_float_as_uint32(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_float_as_uint32(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_float_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_float_field_element(wire_json JSON, field_number INT, repeated_index INT, value FLOAT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_i32_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_float_as_uint32(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_float_as_uint32(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_float_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_float_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_i32_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_i32_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_float_field $$
@@ -5530,19 +7560,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_fixed64_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_fixed64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT UNSIGNED, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_i64_field_element(wire_json, field_number, repeated_index, value, use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value, use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_fixed64_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_fixed64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT UNSIGNED) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_i64_field_element(wire_json, field_number, repeated_index, value);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_fixed64_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_fixed64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_i64_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_fixed64_field $$
@@ -5584,19 +7614,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_sfixed64_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_sfixed64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_i64_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_sfixed64_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_sfixed64_field_element(wire_json JSON, field_number INT, repeated_index INT, value BIGINT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_i64_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_int64_as_uint64(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_sfixed64_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_sfixed64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_i64_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_sfixed64_field $$
@@ -5638,19 +7668,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_double_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_double_field_element(wire_json JSON, field_number INT, repeated_index INT, value DOUBLE, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_i64_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_double_as_uint64(value), use_packed);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_double_as_uint64(value), use_packed), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_double_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_double_field_element(wire_json JSON, field_number INT, repeated_index INT, value DOUBLE) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_i64_field_element(wire_json, field_number, repeated_index, _pb_util_reinterpret_double_as_uint64(value));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, _pb_util_reinterpret_double_as_uint64(value)), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_double_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_double_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_i64_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_i64_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_double_field $$
@@ -5692,19 +7722,19 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_bytes_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_bytes_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_len_field_element(wire_json, field_number, repeated_index, value);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_bytes_field_element $$
 CREATE FUNCTION pb_wire_json_set_repeated_bytes_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_len_field_element(wire_json, field_number, repeated_index, value);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_bytes_field_element $$
 CREATE FUNCTION pb_wire_json_remove_repeated_bytes_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_len_field_element(wire_json, field_number, repeated_index);
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
 END $$
 
 DROP FUNCTION IF EXISTS pb_wire_json_clear_bytes_field $$
@@ -5746,91 +7776,1144 @@ END $$
 DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_string_field_element $$
 CREATE FUNCTION pb_wire_json_insert_repeated_string_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGTEXT) RETURNS JSON DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_len_field_element(wire_json, field_number, repeated_index, CONVERT(value USING binary));
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, CONVERT(value USING binary)), wire_json);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_string_field_element $$
+CREATE FUNCTION pb_wire_json_set_repeated_string_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGTEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, CONVERT(value USING binary)), wire_json);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_string_field_element $$
+CREATE FUNCTION pb_wire_json_remove_repeated_string_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_clear_string_field $$
+CREATE FUNCTION pb_wire_json_clear_string_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_clear_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_clear_repeated_string_field $$
+CREATE FUNCTION pb_wire_json_clear_repeated_string_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_clear_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_string_field $$
+CREATE FUNCTION pb_wire_json_set_repeated_string_field(wire_json JSON, field_number INT, value_array JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN pb_wire_json_add_all_repeated_string_field_elements(pb_wire_json_clear_repeated_string_field(wire_json, field_number), field_number, value_array);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_set_message_field $$
+CREATE FUNCTION pb_wire_json_set_message_field(wire_json JSON, field_number INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_set_len_field(wire_json, field_number, value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_add_repeated_message_field_element $$
+CREATE FUNCTION pb_wire_json_add_repeated_message_field_element(wire_json JSON, field_number INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_add_repeated_len_field_element(wire_json, field_number, value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_add_all_repeated_message_field_elements $$
+CREATE FUNCTION pb_wire_json_add_all_repeated_message_field_elements(wire_json JSON, field_number INT, value_array JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_add_all_repeated_message_field_elements(wire_json, field_number, value_array);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_message_field_element $$
+CREATE FUNCTION pb_wire_json_insert_repeated_message_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_insert_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value), wire_json);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_message_field_element $$
+CREATE FUNCTION pb_wire_json_set_repeated_message_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_set_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index, value), wire_json);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_message_field_element $$
+CREATE FUNCTION pb_wire_json_remove_repeated_message_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_to_same_version(_pb_wire_json_remove_repeated_len_field_element(pb_wire_json_to_v1(wire_json), field_number, repeated_index), wire_json);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_clear_message_field $$
+CREATE FUNCTION pb_wire_json_clear_message_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_clear_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_clear_repeated_message_field $$
+CREATE FUNCTION pb_wire_json_clear_repeated_message_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_clear_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_message_field $$
+CREATE FUNCTION pb_wire_json_set_repeated_message_field(wire_json JSON, field_number INT, value_array JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN pb_wire_json_add_all_repeated_message_field_elements(pb_wire_json_clear_repeated_message_field(wire_json, field_number), field_number, value_array);
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_int32_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_int32_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_slice_indexes(pb_wire_json_get_repeated_int32_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_int32_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_int32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_reverse_indexes(pb_wire_json_get_repeated_int32_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_int32_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_int32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_int32_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_int32_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_int32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_int32_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_int64_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_int64_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_slice_indexes(pb_wire_json_get_repeated_int64_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_int64_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_int64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_reverse_indexes(pb_wire_json_get_repeated_int64_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_int64_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_int64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_int64_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_int64_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_int64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_int64_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_uint32_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_uint32_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_slice_indexes(pb_wire_json_get_repeated_uint32_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_uint32_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_uint32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_reverse_indexes(pb_wire_json_get_repeated_uint32_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_uint32_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_uint32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_uint32_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_uint32_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_uint32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_uint32_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_uint64_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_uint64_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_slice_indexes(pb_wire_json_get_repeated_uint64_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_uint64_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_uint64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_reverse_indexes(pb_wire_json_get_repeated_uint64_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_uint64_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_uint64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_uint64_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_uint64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_sint32_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_sint32_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_slice_indexes(pb_wire_json_get_repeated_sint32_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_sint32_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_sint32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_reverse_indexes(pb_wire_json_get_repeated_sint32_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_sint32_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_sint32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_sint32_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_sint32_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_sint32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_sint32_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_sint64_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_sint64_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_slice_indexes(pb_wire_json_get_repeated_sint64_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_sint64_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_sint64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_reverse_indexes(pb_wire_json_get_repeated_sint64_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_sint64_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_sint64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_sint64_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_sint64_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_sint64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_sint64_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_enum_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_enum_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_slice_indexes(pb_wire_json_get_repeated_enum_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_enum_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_enum_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_reverse_indexes(pb_wire_json_get_repeated_enum_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_enum_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_enum_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_enum_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_enum_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_bool_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_bool_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_slice_indexes(pb_wire_json_get_repeated_bool_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_bool_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_bool_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_reverse_indexes(pb_wire_json_get_repeated_bool_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_bool_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_bool_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 0, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_bool_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_fixed32_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_fixed32_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_slice_indexes(pb_wire_json_get_repeated_fixed32_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_fixed32_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_fixed32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_reverse_indexes(pb_wire_json_get_repeated_fixed32_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_fixed32_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_fixed32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_fixed32_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_fixed32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_sfixed32_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_sfixed32_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_slice_indexes(pb_wire_json_get_repeated_sfixed32_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_sfixed32_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_sfixed32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_reverse_indexes(pb_wire_json_get_repeated_sfixed32_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_sfixed32_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_sfixed32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_sfixed32_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_sfixed32_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_sfixed32_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_sfixed32_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_float_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_float_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_slice_indexes(pb_wire_json_get_repeated_float_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_float_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_float_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_reverse_indexes(pb_wire_json_get_repeated_float_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_float_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_float_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_float_field_as_json_array(wire_json, field_number), 'double'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_float_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_float_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 5, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_float_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_fixed64_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_fixed64_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_slice_indexes(pb_wire_json_get_repeated_fixed64_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_fixed64_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_fixed64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_reverse_indexes(pb_wire_json_get_repeated_fixed64_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_fixed64_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_fixed64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_fixed64_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_fixed64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_sfixed64_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_sfixed64_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_slice_indexes(pb_wire_json_get_repeated_sfixed64_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_sfixed64_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_sfixed64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_reverse_indexes(pb_wire_json_get_repeated_sfixed64_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_sfixed64_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_sfixed64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_sfixed64_field_as_json_array(wire_json, field_number), 'integer'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_sfixed64_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_sfixed64_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_sfixed64_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_double_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_double_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_slice_indexes(pb_wire_json_get_repeated_double_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_double_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_double_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_reverse_indexes(pb_wire_json_get_repeated_double_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_double_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_double_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_double_field_as_json_array(wire_json, field_number), 'double'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_double_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_double_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 1, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_double_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_bytes_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_bytes_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_slice_indexes(pb_wire_json_get_repeated_bytes_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_bytes_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_bytes_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_reverse_indexes(pb_wire_json_get_repeated_bytes_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_bytes_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_bytes_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_string_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_string_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_slice_indexes(pb_wire_json_get_repeated_string_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_string_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_string_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_reverse_indexes(pb_wire_json_get_repeated_string_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_sort_repeated_string_field $$
+CREATE FUNCTION pb_wire_json_sort_repeated_string_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_json_array_sort_indexes(pb_wire_json_get_repeated_string_field_as_json_array(wire_json, field_number), 'string'));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_string_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_string_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_string_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_slice_repeated_message_field $$
+CREATE FUNCTION pb_wire_json_slice_repeated_message_field(wire_json JSON, field_number INT, start_index INT, end_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_slice_indexes(pb_wire_json_get_repeated_message_field_count(wire_json, field_number), start_index, end_index));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_reverse_repeated_message_field $$
+CREATE FUNCTION pb_wire_json_reverse_repeated_message_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_reverse_indexes(pb_wire_json_get_repeated_message_field_count(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_distinct_repeated_message_field $$
+CREATE FUNCTION pb_wire_json_distinct_repeated_message_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN _pb_wire_json_rearrange_repeated_field(wire_json, field_number, 2, _pb_util_json_array_distinct_indexes(pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number)));
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_int32_field $$
+CREATE FUNCTION pb_message_index_get_int32_field(message_index LONGBLOB, field_number INT, default_value INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_int32_field $$
+CREATE FUNCTION pb_message_index_has_int32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_int32_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_int32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_int32_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_int32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_int64_field $$
+CREATE FUNCTION pb_message_index_get_int64_field(message_index LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_int64_field $$
+CREATE FUNCTION pb_message_index_has_int64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_int64_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_int64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_int64_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_int64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_uint32_field $$
+CREATE FUNCTION pb_message_index_get_uint32_field(message_index LONGBLOB, field_number INT, default_value INT UNSIGNED) RETURNS INT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN value;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_uint32_field $$
+CREATE FUNCTION pb_message_index_has_uint32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_uint32_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_uint32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN value;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_uint32_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_uint32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_uint64_field $$
+CREATE FUNCTION pb_message_index_get_uint64_field(message_index LONGBLOB, field_number INT, default_value BIGINT UNSIGNED) RETURNS BIGINT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN value;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_uint64_field $$
+CREATE FUNCTION pb_message_index_has_uint64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_uint64_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_uint64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN value;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_uint64_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_uint64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_sint32_field $$
+CREATE FUNCTION pb_message_index_get_sint32_field(message_index LONGBLOB, field_number INT, default_value INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_sint64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_sint32_field $$
+CREATE FUNCTION pb_message_index_has_sint32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sint32_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_sint32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_sint64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sint32_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_sint32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_sint64_field $$
+CREATE FUNCTION pb_message_index_get_sint64_field(message_index LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_sint64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_sint64_field $$
+CREATE FUNCTION pb_message_index_has_sint64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sint64_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_sint64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_sint64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sint64_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_sint64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_enum_field $$
+CREATE FUNCTION pb_message_index_get_enum_field(message_index LONGBLOB, field_number INT, default_value INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_enum_field $$
+CREATE FUNCTION pb_message_index_has_enum_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_enum_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_enum_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_enum_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_enum_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_bool_field $$
+CREATE FUNCTION pb_message_index_get_bool_field(message_index LONGBLOB, field_number INT, default_value BOOLEAN) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN value <> 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_bool_field $$
+CREATE FUNCTION pb_message_index_has_bool_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_bool_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_bool_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN value <> 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_bool_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_bool_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_varint_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_fixed32_field $$
+CREATE FUNCTION pb_message_index_get_fixed32_field(message_index LONGBLOB, field_number INT, default_value INT UNSIGNED) RETURNS INT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN value;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_fixed32_field $$
+CREATE FUNCTION pb_message_index_has_fixed32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_fixed32_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_fixed32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, repeated_index, value, field_count);
+	RETURN value;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_fixed32_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_fixed32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_sfixed32_field $$
+CREATE FUNCTION pb_message_index_get_sfixed32_field(message_index LONGBLOB, field_number INT, default_value INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint32_as_int32(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_sfixed32_field $$
+CREATE FUNCTION pb_message_index_has_sfixed32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sfixed32_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_sfixed32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint32_as_int32(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sfixed32_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_sfixed32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_float_field $$
+CREATE FUNCTION pb_message_index_get_float_field(message_index LONGBLOB, field_number INT, default_value FLOAT) RETURNS FLOAT DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint32_as_float(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_float_field $$
+CREATE FUNCTION pb_message_index_has_float_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_float_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_float_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS FLOAT DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint32_as_float(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_float_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_float_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value INT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i32_field_as_uint32(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_fixed64_field $$
+CREATE FUNCTION pb_message_index_get_fixed64_field(message_index LONGBLOB, field_number INT, default_value BIGINT UNSIGNED) RETURNS BIGINT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN value;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_fixed64_field $$
+CREATE FUNCTION pb_message_index_has_fixed64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_fixed64_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_fixed64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN value;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_fixed64_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_fixed64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_sfixed64_field $$
+CREATE FUNCTION pb_message_index_get_sfixed64_field(message_index LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_sfixed64_field $$
+CREATE FUNCTION pb_message_index_has_sfixed64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sfixed64_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_sfixed64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_int64(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sfixed64_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_sfixed64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_double_field $$
+CREATE FUNCTION pb_message_index_get_double_field(message_index LONGBLOB, field_number INT, default_value DOUBLE) RETURNS DOUBLE DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN _pb_util_reinterpret_uint64_as_double(value);
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_has_double_field $$
+CREATE FUNCTION pb_message_index_has_double_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_string_field_element $$
-CREATE FUNCTION pb_wire_json_set_repeated_string_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGTEXT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_double_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_double_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS DOUBLE DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_len_field_element(wire_json, field_number, repeated_index, CONVERT(value USING binary));
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, repeated_index, value, field_count);
+	RETURN _pb_util_reinterpret_uint64_as_double(value);
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_string_field_element $$
-CREATE FUNCTION pb_wire_json_remove_repeated_string_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_double_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_double_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_len_field_element(wire_json, field_number, repeated_index);
+	DECLARE value BIGINT UNSIGNED;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_i64_field_as_uint64(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_clear_string_field $$
-CREATE FUNCTION pb_wire_json_clear_string_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_bytes_field $$
+CREATE FUNCTION pb_message_index_get_bytes_field(message_index LONGBLOB, field_number INT, default_value LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_clear_field(wire_json, field_number);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN value;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_clear_repeated_string_field $$
-CREATE FUNCTION pb_wire_json_clear_repeated_string_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_has_bytes_field $$
+CREATE FUNCTION pb_message_index_has_bytes_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_clear_field(wire_json, field_number);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_string_field $$
-CREATE FUNCTION pb_wire_json_set_repeated_string_field(wire_json JSON, field_number INT, value_array JSON) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_bytes_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_bytes_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	RETURN pb_wire_json_add_all_repeated_string_field_elements(pb_wire_json_clear_repeated_string_field(wire_json, field_number), field_number, value_array);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, repeated_index, value, field_count);
+	RETURN value;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_set_message_field $$
-CREATE FUNCTION pb_wire_json_set_message_field(wire_json JSON, field_number INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_bytes_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_bytes_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_len_field(wire_json, field_number, value);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_add_repeated_message_field_element $$
-CREATE FUNCTION pb_wire_json_add_repeated_message_field_element(wire_json JSON, field_number INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_string_field $$
+CREATE FUNCTION pb_message_index_get_string_field(message_index LONGBLOB, field_number INT, default_value LONGTEXT) RETURNS LONGTEXT DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_add_repeated_len_field_element(wire_json, field_number, value);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN CONVERT(value USING utf8mb4);
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_add_all_repeated_message_field_elements $$
-CREATE FUNCTION pb_wire_json_add_all_repeated_message_field_elements(wire_json JSON, field_number INT, value_array JSON) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_has_string_field $$
+CREATE FUNCTION pb_message_index_has_string_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_add_all_repeated_message_field_elements(wire_json, field_number, value_array);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_insert_repeated_message_field_element $$
-CREATE FUNCTION pb_wire_json_insert_repeated_message_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_string_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_string_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGTEXT DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_insert_repeated_len_field_element(wire_json, field_number, repeated_index, value);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, repeated_index, value, field_count);
+	RETURN CONVERT(value USING utf8mb4);
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_message_field_element $$
-CREATE FUNCTION pb_wire_json_set_repeated_message_field_element(wire_json JSON, field_number INT, repeated_index INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_string_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_string_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_set_repeated_len_field_element(wire_json, field_number, repeated_index, value);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_remove_repeated_message_field_element $$
-CREATE FUNCTION pb_wire_json_remove_repeated_message_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_message_field $$
+CREATE FUNCTION pb_message_index_get_message_field(message_index LONGBLOB, field_number INT, default_value LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_remove_repeated_len_field_element(wire_json, field_number, repeated_index);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, NULL, value, field_count);
+	IF field_count = 0 THEN
+		RETURN default_value;
+	END IF;
+	RETURN value;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_clear_message_field $$
-CREATE FUNCTION pb_wire_json_clear_message_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_has_message_field $$
+CREATE FUNCTION pb_message_index_has_message_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_clear_field(wire_json, field_number);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, NULL, value, field_count);
+	RETURN field_count > 0;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_clear_repeated_message_field $$
-CREATE FUNCTION pb_wire_json_clear_repeated_message_field(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_message_field_element $$
+CREATE FUNCTION pb_message_index_get_repeated_message_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGBLOB DETERMINISTIC
 BEGIN
-	RETURN _pb_wire_json_clear_field(wire_json, field_number);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, repeated_index, value, field_count);
+	RETURN value;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_set_repeated_message_field $$
-CREATE FUNCTION pb_wire_json_set_repeated_message_field(wire_json JSON, field_number INT, value_array JSON) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_message_field_count $$
+CREATE FUNCTION pb_message_index_get_repeated_message_field_count(message_index LONGBLOB, field_number INT) RETURNS INT DETERMINISTIC
 BEGIN
-	RETURN pb_wire_json_add_all_repeated_message_field_elements(pb_wire_json_clear_repeated_message_field(wire_json, field_number), field_number, value_array);
+	DECLARE value LONGBLOB;
+	DECLARE field_count INT;
+	CALL _pb_message_index_get_len_type_field(message_index, field_number, -1, value, field_count);
+	RETURN field_count;
 END $$
 
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_int32_field_as_json_array $$
@@ -5839,6 +8922,7 @@ BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -5847,7 +8931,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -5861,13 +8945,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_int64(uint_value)));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_int64(uint_value)));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_int32_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_int32_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -5886,39 +8970,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_int32_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_int32_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_int64(uint_value)));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_int64(uint_value)));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_int32_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_int32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -5931,12 +9028,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_int32_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_int32_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_int64(uint_value)));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_int64(uint_value)));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_int32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_int32_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_int32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_int32_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_uint32_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_uint32_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -5945,7 +9105,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -5959,13 +9119,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_uint64_as_uint32(uint_value));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_uint64_as_uint32(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_uint32_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_uint32_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -5984,39 +9144,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_uint32_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_uint32_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_uint64_as_uint32(uint_value));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_uint64_as_uint32(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_uint32_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_uint32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6029,12 +9202,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_uint32_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_uint32_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_uint64_as_uint32(uint_value));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_uint64_as_uint32(uint_value));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_uint32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_uint32_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_uint32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_uint32_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_int64_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_int64_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6043,7 +9279,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6057,13 +9293,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_int64_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_int64_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -6082,39 +9318,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_int64_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_int64_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_int64_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_int64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6127,12 +9376,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_int64_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_int64_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_int64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_int64_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_int64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_int64_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_int64_field_as_json_string_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_int64_field_as_json_string_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6141,7 +9453,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6155,13 +9467,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_int64_field_as_json_string_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_int64_field_as_json_string_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -6180,39 +9492,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_int64_field_as_json_string_arr
 CREATE PROCEDURE _pb_message_get_repeated_int64_field_as_json_string_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_int64_field_as_json_string_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_int64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6225,12 +9550,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_int64_field_as_json_string_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_int64_field_as_json_string_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_int64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_int64_field_as_json_string_array $$
+CREATE FUNCTION pb_message_index_get_repeated_int64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_int64_field_as_json_string_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_uint64_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_uint64_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6239,7 +9627,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6249,77 +9637,152 @@ BEGIN
 
 		CASE wire_type
 		WHEN 0 THEN
-			SET uint_value = CAST(JSON_EXTRACT(wire_element, '$.v') AS UNSIGNED);
+			SET uint_value = CAST(JSON_EXTRACT(wire_element, '$.v') AS UNSIGNED);
+			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
+		WHEN 2 THEN -- LEN
+			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_uint64_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
+		END CASE;
+
+		SET wire_element_index = wire_element_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_uint64_field_as_json_array $$
+CREATE FUNCTION pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json, field_number, result);
+	RETURN result;
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_message_get_repeated_uint64_field_as_json_array $$
+CREATE PROCEDURE _pb_message_get_repeated_uint64_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE tag BIGINT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
+		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
+		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
+
+		IF current_field_number != field_number THEN
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
+			ITERATE l1;
+		END IF;
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
-		WHEN 2 THEN -- LEN
-			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+		WHEN 2 THEN
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_uint64_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_uint64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
-
-		SET wire_element_index = wire_element_index + 1;
 	END WHILE;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_uint64_field_as_json_array $$
-CREATE FUNCTION pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_get_repeated_uint64_field_as_json_array $$
+CREATE FUNCTION pb_message_get_repeated_uint64_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json, field_number, result);
+	CALL _pb_message_get_repeated_uint64_field_as_json_array(message, field_number, result);
 	RETURN result;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_message_get_repeated_uint64_field_as_json_array $$
-CREATE PROCEDURE _pb_message_get_repeated_uint64_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_uint64_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_uint64_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
-	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
-	DECLARE current_field_number INT;
-	DECLARE current_wire_type INT;
 
-	SET tail = message;
-	SET result = JSON_ARRAY();
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
-		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
-		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
 
-		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
-			ITERATE l1;
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
 		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_uint64_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_uint64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
+
+		SET entry_index = entry_index + 1;
 	END WHILE;
 END $$
 
-DROP FUNCTION IF EXISTS pb_message_get_repeated_uint64_field_as_json_array $$
-CREATE FUNCTION pb_message_get_repeated_uint64_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_uint64_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_uint64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_message_get_repeated_uint64_field_as_json_array(message, field_number, result);
+	CALL _pb_message_index_get_repeated_uint64_field_as_json_array(message_index, field_number, result);
 	RETURN result;
 END $$
 
@@ -6329,6 +9792,7 @@ BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6337,7 +9801,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6351,13 +9815,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_uint64_field_as_json_string_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_uint64_field_as_json_string_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -6376,39 +9840,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_uint64_field_as_json_string_ar
 CREATE PROCEDURE _pb_message_get_repeated_uint64_field_as_json_string_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_uint64_field_as_json_string_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_uint64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6421,12 +9898,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_uint64_field_as_json_string_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_uint64_field_as_json_string_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_uint64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_uint64_field_as_json_string_array $$
+CREATE FUNCTION pb_message_index_get_repeated_uint64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_uint64_field_as_json_string_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_sint32_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_sint32_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6435,7 +9975,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6449,13 +9989,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_sint64(uint_value)));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_sint64(uint_value)));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_sint32_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_sint32_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -6474,39 +10014,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_sint32_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_sint32_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_sint64(uint_value)));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_sint64(uint_value)));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_sint32_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_sint32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6519,12 +10072,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_sint32_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_sint32_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_sint64(uint_value)));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_cast_int64_as_int32(_pb_util_reinterpret_uint64_as_sint64(uint_value)));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_sint32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sint32_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_sint32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_sint32_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_sint64_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_sint64_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6533,7 +10149,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6547,13 +10163,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_sint64(uint_value));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_sint64(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_sint64_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_sint64_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -6572,39 +10188,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_sint64_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_sint64_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_sint64(uint_value));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_sint64(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_sint64_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_sint64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6617,12 +10246,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_sint64_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_sint64_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_sint64(uint_value));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_sint64(uint_value));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_sint64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sint64_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_sint64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_sint64_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_sint64_field_as_json_string_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_sint64_field_as_json_string_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6631,7 +10323,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6645,13 +10337,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_sint64(uint_value) AS CHAR));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_sint64(uint_value) AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_sint64_field_as_json_string_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_sint64_field_as_json_string_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -6670,39 +10362,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_sint64_field_as_json_string_ar
 CREATE PROCEDURE _pb_message_get_repeated_sint64_field_as_json_string_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_sint64(uint_value) AS CHAR));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_sint64(uint_value) AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_sint64_field_as_json_string_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_sint64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6715,12 +10420,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_sint64_field_as_json_string_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_sint64_field_as_json_string_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_sint64(uint_value) AS CHAR));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_sint64(uint_value) AS CHAR));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_sint64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sint64_field_as_json_string_array $$
+CREATE FUNCTION pb_message_index_get_repeated_sint64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_sint64_field_as_json_string_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_enum_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_enum_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6729,7 +10497,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6743,13 +10511,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_enum_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_enum_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -6768,39 +10536,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_enum_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_enum_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_enum_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_enum_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6813,12 +10594,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_enum_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_enum_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_enum_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_enum_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_enum_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_enum_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_bool_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_bool_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6827,7 +10671,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6841,13 +10685,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', uint_value <> 0);
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', uint_value <> 0);
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_bool_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_bool_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -6866,39 +10710,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_bool_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_bool_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 0 THEN
-			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', uint_value <> 0);
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_varint_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', uint_value <> 0);
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_bool_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_bool_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -6911,12 +10768,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_bool_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_bool_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 0 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_varint_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', uint_value <> 0);
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', uint_value <> 0);
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_bool_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_bool_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_bool_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_bool_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_fixed32_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_fixed32_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -6925,7 +10845,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -6939,73 +10859,148 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i32_as_uint32(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_fixed32_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
+		END CASE;
+
+		SET wire_element_index = wire_element_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_fixed32_field_as_json_array $$
+CREATE FUNCTION pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number, result);
+	RETURN result;
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_message_get_repeated_fixed32_field_as_json_array $$
+CREATE PROCEDURE _pb_message_get_repeated_fixed32_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE tag BIGINT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
+		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
+		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
+
+		IF current_field_number != field_number THEN
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
+			ITERATE l1;
+		END IF;
+
+		CASE current_wire_type
+		WHEN 5 THEN
+			CALL _pb_wire_read_i32_as_uint32(SUBSTRING(message, offset + 1, 4), offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
+		WHEN 2 THEN
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_fixed32_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_fixed32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
-
-		SET wire_element_index = wire_element_index + 1;
 	END WHILE;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_fixed32_field_as_json_array $$
-CREATE FUNCTION pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_get_repeated_fixed32_field_as_json_array $$
+CREATE FUNCTION pb_message_get_repeated_fixed32_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number, result);
+	CALL _pb_message_get_repeated_fixed32_field_as_json_array(message, field_number, result);
 	RETURN result;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_message_get_repeated_fixed32_field_as_json_array $$
-CREATE PROCEDURE _pb_message_get_repeated_fixed32_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_fixed32_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_fixed32_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
-	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
-	DECLARE current_field_number INT;
-	DECLARE current_wire_type INT;
 
-	SET tail = message;
-	SET result = JSON_ARRAY();
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
-		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
-		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
 
-		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
-			ITERATE l1;
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
 		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
 
 		CASE current_wire_type
 		WHEN 5 THEN
-			CALL _pb_wire_read_i32_as_uint32(tail, uint_value, tail);
+			SET packed_offset = 0;
+			CALL _pb_wire_read_i32_as_uint32(bytes_value, packed_offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i32_as_uint32(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_fixed32_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_fixed32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
+
+		SET entry_index = entry_index + 1;
 	END WHILE;
 END $$
 
-DROP FUNCTION IF EXISTS pb_message_get_repeated_fixed32_field_as_json_array $$
-CREATE FUNCTION pb_message_get_repeated_fixed32_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_fixed32_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_fixed32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_message_get_repeated_fixed32_field_as_json_array(message, field_number, result);
+	CALL _pb_message_index_get_repeated_fixed32_field_as_json_array(message_index, field_number, result);
 	RETURN result;
 END $$
 
@@ -7015,6 +11010,7 @@ BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7023,7 +11019,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7037,13 +11033,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_int32(uint_value));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i32_as_uint32(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_int32(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_sfixed32_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_sfixed32_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7062,39 +11058,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_sfixed32_field_as_json_array $
 CREATE PROCEDURE _pb_message_get_repeated_sfixed32_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 5 THEN
-			CALL _pb_wire_read_i32_as_uint32(tail, uint_value, tail);
+			CALL _pb_wire_read_i32_as_uint32(SUBSTRING(message, offset + 1, 4), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_int32(uint_value));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i32_as_uint32(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_int32(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_sfixed32_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_sfixed32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7107,12 +11116,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_sfixed32_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_sfixed32_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 5 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_i32_as_uint32(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_int32(uint_value));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_int32(uint_value));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_sfixed32_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sfixed32_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_sfixed32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_sfixed32_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_float_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_float_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7121,7 +11193,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7135,13 +11207,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_float(uint_value));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i32_as_uint32(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_float(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_float_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_float_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7160,39 +11232,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_float_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_float_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 5 THEN
-			CALL _pb_wire_read_i32_as_uint32(tail, uint_value, tail);
+			CALL _pb_wire_read_i32_as_uint32(SUBSTRING(message, offset + 1, 4), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_float(uint_value));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i32_as_uint32(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_float(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_float_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_float_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7205,12 +11290,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_float_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_float_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 5 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_i32_as_uint32(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_float(uint_value));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_i32_as_uint32(SUBSTRING(bytes_value, packed_offset + 1, 4), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint32_as_float(uint_value));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_float_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_float_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_float_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_float_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_fixed64_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_fixed64_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7219,7 +11367,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7233,13 +11381,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_fixed64_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_fixed64_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7258,39 +11406,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_fixed64_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_fixed64_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 1 THEN
-			CALL _pb_wire_read_i64_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_i64_as_uint64(SUBSTRING(message, offset + 1, 8), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_fixed64_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_fixed64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7303,12 +11464,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_fixed64_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_fixed64_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 1 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_i64_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', uint_value);
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_fixed64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_fixed64_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_fixed64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_fixed64_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_fixed64_field_as_json_string_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_fixed64_field_as_json_string_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7317,7 +11541,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7331,13 +11555,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_fixed64_field_as_json_string_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_fixed64_field_as_json_string_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7356,39 +11580,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_fixed64_field_as_json_string_a
 CREATE PROCEDURE _pb_message_get_repeated_fixed64_field_as_json_string_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 1 THEN
-			CALL _pb_wire_read_i64_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_i64_as_uint64(SUBSTRING(message, offset + 1, 8), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_fixed64_field_as_json_string_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_fixed64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7401,12 +11638,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_fixed64_field_as_json_string_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_fixed64_field_as_json_string_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 1 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_i64_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', CAST(uint_value AS CHAR));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_fixed64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_fixed64_field_as_json_string_array $$
+CREATE FUNCTION pb_message_index_get_repeated_fixed64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_fixed64_field_as_json_string_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_sfixed64_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_sfixed64_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7415,7 +11715,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7429,13 +11729,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_sfixed64_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_sfixed64_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7454,39 +11754,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_sfixed64_field_as_json_array $
 CREATE PROCEDURE _pb_message_get_repeated_sfixed64_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 1 THEN
-			CALL _pb_wire_read_i64_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_i64_as_uint64(SUBSTRING(message, offset + 1, 8), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_sfixed64_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_sfixed64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7499,12 +11812,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_sfixed64_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_sfixed64_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 1 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_i64_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_int64(uint_value));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_sfixed64_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sfixed64_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_sfixed64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_sfixed64_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_sfixed64_field_as_json_string_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_sfixed64_field_as_json_string_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7513,7 +11889,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7527,13 +11903,13 @@ BEGIN
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
 		WHEN 2 THEN -- LEN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_sfixed64_field_as_json_string_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_sfixed64_field_as_json_string_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7552,39 +11928,52 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_sfixed64_field_as_json_string_
 CREATE PROCEDURE _pb_message_get_repeated_sfixed64_field_as_json_string_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 1 THEN
-			CALL _pb_wire_read_i64_as_uint64(tail, uint_value, tail);
+			CALL _pb_wire_read_i64_as_uint64(SUBSTRING(message, offset + 1, 8), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_sfixed64_field_as_json_string_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_sfixed64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7597,12 +11986,75 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_sfixed64_field_as_json_string_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_sfixed64_field_as_json_string_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 1 THEN
+			SET packed_offset = 0;
+			CALL _pb_wire_read_i64_as_uint64(bytes_value, packed_offset, uint_value);
+			SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
+		WHEN 2 THEN
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', CAST(_pb_util_reinterpret_uint64_as_int64(uint_value) AS CHAR));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_sfixed64_field_as_json_string_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_sfixed64_field_as_json_string_array $$
+CREATE FUNCTION pb_message_index_get_repeated_sfixed64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_sfixed64_field_as_json_string_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_double_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_double_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7611,7 +12063,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7621,77 +12073,152 @@ BEGIN
 
 		CASE wire_type
 		WHEN 1 THEN
-			SET uint_value = CAST(JSON_EXTRACT(wire_element, '$.v') AS UNSIGNED);
+			SET uint_value = CAST(JSON_EXTRACT(wire_element, '$.v') AS UNSIGNED);
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_double(uint_value));
+		WHEN 2 THEN -- LEN
+			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
+				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_double(uint_value));
+			END WHILE;
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_double_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
+		END CASE;
+
+		SET wire_element_index = wire_element_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_double_field_as_json_array $$
+CREATE FUNCTION pb_wire_json_get_repeated_double_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_wire_json_get_repeated_double_field_as_json_array(wire_json, field_number, result);
+	RETURN result;
+END $$
+
+DROP PROCEDURE IF EXISTS _pb_message_get_repeated_double_field_as_json_array $$
+CREATE PROCEDURE _pb_message_get_repeated_double_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE tag BIGINT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
+		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
+		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
+
+		IF current_field_number != field_number THEN
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
+			ITERATE l1;
+		END IF;
+
+		CASE current_wire_type
+		WHEN 1 THEN
+			CALL _pb_wire_read_i64_as_uint64(SUBSTRING(message, offset + 1, 8), offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_double(uint_value));
-		WHEN 2 THEN -- LEN
-			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+		WHEN 2 THEN
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
+			SET packed_offset = 0;
+			WHILE packed_offset < LENGTH(bytes_value) DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_double(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_double_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_double_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
-
-		SET wire_element_index = wire_element_index + 1;
 	END WHILE;
 END $$
 
-DROP FUNCTION IF EXISTS pb_wire_json_get_repeated_double_field_as_json_array $$
-CREATE FUNCTION pb_wire_json_get_repeated_double_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_get_repeated_double_field_as_json_array $$
+CREATE FUNCTION pb_message_get_repeated_double_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_wire_json_get_repeated_double_field_as_json_array(wire_json, field_number, result);
+	CALL _pb_message_get_repeated_double_field_as_json_array(message, field_number, result);
 	RETURN result;
 END $$
 
-DROP PROCEDURE IF EXISTS _pb_message_get_repeated_double_field_as_json_array $$
-CREATE PROCEDURE _pb_message_get_repeated_double_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_double_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_double_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
-	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
-	DECLARE current_field_number INT;
-	DECLARE current_wire_type INT;
 
-	SET tail = message;
-	SET result = JSON_ARRAY();
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
-		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
-		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
 
-		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
-			ITERATE l1;
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
 		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
 
 		CASE current_wire_type
 		WHEN 1 THEN
-			CALL _pb_wire_read_i64_as_uint64(tail, uint_value, tail);
+			SET packed_offset = 0;
+			CALL _pb_wire_read_i64_as_uint64(bytes_value, packed_offset, uint_value);
 			SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_double(uint_value));
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-			WHILE LENGTH(bytes_value) <> 0 DO
-				CALL _pb_wire_read_i64_as_uint64(bytes_value, uint_value, bytes_value);
+			SET packed_offset = 0;
+			WHILE packed_offset < value_length DO
+				CALL _pb_wire_read_i64_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 8), packed_offset, uint_value);
 				SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_reinterpret_uint64_as_double(uint_value));
 			END WHILE;
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_double_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_double_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
+
+		SET entry_index = entry_index + 1;
 	END WHILE;
 END $$
 
-DROP FUNCTION IF EXISTS pb_message_get_repeated_double_field_as_json_array $$
-CREATE FUNCTION pb_message_get_repeated_double_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_double_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_double_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_message_get_repeated_double_field_as_json_array(message, field_number, result);
+	CALL _pb_message_index_get_repeated_double_field_as_json_array(message_index, field_number, result);
 	RETURN result;
 END $$
 
@@ -7701,6 +12228,7 @@ BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7709,7 +12237,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7722,8 +12250,7 @@ BEGIN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
 			SET result = JSON_ARRAY_APPEND(result, '$', TO_BASE64(bytes_value));
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_bytes_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_bytes_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7742,33 +12269,45 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_bytes_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_bytes_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
 			SET result = JSON_ARRAY_APPEND(result, '$', TO_BASE64(bytes_value));
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_bytes_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_bytes_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7781,12 +12320,67 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_bytes_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_bytes_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 2 THEN
+			SET result = JSON_ARRAY_APPEND(result, '$', TO_BASE64(bytes_value));
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_bytes_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_bytes_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_bytes_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_bytes_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_string_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_string_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7795,7 +12389,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7808,8 +12402,7 @@ BEGIN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
 			SET result = JSON_ARRAY_APPEND(result, '$', CONVERT(bytes_value USING utf8mb4));
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_string_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_string_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7828,33 +12421,45 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_string_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_string_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
 			SET result = JSON_ARRAY_APPEND(result, '$', CONVERT(bytes_value USING utf8mb4));
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_string_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_string_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7867,12 +12472,67 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_string_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_string_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 2 THEN
+			SET result = JSON_ARRAY_APPEND(result, '$', CONVERT(bytes_value USING utf8mb4));
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_string_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_string_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_string_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_string_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_repeated_message_field_as_json_array $$
 CREATE PROCEDURE _pb_wire_json_get_repeated_message_field_as_json_array(IN wire_json JSON, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE message_text TEXT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
 	DECLARE wire_type INT;
 	DECLARE wire_elements JSON;
 	DECLARE wire_element JSON;
@@ -7881,7 +12541,7 @@ BEGIN
 
 	SET result = JSON_ARRAY();
 
-	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 	SET wire_element_index = 0;
 	SET wire_element_count = JSON_LENGTH(wire_elements);
 
@@ -7894,8 +12554,7 @@ BEGIN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
 			SET result = JSON_ARRAY_APPEND(result, '$', TO_BASE64(bytes_value));
 		ELSE
-			SET message_text = CONCAT('_pb_wire_json_get_repeated_message_field_as_json_array: unexpected wire_type (', wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_message_field_as_json_array', CONCAT('unexpected wire_type (', wire_type, ')'));
 		END CASE;
 
 		SET wire_element_index = wire_element_index + 1;
@@ -7914,33 +12573,45 @@ DROP PROCEDURE IF EXISTS _pb_message_get_repeated_message_field_as_json_array $$
 CREATE PROCEDURE _pb_message_get_repeated_message_field_as_json_array(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 BEGIN
 	DECLARE tag BIGINT;
-	DECLARE tail LONGBLOB;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
 	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE message_text TEXT;
 	DECLARE current_field_number INT;
 	DECLARE current_wire_type INT;
 
-	SET tail = message;
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
 	SET result = JSON_ARRAY();
 
-	l1: WHILE LENGTH(tail) <> 0 DO
-		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+	l1: WHILE offset < message_length DO
+		SET tag = NULL;
+		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 
 		IF current_field_number != field_number THEN
-			CALL _pb_wire_skip(tail, current_wire_type, tail);
+			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 			ITERATE l1;
 		END IF;
 
 		CASE current_wire_type
 		WHEN 2 THEN
-			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
+			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+			SET offset = offset + value_length;
 			SET result = JSON_ARRAY_APPEND(result, '$', TO_BASE64(bytes_value));
 		ELSE
-			SET message_text = CONCAT('_pb_message_get_repeated_message_field_as_json_array: unexpected wire_type (', current_wire_type, ')');
-			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_message_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		END CASE;
 	END WHILE;
 END $$
@@ -7953,6 +12624,60 @@ BEGIN
 	RETURN result;
 END $$
 
+DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_message_field_as_json_array $$
+CREATE PROCEDURE _pb_message_index_get_repeated_message_field_as_json_array(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+BEGIN
+	DECLARE entry_index INT;
+	DECLARE entry_count INT;
+	DECLARE message_start BIGINT;
+	DECLARE current_field_number INT;
+	DECLARE current_wire_type INT;
+	DECLARE value_offset BIGINT;
+	DECLARE value_length BIGINT;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset and field number of the failing element to decoder errors
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET result = JSON_ARRAY();
+	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+	SET message_start = 5 + entry_count * 13;
+
+	l1: WHILE entry_index < entry_count DO
+		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		IF current_field_number <> field_number THEN
+			LEAVE l1;
+		END IF;
+		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+
+		CASE current_wire_type
+		WHEN 2 THEN
+			SET result = JSON_ARRAY_APPEND(result, '$', TO_BASE64(bytes_value));
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_message_field_as_json_array', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		END CASE;
+
+		SET entry_index = entry_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_index_get_repeated_message_field_as_json_array $$
+CREATE FUNCTION pb_message_index_get_repeated_message_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_index_get_repeated_message_field_as_json_array(message_index, field_number, result);
+	RETURN result;
+END $$
+
 DROP FUNCTION IF EXISTS _pb_wire_json_add_all_repeated_int32_field_elements $$
 CREATE FUNCTION _pb_wire_json_add_all_repeated_int32_field_elements(wire_json JSON, field_number INT, value_array JSON, use_packed BOOLEAN) RETURNS JSON DETERMINISTIC
 BEGIN
@@ -7962,10 +12687,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -7982,31 +12703,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8027,10 +12724,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8047,31 +12740,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8092,10 +12761,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8112,31 +12777,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8157,10 +12798,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8177,31 +12814,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8222,10 +12835,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8242,31 +12851,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8287,10 +12872,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8307,31 +12888,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8352,10 +12909,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8372,31 +12925,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8417,10 +12946,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8437,31 +12962,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8482,10 +12983,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8502,31 +12999,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8547,10 +13020,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8567,31 +13036,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8612,10 +13057,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8632,31 +13073,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8677,10 +13094,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8697,31 +13110,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8742,10 +13131,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8762,31 +13147,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8807,10 +13168,6 @@ BEGIN
 	DECLARE result JSON;
 	DECLARE packed_data LONGBLOB DEFAULT '';
 	DECLARE temp_encoded LONGBLOB;
-	DECLARE new_element JSON;
-	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-	DECLARE field_array JSON;
-	DECLARE next_index INT;
 
 	SET result = wire_json;
 	SET array_length = JSON_LENGTH(value_array);
@@ -8827,31 +13184,7 @@ BEGIN
 			SET i = i + 1;
 		END WHILE;
 
-		-- Get existing field array
-		SET field_array = JSON_EXTRACT(result, field_path);
-
-		-- Check if we can append to existing packed field
-		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-			-- Check if last element is packed (wire type 2)
-			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-				-- Append to existing packed data
-				SET packed_data = CONCAT(
-					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-					packed_data
-				);
-				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-			END IF;
-		END IF;
-
-		-- Create new packed element
-		SET next_index = _pb_wire_json_get_next_index(result);
-		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-
-		IF field_array IS NULL THEN
-			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		ELSE
-			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		END IF;
+		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 	ELSE
 		-- Add unpacked elements
 		WHILE i < array_length DO
@@ -8931,3 +13264,3853 @@ BEGIN
 	END WHILE;
 	RETURN result;
 END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_to_wire_json $$
+CREATE FUNCTION try_pb_message_to_wire_json(buf LONGBLOB) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_to_wire_json(buf);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_to_wire_json_v2 $$
+CREATE FUNCTION try_pb_message_to_wire_json_v2(buf LONGBLOB) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_to_wire_json_v2(buf);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index $$
+CREATE FUNCTION try_pb_message_index(message LONGBLOB) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index(message);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_to_json $$
+CREATE FUNCTION try_pb_message_to_json(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_to_json(descriptor_set_json, type_name, message);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_to_json $$
+CREATE FUNCTION try_pb_wire_json_to_json(descriptor_set_json JSON, type_name TEXT, wire_json JSON) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_to_json(descriptor_set_json, type_name, wire_json);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_int32_field $$
+CREATE FUNCTION try_pb_message_get_int32_field(message LONGBLOB, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_int32_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_int32_field $$
+CREATE FUNCTION try_pb_message_has_int32_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_int32_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_int32_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_int32_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_int32_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_int32_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_int32_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_int32_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_int64_field $$
+CREATE FUNCTION try_pb_message_get_int64_field(message LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_int64_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_int64_field $$
+CREATE FUNCTION try_pb_message_has_int64_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_int64_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_int64_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_int64_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_int64_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_int64_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_int64_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_int64_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_uint32_field $$
+CREATE FUNCTION try_pb_message_get_uint32_field(message LONGBLOB, field_number INT, default_value INT UNSIGNED) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_uint32_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_uint32_field $$
+CREATE FUNCTION try_pb_message_has_uint32_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_uint32_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_uint32_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_uint32_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_uint32_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_uint32_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_uint32_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_uint32_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_uint64_field $$
+CREATE FUNCTION try_pb_message_get_uint64_field(message LONGBLOB, field_number INT, default_value BIGINT UNSIGNED) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_uint64_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_uint64_field $$
+CREATE FUNCTION try_pb_message_has_uint64_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_uint64_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_uint64_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_uint64_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_uint64_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_uint64_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_uint64_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_uint64_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_sint32_field $$
+CREATE FUNCTION try_pb_message_get_sint32_field(message LONGBLOB, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_sint32_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_sint32_field $$
+CREATE FUNCTION try_pb_message_has_sint32_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_sint32_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sint32_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_sint32_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sint32_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sint32_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_sint32_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sint32_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_sint64_field $$
+CREATE FUNCTION try_pb_message_get_sint64_field(message LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_sint64_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_sint64_field $$
+CREATE FUNCTION try_pb_message_has_sint64_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_sint64_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sint64_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_sint64_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sint64_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sint64_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_sint64_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sint64_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_enum_field $$
+CREATE FUNCTION try_pb_message_get_enum_field(message LONGBLOB, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_enum_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_enum_field $$
+CREATE FUNCTION try_pb_message_has_enum_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_enum_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_enum_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_enum_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_enum_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_enum_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_enum_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_enum_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_bool_field $$
+CREATE FUNCTION try_pb_message_get_bool_field(message LONGBLOB, field_number INT, default_value BOOLEAN) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_bool_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_bool_field $$
+CREATE FUNCTION try_pb_message_has_bool_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_bool_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_bool_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_bool_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_bool_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_bool_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_bool_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_bool_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_fixed32_field $$
+CREATE FUNCTION try_pb_message_get_fixed32_field(message LONGBLOB, field_number INT, default_value INT UNSIGNED) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_fixed32_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_fixed32_field $$
+CREATE FUNCTION try_pb_message_has_fixed32_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_fixed32_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_fixed32_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_fixed32_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_fixed32_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_fixed32_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_fixed32_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_fixed32_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_sfixed32_field $$
+CREATE FUNCTION try_pb_message_get_sfixed32_field(message LONGBLOB, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_sfixed32_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_sfixed32_field $$
+CREATE FUNCTION try_pb_message_has_sfixed32_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_sfixed32_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sfixed32_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_sfixed32_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sfixed32_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sfixed32_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_sfixed32_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sfixed32_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_float_field $$
+CREATE FUNCTION try_pb_message_get_float_field(message LONGBLOB, field_number INT, default_value FLOAT) RETURNS FLOAT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_float_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_float_field $$
+CREATE FUNCTION try_pb_message_has_float_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_float_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_float_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_float_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS FLOAT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_float_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_float_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_float_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_float_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_fixed64_field $$
+CREATE FUNCTION try_pb_message_get_fixed64_field(message LONGBLOB, field_number INT, default_value BIGINT UNSIGNED) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_fixed64_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_fixed64_field $$
+CREATE FUNCTION try_pb_message_has_fixed64_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_fixed64_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_fixed64_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_fixed64_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_fixed64_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_fixed64_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_fixed64_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_fixed64_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_sfixed64_field $$
+CREATE FUNCTION try_pb_message_get_sfixed64_field(message LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_sfixed64_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_sfixed64_field $$
+CREATE FUNCTION try_pb_message_has_sfixed64_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_sfixed64_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sfixed64_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_sfixed64_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sfixed64_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sfixed64_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_sfixed64_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sfixed64_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_double_field $$
+CREATE FUNCTION try_pb_message_get_double_field(message LONGBLOB, field_number INT, default_value DOUBLE) RETURNS DOUBLE NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_double_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_double_field $$
+CREATE FUNCTION try_pb_message_has_double_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_double_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_double_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_double_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS DOUBLE NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_double_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_double_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_double_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_double_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_bytes_field $$
+CREATE FUNCTION try_pb_message_get_bytes_field(message LONGBLOB, field_number INT, default_value LONGBLOB) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_bytes_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_bytes_field $$
+CREATE FUNCTION try_pb_message_has_bytes_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_bytes_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_bytes_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_bytes_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_bytes_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_bytes_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_bytes_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_bytes_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_string_field $$
+CREATE FUNCTION try_pb_message_get_string_field(message LONGBLOB, field_number INT, default_value LONGTEXT) RETURNS LONGTEXT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_string_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_string_field $$
+CREATE FUNCTION try_pb_message_has_string_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_string_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_string_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_string_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGTEXT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_string_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_string_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_string_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_string_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_message_field $$
+CREATE FUNCTION try_pb_message_get_message_field(message LONGBLOB, field_number INT, default_value LONGBLOB) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_message_field(message, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_has_message_field $$
+CREATE FUNCTION try_pb_message_has_message_field(message LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_has_message_field(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_message_field_element $$
+CREATE FUNCTION try_pb_message_get_repeated_message_field_element(message LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_message_field_element(message, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_message_field_count $$
+CREATE FUNCTION try_pb_message_get_repeated_message_field_count(message LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_message_field_count(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_int32_field $$
+CREATE FUNCTION try_pb_wire_json_get_int32_field(wire_json JSON, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_int32_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_int32_field $$
+CREATE FUNCTION try_pb_wire_json_has_int32_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_int32_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_int32_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_int32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_int32_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_int32_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_int32_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_int32_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_int64_field $$
+CREATE FUNCTION try_pb_wire_json_get_int64_field(wire_json JSON, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_int64_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_int64_field $$
+CREATE FUNCTION try_pb_wire_json_has_int64_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_int64_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_int64_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_int64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_int64_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_int64_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_int64_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_int64_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_uint32_field $$
+CREATE FUNCTION try_pb_wire_json_get_uint32_field(wire_json JSON, field_number INT, default_value INT UNSIGNED) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_uint32_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_uint32_field $$
+CREATE FUNCTION try_pb_wire_json_has_uint32_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_uint32_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_uint32_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_uint32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_uint32_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_uint32_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_uint32_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_uint32_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_uint64_field $$
+CREATE FUNCTION try_pb_wire_json_get_uint64_field(wire_json JSON, field_number INT, default_value BIGINT UNSIGNED) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_uint64_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_uint64_field $$
+CREATE FUNCTION try_pb_wire_json_has_uint64_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_uint64_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_uint64_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_uint64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_uint64_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_uint64_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_uint64_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_uint64_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_sint32_field $$
+CREATE FUNCTION try_pb_wire_json_get_sint32_field(wire_json JSON, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_sint32_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_sint32_field $$
+CREATE FUNCTION try_pb_wire_json_has_sint32_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_sint32_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sint32_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sint32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sint32_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sint32_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sint32_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sint32_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_sint64_field $$
+CREATE FUNCTION try_pb_wire_json_get_sint64_field(wire_json JSON, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_sint64_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_sint64_field $$
+CREATE FUNCTION try_pb_wire_json_has_sint64_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_sint64_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sint64_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sint64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sint64_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sint64_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sint64_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sint64_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_enum_field $$
+CREATE FUNCTION try_pb_wire_json_get_enum_field(wire_json JSON, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_enum_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_enum_field $$
+CREATE FUNCTION try_pb_wire_json_has_enum_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_enum_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_enum_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_enum_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_enum_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_enum_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_enum_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_enum_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_bool_field $$
+CREATE FUNCTION try_pb_wire_json_get_bool_field(wire_json JSON, field_number INT, default_value BOOLEAN) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_bool_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_bool_field $$
+CREATE FUNCTION try_pb_wire_json_has_bool_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_bool_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_bool_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_bool_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_bool_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_bool_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_bool_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_bool_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_fixed32_field $$
+CREATE FUNCTION try_pb_wire_json_get_fixed32_field(wire_json JSON, field_number INT, default_value INT UNSIGNED) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_fixed32_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_fixed32_field $$
+CREATE FUNCTION try_pb_wire_json_has_fixed32_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_fixed32_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_fixed32_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_fixed32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_fixed32_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_fixed32_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_fixed32_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_fixed32_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_sfixed32_field $$
+CREATE FUNCTION try_pb_wire_json_get_sfixed32_field(wire_json JSON, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_sfixed32_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_sfixed32_field $$
+CREATE FUNCTION try_pb_wire_json_has_sfixed32_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_sfixed32_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sfixed32_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sfixed32_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sfixed32_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sfixed32_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sfixed32_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sfixed32_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_float_field $$
+CREATE FUNCTION try_pb_wire_json_get_float_field(wire_json JSON, field_number INT, default_value FLOAT) RETURNS FLOAT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_float_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_float_field $$
+CREATE FUNCTION try_pb_wire_json_has_float_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_float_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_float_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_float_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS FLOAT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_float_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_float_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_float_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_float_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_fixed64_field $$
+CREATE FUNCTION try_pb_wire_json_get_fixed64_field(wire_json JSON, field_number INT, default_value BIGINT UNSIGNED) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_fixed64_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_fixed64_field $$
+CREATE FUNCTION try_pb_wire_json_has_fixed64_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_fixed64_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_fixed64_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_fixed64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_fixed64_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_fixed64_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_fixed64_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_fixed64_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_sfixed64_field $$
+CREATE FUNCTION try_pb_wire_json_get_sfixed64_field(wire_json JSON, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_sfixed64_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_sfixed64_field $$
+CREATE FUNCTION try_pb_wire_json_has_sfixed64_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_sfixed64_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sfixed64_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sfixed64_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sfixed64_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sfixed64_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sfixed64_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sfixed64_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_double_field $$
+CREATE FUNCTION try_pb_wire_json_get_double_field(wire_json JSON, field_number INT, default_value DOUBLE) RETURNS DOUBLE NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_double_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_double_field $$
+CREATE FUNCTION try_pb_wire_json_has_double_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_double_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_double_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_double_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS DOUBLE NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_double_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_double_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_double_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_double_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_bytes_field $$
+CREATE FUNCTION try_pb_wire_json_get_bytes_field(wire_json JSON, field_number INT, default_value LONGBLOB) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_bytes_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_bytes_field $$
+CREATE FUNCTION try_pb_wire_json_has_bytes_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_bytes_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_bytes_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_bytes_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_bytes_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_bytes_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_bytes_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_bytes_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_string_field $$
+CREATE FUNCTION try_pb_wire_json_get_string_field(wire_json JSON, field_number INT, default_value LONGTEXT) RETURNS LONGTEXT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_string_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_string_field $$
+CREATE FUNCTION try_pb_wire_json_has_string_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_string_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_string_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_string_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS LONGTEXT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_string_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_string_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_string_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_string_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_message_field $$
+CREATE FUNCTION try_pb_wire_json_get_message_field(wire_json JSON, field_number INT, default_value LONGBLOB) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_message_field(wire_json, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_has_message_field $$
+CREATE FUNCTION try_pb_wire_json_has_message_field(wire_json JSON, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_has_message_field(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_message_field_element $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_message_field_element(wire_json JSON, field_number INT, repeated_index INT) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_message_field_element(wire_json, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_message_field_count $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_message_field_count(wire_json JSON, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_int32_field $$
+CREATE FUNCTION try_pb_message_index_get_int32_field(message_index LONGBLOB, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_int32_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_int32_field $$
+CREATE FUNCTION try_pb_message_index_has_int32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_int32_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_int32_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_int32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_int32_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_int32_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_int32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_int32_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_int64_field $$
+CREATE FUNCTION try_pb_message_index_get_int64_field(message_index LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_int64_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_int64_field $$
+CREATE FUNCTION try_pb_message_index_has_int64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_int64_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_int64_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_int64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_int64_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_int64_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_int64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_int64_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_uint32_field $$
+CREATE FUNCTION try_pb_message_index_get_uint32_field(message_index LONGBLOB, field_number INT, default_value INT UNSIGNED) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_uint32_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_uint32_field $$
+CREATE FUNCTION try_pb_message_index_has_uint32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_uint32_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_uint32_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_uint32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_uint32_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_uint32_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_uint32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_uint32_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_uint64_field $$
+CREATE FUNCTION try_pb_message_index_get_uint64_field(message_index LONGBLOB, field_number INT, default_value BIGINT UNSIGNED) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_uint64_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_uint64_field $$
+CREATE FUNCTION try_pb_message_index_has_uint64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_uint64_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_uint64_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_uint64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_uint64_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_uint64_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_uint64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_uint64_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_sint32_field $$
+CREATE FUNCTION try_pb_message_index_get_sint32_field(message_index LONGBLOB, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_sint32_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_sint32_field $$
+CREATE FUNCTION try_pb_message_index_has_sint32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_sint32_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sint32_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sint32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sint32_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sint32_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sint32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sint32_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_sint64_field $$
+CREATE FUNCTION try_pb_message_index_get_sint64_field(message_index LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_sint64_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_sint64_field $$
+CREATE FUNCTION try_pb_message_index_has_sint64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_sint64_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sint64_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sint64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sint64_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sint64_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sint64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sint64_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_enum_field $$
+CREATE FUNCTION try_pb_message_index_get_enum_field(message_index LONGBLOB, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_enum_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_enum_field $$
+CREATE FUNCTION try_pb_message_index_has_enum_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_enum_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_enum_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_enum_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_enum_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_enum_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_enum_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_enum_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_bool_field $$
+CREATE FUNCTION try_pb_message_index_get_bool_field(message_index LONGBLOB, field_number INT, default_value BOOLEAN) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_bool_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_bool_field $$
+CREATE FUNCTION try_pb_message_index_has_bool_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_bool_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_bool_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_bool_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_bool_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_bool_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_bool_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_bool_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_fixed32_field $$
+CREATE FUNCTION try_pb_message_index_get_fixed32_field(message_index LONGBLOB, field_number INT, default_value INT UNSIGNED) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_fixed32_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_fixed32_field $$
+CREATE FUNCTION try_pb_message_index_has_fixed32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_fixed32_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_fixed32_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_fixed32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_fixed32_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_fixed32_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_fixed32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_fixed32_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_sfixed32_field $$
+CREATE FUNCTION try_pb_message_index_get_sfixed32_field(message_index LONGBLOB, field_number INT, default_value INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_sfixed32_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_sfixed32_field $$
+CREATE FUNCTION try_pb_message_index_has_sfixed32_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_sfixed32_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sfixed32_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sfixed32_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sfixed32_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sfixed32_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sfixed32_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sfixed32_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_float_field $$
+CREATE FUNCTION try_pb_message_index_get_float_field(message_index LONGBLOB, field_number INT, default_value FLOAT) RETURNS FLOAT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_float_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_float_field $$
+CREATE FUNCTION try_pb_message_index_has_float_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_float_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_float_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_float_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS FLOAT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_float_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_float_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_float_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_float_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_fixed64_field $$
+CREATE FUNCTION try_pb_message_index_get_fixed64_field(message_index LONGBLOB, field_number INT, default_value BIGINT UNSIGNED) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_fixed64_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_fixed64_field $$
+CREATE FUNCTION try_pb_message_index_has_fixed64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_fixed64_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_fixed64_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_fixed64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT UNSIGNED NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_fixed64_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_fixed64_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_fixed64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_fixed64_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_sfixed64_field $$
+CREATE FUNCTION try_pb_message_index_get_sfixed64_field(message_index LONGBLOB, field_number INT, default_value BIGINT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_sfixed64_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_sfixed64_field $$
+CREATE FUNCTION try_pb_message_index_has_sfixed64_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_sfixed64_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sfixed64_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sfixed64_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS BIGINT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sfixed64_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sfixed64_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sfixed64_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sfixed64_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_double_field $$
+CREATE FUNCTION try_pb_message_index_get_double_field(message_index LONGBLOB, field_number INT, default_value DOUBLE) RETURNS DOUBLE NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_double_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_double_field $$
+CREATE FUNCTION try_pb_message_index_has_double_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_double_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_double_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_double_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS DOUBLE NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_double_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_double_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_double_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_double_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_bytes_field $$
+CREATE FUNCTION try_pb_message_index_get_bytes_field(message_index LONGBLOB, field_number INT, default_value LONGBLOB) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_bytes_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_bytes_field $$
+CREATE FUNCTION try_pb_message_index_has_bytes_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_bytes_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_bytes_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_bytes_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_bytes_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_bytes_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_bytes_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_bytes_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_string_field $$
+CREATE FUNCTION try_pb_message_index_get_string_field(message_index LONGBLOB, field_number INT, default_value LONGTEXT) RETURNS LONGTEXT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_string_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_string_field $$
+CREATE FUNCTION try_pb_message_index_has_string_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_string_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_string_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_string_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGTEXT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_string_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_string_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_string_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_string_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_message_field $$
+CREATE FUNCTION try_pb_message_index_get_message_field(message_index LONGBLOB, field_number INT, default_value LONGBLOB) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_message_field(message_index, field_number, default_value);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_has_message_field $$
+CREATE FUNCTION try_pb_message_index_has_message_field(message_index LONGBLOB, field_number INT) RETURNS BOOLEAN NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_has_message_field(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_message_field_element $$
+CREATE FUNCTION try_pb_message_index_get_repeated_message_field_element(message_index LONGBLOB, field_number INT, repeated_index INT) RETURNS LONGBLOB NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_message_field_element(message_index, field_number, repeated_index);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_message_field_count $$
+CREATE FUNCTION try_pb_message_index_get_repeated_message_field_count(message_index LONGBLOB, field_number INT) RETURNS INT NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_message_field_count(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_int32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_int32_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_int32_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_int32_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_int32_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_int32_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_int32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_int32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_int32_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_uint32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_uint32_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_uint32_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_uint32_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_uint32_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_uint32_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_uint32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_uint32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_uint32_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_int64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_int64_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_int64_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_int64_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_int64_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_int64_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_int64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_int64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_int64_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_int64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_get_repeated_int64_field_as_json_string_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_int64_field_as_json_string_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_int64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_int64_field_as_json_string_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_int64_field_as_json_string_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_int64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_int64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_int64_field_as_json_string_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_uint64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_uint64_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_uint64_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_uint64_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_uint64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_uint64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_uint64_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_uint64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_get_repeated_uint64_field_as_json_string_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_uint64_field_as_json_string_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_uint64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_uint64_field_as_json_string_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_uint64_field_as_json_string_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_uint64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_uint64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_uint64_field_as_json_string_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sint32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_sint32_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sint32_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sint32_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sint32_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sint32_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sint32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sint32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sint32_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sint64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_sint64_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sint64_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sint64_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sint64_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sint64_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sint64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sint64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sint64_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sint64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_get_repeated_sint64_field_as_json_string_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sint64_field_as_json_string_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sint64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sint64_field_as_json_string_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sint64_field_as_json_string_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sint64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sint64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sint64_field_as_json_string_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_enum_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_enum_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_enum_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_enum_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_enum_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_enum_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_enum_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_enum_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_bool_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_bool_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_bool_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_bool_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_bool_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_bool_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_bool_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_bool_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_bool_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_fixed32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_fixed32_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_fixed32_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_fixed32_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_fixed32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_fixed32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_fixed32_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sfixed32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_sfixed32_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sfixed32_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sfixed32_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sfixed32_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sfixed32_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sfixed32_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sfixed32_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sfixed32_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_float_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_float_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_float_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_float_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_float_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_float_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_float_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_float_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_float_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_fixed64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_fixed64_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_fixed64_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_fixed64_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_fixed64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_fixed64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_fixed64_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_fixed64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_get_repeated_fixed64_field_as_json_string_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_fixed64_field_as_json_string_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_fixed64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_fixed64_field_as_json_string_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_fixed64_field_as_json_string_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_fixed64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_fixed64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_fixed64_field_as_json_string_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sfixed64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_sfixed64_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sfixed64_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sfixed64_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sfixed64_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sfixed64_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sfixed64_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sfixed64_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sfixed64_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_sfixed64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_get_repeated_sfixed64_field_as_json_string_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_sfixed64_field_as_json_string_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_sfixed64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_sfixed64_field_as_json_string_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_sfixed64_field_as_json_string_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_sfixed64_field_as_json_string_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_sfixed64_field_as_json_string_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_sfixed64_field_as_json_string_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_double_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_double_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_double_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_double_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_double_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_double_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_double_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_double_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_double_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_bytes_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_bytes_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_bytes_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_bytes_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_bytes_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_bytes_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_bytes_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_string_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_string_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_string_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_string_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_string_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_string_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_string_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_string_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_string_field_as_json_array(message_index, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_get_repeated_message_field_as_json_array $$
+CREATE FUNCTION try_pb_message_get_repeated_message_field_as_json_array(message LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_get_repeated_message_field_as_json_array(message, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_wire_json_get_repeated_message_field_as_json_array $$
+CREATE FUNCTION try_pb_wire_json_get_repeated_message_field_as_json_array(wire_json JSON, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+END $$
+
+DROP FUNCTION IF EXISTS try_pb_message_index_get_repeated_message_field_as_json_array $$
+CREATE FUNCTION try_pb_message_index_get_repeated_message_field_as_json_array(message_index LONGBLOB, field_number INT) RETURNS JSON NOT DETERMINISTIC
+BEGIN
+	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+	-- missing from the schema (45006), are raised as usual.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		RETURN NULL;
+	END;
+	SET @pb_last_error = NULL;
+	RETURN pb_message_index_get_repeated_message_field_as_json_array(message_index, field_number);
+END $$
@@ -13,6 +13,7 @@ import (
 )
 
 type WireTypeAccessor struct {
+	WireType                      int // wire type of an unpacked element
 	SqlType                       string
 	SupportsPacked                bool
 	GetFunction                   string
@@ -34,9 +35,18 @@ type Accessor struct {
 }
 
 type Input struct {
-	Kind    string // message or wire_json (used as part of the function or procedure name)
-	Name    string // message or wire_json (used as a parameter name in the function)
-	SqlType string // LONGBLOB or JSON (used as a parameter type in the function)
+	Kind     string // message, wire_json or message_index (used as part of the function or procedure name)
+	Name     string // message, wire_json or message_index (used as a parameter name in the function)
+	SqlType  string // LONGBLOB or JSON (used as a parameter type in the function)
+	ReadOnly bool   // only getters are generated
+}
+
+// TryFunction describes a function for which a non-throwing try_ variant is generated
+type TryFunction struct {
+	Name       string // name of the wrapped function
+	Parameters string // parameter list of the wrapped function
+	Arguments  string // argument list passed to the wrapped function
+	ReturnType string
 }
 
 type RepeatedAsJsonAccessor struct {
@@ -48,7 +58,20 @@ type RepeatedAsJsonAccessor struct {
 	Suffix              string
 }
 
-func generateRepeatedNumbersAsJson() {
+// PackedHeadSize returns the maximum encoded size of a single packed element, which is the
+// size of the window passed to PackedUint64Decoder.
+func (a *RepeatedAsJsonAccessor) PackedHeadSize() int {
+	switch a.PackedUint64Decoder {
+	case "_pb_wire_read_i32_as_uint32":
+		return 4
+	case "_pb_wire_read_i64_as_uint64":
+		return 8
+	default:
+		return 10
+	}
+}
+
+func generateRepeatedNumbersAsJson(inputs []*Input) []*TryFunction {
 	accessors := []*RepeatedAsJsonAccessor{
 		{
 			ProtoType:           "int32",
@@ -236,6 +259,7 @@ func generateRepeatedNumbersAsJson() {
 		|	DECLARE message_text TEXT;
 		|	DECLARE uint_value BIGINT UNSIGNED;
 		|	DECLARE bytes_value LONGBLOB;
+		|	DECLARE packed_offset BIGINT;
 		|	DECLARE wire_type INT;
 		|	DECLARE wire_elements JSON;
 		|	DECLARE wire_element JSON;
@@ -244,7 +268,7 @@ func generateRepeatedNumbersAsJson() {
 		|
 		|	SET result = JSON_ARRAY();
 		|
-		|	SET wire_elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		|	SET wire_elements = _pb_wire_json_get_field_elements(wire_json, field_number);
 		|	SET wire_element_index = 0;
 		|	SET wire_element_count = JSON_LENGTH(wire_elements);
 		|
@@ -263,14 +287,14 @@ func generateRepeatedNumbersAsJson() {
 		|{{- if .PackedUint64Decoder }}
 		|		WHEN 2 THEN -- LEN
 		|			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_element, '$.v')));
-		|			WHILE LENGTH(bytes_value) <> 0 DO
-		|				CALL {{.PackedUint64Decoder}}(bytes_value, uint_value, bytes_value);
+		|			SET packed_offset = 0;
+		|			WHILE packed_offset < LENGTH(bytes_value) DO
+		|				CALL {{.PackedUint64Decoder}}(SUBSTRING(bytes_value, packed_offset + 1, {{.PackedHeadSize}}), packed_offset, uint_value);
 		|				SET result = JSON_ARRAY_APPEND(result, '$', {{.Expr}});
 		|			END WHILE;
 		|{{- end }}
 		|		ELSE
-		|			SET message_text = CONCAT('_pb_wire_json_get_repeated_{{.ProtoType}}_field{{.Suffix}}: unexpected wire_type (', wire_type, ')');
-		|			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+		|			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_wire_json_get_repeated_{{.ProtoType}}_field{{.Suffix}}', CONCAT('unexpected wire_type (', wire_type, ')'));
 		|		END CASE;
 		|
 		|		SET wire_element_index = wire_element_index + 1;
@@ -289,49 +313,64 @@ func generateRepeatedNumbersAsJson() {
 		|CREATE PROCEDURE _pb_message_get_repeated_{{.ProtoType}}_field{{.Suffix}}(IN message LONGBLOB, IN field_number INT, OUT result JSON)
 		|BEGIN
 		|	DECLARE tag BIGINT;
-		|	DECLARE tail LONGBLOB;
+		|	DECLARE offset BIGINT DEFAULT 0;
+		|	DECLARE message_length BIGINT DEFAULT LENGTH(message);
+		|	DECLARE value_length BIGINT;
+		|	DECLARE packed_offset BIGINT;
 		|	DECLARE uint_value BIGINT UNSIGNED;
 		|	DECLARE bytes_value LONGBLOB;
 		|	DECLARE message_text TEXT;
 		|	DECLARE current_field_number INT;
 		|	DECLARE current_wire_type INT;
 		|
-		|	SET tail = message;
+		|	-- Add the byte offset and field number of the failing element to decoder errors
+		|	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+		|	BEGIN
+		|		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		|		SET message_text = _pb_util_annotate_error(message_text, offset, _pb_wire_get_field_number_from_tag(tag));
+		|		RESIGNAL SET MESSAGE_TEXT = message_text;
+		|	END;
+		|
 		|	SET result = JSON_ARRAY();
 		|
-		|	l1: WHILE LENGTH(tail) <> 0 DO
-		|		CALL _pb_wire_read_varint_as_uint64(tail, tag, tail);
+		|	l1: WHILE offset < message_length DO
+		|		SET tag = NULL;
+		|		CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, tag);
 		|		SET current_field_number = _pb_wire_get_field_number_from_tag(tag);
 		|		SET current_wire_type = _pb_wire_get_wire_type_from_tag(tag);
 		|
 		|		IF current_field_number != field_number THEN
-		|			CALL _pb_wire_skip(tail, current_wire_type, tail);
+		|			CALL _pb_wire_skip(SUBSTRING(message, offset + 1, 10), message_length, current_wire_type, offset);
 		|			ITERATE l1;
 		|		END IF;
 		|
 		|		CASE current_wire_type
 		|		WHEN {{.WireType}} THEN
 		|{{- if eq .WireType 0 }}
-		|			CALL _pb_wire_read_varint_as_uint64(tail, uint_value, tail);
+		|			CALL _pb_wire_read_varint_as_uint64(SUBSTRING(message, offset + 1, 10), offset, uint_value);
 		|{{- else if eq .WireType 2 }}
-		|			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
+		|			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+		|			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+		|			SET offset = offset + value_length;
 		|{{- else if eq .WireType 1 }}
-		|			CALL _pb_wire_read_i64_as_uint64(tail, uint_value, tail);
+		|			CALL _pb_wire_read_i64_as_uint64(SUBSTRING(message, offset + 1, 8), offset, uint_value);
 		|{{- else if eq .WireType 5 }}
-		|			CALL _pb_wire_read_i32_as_uint32(tail, uint_value, tail);
+		|			CALL _pb_wire_read_i32_as_uint32(SUBSTRING(message, offset + 1, 4), offset, uint_value);
 		|{{- end }}
 		|			SET result = JSON_ARRAY_APPEND(result, '$', {{.Expr}});
 		|{{- if .PackedUint64Decoder }}
 		|		WHEN 2 THEN
-		|			CALL _pb_wire_read_len_type(tail, bytes_value, tail);
-		|			WHILE LENGTH(bytes_value) <> 0 DO
-		|				CALL {{.PackedUint64Decoder}}(bytes_value, uint_value, bytes_value);
+		|			CALL _pb_wire_read_len_type_header(SUBSTRING(message, offset + 1, 10), message_length, offset, value_length);
+		|			SET bytes_value = SUBSTRING(message, offset + 1, value_length);
+		|			SET offset = offset + value_length;
+		|			SET packed_offset = 0;
+		|			WHILE packed_offset < LENGTH(bytes_value) DO
+		|				CALL {{.PackedUint64Decoder}}(SUBSTRING(bytes_value, packed_offset + 1, {{.PackedHeadSize}}), packed_offset, uint_value);
 		|				SET result = JSON_ARRAY_APPEND(result, '$', {{.Expr}});
 		|			END WHILE;
 		|{{- end }}
 		|		ELSE
-		|			SET message_text = CONCAT('_pb_message_get_repeated_{{.ProtoType}}_field{{.Suffix}}: unexpected wire_type (', current_wire_type, ')');
-		|			SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = message_text;
+		|			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_get_repeated_{{.ProtoType}}_field{{.Suffix}}', CONCAT('unexpected wire_type (', current_wire_type, ')'));
 		|		END CASE;
 		|	END WHILE;
 		|END $$
@@ -343,6 +382,72 @@ func generateRepeatedNumbersAsJson() {
 		|	CALL _pb_message_get_repeated_{{.ProtoType}}_field{{.Suffix}}(message, field_number, result);
 		|	RETURN result;
 		|END $$
+		|
+		|DROP PROCEDURE IF EXISTS _pb_message_index_get_repeated_{{.ProtoType}}_field{{.Suffix}} $$
+		|CREATE PROCEDURE _pb_message_index_get_repeated_{{.ProtoType}}_field{{.Suffix}}(IN message_index LONGBLOB, IN field_number INT, OUT result JSON)
+		|BEGIN
+		|	DECLARE entry_index INT;
+		|	DECLARE entry_count INT;
+		|	DECLARE message_start BIGINT;
+		|	DECLARE current_field_number INT;
+		|	DECLARE current_wire_type INT;
+		|	DECLARE value_offset BIGINT;
+		|	DECLARE value_length BIGINT;
+		|	DECLARE packed_offset BIGINT;
+		|	DECLARE uint_value BIGINT UNSIGNED;
+		|	DECLARE bytes_value LONGBLOB;
+		|	DECLARE message_text TEXT;
+		|
+		|	-- Add the byte offset and field number of the failing element to decoder errors
+		|	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005'
+		|	BEGIN
+		|		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		|		SET message_text = _pb_util_annotate_error(message_text, value_offset, field_number);
+		|		RESIGNAL SET MESSAGE_TEXT = message_text;
+		|	END;
+		|
+		|	SET result = JSON_ARRAY();
+		|	SET entry_count = _pb_message_index_get_entry_count(LEFT(message_index, 5), LENGTH(message_index));
+		|	CALL _pb_message_index_find_field(SUBSTRING(message_index, 6, entry_count * 13), field_number, entry_index);
+		|	SET message_start = 5 + entry_count * 13;
+		|
+		|	l1: WHILE entry_index < entry_count DO
+		|		CALL _pb_message_index_read_entry(SUBSTRING(message_index, 6 + entry_index * 13, 13), current_field_number, current_wire_type, value_offset, value_length);
+		|		IF current_field_number <> field_number THEN
+		|			LEAVE l1;
+		|		END IF;
+		|		SET bytes_value = SUBSTRING(message_index, message_start + value_offset + 1, value_length);
+		|
+		|		CASE current_wire_type
+		|		WHEN {{.WireType}} THEN
+		|{{- if ne .WireType 2 }}
+		|			SET packed_offset = 0;
+		|			CALL {{.PackedUint64Decoder}}(bytes_value, packed_offset, uint_value);
+		|{{- end }}
+		|			SET result = JSON_ARRAY_APPEND(result, '$', {{.Expr}});
+		|{{- if .PackedUint64Decoder }}
+		|		WHEN 2 THEN
+		|			SET packed_offset = 0;
+		|			WHILE packed_offset < value_length DO
+		|				CALL {{.PackedUint64Decoder}}(SUBSTRING(bytes_value, packed_offset + 1, {{.PackedHeadSize}}), packed_offset, uint_value);
+		|				SET result = JSON_ARRAY_APPEND(result, '$', {{.Expr}});
+		|			END WHILE;
+		|{{- end }}
+		|		ELSE
+		|			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', '_pb_message_index_get_repeated_{{.ProtoType}}_field{{.Suffix}}', CONCAT('unexpected wire_type (', current_wire_type, ')'));
+		|		END CASE;
+		|
+		|		SET entry_index = entry_index + 1;
+		|	END WHILE;
+		|END $$
+		|
+		|DROP FUNCTION IF EXISTS pb_message_index_get_repeated_{{.ProtoType}}_field{{.Suffix}} $$
+		|CREATE FUNCTION pb_message_index_get_repeated_{{.ProtoType}}_field{{.Suffix}}(message_index LONGBLOB, field_number INT) RETURNS JSON DETERMINISTIC
+		|BEGIN
+		|	DECLARE result JSON;
+		|	CALL _pb_message_index_get_repeated_{{.ProtoType}}_field{{.Suffix}}(message_index, field_number, result);
+		|	RETURN result;
+		|END $$
 	`)
 
 	tmpl, err := template.New("t").Parse(templateText)
@@ -350,10 +455,53 @@ func generateRepeatedNumbersAsJson() {
 		panic(err)
 	}
 
+	var tryFunctions []*TryFunction
 	for _, accessor := range accessors {
 		if err := tmpl.Execute(os.Stdout, accessor); err != nil {
 			panic(err)
 		}
+		for _, input := range inputs {
+			tryFunctions = append(tryFunctions, &TryFunction{
+				Name:       fmt.Sprintf("pb_%s_get_repeated_%s_field%s", input.Kind, accessor.ProtoType, accessor.Suffix),
+				Parameters: fmt.Sprintf("%s %s, field_number INT", input.Name, input.SqlType),
+				Arguments:  fmt.Sprintf("%s, field_number", input.Name),
+				ReturnType: "JSON",
+			})
+		}
+	}
+	return tryFunctions
+}
+
+// generateTryFunctions generates try_ variants that return NULL instead of raising an error on malformed input.
+// The error message is stored in @pb_last_error, which is reset to NULL on each successful call. Since they write a
+// session variable, they are declared NOT DETERMINISTIC unlike the functions they wrap.
+func generateTryFunctions(tryFunctions []*TryFunction) {
+	templateText := dedent.Pipe(`
+		|
+		|DROP FUNCTION IF EXISTS try_{{.Name}} $$
+		|CREATE FUNCTION try_{{.Name}}({{.Parameters}}) RETURNS {{.ReturnType}} NOT DETERMINISTIC
+		|BEGIN
+		|	-- Only malformed or hostile input is turned into NULL. Other errors, such as invalid arguments or a message type
+		|	-- missing from the schema (45006), are raised as usual.
+		|	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007', SQLSTATE '45008', SQLSTATE '45009', SQLSTATE '45010', SQLSTATE '45011'
+		|	BEGIN
+		|		GET DIAGNOSTICS CONDITION 1 @pb_last_error = MESSAGE_TEXT;
+		|		RETURN NULL;
+		|	END;
+		|	SET @pb_last_error = NULL;
+		|	RETURN {{.Name}}({{.Arguments}});
+		|END $$
+	`)
+
+	tmpl, err := template.New("try").Parse(templateText)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, tryFunction := range tryFunctions {
+		if err := tmpl.Execute(os.Stdout, tryFunction); err != nil {
+			panic(err)
+		}
 	}
 }
 
@@ -361,6 +509,7 @@ func generateAccessorsAction(ctx context.Context, command *cli.Command) error {
 	inputs := []*Input{
 		{Kind: "message", Name: "message", SqlType: "LONGBLOB"},
 		{Kind: "wire_json", Name: "wire_json", SqlType: "JSON"},
+		{Kind: "message_index", Name: "message_index", SqlType: "LONGBLOB", ReadOnly: true},
 	}
 
 	templateText := dedent.Pipe(`
@@ -407,8 +556,18 @@ func generateAccessorsAction(ctx context.Context, command *cli.Command) error {
 
 	os.Stdout.WriteString("DELIMITER $$\n")
 
+	tryFunctions := []*TryFunction{
+		{Name: "pb_message_to_wire_json", Parameters: "buf LONGBLOB", Arguments: "buf", ReturnType: "JSON"},
+		{Name: "pb_message_to_wire_json_v2", Parameters: "buf LONGBLOB", Arguments: "buf", ReturnType: "JSON"},
+		{Name: "pb_message_index", Parameters: "message LONGBLOB", Arguments: "message", ReturnType: "LONGBLOB"},
+		// pb_message_to_json and pb_wire_json_to_json are defined in protobuf-json.sql and resolved when the function is called
+		{Name: "pb_message_to_json", Parameters: "descriptor_set_json JSON, type_name TEXT, message LONGBLOB", Arguments: "descriptor_set_json, type_name, message", ReturnType: "JSON"},
+		{Name: "pb_wire_json_to_json", Parameters: "descriptor_set_json JSON, type_name TEXT, wire_json JSON", Arguments: "descriptor_set_json, type_name, wire_json", ReturnType: "JSON"},
+	}
+
 	for _, input := range inputs {
 		getVarintFieldAsUint64 := &WireTypeAccessor{
+			WireType:                      0,
 			SqlType:                       "BIGINT UNSIGNED",
 			SupportsPacked:                true,
 			GetFunction:                   fmt.Sprintf("_pb_%s_get_varint_field_as_uint64", input.Kind),
@@ -420,6 +579,7 @@ func generateAccessorsAction(ctx context.Context, command *cli.Command) error {
 		}
 
 		getI64FieldAsUint64 := &WireTypeAccessor{
+			WireType:                      1,
 			SqlType:                       "BIGINT UNSIGNED",
 			SupportsPacked:                true,
 			GetFunction:                   fmt.Sprintf("_pb_%s_get_i64_field_as_uint64", input.Kind),
@@ -431,6 +591,7 @@ func generateAccessorsAction(ctx context.Context, command *cli.Command) error {
 		}
 
 		getI32FieldAsUint64 := &WireTypeAccessor{
+			WireType:                      5,
 			SqlType:                       "INT UNSIGNED",
 			SupportsPacked:                true,
 			GetFunction:                   fmt.Sprintf("_pb_%s_get_i32_field_as_uint32", input.Kind),
@@ -442,6 +603,7 @@ func generateAccessorsAction(ctx context.Context, command *cli.Command) error {
 		}
 
 		getLengthDelimitedField := &WireTypeAccessor{
+			WireType:                      2,
 			SqlType:                       "LONGBLOB",
 			SupportsPacked:                false,
 			GetFunction:                   fmt.Sprintf("_pb_%s_get_len_type_field", input.Kind),
@@ -487,6 +649,38 @@ func generateAccessorsAction(ctx context.Context, command *cli.Command) error {
 			if err := tmpl.Execute(os.Stdout, accessor); err != nil {
 				panic(err)
 			}
+
+			tryFunctions = append(tryFunctions,
+				&TryFunction{
+					Name:       fmt.Sprintf("pb_%s_get_%s_field", input.Kind, accessor.ProtoType),
+					Parameters: fmt.Sprintf("%s %s, field_number INT, default_value %s", input.Name, input.SqlType, accessor.SqlType),
+					Arguments:  fmt.Sprintf("%s, field_number, default_value", input.Name),
+					ReturnType: accessor.SqlType,
+				},
+				&TryFunction{
+					Name:       fmt.Sprintf("pb_%s_has_%s_field", input.Kind, accessor.ProtoType),
+					Parameters: fmt.Sprintf("%s %s, field_number INT", input.Name, input.SqlType),
+					Arguments:  fmt.Sprintf("%s, field_number", input.Name),
+					ReturnType: "BOOLEAN",
+				},
+				&TryFunction{
+					Name:       fmt.Sprintf("pb_%s_get_repeated_%s_field_element", input.Kind, accessor.ProtoType),
+					Parameters: fmt.Sprintf("%s %s, field_number INT, repeated_index INT", input.Name, input.SqlType),
+					Arguments:  fmt.Sprintf("%s, field_number, repeated_index", input.Name),
+					ReturnType: accessor.SqlType,
+				},
+				&TryFunction{
+					Name:       fmt.Sprintf("pb_%s_get_repeated_%s_field_count", input.Kind, accessor.ProtoType),
+					Parameters: fmt.Sprintf("%s %s, field_number INT", input.Name, input.SqlType),
+					Arguments:  fmt.Sprintf("%s, field_number", input.Name),
+					ReturnType: "INT",
+				},
+			)
+		}
+
+		// The message index is an immutable view of the message bytes
+		if input.ReadOnly {
+			continue
 		}
 
 		// Generate setter functions only for wire_json input
@@ -498,12 +692,16 @@ func generateAccessorsAction(ctx context.Context, command *cli.Command) error {
 		if input.Kind == "message" {
 			generateMessageSetters(accessors)
 		}
+
+		generateRepeatedFieldOperations(accessors)
 	}
 
-	generateRepeatedNumbersAsJson()
+	tryFunctions = append(tryFunctions, generateRepeatedNumbersAsJson(inputs)...)
 
 	generateBulkAddFunctions()
 
+	generateTryFunctions(tryFunctions)
+
 	return nil
 }
 
@@ -536,22 +734,22 @@ func generateWireJsonSetters(accessors []*Accessor) {
 		|CREATE FUNCTION pb_{{.Input.Kind}}_insert_repeated_{{.ProtoType}}_field_element({{.Input.Name}} {{.Input.SqlType}}, field_number INT, repeated_index INT, value {{.SqlType}}{{if .SupportsPacked}}, use_packed BOOLEAN{{end}}) RETURNS {{.Input.SqlType}} DETERMINISTIC
 		|BEGIN
 		|{{- if .Procedure.SupportsPacked}}
-		|	RETURN {{.Procedure.InsertRepeatedElementFunction}}({{.Input.Name}}, field_number, repeated_index, {{.ConvertExpr}}{{if .SupportsPacked}}, use_packed{{else}}, FALSE{{end}});
+		|	RETURN _pb_wire_json_to_same_version({{.Procedure.InsertRepeatedElementFunction}}(pb_wire_json_to_v1({{.Input.Name}}), field_number, repeated_index, {{.ConvertExpr}}{{if .SupportsPacked}}, use_packed{{else}}, FALSE{{end}}), {{.Input.Name}});
 		|{{- else}}
-		|	RETURN {{.Procedure.InsertRepeatedElementFunction}}({{.Input.Name}}, field_number, repeated_index, {{.ConvertExpr}});
+		|	RETURN _pb_wire_json_to_same_version({{.Procedure.InsertRepeatedElementFunction}}(pb_wire_json_to_v1({{.Input.Name}}), field_number, repeated_index, {{.ConvertExpr}}), {{.Input.Name}});
 		|{{- end}}
 		|END $$
 		|
 		|DROP FUNCTION IF EXISTS pb_{{.Input.Kind}}_set_repeated_{{.ProtoType}}_field_element $$
 		|CREATE FUNCTION pb_{{.Input.Kind}}_set_repeated_{{.ProtoType}}_field_element({{.Input.Name}} {{.Input.SqlType}}, field_number INT, repeated_index INT, value {{.SqlType}}) RETURNS {{.Input.SqlType}} DETERMINISTIC
 		|BEGIN
-		|	RETURN {{.Procedure.SetRepeatedElementFunction}}({{.Input.Name}}, field_number, repeated_index, {{.ConvertExpr}});
+		|	RETURN _pb_wire_json_to_same_version({{.Procedure.SetRepeatedElementFunction}}(pb_wire_json_to_v1({{.Input.Name}}), field_number, repeated_index, {{.ConvertExpr}}), {{.Input.Name}});
 		|END $$
 		|
 		|DROP FUNCTION IF EXISTS pb_{{.Input.Kind}}_remove_repeated_{{.ProtoType}}_field_element $$
 		|CREATE FUNCTION pb_{{.Input.Kind}}_remove_repeated_{{.ProtoType}}_field_element({{.Input.Name}} {{.Input.SqlType}}, field_number INT, repeated_index INT) RETURNS {{.Input.SqlType}} DETERMINISTIC
 		|BEGIN
-		|	RETURN {{.Procedure.RemoveRepeatedElementFunction}}({{.Input.Name}}, field_number, repeated_index);
+		|	RETURN _pb_wire_json_to_same_version({{.Procedure.RemoveRepeatedElementFunction}}(pb_wire_json_to_v1({{.Input.Name}}), field_number, repeated_index), {{.Input.Name}});
 		|END $$
 		|
 		|DROP FUNCTION IF EXISTS pb_{{.Input.Kind}}_clear_{{.ProtoType}}_field $$
@@ -655,6 +853,73 @@ func generateMessageSetters(accessors []*Accessor) {
 	}
 }
 
+func generateRepeatedFieldOperations(accessors []*Accessor) {
+	// Key type passed to _pb_util_json_array_sort_indexes; types without a key type have no sort function
+	sortKeyTypes := map[string]string{
+		"int32":    "integer",
+		"int64":    "integer",
+		"uint32":   "integer",
+		"uint64":   "integer",
+		"sint32":   "integer",
+		"sint64":   "integer",
+		"enum":     "integer",
+		"fixed32":  "integer",
+		"sfixed32": "integer",
+		"fixed64":  "integer",
+		"sfixed64": "integer",
+		"float":    "double",
+		"double":   "double",
+		"string":   "string",
+	}
+
+	type RepeatedFieldOperation struct {
+		*Accessor
+		SortKeyType string
+	}
+
+	// The operations compute the indexes of the elements to keep, in their new order, and rewrite the field in place
+	operationTemplateText := dedent.Pipe(`
+		|
+		|DROP FUNCTION IF EXISTS pb_{{.Input.Kind}}_slice_repeated_{{.ProtoType}}_field $$
+		|CREATE FUNCTION pb_{{.Input.Kind}}_slice_repeated_{{.ProtoType}}_field({{.Input.Name}} {{.Input.SqlType}}, field_number INT, start_index INT, end_index INT) RETURNS {{.Input.SqlType}} DETERMINISTIC
+		|BEGIN
+		|	RETURN _pb_{{.Input.Kind}}_rearrange_repeated_field({{.Input.Name}}, field_number, {{.Procedure.WireType}}, _pb_util_slice_indexes(pb_{{.Input.Kind}}_get_repeated_{{.ProtoType}}_field_count({{.Input.Name}}, field_number), start_index, end_index));
+		|END $$
+		|
+		|DROP FUNCTION IF EXISTS pb_{{.Input.Kind}}_reverse_repeated_{{.ProtoType}}_field $$
+		|CREATE FUNCTION pb_{{.Input.Kind}}_reverse_repeated_{{.ProtoType}}_field({{.Input.Name}} {{.Input.SqlType}}, field_number INT) RETURNS {{.Input.SqlType}} DETERMINISTIC
+		|BEGIN
+		|	RETURN _pb_{{.Input.Kind}}_rearrange_repeated_field({{.Input.Name}}, field_number, {{.Procedure.WireType}}, _pb_util_reverse_indexes(pb_{{.Input.Kind}}_get_repeated_{{.ProtoType}}_field_count({{.Input.Name}}, field_number)));
+		|END $$
+		|{{- if .SortKeyType}}
+		|
+		|DROP FUNCTION IF EXISTS pb_{{.Input.Kind}}_sort_repeated_{{.ProtoType}}_field $$
+		|CREATE FUNCTION pb_{{.Input.Kind}}_sort_repeated_{{.ProtoType}}_field({{.Input.Name}} {{.Input.SqlType}}, field_number INT) RETURNS {{.Input.SqlType}} DETERMINISTIC
+		|BEGIN
+		|	RETURN _pb_{{.Input.Kind}}_rearrange_repeated_field({{.Input.Name}}, field_number, {{.Procedure.WireType}}, _pb_util_json_array_sort_indexes(pb_{{.Input.Kind}}_get_repeated_{{.ProtoType}}_field_as_json_array({{.Input.Name}}, field_number), '{{.SortKeyType}}'));
+		|END $$
+		|{{- end}}
+		|
+		|DROP FUNCTION IF EXISTS pb_{{.Input.Kind}}_distinct_repeated_{{.ProtoType}}_field $$
+		|CREATE FUNCTION pb_{{.Input.Kind}}_distinct_repeated_{{.ProtoType}}_field({{.Input.Name}} {{.Input.SqlType}}, field_number INT) RETURNS {{.Input.SqlType}} DETERMINISTIC
+		|BEGIN
+		|	RETURN _pb_{{.Input.Kind}}_rearrange_repeated_field({{.Input.Name}}, field_number, {{.Procedure.WireType}}, _pb_util_json_array_distinct_indexes(pb_{{.Input.Kind}}_get_repeated_{{.ProtoType}}_field_as_json_array({{.Input.Name}}, field_number)));
+		|END $$
+	`)
+
+	tmpl, err := template.New("repeatedFieldOperation").Parse(operationTemplateText)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, accessor := range accessors {
+		operation := &RepeatedFieldOperation{Accessor: accessor, SortKeyType: sortKeyTypes[accessor.ProtoType]}
+		if err := tmpl.Execute(os.Stdout, operation); err != nil {
+			panic(err)
+		}
+	}
+}
+
 func generateBulkAddFunctions() {
 	// Group accessors by type category
 	type BulkAccessor struct {
@@ -706,10 +971,6 @@ func generateBulkAddFunctions() {
 		|{{- if .SupportsPacked}}
 		|	DECLARE packed_data LONGBLOB DEFAULT '';
 		|	DECLARE temp_encoded LONGBLOB;
-		|	DECLARE new_element JSON;
-		|	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
-		|	DECLARE field_array JSON;
-		|	DECLARE next_index INT;
 		|{{- end}}
 		|
 		|	SET result = wire_json;
@@ -729,31 +990,7 @@ func generateBulkAddFunctions() {
 		|			SET i = i + 1;
 		|		END WHILE;
 		|
-		|		-- Get existing field array
-		|		SET field_array = JSON_EXTRACT(result, field_path);
-		|
-		|		-- Check if we can append to existing packed field
-		|		IF field_array IS NOT NULL AND JSON_LENGTH(field_array) > 0 THEN
-		|			-- Check if last element is packed (wire type 2)
-		|			IF JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].t')) = 2 THEN
-		|				-- Append to existing packed data
-		|				SET packed_data = CONCAT(
-		|					FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(field_array, CONCAT('$[', JSON_LENGTH(field_array) - 1, '].v')))),
-		|					packed_data
-		|				);
-		|				RETURN JSON_SET(result, CONCAT(field_path, '[', JSON_LENGTH(field_array) - 1, '].v'), TO_BASE64(packed_data));
-		|			END IF;
-		|		END IF;
-		|
-		|		-- Create new packed element
-		|		SET next_index = _pb_wire_json_get_next_index(result);
-		|		SET new_element = JSON_OBJECT('i', next_index, 'n', field_number, 't', 2, 'v', TO_BASE64(packed_data));
-		|
-		|		IF field_array IS NULL THEN
-		|			RETURN JSON_SET(result, field_path, JSON_ARRAY(new_element));
-		|		ELSE
-		|			RETURN JSON_ARRAY_APPEND(result, field_path, new_element);
-		|		END IF;
+		|		RETURN _pb_wire_json_append_packed_data(result, field_number, packed_data);
 		|	ELSE
 		|		-- Add unpacked elements
 		|		WHILE i < array_length DO
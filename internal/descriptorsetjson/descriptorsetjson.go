@@ -3,13 +3,21 @@ package descriptorsetjson
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/eiiches/mysql-protobuf-functions/internal/protonumberjson"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
-// TypeIndex represents a type reference with kind, file path, and type path
-type TypeIndex [3]interface{}
+// TypeIndex represents a type reference with kind, file path, type path and file syntax. Enum types also have a map of
+// their values.
+type TypeIndex []interface{}
+
+// EnumMap maps the numbers of enum values to their names ("n") and the names back to the numbers ("v")
+type EnumMap struct {
+	Names   map[string]string `json:"n"`
+	Numbers map[string]int32  `json:"v"`
+}
 
 // Result represents the complete descriptor set JSON structure
 type Result struct {
@@ -63,22 +71,26 @@ func buildTypeIndex(fileDescriptorSet *descriptorpb.FileDescriptorSet) map[strin
 		}
 
 		filePath := fmt.Sprintf("$[1].\"1\"[%d]", fileIndex)
+		fileSyntax := fileDesc.GetSyntax()
+		if fileSyntax == "" {
+			fileSyntax = "proto2"
+		}
 
 		// Process message types
 		for msgIndex, msgDesc := range fileDesc.MessageType {
 			msgPath := fmt.Sprintf("%s.\"4\"[%d]", filePath, msgIndex)
 			msgName := buildTypeName(filePackage, *msgDesc.Name)
-			index[msgName] = TypeIndex{11, filePath, msgPath} // TYPE_MESSAGE
+			index[msgName] = TypeIndex{11, filePath, msgPath, fileSyntax} // TYPE_MESSAGE
 
 			// Process nested types recursively
-			buildNestedTypes(index, msgDesc, msgName, msgPath, filePath)
+			buildNestedTypes(index, msgDesc, msgName, msgPath, filePath, fileSyntax)
 		}
 
 		// Process enum types
 		for enumIndex, enumDesc := range fileDesc.EnumType {
 			enumPath := fmt.Sprintf("%s.\"5\"[%d]", filePath, enumIndex)
 			enumName := buildTypeName(filePackage, *enumDesc.Name)
-			index[enumName] = TypeIndex{14, filePath, enumPath} // TYPE_ENUM
+			index[enumName] = TypeIndex{14, filePath, enumPath, fileSyntax, buildEnumMap(enumDesc)} // TYPE_ENUM
 		}
 	}
 
@@ -86,23 +98,39 @@ func buildTypeIndex(fileDescriptorSet *descriptorpb.FileDescriptorSet) map[strin
 }
 
 // buildNestedTypes recursively processes nested message and enum types
-func buildNestedTypes(index map[string]TypeIndex, msgDesc *descriptorpb.DescriptorProto, parentName, parentPath, filePath string) {
+func buildNestedTypes(index map[string]TypeIndex, msgDesc *descriptorpb.DescriptorProto, parentName, parentPath, filePath, fileSyntax string) {
 	// Process nested message types
 	for nestedMsgIndex, nestedMsgDesc := range msgDesc.NestedType {
 		nestedMsgPath := fmt.Sprintf("%s.\"3\"[%d]", parentPath, nestedMsgIndex)
 		nestedMsgName := parentName + "." + *nestedMsgDesc.Name
-		index[nestedMsgName] = TypeIndex{11, filePath, nestedMsgPath} // TYPE_MESSAGE
+		index[nestedMsgName] = TypeIndex{11, filePath, nestedMsgPath, fileSyntax} // TYPE_MESSAGE
 
 		// Recursively process further nested types
-		buildNestedTypes(index, nestedMsgDesc, nestedMsgName, nestedMsgPath, filePath)
+		buildNestedTypes(index, nestedMsgDesc, nestedMsgName, nestedMsgPath, filePath, fileSyntax)
 	}
 
 	// Process nested enum types
 	for nestedEnumIndex, nestedEnumDesc := range msgDesc.EnumType {
 		nestedEnumPath := fmt.Sprintf("%s.\"4\"[%d]", parentPath, nestedEnumIndex)
 		nestedEnumName := parentName + "." + *nestedEnumDesc.Name
-		index[nestedEnumName] = TypeIndex{14, filePath, nestedEnumPath} // TYPE_ENUM
+		index[nestedEnumName] = TypeIndex{14, filePath, nestedEnumPath, fileSyntax, buildEnumMap(nestedEnumDesc)} // TYPE_ENUM
+	}
+}
+
+// buildEnumMap maps the values of an enum in both directions. When several names share a number (allow_alias), the
+// first one is used for the number, as in protojson.
+func buildEnumMap(enumDesc *descriptorpb.EnumDescriptorProto) EnumMap {
+	enumMap := EnumMap{Names: make(map[string]string), Numbers: make(map[string]int32)}
+	for _, valueDesc := range enumDesc.Value {
+		number := strconv.Itoa(int(valueDesc.GetNumber()))
+		if _, ok := enumMap.Names[number]; !ok {
+			enumMap.Names[number] = valueDesc.GetName()
+		}
+		if _, ok := enumMap.Numbers[valueDesc.GetName()]; !ok {
+			enumMap.Numbers[valueDesc.GetName()] = valueDesc.GetNumber()
+		}
 	}
+	return enumMap
 }
 
 // buildTypeName constructs a fully-qualified type name
@@ -65,12 +65,16 @@ func TestToJson(t *testing.T) {
 			// Verify TypeIndex structure
 			typeIndex, ok := typeIndexData[expectedType].([]interface{})
 			g.Expect(ok).To(BeTrue(), "type index should be array for %s", expectedType)
-			g.Expect(typeIndex).To(HaveLen(3), "type index should have 3 elements for %s", expectedType)
 
 			// Verify kind (first element)
 			kind, ok := typeIndex[0].(float64)
 			g.Expect(ok).To(BeTrue(), "kind should be number for %s", expectedType)
 			g.Expect(kind).To(SatisfyAny(Equal(float64(11)), Equal(float64(14))), "kind should be 11 (message) or 14 (enum) for %s", expectedType)
+			if kind == 11 {
+				g.Expect(typeIndex).To(HaveLen(4), "message type index should have 4 elements for %s", expectedType)
+			} else {
+				g.Expect(typeIndex).To(HaveLen(5), "enum type index should have 5 elements for %s", expectedType)
+			}
 
 			// Verify file path (second element)
 			filePath, ok := typeIndex[1].(string)
@@ -81,6 +85,9 @@ func TestToJson(t *testing.T) {
 			typePath, ok := typeIndex[2].(string)
 			g.Expect(ok).To(BeTrue(), "type path should be string for %s", expectedType)
 			g.Expect(typePath).To(ContainSubstring(filePath), "type path should contain file path for %s", expectedType)
+
+			// Verify syntax (fourth element)
+			g.Expect(typeIndex[3]).To(Equal("proto2"), "syntax of descriptor.proto for %s", expectedType)
 		}
 	})
 
@@ -246,6 +253,10 @@ func TestToJsonTree(t *testing.T) {
 									Name:   proto.String("INACTIVE"),
 									Number: proto.Int32(2),
 								},
+								{
+									Name:   proto.String("ENABLED"), // alias of ACTIVE
+									Number: proto.Int32(1),
+								},
 							},
 						},
 					},
@@ -265,6 +276,13 @@ func TestToJsonTree(t *testing.T) {
 		g.Expect(statusIndex[0]).To(Equal(14)) // TYPE_ENUM
 		g.Expect(statusIndex[1]).To(Equal("$[1].\"1\"[0]"))
 		g.Expect(statusIndex[2]).To(Equal("$[1].\"1\"[0].\"5\"[0]"))
+		g.Expect(statusIndex[3]).To(Equal("proto2"))
+
+		// Verify enum values, where the first name is used for a number with aliases
+		g.Expect(statusIndex[4]).To(Equal(EnumMap{
+			Names:   map[string]string{"1": "ACTIVE", "2": "INACTIVE"},
+			Numbers: map[string]int32{"ACTIVE": 1, "INACTIVE": 2, "ENABLED": 1},
+		}))
 	})
 
 	t.Run("with multiple files", func(t *testing.T) {
@@ -14,15 +14,36 @@ func EqualJson(expectedJson string) *EqualJsonMatcher {
 type EqualJsonMatcher struct {
 	expectedJson string
 	floatEqualFn func(a, b float64) bool
+	numberTextFn func(expected string) string
 }
 
 func (m *EqualJsonMatcher) WithFloatEqualFn(floatEqualFn func(a, b float64) bool) *EqualJsonMatcher {
 	return &EqualJsonMatcher{
 		expectedJson: m.expectedJson,
 		floatEqualFn: floatEqualFn,
+		numberTextFn: m.numberTextFn,
 	}
 }
 
+// WithNumberTextFn makes the matcher compare numbers by their text instead of
+// their value. Each number in the expected JSON is passed through
+// numberTextFn before it is compared with the actual text.
+func (m *EqualJsonMatcher) WithNumberTextFn(numberTextFn func(expected string) string) *EqualJsonMatcher {
+	return &EqualJsonMatcher{
+		expectedJson: m.expectedJson,
+		floatEqualFn: m.floatEqualFn,
+		numberTextFn: numberTextFn,
+	}
+}
+
+func (m *EqualJsonMatcher) unmarshal(data string, v interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	if m.numberTextFn != nil {
+		decoder.UseNumber()
+	}
+	return decoder.Decode(v)
+}
+
 func (m *EqualJsonMatcher) Match(actual interface{}) (success bool, err error) {
 	if actual == nil {
 		return false, nil
@@ -42,11 +63,11 @@ func (m *EqualJsonMatcher) Match(actual interface{}) (success bool, err error) {
 	var actualData interface{}
 	var expectedData interface{}
 
-	if err := json.Unmarshal([]byte(actualJson), &actualData); err != nil {
+	if err := m.unmarshal(actualJson, &actualData); err != nil {
 		return false, fmt.Errorf("error unmarshalling actual JSON: %w", err)
 	}
 
-	if err := json.Unmarshal([]byte(m.expectedJson), &expectedData); err != nil {
+	if err := m.unmarshal(m.expectedJson, &expectedData); err != nil {
 		return false, fmt.Errorf("error unmarshalling expected JSON: %w", err)
 	}
 
@@ -63,6 +84,11 @@ func (m *EqualJsonMatcher) deepEqual(actual, expected interface{}) bool {
 		return false
 	}
 
+	if actualNumber, ok := actual.(json.Number); ok {
+		expectedNumber, ok := expected.(json.Number)
+		return ok && string(actualNumber) == m.numberTextFn(string(expectedNumber))
+	}
+
 	actualValue := reflect.ValueOf(actual)
 	expectedValue := reflect.ValueOf(expected)
 
@@ -196,10 +222,10 @@ func (m *EqualJsonMatcher) findMismatchPath(actual interface{}) string {
 	var actualData interface{}
 	var expectedData interface{}
 
-	if err := json.Unmarshal([]byte(actualJson), &actualData); err != nil {
+	if err := m.unmarshal(actualJson, &actualData); err != nil {
 		return ""
 	}
-	if err := json.Unmarshal([]byte(m.expectedJson), &expectedData); err != nil {
+	if err := m.unmarshal(m.expectedJson, &expectedData); err != nil {
 		return ""
 	}
 
@@ -300,7 +326,7 @@ func (m *EqualJsonMatcher) formatJson(value interface{}) string {
 
 	// Try to pretty-print the JSON
 	var parsed interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &parsed); err == nil {
+	if err := m.unmarshal(jsonStr, &parsed); err == nil {
 		if formatted, err := json.MarshalIndent(parsed, "", "  "); err == nil {
 			return string(formatted)
 		}
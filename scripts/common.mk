@@ -3,6 +3,11 @@ test: purge reload ensure-test-database
 	go test ./internal/...
 	go test ./tests -database "root@tcp($(MYSQL_HOST):$(MYSQL_PORT))/$(MYSQL_DATABASE)" -fuzz-iterations 20 $${GO_TEST_FLAGS:-}
 
+# Runs the Benchmark* functions in tests/ matching BENCH, e.g. make benchmark BENCH=BenchmarkWire
+.PHONY: benchmark
+benchmark: purge reload ensure-test-database
+	go test ./tests -run '^$$' -bench '$(or $(BENCH),.)' -database "root@tcp($(MYSQL_HOST):$(MYSQL_PORT))/$(MYSQL_DATABASE)" $${GO_TEST_FLAGS:-}
+
 .PHONY: build
 build: build/protobuf.sql build/protobuf-json.sql build/protobuf-descriptor.sql protoc-gen-descriptor_set_json mysql-coverage
 
@@ -32,6 +37,22 @@ build/protobuf-json.sql: src/protobuf-json.sql scripts/common.mk
 	cat src/protobuf-json.sql >> $@.tmp
 	echo >> $@.tmp
 	cat src/protobuf-json-v2.sql >> $@.tmp
+	echo >> $@.tmp
+	cat src/protobuf-text.sql >> $@.tmp
+	echo >> $@.tmp
+	cat src/protobuf-validate.sql >> $@.tmp
+	echo >> $@.tmp
+	cat src/protobuf-field-mask.sql >> $@.tmp
+	echo >> $@.tmp
+	cat src/protobuf-merge.sql >> $@.tmp
+	echo >> $@.tmp
+	cat src/protobuf-diff.sql >> $@.tmp
+	echo >> $@.tmp
+	cat src/protobuf-canonical.sql >> $@.tmp
+	echo >> $@.tmp
+	cat src/protobuf-unknown-fields.sql >> $@.tmp
+	echo >> $@.tmp
+	cat src/protobuf-explode.sql >> $@.tmp
 	mv $@.tmp $@
 
 .PHONY: build/protobuf-descriptor.sql
@@ -0,0 +1,357 @@
+DELIMITER $$
+
+-- Helper function to normalize a varint to the value written by protobuf serializers for the field type.
+-- Parsers truncate 32-bit values and sign-extend int32 and enum values, so differently encoded varints can decode to the same value.
+DROP FUNCTION IF EXISTS _pb_util_canonicalize_varint $$
+CREATE FUNCTION _pb_util_canonicalize_varint(value BIGINT UNSIGNED, field_type INT) RETURNS BIGINT UNSIGNED DETERMINISTIC
+BEGIN
+	DECLARE low32 BIGINT UNSIGNED;
+
+	SET low32 = value & 0xFFFFFFFF;
+	CASE
+	WHEN field_type = 8 THEN -- bool
+		RETURN IF(value <> 0, 1, 0);
+	WHEN field_type IN (5, 14) THEN -- int32, enum
+		RETURN IF(low32 > 0x7FFFFFFF, low32 | 0xFFFFFFFF00000000, low32);
+	WHEN field_type IN (13, 17) THEN -- uint32, sint32
+		RETURN low32;
+	ELSE
+		RETURN value;
+	END CASE;
+END $$
+
+-- Helper procedure to sort map entries (wire elements) by key: numerically for integer and bool keys, and by UTF-8 bytes for string keys
+DROP PROCEDURE IF EXISTS _pb_wire_json_sort_map_entries $$
+CREATE PROCEDURE _pb_wire_json_sort_map_entries(IN elements JSON, IN key_type INT, OUT result JSON)
+BEGIN
+	DECLARE done BOOLEAN DEFAULT FALSE;
+	DECLARE keyed_elements JSON;
+	DECLARE element JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE key_json JSON;
+
+	DECLARE element_cursor CURSOR FOR
+		SELECT e
+		FROM JSON_TABLE(
+			keyed_elements,
+			'$[*]' COLUMNS (
+				n DECIMAL(20, 0) PATH '$.n',
+				s LONGTEXT PATH '$.s',
+				e JSON PATH '$.e'
+			)
+		) jt
+		ORDER BY n, s;
+
+	DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = TRUE;
+
+	SET keyed_elements = JSON_ARRAY();
+	SET element_count = JSON_LENGTH(elements);
+	WHILE element_index < element_count DO
+		SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+		CALL _pb_wire_json_get_primitive_field_as_json(pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')))), 1, key_type, FALSE, FALSE, TRUE, key_json);
+		CASE key_type
+		WHEN 9 THEN -- string
+			SET keyed_elements = JSON_ARRAY_APPEND(keyed_elements, '$', JSON_OBJECT('s', HEX(JSON_UNQUOTE(key_json)), 'e', element));
+		WHEN 8 THEN -- bool
+			SET keyed_elements = JSON_ARRAY_APPEND(keyed_elements, '$', JSON_OBJECT('n', IF(key_json = CAST('true' AS JSON), 1, 0), 'e', element));
+		ELSE
+			SET keyed_elements = JSON_ARRAY_APPEND(keyed_elements, '$', JSON_OBJECT('n', key_json, 'e', element));
+		END CASE;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	SET result = JSON_ARRAY();
+	OPEN element_cursor;
+	read_loop: LOOP
+		FETCH element_cursor INTO element;
+		IF done THEN
+			LEAVE read_loop;
+		END IF;
+		SET result = JSON_ARRAY_APPEND(result, '$', element);
+	END LOOP;
+	CLOSE element_cursor;
+END $$
+
+-- Helper procedure to encode all values of a repeated scalar field, packed or not, as the payload of a single packed element
+DROP PROCEDURE IF EXISTS _pb_wire_json_pack_elements $$
+CREATE PROCEDURE _pb_wire_json_pack_elements(IN elements JSON, IN field_type INT, IN field_name TEXT, OUT payload LONGBLOB)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE expected_wire_type INT;
+	DECLARE element JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE wire_type INT;
+	DECLARE packed_values LONGBLOB;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE encoded LONGBLOB;
+
+	SET expected_wire_type = _pb_field_type_to_wire_type(field_type);
+	SET payload = _binary '';
+	SET element_count = JSON_LENGTH(elements);
+
+	WHILE element_index < element_count DO
+		SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+		SET wire_type = JSON_EXTRACT(element, '$.t');
+
+		IF wire_type = 2 THEN -- LEN (packed)
+			SET packed_values = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
+			IF expected_wire_type = 0 THEN
+				-- Varints are re-encoded so that they are normalized
+				SET packed_offset = 0;
+				WHILE packed_offset < LENGTH(packed_values) DO
+					CALL _pb_wire_read_varint_as_uint64(SUBSTRING(packed_values, packed_offset + 1, 10), packed_offset, uint_value);
+					CALL _pb_wire_write_varint(_pb_util_canonicalize_varint(uint_value, field_type), encoded);
+					SET payload = CONCAT(payload, encoded);
+				END WHILE;
+			ELSE
+				SET payload = CONCAT(payload, packed_values);
+			END IF;
+		ELSEIF wire_type = expected_wire_type THEN
+			SET uint_value = CAST(JSON_EXTRACT(element, '$.v') AS UNSIGNED);
+			CASE wire_type
+			WHEN 0 THEN CALL _pb_wire_write_varint(_pb_util_canonicalize_varint(uint_value, field_type), encoded);
+			WHEN 1 THEN CALL _pb_wire_write_i64(uint_value, encoded);
+			WHEN 5 THEN CALL _pb_wire_write_i32(uint_value, encoded);
+			END CASE;
+			SET payload = CONCAT(payload, encoded);
+		ELSE
+			CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', 'pb_message_canonicalize', CONCAT('unexpected wire type ', wire_type, ' for field `', field_name, '`'));
+		END IF;
+
+		SET element_index = element_index + 1;
+	END WHILE;
+END $$
+
+-- Helper procedure to convert a message into its canonical form. message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_canonicalize $$
+CREATE PROCEDURE _pb_message_canonicalize(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN message_depth INT, OUT result LONGBLOB)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE wire_json JSON;
+	DECLARE result_wire_json JSON;
+	DECLARE oneof_winners JSON;
+	DECLARE known_field_numbers JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE oneof_index INT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE has_field_presence BOOLEAN;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE is_map BOOLEAN;
+
+	-- Wire elements
+	DECLARE elements JSON;
+	DECLARE element JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE canonical_elements JSON;
+	DECLARE wire_type INT;
+	DECLARE value JSON;
+	DECLARE sub_message LONGBLOB;
+	DECLARE payload LONGBLOB;
+
+	-- Output
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT DEFAULT 0;
+	DECLARE next_index INT DEFAULT 0;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_canonicalize');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_canonicalize', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET wire_json = pb_message_to_wire_json(buf);
+	SET oneof_winners = _pb_wire_json_get_oneof_winners(message_descriptor, wire_json);
+	SET result_wire_json = JSON_OBJECT();
+	SET known_field_numbers = JSON_ARRAY();
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	l1: WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET field_index = field_index + 1;
+
+		SET known_field_numbers = JSON_ARRAY_APPEND(known_field_numbers, '$', field_number);
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		IF elements IS NULL THEN
+			ITERATE l1;
+		END IF;
+
+		IF field_type = 10 THEN -- TYPE_GROUP
+			SET message_text = CONCAT('pb_message_canonicalize: unsupported field_type `', field_type, '` for field `', field_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		-- Members of a oneof superseded by a later member are dropped
+		IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+			IF JSON_EXTRACT(oneof_winners, CONCAT('$."', oneof_index, '"')) <> field_number THEN
+				ITERATE l1;
+			END IF;
+		END IF;
+
+		SET has_field_presence =
+			(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+			OR (syntax = 'proto3'
+				AND (
+					(field_label = 1 AND proto3_optional) -- proto3 optional
+					OR (field_label <> 3 AND field_type = 11) -- message fields
+					OR (oneof_index IS NOT NULL) -- oneof fields
+				));
+
+		SET is_map = FALSE;
+		IF field_type = 11 AND field_label = 3 THEN -- repeated TYPE_MESSAGE
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		SET canonical_elements = JSON_ARRAY();
+
+		IF field_type = 11 AND field_label = 3 THEN -- repeated TYPE_MESSAGE
+			IF is_map THEN
+				SET elements = _pb_wire_json_merge_map_entries(elements, JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"'));
+			END IF;
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			WHILE element_index < element_count DO
+				SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+				CALL _pb_message_canonicalize(descriptor_set_json, field_type_name, FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v'))), message_depth + 1, sub_message);
+				SET canonical_elements = JSON_ARRAY_APPEND(canonical_elements, '$', JSON_OBJECT('i', 0, 'n', field_number, 't', 2, 'v', TO_BASE64(sub_message)));
+				SET element_index = element_index + 1;
+			END WHILE;
+			IF is_map THEN
+				CALL _pb_wire_json_sort_map_entries(canonical_elements, JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"'), canonical_elements);
+			END IF;
+
+		ELSEIF field_type = 11 THEN -- TYPE_MESSAGE
+			-- Occurrences of a singular message field are merged
+			CALL _pb_message_canonicalize(descriptor_set_json, field_type_name, _pb_wire_json_get_merged_message_field(wire_json, field_number), message_depth + 1, sub_message);
+			SET canonical_elements = JSON_ARRAY(JSON_OBJECT('i', 0, 'n', field_number, 't', 2, 'v', TO_BASE64(sub_message)));
+
+		ELSEIF field_label = 3 AND field_type IN (9, 12) THEN -- repeated string, bytes
+			SET canonical_elements = elements;
+
+		ELSEIF field_label = 3 THEN
+			-- Repeated scalar fields are always packed
+			CALL _pb_wire_json_pack_elements(elements, field_type, field_name, payload);
+			IF LENGTH(payload) > 0 THEN
+				SET canonical_elements = JSON_ARRAY(JSON_OBJECT('i', 0, 'n', field_number, 't', 2, 'v', TO_BASE64(payload)));
+			END IF;
+
+		ELSE
+			-- The last occurrence of a scalar wins
+			SET element = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, ']'));
+			SET wire_type = JSON_EXTRACT(element, '$.t');
+			IF wire_type <> _pb_field_type_to_wire_type(field_type) THEN
+				CALL _pb_signal_error('PB_WIRE_TYPE_MISMATCH', 'pb_message_canonicalize', CONCAT('unexpected wire type ', wire_type, ' for field `', field_name, '`'));
+			END IF;
+			SET value = JSON_EXTRACT(element, '$.v');
+			IF wire_type = 0 THEN
+				SET value = CAST(_pb_util_canonicalize_varint(CAST(value AS UNSIGNED), field_type) AS JSON);
+			END IF;
+			-- Without presence, zero values are the same as not being set
+			IF has_field_presence OR JSON_UNQUOTE(value) NOT IN ('0', '') THEN
+				SET canonical_elements = JSON_ARRAY(JSON_OBJECT('i', 0, 'n', field_number, 't', wire_type, 'v', value));
+			END IF;
+		END IF;
+
+		IF JSON_LENGTH(canonical_elements) > 0 THEN
+			SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), canonical_elements);
+		END IF;
+	END WHILE;
+
+	-- Unknown fields are kept as they are
+	SET field_numbers = JSON_KEYS(wire_json);
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		IF NOT JSON_CONTAINS(known_field_numbers, CAST(field_number AS JSON)) THEN
+			SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"')));
+		END IF;
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+
+	-- Fields are written in ascending order of field numbers. JSON_KEYS() returns shorter keys first, so numeric keys come out in numeric order.
+	SET field_numbers = JSON_KEYS(result_wire_json);
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	SET field_number_index = 0;
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		SET elements = JSON_EXTRACT(result_wire_json, CONCAT('$."', field_number, '"'));
+		SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), _pb_wire_json_renumber_elements(elements, next_index));
+		SET next_index = next_index + JSON_LENGTH(elements);
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(result_wire_json);
+END $$
+
+-- Converts a message into a canonical form, so that semantically equal messages are serialized to the same bytes
+DROP FUNCTION IF EXISTS pb_message_canonicalize $$
+CREATE FUNCTION pb_message_canonicalize(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_canonicalize(descriptor_set_json, type_name, message, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Compares two messages for semantic equality by comparing their canonical forms
+DROP FUNCTION IF EXISTS pb_message_equals $$
+CREATE FUNCTION pb_message_equals(descriptor_set_json JSON, type_name TEXT, a LONGBLOB, b LONGBLOB) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	IF a IS NULL OR b IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	RETURN pb_message_canonicalize(descriptor_set_json, type_name, a) = pb_message_canonicalize(descriptor_set_json, type_name, b);
+END $$
+
+-- Returns a stable hash of the canonical form of a message, as a hex-encoded SHA-256 digest
+DROP FUNCTION IF EXISTS pb_message_fingerprint $$
+CREATE FUNCTION pb_message_fingerprint(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS CHAR(64) DETERMINISTIC
+BEGIN
+	RETURN SHA2(pb_message_canonicalize(descriptor_set_json, type_name, message), 256);
+END $$
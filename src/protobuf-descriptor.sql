@@ -18,6 +18,7 @@ CREATE PROCEDURE _pb_build_nested_types(
 	IN parent_name TEXT,
 	IN parent_path TEXT,
 	IN file_path TEXT,
+	IN file_syntax TEXT,
 	INOUT type_index JSON
 )
 proc: BEGIN
@@ -49,12 +50,12 @@ proc: BEGIN
 			SET nested_msg_path = CONCAT(parent_path, '."3"[', nested_msg_index, ']');
 			SET nested_type_name = CONCAT(parent_name, '.', nested_msg_name);
 			
-			-- Add to type index: [kind=11, file_path, type_path]
-			SET type_entry = JSON_ARRAY(11, file_path, nested_msg_path);
+			-- Add to type index: [kind=11, file_path, type_path, syntax]
+			SET type_entry = JSON_ARRAY(11, file_path, nested_msg_path, file_syntax);
 			SET type_index = JSON_SET(type_index, CONCAT('$."', nested_type_name, '"'), type_entry);
 			
 			-- Recursively process further nested types
-			CALL _pb_build_nested_types(nested_msg_descriptor, nested_type_name, nested_msg_path, file_path, type_index);
+			CALL _pb_build_nested_types(nested_msg_descriptor, nested_type_name, nested_msg_path, file_path, file_syntax, type_index);
 			
 			SET nested_msg_index = nested_msg_index + 1;
 		END WHILE;
@@ -73,8 +74,8 @@ proc: BEGIN
 			SET nested_enum_path = CONCAT(parent_path, '."4"[', nested_enum_index, ']');
 			SET nested_type_name = CONCAT(parent_name, '.', nested_enum_name);
 			
-			-- Add to type index: [kind=14, file_path, type_path]
-			SET type_entry = JSON_ARRAY(14, file_path, nested_enum_path);
+			-- Add to type index: [kind=14, file_path, type_path, syntax, enum_map]
+			SET type_entry = JSON_ARRAY(14, file_path, nested_enum_path, file_syntax, _pb_build_enum_map(nested_enum_descriptor));
 			SET type_index = JSON_SET(type_index, CONCAT('$."', nested_type_name, '"'), type_entry);
 			
 			SET nested_enum_index = nested_enum_index + 1;
@@ -93,6 +94,7 @@ BEGIN
 	DECLARE file_descriptor JSON;
 	DECLARE file_package TEXT;
 	DECLARE file_path TEXT;
+	DECLARE file_syntax TEXT;
 	DECLARE message_types JSON;
 	DECLARE enum_types JSON;
 	DECLARE msg_count INT DEFAULT 0;
@@ -123,6 +125,7 @@ BEGIN
 		SET file_descriptor = JSON_EXTRACT(files, CONCAT('$[', file_index, ']'));
 		SET file_package = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(file_descriptor, '$."2"')), ''); -- package field
 		SET file_path = CONCAT('$[1]."1"[', file_index, ']');
+		SET file_syntax = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(file_descriptor, '$."12"')), 'proto2'); -- syntax field
 		
 		-- Process message types (field 4 in FileDescriptorProto)
 		SET message_types = JSON_EXTRACT(file_descriptor, '$."4"');
@@ -137,12 +140,12 @@ BEGIN
 				SET message_path = CONCAT(file_path, '."4"[', msg_index, ']');
 				SET full_type_name = _pb_build_type_name(file_package, message_name);
 				
-				-- Add to type index: [kind=11, file_path, type_path]
-				SET type_entry = JSON_ARRAY(11, file_path, message_path);
+				-- Add to type index: [kind=11, file_path, type_path, syntax]
+				SET type_entry = JSON_ARRAY(11, file_path, message_path, file_syntax);
 				SET type_index = JSON_SET(type_index, CONCAT('$."', full_type_name, '"'), type_entry);
 				
 				-- Process nested types recursively
-				CALL _pb_build_nested_types(message_descriptor, full_type_name, message_path, file_path, type_index);
+				CALL _pb_build_nested_types(message_descriptor, full_type_name, message_path, file_path, file_syntax, type_index);
 				
 				SET msg_index = msg_index + 1;
 			END WHILE;
@@ -161,8 +164,8 @@ BEGIN
 				SET enum_path = CONCAT(file_path, '."5"[', enum_index, ']');
 				SET full_type_name = _pb_build_type_name(file_package, enum_name);
 				
-				-- Add to type index: [kind=14, file_path, type_path]
-				SET type_entry = JSON_ARRAY(14, file_path, enum_path);
+				-- Add to type index: [kind=14, file_path, type_path, syntax, enum_map]
+				SET type_entry = JSON_ARRAY(14, file_path, enum_path, file_syntax, _pb_build_enum_map(enum_descriptor));
 				SET type_index = JSON_SET(type_index, CONCAT('$."', full_type_name, '"'), type_entry);
 				
 				SET enum_index = enum_index + 1;
@@ -176,7 +179,7 @@ BEGIN
 END $$
 
 -- Public function to convert FileDescriptorSet LONGBLOB to descriptor set JSON
--- Returns a 2-element JSON array: [fileDescriptorSet, typeIndex]
+-- Returns a 3-element JSON array: [version, fileDescriptorSet, typeIndex]
 DROP FUNCTION IF EXISTS pb_build_descriptor_set_json $$
 CREATE FUNCTION pb_build_descriptor_set_json(file_descriptor_set_blob LONGBLOB) RETURNS JSON DETERMINISTIC
 BEGIN
@@ -0,0 +1,412 @@
+DELIMITER $$
+
+-- Helper function to format a map key as a path subscript accepted by pb_message_set_by_path()
+DROP FUNCTION IF EXISTS _pb_util_format_map_key_subscript $$
+CREATE FUNCTION _pb_util_format_map_key_subscript(map_key TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	RETURN CONCAT('["', REPLACE(REPLACE(map_key, '\\', '\\\\'), '"', '\\"'), '"]');
+END $$
+
+-- Helper procedure to read a non-message field (or the value of a map entry) as JSON for pb_message_diff().
+-- Unlike _pb_enum_to_json(), enum values without a name are returned as numbers.
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_diff_value $$
+CREATE PROCEDURE _pb_wire_json_get_diff_value(IN descriptor_set_json JSON, IN wire_json JSON, IN field_number INT, IN field_type INT, IN field_type_name TEXT, IN is_repeated BOOLEAN, IN has_field_presence BOOLEAN, OUT result JSON)
+BEGIN
+	DECLARE enum_numbers JSON;
+	DECLARE enum_number INT;
+	DECLARE enum_json JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+
+	IF field_type = 14 THEN -- TYPE_ENUM
+		IF is_repeated THEN
+			SET enum_numbers = pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(enum_numbers);
+			SET result = JSON_ARRAY();
+			WHILE element_index < element_count DO
+				SET enum_number = JSON_EXTRACT(enum_numbers, CONCAT('$[', element_index, ']'));
+				CALL _pb_enum_to_json(descriptor_set_json, field_type_name, enum_number, enum_json);
+				SET result = JSON_ARRAY_APPEND(result, '$', COALESCE(enum_json, CAST(enum_number AS JSON)));
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSE
+			SET enum_number = pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0));
+			CALL _pb_enum_to_json(descriptor_set_json, field_type_name, enum_number, enum_json);
+			SET result = COALESCE(enum_json, CAST(enum_number AS JSON));
+		END IF;
+	ELSE
+		CALL _pb_wire_json_get_primitive_field_as_json(wire_json, field_number, field_type, is_repeated, has_field_presence, FALSE, result);
+	END IF;
+END $$
+
+-- Helper procedure to diff the entries of a map field by key. message_depth is the depth of the message containing the map.
+DROP PROCEDURE IF EXISTS _pb_message_diff_map $$
+CREATE PROCEDURE _pb_message_diff_map(IN descriptor_set_json JSON, IN map_entry_descriptor JSON, IN old_wire_json JSON, IN new_wire_json JSON, IN field_number INT, IN path TEXT, IN message_depth INT, INOUT diff JSON)
+BEGIN
+	DECLARE map_key_type INT;
+	DECLARE map_value_type INT;
+	DECLARE map_value_type_name TEXT;
+	DECLARE old_entries JSON;
+	DECLARE new_entries JSON;
+	DECLARE entries JSON;
+	DECLARE entry_wire_json JSON;
+	DECLARE entry_count INT;
+	DECLARE entry_index INT;
+	DECLARE map_key_json JSON;
+	DECLARE map_keys JSON;
+	DECLARE map_key TEXT;
+	DECLARE key_count INT;
+	DECLARE key_index INT DEFAULT 0;
+	DECLARE key_path TEXT;
+	DECLARE old_entry JSON;
+	DECLARE new_entry JSON;
+	DECLARE old_value JSON;
+	DECLARE new_value JSON;
+	DECLARE side INT DEFAULT 0;
+
+	SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+	SET map_value_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]."5"');
+	SET map_value_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_entry_descriptor, '$."2"[1]."6"'));
+
+	-- Index the entries of both messages by key. Later entries replace earlier ones with the same key.
+	WHILE side < 2 DO
+		SET entries = pb_wire_json_get_repeated_message_field_as_json_array(IF(side = 0, old_wire_json, new_wire_json), field_number);
+		SET entry_count = JSON_LENGTH(entries);
+		SET entry_index = 0;
+		SET map_keys = JSON_OBJECT();
+		WHILE entry_index < entry_count DO
+			SET entry_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(entries, CONCAT('$[', entry_index, ']')))));
+			CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 1, map_key_type, FALSE, FALSE, FALSE, map_key_json);
+			SET map_keys = JSON_SET(map_keys, CONCAT('$.', JSON_QUOTE(JSON_UNQUOTE(map_key_json))), entry_wire_json);
+			SET entry_index = entry_index + 1;
+		END WHILE;
+		IF side = 0 THEN
+			SET old_entries = map_keys;
+		ELSE
+			SET new_entries = map_keys;
+		END IF;
+		SET side = side + 1;
+	END WHILE;
+
+	-- Visit the keys of both messages
+	SET map_keys = JSON_KEYS(JSON_MERGE_PATCH(old_entries, new_entries));
+	SET key_count = JSON_LENGTH(map_keys);
+
+	WHILE key_index < key_count DO
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(map_keys, CONCAT('$[', key_index, ']')));
+		SET key_path = CONCAT(path, _pb_util_format_map_key_subscript(map_key));
+		SET old_entry = JSON_EXTRACT(old_entries, CONCAT('$.', JSON_QUOTE(map_key)));
+		SET new_entry = JSON_EXTRACT(new_entries, CONCAT('$.', JSON_QUOTE(map_key)));
+
+		IF map_value_type = 11 THEN -- TYPE_MESSAGE
+			IF new_entry IS NULL THEN
+				CALL _pb_message_to_json(descriptor_set_json, map_value_type_name, pb_wire_json_get_message_field(old_entry, 2, _binary ''), FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), old_value);
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'remove', old_value, NULL));
+			ELSE
+				IF old_entry IS NULL THEN
+					SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'add', NULL, JSON_OBJECT()));
+				END IF;
+				CALL _pb_message_diff(descriptor_set_json, map_value_type_name,
+					COALESCE(pb_wire_json_get_message_field(old_entry, 2, NULL), _binary ''),
+					pb_wire_json_get_message_field(new_entry, 2, _binary ''),
+					key_path, message_depth + 2, diff); -- the map entry counts as a message
+			END IF;
+		ELSE
+			SET old_value = NULL;
+			SET new_value = NULL;
+			IF old_entry IS NOT NULL THEN
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, old_entry, 2, map_value_type, map_value_type_name, FALSE, FALSE, old_value);
+			END IF;
+			IF new_entry IS NOT NULL THEN
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, new_entry, 2, map_value_type, map_value_type_name, FALSE, FALSE, new_value);
+			END IF;
+
+			IF old_value IS NULL THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'add', NULL, new_value));
+			ELSEIF new_value IS NULL THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'remove', old_value, NULL));
+			ELSEIF old_value <> new_value THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(key_path, 'replace', old_value, new_value));
+			END IF;
+		END IF;
+
+		SET key_index = key_index + 1;
+	END WHILE;
+END $$
+
+-- Helper procedure to append the differences between two messages of the same type to diff.
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_diff $$
+CREATE PROCEDURE _pb_message_diff(IN descriptor_set_json JSON, IN full_type_name TEXT, IN old_message LONGBLOB, IN new_message LONGBLOB, IN path TEXT, IN message_depth INT, INOUT diff JSON)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE old_wire_json JSON;
+	DECLARE new_wire_json JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE oneof_index INT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE is_repeated BOOLEAN;
+	DECLARE is_map BOOLEAN;
+	DECLARE has_field_presence BOOLEAN;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE field_path TEXT;
+	DECLARE element_path TEXT;
+
+	-- Values
+	DECLARE old_value JSON;
+	DECLARE new_value JSON;
+	DECLARE old_values JSON;
+	DECLARE new_values JSON;
+	DECLARE default_value JSON;
+	DECLARE old_sub_message LONGBLOB;
+	DECLARE new_sub_message LONGBLOB;
+	DECLARE old_count INT;
+	DECLARE new_count INT;
+	DECLARE element_index INT;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_diff');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_diff', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET old_wire_json = pb_message_to_wire_json(old_message);
+	SET new_wire_json = pb_message_to_wire_json(new_message);
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET field_index = field_index + 1;
+
+		SET is_repeated = (field_label = 3); -- LABEL_REPEATED
+		SET field_path = _pb_util_join_field_path(path, field_name);
+
+		SET is_map = FALSE;
+		IF field_type = 11 AND is_repeated THEN -- TYPE_MESSAGE
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		SET has_field_presence =
+			(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+			OR (syntax = 'proto3'
+				AND (
+					(field_label = 1 AND proto3_optional) -- proto3 optional
+					OR (field_label <> 3 AND field_type = 11) -- message fields
+					OR (oneof_index IS NOT NULL) -- oneof fields
+				));
+
+		IF field_type = 10 THEN -- TYPE_GROUP
+			SET message_text = CONCAT('pb_message_diff: unsupported field_type `', field_type, '` for field `', field_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+
+		ELSEIF is_map THEN
+			CALL _pb_message_diff_map(descriptor_set_json, map_entry_descriptor, old_wire_json, new_wire_json, field_number, field_path, message_depth, diff);
+
+		ELSEIF is_repeated THEN
+			IF field_type = 11 THEN -- TYPE_MESSAGE
+				SET old_count = pb_wire_json_get_repeated_message_field_count(old_wire_json, field_number);
+				SET new_count = pb_wire_json_get_repeated_message_field_count(new_wire_json, field_number);
+			ELSE
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, old_wire_json, field_number, field_type, field_type_name, TRUE, FALSE, old_values);
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, new_wire_json, field_number, field_type, field_type_name, TRUE, FALSE, new_values);
+				SET old_count = JSON_LENGTH(old_values);
+				SET new_count = JSON_LENGTH(new_values);
+			END IF;
+
+			-- Elements are compared by index
+			SET element_index = 0;
+			WHILE element_index < GREATEST(old_count, new_count) DO
+				SET element_path = CONCAT(field_path, '[', element_index, ']');
+				IF field_type = 11 THEN -- TYPE_MESSAGE
+					IF element_index >= old_count THEN
+						SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(element_path, 'add', NULL, JSON_OBJECT()));
+						SET old_sub_message = _binary '';
+					ELSE
+						SET old_sub_message = pb_wire_json_get_repeated_message_field_element(old_wire_json, field_number, element_index);
+					END IF;
+					IF element_index < new_count THEN
+						CALL _pb_message_diff(descriptor_set_json, field_type_name, old_sub_message, pb_wire_json_get_repeated_message_field_element(new_wire_json, field_number, element_index), element_path, message_depth + 1, diff);
+					END IF;
+				ELSE
+					SET old_value = JSON_EXTRACT(old_values, CONCAT('$[', element_index, ']'));
+					SET new_value = JSON_EXTRACT(new_values, CONCAT('$[', element_index, ']'));
+					IF old_value IS NULL THEN
+						SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(element_path, 'add', NULL, new_value));
+					ELSEIF new_value IS NOT NULL AND old_value <> new_value THEN
+						SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(element_path, 'replace', old_value, new_value));
+					END IF;
+				END IF;
+				SET element_index = element_index + 1;
+			END WHILE;
+
+			-- Surplus elements are removed from the end, so that the entries can be applied in order
+			SET element_index = old_count - 1;
+			WHILE element_index >= new_count DO
+				IF field_type = 11 THEN -- TYPE_MESSAGE
+					CALL _pb_message_to_json(descriptor_set_json, field_type_name, pb_wire_json_get_repeated_message_field_element(old_wire_json, field_number, element_index), FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), old_value);
+				ELSE
+					SET old_value = JSON_EXTRACT(old_values, CONCAT('$[', element_index, ']'));
+				END IF;
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(CONCAT(field_path, '[', element_index, ']'), 'remove', old_value, NULL));
+				SET element_index = element_index - 1;
+			END WHILE;
+
+		ELSEIF field_type = 11 THEN -- TYPE_MESSAGE
+			-- Occurrences of a singular message field are merged
+			SET old_sub_message = _pb_wire_json_get_merged_message_field(old_wire_json, field_number);
+			SET new_sub_message = _pb_wire_json_get_merged_message_field(new_wire_json, field_number);
+			IF new_sub_message IS NULL THEN
+				IF old_sub_message IS NOT NULL THEN
+					CALL _pb_message_to_json(descriptor_set_json, field_type_name, old_sub_message, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), old_value);
+					SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'remove', old_value, NULL));
+				END IF;
+			ELSE
+				IF old_sub_message IS NULL THEN
+					SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'add', NULL, JSON_OBJECT()));
+				END IF;
+				CALL _pb_message_diff(descriptor_set_json, field_type_name, COALESCE(old_sub_message, _binary ''), new_sub_message, field_path, message_depth + 1, diff);
+			END IF;
+
+		ELSE
+			CALL _pb_wire_json_get_diff_value(descriptor_set_json, old_wire_json, field_number, field_type, field_type_name, FALSE, has_field_presence, old_value);
+			CALL _pb_wire_json_get_diff_value(descriptor_set_json, new_wire_json, field_number, field_type, field_type_name, FALSE, has_field_presence, new_value);
+
+			-- Without presence, the default value means the field is not set
+			IF NOT has_field_presence THEN
+				CALL _pb_wire_json_get_diff_value(descriptor_set_json, JSON_OBJECT(), field_number, field_type, field_type_name, FALSE, FALSE, default_value);
+				IF old_value = default_value THEN
+					SET old_value = NULL;
+				END IF;
+				IF new_value = default_value THEN
+					SET new_value = NULL;
+				END IF;
+			END IF;
+
+			IF old_value IS NULL THEN
+				IF new_value IS NOT NULL THEN
+					SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'add', NULL, new_value));
+				END IF;
+			ELSEIF new_value IS NULL THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'remove', old_value, NULL));
+			ELSEIF old_value <> new_value THEN
+				SET diff = JSON_ARRAY_APPEND(diff, '$', _pb_diff_entry(field_path, 'replace', old_value, new_value));
+			END IF;
+		END IF;
+	END WHILE;
+END $$
+
+-- Compares two messages of the same type and returns a JSON array of {path, op, old, new} entries
+DROP FUNCTION IF EXISTS pb_message_diff $$
+CREATE FUNCTION pb_message_diff(descriptor_set_json JSON, type_name TEXT, old_message LONGBLOB, new_message LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE diff JSON;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF old_message IS NULL OR new_message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET diff = JSON_ARRAY();
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_diff(descriptor_set_json, type_name, old_message, new_message, '', 1, diff);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN diff;
+END $$
+
+-- Applies a diff returned by pb_message_diff() to a message
+DROP FUNCTION IF EXISTS pb_message_patch $$
+CREATE FUNCTION pb_message_patch(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, diff JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE entry_count INT;
+	DECLARE entry_index INT DEFAULT 0;
+	DECLARE entry JSON;
+	DECLARE path TEXT;
+	DECLARE op TEXT;
+	DECLARE new_value JSON;
+	DECLARE segments JSON;
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL OR diff IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	SET entry_count = JSON_LENGTH(diff);
+	WHILE entry_index < entry_count DO
+		SET entry = JSON_EXTRACT(diff, CONCAT('$[', entry_index, ']'));
+		SET path = JSON_UNQUOTE(JSON_EXTRACT(entry, '$.path'));
+		SET op = JSON_UNQUOTE(JSON_EXTRACT(entry, '$.op'));
+		SET new_value = JSON_EXTRACT(entry, '$.new');
+
+		SET segments = _pb_util_parse_field_path(path);
+		IF JSON_LENGTH(segments) = 0 THEN
+			SET message_text = 'pb_message_patch: path must not be empty';
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		IF op = 'remove' THEN
+			CALL _pb_message_set_by_path(descriptor_set_json, type_name, message, path, segments, 0, NULL, TRUE, FALSE, result);
+		ELSEIF op = 'add' OR op = 'replace' THEN
+			-- Messages are added empty, and their fields are set by the entries that follow
+			IF JSON_TYPE(new_value) = 'OBJECT' THEN
+				IF JSON_LENGTH(new_value) > 0 THEN
+					SET message_text = CONCAT('pb_message_patch: message value at `', path, '` must be an empty object');
+					SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+				END IF;
+				SET new_value = JSON_QUOTE('');
+			END IF;
+			CALL _pb_message_set_by_path(descriptor_set_json, type_name, message, path, segments, 0, new_value, FALSE, op = 'add', result);
+		ELSE
+			SET message_text = CONCAT('pb_message_patch: unsupported op `', COALESCE(op, 'NULL'), '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET message = result;
+		SET entry_index = entry_index + 1;
+	END WHILE;
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+
+	RETURN message;
+END $$
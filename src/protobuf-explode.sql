@@ -0,0 +1,327 @@
+DELIMITER $$
+
+-- Helper function to quote an identifier, such as a table or column name, with backticks
+DROP FUNCTION IF EXISTS _pb_util_quote_identifier $$
+CREATE FUNCTION _pb_util_quote_identifier(identifier TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	RETURN CONCAT('`', REPLACE(identifier, '`', '``'), '`');
+END $$
+
+-- Helper procedure to add a row for a single element or map entry to the pb_exploded table.
+-- number_value holds the value as a JSON number (or the enum number), and json_value the value as in pb_message_to_json().
+DROP PROCEDURE IF EXISTS _pb_message_explode_add_row $$
+CREATE PROCEDURE _pb_message_explode_add_row(IN parent_id LONGBLOB, IN element_index INT, IN map_key TEXT, IN field_type INT, IN number_value JSON, IN json_value JSON, IN message_value LONGBLOB)
+BEGIN
+	INSERT INTO pb_exploded (parent_id, element_index, map_key, int_value, uint_value, double_value, bool_value, string_value, bytes_value, message_value, json_value)
+	VALUES (
+		parent_id,
+		element_index,
+		map_key,
+		IF(field_type IN (3, 5, 14, 15, 16, 17, 18), CAST(number_value AS SIGNED), NULL), -- int64, int32, enum, sfixed32, sfixed64, sint32, sint64
+		IF(field_type IN (4, 6, 7, 13), CAST(number_value AS UNSIGNED), NULL), -- uint64, fixed64, fixed32, uint32
+		IF(field_type IN (1, 2) AND JSON_TYPE(number_value) <> 'STRING', CAST(number_value AS DOUBLE), NULL), -- double, float; NaN and infinities are strings
+		IF(field_type = 8, json_value = CAST('true' AS JSON), NULL), -- bool
+		IF(field_type = 9, JSON_UNQUOTE(json_value), NULL), -- string
+		IF(field_type = 12, FROM_BASE64(JSON_UNQUOTE(json_value)), NULL), -- bytes
+		message_value,
+		json_value
+	);
+END $$
+
+-- Helper procedure to add the rows for the repeated or map field at the path of a single message to the pb_exploded table
+DROP PROCEDURE IF EXISTS _pb_message_explode_message $$
+CREATE PROCEDURE _pb_message_explode_message(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN path TEXT, IN segments JSON, IN parent_id LONGBLOB)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segment JSON;
+	DECLARE segment_count INT;
+	DECLARE segment_index INT DEFAULT 0;
+	DECLARE field_token TEXT;
+	DECLARE repeated_index INT;
+	DECLARE map_key TEXT;
+
+	DECLARE current_type_name TEXT;
+	DECLARE current_message LONGBLOB;
+	DECLARE message_descriptor JSON;
+	DECLARE field_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE utf8_validation TEXT;
+	DECLARE wire_json JSON;
+
+	-- Field properties
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE is_map BOOLEAN;
+
+	-- Map handling
+	DECLARE map_entry_descriptor JSON;
+	DECLARE map_key_type INT;
+	DECLARE map_value_field JSON;
+	DECLARE map_value_type INT;
+	DECLARE map_value_type_name TEXT;
+	DECLARE entry_wire_json JSON;
+	DECLARE found_entry LONGBLOB;
+	DECLARE map_key_json JSON;
+
+	-- Elements
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE number_values JSON;
+	DECLARE json_values JSON;
+	DECLARE number_value JSON;
+	DECLARE json_value JSON;
+	DECLARE message_value LONGBLOB;
+
+	SET current_type_name = full_type_name;
+	SET current_message = message;
+	SET segment_count = JSON_LENGTH(segments);
+
+	WHILE segment_index < segment_count DO
+		SET segment = JSON_EXTRACT(segments, CONCAT('$[', segment_index, ']'));
+		SET field_token = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.f'));
+		SET repeated_index = JSON_EXTRACT(segment, '$.i');
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.k'));
+
+		SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, current_type_name);
+		IF message_descriptor IS NULL THEN
+			CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_explode', CONCAT('message type `', current_type_name, '` not found in descriptor set'));
+		END IF;
+
+		SET field_descriptor = _pb_get_field_descriptor_by_name(message_descriptor, field_token);
+		IF field_descriptor IS NULL THEN
+			SET message_text = CONCAT('pb_message_explode: field `', field_token, '` not found in message type `', current_type_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+
+		SET is_map = FALSE;
+		IF field_type = 11 AND field_label = 3 THEN -- repeated TYPE_MESSAGE
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		IF is_map AND repeated_index IS NOT NULL THEN
+			-- Unquoted integer subscripts on map fields are integer keys
+			SET map_key = CAST(repeated_index AS CHAR);
+			SET repeated_index = NULL;
+		END IF;
+
+		SET wire_json = pb_message_to_wire_json(current_message);
+
+		IF segment_index = segment_count - 1 THEN
+			IF field_label <> 3 OR repeated_index IS NOT NULL OR map_key IS NOT NULL THEN
+				SET message_text = CONCAT('pb_message_explode: path `', path, '` does not end with a repeated or map field');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			IF field_type = 10 THEN -- TYPE_GROUP
+				SET message_text = CONCAT('pb_message_explode: unsupported field_type `', field_type, '` for field `', field_token, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET utf8_validation = _pb_get_utf8_validation(descriptor_set_json, current_type_name);
+
+			IF is_map THEN
+				SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+				SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]');
+				SET map_value_type = JSON_EXTRACT(map_value_field, '$."5"');
+				SET map_value_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
+
+				SET elements = _pb_wire_json_merge_map_entries(COALESCE(JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"')), JSON_ARRAY()), map_key_type);
+				SET element_count = JSON_LENGTH(elements);
+				SET element_index = 0;
+				WHILE element_index < element_count DO
+					SET entry_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, '].v')))));
+					CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 1, map_key_type, FALSE, FALSE, FALSE, map_key_json);
+
+					SET number_value = NULL;
+					SET message_value = NULL;
+					IF map_value_type = 11 THEN -- TYPE_MESSAGE
+						SET message_value = pb_wire_json_get_message_field(entry_wire_json, 2, _binary '');
+						CALL _pb_message_to_json(descriptor_set_json, map_value_type_name, message_value, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), json_value);
+					ELSE
+						IF map_value_type = 14 THEN -- TYPE_ENUM
+							SET number_value = CAST(pb_wire_json_get_enum_field(entry_wire_json, 2, 0) AS JSON);
+						ELSE
+							CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 2, map_value_type, FALSE, FALSE, TRUE, number_value);
+						END IF;
+						-- Map values have no presence, so the entry is read as proto3 to get the default for a missing value
+						CALL _pb_wire_json_get_field_as_json(descriptor_set_json, 'proto3', _pb_get_field_utf8_validation(field_descriptor, utf8_validation), entry_wire_json, map_value_field, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), json_value);
+					END IF;
+
+					CALL _pb_message_explode_add_row(parent_id, element_index, JSON_UNQUOTE(map_key_json), map_value_type, number_value, json_value, message_value);
+					SET element_index = element_index + 1;
+				END WHILE;
+			ELSE
+				SET syntax = _pb_get_file_syntax(descriptor_set_json, current_type_name);
+				CALL _pb_wire_json_get_field_as_json(descriptor_set_json, syntax, utf8_validation, wire_json, field_descriptor, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), json_values);
+
+				SET number_values = NULL;
+				IF field_type = 14 THEN -- TYPE_ENUM
+					SET number_values = pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+				ELSEIF field_type <> 11 THEN -- TYPE_MESSAGE
+					CALL _pb_wire_json_get_primitive_field_as_json(wire_json, field_number, field_type, TRUE, FALSE, TRUE, number_values);
+				END IF;
+
+				SET element_count = JSON_LENGTH(json_values);
+				SET element_index = 0;
+				WHILE element_index < element_count DO
+					SET json_value = JSON_EXTRACT(json_values, CONCAT('$[', element_index, ']'));
+					SET number_value = JSON_EXTRACT(number_values, CONCAT('$[', element_index, ']'));
+					SET message_value = NULL;
+					IF field_type = 11 THEN -- TYPE_MESSAGE
+						SET message_value = pb_wire_json_get_repeated_message_field_element(wire_json, field_number, element_index);
+					END IF;
+
+					CALL _pb_message_explode_add_row(parent_id, element_index, NULL, field_type, number_value, json_value, message_value);
+					SET element_index = element_index + 1;
+				END WHILE;
+			END IF;
+
+			LEAVE proc;
+		END IF;
+
+		-- Intermediate segment: descend into a nested message
+		IF is_map THEN
+			SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+			SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]');
+			IF map_key IS NULL OR JSON_EXTRACT(map_value_field, '$."5"') <> 11 THEN
+				SET message_text = CONCAT('pb_message_explode: cannot descend into map field `', field_token, '` in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET found_entry = _pb_wire_json_get_map_entry(wire_json, field_number, map_key_type, map_key);
+			IF found_entry IS NULL THEN
+				LEAVE proc;
+			END IF;
+
+			SET current_message = pb_message_get_message_field(found_entry, 2, _binary '');
+			SET current_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
+		ELSEIF field_type <> 11 THEN
+			SET message_text = CONCAT('pb_message_explode: field `', field_token, '` is not a message field in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		ELSEIF field_label = 3 THEN
+			IF repeated_index IS NULL THEN
+				SET message_text = CONCAT('pb_message_explode: repeated field `', field_token, '` requires an index in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET element_count = pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
+			IF repeated_index < 0 THEN
+				SET repeated_index = element_count + repeated_index;
+			END IF;
+			IF repeated_index < 0 OR repeated_index >= element_count THEN
+				LEAVE proc;
+			END IF;
+
+			SET current_message = pb_wire_json_get_repeated_message_field_element(wire_json, field_number, repeated_index);
+			SET current_type_name = field_type_name;
+		ELSE
+			SET current_message = _pb_wire_json_get_merged_message_field(wire_json, field_number);
+			IF current_message IS NULL THEN
+				LEAVE proc;
+			END IF;
+			SET current_type_name = field_type_name;
+		END IF;
+
+		SET segment_index = segment_index + 1;
+	END WHILE;
+END $$
+
+-- Fills the temporary table pb_exploded with one row per element of the repeated field, or entry of the map field, at path in the messages of a table.
+-- blob_table (optionally qualified as `db.table`) must have a single-column primary key, which is copied to the parent_id column.
+-- Each row has element_index (the position of the element or entry), map_key (for map fields), the typed value columns matching
+-- the field type (int_value, uint_value, double_value, bool_value, string_value, bytes_value or message_value) and json_value.
+DROP PROCEDURE IF EXISTS pb_message_explode $$
+CREATE PROCEDURE pb_message_explode(IN descriptor_set_json JSON, IN type_name TEXT, IN blob_table TEXT, IN blob_column TEXT, IN path TEXT)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segments JSON;
+	DECLARE table_schema_name TEXT;
+	DECLARE table_name TEXT;
+	DECLARE table_reference TEXT;
+	DECLARE primary_key_count INT;
+	DECLARE primary_key_column TEXT;
+	DECLARE done BOOLEAN DEFAULT FALSE;
+	DECLARE parent_id LONGBLOB;
+	DECLARE message LONGBLOB;
+
+	DECLARE source_cursor CURSOR FOR SELECT s.parent_id, s.message FROM _pb_explode_source s;
+	DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = TRUE;
+
+	IF _pb_get_message_descriptor(descriptor_set_json, type_name) IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_explode', CONCAT('message type `', type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET segments = _pb_util_parse_field_path(path);
+	IF JSON_LENGTH(segments) = 0 THEN
+		SET message_text = CONCAT('pb_message_explode: path `', path, '` does not end with a repeated or map field');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	IF LOCATE('.', blob_table) > 0 THEN
+		SET table_schema_name = SUBSTRING_INDEX(blob_table, '.', 1);
+		SET table_name = SUBSTRING(blob_table, LENGTH(table_schema_name) + 2);
+	ELSE
+		SET table_schema_name = DATABASE();
+		SET table_name = blob_table;
+	END IF;
+	SET table_reference = CONCAT(_pb_util_quote_identifier(table_schema_name), '.', _pb_util_quote_identifier(table_name));
+
+	SELECT COUNT(*), MIN(s.COLUMN_NAME) INTO primary_key_count, primary_key_column
+	FROM information_schema.STATISTICS s
+	WHERE s.TABLE_SCHEMA = table_schema_name AND s.TABLE_NAME = table_name AND s.INDEX_NAME = 'PRIMARY';
+	IF primary_key_count <> 1 THEN
+		SET message_text = CONCAT('pb_message_explode: table `', blob_table, '` must have a single-column primary key');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	-- The parent_id column takes the type of the primary key column
+	DROP TEMPORARY TABLE IF EXISTS pb_exploded;
+	SET @_pb_explode_statement = CONCAT('CREATE TEMPORARY TABLE pb_exploded AS SELECT ', _pb_util_quote_identifier(primary_key_column), ' AS parent_id FROM ', table_reference, ' LIMIT 0');
+	PREPARE _pb_explode_statement FROM @_pb_explode_statement;
+	EXECUTE _pb_explode_statement;
+	DEALLOCATE PREPARE _pb_explode_statement;
+
+	ALTER TABLE pb_exploded
+		ADD COLUMN element_index INT NOT NULL,
+		ADD COLUMN map_key TEXT,
+		ADD COLUMN int_value BIGINT,
+		ADD COLUMN uint_value BIGINT UNSIGNED,
+		ADD COLUMN double_value DOUBLE,
+		ADD COLUMN bool_value BOOLEAN,
+		ADD COLUMN string_value LONGTEXT,
+		ADD COLUMN bytes_value LONGBLOB,
+		ADD COLUMN message_value LONGBLOB,
+		ADD COLUMN json_value JSON;
+
+	DROP TEMPORARY TABLE IF EXISTS _pb_explode_source;
+	SET @_pb_explode_statement = CONCAT('CREATE TEMPORARY TABLE _pb_explode_source AS SELECT ', _pb_util_quote_identifier(primary_key_column), ' AS parent_id, ',
+		_pb_util_quote_identifier(blob_column), ' AS message FROM ', table_reference, ' WHERE ', _pb_util_quote_identifier(blob_column), ' IS NOT NULL');
+	PREPARE _pb_explode_statement FROM @_pb_explode_statement;
+	EXECUTE _pb_explode_statement;
+	DEALLOCATE PREPARE _pb_explode_statement;
+	SET @_pb_explode_statement = NULL;
+
+	OPEN source_cursor;
+	l1: LOOP
+		FETCH source_cursor INTO parent_id, message;
+		IF done THEN
+			LEAVE l1;
+		END IF;
+		CALL _pb_message_explode_message(descriptor_set_json, type_name, message, path, segments, parent_id);
+	END LOOP;
+	CLOSE source_cursor;
+
+	DROP TEMPORARY TABLE _pb_explode_source;
+END $$
@@ -0,0 +1,297 @@
+DELIMITER $$
+
+-- Helper function to get the paths of a field mask as a JSON array of strings.
+-- The mask can be the JSON representation of google.protobuf.FieldMask (e.g. "a.b,c"), a JSON array of paths,
+-- a JSON object {"paths": [...]}, or a serialized google.protobuf.FieldMask message.
+DROP FUNCTION IF EXISTS _pb_field_mask_get_paths $$
+CREATE FUNCTION _pb_field_mask_get_paths(mask LONGBLOB, func_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE mask_text LONGTEXT;
+	DECLARE mask_json JSON;
+	DECLARE paths JSON;
+	DECLARE path TEXT;
+	DECLARE remaining TEXT;
+
+	SET mask_text = CONVERT(mask USING utf8mb4);
+	IF JSON_VALID(mask_text) THEN
+		SET mask_json = CAST(mask_text AS JSON);
+		IF JSON_TYPE(mask_json) = 'OBJECT' THEN
+			SET mask_json = COALESCE(JSON_EXTRACT(mask_json, '$.paths'), JSON_ARRAY());
+		END IF;
+	ELSE
+		SET mask_json = _pb_wire_json_decode_wkt_field_mask_as_json(pb_message_to_wire_json(mask));
+	END IF;
+
+	CASE JSON_TYPE(mask_json)
+	WHEN 'ARRAY' THEN
+		RETURN mask_json;
+	WHEN 'STRING' THEN
+		SET paths = JSON_ARRAY();
+		SET remaining = JSON_UNQUOTE(mask_json);
+		WHILE remaining <> '' DO
+			SET path = TRIM(SUBSTRING_INDEX(remaining, ',', 1));
+			IF path <> '' THEN
+				SET paths = JSON_ARRAY_APPEND(paths, '$', path);
+			END IF;
+			SET remaining = IF(LOCATE(',', remaining) > 0, SUBSTRING(remaining, LOCATE(',', remaining) + 1), '');
+		END WHILE;
+		RETURN paths;
+	ELSE
+		SET message_text = CONCAT(func_name, ': invalid field mask `', LEFT(mask_text, 100), '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to turn field mask paths into a tree of field names, resolved against the schema.
+-- For example, paths ["a.b", "a.c", "d"] become {"a": {"b": true, "c": true}, "d": true}, where true selects the whole field.
+-- Paths may use either proto field names or JSON names. Only singular message fields can have sub-paths.
+-- A path can have at most 99 fields, since the tree of a longer path would exceed the maximum depth of a JSON document.
+DROP FUNCTION IF EXISTS _pb_field_mask_to_tree $$
+CREATE FUNCTION _pb_field_mask_to_tree(descriptor_set_json JSON, type_name TEXT, paths JSON, func_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE tree JSON;
+	DECLARE path_count INT;
+	DECLARE path_index INT DEFAULT 0;
+	DECLARE path TEXT;
+	DECLARE remaining TEXT;
+	DECLARE segment TEXT;
+	DECLARE segment_count INT;
+	DECLARE is_last BOOLEAN;
+	DECLARE current_type_name TEXT;
+	DECLARE node_path TEXT;
+	DECLARE node JSON;
+	DECLARE field_descriptor JSON;
+
+	SET tree = JSON_OBJECT();
+	SET path_count = JSON_LENGTH(paths);
+
+	WHILE path_index < path_count DO
+		SET path = JSON_UNQUOTE(JSON_EXTRACT(paths, CONCAT('$[', path_index, ']')));
+		SET remaining = path;
+		SET current_type_name = type_name;
+		SET node_path = '$';
+		SET segment_count = 0;
+
+		l1: WHILE TRUE DO
+			SET segment_count = segment_count + 1;
+			IF segment_count > 99 THEN
+				CALL _pb_signal_error('PB_DEPTH_LIMIT_EXCEEDED', func_name, 'field mask path has more than 99 fields');
+			END IF;
+
+			SET segment = SUBSTRING_INDEX(remaining, '.', 1);
+			SET is_last = LOCATE('.', remaining) = 0;
+			SET remaining = SUBSTRING(remaining, CHAR_LENGTH(segment) + 2);
+
+			SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, current_type_name, segment, func_name);
+			SET node_path = CONCAT(node_path, '."', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '"'); -- name
+			SET node = JSON_EXTRACT(tree, node_path);
+
+			IF node = CAST('true' AS JSON) THEN
+				-- The whole field is already selected by a shorter path
+				LEAVE l1;
+			END IF;
+
+			IF is_last THEN
+				SET tree = JSON_SET(tree, node_path, CAST('true' AS JSON));
+				LEAVE l1;
+			END IF;
+
+			IF JSON_EXTRACT(field_descriptor, '$."5"') <> 11 OR JSON_EXTRACT(field_descriptor, '$."4"') = 3 THEN -- not a singular message field
+				SET message_text = CONCAT(func_name, ': field `', segment, '` in path `', path, '` is not a singular message field');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			IF node IS NULL THEN
+				SET tree = JSON_SET(tree, node_path, JSON_OBJECT());
+			END IF;
+			SET current_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		END WHILE;
+
+		SET path_index = path_index + 1;
+	END WHILE;
+
+	RETURN tree;
+END $$
+
+-- Helper procedure to keep only the fields selected by a field mask tree
+DROP PROCEDURE IF EXISTS _pb_message_apply_field_mask $$
+CREATE PROCEDURE _pb_message_apply_field_mask(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN tree JSON, OUT result LONGBLOB)
+BEGIN
+	DECLARE wire_json JSON;
+	DECLARE result_wire_json JSON;
+	DECLARE field_names JSON;
+	DECLARE field_name_count INT;
+	DECLARE field_name_index INT DEFAULT 0;
+	DECLARE field_name TEXT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE subtree JSON;
+	DECLARE elements JSON;
+	DECLARE last_element JSON;
+	DECLARE sub_message LONGBLOB;
+
+	SET wire_json = pb_message_to_wire_json(message);
+	SET result_wire_json = JSON_OBJECT();
+
+	SET field_names = JSON_KEYS(tree);
+	SET field_name_count = JSON_LENGTH(field_names);
+
+	WHILE field_name_index < field_name_count DO
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_names, CONCAT('$[', field_name_index, ']')));
+		SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, full_type_name, field_name, 'pb_message_apply_field_mask');
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET subtree = JSON_EXTRACT(tree, CONCAT('$."', field_name, '"'));
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+
+		IF elements IS NOT NULL THEN
+			IF JSON_TYPE(subtree) = 'BOOLEAN' THEN
+				SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), elements);
+			ELSE
+				-- The message is kept at the position of its last occurrence, with only the selected sub-fields
+				CALL _pb_message_apply_field_mask(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')), _pb_wire_json_get_merged_message_field(wire_json, field_number), subtree, sub_message);
+				SET last_element = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, ']'));
+				SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), JSON_ARRAY(JSON_SET(last_element, '$.v', TO_BASE64(sub_message))));
+			END IF;
+		END IF;
+
+		SET field_name_index = field_name_index + 1;
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(result_wire_json);
+END $$
+
+-- Returns a copy of the message with only the fields selected by the field mask
+DROP FUNCTION IF EXISTS pb_message_apply_field_mask $$
+CREATE FUNCTION pb_message_apply_field_mask(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, mask LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL OR mask IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_apply_field_mask(descriptor_set_json, type_name, message,
+		_pb_field_mask_to_tree(descriptor_set_json, type_name, _pb_field_mask_get_paths(mask, 'pb_message_apply_field_mask'), 'pb_message_apply_field_mask'),
+		result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Helper procedure to merge the fields selected by a field mask tree from source into target
+DROP PROCEDURE IF EXISTS _pb_message_merge_with_field_mask $$
+CREATE PROCEDURE _pb_message_merge_with_field_mask(IN descriptor_set_json JSON, IN full_type_name TEXT, IN target LONGBLOB, IN source LONGBLOB, IN tree JSON, OUT result LONGBLOB)
+BEGIN
+	DECLARE target_wire_json JSON;
+	DECLARE source_wire_json JSON;
+	DECLARE field_names JSON;
+	DECLARE field_name_count INT;
+	DECLARE field_name_index INT DEFAULT 0;
+	DECLARE field_name TEXT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE subtree JSON;
+	DECLARE source_elements JSON;
+	DECLARE target_message LONGBLOB;
+	DECLARE source_message LONGBLOB;
+	DECLARE sub_message LONGBLOB;
+
+	SET target_wire_json = pb_message_to_wire_json(target);
+	SET source_wire_json = pb_message_to_wire_json(source);
+
+	SET field_names = JSON_KEYS(tree);
+	SET field_name_count = JSON_LENGTH(field_names);
+
+	WHILE field_name_index < field_name_count DO
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_names, CONCAT('$[', field_name_index, ']')));
+		SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, full_type_name, field_name, 'pb_message_merge_with_field_mask');
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET subtree = JSON_EXTRACT(tree, CONCAT('$."', field_name, '"'));
+		SET source_elements = JSON_EXTRACT(source_wire_json, CONCAT('$."', field_number, '"'));
+
+		IF JSON_TYPE(subtree) = 'BOOLEAN' THEN
+			IF field_label = 3 THEN
+				-- Repeated fields are appended; map entries with the same key are overwritten on read
+				SET target_wire_json = _pb_wire_json_append_elements(target_wire_json, field_number, source_elements);
+			ELSEIF field_type = 11 THEN
+				-- Message fields are merged recursively
+				SET source_message = _pb_wire_json_get_merged_message_field(source_wire_json, field_number);
+				IF source_message IS NOT NULL THEN
+					SET target_message = _pb_wire_json_get_merged_message_field(target_wire_json, field_number);
+					SET target_wire_json = _pb_wire_json_clear_oneof_members(descriptor_set_json, full_type_name, target_wire_json, field_descriptor);
+					SET target_wire_json = _pb_wire_json_set_len_field(target_wire_json, field_number, CONCAT(COALESCE(target_message, _binary ''), source_message));
+				END IF;
+			ELSE
+				-- Scalar fields are replaced, or cleared if they are not set in source
+				SET target_wire_json = _pb_wire_json_clear_field(target_wire_json, field_number);
+				IF source_elements IS NOT NULL THEN
+					SET target_wire_json = _pb_wire_json_clear_oneof_members(descriptor_set_json, full_type_name, target_wire_json, field_descriptor);
+					SET target_wire_json = _pb_wire_json_append_elements(target_wire_json, field_number, JSON_ARRAY(JSON_EXTRACT(source_elements, CONCAT('$[', JSON_LENGTH(source_elements) - 1, ']'))));
+				END IF;
+			END IF;
+		ELSE
+			SET target_message = _pb_wire_json_get_merged_message_field(target_wire_json, field_number);
+			SET source_message = _pb_wire_json_get_merged_message_field(source_wire_json, field_number);
+			IF target_message IS NOT NULL OR source_message IS NOT NULL THEN
+				CALL _pb_message_merge_with_field_mask(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')), COALESCE(target_message, _binary ''), COALESCE(source_message, _binary ''), subtree, sub_message);
+				IF source_message IS NOT NULL THEN
+					SET target_wire_json = _pb_wire_json_clear_oneof_members(descriptor_set_json, full_type_name, target_wire_json, field_descriptor);
+				END IF;
+				SET target_wire_json = _pb_wire_json_replace_len_field(target_wire_json, field_number, sub_message);
+			END IF;
+		END IF;
+
+		SET field_name_index = field_name_index + 1;
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(target_wire_json);
+END $$
+
+-- Merges the fields selected by the field mask from source into target, following the update semantics of google.protobuf.FieldMask
+DROP FUNCTION IF EXISTS pb_message_merge_with_field_mask $$
+CREATE FUNCTION pb_message_merge_with_field_mask(descriptor_set_json JSON, type_name TEXT, target LONGBLOB, source LONGBLOB, mask LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF target IS NULL OR source IS NULL OR mask IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_merge_with_field_mask(descriptor_set_json, type_name, target, source,
+		_pb_field_mask_to_tree(descriptor_set_json, type_name, _pb_field_mask_get_paths(mask, 'pb_message_merge_with_field_mask'), 'pb_message_merge_with_field_mask'),
+		result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Converts a message to JSON, rendering only the fields selected by the field mask
+DROP FUNCTION IF EXISTS pb_message_to_json_with_field_mask $$
+CREATE FUNCTION pb_message_to_json_with_field_mask(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, mask LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN pb_message_to_json(descriptor_set_json, type_name, pb_message_apply_field_mask(descriptor_set_json, type_name, message, mask));
+END $$
@@ -1,187 +1,294 @@
 DELIMITER $$
 
+-- Type index entries
+--
+-- The type index at $[2] of the descriptor set JSON maps each type name to [kind, file path, type path, syntax], where
+-- syntax is the syntax of the file defining the type. Entries of enums (kind 14) also carry the values of the enum as
+-- {"n": {number: name}, "v": {name: number}}, so converting many rows with the same schema finds them without walking the
+-- enum descriptor again. Both are precomputed by pb_build_descriptor_set_json and protoc-gen-descriptor_set_json, and they
+-- are computed here instead when a descriptor set JSON built without them is given.
+
 -- Helper function to get message descriptor from descriptor set JSON
 DROP FUNCTION IF EXISTS _pb_get_message_descriptor $$
 CREATE FUNCTION _pb_get_message_descriptor(descriptor_set_json JSON, type_name TEXT) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE type_index JSON;
 	DECLARE type_paths JSON;
-	DECLARE kind INT;
-	DECLARE file_path TEXT;
-	DECLARE type_path TEXT;
-	
-	-- Get type index (element 2)
-	SET type_index = JSON_EXTRACT(descriptor_set_json, '$[2]');
-	
-	-- Get paths for the type
-	SET type_paths = JSON_EXTRACT(type_index, CONCAT('$."', type_name, '"'));
-	
-	IF type_paths IS NULL THEN
-		RETURN NULL;
-	END IF;
-	
-	-- Extract kind, file path and type path
-	SET kind = JSON_EXTRACT(type_paths, '$[0]');
-	SET file_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[1]'));
-	SET type_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]'));
-	
+
+	-- Get [kind, file path, type path, ...] from the type index (element 2)
+	SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name)));
+
 	-- Verify this is a message type (kind = 11)
-	IF kind <> 11 THEN
+	IF type_paths IS NULL OR JSON_EXTRACT(type_paths, '$[0]') <> 11 THEN
 		RETURN NULL;
 	END IF;
-	
-	-- Return the message descriptor
-	RETURN JSON_EXTRACT(descriptor_set_json, type_path);
+
+	RETURN JSON_EXTRACT(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]')));
 END $$
 
 -- Helper function to get enum descriptor from descriptor set JSON
 DROP FUNCTION IF EXISTS _pb_get_enum_descriptor $$
 CREATE FUNCTION _pb_get_enum_descriptor(descriptor_set_json JSON, type_name TEXT) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE type_index JSON;
 	DECLARE type_paths JSON;
-	DECLARE kind INT;
-	DECLARE file_path TEXT;
-	DECLARE type_path TEXT;
-	
-	-- Get type index (element 2)
-	SET type_index = JSON_EXTRACT(descriptor_set_json, '$[2]');
-	
-	-- Get paths for the type
-	SET type_paths = JSON_EXTRACT(type_index, CONCAT('$."', type_name, '"'));
-	
-	IF type_paths IS NULL THEN
-		RETURN NULL;
-	END IF;
-	
-	-- Extract kind, file path and type path
-	SET kind = JSON_EXTRACT(type_paths, '$[0]');
-	SET file_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[1]'));
-	SET type_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]'));
-	
+
+	-- Get [kind, file path, type path, ...] from the type index (element 2)
+	SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name)));
+
 	-- Verify this is an enum type (kind = 14)
-	IF kind <> 14 THEN
+	IF type_paths IS NULL OR JSON_EXTRACT(type_paths, '$[0]') <> 14 THEN
 		RETURN NULL;
 	END IF;
-	
-	-- Return the enum descriptor
-	RETURN JSON_EXTRACT(descriptor_set_json, type_path);
+
+	RETURN JSON_EXTRACT(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]')));
 END $$
 
--- Helper procedure to convert enum value to JSON using descriptor set
-DROP PROCEDURE IF EXISTS _pb_enum_to_json $$
-CREATE PROCEDURE _pb_enum_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN enum_value_number INT, OUT result JSON)
-proc: BEGIN
-	DECLARE enum_descriptor JSON;
+-- Helper function to build {"n": {number: name}, "v": {name: number}} from the values of an EnumDescriptorProto.
+-- When several names share a number (allow_alias), the first one is used for the number, as in protojson.
+DROP FUNCTION IF EXISTS _pb_build_enum_map $$
+CREATE FUNCTION _pb_build_enum_map(enum_descriptor JSON) RETURNS JSON DETERMINISTIC
+BEGIN
 	DECLARE enum_values JSON;
-	DECLARE enum_value JSON;
 	DECLARE enum_count INT;
-	DECLARE enum_index INT;
-	DECLARE current_number INT;
-	DECLARE current_name TEXT;
-	
-	SET enum_descriptor = _pb_get_enum_descriptor(descriptor_set_json, full_type_name);
-	
-	IF enum_descriptor IS NULL THEN
-		SET result = NULL;
-		LEAVE proc;
-	END IF;
-	
+	DECLARE enum_index INT DEFAULT 0;
+	DECLARE enum_name TEXT;
+	DECLARE enum_number INT;
+	DECLARE names JSON;
+	DECLARE numbers JSON;
+
+	SET names = JSON_OBJECT();
+	SET numbers = JSON_OBJECT();
+
 	-- Get enum values array (field 2 in EnumDescriptorProto)
 	SET enum_values = JSON_EXTRACT(enum_descriptor, '$."2"');
-	
-	IF enum_values IS NULL THEN
-		SET result = NULL;
-		LEAVE proc;
-	END IF;
-	
-	SET enum_count = JSON_LENGTH(enum_values);
-	SET enum_index = 0;
-	
-	-- Find enum value by number
+	SET enum_count = COALESCE(JSON_LENGTH(enum_values), 0);
 	WHILE enum_index < enum_count DO
-		SET enum_value = JSON_EXTRACT(enum_values, CONCAT('$[', enum_index, ']'));
-		SET current_number = JSON_EXTRACT(enum_value, '$."2"'); -- number field
-		
-		IF current_number = enum_value_number THEN
-			SET current_name = JSON_UNQUOTE(JSON_EXTRACT(enum_value, '$."1"')); -- name field
-			SET result = JSON_QUOTE(current_name);
-			LEAVE proc;
-		END IF;
-		
+		SET enum_name = JSON_UNQUOTE(JSON_EXTRACT(enum_values, CONCAT('$[', enum_index, ']."1"'))); -- name
+		SET enum_number = JSON_EXTRACT(enum_values, CONCAT('$[', enum_index, ']."2"')); -- number
+		SET names = JSON_INSERT(names, CONCAT('$."', enum_number, '"'), enum_name);
+		SET numbers = JSON_INSERT(numbers, CONCAT('$.', JSON_QUOTE(enum_name)), enum_number);
 		SET enum_index = enum_index + 1;
 	END WHILE;
-	
-	-- If not found, return null
-	IF result IS NULL THEN
-		SET result = NULL;
+
+	RETURN JSON_OBJECT('n', names, 'v', numbers);
+END $$
+
+-- Helper function to get the values of an enum as {"n": {number: name}, "v": {name: number}}
+DROP FUNCTION IF EXISTS _pb_get_enum_map $$
+CREATE FUNCTION _pb_get_enum_map(descriptor_set_json JSON, type_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE type_paths JSON;
+	DECLARE enum_map JSON;
+
+	-- Get [kind, file path, type path, syntax, enum map] from the type index (element 2)
+	SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name)));
+
+	-- Verify this is an enum type (kind = 14)
+	IF type_paths IS NULL OR JSON_EXTRACT(type_paths, '$[0]') <> 14 THEN
+		RETURN NULL;
 	END IF;
+
+	SET enum_map = JSON_EXTRACT(type_paths, '$[4]');
+	IF enum_map IS NULL THEN
+		SET enum_map = _pb_build_enum_map(JSON_EXTRACT(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]'))));
+	END IF;
+	RETURN enum_map;
+END $$
+
+-- Helper procedure to convert enum value to JSON using descriptor set
+DROP PROCEDURE IF EXISTS _pb_enum_to_json $$
+CREATE PROCEDURE _pb_enum_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN enum_value_number INT, OUT result JSON)
+BEGIN
+	-- Unknown enum types and values are returned as NULL
+	SET result = JSON_EXTRACT(_pb_get_enum_map(descriptor_set_json, full_type_name), CONCAT('$.n."', enum_value_number, '"'));
 END $$
 
 -- Helper function to get file descriptor for a type
 DROP FUNCTION IF EXISTS _pb_get_file_descriptor $$
 CREATE FUNCTION _pb_get_file_descriptor(descriptor_set_json JSON, type_name TEXT) RETURNS JSON DETERMINISTIC
 BEGIN
-	DECLARE type_index JSON;
-	DECLARE type_paths JSON;
 	DECLARE file_path TEXT;
-	
-	-- Get type index (element 2)
-	SET type_index = JSON_EXTRACT(descriptor_set_json, '$[2]');
-	
-	-- Get paths for the type
-	SET type_paths = JSON_EXTRACT(type_index, CONCAT('$."', type_name, '"'));
-	
-	IF type_paths IS NULL THEN
+
+	-- Get the file path from [kind, file path, type path, ...] in the type index (element 2)
+	SET file_path = JSON_UNQUOTE(JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name), '[1]')));
+
+	IF file_path IS NULL THEN
 		RETURN NULL;
 	END IF;
-	
-	-- Extract file path (now at index 1)
-	SET file_path = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[1]'));
-	
+
 	-- Return the file descriptor
 	RETURN JSON_EXTRACT(descriptor_set_json, file_path);
 END $$
 
--- Main procedure for converting protobuf message to JSON using descriptor set
-DROP PROCEDURE IF EXISTS _pb_message_to_json $$
-CREATE PROCEDURE _pb_message_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN as_number_json BOOLEAN, OUT result JSON)
-proc: BEGIN
-	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
-	
-	DECLARE message_text TEXT;
-	DECLARE message_descriptor JSON;
-	DECLARE file_descriptor JSON;
+-- Helper function to get the syntax ('proto2', 'proto3' or 'editions') of the file defining a type
+DROP FUNCTION IF EXISTS _pb_get_file_syntax $$
+CREATE FUNCTION _pb_get_file_syntax(descriptor_set_json JSON, type_name TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE type_paths JSON;
 	DECLARE syntax TEXT;
-	DECLARE wire_json JSON;
+
+	-- Get [kind, file path, type path, syntax, ...] from the type index (element 2)
+	SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(type_name)));
+
+	SET syntax = JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[3]'));
+	IF syntax IS NULL THEN
+		-- Only the file path is needed, so the file descriptor is not extracted as a whole
+		SET syntax = JSON_UNQUOTE(JSON_EXTRACT(descriptor_set_json, CONCAT(JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[1]')), '."12"'))); -- syntax field
+	END IF;
+	IF syntax IS NULL THEN
+		SET syntax = 'proto2'; -- default
+	END IF;
+
+	RETURN syntax;
+END $$
+
+-- Helper function to get the default UTF-8 validation mode of the string fields in a message type: 'strict' for proto3 and
+-- 'passthrough' for proto2. Editions use the utf8_validation feature of the innermost of the message, its enclosing
+-- messages and the file that sets it (VERIFY by default). Features set on a field override this (see _pb_get_field_utf8_validation).
+DROP FUNCTION IF EXISTS _pb_get_utf8_validation $$
+CREATE FUNCTION _pb_get_utf8_validation(descriptor_set_json JSON, type_name TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE utf8_validation_feature INT;
+	DECLARE scope_name TEXT;
+	DECLARE type_paths JSON;
+
+	CASE _pb_get_file_syntax(descriptor_set_json, type_name)
+	WHEN 'proto3' THEN
+		RETURN 'strict';
+	WHEN 'editions' THEN
+		-- Walk out from the message through its enclosing messages; the first one that sets the feature wins
+		SET scope_name = type_name;
+		SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(scope_name)));
+		WHILE utf8_validation_feature IS NULL AND JSON_EXTRACT(type_paths, '$[0]') = 11 DO
+			SET utf8_validation_feature = JSON_EXTRACT(descriptor_set_json, CONCAT(JSON_UNQUOTE(JSON_EXTRACT(type_paths, '$[2]')), '."7"."12"."4"')); -- options.features.utf8_validation
+			SET scope_name = SUBSTRING(scope_name, 1, CHAR_LENGTH(scope_name) - CHAR_LENGTH(SUBSTRING_INDEX(scope_name, '.', -1)) - 1);
+			SET type_paths = JSON_EXTRACT(descriptor_set_json, CONCAT('$[2].', JSON_QUOTE(scope_name)));
+		END WHILE;
+		IF utf8_validation_feature IS NULL THEN
+			SET utf8_validation_feature = JSON_EXTRACT(_pb_get_file_descriptor(descriptor_set_json, type_name), '$."8"."50"."4"'); -- options.features.utf8_validation
+		END IF;
+		RETURN IF(utf8_validation_feature = 3, 'passthrough', 'strict'); -- NONE
+	ELSE
+		RETURN 'passthrough';
+	END CASE;
+END $$
+
+-- Helper function to get the UTF-8 validation mode of a string field, given the default of the message type containing it
+DROP FUNCTION IF EXISTS _pb_get_field_utf8_validation $$
+CREATE FUNCTION _pb_get_field_utf8_validation(field_descriptor JSON, default_utf8_validation TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE utf8_validation_feature INT;
+
+	SET utf8_validation_feature = JSON_EXTRACT(field_descriptor, '$."8"."21"."4"'); -- options.features.utf8_validation
+	CASE utf8_validation_feature
+	WHEN 2 THEN -- VERIFY
+		RETURN 'strict';
+	WHEN 3 THEN -- NONE
+		RETURN 'passthrough';
+	ELSE
+		RETURN default_utf8_validation;
+	END CASE;
+END $$
+
+-- Helper function to find a field descriptor by its name or json_name
+DROP FUNCTION IF EXISTS _pb_get_field_descriptor_by_name $$
+CREATE FUNCTION _pb_get_field_descriptor_by_name(message_descriptor JSON, field_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
 	DECLARE fields JSON;
+	DECLARE field_descriptor JSON;
 	DECLARE field_count INT;
 	DECLARE field_index INT;
-	DECLARE field_descriptor JSON;
-	
+	DECLARE name TEXT;
+	DECLARE json_name TEXT;
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+	SET field_index = 0;
+
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET json_name = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."10"')), _pb_util_snake_to_lower_camel(name)); -- json_name
+		IF BINARY name = BINARY field_name OR BINARY json_name = BINARY field_name THEN
+			RETURN field_descriptor;
+		END IF;
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	RETURN NULL;
+END $$
+
+-- Helper function to read a boolean option of pb_message_to_json_with_options(). Missing options are false.
+DROP FUNCTION IF EXISTS _pb_json_options_get_boolean $$
+CREATE FUNCTION _pb_json_options_get_boolean(options JSON, option_name TEXT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	RETURN COALESCE(JSON_EXTRACT(options, CONCAT('$.', option_name)) = CAST('true' AS JSON), FALSE);
+END $$
+
+-- Helper function to re-encode base64 JSON strings (or arrays of them) holding bytes values as base64url or hex
+DROP FUNCTION IF EXISTS _pb_json_encode_bytes $$
+CREATE FUNCTION _pb_json_encode_bytes(json_value JSON, bytes_encoding TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE encoded TEXT;
+
+	IF json_value IS NULL OR bytes_encoding = 'base64' THEN
+		RETURN json_value;
+	END IF;
+
+	IF JSON_TYPE(json_value) = 'ARRAY' THEN
+		SET result = JSON_ARRAY();
+		SET element_count = JSON_LENGTH(json_value);
+		WHILE element_index < element_count DO
+			SET result = JSON_ARRAY_APPEND(result, '$', _pb_json_encode_bytes(JSON_EXTRACT(json_value, CONCAT('$[', element_index, ']')), bytes_encoding));
+			SET element_index = element_index + 1;
+		END WHILE;
+		RETURN result;
+	END IF;
+
+	SET encoded = JSON_UNQUOTE(json_value);
+	CASE bytes_encoding
+	WHEN 'base64url' THEN
+		RETURN JSON_QUOTE(REPLACE(REPLACE(REPLACE(encoded, '\n', ''), '+', '-'), '/', '_'));
+	WHEN 'hex' THEN
+		RETURN JSON_QUOTE(LOWER(HEX(FROM_BASE64(encoded))));
+	ELSE
+		RETURN json_value;
+	END CASE;
+END $$
+
+-- Helper procedure to convert a single field of a message to JSON using its field descriptor.
+-- Nested messages are not converted here. Each of them is left as a JSON null placeholder and described in
+-- nested_messages as {"p": <JSON path of the placeholder relative to the field value>, "t": <type name>,
+-- "b": <base64-encoded message>, "f": <field number>}, to be filled in by _pb_json_resolve_nested_messages.
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_field_as_partial_json $$
+CREATE PROCEDURE _pb_wire_json_get_field_as_partial_json(IN descriptor_set_json JSON, IN syntax TEXT, IN default_utf8_validation TEXT, IN wire_json JSON, IN field_descriptor JSON, IN as_number_json BOOLEAN, IN options JSON, OUT field_json_value JSON, OUT nested_messages JSON)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+
 	-- Field properties
 	DECLARE field_number INT;
 	DECLARE field_name TEXT;
 	DECLARE field_label INT;
 	DECLARE field_type INT;
 	DECLARE field_type_name TEXT;
-	DECLARE json_name TEXT;
 	DECLARE proto3_optional BOOLEAN;
 	DECLARE oneof_index INT;
-	DECLARE default_value TEXT;
-	
+
 	-- Processing variables
 	DECLARE is_repeated BOOLEAN;
 	DECLARE has_field_presence BOOLEAN;
-	DECLARE field_json_value JSON;
-	DECLARE json_field_name TEXT;
+	DECLARE utf8_validation TEXT;
 	DECLARE bytes_value LONGBLOB;
 	DECLARE nested_json_value JSON;
 	DECLARE elements JSON;
 	DECLARE element JSON;
 	DECLARE element_count INT;
 	DECLARE element_index INT;
-	
+
 	-- Map handling
 	DECLARE is_map BOOLEAN;
 	DECLARE map_entry_descriptor JSON;
@@ -191,216 +298,406 @@ proc: BEGIN
 	DECLARE map_value_type INT;
 	DECLARE map_value_type_name TEXT;
 	DECLARE map_key JSON;
+	DECLARE map_key_path TEXT;
 	DECLARE map_value JSON;
+
+	-- Options
+	DECLARE use_enum_numbers BOOLEAN;
+	DECLARE int64_as_number BOOLEAN;
+	DECLARE bytes_encoding TEXT;
+
+	-- Report decoder errors in this field (or in messages nested in it) with the field path from this message
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_prefix_error_field_path(message_text, JSON_EXTRACT(field_descriptor, '$."3"'));
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	SET use_enum_numbers = as_number_json OR _pb_json_options_get_boolean(options, 'UseEnumNumbers');
+	SET int64_as_number = as_number_json OR _pb_json_options_get_boolean(options, 'Int64AsNumber');
+	SET bytes_encoding = COALESCE(JSON_UNQUOTE(JSON_EXTRACT(options, '$.BytesEncoding')), 'base64');
+	SET nested_messages = JSON_ARRAY();
+
+	-- Extract field properties from FieldDescriptorProto
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+	SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+	SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+
+	SET is_repeated = (field_label = 3); -- LABEL_REPEATED
+
+	-- Check if this is a map field
+	SET is_map = FALSE;
+	IF field_type = 11 AND field_type_name IS NOT NULL THEN -- TYPE_MESSAGE
+		SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+		SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+	END IF;
+
+	-- Determine field presence
+	SET has_field_presence =
+		(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+		OR (syntax = 'proto3'
+			AND (
+				(field_label = 1 AND proto3_optional) -- proto3 optional
+				OR (field_label <> 3 AND field_type = 11) -- message fields
+				OR (oneof_index IS NOT NULL) -- oneof fields
+			));
+
+	SET utf8_validation = _pb_get_field_utf8_validation(field_descriptor, default_utf8_validation);
+
+	CASE field_type
+	WHEN 10 THEN -- TYPE_GROUP (unsupported)
+		SET message_text = CONCAT('_pb_message_to_json: unsupported field_type `', field_type, '` for field `', field_name, '` (', field_number, ').');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+
+	WHEN 11 THEN -- TYPE_MESSAGE
+		IF is_map THEN
+			-- Handle map fields
+			SET elements = pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			SET field_json_value = JSON_OBJECT();
+
+			-- Get map key/value field descriptors
+			SET map_key_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]'); -- first field (key)
+			SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]'); -- second field (value)
+			SET map_key_type = JSON_EXTRACT(map_key_field, '$."5"');
+			SET map_value_type = JSON_EXTRACT(map_value_field, '$."5"');
+			SET map_value_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
+
+			WHILE element_index < element_count DO
+				SET element = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))));
+				IF map_key_type = 9 THEN -- string
+					CALL _pb_wire_json_get_string_field_as_json(element, 1, FALSE, FALSE, utf8_validation, map_key);
+				ELSE
+					CALL _pb_wire_json_get_primitive_field_as_json(element, 1, map_key_type, FALSE, FALSE, as_number_json, map_key);
+				END IF;
+
+				IF JSON_TYPE(map_key) = 'STRING' THEN
+					SET map_key_path = CONCAT('.', map_key);
+				ELSE
+					SET map_key_path = CONCAT('."', map_key, '"');
+				END IF;
+
+				IF map_value_type = 11 THEN -- message
+					SET bytes_value = pb_wire_json_get_message_field(element, 2, NULL);
+					IF bytes_value IS NULL THEN
+						SET map_value = NULL;
+					ELSE
+						SET map_value = CAST('null' AS JSON);
+						SET nested_messages = JSON_ARRAY_APPEND(nested_messages, '$', JSON_OBJECT('p', map_key_path, 't', map_value_type_name, 'b', TO_BASE64(bytes_value), 'f', field_number));
+					END IF;
+				ELSEIF map_value_type = 14 THEN -- enum
+					IF use_enum_numbers THEN
+						SET map_value = CAST(pb_wire_json_get_enum_field(element, 2, NULL) AS JSON);
+					ELSE
+						CALL _pb_enum_to_json(descriptor_set_json, map_value_type_name, pb_wire_json_get_enum_field(element, 2, NULL), map_value);
+					END IF;
+				ELSEIF map_value_type = 9 THEN -- string
+					CALL _pb_wire_json_get_string_field_as_json(element, 2, FALSE, TRUE, utf8_validation, map_value);
+				ELSE
+					CALL _pb_wire_json_get_primitive_field_as_json(element, 2, map_value_type, FALSE, TRUE, int64_as_number, map_value);
+					IF map_value_type = 12 THEN -- bytes
+						SET map_value = _pb_json_encode_bytes(map_value, bytes_encoding);
+					END IF;
+				END IF;
+
+				SET field_json_value = JSON_SET(field_json_value, CONCAT('$', map_key_path), map_value);
+
+				SET element_index = element_index + 1;
+			END WHILE;
+
+		ELSEIF is_repeated THEN
+			-- Handle repeated message fields
+			SET elements = pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			SET field_json_value = JSON_ARRAY();
+
+			WHILE element_index < element_count DO
+				SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', CAST('null' AS JSON));
+				SET nested_messages = JSON_ARRAY_APPEND(nested_messages, '$', JSON_OBJECT('p', CONCAT('[', element_index, ']'), 't', field_type_name, 'b', JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')), 'f', field_number));
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSE
+			-- Handle singular message fields
+			SET bytes_value = pb_wire_json_get_message_field(wire_json, field_number, NULL);
+			IF bytes_value IS NULL THEN
+				SET field_json_value = NULL;
+			ELSE
+				SET field_json_value = CAST('null' AS JSON);
+				SET nested_messages = JSON_ARRAY_APPEND(nested_messages, '$', JSON_OBJECT('p', '', 't', field_type_name, 'b', TO_BASE64(bytes_value), 'f', field_number));
+			END IF;
+		END IF;
+
+	WHEN 14 THEN -- TYPE_ENUM
+		IF is_repeated THEN
+			SET elements = pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			SET field_json_value = JSON_ARRAY();
+
+			WHILE element_index < element_count DO
+				SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+				IF use_enum_numbers THEN
+					SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', CAST(element AS JSON));
+				ELSE
+					CALL _pb_enum_to_json(descriptor_set_json, field_type_name, element, nested_json_value);
+					SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', nested_json_value);
+				END IF;
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSE
+			IF use_enum_numbers THEN
+				SET field_json_value = CAST(pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0)) AS JSON);
+			ELSE
+				CALL _pb_enum_to_json(descriptor_set_json, field_type_name, pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0)), field_json_value);
+			END IF;
+		END IF;
+
+	WHEN 9 THEN -- TYPE_STRING
+		CALL _pb_wire_json_get_string_field_as_json(wire_json, field_number, is_repeated, has_field_presence, utf8_validation, field_json_value);
+
+	ELSE
+		-- Handle primitive types using existing function
+		CALL _pb_wire_json_get_primitive_field_as_json(wire_json, field_number, field_type, is_repeated, has_field_presence, int64_as_number, field_json_value);
+		IF field_type = 12 THEN -- bytes
+			SET field_json_value = _pb_json_encode_bytes(field_json_value, bytes_encoding);
+		END IF;
+	END CASE;
+END $$
+
+-- Helper function to get the fields of a wire JSON object that are not declared in the message descriptor
+DROP FUNCTION IF EXISTS _pb_wire_json_get_unknown_fields $$
+CREATE FUNCTION _pb_wire_json_get_unknown_fields(message_descriptor JSON, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	WHILE field_index < field_count DO
+		SET wire_json = JSON_REMOVE(wire_json, CONCAT('$."', JSON_EXTRACT(fields, CONCAT('$[', field_index, ']."3"')), '"')); -- number
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	RETURN wire_json;
+END $$
+
+-- Helper procedure to convert a single message in wire JSON format to JSON, without converting the messages nested in it.
+-- Nested messages are left as placeholders and returned in nested_messages, with paths relative to the result
+-- (see _pb_wire_json_get_field_as_partial_json). repeated_element_count is the number of repeated field elements and
+-- map entries in the result.
+DROP PROCEDURE IF EXISTS _pb_wire_json_message_to_partial_json $$
+CREATE PROCEDURE _pb_wire_json_message_to_partial_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN wire_json JSON, IN as_number_json BOOLEAN, IN options JSON, OUT result JSON, OUT nested_messages JSON, OUT repeated_element_count INT)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+	
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE default_utf8_validation TEXT;
+	DECLARE fields JSON;
+	DECLARE field_map JSON;
+	DECLARE field_numbers JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT;
+	DECLARE field_descriptor JSON;
+	DECLARE unknown_fields JSON;
+	
+	-- Field properties
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE json_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE oneof_index INT;
+	DECLARE has_field_presence BOOLEAN;
+	
+	-- Processing variables
+	DECLARE field_json_value JSON;
+	DECLARE field_nested_messages JSON;
+	DECLARE json_field_name TEXT;
+	DECLARE is_populated BOOLEAN;
+	DECLARE elements JSON;
+	DECLARE element JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
 	
 	-- Oneof handling
 	DECLARE oneofs JSON;
 	DECLARE oneof_priority INT;
 	DECLARE oneof_priority_prev INT;
+
+	-- Options
+	DECLARE use_proto_names BOOLEAN;
+	DECLARE emit_unpopulated BOOLEAN;
+	DECLARE emit_default_values BOOLEAN;
+	DECLARE emit_unknown_fields BOOLEAN;
 	
-	SET @@SESSION.max_sp_recursion_depth = 255;
-	
-	IF buf IS NULL THEN
-		SET result = NULL;
-		LEAVE proc;
-	END IF;
+	SET nested_messages = JSON_ARRAY();
+	SET repeated_element_count = 0;
 	
 	-- Handle well-known types first
 	IF full_type_name LIKE '.google.protobuf.%' THEN
-		SET result = _pb_wire_json_decode_wkt_as_json(pb_message_to_wire_json(buf), full_type_name, as_number_json);
+		SET result = _pb_wire_json_decode_wkt_as_json(wire_json, full_type_name, as_number_json OR _pb_json_options_get_boolean(options, 'Int64AsNumber'));
 		IF result IS NOT NULL THEN
+			IF full_type_name = '.google.protobuf.BytesValue' THEN
+				SET result = _pb_json_encode_bytes(result, COALESCE(JSON_UNQUOTE(JSON_EXTRACT(options, '$.BytesEncoding')), 'base64'));
+			END IF;
 			LEAVE proc;
 		END IF;
 	END IF;
+
+	SET use_proto_names = _pb_json_options_get_boolean(options, 'UseProtoNames');
+	SET emit_unpopulated = _pb_json_options_get_boolean(options, 'EmitUnpopulated');
+	SET emit_default_values = _pb_json_options_get_boolean(options, 'EmitDefaultValues');
+	SET emit_unknown_fields = _pb_json_options_get_boolean(options, 'EmitUnknownFields');
 	
 	-- Get message descriptor
 	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
 	
 	IF message_descriptor IS NULL THEN
-		SET message_text = CONCAT('_pb_message_to_json: message type `', full_type_name, '` not found in descriptor set');
-		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', '_pb_message_to_json', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
 	END IF;
 	
 	-- Get file descriptor to determine syntax
-	SET file_descriptor = _pb_get_file_descriptor(descriptor_set_json, full_type_name);
-	SET syntax = JSON_UNQUOTE(JSON_EXTRACT(file_descriptor, '$."12"')); -- syntax field
-	IF syntax IS NULL THEN
-		SET syntax = 'proto2'; -- default
-	END IF;
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET default_utf8_validation = _pb_get_utf8_validation(descriptor_set_json, full_type_name);
 	
 	SET result = JSON_OBJECT();
 	SET oneofs = JSON_OBJECT();
-	SET wire_json = pb_message_to_wire_json(buf);
-	
-	-- Get fields array (field 2 in DescriptorProto)
-	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET unknown_fields = JSON_OBJECT();
 	
-	IF fields IS NOT NULL THEN
+	-- Map field numbers to field descriptors (fields are field 2 in DescriptorProto)
+	SET fields = COALESCE(JSON_EXTRACT(message_descriptor, '$."2"'), JSON_ARRAY());
+	SELECT COALESCE(JSON_OBJECTAGG(number, descriptor), JSON_OBJECT())
+	INTO field_map
+	FROM JSON_TABLE(fields, '$[*]' COLUMNS (
+		number INT PATH '$."3"',
+		descriptor JSON PATH '$'
+	)) jt;
+
+	-- Visit the fields present on the wire, followed by the unpopulated fields if they are to be emitted.
+	-- Each field is converted from its own wire elements rather than from the whole wire_json.
+	SET field_numbers = JSON_KEYS(wire_json);
+	IF emit_unpopulated OR emit_default_values THEN
 		SET field_count = JSON_LENGTH(fields);
 		SET field_index = 0;
-		
 		WHILE field_index < field_count DO
-			SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
-			
-			-- Extract field properties from FieldDescriptorProto
-			SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
-			SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
-			SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
-			SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
-			SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
-			SET json_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."10"')); -- json_name
-			SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
-			SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
-			SET default_value = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."7"')); -- default_value
-			
-			SET is_repeated = (field_label = 3); -- LABEL_REPEATED
-			
-			-- Check if this is a map field
-			SET is_map = FALSE;
-			IF field_type = 11 AND field_type_name IS NOT NULL THEN -- TYPE_MESSAGE
-				SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
-				SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+			SET field_number = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']."3"'));
+			IF NOT JSON_CONTAINS_PATH(wire_json, 'one', CONCAT('$."', field_number, '"')) THEN
+				SET field_numbers = JSON_ARRAY_APPEND(field_numbers, '$', CAST(field_number AS CHAR));
 			END IF;
+			SET field_index = field_index + 1;
+		END WHILE;
+	END IF;
+
+	SET field_count = JSON_LENGTH(field_numbers);
+	SET field_index = 0;
+
+	l1: WHILE field_index < field_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_index, ']')));
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		SET field_descriptor = JSON_EXTRACT(field_map, CONCAT('$."', field_number, '"'));
+		SET field_index = field_index + 1;
+
+		IF field_descriptor IS NULL THEN
+			-- Unknown field
+			IF emit_unknown_fields THEN
+				SET unknown_fields = JSON_SET(unknown_fields, CONCAT('$."', field_number, '"'), elements);
+			END IF;
+			ITERATE l1;
+		END IF;
+		
+		-- Extract field properties from FieldDescriptorProto
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET json_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."10"')); -- json_name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+
+		SET has_field_presence =
+			(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+			OR (syntax = 'proto3'
+				AND (
+					(field_label = 1 AND proto3_optional) -- proto3 optional
+					OR (field_label <> 3 AND field_type = 11) -- message fields
+					OR (oneof_index IS NOT NULL) -- oneof fields
+				));
+		
+		CALL _pb_wire_json_get_field_as_partial_json(descriptor_set_json, syntax, default_utf8_validation, IF(elements IS NULL, JSON_OBJECT(), JSON_OBJECT(CAST(field_number AS CHAR), elements)), field_descriptor, as_number_json, options, field_json_value, field_nested_messages);
+
+		-- A field is populated if it is set, or for fields without presence, if it has a non-default value
+		IF field_label = 3 THEN -- LABEL_REPEATED
+			SET is_populated = COALESCE(JSON_LENGTH(field_json_value) > 0, FALSE);
+		ELSEIF has_field_presence THEN
+			SET is_populated = elements IS NOT NULL;
+		ELSE
+			SET is_populated = elements IS NOT NULL AND JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, '].v'))) NOT IN ('0', '');
+		END IF;
+
+		-- Unpopulated fields are emitted in the same way as protojson's EmitUnpopulated and EmitDefaultValues options
+		IF NOT is_populated THEN
+			IF oneof_index IS NOT NULL OR NOT (emit_unpopulated OR emit_default_values) THEN
+				SET field_json_value = NULL;
+			ELSEIF field_label <> 3 AND has_field_presence THEN -- proto2 scalar or singular message fields
+				SET field_json_value = IF(emit_unpopulated, CAST('null' AS JSON), NULL);
+			END IF;
+		END IF;
+		
+		-- Add field to result if it has a value
+		IF field_json_value IS NOT NULL THEN
+			IF as_number_json THEN
+				SET json_field_name = CAST(field_number AS CHAR);
+			ELSEIF use_proto_names THEN
+				SET json_field_name = field_name;
+			ELSE
+				SET json_field_name = IF(json_name IS NOT NULL, json_name, _pb_util_snake_to_lower_camel(field_name));
+			END IF;
+
+			IF field_label = 3 THEN -- LABEL_REPEATED
+				SET repeated_element_count = repeated_element_count + JSON_LENGTH(field_json_value);
+			END IF;
+
+			-- Nested messages are placed under the field name. Those in a oneof member that is later overwritten are
+			-- skipped by _pb_json_resolve_nested_messages, as their placeholders never make it into the result.
+			SET element_count = JSON_LENGTH(field_nested_messages);
+			SET element_index = 0;
+			WHILE element_index < element_count DO
+				SET element = JSON_EXTRACT(field_nested_messages, CONCAT('$[', element_index, ']'));
+				SET element = JSON_SET(element, '$.p', CONCAT('."', json_field_name, '"', JSON_UNQUOTE(JSON_EXTRACT(element, '$.p'))));
+				SET nested_messages = JSON_ARRAY_APPEND(nested_messages, '$', element);
+				SET element_index = element_index + 1;
+			END WHILE;
 			
-			-- Determine field presence
-			SET has_field_presence =
-				(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
-				OR (syntax = 'proto3'
-					AND (
-						(field_label = 1 AND proto3_optional) -- proto3 optional
-						OR (field_label <> 3 AND field_type = 11) -- message fields
-						OR (oneof_index IS NOT NULL) -- oneof fields
-					));
-			
-			CASE field_type
-			WHEN 10 THEN -- TYPE_GROUP (unsupported)
-				SET message_text = CONCAT('_pb_message_to_json: unsupported field_type `', field_type, '` for field `', field_name, '` (', field_number, ').');
-				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
-			
-			WHEN 11 THEN -- TYPE_MESSAGE
-				IF is_map THEN
-					-- Handle map fields
-					SET elements = pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
-					SET element_count = JSON_LENGTH(elements);
-					SET element_index = 0;
-					SET field_json_value = JSON_OBJECT();
-					
-					-- Get map key/value field descriptors
-					SET map_key_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]'); -- first field (key)
-					SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]'); -- second field (value)
-					SET map_key_type = JSON_EXTRACT(map_key_field, '$."5"');
-					SET map_value_type = JSON_EXTRACT(map_value_field, '$."5"');
-					SET map_value_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
-					
-					WHILE element_index < element_count DO
-						SET element = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))));
-						CALL _pb_wire_json_get_primitive_field_as_json(element, 1, map_key_type, FALSE, FALSE, as_number_json, map_key);
-						
-						IF map_value_type = 11 THEN -- message
-							CALL _pb_message_to_json(descriptor_set_json, map_value_type_name, pb_wire_json_get_message_field(element, 2, NULL), as_number_json, map_value);
-						ELSEIF map_value_type = 14 THEN -- enum
-							IF as_number_json THEN
-								SET map_value = CAST(pb_wire_json_get_enum_field(element, 2, NULL) AS JSON);
-							ELSE
-								CALL _pb_enum_to_json(descriptor_set_json, map_value_type_name, pb_wire_json_get_enum_field(element, 2, NULL), map_value);
-							END IF;
-						ELSE
-							CALL _pb_wire_json_get_primitive_field_as_json(element, 2, map_value_type, FALSE, TRUE, as_number_json, map_value);
-						END IF;
-						
-						IF JSON_TYPE(map_key) = 'STRING' THEN
-							SET field_json_value = JSON_SET(field_json_value, CONCAT('$.', map_key), map_value);
-						ELSE
-							SET field_json_value = JSON_SET(field_json_value, CONCAT('$."', map_key, '"'), map_value);
-						END IF;
-						
-						SET element_index = element_index + 1;
-					END WHILE;
-					
-				ELSEIF is_repeated THEN
-					-- Handle repeated message fields
-					SET element_count = pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
-					SET element_index = 0;
-					SET field_json_value = JSON_ARRAY();
-					
-					WHILE element_index < element_count DO
-						SET bytes_value = pb_wire_json_get_repeated_message_field_element(wire_json, field_number, element_index);
-						CALL _pb_message_to_json(descriptor_set_json, field_type_name, bytes_value, as_number_json, nested_json_value);
-						SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', nested_json_value);
-						SET element_index = element_index + 1;
-					END WHILE;
-				ELSE
-					-- Handle singular message fields
-					SET bytes_value = pb_wire_json_get_message_field(wire_json, field_number, NULL);
-					IF bytes_value IS NULL THEN
-						SET field_json_value = NULL;
-					ELSE
-						CALL _pb_message_to_json(descriptor_set_json, field_type_name, bytes_value, as_number_json, nested_json_value);
-						SET field_json_value = nested_json_value;
-					END IF;
-				END IF;
-			
-			WHEN 14 THEN -- TYPE_ENUM
-				IF is_repeated THEN
-					SET elements = pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
-					SET element_count = JSON_LENGTH(elements);
-					SET element_index = 0;
-					SET field_json_value = JSON_ARRAY();
-					
-					WHILE element_index < element_count DO
-						SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
-						IF as_number_json THEN
-							SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', CAST(element AS JSON));
-						ELSE
-							CALL _pb_enum_to_json(descriptor_set_json, field_type_name, element, nested_json_value);
-							SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', nested_json_value);
-						END IF;
-						SET element_index = element_index + 1;
-					END WHILE;
-				ELSE
-					IF as_number_json THEN
-						SET field_json_value = CAST(pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0)) AS JSON);
-					ELSE
-						CALL _pb_enum_to_json(descriptor_set_json, field_type_name, pb_wire_json_get_enum_field(wire_json, field_number, IF(has_field_presence, NULL, 0)), field_json_value);
-					END IF;
+			IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+				-- Handle oneof fields; the field that appears last on the wire wins
+				SET oneof_priority = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements)-1, '].i'));
+				SET oneof_priority_prev = JSON_EXTRACT(oneofs, CONCAT('$."', oneof_index, '".i'));
+				
+				IF oneof_priority_prev IS NULL OR oneof_priority_prev < oneof_priority THEN
+					SET oneofs = JSON_SET(oneofs, CONCAT('$."', oneof_index, '"'), JSON_OBJECT('i', oneof_priority, 'v', JSON_OBJECT(json_field_name, field_json_value)));
 				END IF;
-			
 			ELSE
-				-- Handle primitive types using existing function
-				CALL _pb_wire_json_get_primitive_field_as_json(wire_json, field_number, field_type, is_repeated, has_field_presence, as_number_json, field_json_value);
-			END CASE;
-			
-			-- Add field to result if it has a value
-			IF field_json_value IS NOT NULL THEN
+				-- Regular field
 				IF as_number_json THEN
-					SET json_field_name = CAST(field_number AS CHAR);
-				ELSE
-					SET json_field_name = IF(json_name IS NOT NULL, json_name, _pb_util_snake_to_lower_camel(field_name));
-				END IF;
-				
-				IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
-					-- Handle oneof fields
-					SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
-					SET oneof_priority = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements)-1, '].i'));
-					SET oneof_priority_prev = JSON_EXTRACT(oneofs, CONCAT('$."', oneof_index, '".i'));
-					
-					IF oneof_priority_prev IS NULL OR oneof_priority_prev < oneof_priority THEN
-						SET oneofs = JSON_SET(oneofs, CONCAT('$."', oneof_index, '"'), JSON_OBJECT('i', oneof_priority, 'v', JSON_OBJECT(json_field_name, field_json_value)));
-					END IF;
+					-- For number JSON format, field names are numeric and need to be quoted in JSON paths
+					SET result = JSON_SET(result, CONCAT('$."', json_field_name, '"'), field_json_value);
 				ELSE
-					-- Regular field
-					IF as_number_json THEN
-						-- For number JSON format, field names are numeric and need to be quoted in JSON paths
-						SET result = JSON_SET(result, CONCAT('$."', json_field_name, '"'), field_json_value);
-					ELSE
-						SET result = JSON_SET(result, CONCAT('$.', json_field_name), field_json_value);
-					END IF;
+					SET result = JSON_SET(result, CONCAT('$.', json_field_name), field_json_value);
 				END IF;
 			END IF;
-			
-			SET field_index = field_index + 1;
-		END WHILE;
-	END IF;
+		END IF;
+	END WHILE;
 	
 	-- Add oneof fields to result
 	SET elements = JSON_EXTRACT(oneofs, '$.*.v');
@@ -412,6 +709,167 @@ proc: BEGIN
 		SET result = JSON_MERGE(result, field_json_value);
 		SET element_index = element_index + 1;
 	END WHILE;
+
+	-- Add unknown fields as wire JSON under a reserved key
+	IF emit_unknown_fields AND JSON_LENGTH(unknown_fields) > 0 THEN
+		SET result = JSON_SET(result, '$."@unknown"', unknown_fields);
+	END IF;
+END $$
+
+-- Helper procedure to fill in the placeholders of nested messages in a partially converted JSON value.
+-- The messages are converted one at a time from an explicit work stack instead of by recursive calls, so the
+-- conversion does not depend on max_sp_recursion_depth. Instead, it is bounded by the MaxDepth (default 100),
+-- MaxOutputSize and MaxElements options.
+DROP PROCEDURE IF EXISTS _pb_json_resolve_nested_messages $$
+CREATE PROCEDURE _pb_json_resolve_nested_messages(IN descriptor_set_json JSON, IN nested_messages JSON, IN as_number_json BOOLEAN, IN options JSON, INOUT result JSON)
+BEGIN
+	DECLARE message_text TEXT;
+	DECLARE stack JSON;
+	DECLARE stack_length INT;
+	DECLARE nested_message JSON;
+	DECLARE nested_message_count INT;
+	DECLARE nested_message_index INT;
+	DECLARE resolved_paths JSON;
+
+	-- Current message
+	DECLARE message_path TEXT;
+	DECLARE message_depth INT;
+	DECLARE message_field_path JSON;
+	DECLARE message_wire_json JSON;
+	DECLARE message_json JSON;
+	DECLARE message_nested_messages JSON;
+	DECLARE message_element_count INT;
+	DECLARE field_path_index INT;
+
+	-- Limits
+	DECLARE max_depth INT;
+	DECLARE max_output_size BIGINT;
+	DECLARE max_elements BIGINT;
+	DECLARE output_size BIGINT;
+	DECLARE element_count BIGINT DEFAULT 0;
+
+	SET max_depth = COALESCE(JSON_EXTRACT(options, '$.MaxDepth'), 100);
+	SET max_output_size = JSON_EXTRACT(options, '$.MaxOutputSize');
+	SET max_elements = JSON_EXTRACT(options, '$.MaxElements');
+	SET output_size = COALESCE(LENGTH(result), 0);
+
+	-- Stack entries have the same keys as nested_messages, with "p" made absolute, "d" for the nesting depth
+	-- and "f" replaced by the path of field numbers from the outermost message for error messages.
+	SET stack = JSON_ARRAY();
+	SET resolved_paths = JSON_ARRAY();
+	SET nested_message_count = JSON_LENGTH(nested_messages);
+	SET nested_message_index = 0;
+	WHILE nested_message_index < nested_message_count DO
+		SET nested_message = JSON_EXTRACT(nested_messages, CONCAT('$[', nested_message_index, ']'));
+		SET nested_message = JSON_SET(nested_message,
+			'$.p', CONCAT('$', JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.p'))),
+			'$.d', 1,
+			'$.f', IF(JSON_TYPE(JSON_EXTRACT(nested_message, '$.f')) IN ('INTEGER', 'UNSIGNED INTEGER'), JSON_ARRAY(JSON_EXTRACT(nested_message, '$.f')), JSON_ARRAY()));
+		SET stack = JSON_ARRAY_APPEND(stack, '$', nested_message);
+		SET nested_message_index = nested_message_index + 1;
+	END WHILE;
+
+	WHILE JSON_LENGTH(stack) > 0 DO
+		SET stack_length = JSON_LENGTH(stack);
+		SET nested_message = JSON_EXTRACT(stack, CONCAT('$[', stack_length - 1, ']'));
+		SET stack = JSON_REMOVE(stack, CONCAT('$[', stack_length - 1, ']'));
+
+		SET message_path = JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.p'));
+		SET message_depth = JSON_EXTRACT(nested_message, '$.d');
+		SET message_field_path = JSON_EXTRACT(nested_message, '$.f');
+
+		-- Skip messages whose placeholder was dropped, e.g. members of a oneof that is set again later on the wire.
+		-- Also skip messages whose placeholder was already filled in. Entries of a map with duplicate keys share the
+		-- same path, and the last one on the wire is popped first, so it wins as in protojson.
+		IF message_path = '$' OR (JSON_CONTAINS_PATH(result, 'one', message_path) AND NOT JSON_CONTAINS(resolved_paths, JSON_QUOTE(message_path))) THEN
+			IF message_depth > max_depth THEN
+				CALL _pb_signal_error('PB_DEPTH_LIMIT_EXCEEDED', '_pb_message_to_json', CONCAT('message nesting depth exceeds MaxDepth (', max_depth, ')'));
+			END IF;
+
+			message_block: BEGIN
+				-- Report decoder errors with the field path from the outermost message
+				DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003', SQLSTATE '45004', SQLSTATE '45005', SQLSTATE '45007'
+				BEGIN
+					GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+					SET field_path_index = JSON_LENGTH(message_field_path);
+					WHILE field_path_index > 0 DO
+						SET field_path_index = field_path_index - 1;
+						SET message_text = _pb_util_prefix_error_field_path(message_text, JSON_EXTRACT(message_field_path, CONCAT('$[', field_path_index, ']')));
+					END WHILE;
+					RESIGNAL SET MESSAGE_TEXT = message_text;
+				END;
+
+				IF JSON_CONTAINS_PATH(nested_message, 'one', '$.w') THEN
+					SET message_wire_json = JSON_EXTRACT(nested_message, '$.w');
+				ELSE
+					SET message_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.b'))));
+				END IF;
+				CALL _pb_wire_json_message_to_partial_json(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.t')), message_wire_json, as_number_json, options, message_json, message_nested_messages, message_element_count);
+			END message_block;
+
+			-- The 'null' placeholder being replaced is 4 bytes long
+			SET output_size = output_size + LENGTH(message_json) - IF(result IS NULL, 0, 4);
+			IF output_size > max_output_size THEN
+				CALL _pb_signal_error('PB_OUTPUT_SIZE_LIMIT_EXCEEDED', '_pb_message_to_json', CONCAT('JSON output exceeds MaxOutputSize (', max_output_size, ' bytes)'));
+			END IF;
+			SET element_count = element_count + message_element_count;
+			IF element_count > max_elements THEN
+				CALL _pb_signal_error('PB_ELEMENT_LIMIT_EXCEEDED', '_pb_message_to_json', CONCAT('number of repeated field elements and map entries exceeds MaxElements (', max_elements, ')'));
+			END IF;
+
+			IF message_path = '$' THEN
+				SET result = message_json;
+			ELSE
+				SET result = JSON_REPLACE(result, message_path, message_json);
+				SET resolved_paths = JSON_ARRAY_APPEND(resolved_paths, '$', message_path);
+			END IF;
+
+			SET nested_message_count = JSON_LENGTH(message_nested_messages);
+			SET nested_message_index = 0;
+			WHILE nested_message_index < nested_message_count DO
+				SET nested_message = JSON_EXTRACT(message_nested_messages, CONCAT('$[', nested_message_index, ']'));
+				SET nested_message = JSON_SET(nested_message,
+					'$.p', CONCAT(message_path, JSON_UNQUOTE(JSON_EXTRACT(nested_message, '$.p'))),
+					'$.d', message_depth + 1,
+					'$.f', JSON_ARRAY_APPEND(message_field_path, '$', JSON_EXTRACT(nested_message, '$.f')));
+				SET stack = JSON_ARRAY_APPEND(stack, '$', nested_message);
+				SET nested_message_index = nested_message_index + 1;
+			END WHILE;
+		END IF;
+	END WHILE;
+END $$
+
+-- Helper procedure to convert a single field of a message to JSON using its field descriptor, including nested messages
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_field_as_json $$
+CREATE PROCEDURE _pb_wire_json_get_field_as_json(IN descriptor_set_json JSON, IN syntax TEXT, IN default_utf8_validation TEXT, IN wire_json JSON, IN field_descriptor JSON, IN as_number_json BOOLEAN, IN options JSON, OUT field_json_value JSON)
+BEGIN
+	DECLARE nested_messages JSON;
+
+	CALL _pb_wire_json_get_field_as_partial_json(descriptor_set_json, syntax, default_utf8_validation, wire_json, field_descriptor, as_number_json, options, field_json_value, nested_messages);
+	IF field_json_value IS NOT NULL THEN
+		CALL _pb_json_resolve_nested_messages(descriptor_set_json, nested_messages, as_number_json, options, field_json_value);
+	END IF;
+END $$
+
+-- Main procedure for converting a message in wire JSON format to JSON using descriptor set
+DROP PROCEDURE IF EXISTS _pb_wire_json_to_json $$
+CREATE PROCEDURE _pb_wire_json_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN wire_json JSON, IN as_number_json BOOLEAN, IN options JSON, OUT result JSON)
+BEGIN
+	SET result = NULL;
+	IF wire_json IS NOT NULL THEN
+		CALL _pb_json_resolve_nested_messages(descriptor_set_json, JSON_ARRAY(JSON_OBJECT('p', '', 't', full_type_name, 'w', wire_json)), as_number_json, options, result);
+	END IF;
+END $$
+
+-- Main procedure for converting protobuf message to JSON using descriptor set
+DROP PROCEDURE IF EXISTS _pb_message_to_json $$
+CREATE PROCEDURE _pb_message_to_json(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN as_number_json BOOLEAN, IN options JSON, OUT result JSON)
+BEGIN
+	IF buf IS NULL THEN
+		SET result = NULL;
+	ELSE
+		CALL _pb_wire_json_to_json(descriptor_set_json, full_type_name, pb_message_to_wire_json(buf), as_number_json, options, result);
+	END IF;
 END $$
 
 -- Public function interface
@@ -419,7 +877,67 @@ DROP FUNCTION IF EXISTS pb_message_to_json $$
 CREATE FUNCTION pb_message_to_json(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_message_to_json(descriptor_set_json, type_name, message, FALSE, result);
+	CALL _pb_message_to_json(descriptor_set_json, type_name, message, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface that also emits fields not declared in the schema, as wire JSON under the "@unknown" key
+DROP FUNCTION IF EXISTS pb_message_to_json_with_unknown_fields $$
+CREATE FUNCTION pb_message_to_json_with_unknown_fields(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_to_json(descriptor_set_json, type_name, message, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE, 'EmitUnknownFields', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface with protojson-style marshal options
+DROP FUNCTION IF EXISTS pb_message_to_json_with_options $$
+CREATE FUNCTION pb_message_to_json_with_options(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, options JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE option_names JSON;
+	DECLARE option_count INT;
+	DECLARE option_index INT DEFAULT 0;
+	DECLARE option_name TEXT;
+	DECLARE option_value JSON;
+	DECLARE result JSON;
+
+	SET options = COALESCE(options, JSON_OBJECT());
+	IF JSON_TYPE(options) <> 'OBJECT' THEN
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = 'pb_message_to_json_with_options: options must be a JSON object';
+	END IF;
+
+	SET option_names = JSON_KEYS(options);
+	SET option_count = JSON_LENGTH(option_names);
+	WHILE option_index < option_count DO
+		SET option_name = JSON_UNQUOTE(JSON_EXTRACT(option_names, CONCAT('$[', option_index, ']')));
+		SET option_value = JSON_EXTRACT(options, CONCAT('$.', JSON_QUOTE(option_name)));
+		CASE
+		WHEN option_name IN ('UseProtoNames', 'EmitUnpopulated', 'EmitDefaultValues', 'UseEnumNumbers', 'Int64AsNumber', 'EmitUnknownFields') THEN
+			IF JSON_TYPE(option_value) <> 'BOOLEAN' THEN
+				SET message_text = CONCAT('pb_message_to_json_with_options: option `', option_name, '` must be a boolean');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+		WHEN option_name = 'BytesEncoding' THEN
+			IF JSON_TYPE(option_value) <> 'STRING' OR JSON_UNQUOTE(option_value) NOT IN ('base64', 'base64url', 'hex') THEN
+				SET message_text = CONCAT('pb_message_to_json_with_options: unsupported BytesEncoding ', option_value);
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+		WHEN option_name IN ('MaxDepth', 'MaxOutputSize', 'MaxElements') THEN
+			IF JSON_TYPE(option_value) NOT IN ('INTEGER', 'UNSIGNED INTEGER') OR option_value < 0 THEN
+				SET message_text = CONCAT('pb_message_to_json_with_options: option `', option_name, '` must be a non-negative integer');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+		ELSE
+			SET message_text = CONCAT('pb_message_to_json_with_options: unknown option `', option_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END CASE;
+		SET option_index = option_index + 1;
+	END WHILE;
+
+	CALL _pb_message_to_json(descriptor_set_json, type_name, message, FALSE, options, result);
 	RETURN result;
 END $$
 
@@ -428,6 +946,1224 @@ DROP FUNCTION IF EXISTS _pb_message_to_number_json $$
 CREATE FUNCTION _pb_message_to_number_json(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
 BEGIN
 	DECLARE result JSON;
-	CALL _pb_message_to_json(descriptor_set_json, type_name, message, TRUE, result);
+	CALL _pb_message_to_json(descriptor_set_json, type_name, message, TRUE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface for messages kept in wire JSON format, without serializing them to a message first
+DROP FUNCTION IF EXISTS pb_wire_json_to_json $$
+CREATE FUNCTION pb_wire_json_to_json(descriptor_set_json JSON, type_name TEXT, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_wire_json_to_json(descriptor_set_json, type_name, pb_wire_json_to_v1(wire_json), FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface for protonumberjson format from wire JSON
+DROP FUNCTION IF EXISTS pb_wire_json_to_number_json $$
+CREATE FUNCTION pb_wire_json_to_number_json(descriptor_set_json JSON, type_name TEXT, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_wire_json_to_json(descriptor_set_json, type_name, pb_wire_json_to_v1(wire_json), TRUE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	RETURN result;
+END $$
+
+-- Public function interface for converting every message in a length-delimited stream (see pb_delimited_count) to JSON
+DROP FUNCTION IF EXISTS pb_delimited_as_json_array $$
+CREATE FUNCTION pb_delimited_as_json_array(descriptor_set_json JSON, type_name TEXT, stream LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE stream_length BIGINT;
+	DECLARE offset BIGINT DEFAULT 0;
+	DECLARE element_length BIGINT;
+	DECLARE element_json JSON;
+	DECLARE result JSON;
+	DECLARE message_text TEXT;
+
+	-- Add the byte offset of the failing length prefix to decoder errors. Errors in the messages themselves are already
+	-- annotated with the offset in the message and left as is.
+	DECLARE EXIT HANDLER FOR SQLSTATE '45001', SQLSTATE '45002', SQLSTATE '45003'
+	BEGIN
+		GET DIAGNOSTICS CONDITION 1 message_text = MESSAGE_TEXT;
+		SET message_text = _pb_util_annotate_error(message_text, offset, NULL);
+		RESIGNAL SET MESSAGE_TEXT = message_text;
+	END;
+
+	IF stream IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET result = JSON_ARRAY();
+	SET stream_length = LENGTH(stream);
+	WHILE offset < stream_length DO
+		CALL _pb_delimited_read_element_header(stream, stream_length, offset, element_length);
+		CALL _pb_message_to_json(descriptor_set_json, type_name, SUBSTRING(stream, offset + 1, element_length), FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), element_json);
+		SET result = JSON_ARRAY_APPEND(result, '$', element_json);
+		SET offset = offset + element_length;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Procedure for extracting the JSON value at a field name path such as 'order.items[2].price' or 'labels["env"]'
+DROP PROCEDURE IF EXISTS _pb_message_get_json_by_path $$
+CREATE PROCEDURE _pb_message_get_json_by_path(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN path TEXT, OUT result JSON)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segments JSON;
+	DECLARE segment JSON;
+	DECLARE segment_count INT;
+	DECLARE segment_index INT;
+	DECLARE field_token TEXT;
+	DECLARE repeated_index INT;
+	DECLARE map_key TEXT;
+
+	DECLARE current_type_name TEXT;
+	DECLARE current_message LONGBLOB;
+	DECLARE message_descriptor JSON;
+	DECLARE field_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE wire_json JSON;
+	DECLARE field_json_value JSON;
+
+	-- Field properties
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE is_repeated BOOLEAN;
+	DECLARE is_map BOOLEAN;
+
+	-- Map handling
+	DECLARE map_entry_descriptor JSON;
+	DECLARE map_key_type INT;
+	DECLARE map_value_field JSON;
+	DECLARE entry_count INT;
+	DECLARE found_entry LONGBLOB;
+
+	IF message IS NULL OR path IS NULL THEN
+		SET result = NULL;
+		LEAVE proc;
+	END IF;
+
+	SET segments = _pb_util_parse_field_path(path);
+	SET segment_count = JSON_LENGTH(segments);
+
+	IF segment_count = 0 THEN
+		CALL _pb_message_to_json(descriptor_set_json, full_type_name, message, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+		LEAVE proc;
+	END IF;
+
+	SET current_type_name = full_type_name;
+	SET current_message = message;
+	SET segment_index = 0;
+
+	WHILE segment_index < segment_count DO
+		SET segment = JSON_EXTRACT(segments, CONCAT('$[', segment_index, ']'));
+		SET field_token = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.f'));
+		SET repeated_index = JSON_EXTRACT(segment, '$.i');
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.k'));
+
+		SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, current_type_name);
+		IF message_descriptor IS NULL THEN
+			CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_get_json_by_path', CONCAT('message type `', current_type_name, '` not found in descriptor set'));
+		END IF;
+
+		SET field_descriptor = _pb_get_field_descriptor_by_name(message_descriptor, field_token);
+		IF field_descriptor IS NULL THEN
+			SET message_text = CONCAT('pb_message_get_json_by_path: field `', field_token, '` not found in message type `', current_type_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET is_repeated = (field_label = 3); -- LABEL_REPEATED
+
+		SET is_map = FALSE;
+		IF field_type = 11 AND is_repeated THEN -- TYPE_MESSAGE
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		IF is_map AND repeated_index IS NOT NULL THEN
+			-- Unquoted integer subscripts on map fields are integer keys
+			SET map_key = CAST(repeated_index AS CHAR);
+			SET repeated_index = NULL;
+		END IF;
+
+		IF map_key IS NOT NULL AND NOT is_map THEN
+			SET message_text = CONCAT('pb_message_get_json_by_path: field `', field_token, '` is not a map field in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+		IF repeated_index IS NOT NULL AND (NOT is_repeated OR is_map) THEN
+			SET message_text = CONCAT('pb_message_get_json_by_path: field `', field_token, '` is not a repeated field in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET wire_json = pb_message_to_wire_json(current_message);
+
+		IF segment_index = segment_count - 1 THEN
+			-- Last segment: convert the field and pick the requested element
+			SET syntax = _pb_get_file_syntax(descriptor_set_json, current_type_name);
+			CALL _pb_wire_json_get_field_as_json(descriptor_set_json, syntax, _pb_get_utf8_validation(descriptor_set_json, current_type_name), wire_json, field_descriptor, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), field_json_value);
+
+			IF map_key IS NOT NULL THEN
+				SET result = JSON_EXTRACT(field_json_value, CONCAT('$.', JSON_QUOTE(map_key)));
+			ELSEIF repeated_index IS NOT NULL THEN
+				IF repeated_index < 0 THEN
+					SET repeated_index = JSON_LENGTH(field_json_value) + repeated_index;
+				END IF;
+				IF repeated_index < 0 THEN
+					SET result = NULL;
+				ELSE
+					SET result = JSON_EXTRACT(field_json_value, CONCAT('$[', repeated_index, ']'));
+				END IF;
+			ELSE
+				SET result = field_json_value;
+			END IF;
+			LEAVE proc;
+		END IF;
+
+		-- Intermediate segment: descend into a nested message
+		IF is_map THEN
+			SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+			SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]');
+			IF map_key IS NULL OR JSON_EXTRACT(map_value_field, '$."5"') <> 11 THEN
+				SET message_text = CONCAT('pb_message_get_json_by_path: cannot descend into map field `', field_token, '` in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET found_entry = _pb_wire_json_get_map_entry(wire_json, field_number, map_key_type, map_key);
+			IF found_entry IS NULL THEN
+				SET result = NULL;
+				LEAVE proc;
+			END IF;
+
+			SET current_message = pb_message_get_message_field(found_entry, 2, _binary '');
+			SET current_type_name = JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"'));
+		ELSEIF field_type <> 11 THEN
+			SET message_text = CONCAT('pb_message_get_json_by_path: field `', field_token, '` is not a message field in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		ELSEIF is_repeated THEN
+			IF repeated_index IS NULL THEN
+				SET message_text = CONCAT('pb_message_get_json_by_path: repeated field `', field_token, '` requires an index in path `', path, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+
+			SET entry_count = pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
+			IF repeated_index < 0 THEN
+				SET repeated_index = entry_count + repeated_index;
+			END IF;
+			IF repeated_index < 0 OR repeated_index >= entry_count THEN
+				SET result = NULL;
+				LEAVE proc;
+			END IF;
+
+			SET current_message = pb_wire_json_get_repeated_message_field_element(wire_json, field_number, repeated_index);
+			SET current_type_name = field_type_name;
+		ELSE
+			-- An absent message is read as an empty message so that default values are returned
+			SET current_message = pb_wire_json_get_message_field(wire_json, field_number, _binary '');
+			SET current_type_name = field_type_name;
+		END IF;
+
+		SET segment_index = segment_index + 1;
+	END WHILE;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_get_json_by_path $$
+CREATE FUNCTION pb_message_get_json_by_path(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, path TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_get_json_by_path(descriptor_set_json, type_name, message, path, result);
+	RETURN result;
+END $$
+
+-- Helper function to decode C-style escape sequences used by FieldDescriptorProto.default_value of bytes fields
+-- and by string literals in the protobuf text format
+DROP FUNCTION IF EXISTS _pb_util_c_unescape $$
+CREATE FUNCTION _pb_util_c_unescape(s LONGTEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB DEFAULT _binary '';
+	DECLARE i INT DEFAULT 1;
+	DECLARE c TEXT;
+	DECLARE digits TEXT;
+	DECLARE code_point INT;
+	DECLARE low_surrogate INT;
+
+	IF s IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	WHILE i <= CHAR_LENGTH(s) DO
+		SET c = SUBSTRING(s, i, 1);
+		IF c = '\\' AND i < CHAR_LENGTH(s) THEN
+			SET i = i + 1;
+			SET c = SUBSTRING(s, i, 1);
+			-- Escape letters are case-sensitive, so compare them in binary rather than with the case-insensitive collation
+			CASE
+			WHEN BINARY c = 'n' THEN SET result = CONCAT(result, _binary X'0A');
+			WHEN BINARY c = 'r' THEN SET result = CONCAT(result, _binary X'0D');
+			WHEN BINARY c = 't' THEN SET result = CONCAT(result, _binary X'09');
+			WHEN BINARY c = 'a' THEN SET result = CONCAT(result, _binary X'07');
+			WHEN BINARY c = 'b' THEN SET result = CONCAT(result, _binary X'08');
+			WHEN BINARY c = 'f' THEN SET result = CONCAT(result, _binary X'0C');
+			WHEN BINARY c = 'v' THEN SET result = CONCAT(result, _binary X'0B');
+			WHEN BINARY c IN ('u', 'U') THEN
+				SET digits = REGEXP_SUBSTR(SUBSTRING(s, i + 1, IF(BINARY c = 'u', 4, 8)), '^[0-9A-Fa-f]+');
+				SET code_point = CONV(digits, 16, 10);
+				SET i = i + CHAR_LENGTH(digits);
+				-- A high surrogate is combined with the following \uXXXX low surrogate
+				IF code_point BETWEEN 0xD800 AND 0xDBFF AND BINARY SUBSTRING(s, i + 1, 2) = '\\u' THEN
+					SET low_surrogate = CONV(REGEXP_SUBSTR(SUBSTRING(s, i + 3, 4), '^[0-9A-Fa-f]{4}'), 16, 10);
+					IF low_surrogate BETWEEN 0xDC00 AND 0xDFFF THEN
+						SET code_point = 0x10000 + ((code_point - 0xD800) << 10) + (low_surrogate - 0xDC00);
+						SET i = i + 6;
+					END IF;
+				END IF;
+				SET result = CONCAT(result, COALESCE(CAST(CONVERT(CHAR(code_point USING utf32) USING utf8mb4) AS BINARY), _binary ''));
+			WHEN BINARY c = 'x' THEN
+				SET digits = REGEXP_SUBSTR(SUBSTRING(s, i + 1, 2), '^[0-9A-Fa-f]+');
+				SET result = CONCAT(result, UNHEX(LPAD(digits, 2, '0')));
+				SET i = i + CHAR_LENGTH(digits);
+			WHEN c BETWEEN '0' AND '7' THEN
+				SET digits = REGEXP_SUBSTR(SUBSTRING(s, i, 3), '^[0-7]+');
+				SET result = CONCAT(result, UNHEX(LPAD(CONV(digits, 8, 16), 2, '0')));
+				SET i = i + CHAR_LENGTH(digits) - 1;
+			ELSE
+				SET result = CONCAT(result, CAST(c AS BINARY));
+			END CASE;
+		ELSE
+			SET result = CONCAT(result, CAST(c AS BINARY));
+		END IF;
+		SET i = i + 1;
+	END WHILE;
+
 	RETURN result;
 END $$
+
+-- Helper procedure to get the JSON representation of the default value of a field
+DROP PROCEDURE IF EXISTS _pb_get_field_default_as_json $$
+CREATE PROCEDURE _pb_get_field_default_as_json(IN descriptor_set_json JSON, IN field_descriptor JSON, OUT result JSON)
+BEGIN
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE default_value TEXT;
+
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET default_value = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."7"')); -- default_value
+
+	CASE
+	WHEN field_type = 11 THEN -- message
+		CALL _pb_message_to_json(descriptor_set_json, field_type_name, _binary '', FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+	WHEN field_type = 14 THEN -- enum
+		IF default_value IS NULL THEN
+			-- The first value of the enum is the default
+			SET default_value = JSON_UNQUOTE(JSON_EXTRACT(_pb_get_enum_descriptor(descriptor_set_json, field_type_name), '$."2"[0]."1"'));
+		END IF;
+		SET result = JSON_QUOTE(default_value);
+	WHEN field_type IN (1, 2) THEN -- double, float
+		CASE default_value
+		WHEN 'inf' THEN SET result = JSON_QUOTE('Infinity');
+		WHEN '-inf' THEN SET result = JSON_QUOTE('-Infinity');
+		WHEN 'nan' THEN SET result = JSON_QUOTE('NaN');
+		ELSE SET result = CAST(CAST(COALESCE(default_value, '0') AS DOUBLE) AS JSON);
+		END CASE;
+	WHEN field_type IN (3, 4, 6, 16, 18) THEN -- 64-bit integers
+		SET result = JSON_QUOTE(COALESCE(default_value, '0'));
+	WHEN field_type IN (5, 7, 13, 15, 17) THEN -- 32-bit integers
+		SET result = CAST(COALESCE(default_value, '0') AS JSON);
+	WHEN field_type = 8 THEN -- bool
+		SET result = CAST(((default_value = 'true') IS TRUE) AS JSON);
+	WHEN field_type = 9 THEN -- string
+		SET result = JSON_QUOTE(COALESCE(default_value, ''));
+	WHEN field_type = 12 THEN -- bytes
+		SET result = JSON_QUOTE(TO_BASE64(COALESCE(_pb_util_c_unescape(default_value), _binary '')));
+	ELSE
+		SET result = NULL;
+	END CASE;
+END $$
+
+-- Helper function to convert an enum value given as a JSON name or number into its number
+DROP FUNCTION IF EXISTS _pb_enum_from_json $$
+CREATE FUNCTION _pb_enum_from_json(descriptor_set_json JSON, full_type_name TEXT, json_value JSON) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE enum_name TEXT;
+	DECLARE enum_number JSON;
+
+	IF JSON_TYPE(json_value) <> 'STRING' THEN
+		RETURN CAST(json_value AS SIGNED);
+	END IF;
+
+	SET enum_name = JSON_UNQUOTE(json_value);
+	SET enum_number = JSON_EXTRACT(_pb_get_enum_map(descriptor_set_json, full_type_name), CONCAT('$.v.', JSON_QUOTE(enum_name)));
+	IF enum_number IS NOT NULL THEN
+		RETURN enum_number;
+	END IF;
+
+	SET message_text = CONCAT('_pb_enum_from_json: unknown value `', enum_name, '` for enum `', full_type_name, '`');
+	SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+END $$
+
+-- Helper function to determine whether a repeated field is written in packed encoding
+DROP FUNCTION IF EXISTS _pb_is_field_packed $$
+CREATE FUNCTION _pb_is_field_packed(syntax TEXT, field_descriptor JSON) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE field_type INT;
+	DECLARE packed_option JSON;
+
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	IF field_type IN (9, 10, 11, 12) THEN -- string, group, message, bytes
+		RETURN FALSE;
+	END IF;
+
+	SET packed_option = JSON_EXTRACT(field_descriptor, '$."8"."2"'); -- options.packed
+	IF packed_option IS NOT NULL THEN
+		RETURN packed_option = CAST('true' AS JSON);
+	END IF;
+
+	-- Scalar numeric fields are packed by default since proto3
+	RETURN syntax <> 'proto2';
+END $$
+
+-- Helper function to set a singular field value given as JSON
+DROP FUNCTION IF EXISTS _pb_message_set_singular_field_from_json $$
+CREATE FUNCTION _pb_message_set_singular_field_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE text_value LONGTEXT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET text_value = JSON_UNQUOTE(json_value);
+
+	IF field_type IN (9, 11, 12) AND JSON_TYPE(json_value) <> 'STRING' THEN
+		SET message_text = CONCAT('pb_message_set_field: expected a JSON string for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '`, but got ', JSON_TYPE(json_value));
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_set_double_field(message, field_number, CAST(text_value AS DOUBLE));
+	WHEN 2 THEN RETURN pb_message_set_float_field(message, field_number, CAST(text_value AS FLOAT));
+	WHEN 3 THEN RETURN pb_message_set_int64_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 4 THEN RETURN pb_message_set_uint64_field(message, field_number, CAST(text_value AS UNSIGNED));
+	WHEN 5 THEN RETURN pb_message_set_int32_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 6 THEN RETURN pb_message_set_fixed64_field(message, field_number, CAST(text_value AS UNSIGNED));
+	WHEN 7 THEN RETURN pb_message_set_fixed32_field(message, field_number, CAST(text_value AS UNSIGNED));
+	WHEN 8 THEN RETURN pb_message_set_bool_field(message, field_number, text_value = 'true' OR (text_value <> 'false' AND CAST(text_value AS SIGNED) <> 0));
+	WHEN 9 THEN RETURN pb_message_set_string_field(message, field_number, text_value);
+	WHEN 11 THEN RETURN pb_message_set_message_field(message, field_number, FROM_BASE64(text_value));
+	WHEN 12 THEN RETURN pb_message_set_bytes_field(message, field_number, FROM_BASE64(text_value));
+	WHEN 13 THEN RETURN pb_message_set_uint32_field(message, field_number, CAST(text_value AS UNSIGNED));
+	WHEN 14 THEN RETURN pb_message_set_enum_field(message, field_number, _pb_enum_from_json(descriptor_set_json, field_type_name, json_value));
+	WHEN 15 THEN RETURN pb_message_set_sfixed32_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 16 THEN RETURN pb_message_set_sfixed64_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 17 THEN RETURN pb_message_set_sint32_field(message, field_number, CAST(text_value AS SIGNED));
+	WHEN 18 THEN RETURN pb_message_set_sint64_field(message, field_number, CAST(text_value AS SIGNED));
+	ELSE
+		SET message_text = CONCAT('pb_message_set_field: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to set a repeated (non-map) field from a JSON array
+DROP FUNCTION IF EXISTS _pb_message_set_repeated_field_from_json $$
+CREATE FUNCTION _pb_message_set_repeated_field_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, use_packed BOOLEAN, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE enum_numbers JSON;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_set_repeated_double_field(message, field_number, json_value, use_packed);
+	WHEN 2 THEN RETURN pb_message_set_repeated_float_field(message, field_number, json_value, use_packed);
+	WHEN 3 THEN RETURN pb_message_set_repeated_int64_field(message, field_number, json_value, use_packed);
+	WHEN 4 THEN RETURN pb_message_set_repeated_uint64_field(message, field_number, json_value, use_packed);
+	WHEN 5 THEN RETURN pb_message_set_repeated_int32_field(message, field_number, json_value, use_packed);
+	WHEN 6 THEN RETURN pb_message_set_repeated_fixed64_field(message, field_number, json_value, use_packed);
+	WHEN 7 THEN RETURN pb_message_set_repeated_fixed32_field(message, field_number, json_value, use_packed);
+	WHEN 8 THEN RETURN pb_message_set_repeated_bool_field(message, field_number, json_value, use_packed);
+	WHEN 9 THEN RETURN pb_message_set_repeated_string_field(message, field_number, json_value);
+	WHEN 11 THEN RETURN pb_message_set_repeated_message_field(message, field_number, json_value);
+	WHEN 12 THEN RETURN pb_message_set_repeated_bytes_field(message, field_number, json_value);
+	WHEN 13 THEN RETURN pb_message_set_repeated_uint32_field(message, field_number, json_value, use_packed);
+	WHEN 14 THEN
+		-- Enum values may be given by name
+		SET enum_numbers = JSON_ARRAY();
+		SET element_count = JSON_LENGTH(json_value);
+		SET element_index = 0;
+		WHILE element_index < element_count DO
+			SET enum_numbers = JSON_ARRAY_APPEND(enum_numbers, '$', _pb_enum_from_json(descriptor_set_json, field_type_name, JSON_EXTRACT(json_value, CONCAT('$[', element_index, ']'))));
+			SET element_index = element_index + 1;
+		END WHILE;
+		RETURN pb_message_set_repeated_enum_field(message, field_number, enum_numbers, use_packed);
+	WHEN 15 THEN RETURN pb_message_set_repeated_sfixed32_field(message, field_number, json_value, use_packed);
+	WHEN 16 THEN RETURN pb_message_set_repeated_sfixed64_field(message, field_number, json_value, use_packed);
+	WHEN 17 THEN RETURN pb_message_set_repeated_sint32_field(message, field_number, json_value, use_packed);
+	WHEN 18 THEN RETURN pb_message_set_repeated_sint64_field(message, field_number, json_value, use_packed);
+	ELSE
+		SET message_text = CONCAT('pb_message_set_field: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to build a map entry message from a key given as text and a value given as JSON
+DROP FUNCTION IF EXISTS _pb_message_new_map_entry_from_json $$
+CREATE FUNCTION _pb_message_new_map_entry_from_json(descriptor_set_json JSON, map_entry_descriptor JSON, map_key TEXT, value_json JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE map_key_field JSON;
+	DECLARE map_key_json JSON;
+	DECLARE entry LONGBLOB;
+
+	SET map_key_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]');
+	IF JSON_EXTRACT(map_key_field, '$."5"') = 8 THEN -- bool
+		SET map_key_json = CAST(((map_key = 'true') IS TRUE) AS JSON);
+	ELSE
+		SET map_key_json = JSON_QUOTE(map_key);
+	END IF;
+
+	SET entry = _pb_message_set_singular_field_from_json(descriptor_set_json, pb_message_new(), map_key_field, map_key_json);
+	IF value_json IS NOT NULL THEN
+		SET entry = _pb_message_set_singular_field_from_json(descriptor_set_json, entry, JSON_EXTRACT(map_entry_descriptor, '$."2"[1]'), value_json);
+	END IF;
+
+	RETURN entry;
+END $$
+
+-- Helper function to set a map field from a JSON object
+DROP FUNCTION IF EXISTS _pb_message_set_map_field_from_json $$
+CREATE FUNCTION _pb_message_set_map_field_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE field_number INT;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE map_keys JSON;
+	DECLARE map_key TEXT;
+	DECLARE key_count INT;
+	DECLARE key_index INT;
+	DECLARE entry LONGBLOB;
+	DECLARE result LONGBLOB;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')));
+
+	SET result = pb_wire_json_to_message(_pb_wire_json_clear_field(pb_message_to_wire_json(message), field_number));
+	SET map_keys = JSON_KEYS(json_value);
+	SET key_count = JSON_LENGTH(map_keys);
+	SET key_index = 0;
+
+	WHILE key_index < key_count DO
+		SET map_key = JSON_UNQUOTE(JSON_EXTRACT(map_keys, CONCAT('$[', key_index, ']')));
+		SET entry = _pb_message_new_map_entry_from_json(descriptor_set_json, map_entry_descriptor, map_key, JSON_EXTRACT(json_value, CONCAT('$.', JSON_QUOTE(map_key))));
+		SET result = pb_message_add_repeated_message_field_element(result, field_number, entry);
+		SET key_index = key_index + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper function to look up a field descriptor by name, signaling an error if it does not exist
+DROP FUNCTION IF EXISTS _pb_get_field_descriptor_by_type_and_name $$
+CREATE FUNCTION _pb_get_field_descriptor_by_type_and_name(descriptor_set_json JSON, type_name TEXT, field_name TEXT, func_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE field_descriptor JSON;
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', func_name, CONCAT('message type `', type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET field_descriptor = _pb_get_field_descriptor_by_name(message_descriptor, field_name);
+	IF field_descriptor IS NULL THEN
+		SET message_text = CONCAT(func_name, ': field `', field_name, '` not found in message type `', type_name, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	RETURN field_descriptor;
+END $$
+
+-- Procedure for reading a single field by name as JSON, with default values applied
+DROP PROCEDURE IF EXISTS _pb_message_get_field $$
+CREATE PROCEDURE _pb_message_get_field(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN field_name TEXT, OUT result JSON)
+proc: BEGIN
+	DECLARE field_descriptor JSON;
+
+	IF message IS NULL THEN
+		SET result = NULL;
+		LEAVE proc;
+	END IF;
+
+	SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, full_type_name, field_name, 'pb_message_get_field');
+
+	CALL _pb_wire_json_get_field_as_json(descriptor_set_json, _pb_get_file_syntax(descriptor_set_json, full_type_name), _pb_get_utf8_validation(descriptor_set_json, full_type_name), pb_message_to_wire_json(message), field_descriptor, FALSE, JSON_OBJECT('EmitDefaultValues', TRUE), result);
+
+	IF result IS NULL THEN
+		CALL _pb_get_field_default_as_json(descriptor_set_json, field_descriptor, result);
+	END IF;
+END $$
+
+DROP FUNCTION IF EXISTS pb_message_get_field $$
+CREATE FUNCTION pb_message_get_field(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, field_name TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	CALL _pb_message_get_field(descriptor_set_json, type_name, message, field_name, result);
+	RETURN result;
+END $$
+
+-- Sets a single field by name from a JSON value. A JSON null clears the field.
+DROP FUNCTION IF EXISTS pb_message_set_field $$
+CREATE FUNCTION pb_message_set_field(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, field_name TEXT, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE is_map BOOLEAN;
+	DECLARE wire_json JSON;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, type_name, field_name, 'pb_message_set_field');
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+	SET wire_json = _pb_wire_json_clear_field(pb_message_to_wire_json(message), field_number);
+
+	IF json_value IS NULL OR JSON_TYPE(json_value) = 'NULL' THEN
+		RETURN pb_wire_json_to_message(wire_json);
+	END IF;
+
+	-- Setting a member of a oneof clears the other members
+	SET wire_json = _pb_wire_json_clear_oneof_members(descriptor_set_json, type_name, wire_json, field_descriptor);
+
+	SET message = pb_wire_json_to_message(wire_json);
+
+	IF field_label = 3 THEN -- LABEL_REPEATED
+		SET is_map = field_type = 11 AND COALESCE(CAST(JSON_EXTRACT(_pb_get_message_descriptor(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"'))), '$."7"."7"') AS UNSIGNED), FALSE);
+		IF is_map THEN
+			IF JSON_TYPE(json_value) <> 'OBJECT' THEN
+				SET message_text = CONCAT('pb_message_set_field: expected a JSON object for map field `', field_name, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			RETURN _pb_message_set_map_field_from_json(descriptor_set_json, message, field_descriptor, json_value);
+		END IF;
+
+		IF JSON_TYPE(json_value) <> 'ARRAY' THEN
+			SET message_text = CONCAT('pb_message_set_field: expected a JSON array for repeated field `', field_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+		RETURN _pb_message_set_repeated_field_from_json(descriptor_set_json, message, field_descriptor, _pb_is_field_packed(_pb_get_file_syntax(descriptor_set_json, type_name), field_descriptor), json_value);
+	END IF;
+
+	RETURN _pb_message_set_singular_field_from_json(descriptor_set_json, message, field_descriptor, json_value);
+END $$
+
+-- Helper function to find the positions of map entries with the given key within the wire JSON elements of a map field
+DROP FUNCTION IF EXISTS _pb_wire_json_find_map_entries $$
+CREATE FUNCTION _pb_wire_json_find_map_entries(wire_json JSON, field_number INT, map_key_type INT, map_key TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE entry_wire_json JSON;
+	DECLARE map_key_json JSON;
+	DECLARE positions JSON;
+
+	SET positions = JSON_ARRAY();
+	SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET element_count = COALESCE(JSON_LENGTH(elements), 0);
+	SET element_index = 0;
+
+	WHILE element_index < element_count DO
+		SET entry_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, '].v')))));
+		CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 1, map_key_type, FALSE, FALSE, FALSE, map_key_json);
+		IF BINARY JSON_UNQUOTE(map_key_json) = BINARY map_key THEN
+			SET positions = JSON_ARRAY_APPEND(positions, '$', element_index);
+		END IF;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN positions;
+END $$
+
+-- Helper function to get the map entry with the given key. Later entries win, as in the wire format.
+DROP FUNCTION IF EXISTS _pb_wire_json_get_map_entry $$
+CREATE FUNCTION _pb_wire_json_get_map_entry(wire_json JSON, field_number INT, map_key_type INT, map_key TEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE positions JSON;
+
+	SET positions = _pb_wire_json_find_map_entries(wire_json, field_number, map_key_type, map_key);
+	IF JSON_LENGTH(positions) = 0 THEN
+		RETURN NULL;
+	END IF;
+
+	RETURN FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"[', JSON_EXTRACT(positions, '$[last]'), '].v'))));
+END $$
+
+-- Helper function to replace (or remove, if entry is NULL) the map entry with the given key.
+-- The last existing entry is replaced in place and duplicates are removed. A new entry is appended if none exists.
+DROP FUNCTION IF EXISTS _pb_wire_json_set_map_entry $$
+CREATE FUNCTION _pb_wire_json_set_map_entry(wire_json JSON, field_number INT, map_key_type INT, map_key TEXT, entry LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE positions JSON;
+	DECLARE position_index INT;
+
+	SET positions = _pb_wire_json_find_map_entries(wire_json, field_number, map_key_type, map_key);
+	SET position_index = JSON_LENGTH(positions) - 1;
+
+	IF entry IS NOT NULL THEN
+		IF position_index < 0 THEN
+			RETURN pb_wire_json_add_repeated_message_field_element(wire_json, field_number, entry);
+		END IF;
+		SET wire_json = JSON_SET(wire_json, CONCAT(field_path, '[', JSON_EXTRACT(positions, CONCAT('$[', position_index, ']')), '].v'), TO_BASE64(entry));
+		SET position_index = position_index - 1;
+	END IF;
+
+	-- Remove from the end so that the remaining positions stay valid
+	WHILE position_index >= 0 DO
+		SET wire_json = JSON_REMOVE(wire_json, CONCAT(field_path, '[', JSON_EXTRACT(positions, CONCAT('$[', position_index, ']')), ']'));
+		SET position_index = position_index - 1;
+	END WHILE;
+
+	IF JSON_LENGTH(wire_json, field_path) = 0 THEN
+		SET wire_json = JSON_REMOVE(wire_json, field_path);
+	END IF;
+
+	RETURN wire_json;
+END $$
+
+-- Helper function to replace the value of a singular length-delimited field, keeping its position in the message
+DROP FUNCTION IF EXISTS _pb_wire_json_replace_len_field $$
+CREATE FUNCTION _pb_wire_json_replace_len_field(wire_json JSON, field_number INT, value LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE element_index INT;
+
+	SET element_index = JSON_LENGTH(wire_json, field_path) - 1;
+	IF element_index IS NULL OR element_index < 0 THEN
+		RETURN _pb_wire_json_set_len_field(wire_json, field_number, value);
+	END IF;
+
+	-- The last occurrence is replaced in place and the earlier ones are removed
+	SET wire_json = JSON_SET(wire_json, CONCAT(field_path, '[', element_index, '].v'), TO_BASE64(value));
+	SET element_index = element_index - 1;
+	WHILE element_index >= 0 DO
+		SET wire_json = JSON_REMOVE(wire_json, CONCAT(field_path, '[', element_index, ']'));
+		SET element_index = element_index - 1;
+	END WHILE;
+
+	RETURN wire_json;
+END $$
+
+-- Helper function to get a singular message field with all of its occurrences merged.
+-- Concatenating serialized messages is equivalent to merging them, so this returns NULL if the field is absent.
+DROP FUNCTION IF EXISTS _pb_wire_json_get_merged_message_field $$
+CREATE FUNCTION _pb_wire_json_get_merged_message_field(wire_json JSON, field_number INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE result LONGBLOB;
+
+	SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+	SET element_count = COALESCE(JSON_LENGTH(elements), 0);
+
+	WHILE element_index < element_count DO
+		IF JSON_EXTRACT(elements, CONCAT('$[', element_index, '].t')) = 2 THEN -- LEN
+			SET result = CONCAT(COALESCE(result, _binary ''), FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, '].v')))));
+		END IF;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper function to append wire elements (e.g. taken from another message) to a field, renumbering them to come last
+DROP FUNCTION IF EXISTS _pb_wire_json_append_elements $$
+CREATE FUNCTION _pb_wire_json_append_elements(wire_json JSON, field_number INT, elements JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE field_path TEXT DEFAULT CONCAT('$."', field_number, '"');
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE next_index INT;
+
+	SET element_count = COALESCE(JSON_LENGTH(elements), 0);
+	IF element_count = 0 THEN
+		RETURN wire_json;
+	END IF;
+
+	SET next_index = _pb_wire_json_get_next_index(wire_json);
+	IF NOT JSON_CONTAINS_PATH(wire_json, 'one', field_path) THEN
+		SET wire_json = JSON_SET(wire_json, field_path, JSON_ARRAY());
+	END IF;
+
+	WHILE element_index < element_count DO
+		SET wire_json = JSON_ARRAY_APPEND(wire_json, field_path, JSON_SET(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')), '$.i', next_index, '$.n', field_number));
+		SET next_index = next_index + 1;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN wire_json;
+END $$
+
+-- Helper function to clear all members of the oneof that the given field belongs to. Does nothing for other fields.
+DROP FUNCTION IF EXISTS _pb_wire_json_clear_oneof_members $$
+CREATE FUNCTION _pb_wire_json_clear_oneof_members(descriptor_set_json JSON, type_name TEXT, wire_json JSON, field_descriptor JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE oneof_index INT;
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+
+	SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+	IF oneof_index IS NULL OR COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE) THEN -- proto3_optional
+		RETURN wire_json;
+	END IF;
+
+	SET fields = JSON_EXTRACT(_pb_get_message_descriptor(descriptor_set_json, type_name), '$."2"');
+	SET field_count = JSON_LENGTH(fields);
+	WHILE field_index < field_count DO
+		IF JSON_EXTRACT(fields, CONCAT('$[', field_index, ']."9"')) = oneof_index THEN
+			SET wire_json = _pb_wire_json_clear_field(wire_json, JSON_EXTRACT(fields, CONCAT('$[', field_index, ']."3"')));
+		END IF;
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	RETURN wire_json;
+END $$
+
+-- Helper function to count the elements of a repeated field using its field descriptor
+DROP FUNCTION IF EXISTS _pb_message_get_repeated_field_count $$
+CREATE FUNCTION _pb_message_get_repeated_field_count(message LONGBLOB, field_descriptor JSON) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_get_repeated_double_field_count(message, field_number);
+	WHEN 2 THEN RETURN pb_message_get_repeated_float_field_count(message, field_number);
+	WHEN 3 THEN RETURN pb_message_get_repeated_int64_field_count(message, field_number);
+	WHEN 4 THEN RETURN pb_message_get_repeated_uint64_field_count(message, field_number);
+	WHEN 5 THEN RETURN pb_message_get_repeated_int32_field_count(message, field_number);
+	WHEN 6 THEN RETURN pb_message_get_repeated_fixed64_field_count(message, field_number);
+	WHEN 7 THEN RETURN pb_message_get_repeated_fixed32_field_count(message, field_number);
+	WHEN 8 THEN RETURN pb_message_get_repeated_bool_field_count(message, field_number);
+	WHEN 9 THEN RETURN pb_message_get_repeated_string_field_count(message, field_number);
+	WHEN 11 THEN RETURN pb_message_get_repeated_message_field_count(message, field_number);
+	WHEN 12 THEN RETURN pb_message_get_repeated_bytes_field_count(message, field_number);
+	WHEN 13 THEN RETURN pb_message_get_repeated_uint32_field_count(message, field_number);
+	WHEN 14 THEN RETURN pb_message_get_repeated_enum_field_count(message, field_number);
+	WHEN 15 THEN RETURN pb_message_get_repeated_sfixed32_field_count(message, field_number);
+	WHEN 16 THEN RETURN pb_message_get_repeated_sfixed64_field_count(message, field_number);
+	WHEN 17 THEN RETURN pb_message_get_repeated_sint32_field_count(message, field_number);
+	WHEN 18 THEN RETURN pb_message_get_repeated_sint64_field_count(message, field_number);
+	ELSE
+		SET message_text = CONCAT('_pb_message_get_repeated_field_count: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to set an element of a repeated field from a JSON value
+DROP FUNCTION IF EXISTS _pb_message_set_repeated_field_element_from_json $$
+CREATE FUNCTION _pb_message_set_repeated_field_element_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, repeated_index INT, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE text_value LONGTEXT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET text_value = JSON_UNQUOTE(json_value);
+
+	IF field_type IN (9, 11, 12) AND JSON_TYPE(json_value) <> 'STRING' THEN
+		SET message_text = CONCAT('pb_message_set_by_path: expected a JSON string for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '`, but got ', JSON_TYPE(json_value));
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_set_repeated_double_field_element(message, field_number, repeated_index, CAST(text_value AS DOUBLE));
+	WHEN 2 THEN RETURN pb_message_set_repeated_float_field_element(message, field_number, repeated_index, CAST(text_value AS FLOAT));
+	WHEN 3 THEN RETURN pb_message_set_repeated_int64_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 4 THEN RETURN pb_message_set_repeated_uint64_field_element(message, field_number, repeated_index, CAST(text_value AS UNSIGNED));
+	WHEN 5 THEN RETURN pb_message_set_repeated_int32_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 6 THEN RETURN pb_message_set_repeated_fixed64_field_element(message, field_number, repeated_index, CAST(text_value AS UNSIGNED));
+	WHEN 7 THEN RETURN pb_message_set_repeated_fixed32_field_element(message, field_number, repeated_index, CAST(text_value AS UNSIGNED));
+	WHEN 8 THEN RETURN pb_message_set_repeated_bool_field_element(message, field_number, repeated_index, text_value = 'true' OR (text_value <> 'false' AND CAST(text_value AS SIGNED) <> 0));
+	WHEN 9 THEN RETURN pb_message_set_repeated_string_field_element(message, field_number, repeated_index, text_value);
+	WHEN 11 THEN RETURN pb_message_set_repeated_message_field_element(message, field_number, repeated_index, FROM_BASE64(text_value));
+	WHEN 12 THEN RETURN pb_message_set_repeated_bytes_field_element(message, field_number, repeated_index, FROM_BASE64(text_value));
+	WHEN 13 THEN RETURN pb_message_set_repeated_uint32_field_element(message, field_number, repeated_index, CAST(text_value AS UNSIGNED));
+	WHEN 14 THEN RETURN pb_message_set_repeated_enum_field_element(message, field_number, repeated_index, _pb_enum_from_json(descriptor_set_json, field_type_name, json_value));
+	WHEN 15 THEN RETURN pb_message_set_repeated_sfixed32_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 16 THEN RETURN pb_message_set_repeated_sfixed64_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 17 THEN RETURN pb_message_set_repeated_sint32_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	WHEN 18 THEN RETURN pb_message_set_repeated_sint64_field_element(message, field_number, repeated_index, CAST(text_value AS SIGNED));
+	ELSE
+		SET message_text = CONCAT('pb_message_set_by_path: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to append an element to a repeated field from a JSON value
+DROP FUNCTION IF EXISTS _pb_message_add_repeated_field_element_from_json $$
+CREATE FUNCTION _pb_message_add_repeated_field_element_from_json(descriptor_set_json JSON, message LONGBLOB, field_descriptor JSON, use_packed BOOLEAN, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE text_value LONGTEXT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET text_value = JSON_UNQUOTE(json_value);
+
+	IF field_type IN (9, 11, 12) AND JSON_TYPE(json_value) <> 'STRING' THEN
+		SET message_text = CONCAT('pb_message_patch: expected a JSON string for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '`, but got ', JSON_TYPE(json_value));
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_add_repeated_double_field_element(message, field_number, CAST(text_value AS DOUBLE), use_packed);
+	WHEN 2 THEN RETURN pb_message_add_repeated_float_field_element(message, field_number, CAST(text_value AS FLOAT), use_packed);
+	WHEN 3 THEN RETURN pb_message_add_repeated_int64_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 4 THEN RETURN pb_message_add_repeated_uint64_field_element(message, field_number, CAST(text_value AS UNSIGNED), use_packed);
+	WHEN 5 THEN RETURN pb_message_add_repeated_int32_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 6 THEN RETURN pb_message_add_repeated_fixed64_field_element(message, field_number, CAST(text_value AS UNSIGNED), use_packed);
+	WHEN 7 THEN RETURN pb_message_add_repeated_fixed32_field_element(message, field_number, CAST(text_value AS UNSIGNED), use_packed);
+	WHEN 8 THEN RETURN pb_message_add_repeated_bool_field_element(message, field_number, text_value = 'true' OR (text_value <> 'false' AND CAST(text_value AS SIGNED) <> 0), use_packed);
+	WHEN 9 THEN RETURN pb_message_add_repeated_string_field_element(message, field_number, text_value);
+	WHEN 11 THEN RETURN pb_message_add_repeated_message_field_element(message, field_number, FROM_BASE64(text_value));
+	WHEN 12 THEN RETURN pb_message_add_repeated_bytes_field_element(message, field_number, FROM_BASE64(text_value));
+	WHEN 13 THEN RETURN pb_message_add_repeated_uint32_field_element(message, field_number, CAST(text_value AS UNSIGNED), use_packed);
+	WHEN 14 THEN RETURN pb_message_add_repeated_enum_field_element(message, field_number, _pb_enum_from_json(descriptor_set_json, field_type_name, json_value), use_packed);
+	WHEN 15 THEN RETURN pb_message_add_repeated_sfixed32_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 16 THEN RETURN pb_message_add_repeated_sfixed64_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 17 THEN RETURN pb_message_add_repeated_sint32_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	WHEN 18 THEN RETURN pb_message_add_repeated_sint64_field_element(message, field_number, CAST(text_value AS SIGNED), use_packed);
+	ELSE
+		SET message_text = CONCAT('pb_message_patch: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to remove an element of a repeated field
+DROP FUNCTION IF EXISTS _pb_message_remove_repeated_field_element $$
+CREATE FUNCTION _pb_message_remove_repeated_field_element(message LONGBLOB, field_descriptor JSON, repeated_index INT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_message_remove_repeated_double_field_element(message, field_number, repeated_index);
+	WHEN 2 THEN RETURN pb_message_remove_repeated_float_field_element(message, field_number, repeated_index);
+	WHEN 3 THEN RETURN pb_message_remove_repeated_int64_field_element(message, field_number, repeated_index);
+	WHEN 4 THEN RETURN pb_message_remove_repeated_uint64_field_element(message, field_number, repeated_index);
+	WHEN 5 THEN RETURN pb_message_remove_repeated_int32_field_element(message, field_number, repeated_index);
+	WHEN 6 THEN RETURN pb_message_remove_repeated_fixed64_field_element(message, field_number, repeated_index);
+	WHEN 7 THEN RETURN pb_message_remove_repeated_fixed32_field_element(message, field_number, repeated_index);
+	WHEN 8 THEN RETURN pb_message_remove_repeated_bool_field_element(message, field_number, repeated_index);
+	WHEN 9 THEN RETURN pb_message_remove_repeated_string_field_element(message, field_number, repeated_index);
+	WHEN 11 THEN RETURN pb_message_remove_repeated_message_field_element(message, field_number, repeated_index);
+	WHEN 12 THEN RETURN pb_message_remove_repeated_bytes_field_element(message, field_number, repeated_index);
+	WHEN 13 THEN RETURN pb_message_remove_repeated_uint32_field_element(message, field_number, repeated_index);
+	WHEN 14 THEN RETURN pb_message_remove_repeated_enum_field_element(message, field_number, repeated_index);
+	WHEN 15 THEN RETURN pb_message_remove_repeated_sfixed32_field_element(message, field_number, repeated_index);
+	WHEN 16 THEN RETURN pb_message_remove_repeated_sfixed64_field_element(message, field_number, repeated_index);
+	WHEN 17 THEN RETURN pb_message_remove_repeated_sint32_field_element(message, field_number, repeated_index);
+	WHEN 18 THEN RETURN pb_message_remove_repeated_sint64_field_element(message, field_number, repeated_index);
+	ELSE
+		SET message_text = CONCAT('pb_message_clear_by_path: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper procedure to allow the recursive CALLs that schema-aware functions use for nested messages. Returns the caller's
+-- max_sp_recursion_depth, which must be put back with _pb_restore_recursion_depth, also when an error is raised.
+DROP PROCEDURE IF EXISTS _pb_raise_recursion_depth $$
+CREATE PROCEDURE _pb_raise_recursion_depth(OUT saved_recursion_depth INT)
+BEGIN
+	SET saved_recursion_depth = @@SESSION.max_sp_recursion_depth;
+	SET @@SESSION.max_sp_recursion_depth = 255;
+END $$
+
+-- Helper procedure to put back the max_sp_recursion_depth returned by _pb_raise_recursion_depth.
+-- Does nothing if it was not raised yet.
+DROP PROCEDURE IF EXISTS _pb_restore_recursion_depth $$
+CREATE PROCEDURE _pb_restore_recursion_depth(IN saved_recursion_depth INT)
+BEGIN
+	IF saved_recursion_depth IS NOT NULL THEN
+		SET @@SESSION.max_sp_recursion_depth = saved_recursion_depth;
+	END IF;
+END $$
+
+-- Helper procedure to limit the nesting depth of messages handled by recursive CALLs, where the top-level message is at
+-- depth 1. The limit is the default MaxDepth of pb_message_to_json.
+DROP PROCEDURE IF EXISTS _pb_check_message_depth $$
+CREATE PROCEDURE _pb_check_message_depth(IN message_depth INT, IN function_name TEXT)
+BEGIN
+	IF message_depth > 100 THEN
+		CALL _pb_signal_error('PB_DEPTH_LIMIT_EXCEEDED', function_name, 'message nesting depth exceeds 100');
+	END IF;
+END $$
+
+-- Recursive procedure for setting or clearing the value at a field name path.
+-- Only the length-delimited fields enclosing the target are rewritten; everything else is kept as-is.
+-- With allow_append, an index equal to the element count in the last segment appends a new element.
+DROP PROCEDURE IF EXISTS _pb_message_set_by_path $$
+CREATE PROCEDURE _pb_message_set_by_path(IN descriptor_set_json JSON, IN full_type_name TEXT, IN message LONGBLOB, IN path TEXT, IN segments JSON, IN segment_index INT, IN json_value JSON, IN is_clear BOOLEAN, IN allow_append BOOLEAN, OUT result LONGBLOB)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE func_name TEXT;
+	DECLARE segment JSON;
+	DECLARE field_token TEXT;
+	DECLARE repeated_index INT;
+	DECLARE map_key TEXT;
+	DECLARE is_leaf BOOLEAN;
+
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE is_repeated BOOLEAN;
+	DECLARE is_map BOOLEAN;
+
+	DECLARE map_entry_descriptor JSON;
+	DECLARE map_key_type INT;
+	DECLARE map_value_field JSON;
+	DECLARE wire_json JSON;
+	DECLARE entry LONGBLOB;
+	DECLARE child LONGBLOB;
+	DECLARE element_count INT;
+
+	SET func_name = IF(is_clear, 'pb_message_clear_by_path', 'pb_message_set_by_path');
+
+	-- The message at segment_index is nested segment_index levels below the top-level message
+	CALL _pb_check_message_depth(segment_index + 1, func_name);
+
+	SET segment = JSON_EXTRACT(segments, CONCAT('$[', segment_index, ']'));
+	SET field_token = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.f'));
+	SET repeated_index = JSON_EXTRACT(segment, '$.i');
+	SET map_key = JSON_UNQUOTE(JSON_EXTRACT(segment, '$.k'));
+	SET is_leaf = (segment_index = JSON_LENGTH(segments) - 1);
+
+	SET field_descriptor = _pb_get_field_descriptor_by_type_and_name(descriptor_set_json, full_type_name, field_token, func_name);
+	SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+	SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+	SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+	SET is_repeated = (field_label = 3); -- LABEL_REPEATED
+
+	SET is_map = FALSE;
+	IF field_type = 11 AND is_repeated THEN -- TYPE_MESSAGE
+		SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+		SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+	END IF;
+
+	IF is_map AND repeated_index IS NOT NULL THEN
+		-- Unquoted integer subscripts on map fields are integer keys
+		SET map_key = CAST(repeated_index AS CHAR);
+		SET repeated_index = NULL;
+	END IF;
+
+	IF map_key IS NOT NULL AND NOT is_map THEN
+		SET message_text = CONCAT(func_name, ': field `', field_token, '` is not a map field in path `', path, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+	IF repeated_index IS NOT NULL AND (NOT is_repeated OR is_map) THEN
+		SET message_text = CONCAT(func_name, ': field `', field_token, '` is not a repeated field in path `', path, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	IF is_map THEN
+		SET map_key_type = JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"');
+		SET map_value_field = JSON_EXTRACT(map_entry_descriptor, '$."2"[1]');
+	END IF;
+
+	IF repeated_index IS NOT NULL THEN
+		SET element_count = _pb_message_get_repeated_field_count(message, field_descriptor);
+		IF repeated_index < 0 THEN
+			SET repeated_index = element_count + repeated_index;
+		END IF;
+		IF (repeated_index < 0 OR repeated_index >= element_count) AND NOT (allow_append AND is_leaf AND repeated_index = element_count) THEN
+			IF is_clear THEN
+				-- Nothing to clear
+				SET result = message;
+				LEAVE proc;
+			END IF;
+			SET message_text = CONCAT(func_name, ': index out of range for field `', field_token, '` in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+	END IF;
+
+	IF is_leaf THEN
+		IF map_key IS NOT NULL THEN
+			SET wire_json = pb_message_to_wire_json(message);
+			IF is_clear THEN
+				SET entry = NULL;
+			ELSE
+				SET entry = _pb_message_new_map_entry_from_json(descriptor_set_json, map_entry_descriptor, map_key, json_value);
+			END IF;
+			SET result = pb_wire_json_to_message(_pb_wire_json_set_map_entry(wire_json, field_number, map_key_type, map_key, entry));
+		ELSEIF repeated_index IS NOT NULL THEN
+			IF is_clear THEN
+				SET result = _pb_message_remove_repeated_field_element(message, field_descriptor, repeated_index);
+			ELSEIF repeated_index = element_count THEN
+				SET result = _pb_message_add_repeated_field_element_from_json(descriptor_set_json, message, field_descriptor, _pb_is_field_packed(_pb_get_file_syntax(descriptor_set_json, full_type_name), field_descriptor), json_value);
+			ELSE
+				SET result = _pb_message_set_repeated_field_element_from_json(descriptor_set_json, message, field_descriptor, repeated_index, json_value);
+			END IF;
+		ELSE
+			SET result = pb_message_set_field(descriptor_set_json, full_type_name, message, field_token, IF(is_clear, NULL, json_value));
+		END IF;
+		LEAVE proc;
+	END IF;
+
+	-- Intermediate segment: update the nested message and write it back to its enclosing field
+	IF is_map THEN
+		IF map_key IS NULL OR JSON_EXTRACT(map_value_field, '$."5"') <> 11 THEN
+			SET message_text = CONCAT(func_name, ': cannot descend into map field `', field_token, '` in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET wire_json = pb_message_to_wire_json(message);
+		SET entry = _pb_wire_json_get_map_entry(wire_json, field_number, map_key_type, map_key);
+		IF entry IS NULL THEN
+			IF is_clear THEN
+				SET result = message;
+				LEAVE proc;
+			END IF;
+			SET entry = _pb_message_new_map_entry_from_json(descriptor_set_json, map_entry_descriptor, map_key, NULL);
+		END IF;
+
+		CALL _pb_message_set_by_path(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(map_value_field, '$."6"')), pb_message_get_message_field(entry, 2, _binary ''), path, segments, segment_index + 1, json_value, is_clear, allow_append, child);
+		SET entry = pb_message_set_message_field(entry, 2, child);
+		SET result = pb_wire_json_to_message(_pb_wire_json_set_map_entry(wire_json, field_number, map_key_type, map_key, entry));
+	ELSEIF field_type <> 11 THEN
+		SET message_text = CONCAT(func_name, ': field `', field_token, '` is not a message field in path `', path, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	ELSEIF is_repeated THEN
+		IF repeated_index IS NULL THEN
+			SET message_text = CONCAT(func_name, ': repeated field `', field_token, '` requires an index in path `', path, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		CALL _pb_message_set_by_path(descriptor_set_json, field_type_name, pb_message_get_repeated_message_field_element(message, field_number, repeated_index), path, segments, segment_index + 1, json_value, is_clear, allow_append, child);
+		SET result = pb_message_set_repeated_message_field_element(message, field_number, repeated_index, child);
+	ELSE
+		IF is_clear AND NOT pb_message_has_message_field(message, field_number) THEN
+			SET result = message;
+			LEAVE proc;
+		END IF;
+
+		CALL _pb_message_set_by_path(descriptor_set_json, field_type_name, pb_message_get_message_field(message, field_number, _binary ''), path, segments, segment_index + 1, json_value, is_clear, allow_append, child);
+		IF pb_message_has_message_field(message, field_number) THEN
+			SET result = pb_wire_json_to_message(_pb_wire_json_replace_len_field(pb_message_to_wire_json(message), field_number, child));
+		ELSE
+			-- Missing intermediate messages are created (which also clears other members of a oneof)
+			SET result = pb_message_set_field(descriptor_set_json, full_type_name, message, field_token, JSON_QUOTE(TO_BASE64(child)));
+		END IF;
+	END IF;
+END $$
+
+DROP FUNCTION IF EXISTS _pb_message_set_or_clear_by_path $$
+CREATE FUNCTION _pb_message_set_or_clear_by_path(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, path TEXT, json_value JSON, is_clear BOOLEAN) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE segments JSON;
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL OR path IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET segments = _pb_util_parse_field_path(path);
+	IF JSON_LENGTH(segments) = 0 THEN
+		SET message_text = CONCAT(IF(is_clear, 'pb_message_clear_by_path', 'pb_message_set_by_path'), ': path must not be empty');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_set_by_path(descriptor_set_json, type_name, message, path, segments, 0, json_value, is_clear, FALSE, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Sets the value at a field name path such as 'config.retry.backoff.max_ms', creating missing intermediate messages
+DROP FUNCTION IF EXISTS pb_message_set_by_path $$
+CREATE FUNCTION pb_message_set_by_path(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, path TEXT, json_value JSON) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_set_or_clear_by_path(descriptor_set_json, type_name, message, path, json_value, FALSE);
+END $$
+
+-- Clears the value at a field name path. Repeated elements and map entries addressed by the path are removed.
+DROP FUNCTION IF EXISTS pb_message_clear_by_path $$
+CREATE FUNCTION pb_message_clear_by_path(descriptor_set_json JSON, type_name TEXT, message LONGBLOB, path TEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	RETURN _pb_message_set_or_clear_by_path(descriptor_set_json, type_name, message, path, NULL, TRUE);
+END $$
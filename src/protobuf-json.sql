@@ -3,8 +3,204 @@ DELIMITER $$
 DROP FUNCTION IF EXISTS _pb_util_snake_to_lower_camel $$
 CREATE FUNCTION _pb_util_snake_to_lower_camel(s TEXT) RETURNS TEXT DETERMINISTIC
 BEGIN
-	-- TODO: implement
-	RETURN s;
+	DECLARE result TEXT DEFAULT '';
+	DECLARE i INT DEFAULT 1;
+	DECLARE c TEXT;
+	DECLARE capitalize_next BOOLEAN DEFAULT FALSE;
+
+	IF s IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	-- Same as ToJsonName() in protoc: drop underscores and capitalize the following letter
+	WHILE i <= CHAR_LENGTH(s) DO
+		SET c = SUBSTRING(s, i, 1);
+		IF c = '_' THEN
+			SET capitalize_next = TRUE;
+		ELSEIF capitalize_next THEN
+			SET result = CONCAT(result, UPPER(c));
+			SET capitalize_next = FALSE;
+		ELSE
+			SET result = CONCAT(result, c);
+		END IF;
+		SET i = i + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper procedure to get a string field as JSON with the given UTF-8 validation mode (see _pb_util_check_utf8).
+-- JSON text cannot hold ill-formed UTF-8, so 'passthrough' replaces ill-formed sequences in the same way as 'replace'.
+DROP PROCEDURE IF EXISTS _pb_wire_json_get_string_field_as_json $$
+CREATE PROCEDURE _pb_wire_json_get_string_field_as_json(IN wire_json JSON, IN field_number INT, IN is_repeated BOOLEAN, IN has_field_presence BOOLEAN, IN utf8_validation TEXT, OUT field_json_value JSON)
+BEGIN
+	DECLARE elements JSON;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE element_count INT;
+
+	IF utf8_validation = 'passthrough' THEN
+		SET utf8_validation = 'replace';
+	END IF;
+
+	IF is_repeated THEN
+		SET elements = pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json, field_number);
+		SET element_count = JSON_LENGTH(elements);
+		SET field_json_value = JSON_ARRAY();
+		WHILE element_index < element_count DO
+			SET field_json_value = JSON_ARRAY_APPEND(field_json_value, '$', CONVERT(_pb_util_check_utf8(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))), utf8_validation) USING utf8mb4));
+			SET element_index = element_index + 1;
+		END WHILE;
+	ELSE
+		SET field_json_value = JSON_QUOTE(CONVERT(_pb_util_check_utf8(pb_wire_json_get_bytes_field(wire_json, field_number, IF(has_field_presence, NULL, _binary X'')), utf8_validation) USING utf8mb4));
+	END IF;
+END $$
+
+-- Helper function to format the bit pattern of a finite float in the same way as protojson: the shortest decimal that
+-- rounds back to the same float, using exponent notation below 1e-6 and from 1e21. Unlike widening the float to a
+-- DOUBLE, this does not print digits that are only significant for doubles (e.g. 0.10000000149011612 for 0.1).
+DROP FUNCTION IF EXISTS _pb_util_format_shortest_float $$
+CREATE FUNCTION _pb_util_format_shortest_float(bits INT UNSIGNED) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE sign TEXT;
+	DECLARE biased_exponent INT;
+	DECLARE fraction INT;
+	DECLARE significand INT;
+	DECLARE binary_exponent INT;
+	DECLARE magnitude DOUBLE;
+	DECLARE lower_bound DOUBLE;
+	DECLARE upper_bound DOUBLE;
+	DECLARE is_even BOOLEAN;
+	DECLARE digit_count INT DEFAULT 1;
+	DECLARE first_digit_exponent INT;
+	DECLARE mantissa BIGINT;
+	DECLARE exponent INT; -- decimal exponent of the last digit of mantissa
+	DECLARE candidate DOUBLE;
+	DECLARE attempt INT;
+	DECLARE digits TEXT;
+
+	SET sign = IF(bits >> 31 = 0, '', '-');
+	SET biased_exponent = (bits >> 23) & 0xFF;
+	SET fraction = bits & 0x7FFFFF;
+
+	IF biased_exponent = 0 THEN -- zero or subnormal number
+		SET significand = fraction;
+		SET binary_exponent = -149;
+	ELSE -- normal number
+		SET significand = fraction | 0x800000;
+		SET binary_exponent = biased_exponent - 150;
+	END IF;
+
+	IF significand = 0 THEN
+		RETURN CONCAT(sign, '0');
+	END IF;
+
+	-- All of these are exact in DOUBLE. Any decimal between the bounds rounds to this float, and so do the bounds
+	-- themselves if the significand is even (round half to even). The gap below a power of two is half as wide.
+	SET magnitude = significand * POW(2, binary_exponent);
+	SET upper_bound = (significand * 2 + 1) * POW(2, binary_exponent - 1);
+	IF fraction = 0 AND biased_exponent > 1 THEN
+		SET lower_bound = (significand * 4 - 1) * POW(2, binary_exponent - 2);
+	ELSE
+		SET lower_bound = (significand * 2 - 1) * POW(2, binary_exponent - 1);
+	END IF;
+	SET is_even = significand % 2 = 0;
+
+	-- Try the nearest decimal with 1, 2, ... significant digits; 9 digits always round-trip.
+	SET first_digit_exponent = FLOOR(LOG10(magnitude));
+	WHILE digits IS NULL DO
+		SET exponent = first_digit_exponent - digit_count + 1;
+		SET mantissa = ROUND(IF(exponent < 0, magnitude * POW(10, -exponent), magnitude / POW(10, exponent)));
+		SET attempt = 0;
+		WHILE digits IS NULL AND attempt < 2 DO
+			SET candidate = CAST(CONCAT(mantissa, 'e', exponent) AS DOUBLE);
+			IF (candidate > lower_bound AND candidate < upper_bound)
+				OR (is_even AND (candidate = lower_bound OR candidate = upper_bound))
+				OR digit_count >= 17 THEN
+				SET digits = CAST(mantissa AS CHAR);
+			ELSE
+				-- Near a power of two the bounds are asymmetric, so the neighbor on the other side may still round-trip
+				SET mantissa = mantissa + IF(candidate < magnitude, 1, -1);
+			END IF;
+			SET attempt = attempt + 1;
+		END WHILE;
+		SET digit_count = digit_count + 1;
+	END WHILE;
+
+	-- Drop trailing zeros
+	WHILE RIGHT(digits, 1) = '0' DO
+		SET digits = LEFT(digits, CHAR_LENGTH(digits) - 1);
+		SET exponent = exponent + 1;
+	END WHILE;
+	SET digit_count = CHAR_LENGTH(digits);
+	SET first_digit_exponent = exponent + digit_count - 1;
+
+	-- float32(1e-6) and float32(1e21)
+	IF magnitude < 9.999999974752427e-07 OR magnitude >= 1.0000000200408773e+21 THEN
+		RETURN CONCAT(sign, LEFT(digits, 1), IF(digit_count > 1, CONCAT('.', SUBSTRING(digits, 2)), ''),
+			'e', IF(first_digit_exponent < 0, CONCAT('-', -first_digit_exponent), CONCAT('+', LPAD(first_digit_exponent, 2, '0'))));
+	ELSEIF exponent >= 0 THEN
+		RETURN CONCAT(sign, digits, REPEAT('0', exponent));
+	ELSEIF first_digit_exponent >= 0 THEN
+		RETURN CONCAT(sign, LEFT(digits, first_digit_exponent + 1), '.', SUBSTRING(digits, first_digit_exponent + 2));
+	ELSE
+		RETURN CONCAT(sign, '0.', REPEAT('0', -first_digit_exponent - 1), digits);
+	END IF;
+END $$
+
+-- Helper function to convert the bit pattern of a float (bit_size = 32) or double (bit_size = 64) to JSON in the same
+-- way as protojson. NaN and infinities become the strings "NaN", "Infinity" and "-Infinity".
+DROP FUNCTION IF EXISTS _pb_util_floating_point_bits_to_json $$
+CREATE FUNCTION _pb_util_floating_point_bits_to_json(bits BIGINT UNSIGNED, bit_size INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE double_value DOUBLE;
+
+	IF bits IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	IF bit_size = 32 THEN
+		IF ((bits >> 23) & 0xFF) = 0xFF THEN
+			RETURN JSON_QUOTE(IF((bits & 0x7FFFFF) <> 0, 'NaN', IF((bits >> 31) = 0, 'Infinity', '-Infinity')));
+		ELSEIF bits = 0x80000000 THEN
+			RETURN CAST(_pb_util_reinterpret_uint32_as_float(bits) AS JSON); -- keeps the sign of -0
+		END IF;
+		RETURN CAST(_pb_util_format_shortest_float(bits) AS JSON);
+	END IF;
+
+	IF ((bits >> 52) & 0x7FF) = 0x7FF THEN
+		RETURN JSON_QUOTE(IF((bits & 0xFFFFFFFFFFFFF) <> 0, 'NaN', IF((bits >> 63) = 0, 'Infinity', '-Infinity')));
+	END IF;
+
+	-- MySQL already prints a DOUBLE in JSON as the shortest decimal that round-trips, but adds '.0' to integral values
+	-- (e.g. 3.0 where protojson writes 3). Integral values that fit in a BIGINT (UNSIGNED) are written as integers instead.
+	-- Negative zero stays a DOUBLE to keep its sign.
+	SET double_value = _pb_util_reinterpret_uint64_as_double(bits);
+	IF bits <> 0x8000000000000000 AND double_value = FLOOR(double_value) THEN
+		IF ABS(double_value) < 9223372036854775808 THEN
+			RETURN CAST(CAST(double_value AS SIGNED) AS JSON);
+		ELSEIF double_value > 0 AND double_value < 18446744073709551616 THEN
+			RETURN CAST(CAST(double_value AS UNSIGNED) AS JSON);
+		END IF;
+	END IF;
+	RETURN CAST(double_value AS JSON);
+END $$
+
+-- Helper function to convert a JSON array of float (bit_size = 32) or double (bit_size = 64) bit patterns with _pb_util_floating_point_bits_to_json
+DROP FUNCTION IF EXISTS _pb_util_floating_point_bits_array_to_json $$
+CREATE FUNCTION _pb_util_floating_point_bits_array_to_json(bits_array JSON, bit_size INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE element_count INT;
+
+	SET result = JSON_ARRAY();
+	SET element_count = JSON_LENGTH(bits_array);
+	WHILE element_index < element_count DO
+		SET result = JSON_ARRAY_APPEND(result, '$', _pb_util_floating_point_bits_to_json(CAST(JSON_UNQUOTE(JSON_EXTRACT(bits_array, CONCAT('$[', element_index, ']'))) AS UNSIGNED), bit_size));
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN result;
 END $$
 
 DROP PROCEDURE IF EXISTS _pb_wire_json_get_primitive_field_as_json $$
@@ -16,15 +212,15 @@ BEGIN
 	CASE field_type
 	WHEN 1 THEN -- double
 		IF is_repeated THEN
-			SET field_json_value = pb_wire_json_get_repeated_double_field_as_json_array(wire_json, field_number);
+			SET field_json_value = _pb_util_floating_point_bits_array_to_json(pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number), 64);
 		ELSE
-			SET field_json_value = CAST(pb_wire_json_get_double_field(wire_json, field_number, IF(has_field_presence, NULL, 0)) AS JSON);
+			SET field_json_value = _pb_util_floating_point_bits_to_json(pb_wire_json_get_fixed64_field(wire_json, field_number, IF(has_field_presence, NULL, 0)), 64);
 		END IF;
 	WHEN 2 THEN -- float
 		IF is_repeated THEN
-			SET field_json_value = pb_wire_json_get_repeated_float_field_as_json_array(wire_json, field_number);
+			SET field_json_value = _pb_util_floating_point_bits_array_to_json(pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number), 32);
 		ELSE
-			SET field_json_value = CAST(pb_wire_json_get_float_field(wire_json, field_number, IF(has_field_presence, NULL, 0)) AS JSON);
+			SET field_json_value = _pb_util_floating_point_bits_to_json(pb_wire_json_get_fixed32_field(wire_json, field_number, IF(has_field_presence, NULL, 0)), 32);
 		END IF;
 	WHEN 3 THEN -- int64
 		IF is_repeated THEN
@@ -93,11 +289,7 @@ BEGIN
 			END IF;
 		END IF;
 	WHEN 9 THEN -- string
-		IF is_repeated THEN
-			SET field_json_value = pb_wire_json_get_repeated_string_field_as_json_array(wire_json, field_number);
-		ELSE
-			SET field_json_value = JSON_QUOTE(pb_wire_json_get_string_field(wire_json, field_number, IF(has_field_presence, NULL, '')));
-		END IF;
+		CALL _pb_wire_json_get_string_field_as_json(wire_json, field_number, is_repeated, has_field_presence, 'strict', field_json_value);
 	WHEN 12 THEN -- bytes
 		IF is_repeated THEN
 			SET field_json_value = pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json, field_number);
@@ -301,7 +493,7 @@ BEGIN
 	DECLARE wire_type INT;
 	DECLARE field_number INT;
 	DECLARE result JSON;
-	DECLARE uint_value BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
 	DECLARE bytes_value LONGBLOB;
 
 	SET result = JSON_OBJECT();
@@ -327,7 +519,7 @@ BEGIN
 			SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
 			CASE field_number
 			WHEN 3 THEN -- string_value
-				SET result = JSON_QUOTE(CONVERT(bytes_value USING utf8mb4));
+				SET result = JSON_QUOTE(CONVERT(_pb_util_check_utf8(bytes_value, 'strict') USING utf8mb4));
 			WHEN 5 THEN -- struct_value
 				SET result = _pb_wire_json_decode_wkt_struct_as_json(pb_message_to_wire_json(bytes_value));
 			WHEN 6 THEN -- list_value
@@ -337,7 +529,11 @@ BEGIN
 			SET uint_value = CAST(JSON_EXTRACT(element, '$.v') AS UNSIGNED);
 			CASE field_number
 			WHEN 2 THEN -- double_value
-				SET result = CAST(_pb_util_reinterpret_uint64_as_double(uint_value) AS JSON);
+				-- Same as protojson, which has no JSON representation for a Value of NaN or infinity
+				IF ((uint_value >> 52) & 0x7FF) = 0x7FF THEN
+					CALL _pb_signal_error('PB_NON_FINITE_VALUE', '_pb_wire_json_decode_wkt_value_as_json', 'number_value must be finite');
+				END IF;
+				SET result = _pb_util_floating_point_bits_to_json(uint_value, 64);
 			END CASE;
 		END CASE;
 
@@ -425,7 +621,7 @@ BEGIN
 
 		CASE wire_type
 		WHEN 2 THEN -- LEN
-			SET string_value = CONVERT(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v'))) USING utf8mb4);
+			SET string_value = CONVERT(_pb_util_check_utf8(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v'))), 'strict') USING utf8mb4);
 			CASE field_number
 			WHEN 1 THEN -- values
 				SET result = CONCAT(result, sep, string_value);
@@ -456,9 +652,9 @@ BEGIN
 	WHEN '.google.protobuf.Empty' THEN
 		RETURN JSON_OBJECT();
 	WHEN '.google.protobuf.DoubleValue' THEN
-		RETURN CAST(pb_wire_json_get_double_field(wire_json, 1, 0.0) AS JSON);
+		RETURN _pb_util_floating_point_bits_to_json(pb_wire_json_get_fixed64_field(wire_json, 1, 0), 64);
 	WHEN '.google.protobuf.FloatValue' THEN
-		RETURN CAST(pb_wire_json_get_float_field(wire_json, 1, 0.0) AS JSON);
+		RETURN _pb_util_floating_point_bits_to_json(pb_wire_json_get_fixed32_field(wire_json, 1, 0), 32);
 	WHEN '.google.protobuf.Int64Value' THEN
 		IF as_number_json THEN
 			RETURN CAST(pb_wire_json_get_int64_field(wire_json, 1, 0) AS JSON);
@@ -0,0 +1,275 @@
+DELIMITER $$
+
+-- Helper function to renumber wire elements so that they are serialized in order, starting at start_index
+DROP FUNCTION IF EXISTS _pb_wire_json_renumber_elements $$
+CREATE FUNCTION _pb_wire_json_renumber_elements(elements JSON, start_index INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+
+	SET element_count = JSON_LENGTH(elements);
+	WHILE element_index < element_count DO
+		SET elements = JSON_SET(elements, CONCAT('$[', element_index, '].i'), start_index + element_index);
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN elements;
+END $$
+
+-- Helper function to get the member of each oneof that is set last on the wire, as {"<oneof_index>": <field_number>}
+DROP FUNCTION IF EXISTS _pb_wire_json_get_oneof_winners $$
+CREATE FUNCTION _pb_wire_json_get_oneof_winners(message_descriptor JSON, wire_json JSON) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE winners JSON;
+	DECLARE last_indexes JSON;
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE oneof_index INT;
+	DECLARE elements JSON;
+	DECLARE last_index INT;
+
+	SET winners = JSON_OBJECT();
+	SET last_indexes = JSON_OBJECT();
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+
+		IF oneof_index IS NOT NULL AND NOT COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE) AND elements IS NOT NULL THEN -- proto3_optional
+			SET last_index = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, '].i'));
+			IF JSON_EXTRACT(last_indexes, CONCAT('$."', oneof_index, '"')) IS NULL OR JSON_EXTRACT(last_indexes, CONCAT('$."', oneof_index, '"')) < last_index THEN
+				SET last_indexes = JSON_SET(last_indexes, CONCAT('$."', oneof_index, '"'), last_index);
+				SET winners = JSON_SET(winners, CONCAT('$."', oneof_index, '"'), field_number);
+			END IF;
+		END IF;
+
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	RETURN winners;
+END $$
+
+-- Helper function to get a text that identifies the key of a serialized map entry.
+-- Entries with equal keys get the same identity, including entries without a key (which have the default key).
+DROP FUNCTION IF EXISTS _pb_message_get_map_entry_key_identity $$
+CREATE FUNCTION _pb_message_get_map_entry_key_identity(entry LONGBLOB, key_type INT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE key_elements JSON;
+
+	SET key_elements = JSON_EXTRACT(pb_message_to_wire_json(entry), '$."1"');
+	IF key_elements IS NULL THEN
+		RETURN IF(key_type IN (9, 12), '', '0'); -- string and bytes are base64-encoded on the wire
+	END IF;
+
+	RETURN JSON_UNQUOTE(JSON_EXTRACT(key_elements, CONCAT('$[', JSON_LENGTH(key_elements) - 1, '].v')));
+END $$
+
+-- Helper function to merge the wire elements of two map fields, replacing entries of a with entries of b that have the same key
+DROP FUNCTION IF EXISTS _pb_wire_json_merge_map_entries $$
+CREATE FUNCTION _pb_wire_json_merge_map_entries(elements JSON, key_type INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	DECLARE positions JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT DEFAULT 0;
+	DECLARE element JSON;
+	DECLARE key_path TEXT;
+	DECLARE position INT;
+
+	SET result = JSON_ARRAY();
+	SET positions = JSON_OBJECT();
+	SET element_count = JSON_LENGTH(elements);
+
+	WHILE element_index < element_count DO
+		SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+		SET key_path = CONCAT('$."k', _pb_message_get_map_entry_key_identity(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v'))), key_type), '"');
+		SET position = JSON_EXTRACT(positions, key_path);
+		IF position IS NULL THEN
+			SET positions = JSON_SET(positions, key_path, JSON_LENGTH(result));
+			SET result = JSON_ARRAY_APPEND(result, '$', element);
+		ELSE
+			-- The later entry replaces the earlier one, at the position where the key first appeared
+			SET result = JSON_SET(result, CONCAT('$[', position, ']'), element);
+		END IF;
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	RETURN result;
+END $$
+
+-- Helper procedure to merge message b into message a
+DROP PROCEDURE IF EXISTS _pb_message_merge $$
+CREATE PROCEDURE _pb_message_merge(IN descriptor_set_json JSON, IN full_type_name TEXT, IN a LONGBLOB, IN b LONGBLOB, IN message_depth INT, OUT result LONGBLOB)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE wire_json_a JSON;
+	DECLARE wire_json_b JSON;
+	DECLARE result_wire_json JSON;
+	DECLARE next_index INT DEFAULT 0;
+	DECLARE oneof_winners_a JSON;
+	DECLARE oneof_winners_b JSON;
+	DECLARE known_field_numbers JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE oneof_index INT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE has_implicit_presence BOOLEAN;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE winner_a INT;
+	DECLARE winner_b INT;
+
+	-- Wire elements
+	DECLARE elements_a JSON;
+	DECLARE elements_b JSON;
+	DECLARE elements JSON;
+	DECLARE last_element JSON;
+	DECLARE sub_message LONGBLOB;
+
+	-- Unknown fields
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT DEFAULT 0;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_merge');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_merge', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET wire_json_a = pb_message_to_wire_json(a);
+	SET wire_json_b = pb_message_to_wire_json(b);
+	SET oneof_winners_a = _pb_wire_json_get_oneof_winners(message_descriptor, wire_json_a);
+	SET oneof_winners_b = _pb_wire_json_get_oneof_winners(message_descriptor, wire_json_b);
+	SET result_wire_json = JSON_OBJECT();
+	SET known_field_numbers = JSON_ARRAY();
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	l1: WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET field_index = field_index + 1;
+
+		SET known_field_numbers = JSON_ARRAY_APPEND(known_field_numbers, '$', field_number);
+		SET elements_a = JSON_EXTRACT(wire_json_a, CONCAT('$."', field_number, '"'));
+		SET elements_b = JSON_EXTRACT(wire_json_b, CONCAT('$."', field_number, '"'));
+
+		IF elements_a IS NULL AND elements_b IS NULL THEN
+			ITERATE l1;
+		END IF;
+
+		IF field_type = 10 THEN -- TYPE_GROUP
+			SET message_text = CONCAT('pb_message_merge: unsupported field_type `', field_type, '` for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		-- Only the member of a oneof that is set last survives; b takes precedence over a
+		IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+			SET winner_a = JSON_EXTRACT(oneof_winners_a, CONCAT('$."', oneof_index, '"'));
+			SET winner_b = JSON_EXTRACT(oneof_winners_b, CONCAT('$."', oneof_index, '"'));
+			IF COALESCE(winner_b, winner_a) <> field_number THEN
+				ITERATE l1;
+			END IF;
+			IF winner_a <> field_number THEN
+				SET elements_a = NULL;
+			END IF;
+		END IF;
+
+		IF field_label = 3 THEN -- LABEL_REPEATED
+			SET elements = JSON_MERGE_PRESERVE(COALESCE(elements_a, JSON_ARRAY()), COALESCE(elements_b, JSON_ARRAY()));
+			SET map_entry_descriptor = IF(field_type = 11, _pb_get_message_descriptor(descriptor_set_json, field_type_name), NULL);
+			IF COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE) THEN -- map_entry
+				SET elements = _pb_wire_json_merge_map_entries(elements, JSON_EXTRACT(_pb_get_field_descriptor_by_name(map_entry_descriptor, 'key'), '$."5"'));
+			END IF;
+
+		ELSEIF field_type = 11 THEN -- TYPE_MESSAGE
+			-- Messages are merged recursively, which also collapses multiple occurrences into one
+			CALL _pb_message_merge(descriptor_set_json, field_type_name,
+				COALESCE(_pb_wire_json_get_merged_message_field(wire_json_a, field_number), _binary ''),
+				COALESCE(_pb_wire_json_get_merged_message_field(wire_json_b, field_number), _binary ''),
+				message_depth + 1, sub_message);
+			SET elements = JSON_ARRAY(JSON_OBJECT('i', 0, 'n', field_number, 't', 2, 'v', TO_BASE64(sub_message)));
+
+		ELSE
+			-- The last occurrence of a scalar wins. A zero value in b does not overwrite a if the field has no presence.
+			SET has_implicit_presence = syntax = 'proto3' AND NOT proto3_optional AND oneof_index IS NULL;
+			SET last_element = JSON_EXTRACT(elements_b, CONCAT('$[', JSON_LENGTH(elements_b) - 1, ']'));
+			IF last_element IS NULL OR (has_implicit_presence AND elements_a IS NOT NULL AND JSON_UNQUOTE(JSON_EXTRACT(last_element, '$.v')) IN ('0', '')) THEN
+				SET last_element = JSON_EXTRACT(elements_a, CONCAT('$[', JSON_LENGTH(elements_a) - 1, ']'));
+			END IF;
+			SET elements = JSON_ARRAY(last_element);
+		END IF;
+
+		IF JSON_LENGTH(elements) > 0 THEN
+			SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), _pb_wire_json_renumber_elements(elements, next_index));
+			SET next_index = next_index + JSON_LENGTH(elements);
+		END IF;
+	END WHILE;
+
+	-- Unknown fields of both messages are kept as they are
+	SET field_numbers = JSON_KEYS(JSON_MERGE_PATCH(wire_json_a, wire_json_b));
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		IF NOT JSON_CONTAINS(known_field_numbers, CAST(field_number AS JSON)) THEN
+			SET elements = JSON_MERGE_PRESERVE(
+				COALESCE(JSON_EXTRACT(wire_json_a, CONCAT('$."', field_number, '"')), JSON_ARRAY()),
+				COALESCE(JSON_EXTRACT(wire_json_b, CONCAT('$."', field_number, '"')), JSON_ARRAY()));
+			SET result_wire_json = JSON_SET(result_wire_json, CONCAT('$."', field_number, '"'), _pb_wire_json_renumber_elements(elements, next_index));
+			SET next_index = next_index + JSON_LENGTH(elements);
+		END IF;
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(result_wire_json);
+END $$
+
+-- Merges message b into message a with the semantics of MergeFrom, and returns a message without superseded duplicates
+DROP FUNCTION IF EXISTS pb_message_merge $$
+CREATE FUNCTION pb_message_merge(descriptor_set_json JSON, type_name TEXT, a LONGBLOB, b LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF a IS NULL OR b IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_merge(descriptor_set_json, type_name, a, b, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
@@ -0,0 +1,764 @@
+DELIMITER $$
+
+-- Helper function to quote a string or bytes value for the protobuf text format.
+-- Escapes are compatible with the output of google.golang.org/protobuf/encoding/prototext:
+-- valid UTF-8 is kept as is (except C1 control characters), and invalid bytes are written as \xHH.
+DROP FUNCTION IF EXISTS _pb_util_text_quote $$
+CREATE FUNCTION _pb_util_text_quote(value LONGBLOB) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB DEFAULT _binary '"';
+	DECLARE value_length INT;
+	DECLARE i INT DEFAULT 1;
+	DECLARE j INT;
+	DECLARE b INT;
+	DECLARE continuation_byte INT;
+	DECLARE sequence_length INT;
+	DECLARE code_point INT;
+	DECLARE min_second_byte INT;
+	DECLARE max_second_byte INT;
+	DECLARE is_valid BOOLEAN;
+
+	IF value IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET value_length = LENGTH(value);
+
+	WHILE i <= value_length DO
+		SET b = ASCII(SUBSTRING(value, i, 1));
+		IF b < 0x80 THEN
+			CASE
+			WHEN b = 0x22 THEN SET result = CONCAT(result, _binary '\\"');
+			WHEN b = 0x5C THEN SET result = CONCAT(result, _binary '\\\\');
+			WHEN b = 0x0A THEN SET result = CONCAT(result, _binary '\\n');
+			WHEN b = 0x0D THEN SET result = CONCAT(result, _binary '\\r');
+			WHEN b = 0x09 THEN SET result = CONCAT(result, _binary '\\t');
+			WHEN b < 0x20 OR b = 0x7F THEN SET result = CONCAT(result, _binary '\\x', LOWER(LPAD(HEX(b), 2, '0')));
+			ELSE SET result = CONCAT(result, SUBSTRING(value, i, 1));
+			END CASE;
+			SET i = i + 1;
+		ELSE
+			-- Decode a UTF-8 sequence; bytes that do not form a valid sequence are escaped one by one
+			SET sequence_length = CASE
+				WHEN b BETWEEN 0xC2 AND 0xDF THEN 2
+				WHEN b BETWEEN 0xE0 AND 0xEF THEN 3
+				WHEN b BETWEEN 0xF0 AND 0xF4 THEN 4
+				ELSE 0
+			END;
+			SET code_point = b & CASE sequence_length WHEN 2 THEN 0x1F WHEN 3 THEN 0x0F ELSE 0x07 END;
+			SET min_second_byte = CASE b WHEN 0xE0 THEN 0xA0 WHEN 0xF0 THEN 0x90 ELSE 0x80 END; -- reject overlong encodings
+			SET max_second_byte = CASE b WHEN 0xED THEN 0x9F WHEN 0xF4 THEN 0x8F ELSE 0xBF END; -- reject surrogates and values above U+10FFFF
+			SET is_valid = sequence_length > 0 AND i + sequence_length - 1 <= value_length;
+			SET j = 1;
+			WHILE is_valid AND j < sequence_length DO
+				SET continuation_byte = ASCII(SUBSTRING(value, i + j, 1));
+				IF continuation_byte < IF(j = 1, min_second_byte, 0x80) OR continuation_byte > IF(j = 1, max_second_byte, 0xBF) THEN
+					SET is_valid = FALSE;
+				ELSE
+					SET code_point = (code_point << 6) | (continuation_byte & 0x3F);
+				END IF;
+				SET j = j + 1;
+			END WHILE;
+
+			IF NOT is_valid THEN
+				SET result = CONCAT(result, _binary '\\x', LOWER(HEX(b)));
+				SET i = i + 1;
+			ELSEIF code_point <= 0x9F THEN
+				SET result = CONCAT(result, _binary '\\u', LOWER(LPAD(HEX(code_point), 4, '0')));
+				SET i = i + sequence_length;
+			ELSE
+				SET result = CONCAT(result, SUBSTRING(value, i, sequence_length));
+				SET i = i + sequence_length;
+			END IF;
+		END IF;
+	END WHILE;
+
+	RETURN CONVERT(CONCAT(result, _binary '"') USING utf8mb4);
+END $$
+
+-- Helper function to format the bit pattern of a float (bit_size = 32) or double (bit_size = 64) for the text format
+DROP FUNCTION IF EXISTS _pb_util_format_text_floating_point $$
+CREATE FUNCTION _pb_util_format_text_floating_point(bits BIGINT UNSIGNED, bit_size INT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	DECLARE float_value FLOAT;
+
+	IF bit_size = 32 THEN
+		IF ((bits >> 23) & 0xFF) = 0xFF THEN
+			RETURN IF((bits & 0x7FFFFF) <> 0, 'nan', IF((bits >> 31) = 0, 'inf', '-inf'));
+		END IF;
+		SET float_value = _pb_util_reinterpret_uint32_as_float(bits);
+		RETURN CAST(float_value AS CHAR);
+	END IF;
+
+	IF ((bits >> 52) & 0x7FF) = 0x7FF THEN
+		RETURN IF((bits & 0xFFFFFFFFFFFFF) <> 0, 'nan', IF((bits >> 63) = 0, 'inf', '-inf'));
+	END IF;
+	RETURN CAST(_pb_util_reinterpret_uint64_as_double(bits) AS CHAR);
+END $$
+
+-- Helper function to get all values of a field as a JSON array without the schema-specific interpretation.
+-- Float and double values are returned as their IEEE 754 bit patterns so that NaN and infinities are preserved.
+DROP FUNCTION IF EXISTS _pb_wire_json_get_repeated_field_as_raw_json_array $$
+CREATE FUNCTION _pb_wire_json_get_repeated_field_as_raw_json_array(wire_json JSON, field_number INT, field_type INT) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+
+	CASE field_type
+	WHEN 1 THEN RETURN pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number); -- double
+	WHEN 2 THEN RETURN pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number); -- float
+	WHEN 3 THEN RETURN pb_wire_json_get_repeated_int64_field_as_json_array(wire_json, field_number);
+	WHEN 4 THEN RETURN pb_wire_json_get_repeated_uint64_field_as_json_array(wire_json, field_number);
+	WHEN 5 THEN RETURN pb_wire_json_get_repeated_int32_field_as_json_array(wire_json, field_number);
+	WHEN 6 THEN RETURN pb_wire_json_get_repeated_fixed64_field_as_json_array(wire_json, field_number);
+	WHEN 7 THEN RETURN pb_wire_json_get_repeated_fixed32_field_as_json_array(wire_json, field_number);
+	WHEN 8 THEN RETURN pb_wire_json_get_repeated_bool_field_as_json_array(wire_json, field_number);
+	WHEN 9 THEN RETURN pb_wire_json_get_repeated_string_field_as_json_array(wire_json, field_number);
+	WHEN 11 THEN RETURN pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+	WHEN 12 THEN RETURN pb_wire_json_get_repeated_bytes_field_as_json_array(wire_json, field_number);
+	WHEN 13 THEN RETURN pb_wire_json_get_repeated_uint32_field_as_json_array(wire_json, field_number);
+	WHEN 14 THEN RETURN pb_wire_json_get_repeated_enum_field_as_json_array(wire_json, field_number);
+	WHEN 15 THEN RETURN pb_wire_json_get_repeated_sfixed32_field_as_json_array(wire_json, field_number);
+	WHEN 16 THEN RETURN pb_wire_json_get_repeated_sfixed64_field_as_json_array(wire_json, field_number);
+	WHEN 17 THEN RETURN pb_wire_json_get_repeated_sint32_field_as_json_array(wire_json, field_number);
+	WHEN 18 THEN RETURN pb_wire_json_get_repeated_sint64_field_as_json_array(wire_json, field_number);
+	ELSE
+		SET message_text = CONCAT('_pb_wire_json_get_repeated_field_as_raw_json_array: unsupported field_type `', field_type, '`');
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END CASE;
+END $$
+
+-- Helper function to format a scalar value returned by _pb_wire_json_get_repeated_field_as_raw_json_array for the text format
+DROP FUNCTION IF EXISTS _pb_util_format_text_value $$
+CREATE FUNCTION _pb_util_format_text_value(descriptor_set_json JSON, field_type INT, field_type_name TEXT, raw_value JSON) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE enum_name JSON;
+
+	CASE field_type
+	WHEN 1 THEN RETURN _pb_util_format_text_floating_point(CAST(JSON_UNQUOTE(raw_value) AS UNSIGNED), 64);
+	WHEN 2 THEN RETURN _pb_util_format_text_floating_point(CAST(JSON_UNQUOTE(raw_value) AS UNSIGNED), 32);
+	WHEN 8 THEN RETURN IF(JSON_UNQUOTE(raw_value) IN ('1', 'true'), 'true', 'false');
+	WHEN 9 THEN RETURN _pb_util_text_quote(CAST(JSON_UNQUOTE(raw_value) AS BINARY));
+	WHEN 12 THEN RETURN _pb_util_text_quote(FROM_BASE64(JSON_UNQUOTE(raw_value)));
+	WHEN 14 THEN
+		-- Unknown enum values are written as numbers
+		CALL _pb_enum_to_json(descriptor_set_json, field_type_name, CAST(JSON_UNQUOTE(raw_value) AS SIGNED), enum_name);
+		RETURN COALESCE(JSON_UNQUOTE(enum_name), JSON_UNQUOTE(raw_value));
+	ELSE
+		RETURN JSON_UNQUOTE(raw_value);
+	END CASE;
+END $$
+
+-- Procedure for converting a protobuf message to the text format. indent is the indentation of the current
+-- nesting level for the multi-line format, or NULL for the single-line format. message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_to_text $$
+CREATE PROCEDURE _pb_message_to_text(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN indent TEXT, IN message_depth INT, OUT result LONGTEXT)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE is_map_entry BOOLEAN;
+	DECLARE wire_json JSON;
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT;
+	DECLARE field_descriptor JSON;
+
+	-- Field properties
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE proto3_optional BOOLEAN;
+	DECLARE oneof_index INT;
+
+	-- Processing variables
+	DECLARE has_field_presence BOOLEAN;
+	DECLARE is_selected BOOLEAN;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE zero_value JSON;
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE value_text LONGTEXT;
+	DECLARE nested_text LONGTEXT;
+
+	-- Oneof handling
+	DECLARE oneofs JSON;
+	DECLARE oneof_priority INT;
+
+	IF buf IS NULL THEN
+		SET result = NULL;
+		LEAVE proc;
+	END IF;
+
+	CALL _pb_check_message_depth(message_depth, IF(indent IS NULL, 'pb_message_to_single_line_text', 'pb_message_to_text'));
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', '_pb_message_to_text', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET is_map_entry = COALESCE(CAST(JSON_EXTRACT(message_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+	SET wire_json = pb_message_to_wire_json(buf);
+	SET fields = COALESCE(JSON_EXTRACT(message_descriptor, '$."2"'), JSON_ARRAY());
+	SET field_count = JSON_LENGTH(fields);
+	SET result = '';
+
+	-- Find the member of each oneof that was set last
+	SET oneofs = JSON_OBJECT();
+	SET field_index = 0;
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+
+		IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+			SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+			IF elements IS NOT NULL THEN
+				SET oneof_priority = JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, '].i'));
+				IF COALESCE(JSON_EXTRACT(oneofs, CONCAT('$."', oneof_index, '".i')) < oneof_priority, TRUE) THEN
+					SET oneofs = JSON_SET(oneofs, CONCAT('$."', oneof_index, '"'), JSON_OBJECT('i', oneof_priority, 'n', field_number));
+				END IF;
+			END IF;
+		END IF;
+
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	-- Fields are written in the declaration order, as prototext does
+	SET field_index = 0;
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+
+		-- Extract field properties from FieldDescriptorProto
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET proto3_optional = COALESCE(CAST(JSON_EXTRACT(field_descriptor, '$."17"') AS UNSIGNED), FALSE); -- proto3_optional
+		SET oneof_index = JSON_EXTRACT(field_descriptor, '$."9"'); -- oneof_index
+
+		IF field_type = 10 THEN -- TYPE_GROUP (unsupported)
+			SET message_text = CONCAT('_pb_message_to_text: unsupported field_type `', field_type, '` for field `', field_name, '` (', field_number, ').');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET has_field_presence =
+			(syntax = 'proto2' AND field_label <> 3) -- proto2: all non-repeated fields
+			OR (syntax = 'proto3'
+				AND (
+					(field_label = 1 AND proto3_optional) -- proto3 optional
+					OR (field_label <> 3 AND field_type = 11) -- message fields
+					OR (oneof_index IS NOT NULL) -- oneof fields
+				));
+
+		IF oneof_index IS NOT NULL AND NOT proto3_optional THEN
+			SET is_selected = JSON_EXTRACT(oneofs, CONCAT('$."', oneof_index, '".n')) = field_number;
+		ELSE
+			SET is_selected = TRUE;
+		END IF;
+
+		IF is_selected THEN
+			IF field_label = 3 THEN -- LABEL_REPEATED (including maps, written as repeated entry messages)
+				SET elements = _pb_wire_json_get_repeated_field_as_raw_json_array(wire_json, field_number, field_type);
+			ELSEIF field_type = 11 THEN
+				-- Occurrences of a singular message field are merged
+				SET bytes_value = _pb_wire_json_get_merged_message_field(wire_json, field_number);
+				SET elements = IF(bytes_value IS NULL, JSON_ARRAY(), JSON_ARRAY(TO_BASE64(bytes_value)));
+			ELSE
+				-- The last occurrence of a singular scalar field wins
+				SET elements = _pb_wire_json_get_repeated_field_as_raw_json_array(wire_json, field_number, field_type);
+				IF JSON_LENGTH(elements) > 0 THEN
+					SET elements = JSON_ARRAY(JSON_EXTRACT(elements, CONCAT('$[', JSON_LENGTH(elements) - 1, ']')));
+				END IF;
+			END IF;
+
+			SET zero_value = IF(field_type IN (9, 11, 12), JSON_QUOTE(''), CAST(0 AS JSON));
+
+			-- Map entries always have both the key and the value written
+			IF is_map_entry AND JSON_LENGTH(elements) = 0 THEN
+				SET elements = JSON_ARRAY(zero_value);
+			END IF;
+
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+
+			WHILE element_index < element_count DO
+				IF field_type = 11 THEN
+					CALL _pb_message_to_text(descriptor_set_json, field_type_name, FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))), IF(indent IS NULL, NULL, CONCAT(indent, '  ')), message_depth + 1, nested_text);
+					IF indent IS NULL THEN
+						SET value_text = CONCAT('{', nested_text, '}');
+					ELSEIF nested_text = '' THEN
+						SET value_text = '{}';
+					ELSE
+						SET value_text = CONCAT('{\n', nested_text, indent, '}');
+					END IF;
+				ELSE
+					SET value_text = _pb_util_format_text_value(descriptor_set_json, field_type, field_type_name, JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')));
+
+					-- Fields without presence are omitted when they have the default value
+					IF field_label <> 3 AND NOT has_field_presence AND NOT is_map_entry
+						AND BINARY value_text = BINARY _pb_util_format_text_value(descriptor_set_json, field_type, field_type_name, zero_value) THEN
+						SET value_text = NULL;
+					END IF;
+				END IF;
+
+				IF value_text IS NOT NULL THEN
+					IF indent IS NULL THEN
+						SET result = CONCAT(result, IF(result = '', '', ' '), field_name, ':', value_text);
+					ELSE
+						SET result = CONCAT(result, indent, field_name, ': ', value_text, '\n');
+					END IF;
+				END IF;
+
+				SET element_index = element_index + 1;
+			END WHILE;
+		END IF;
+
+		SET field_index = field_index + 1;
+	END WHILE;
+END $$
+
+-- Converts a protobuf message to the multi-line text format
+DROP FUNCTION IF EXISTS pb_message_to_text $$
+CREATE FUNCTION pb_message_to_text(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE result LONGTEXT;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_to_text(descriptor_set_json, type_name, message, '', 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Converts a protobuf message to the single-line text format
+DROP FUNCTION IF EXISTS pb_message_to_single_line_text $$
+CREATE FUNCTION pb_message_to_single_line_text(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS LONGTEXT DETERMINISTIC
+BEGIN
+	DECLARE result LONGTEXT;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_to_text(descriptor_set_json, type_name, message, NULL, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Helper function to skip whitespace and # comments in the text format. Returns the position of the next token.
+DROP FUNCTION IF EXISTS _pb_text_skip_whitespace $$
+CREATE FUNCTION _pb_text_skip_whitespace(text LONGTEXT, pos INT) RETURNS INT DETERMINISTIC
+BEGIN
+	DECLARE text_length INT;
+	DECLARE c INT;
+	DECLARE newline_pos INT;
+
+	SET text_length = CHAR_LENGTH(text);
+
+	WHILE pos <= text_length DO
+		SET c = ASCII(SUBSTRING(text, pos, 1));
+		IF c IN (0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x20) THEN
+			SET pos = pos + 1;
+		ELSEIF c = 0x23 THEN -- '#' starts a comment that runs to the end of the line
+			SET newline_pos = LOCATE('\n', text, pos);
+			SET pos = IF(newline_pos = 0, text_length + 1, newline_pos + 1);
+		ELSE
+			RETURN pos;
+		END IF;
+	END WHILE;
+
+	RETURN pos;
+END $$
+
+-- Helper procedure to read the next token of the text format.
+-- token_type is one of 'EOF', 'PUNCT', 'IDENT', 'NUMBER' and 'STRING'. Strings are returned unescaped,
+-- with adjacent string literals concatenated. A leading '-' is included in NUMBER and IDENT tokens.
+DROP PROCEDURE IF EXISTS _pb_text_next_token $$
+CREATE PROCEDURE _pb_text_next_token(IN text LONGTEXT, INOUT pos INT, OUT token_type TEXT, OUT token LONGBLOB)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE text_length INT;
+	DECLARE c TEXT;
+	DECLARE quote_char TEXT;
+	DECLARE start_pos INT;
+	DECLARE end_pos INT;
+	DECLARE sign TEXT DEFAULT '';
+	DECLARE matched TEXT;
+
+	SET text_length = CHAR_LENGTH(text);
+	SET pos = _pb_text_skip_whitespace(text, pos);
+
+	IF pos > text_length THEN
+		SET token_type = 'EOF';
+		SET token = NULL;
+		LEAVE proc;
+	END IF;
+
+	SET c = SUBSTRING(text, pos, 1);
+
+	IF c IN (':', '{', '}', '<', '>', '[', ']', ',', ';') THEN
+		SET token_type = 'PUNCT';
+		SET token = CAST(c AS BINARY);
+		SET pos = pos + 1;
+		LEAVE proc;
+	END IF;
+
+	IF c IN ('"', '\'') THEN
+		SET token_type = 'STRING';
+		SET token = _binary '';
+		WHILE c IN ('"', '\'') DO
+			SET quote_char = c;
+			SET start_pos = pos + 1;
+			SET end_pos = start_pos;
+			WHILE SUBSTRING(text, end_pos, 1) <> quote_char DO
+				IF end_pos > text_length OR SUBSTRING(text, end_pos, 1) = '\n' THEN
+					SET message_text = CONCAT('pb_text_to_message: unterminated string at position ', pos);
+					SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+				END IF;
+				SET end_pos = end_pos + IF(SUBSTRING(text, end_pos, 1) = '\\', 2, 1);
+			END WHILE;
+			SET token = CONCAT(token, _pb_util_c_unescape(SUBSTRING(text, start_pos, end_pos - start_pos)));
+			SET pos = _pb_text_skip_whitespace(text, end_pos + 1);
+			SET c = SUBSTRING(text, pos, 1);
+		END WHILE;
+		LEAVE proc;
+	END IF;
+
+	IF c = '-' THEN
+		SET sign = '-';
+		SET pos = _pb_text_skip_whitespace(text, pos + 1);
+	END IF;
+
+	SET matched = REGEXP_SUBSTR(SUBSTRING(text, pos, 1024), '^(0[xX][0-9A-Fa-f]+|([0-9]+[.]?[0-9]*|[.][0-9]+)([eE][+-]?[0-9]+)?[fF]?)');
+	IF matched IS NOT NULL THEN
+		SET token_type = 'NUMBER';
+	ELSE
+		SET matched = REGEXP_SUBSTR(SUBSTRING(text, pos, 1024), '^[A-Za-z_][A-Za-z0-9_]*');
+		SET token_type = 'IDENT';
+	END IF;
+
+	IF matched IS NULL THEN
+		SET message_text = CONCAT('pb_text_to_message: unexpected character `', SUBSTRING(text, pos, 1), '` at position ', pos);
+		SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+	END IF;
+
+	SET token = CAST(CONCAT(sign, matched) AS BINARY);
+	SET pos = pos + CHAR_LENGTH(matched);
+END $$
+
+-- Helper function to convert a scalar token of the text format into the JSON value accepted by the setters.
+-- Float and double values are converted into their IEEE 754 bit patterns. Returns NULL if the token is not valid for the field type.
+DROP FUNCTION IF EXISTS _pb_text_token_to_json $$
+CREATE FUNCTION _pb_text_token_to_json(field_type INT, token_type TEXT, token LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE token_text TEXT;
+	DECLARE is_negative BOOLEAN;
+	DECLARE unsigned_text TEXT;
+	DECLARE digits TEXT;
+
+	IF token_type = 'STRING' THEN
+		CASE field_type
+		WHEN 9 THEN RETURN JSON_QUOTE(CONVERT(token USING utf8mb4));
+		WHEN 12 THEN RETURN JSON_QUOTE(TO_BASE64(token));
+		ELSE RETURN NULL;
+		END CASE;
+	END IF;
+
+	IF field_type IN (9, 12) OR token_type NOT IN ('IDENT', 'NUMBER') THEN
+		RETURN NULL;
+	END IF;
+
+	SET token_text = CONVERT(token USING utf8mb4);
+	SET is_negative = token_text LIKE '-%';
+	SET unsigned_text = IF(is_negative, SUBSTRING(token_text, 2), token_text);
+
+	IF field_type = 8 THEN -- bool
+		IF BINARY token_text IN ('true', 'True', 't', '1') THEN
+			RETURN CAST('true' AS JSON);
+		ELSEIF BINARY token_text IN ('false', 'False', 'f', '0') THEN
+			RETURN CAST('false' AS JSON);
+		END IF;
+		RETURN NULL;
+	END IF;
+
+	IF field_type = 14 AND token_type = 'IDENT' THEN -- enum value name
+		RETURN IF(is_negative, NULL, JSON_QUOTE(token_text));
+	END IF;
+
+	IF field_type IN (1, 2) THEN -- double, float
+		IF token_type = 'IDENT' THEN
+			IF LOWER(unsigned_text) IN ('inf', 'infinity') THEN
+				RETURN CAST(CASE
+					WHEN field_type = 1 THEN IF(is_negative, 18442240474082181120, 9218868437227405312) -- 0xFFF0000000000000, 0x7FF0000000000000
+					ELSE IF(is_negative, 4286578688, 2139095040) -- 0xFF800000, 0x7F800000
+				END AS JSON);
+			ELSEIF LOWER(unsigned_text) = 'nan' THEN
+				RETURN CAST(IF(field_type = 1, 9221120237041090560, 2143289344) AS JSON); -- 0x7FF8000000000000, 0x7FC00000
+			END IF;
+			RETURN NULL;
+		END IF;
+
+		IF unsigned_text LIKE '0x%' THEN
+			RETURN NULL;
+		END IF;
+		SET token_text = REGEXP_REPLACE(token_text, '[fF]$', '');
+		IF field_type = 1 THEN
+			RETURN CAST(_pb_util_reinterpret_double_as_uint64(CAST(token_text AS DOUBLE)) AS JSON);
+		END IF;
+		RETURN CAST(_pb_util_reinterpret_float_as_uint32(CAST(token_text AS FLOAT)) AS JSON);
+	END IF;
+
+	-- Integers (and enum values given by number) in decimal, hexadecimal or octal
+	IF token_type <> 'NUMBER' THEN
+		RETURN NULL;
+	END IF;
+
+	IF unsigned_text REGEXP '^0[xX][0-9A-Fa-f]+$' THEN
+		SET digits = CONV(SUBSTRING(unsigned_text, 3), 16, 10);
+	ELSEIF unsigned_text REGEXP '^0[0-7]+$' THEN
+		SET digits = CONV(unsigned_text, 8, 10);
+	ELSEIF unsigned_text REGEXP '^[0-9]+$' THEN
+		SET digits = unsigned_text;
+	ELSE
+		RETURN NULL;
+	END IF;
+
+	RETURN CAST(CONCAT(IF(is_negative, '-', ''), digits) AS JSON);
+END $$
+
+-- Helper procedure to parse the value of a field starting at the given (already read) token. message_depth is the depth
+-- of the message containing the field.
+DROP PROCEDURE IF EXISTS _pb_text_parse_field_value $$
+CREATE PROCEDURE _pb_text_parse_field_value(IN descriptor_set_json JSON, IN field_descriptor JSON, IN text LONGTEXT, INOUT pos INT, IN token_type TEXT, IN token LONGBLOB, IN token_pos INT, IN message_depth INT, OUT value_json JSON)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE field_type INT;
+	DECLARE nested_message LONGBLOB;
+
+	SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+	IF field_type = 11 THEN -- TYPE_MESSAGE
+		IF token_type <> 'PUNCT' OR token NOT IN ('{', '<') THEN
+			SET message_text = CONCAT('pb_text_to_message: expected `{` for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '` at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+		CALL _pb_text_to_message(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')), text, pos, IF(token = '{', '}', '>'), message_depth + 1, nested_message);
+		SET value_json = JSON_QUOTE(TO_BASE64(nested_message));
+	ELSE
+		SET value_json = _pb_text_token_to_json(field_type, token_type, token);
+		IF value_json IS NULL THEN
+			SET message_text = CONCAT('pb_text_to_message: invalid value `', COALESCE(CONVERT(token USING utf8mb4), 'end of input'), '` for field `', JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')), '` at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+	END IF;
+END $$
+
+-- Procedure for parsing the fields of a message in the text format, up to close_delimiter ('}' or '>') or the end of input (NULL).
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_text_to_message $$
+CREATE PROCEDURE _pb_text_to_message(IN descriptor_set_json JSON, IN full_type_name TEXT, IN text LONGTEXT, INOUT pos INT, IN close_delimiter TEXT, IN message_depth INT, OUT result LONGBLOB)
+BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE syntax TEXT;
+	DECLARE token_type TEXT;
+	DECLARE token LONGBLOB;
+	DECLARE token_pos INT;
+	DECLARE field_name TEXT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE value_json JSON;
+	DECLARE is_list_end BOOLEAN;
+
+	-- Values of repeated fields, collected as {"<field_number>": {"d": <field_descriptor>, "v": [<values>]}}
+	DECLARE repeated_fields JSON;
+	DECLARE repeated_field JSON;
+	DECLARE repeated_field_path TEXT;
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_text_to_message');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_text_to_message', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, full_type_name);
+	SET result = pb_message_new();
+	SET repeated_fields = JSON_OBJECT();
+
+	parse_loop: LOOP
+		SET token_pos = _pb_text_skip_whitespace(text, pos);
+		CALL _pb_text_next_token(text, pos, token_type, token);
+
+		IF token_type = 'EOF' THEN
+			IF close_delimiter IS NOT NULL THEN
+				SET message_text = CONCAT('pb_text_to_message: unexpected end of input, expected `', close_delimiter, '`');
+				SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+			END IF;
+			LEAVE parse_loop;
+		END IF;
+
+		IF token_type = 'PUNCT' AND token = close_delimiter THEN
+			LEAVE parse_loop;
+		END IF;
+
+		IF token_type = 'PUNCT' AND token = '[' THEN
+			SET message_text = CONCAT('pb_text_to_message: extension and Any expansion syntax is not supported at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		IF token_type <> 'IDENT' OR token LIKE '-%' THEN
+			SET message_text = CONCAT('pb_text_to_message: unexpected token `', CONVERT(token USING utf8mb4), '` at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_name = CONVERT(token USING utf8mb4);
+		SET field_descriptor = _pb_get_field_descriptor_by_name(message_descriptor, field_name);
+		IF field_descriptor IS NULL THEN
+			SET message_text = CONCAT('pb_text_to_message: field `', field_name, '` not found in message type `', full_type_name, '`');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+
+		IF field_type = 10 THEN -- TYPE_GROUP (unsupported)
+			SET message_text = CONCAT('pb_text_to_message: unsupported field_type `', field_type, '` for field `', field_name, '` (', field_number, ').');
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		-- The ':' separator is optional before a message value
+		SET token_pos = _pb_text_skip_whitespace(text, pos);
+		CALL _pb_text_next_token(text, pos, token_type, token);
+		IF token_type = 'PUNCT' AND token = ':' THEN
+			SET token_pos = _pb_text_skip_whitespace(text, pos);
+			CALL _pb_text_next_token(text, pos, token_type, token);
+		ELSEIF field_type <> 11 THEN
+			SET message_text = CONCAT('pb_text_to_message: expected `:` after field `', field_name, '` at position ', token_pos);
+			SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+		END IF;
+
+		IF field_label = 3 THEN -- LABEL_REPEATED
+			SET repeated_field_path = CONCAT('$."', field_number, '"');
+			IF NOT JSON_CONTAINS_PATH(repeated_fields, 'one', repeated_field_path) THEN
+				-- Float and double values are set as their bit patterns through the fixed64 and fixed32 setters
+				SET repeated_fields = JSON_SET(repeated_fields, repeated_field_path, JSON_OBJECT(
+					'd', CASE field_type
+						WHEN 1 THEN JSON_SET(field_descriptor, '$."5"', 6)
+						WHEN 2 THEN JSON_SET(field_descriptor, '$."5"', 7)
+						ELSE field_descriptor
+					END,
+					'v', JSON_ARRAY()));
+			END IF;
+
+			IF token_type = 'PUNCT' AND token = '[' THEN
+				-- List syntax: name: [value, value, ...]
+				SET token_pos = _pb_text_skip_whitespace(text, pos);
+				CALL _pb_text_next_token(text, pos, token_type, token);
+				SET is_list_end = token_type = 'PUNCT' AND token = ']';
+				WHILE NOT is_list_end DO
+					CALL _pb_text_parse_field_value(descriptor_set_json, field_descriptor, text, pos, token_type, token, token_pos, message_depth, value_json);
+					SET repeated_fields = JSON_ARRAY_APPEND(repeated_fields, CONCAT(repeated_field_path, '.v'), value_json);
+
+					SET token_pos = _pb_text_skip_whitespace(text, pos);
+					CALL _pb_text_next_token(text, pos, token_type, token);
+					IF token_type = 'PUNCT' AND token = ']' THEN
+						SET is_list_end = TRUE;
+					ELSEIF token_type = 'PUNCT' AND token = ',' THEN
+						SET token_pos = _pb_text_skip_whitespace(text, pos);
+						CALL _pb_text_next_token(text, pos, token_type, token);
+					ELSE
+						SET message_text = CONCAT('pb_text_to_message: expected `,` or `]` at position ', token_pos);
+						SIGNAL CUSTOM_EXCEPTION SET MESSAGE_TEXT = message_text;
+					END IF;
+				END WHILE;
+			ELSE
+				CALL _pb_text_parse_field_value(descriptor_set_json, field_descriptor, text, pos, token_type, token, token_pos, message_depth, value_json);
+				SET repeated_fields = JSON_ARRAY_APPEND(repeated_fields, CONCAT(repeated_field_path, '.v'), value_json);
+			END IF;
+		ELSE
+			CALL _pb_text_parse_field_value(descriptor_set_json, field_descriptor, text, pos, token_type, token, token_pos, message_depth, value_json);
+			SET result = _pb_message_set_singular_field_from_json(descriptor_set_json, result,
+				CASE field_type
+					WHEN 1 THEN JSON_SET(field_descriptor, '$."5"', 6)
+					WHEN 2 THEN JSON_SET(field_descriptor, '$."5"', 7)
+					ELSE field_descriptor
+				END,
+				value_json);
+		END IF;
+
+		-- Fields may optionally be separated by ',' or ';'
+		SET token_pos = pos;
+		CALL _pb_text_next_token(text, pos, token_type, token);
+		IF NOT (token_type = 'PUNCT' AND token IN (',', ';')) THEN
+			SET pos = token_pos;
+		END IF;
+	END LOOP;
+
+	SET field_numbers = JSON_KEYS(repeated_fields);
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	SET field_number_index = 0;
+	WHILE field_number_index < field_number_count DO
+		SET repeated_field = JSON_EXTRACT(repeated_fields, CONCAT('$.', JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']'))));
+		SET field_descriptor = JSON_EXTRACT(repeated_field, '$.d');
+		SET result = _pb_message_set_repeated_field_from_json(descriptor_set_json, result, field_descriptor, _pb_is_field_packed(syntax, field_descriptor), JSON_EXTRACT(repeated_field, '$.v'));
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+END $$
+
+-- Parses a protobuf message from the text format
+DROP FUNCTION IF EXISTS pb_text_to_message $$
+CREATE FUNCTION pb_text_to_message(descriptor_set_json JSON, type_name TEXT, text LONGTEXT) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE pos INT DEFAULT 1;
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF text IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_text_to_message(descriptor_set_json, type_name, text, pos, NULL, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
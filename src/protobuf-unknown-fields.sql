@@ -0,0 +1,239 @@
+DELIMITER $$
+
+-- Helper procedure to collect the unknown fields of a message and its nested messages as {"path", "number"} objects.
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_get_unknown_field_numbers $$
+CREATE PROCEDURE _pb_message_get_unknown_field_numbers(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN path TEXT, IN message_depth INT, INOUT result JSON)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE wire_json JSON;
+	DECLARE unknown_field_numbers JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_label INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE field_path TEXT;
+	DECLARE map_entry_descriptor JSON;
+	DECLARE is_map BOOLEAN;
+
+	-- Processing variables
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE entry_wire_json JSON;
+	DECLARE map_key JSON;
+	DECLARE sub_message LONGBLOB;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_get_unknown_field_numbers');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		-- Well-known types may be used without being included in the descriptor set
+		IF path <> '' AND full_type_name LIKE '.google.protobuf.%' THEN
+			LEAVE proc;
+		END IF;
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_get_unknown_field_numbers', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET wire_json = pb_message_to_wire_json(buf);
+
+	SET unknown_field_numbers = JSON_KEYS(_pb_wire_json_get_unknown_fields(message_descriptor, wire_json));
+	SET element_count = JSON_LENGTH(unknown_field_numbers);
+	SET element_index = 0;
+	WHILE element_index < element_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(unknown_field_numbers, CONCAT('$[', element_index, ']')));
+		SET result = JSON_ARRAY_APPEND(result, '$', JSON_OBJECT('path', path, 'number', field_number));
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	l1: WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_label = JSON_EXTRACT(field_descriptor, '$."4"'); -- label
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET field_index = field_index + 1;
+
+		IF field_type <> 11 OR JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"')) IS NULL THEN -- TYPE_MESSAGE
+			ITERATE l1;
+		END IF;
+
+		SET field_path = _pb_util_join_field_path(path, field_name);
+
+		SET is_map = FALSE;
+		IF field_label = 3 THEN -- LABEL_REPEATED
+			SET map_entry_descriptor = _pb_get_message_descriptor(descriptor_set_json, field_type_name);
+			SET is_map = COALESCE(CAST(JSON_EXTRACT(map_entry_descriptor, '$."7"."7"') AS UNSIGNED), FALSE); -- map_entry
+		END IF;
+
+		IF is_map THEN
+			IF JSON_EXTRACT(map_entry_descriptor, '$."2"[1]."5"') <> 11 THEN -- value is not TYPE_MESSAGE
+				ITERATE l1;
+			END IF;
+			SET elements = pb_wire_json_get_repeated_message_field_as_json_array(wire_json, field_number);
+			SET element_count = JSON_LENGTH(elements);
+			SET element_index = 0;
+			WHILE element_index < element_count DO
+				SET entry_wire_json = pb_message_to_wire_json(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, ']')))));
+				SET sub_message = pb_wire_json_get_message_field(entry_wire_json, 2, NULL);
+				IF sub_message IS NOT NULL THEN
+					CALL _pb_wire_json_get_primitive_field_as_json(entry_wire_json, 1, JSON_EXTRACT(map_entry_descriptor, '$."2"[0]."5"'), FALSE, FALSE, FALSE, map_key);
+					CALL _pb_message_get_unknown_field_numbers(descriptor_set_json, JSON_UNQUOTE(JSON_EXTRACT(map_entry_descriptor, '$."2"[1]."6"')), sub_message,
+						CONCAT(field_path, _pb_util_format_map_key_subscript(IF(JSON_TYPE(map_key) = 'STRING', JSON_UNQUOTE(map_key), CAST(map_key AS CHAR)))),
+						message_depth + 2, result); -- the map entry counts as a message
+				END IF;
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSEIF field_label = 3 THEN
+			SET element_count = pb_wire_json_get_repeated_message_field_count(wire_json, field_number);
+			SET element_index = 0;
+			WHILE element_index < element_count DO
+				CALL _pb_message_get_unknown_field_numbers(descriptor_set_json, field_type_name, pb_wire_json_get_repeated_message_field_element(wire_json, field_number, element_index),
+					CONCAT(field_path, '[', element_index, ']'), message_depth + 1, result);
+				SET element_index = element_index + 1;
+			END WHILE;
+		ELSE
+			CALL _pb_message_get_unknown_field_numbers(descriptor_set_json, field_type_name, _pb_wire_json_get_merged_message_field(wire_json, field_number), field_path, message_depth + 1, result);
+		END IF;
+	END WHILE;
+END $$
+
+-- Returns the unknown fields of a message and its nested messages as a JSON array of {"path": ..., "number": ...} objects
+DROP FUNCTION IF EXISTS pb_message_get_unknown_field_numbers $$
+CREATE FUNCTION pb_message_get_unknown_field_numbers(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE result JSON;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET result = JSON_ARRAY();
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_get_unknown_field_numbers(descriptor_set_json, type_name, message, '', 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
+
+-- Helper procedure to remove the unknown fields of a message and its nested messages.
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_discard_unknown $$
+CREATE PROCEDURE _pb_message_discard_unknown(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN message_depth INT, OUT result LONGBLOB)
+proc: BEGIN
+	DECLARE CUSTOM_EXCEPTION CONDITION FOR SQLSTATE '45000';
+
+	DECLARE message_text TEXT;
+	DECLARE message_descriptor JSON;
+	DECLARE wire_json JSON;
+	DECLARE unknown_field_numbers JSON;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT DEFAULT 0;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+
+	-- Processing variables
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE sub_message LONGBLOB;
+
+	CALL _pb_check_message_depth(message_depth, 'pb_message_discard_unknown');
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		-- Well-known types may be used without being included in the descriptor set
+		IF message_depth > 1 AND full_type_name LIKE '.google.protobuf.%' THEN
+			SET result = buf;
+			LEAVE proc;
+		END IF;
+		CALL _pb_signal_error('PB_TYPE_NOT_FOUND', 'pb_message_discard_unknown', CONCAT('message type `', full_type_name, '` not found in descriptor set'));
+	END IF;
+
+	SET wire_json = pb_message_to_wire_json(buf);
+
+	SET unknown_field_numbers = JSON_KEYS(_pb_wire_json_get_unknown_fields(message_descriptor, wire_json));
+	SET element_count = JSON_LENGTH(unknown_field_numbers);
+	SET element_index = 0;
+	WHILE element_index < element_count DO
+		SET wire_json = JSON_REMOVE(wire_json, CONCAT('$.', JSON_EXTRACT(unknown_field_numbers, CONCAT('$[', element_index, ']'))));
+		SET element_index = element_index + 1;
+	END WHILE;
+
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+
+	l1: WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET field_index = field_index + 1;
+
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		IF field_type <> 11 OR elements IS NULL THEN -- TYPE_MESSAGE
+			ITERATE l1;
+		END IF;
+
+		-- Each occurrence is processed separately, so that occurrences of a singular field are still merged by parsers.
+		-- Map entries are messages too, so unknown fields in entries and their values are removed the same way.
+		SET element_count = JSON_LENGTH(elements);
+		SET element_index = 0;
+		WHILE element_index < element_count DO
+			CALL _pb_message_discard_unknown(descriptor_set_json, field_type_name, FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(elements, CONCAT('$[', element_index, '].v')))), message_depth + 1, sub_message);
+			SET elements = JSON_SET(elements, CONCAT('$[', element_index, '].v'), TO_BASE64(sub_message));
+			SET element_index = element_index + 1;
+		END WHILE;
+		SET wire_json = JSON_SET(wire_json, CONCAT('$."', field_number, '"'), elements);
+	END WHILE;
+
+	SET result = pb_wire_json_to_message(wire_json);
+END $$
+
+-- Removes the fields that are not declared in the schema from a message and its nested messages
+DROP FUNCTION IF EXISTS pb_message_discard_unknown $$
+CREATE FUNCTION pb_message_discard_unknown(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS LONGBLOB DETERMINISTIC
+BEGIN
+	DECLARE result LONGBLOB;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_discard_unknown(descriptor_set_json, type_name, message, 1, result);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN result;
+END $$
@@ -0,0 +1,298 @@
+DELIMITER $$
+
+-- Helper function to get the wire type used by a field type
+DROP FUNCTION IF EXISTS _pb_field_type_to_wire_type $$
+CREATE FUNCTION _pb_field_type_to_wire_type(field_type INT) RETURNS INT DETERMINISTIC
+BEGIN
+	CASE
+	WHEN field_type IN (3, 4, 5, 8, 13, 14, 17, 18) THEN -- int64, uint64, int32, bool, uint32, enum, sint32, sint64
+		RETURN 0; -- VARINT
+	WHEN field_type IN (1, 6, 16) THEN -- double, fixed64, sfixed64
+		RETURN 1; -- I64
+	WHEN field_type IN (9, 11, 12) THEN -- string, message, bytes
+		RETURN 2; -- LEN
+	WHEN field_type = 10 THEN -- group
+		RETURN 3; -- SGROUP
+	WHEN field_type IN (2, 7, 15) THEN -- float, fixed32, sfixed32
+		RETURN 5; -- I32
+	ELSE
+		RETURN NULL;
+	END CASE;
+END $$
+
+-- Helper function to determine whether an enum is closed, i.e. values not declared in the enum are not allowed.
+-- Enums in proto2 files are closed, enums in proto3 files are open, and editions use the enum_type feature.
+DROP FUNCTION IF EXISTS _pb_is_enum_closed $$
+CREATE FUNCTION _pb_is_enum_closed(descriptor_set_json JSON, enum_type_name TEXT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE syntax TEXT;
+	DECLARE enum_type_feature INT;
+
+	SET syntax = _pb_get_file_syntax(descriptor_set_json, enum_type_name);
+	CASE syntax
+	WHEN 'proto2' THEN
+		RETURN TRUE;
+	WHEN 'editions' THEN
+		SET enum_type_feature = COALESCE(
+			JSON_EXTRACT(_pb_get_enum_descriptor(descriptor_set_json, enum_type_name), '$."3"."7"."2"'), -- options.features.enum_type
+			JSON_EXTRACT(_pb_get_file_descriptor(descriptor_set_json, enum_type_name), '$."8"."50"."2"') -- options.features.enum_type
+		);
+		RETURN enum_type_feature = 2; -- CLOSED
+	ELSE
+		RETURN FALSE;
+	END CASE;
+END $$
+
+-- Helper function to check whether a number is declared in an enum
+DROP FUNCTION IF EXISTS _pb_enum_has_value $$
+CREATE FUNCTION _pb_enum_has_value(descriptor_set_json JSON, enum_type_name TEXT, enum_value_number BIGINT) RETURNS BOOLEAN DETERMINISTIC
+BEGIN
+	DECLARE enum_descriptor JSON;
+
+	SET enum_descriptor = _pb_get_enum_descriptor(descriptor_set_json, enum_type_name);
+	IF enum_descriptor IS NULL THEN
+		RETURN TRUE;
+	END IF;
+
+	RETURN COALESCE(JSON_CONTAINS(JSON_EXTRACT(enum_descriptor, '$."2"[*]."2"'), CAST(enum_value_number AS JSON)), FALSE); -- value[*].number
+END $$
+
+-- Helper function to append a segment to a field path
+DROP FUNCTION IF EXISTS _pb_util_join_field_path $$
+CREATE FUNCTION _pb_util_join_field_path(path TEXT, segment TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	IF path = '' THEN
+		RETURN segment;
+	END IF;
+	RETURN CONCAT(path, '.', segment);
+END $$
+
+-- Helper function to build a single problem reported by pb_message_validate()
+DROP FUNCTION IF EXISTS _pb_message_validation_problem $$
+CREATE FUNCTION _pb_message_validation_problem(path TEXT, code TEXT, message TEXT) RETURNS JSON DETERMINISTIC
+BEGIN
+	RETURN JSON_OBJECT('path', path, 'code', code, 'message', message);
+END $$
+
+-- Helper function to strip the `function_name: ` prefix and the error code suffix from an error message raised by the wire format readers
+DROP FUNCTION IF EXISTS _pb_util_strip_error_prefix $$
+CREATE FUNCTION _pb_util_strip_error_prefix(message_text TEXT) RETURNS TEXT DETERMINISTIC
+BEGIN
+	IF message_text LIKE '% (PB\_%)' THEN
+		SET message_text = LEFT(message_text, CHAR_LENGTH(message_text) - CHAR_LENGTH(SUBSTRING_INDEX(message_text, ' (PB_', -1)) - 5);
+	END IF;
+	IF message_text LIKE '\_pb\_%: %' OR message_text LIKE 'pb\_%: %' THEN
+		RETURN SUBSTRING(message_text, LOCATE(': ', message_text) + 2);
+	END IF;
+	RETURN message_text;
+END $$
+
+-- Helper procedure to validate a message against its descriptor, appending problems to the given JSON array.
+-- message_depth is 1 for the top-level message.
+DROP PROCEDURE IF EXISTS _pb_message_validate $$
+CREATE PROCEDURE _pb_message_validate(IN descriptor_set_json JSON, IN full_type_name TEXT, IN buf LONGBLOB, IN path TEXT, IN message_depth INT, INOUT problems JSON)
+proc: BEGIN
+	DECLARE message_descriptor JSON;
+	DECLARE wire_json JSON;
+	DECLARE error_message TEXT;
+
+	-- Field properties
+	DECLARE fields JSON;
+	DECLARE field_count INT;
+	DECLARE field_index INT;
+	DECLARE field_descriptor JSON;
+	DECLARE field_number INT;
+	DECLARE field_name TEXT;
+	DECLARE field_type INT;
+	DECLARE field_type_name TEXT;
+	DECLARE field_path TEXT;
+	DECLARE element_path TEXT;
+	DECLARE is_repeated BOOLEAN;
+	DECLARE is_required BOOLEAN;
+	DECLARE is_closed_enum BOOLEAN;
+	DECLARE expected_wire_type INT;
+	DECLARE known_field_numbers JSON;
+
+	-- Wire elements
+	DECLARE elements JSON;
+	DECLARE element_count INT;
+	DECLARE element_index INT;
+	DECLARE element JSON;
+	DECLARE wire_type INT;
+	DECLARE value_index INT;
+	DECLARE bytes_value LONGBLOB;
+	DECLARE packed_offset BIGINT;
+	DECLARE uint_value BIGINT UNSIGNED;
+	DECLARE merged_message LONGBLOB;
+	DECLARE value_size INT;
+
+	-- Unknown fields
+	DECLARE field_numbers JSON;
+	DECLARE field_number_count INT;
+	DECLARE field_number_index INT;
+
+	-- Reported instead of raising PB_DEPTH_LIMIT_EXCEEDED, since bad input never raises errors here
+	IF message_depth > 100 THEN
+		SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(path, 'DEPTH_LIMIT_EXCEEDED', 'message nesting depth exceeds 100'));
+		LEAVE proc;
+	END IF;
+
+	SET message_descriptor = _pb_get_message_descriptor(descriptor_set_json, full_type_name);
+	IF message_descriptor IS NULL THEN
+		SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(path, 'UNKNOWN_MESSAGE_TYPE', CONCAT('message type `', full_type_name, '` not found in descriptor set')));
+		LEAVE proc;
+	END IF;
+
+	-- Malformed wire data makes the rest of the message unreadable, so it is reported alone
+	BEGIN
+		DECLARE EXIT HANDLER FOR SQLEXCEPTION
+		BEGIN
+			GET DIAGNOSTICS CONDITION 1 error_message = MESSAGE_TEXT;
+		END;
+		CALL _pb_message_to_wire_json(buf, wire_json);
+	END;
+
+	IF error_message IS NOT NULL THEN
+		SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(path, 'MALFORMED_WIRE_DATA', _pb_util_strip_error_prefix(error_message)));
+		LEAVE proc;
+	END IF;
+
+	SET known_field_numbers = JSON_ARRAY();
+	SET fields = JSON_EXTRACT(message_descriptor, '$."2"');
+	SET field_count = COALESCE(JSON_LENGTH(fields), 0);
+	SET field_index = 0;
+
+	WHILE field_index < field_count DO
+		SET field_descriptor = JSON_EXTRACT(fields, CONCAT('$[', field_index, ']'));
+		SET field_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."1"')); -- name
+		SET field_number = JSON_EXTRACT(field_descriptor, '$."3"'); -- number
+		SET field_type = JSON_EXTRACT(field_descriptor, '$."5"'); -- type
+		SET field_type_name = JSON_UNQUOTE(JSON_EXTRACT(field_descriptor, '$."6"')); -- type_name
+		SET is_repeated = JSON_EXTRACT(field_descriptor, '$."4"') = 3; -- label = LABEL_REPEATED
+		SET is_required = JSON_EXTRACT(field_descriptor, '$."4"') = 2 -- label = LABEL_REQUIRED
+			OR COALESCE(JSON_EXTRACT(field_descriptor, '$."8"."21"."1"') = 3, FALSE); -- options.features.field_presence = LEGACY_REQUIRED
+		SET field_path = _pb_util_join_field_path(path, field_name);
+		SET known_field_numbers = JSON_ARRAY_APPEND(known_field_numbers, '$', field_number);
+
+		SET elements = JSON_EXTRACT(wire_json, CONCAT('$."', field_number, '"'));
+		SET element_count = COALESCE(JSON_LENGTH(elements), 0);
+
+		IF element_count = 0 AND is_required THEN
+			SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(field_path, 'MISSING_REQUIRED_FIELD', CONCAT('required field `', field_name, '` is missing')));
+		END IF;
+
+		SET expected_wire_type = _pb_field_type_to_wire_type(field_type);
+		SET is_closed_enum = field_type = 14 AND _pb_is_enum_closed(descriptor_set_json, field_type_name);
+		SET merged_message = NULL;
+		SET value_index = 0;
+		SET element_index = 0;
+
+		WHILE element_index < element_count DO
+			SET element = JSON_EXTRACT(elements, CONCAT('$[', element_index, ']'));
+			SET wire_type = JSON_EXTRACT(element, '$.t');
+			SET element_path = IF(is_repeated, CONCAT(field_path, '[', value_index, ']'), field_path);
+
+			IF wire_type = expected_wire_type THEN
+				IF field_type = 9 THEN -- string
+					IF NOT _pb_util_is_valid_utf8(FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')))) THEN
+						SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(element_path, 'INVALID_UTF8', CONCAT('string field `', field_name, '` contains invalid UTF-8')));
+					END IF;
+				ELSEIF field_type = 11 THEN -- message
+					SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
+					IF is_repeated THEN
+						CALL _pb_message_validate(descriptor_set_json, field_type_name, bytes_value, element_path, message_depth + 1, problems);
+					ELSE
+						-- Occurrences of a singular message field are merged, so they are validated together
+						SET merged_message = CONCAT(COALESCE(merged_message, _binary ''), bytes_value);
+					END IF;
+				ELSEIF field_type = 14 THEN -- enum
+					IF is_closed_enum AND NOT _pb_enum_has_value(descriptor_set_json, field_type_name, _pb_util_reinterpret_uint64_as_int64(JSON_EXTRACT(element, '$.v'))) THEN
+						SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(element_path, 'ENUM_VALUE_OUT_OF_RANGE', CONCAT('value ', _pb_util_reinterpret_uint64_as_int64(JSON_EXTRACT(element, '$.v')), ' is not declared in enum `', field_type_name, '`')));
+					END IF;
+				END IF;
+				SET value_index = value_index + 1;
+
+			ELSEIF wire_type = 2 AND is_repeated AND expected_wire_type IN (0, 1, 5) THEN
+				-- Packed repeated field
+				SET bytes_value = FROM_BASE64(JSON_UNQUOTE(JSON_EXTRACT(element, '$.v')));
+				IF expected_wire_type = 0 THEN
+					SET error_message = NULL;
+					BEGIN
+						DECLARE EXIT HANDLER FOR SQLEXCEPTION
+						BEGIN
+							GET DIAGNOSTICS CONDITION 1 error_message = MESSAGE_TEXT;
+						END;
+						SET packed_offset = 0;
+						WHILE packed_offset < LENGTH(bytes_value) DO
+							CALL _pb_wire_read_varint_as_uint64(SUBSTRING(bytes_value, packed_offset + 1, 10), packed_offset, uint_value);
+							IF is_closed_enum AND NOT _pb_enum_has_value(descriptor_set_json, field_type_name, _pb_util_reinterpret_uint64_as_int64(uint_value)) THEN
+								SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(CONCAT(field_path, '[', value_index, ']'), 'ENUM_VALUE_OUT_OF_RANGE', CONCAT('value ', _pb_util_reinterpret_uint64_as_int64(uint_value), ' is not declared in enum `', field_type_name, '`')));
+							END IF;
+							SET value_index = value_index + 1;
+						END WHILE;
+					END;
+					IF error_message IS NOT NULL THEN
+						SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(field_path, 'MALFORMED_WIRE_DATA', CONCAT('packed field `', field_name, '`: ', _pb_util_strip_error_prefix(error_message))));
+					END IF;
+				ELSE
+					SET value_size = IF(expected_wire_type = 1, 8, 4);
+					IF LENGTH(bytes_value) % value_size <> 0 THEN
+						SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(field_path, 'MALFORMED_WIRE_DATA', CONCAT('packed field `', field_name, '`: length ', LENGTH(bytes_value), ' is not a multiple of ', value_size)));
+					END IF;
+					SET value_index = value_index + LENGTH(bytes_value) DIV value_size;
+				END IF;
+
+			ELSE
+				SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(element_path, 'WIRE_TYPE_MISMATCH', CONCAT('field `', field_name, '` expects wire type ', _pb_wire_type_name(expected_wire_type), ' but got ', _pb_wire_type_name(wire_type))));
+				SET value_index = value_index + 1;
+			END IF;
+
+			SET element_index = element_index + 1;
+		END WHILE;
+
+		IF merged_message IS NOT NULL THEN
+			CALL _pb_message_validate(descriptor_set_json, field_type_name, merged_message, field_path, message_depth + 1, problems);
+		END IF;
+
+		SET field_index = field_index + 1;
+	END WHILE;
+
+	-- Field numbers present on the wire but not declared in the message type
+	SET field_numbers = JSON_KEYS(wire_json);
+	SET field_number_count = JSON_LENGTH(field_numbers);
+	SET field_number_index = 0;
+	WHILE field_number_index < field_number_count DO
+		SET field_number = JSON_UNQUOTE(JSON_EXTRACT(field_numbers, CONCAT('$[', field_number_index, ']')));
+		IF field_number = 0 THEN
+			SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(path, 'MALFORMED_WIRE_DATA', 'invalid field number 0'));
+		ELSEIF NOT JSON_CONTAINS(known_field_numbers, CAST(field_number AS JSON)) THEN
+			SET problems = JSON_ARRAY_APPEND(problems, '$', _pb_message_validation_problem(_pb_util_join_field_path(path, field_number), 'UNKNOWN_FIELD', CONCAT('field number ', field_number, ' is not declared in message type `', full_type_name, '`')));
+		END IF;
+		SET field_number_index = field_number_index + 1;
+	END WHILE;
+END $$
+
+-- Validates a message against its schema and returns a JSON array of problems; an empty array means the message is valid.
+-- Each problem is an object {"path": ..., "code": ..., "message": ...}. This function does not raise errors for bad input.
+DROP FUNCTION IF EXISTS pb_message_validate $$
+CREATE FUNCTION pb_message_validate(descriptor_set_json JSON, type_name TEXT, message LONGBLOB) RETURNS JSON DETERMINISTIC
+BEGIN
+	DECLARE problems JSON;
+	DECLARE saved_recursion_depth INT;
+
+	DECLARE EXIT HANDLER FOR SQLEXCEPTION
+	BEGIN
+		CALL _pb_restore_recursion_depth(saved_recursion_depth);
+		RESIGNAL;
+	END;
+
+	IF message IS NULL THEN
+		RETURN NULL;
+	END IF;
+
+	SET problems = JSON_ARRAY();
+	CALL _pb_raise_recursion_depth(saved_recursion_depth);
+	CALL _pb_message_validate(descriptor_set_json, type_name, message, '', 1, problems);
+	CALL _pb_restore_recursion_depth(saved_recursion_depth);
+	RETURN problems;
+END $$
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+)
+
+func TestDelimited(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Event {
+			|    int32 id = 1;
+			|    string name = 2;
+			|}
+		`),
+	})
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// [{id: 1}, {}, {id: 2, name: "x"}]
+	const stream = "_binary X'02080100050802120178'"
+
+	t.Run("pb_delimited_count", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_delimited_count("+stream+")").IsEqualToInt(3)
+		RunTestThatExpression(t, "pb_delimited_count(_binary X'')").IsEqualToInt(0)
+		RunTestThatExpression(t, "pb_delimited_count(NULL)").IsNull()
+	})
+
+	t.Run("pb_delimited_get_element", func(t *testing.T) {
+		RunTestThatExpression(t, "HEX(pb_delimited_get_element("+stream+", 0))").IsEqualToString("0801")
+		RunTestThatExpression(t, "HEX(pb_delimited_get_element("+stream+", 1))").IsEqualToString("")
+		RunTestThatExpression(t, "HEX(pb_delimited_get_element("+stream+", 2))").IsEqualToString("0802120178")
+		RunTestThatExpression(t, "pb_delimited_get_element("+stream+", 3)").ToFailWithSignalException("45000", "pb_delimited_get_element: element index out of range")
+		RunTestThatExpression(t, "pb_delimited_get_element("+stream+", -1)").ToFailWithSignalException("45000", "pb_delimited_get_element: element index out of range")
+		RunTestThatExpression(t, "pb_delimited_get_element(NULL, 0)").IsNull()
+	})
+
+	t.Run("pb_delimited_append", func(t *testing.T) {
+		RunTestThatExpression(t, "HEX(pb_delimited_append(pb_delimited_append(pb_delimited_append(NULL, _binary X'0801'), _binary X''), _binary X'0802120178'))").IsEqualToString("02080100050802120178")
+		RunTestThatExpression(t, "HEX(pb_delimited_append(_binary X'', _binary X'0801'))").IsEqualToString("020801")
+		RunTestThatExpression(t, "pb_delimited_append(_binary X'020801', NULL)").IsNull()
+		// Lengths of 128 bytes or more take more than one byte
+		RunTestThatExpression(t, "HEX(LEFT(pb_delimited_append(NULL, REPEAT(_binary X'00', 200)), 2))").IsEqualToString("C801")
+		RunTestThatExpression(t, "HEX(pb_delimited_get_element(pb_delimited_append(_binary X'020801', REPEAT(_binary X'00', 200)), 1)) = REPEAT('00', 200)").IsTrue()
+	})
+
+	t.Run("pb_delimited_as_json_array", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_delimited_as_json_array(?, '.Event', "+stream+")", descriptorSetJson).IsEqualToJsonString(`[{"id": 1, "name": ""}, {"id": 0, "name": ""}, {"id": 2, "name": "x"}]`)
+		RunTestThatExpression(t, "pb_delimited_as_json_array(?, '.Event', _binary X'')", descriptorSetJson).IsEqualToJsonString(`[]`)
+		RunTestThatExpression(t, "pb_delimited_as_json_array(?, '.Event', NULL)", descriptorSetJson).IsNull()
+	})
+
+	t.Run("malformed streams", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_delimited_count(_binary X'02080103')").ToFailWithMySQLError(45003, "45003", "_pb_delimited_read_element_header: Unexpected end of BLOB. (PB_LENGTH_OUT_OF_BOUNDS, offset 4)")
+		RunTestThatExpression(t, "pb_delimited_count(_binary X'02080180')").ToFailWithMySQLError(45001, "45001", "_pb_wire_read_varint_as_uint64: Unexpected end of BLOB. (PB_TRUNCATED_VARINT, offset 3)")
+		RunTestThatExpression(t, "pb_delimited_get_element(_binary X'02080103', 1)").ToFailWithMySQLError(45003, "45003", "(PB_LENGTH_OUT_OF_BOUNDS, offset 4)")
+		RunTestThatExpression(t, "pb_delimited_as_json_array(?, '.Event', _binary X'02080180')", descriptorSetJson).ToFailWithMySQLError(45001, "45001", "(PB_TRUNCATED_VARINT, offset 3)")
+		// Errors in a message are reported with the offset in the message
+		RunTestThatExpression(t, "pb_delimited_as_json_array(?, '.Event', _binary X'020880')", descriptorSetJson).ToFailWithMySQLError(45001, "45001", "(PB_TRUNCATED_VARINT, offset 1, field 1)")
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+)
+
+func TestDecoderErrors(t *testing.T) {
+	t.Run("PB_TRUNCATED_VARINT", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_int32_field(_binary X'08', 1, 0)").ToFailWithMySQLError(45001, "45001", "_pb_wire_read_varint_as_uint64: Unexpected end of BLOB. (PB_TRUNCATED_VARINT, offset 1, field 1)")
+		RunTestThatExpression(t, "pb_message_to_wire_json(_binary X'0801FF')").ToFailWithMySQLError(45001, "45001", "_pb_wire_read_varint_as_uint64: Unexpected end of BLOB. (PB_TRUNCATED_VARINT, offset 2)")
+		RunTestThatExpression(t, "pb_message_get_repeated_int32_field(_binary X'080108', 1)").ToFailWithMySQLError(45001, "45001", "(PB_TRUNCATED_VARINT, offset 3, field 1)")
+	})
+
+	t.Run("PB_VARINT_OVERFLOW", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_int64_field(_binary X'08FFFFFFFFFFFFFFFFFFFFFF01', 1, 0)").ToFailWithMySQLError(45002, "45002", "_pb_wire_read_varint_as_uint64: Varint cannot exceed 10 bytes. (PB_VARINT_OVERFLOW, offset 1, field 1)")
+	})
+
+	t.Run("PB_LENGTH_OUT_OF_BOUNDS", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_string_field(_binary X'1205', 2, '')").ToFailWithMySQLError(45003, "45003", "Unexpected end of BLOB. (PB_LENGTH_OUT_OF_BOUNDS, offset 1, field 2)")
+		RunTestThatExpression(t, "pb_message_get_fixed32_field(_binary X'1D0102', 3, 0)").ToFailWithMySQLError(45003, "45003", "_pb_wire_read_i32_as_uint32: Unexpected end of BLOB. (PB_LENGTH_OUT_OF_BOUNDS, offset 1, field 3)")
+	})
+
+	t.Run("PB_UNSUPPORTED_WIRE_TYPE", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_wire_json(_binary X'0E')").ToFailWithMySQLError(45004, "45004", "_pb_message_to_wire_json: unsupported wire type (6) (PB_UNSUPPORTED_WIRE_TYPE, offset 1, field 1)")
+	})
+
+	t.Run("PB_WIRE_TYPE_MISMATCH", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_string_field(_binary X'0801', 1, '')").ToFailWithMySQLError(45005, "45005", "_pb_message_get_len_type_field: string or bytes value cannot be parsed from VARINT wire type. (PB_WIRE_TYPE_MISMATCH, offset 1, field 1)")
+		RunTestThatExpression(t, "pb_wire_json_get_string_field(pb_message_to_wire_json(_binary X'0801'), 1, '')").ToFailWithMySQLError(45005, "45005", "(PB_WIRE_TYPE_MISMATCH)")
+	})
+
+	t.Run("conversions", func(t *testing.T) {
+		g := NewWithT(t)
+
+		p := testutils.NewProtoTestSupport(t, map[string]string{
+			"main.proto": dedent.Pipe(`
+				|syntax = "proto3";
+				|message Test {
+				|    string name = 1;
+				|    Inner inner = 4;
+				|    repeated int32 values = 5;
+				|}
+				|message Inner {
+				|    int32 a = 2;
+				|    string b = 3;
+				|}
+			`),
+		})
+		descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+		g.Expect(err).NotTo(HaveOccurred())
+
+		// PB_TYPE_NOT_FOUND
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Unknown', _binary X'')", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "_pb_message_to_json: message type `.Unknown` not found in descriptor set (PB_TYPE_NOT_FOUND)")
+
+		// PB_INVALID_UTF8
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', _binary X'0A01FF')", descriptorSetJson).ToFailWithMySQLError(45007, "45007", "invalid UTF-8 in string field (PB_INVALID_UTF8, field 1)")
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', _binary X'22031A01FF')", descriptorSetJson).ToFailWithMySQLError(45007, "45007", "(PB_INVALID_UTF8, field 4.3)")
+
+		// Errors in nested messages are reported with the field path from the outermost message
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', _binary X'220110')", descriptorSetJson).ToFailWithMySQLError(45001, "45001", "(PB_TRUNCATED_VARINT, offset 1, field 4.2)")
+
+		// Conversion limits (see pb_message_to_json_limits_test.go for the boundaries of each limit)
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Test', _binary X'2200', '{\"MaxDepth\": 1}')", descriptorSetJson).ToFailWithMySQLError(45008, "45008", "_pb_message_to_json: message nesting depth exceeds MaxDepth (1) (PB_DEPTH_LIMIT_EXCEEDED)")
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Test', _binary X'0A0161', '{\"MaxOutputSize\": 1}')", descriptorSetJson).ToFailWithMySQLError(45009, "45009", "(PB_OUTPUT_SIZE_LIMIT_EXCEEDED)")
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Test', _binary X'2A020102', '{\"MaxElements\": 1}')", descriptorSetJson).ToFailWithMySQLError(45010, "45010", "(PB_ELEMENT_LIMIT_EXCEEDED)")
+	})
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/protorandom"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// scrambleMessage re-encodes a serialized message without changing its content: fields are shuffled, packed fields are
+// unpacked, and singular scalar fields are preceded by an occurrence with a random value.
+func scrambleMessage(rng *rand.Rand, descriptor protoreflect.MessageDescriptor, b []byte) []byte {
+	var numbers []protowire.Number
+	groups := map[protowire.Number][]byte{}
+	for len(b) > 0 {
+		number, wireType, n := protowire.ConsumeTag(b)
+		value := b[n:]
+		m := protowire.ConsumeFieldValue(number, wireType, value)
+		value = value[:m]
+		b = b[n+m:]
+
+		if _, ok := groups[number]; !ok {
+			numbers = append(numbers, number)
+		}
+		group := groups[number]
+
+		field := descriptor.Fields().ByNumber(number)
+		switch {
+		case field == nil || field.Kind() == protoreflect.MessageKind || field.Kind() == protoreflect.StringKind || field.Kind() == protoreflect.BytesKind:
+			group = protowire.AppendTag(group, number, wireType)
+			group = append(group, value...)
+		case field.IsList() && wireType == protowire.BytesType:
+			payload, _ := protowire.ConsumeBytes(value)
+			for len(payload) > 0 {
+				switch field.Kind() {
+				case protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind, protoreflect.FloatKind:
+					v, n := protowire.ConsumeFixed32(payload)
+					group = protowire.AppendFixed32(protowire.AppendTag(group, number, protowire.Fixed32Type), v)
+					payload = payload[n:]
+				case protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind, protoreflect.DoubleKind:
+					v, n := protowire.ConsumeFixed64(payload)
+					group = protowire.AppendFixed64(protowire.AppendTag(group, number, protowire.Fixed64Type), v)
+					payload = payload[n:]
+				default:
+					v, n := protowire.ConsumeVarint(payload)
+					group = protowire.AppendVarint(protowire.AppendTag(group, number, protowire.VarintType), v)
+					payload = payload[n:]
+				}
+			}
+		case !field.IsList() && rng.Intn(2) == 0:
+			group = protowire.AppendTag(group, number, wireType)
+			switch wireType {
+			case protowire.Fixed32Type:
+				group = protowire.AppendFixed32(group, rng.Uint32())
+			case protowire.Fixed64Type:
+				group = protowire.AppendFixed64(group, rng.Uint64())
+			default:
+				group = protowire.AppendVarint(group, rng.Uint64())
+			}
+			group = protowire.AppendTag(group, number, wireType)
+			group = append(group, value...)
+		default:
+			group = protowire.AppendTag(group, number, wireType)
+			group = append(group, value...)
+		}
+		groups[number] = group
+	}
+
+	rng.Shuffle(len(numbers), func(i, j int) {
+		numbers[i], numbers[j] = numbers[j], numbers[i]
+	})
+	var result []byte
+	for _, number := range numbers {
+		result = append(result, groups[number]...)
+	}
+	return result
+}
+
+func TestMessageCanonicalize(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 int32_field = 1;
+			|    string string_field = 2;
+			|    repeated int32 repeated_int32_field = 3;
+			|    Inner inner = 4;
+			|    map<string, int32> string_map = 5;
+			|    oneof choice {
+			|        string choice_string = 6;
+			|        int32 choice_int32 = 7;
+			|    }
+			|    bool bool_field = 8;
+			|    repeated fixed32 repeated_fixed32_field = 9;
+			|    map<int32, string> int32_map = 10;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    int32 b = 2;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+
+	varintField := func(b []byte, number protowire.Number, v uint64) []byte {
+		return protowire.AppendVarint(protowire.AppendTag(b, number, protowire.VarintType), v)
+	}
+	bytesField := func(b []byte, number protowire.Number, v []byte) []byte {
+		return protowire.AppendBytes(protowire.AppendTag(b, number, protowire.BytesType), v)
+	}
+	fixed32Field := func(b []byte, number protowire.Number, v uint32) []byte {
+		return protowire.AppendFixed32(protowire.AppendTag(b, number, protowire.Fixed32Type), v)
+	}
+
+	testCanonicalize := func(input []byte, expected []byte) {
+		RunTestThatExpression(t, "pb_message_canonicalize(?, ?, ?)", descriptorSetJson, typeName, input).IsEqualToBytes(expected)
+	}
+
+	t.Run("fields are sorted by field number", func(t *testing.T) {
+		testCanonicalize(varintField(bytesField(nil, 2, []byte("a")), 1, 1), bytesField(varintField(nil, 1, 1), 2, []byte("a")))
+	})
+
+	t.Run("repeated scalar fields are packed", func(t *testing.T) {
+		testCanonicalize(varintField(varintField(nil, 3, 1), 3, 2), bytesField(nil, 3, []byte{1, 2}))
+		testCanonicalize(varintField(bytesField(nil, 3, []byte{1}), 3, 2), bytesField(nil, 3, []byte{1, 2}))
+		testCanonicalize(bytesField(fixed32Field(nil, 9, 1), 9, protowire.AppendFixed32(nil, 2)), bytesField(nil, 9, protowire.AppendFixed32(protowire.AppendFixed32(nil, 1), 2)))
+	})
+
+	t.Run("duplicates are collapsed", func(t *testing.T) {
+		testCanonicalize(varintField(varintField(nil, 1, 1), 1, 2), varintField(nil, 1, 2))
+		testCanonicalize(bytesField(bytesField(nil, 4, varintField(nil, 1, 1)), 4, varintField(nil, 2, 2)), bytesField(nil, 4, varintField(varintField(nil, 1, 1), 2, 2)))
+	})
+
+	t.Run("map entries are deduplicated and sorted by key", func(t *testing.T) {
+		stringEntry := func(key string, value uint64) []byte {
+			return varintField(bytesField(nil, 1, []byte(key)), 2, value)
+		}
+		testCanonicalize(
+			bytesField(bytesField(bytesField(nil, 5, stringEntry("b", 1)), 5, stringEntry("a", 2)), 5, stringEntry("b", 3)),
+			bytesField(bytesField(nil, 5, stringEntry("a", 2)), 5, stringEntry("b", 3)),
+		)
+
+		int32Entry := func(key int64, value string) []byte {
+			return bytesField(varintField(nil, 1, uint64(key)), 2, []byte(value))
+		}
+		testCanonicalize(
+			bytesField(bytesField(bytesField(nil, 10, int32Entry(2, "x")), 10, int32Entry(-1, "y")), 10, int32Entry(1, "z")),
+			bytesField(bytesField(bytesField(nil, 10, int32Entry(-1, "y")), 10, int32Entry(1, "z")), 10, int32Entry(2, "x")),
+		)
+	})
+
+	t.Run("varints are normalized", func(t *testing.T) {
+		testCanonicalize(varintField(nil, 1, 0xFFFFFFFF), varintField(nil, 1, uint64(0xFFFFFFFFFFFFFFFF)))
+		testCanonicalize(varintField(nil, 8, 2), varintField(nil, 8, 1))
+	})
+
+	t.Run("default values of fields without presence are dropped", func(t *testing.T) {
+		testCanonicalize(bytesField(varintField(nil, 1, 0), 2, nil), []byte{})
+		testCanonicalize(bytesField(nil, 4, nil), bytesField(nil, 4, nil))
+		testCanonicalize(bytesField(nil, 6, nil), bytesField(nil, 6, nil))
+	})
+
+	t.Run("oneofs", func(t *testing.T) {
+		testCanonicalize(varintField(bytesField(nil, 6, []byte("x")), 7, 5), varintField(nil, 7, 5))
+	})
+
+	t.Run("unknown fields are kept", func(t *testing.T) {
+		testCanonicalize(varintField(varintField(nil, 100, 1), 1, 1), varintField(varintField(nil, 1, 1), 100, 1))
+	})
+
+	t.Run("equals", func(t *testing.T) {
+		a := varintField(bytesField(nil, 2, []byte("a")), 1, 1)
+		b := bytesField(varintField(varintField(nil, 1, 2), 1, 1), 2, []byte("a"))
+		c := varintField(nil, 1, 1)
+		RunTestThatExpression(t, "pb_message_equals(?, ?, ?, ?)", descriptorSetJson, typeName, a, b).IsTrue()
+		RunTestThatExpression(t, "pb_message_equals(?, ?, ?, ?)", descriptorSetJson, typeName, a, c).IsFalse()
+		RunTestThatExpression(t, "pb_message_equals(?, ?, '', ?)", descriptorSetJson, typeName, varintField(nil, 1, 0)).IsTrue()
+		RunTestThatExpression(t, "pb_message_fingerprint(?, ?, ?) = pb_message_fingerprint(?, ?, ?)", descriptorSetJson, typeName, a, descriptorSetJson, typeName, b).IsTrue()
+		RunTestThatExpression(t, "pb_message_fingerprint(?, ?, ?) = pb_message_fingerprint(?, ?, ?)", descriptorSetJson, typeName, a, descriptorSetJson, typeName, c).IsFalse()
+		RunTestThatExpression(t, "pb_message_fingerprint(?, ?, '')", descriptorSetJson, typeName).IsEqualToString("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_canonicalize(?, '.Unknown', '')", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "pb_message_canonicalize: message type `.Unknown` not found in descriptor set")
+		RunTestThatExpression(t, "pb_message_canonicalize(?, ?, ?)", descriptorSetJson, typeName, fixed32Field(nil, 1, 1)).ToFailWithMySQLError(45005, "45005", "pb_message_canonicalize: unexpected wire type 5 for field `int32_field`")
+	})
+
+	t.Run("null", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_canonicalize(?, ?, NULL)", descriptorSetJson, typeName).IsNull()
+		RunTestThatExpression(t, "pb_message_equals(?, ?, '', NULL)", descriptorSetJson, typeName).IsNull()
+		RunTestThatExpression(t, "pb_message_fingerprint(?, ?, NULL)", descriptorSetJson, typeName).IsNull()
+	})
+}
+
+func TestRandomizedMessageCanonicalize(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 int32_field = 1;
+			|    string string_field = 2;
+			|    optional int64 optional_int64_field = 3;
+			|    Inner inner = 4;
+			|    repeated sint32 repeated_sint32_field = 5;
+			|    repeated Inner repeated_inner = 6;
+			|    map<string, int32> string_map = 7;
+			|    map<int64, Inner> inner_map = 8;
+			|    oneof choice {
+			|        string choice_string = 9;
+			|        Inner choice_inner = 10;
+			|        fixed32 choice_fixed32 = 11;
+			|    }
+			|    double double_field = 12;
+			|    bool bool_field = 13;
+			|    map<bool, bytes> bool_map = 14;
+			|    repeated Color colors = 15;
+			|    uint32 uint32_field = 16;
+			|    repeated sfixed64 repeated_sfixed64_field = 17;
+			|    repeated bool repeated_bool_field = 18;
+			|    Color color = 19;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    string b = 2;
+			|    repeated float c = 3;
+			|    Leaf leaf = 4;
+			|}
+			|message Leaf {
+			|    uint64 value = 1;
+			|}
+			|enum Color {
+			|    COLOR_UNSPECIFIED = 0;
+			|    RED = 1;
+			|    GREEN = 2;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+	descriptor := p.GetMessageDescriptor(typeName)
+
+	seed := time.Now().UnixNano()
+	t.Logf("Using seed = %d.", seed)
+	rng := rand.New(rand.NewSource(seed))
+	config := &protorandom.Config{AllowNan: proto.Bool(false)}
+
+	for i := 0; i < iterations; i++ {
+		message := protorandom.Message(rng, descriptor, config).Interface()
+		original, err := proto.Marshal(message)
+		g.Expect(err).NotTo(HaveOccurred())
+		scrambled := scrambleMessage(rng, descriptor, original)
+
+		RunTestThatExpression(t, "pb_message_canonicalize(?, ?, ?)", descriptorSetJson, typeName, scrambled).IsEqualToProto(message)
+		RunTestThatExpression(t, "pb_message_canonicalize(?, ?, ?) = pb_message_canonicalize(?, ?, ?)", descriptorSetJson, typeName, original, descriptorSetJson, typeName, scrambled).IsTrue()
+		RunTestThatExpression(t, "pb_message_canonicalize(?, ?, pb_message_canonicalize(?, ?, ?)) = pb_message_canonicalize(?, ?, ?)", descriptorSetJson, typeName, descriptorSetJson, typeName, original, descriptorSetJson, typeName, original).IsTrue()
+		RunTestThatExpression(t, "pb_message_equals(?, ?, ?, ?)", descriptorSetJson, typeName, original, scrambled).IsTrue()
+		RunTestThatExpression(t, "pb_message_fingerprint(?, ?, ?) = pb_message_fingerprint(?, ?, ?)", descriptorSetJson, typeName, original, descriptorSetJson, typeName, scrambled).IsTrue()
+	}
+}
+
+func TestMessageCanonicalizeDepth(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Node {
+			|    Node child = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	message, _ := buildNestedNode(100)
+	RunTestThatExpression(t, "pb_message_canonicalize(?, '.Node', ?)", descriptorSetJson, message).IsEqualToBytes(message)
+	message, _ = buildNestedNode(101)
+	RunTestThatExpression(t, "pb_message_canonicalize(?, '.Node', ?)", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "pb_message_canonicalize: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+	RunTestThatExpression(t, "pb_message_equals(?, '.Node', ?, ?)", descriptorSetJson, message, message).ToFailWithMySQLError(45008, "45008", "pb_message_canonicalize: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+
+	// max_sp_recursion_depth is raised for the nested messages and put back afterwards, also on errors
+	RunTestThatExpression(t, "pb_message_canonicalize(?, '.Node', _binary X'0A020A00')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_canonicalize(?, '.Unknown', _binary X'0A020A00')", descriptorSetJson).KeepsRecursionDepth()
+}
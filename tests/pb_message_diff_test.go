@@ -0,0 +1,254 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/morefloat"
+	"github.com/eiiches/mysql-protobuf-functions/internal/protorandom"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMessageDiff(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 id = 1;
+			|    string name = 2;
+			|    optional int32 count = 3;
+			|    Inner inner = 4;
+			|    repeated int32 values = 5;
+			|    repeated Inner inners = 6;
+			|    map<string, int32> labels = 7;
+			|    map<int32, Inner> inner_map = 8;
+			|    oneof choice {
+			|        string choice_string = 9;
+			|        Inner choice_inner = 10;
+			|    }
+			|    Color color = 11;
+			|    int64 big = 12;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    string b = 2;
+			|}
+			|enum Color {
+			|    COLOR_UNSPECIFIED = 0;
+			|    RED = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+
+	testDiff := func(oldJson string, newJson string, expectedDiff string) {
+		oldMessage := p.JsonToProtobuf(typeName, oldJson)
+		newMessage := p.JsonToProtobuf(typeName, newJson)
+		RunTestThatExpression(t, "pb_message_diff(?, ?, ?, ?)", descriptorSetJson, typeName, oldMessage, newMessage).IsEqualToJsonString(expectedDiff)
+		RunTestThatExpression(t, "pb_message_patch(?, ?, ?, ?)", descriptorSetJson, typeName, oldMessage, expectedDiff).IsEqualToProto(p.JsonToDynamicMessage(typeName, newJson).Interface())
+	}
+
+	t.Run("identical", func(t *testing.T) {
+		testDiff(`{"id": 1, "inner": {"a": 1}, "values": [1]}`, `{"id": 1, "inner": {"a": 1}, "values": [1]}`, `[]`)
+	})
+
+	t.Run("scalars", func(t *testing.T) {
+		testDiff(`{"id": 1, "name": "x"}`, `{"id": 2, "count": 0, "color": "RED", "big": "5"}`, `[
+			{"path": "id", "op": "replace", "old": 1, "new": 2},
+			{"path": "name", "op": "remove", "old": "x", "new": null},
+			{"path": "count", "op": "add", "old": null, "new": 0},
+			{"path": "color", "op": "add", "old": null, "new": "RED"},
+			{"path": "big", "op": "add", "old": null, "new": "5"}
+		]`)
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		testDiff(`{"inner": {"a": 1}}`, `{"inner": {"a": 2, "b": "y"}}`, `[
+			{"path": "inner.a", "op": "replace", "old": 1, "new": 2},
+			{"path": "inner.b", "op": "add", "old": null, "new": "y"}
+		]`)
+		testDiff(`{}`, `{"inner": {"a": 1}}`, `[
+			{"path": "inner", "op": "add", "old": null, "new": {}},
+			{"path": "inner.a", "op": "add", "old": null, "new": 1}
+		]`)
+		testDiff(`{"inner": {"a": 1}}`, `{}`, `[
+			{"path": "inner", "op": "remove", "old": {"a": 1}, "new": null}
+		]`)
+	})
+
+	t.Run("repeated fields are diffed by index", func(t *testing.T) {
+		testDiff(`{"values": [1, 2, 3]}`, `{"values": [1, 5]}`, `[
+			{"path": "values[1]", "op": "replace", "old": 2, "new": 5},
+			{"path": "values[2]", "op": "remove", "old": 3, "new": null}
+		]`)
+		testDiff(`{"values": [1, 2, 3]}`, `{"values": [1]}`, `[
+			{"path": "values[2]", "op": "remove", "old": 3, "new": null},
+			{"path": "values[1]", "op": "remove", "old": 2, "new": null}
+		]`)
+		testDiff(`{"values": [1]}`, `{"values": [1, 2, 3]}`, `[
+			{"path": "values[1]", "op": "add", "old": null, "new": 2},
+			{"path": "values[2]", "op": "add", "old": null, "new": 3}
+		]`)
+		testDiff(`{"inners": [{"a": 1}, {"a": 2}]}`, `{"inners": [{"a": 3}]}`, `[
+			{"path": "inners[0].a", "op": "replace", "old": 1, "new": 3},
+			{"path": "inners[1]", "op": "remove", "old": {"a": 2}, "new": null}
+		]`)
+		testDiff(`{}`, `{"inners": [{"b": "x"}]}`, `[
+			{"path": "inners[0]", "op": "add", "old": null, "new": {}},
+			{"path": "inners[0].b", "op": "add", "old": null, "new": "x"}
+		]`)
+	})
+
+	t.Run("map fields are diffed by key", func(t *testing.T) {
+		testDiff(`{"labels": {"a": 1, "b": 2}}`, `{"labels": {"b": 3, "c": 4}}`, `[
+			{"path": "labels[\"a\"]", "op": "remove", "old": 1, "new": null},
+			{"path": "labels[\"b\"]", "op": "replace", "old": 2, "new": 3},
+			{"path": "labels[\"c\"]", "op": "add", "old": null, "new": 4}
+		]`)
+		testDiff(`{"innerMap": {"1": {"a": 1}}}`, `{"innerMap": {"1": {"a": 2}, "2": {}}}`, `[
+			{"path": "inner_map[\"1\"].a", "op": "replace", "old": 1, "new": 2},
+			{"path": "inner_map[\"2\"]", "op": "add", "old": null, "new": {}}
+		]`)
+		testDiff(`{"labels": {"a\"b": 1}}`, `{"labels": {"a\"b": 2}}`, `[
+			{"path": "labels[\"a\\\"b\"]", "op": "replace", "old": 1, "new": 2}
+		]`)
+	})
+
+	t.Run("oneofs", func(t *testing.T) {
+		testDiff(`{"choiceString": "x"}`, `{"choiceInner": {"a": 1}}`, `[
+			{"path": "choice_string", "op": "remove", "old": "x", "new": null},
+			{"path": "choice_inner", "op": "add", "old": null, "new": {}},
+			{"path": "choice_inner.a", "op": "add", "old": null, "new": 1}
+		]`)
+		testDiff(`{"choiceInner": {"a": 1}}`, `{"choiceString": ""}`, `[
+			{"path": "choice_string", "op": "add", "old": null, "new": ""},
+			{"path": "choice_inner", "op": "remove", "old": {"a": 1}, "new": null}
+		]`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_diff(?, '.Unknown', '', '')", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "pb_message_diff: message type `.Unknown` not found in descriptor set")
+		RunTestThatExpression(t, `pb_message_patch(?, ?, '', '[{"path": "id", "op": "move"}]')`, descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_message_patch: unsupported op `move`")
+		RunTestThatExpression(t, `pb_message_patch(?, ?, '', '[{"path": "inner", "op": "add", "new": {"a": 1}}]')`, descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_message_patch: message value at `inner` must be an empty object")
+		RunTestThatExpression(t, `pb_message_patch(?, ?, '', '[{"path": "values[1]", "op": "add", "new": 1}]')`, descriptorSetJson, typeName).ToFailWithSignalException("45000", "index out of range for field `values`")
+		RunTestThatExpression(t, `pb_message_patch(?, ?, '', '[{"path": "values[0]", "op": "replace", "new": 1}]')`, descriptorSetJson, typeName).ToFailWithSignalException("45000", "index out of range for field `values`")
+	})
+
+	t.Run("null", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_diff(?, ?, NULL, '')", descriptorSetJson, typeName).IsNull()
+		RunTestThatExpression(t, "pb_message_patch(?, ?, '', NULL)", descriptorSetJson, typeName).IsNull()
+	})
+}
+
+func TestRandomizedMessageDiff(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 int32_field = 1;
+			|    string string_field = 2;
+			|    optional int64 optional_int64_field = 3;
+			|    Inner inner = 4;
+			|    repeated sint32 repeated_sint32_field = 5;
+			|    repeated Inner repeated_inner = 6;
+			|    map<string, int32> string_map = 7;
+			|    map<int64, Inner> inner_map = 8;
+			|    oneof choice {
+			|        string choice_string = 9;
+			|        Inner choice_inner = 10;
+			|        fixed32 choice_fixed32 = 11;
+			|    }
+			|    double double_field = 12;
+			|    bool bool_field = 13;
+			|    map<bool, bytes> bool_map = 14;
+			|    repeated Color colors = 15;
+			|    uint64 uint64_field = 16;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    string b = 2;
+			|    repeated float c = 3;
+			|    Leaf leaf = 4;
+			|}
+			|message Leaf {
+			|    uint64 value = 1;
+			|}
+			|enum Color {
+			|    COLOR_UNSPECIFIED = 0;
+			|    RED = 1;
+			|    GREEN = 2;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+	descriptor := p.GetMessageDescriptor(typeName)
+
+	seed := time.Now().UnixNano()
+	t.Logf("Using seed = %d.", seed)
+	rng := rand.New(rand.NewSource(seed))
+	config := &protorandom.Config{AllowNan: proto.Bool(false), AllowInf: proto.Bool(false)}
+
+	for i := 0; i < iterations; i++ {
+		oldMessage := protorandom.Message(rng, descriptor, config).Interface()
+		newMessage := protorandom.Message(rng, descriptor, config).Interface()
+
+		RunTestThatExpression(t, "pb_message_patch(?, ?, ?, pb_message_diff(?, ?, ?, ?))", descriptorSetJson, typeName, oldMessage, descriptorSetJson, typeName, oldMessage, newMessage).IsEqualOrCloseToProto(newMessage, morefloat.WithinMantissaThreshold(0, 2))
+		RunTestThatExpression(t, "pb_message_diff(?, ?, ?, ?)", descriptorSetJson, typeName, newMessage, newMessage).IsEqualToJsonString(`[]`)
+	}
+}
+
+func TestMessageDiffDepth(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Node {
+			|    Node child = 1;
+			|    int32 value = 2;
+			|    map<string, Node> children = 3;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	message, _ := buildNestedNode(100)
+	RunTestThatExpression(t, "pb_message_diff(?, '.Node', ?, ?)", descriptorSetJson, message, message).IsEqualToJsonString(`[]`)
+	message, _ = buildNestedNode(101)
+	RunTestThatExpression(t, "pb_message_diff(?, '.Node', ?, ?)", descriptorSetJson, message, message).ToFailWithMySQLError(45008, "45008", "pb_message_diff: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+
+	// The map entry counts as a message
+	RunTestThatExpression(t, "pb_message_diff(?, '.Node', _binary '', pb_message_set_by_path(?, '.Node', _binary '', ?, CAST('1' AS JSON)))", descriptorSetJson, descriptorSetJson, strings.Repeat("child.", 98)+`children["k"].value`).ToFailWithMySQLError(45008, "45008", "pb_message_diff: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+
+	// The target of a path is at most at depth 100
+	path := strings.Repeat("child.", 99) + "value"
+	RunTestThatExpression(t, "pb_message_get_json_by_path(?, '.Node', pb_message_patch(?, '.Node', _binary '', JSON_ARRAY(JSON_OBJECT('path', ?, 'op', 'add', 'new', 1))), ?)", descriptorSetJson, descriptorSetJson, path, path).IsEqualToJsonString(`1`)
+	RunTestThatExpression(t, "pb_message_patch(?, '.Node', _binary '', JSON_ARRAY(JSON_OBJECT('path', ?, 'op', 'add', 'new', 1)))", descriptorSetJson, "child."+path).ToFailWithMySQLError(45008, "45008", "pb_message_set_by_path: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+
+	// max_sp_recursion_depth is raised for the nested messages and put back afterwards, also on errors
+	RunTestThatExpression(t, "pb_message_diff(?, '.Node', _binary X'0A020A00', _binary X'0A00')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_diff(?, '.Unknown', _binary X'0A020A00', _binary X'0A00')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, `pb_message_patch(?, '.Node', _binary '', '[{"path": "child.child.value", "op": "add", "new": 1}]')`, descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, `pb_message_patch(?, '.Node', _binary '', '[{"path": "child.child.unknown", "op": "add", "new": 1}]')`, descriptorSetJson).KeepsRecursionDepth()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type explodedRow struct {
+	ParentId     int64
+	ElementIndex int
+	MapKey       sql.NullString
+	IntValue     sql.NullInt64
+	DoubleValue  sql.NullFloat64
+	BoolValue    sql.NullBool
+	StringValue  sql.NullString
+	MessageValue []byte
+	JsonValue    string
+}
+
+func TestMessageExplode(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    repeated int32 numbers = 1;
+			|    repeated string names = 2;
+			|    repeated Inner inners = 3;
+			|    map<string, int64> counts = 4;
+			|    Inner inner = 5;
+			|    repeated Color colors = 6;
+			|    repeated double doubles = 7;
+			|    map<int32, bool> flags = 8;
+			|    int32 id = 9;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    repeated int32 values = 2;
+			|}
+			|enum Color {
+			|    COLOR_UNSPECIFIED = 0;
+			|    RED = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+
+	// Temporary tables are per session, so every statement must run on the same connection.
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	g.Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		g.Expect(conn.Close()).To(Succeed())
+	}()
+
+	_, err = conn.ExecContext(ctx, "DROP TABLE IF EXISTS pb_message_explode_test")
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = conn.ExecContext(ctx, "CREATE TABLE pb_message_explode_test (id BIGINT PRIMARY KEY, data LONGBLOB)")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer func() {
+		_, err := conn.ExecContext(ctx, "DROP TABLE pb_message_explode_test")
+		g.Expect(err).NotTo(HaveOccurred())
+	}()
+
+	for id, input := range map[int64]string{
+		1: `{"numbers": [1, -2], "names": ["a"], "inners": [{"a": 1}, {}], "counts": {"x": "3"}, "inner": {"values": [5, 6]}, "colors": ["RED", "COLOR_UNSPECIFIED"], "doubles": [1.5], "flags": {"7": true}}`,
+		2: `{"numbers": [3], "counts": {"y": "-4"}}`,
+		3: `{"counts": {"z": "0"}}`,
+	} {
+		_, err = conn.ExecContext(ctx, "INSERT INTO pb_message_explode_test VALUES (?, ?)", id, p.JsonToProtobuf(typeName, input))
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	_, err = conn.ExecContext(ctx, "INSERT INTO pb_message_explode_test VALUES (4, NULL)")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	explode := func(path string) []explodedRow {
+		_, err := conn.ExecContext(ctx, "CALL pb_message_explode(?, ?, 'pb_message_explode_test', 'data', ?)", descriptorSetJson, typeName, path)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		rows, err := conn.QueryContext(ctx, "SELECT parent_id, element_index, map_key, int_value, double_value, bool_value, string_value, message_value, json_value FROM pb_exploded ORDER BY parent_id, element_index")
+		g.Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			g.Expect(rows.Close()).To(Succeed())
+		}()
+
+		var result []explodedRow
+		for rows.Next() {
+			var row explodedRow
+			g.Expect(rows.Scan(&row.ParentId, &row.ElementIndex, &row.MapKey, &row.IntValue, &row.DoubleValue, &row.BoolValue, &row.StringValue, &row.MessageValue, &row.JsonValue)).To(Succeed())
+			result = append(result, row)
+		}
+		g.Expect(rows.Err()).NotTo(HaveOccurred())
+		return result
+	}
+
+	t.Run("repeated scalar", func(t *testing.T) {
+		rows := explode("numbers")
+		g.Expect(rows).To(HaveLen(3))
+		g.Expect(rows[0]).To(Equal(explodedRow{ParentId: 1, ElementIndex: 0, IntValue: sql.NullInt64{Int64: 1, Valid: true}, JsonValue: "1"}))
+		g.Expect(rows[1]).To(Equal(explodedRow{ParentId: 1, ElementIndex: 1, IntValue: sql.NullInt64{Int64: -2, Valid: true}, JsonValue: "-2"}))
+		g.Expect(rows[2]).To(Equal(explodedRow{ParentId: 2, ElementIndex: 0, IntValue: sql.NullInt64{Int64: 3, Valid: true}, JsonValue: "3"}))
+
+		rows = explode("names")
+		g.Expect(rows).To(Equal([]explodedRow{{ParentId: 1, ElementIndex: 0, StringValue: sql.NullString{String: "a", Valid: true}, JsonValue: `"a"`}}))
+
+		rows = explode("doubles")
+		g.Expect(rows).To(Equal([]explodedRow{{ParentId: 1, ElementIndex: 0, DoubleValue: sql.NullFloat64{Float64: 1.5, Valid: true}, JsonValue: "1.5"}}))
+
+		rows = explode("colors")
+		g.Expect(rows).To(HaveLen(2))
+		g.Expect(rows[0].IntValue).To(Equal(sql.NullInt64{Int64: 1, Valid: true}))
+		g.Expect(rows[0].JsonValue).To(Equal(`"RED"`))
+		g.Expect(rows[1].IntValue).To(Equal(sql.NullInt64{Int64: 0, Valid: true}))
+	})
+
+	t.Run("repeated message", func(t *testing.T) {
+		rows := explode("inners")
+		g.Expect(rows).To(HaveLen(2))
+		g.Expect(rows[0].MessageValue).To(Equal(p.JsonToProtobuf(".Inner", `{"a": 1}`)))
+		g.Expect(rows[0].JsonValue).To(MatchJSON(`{"a": 1, "values": []}`))
+		g.Expect(rows[1].MessageValue).To(BeEmpty())
+	})
+
+	t.Run("map", func(t *testing.T) {
+		rows := explode("counts")
+		g.Expect(rows).To(HaveLen(3))
+		g.Expect(rows[0]).To(Equal(explodedRow{ParentId: 1, ElementIndex: 0, MapKey: sql.NullString{String: "x", Valid: true}, IntValue: sql.NullInt64{Int64: 3, Valid: true}, JsonValue: `"3"`}))
+		g.Expect(rows[1]).To(Equal(explodedRow{ParentId: 2, ElementIndex: 0, MapKey: sql.NullString{String: "y", Valid: true}, IntValue: sql.NullInt64{Int64: -4, Valid: true}, JsonValue: `"-4"`}))
+		g.Expect(rows[2]).To(Equal(explodedRow{ParentId: 3, ElementIndex: 0, MapKey: sql.NullString{String: "z", Valid: true}, IntValue: sql.NullInt64{Int64: 0, Valid: true}, JsonValue: `"0"`}))
+
+		rows = explode("flags")
+		g.Expect(rows).To(Equal([]explodedRow{{ParentId: 1, ElementIndex: 0, MapKey: sql.NullString{String: "7", Valid: true}, BoolValue: sql.NullBool{Bool: true, Valid: true}, JsonValue: "true"}}))
+	})
+
+	t.Run("nested path", func(t *testing.T) {
+		rows := explode("inner.values")
+		g.Expect(rows).To(HaveLen(2))
+		g.Expect(rows[1].IntValue).To(Equal(sql.NullInt64{Int64: 6, Valid: true}))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		expectError := func(path string, expectedMessage string) {
+			_, err := conn.ExecContext(ctx, "CALL pb_message_explode(?, ?, 'pb_message_explode_test', 'data', ?)", descriptorSetJson, typeName, path)
+			g.Expect(err).To(MatchError(ContainSubstring(expectedMessage)))
+		}
+		expectError("id", "pb_message_explode: path `id` does not end with a repeated or map field")
+		expectError("numbers[0]", "pb_message_explode: path `numbers[0]` does not end with a repeated or map field")
+		expectError("unknown", "pb_message_explode: field `unknown` not found in message type `.Test`")
+		expectError("inners.values", "pb_message_explode: repeated field `inners` requires an index in path `inners.values`")
+	})
+}
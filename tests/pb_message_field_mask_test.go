@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestMessageFieldMask(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 id = 1;
+			|    string display_name = 2;
+			|    Inner inner = 3;
+			|    repeated int32 values = 4;
+			|    repeated Inner inners = 5;
+			|    map<string, int32> counts = 6;
+			|    oneof choice {
+			|        string choice_string = 7;
+			|        Inner choice_inner = 8;
+			|    }
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    string b = 2;
+			|    Inner child = 3;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+	message := p.JsonToProtobuf(typeName, `{"id": 1, "displayName": "x", "inner": {"a": 2, "b": "y", "child": {"a": 3, "b": "z"}}, "values": [1, 2], "inners": [{"a": 4}], "counts": {"k": 5}, "choiceString": "c"}`)
+
+	t.Run("apply", func(t *testing.T) {
+		testApply := func(mask string, expectedJson string) {
+			RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, ?)", descriptorSetJson, typeName, message, mask).IsEqualToProto(p.JsonToDynamicMessage(typeName, expectedJson).Interface())
+		}
+		testApply(`""`, `{}`)
+		testApply(`"id"`, `{"id": 1}`)
+		testApply(`"id,displayName"`, `{"id": 1, "displayName": "x"}`)
+		testApply(`["id", "display_name"]`, `{"id": 1, "displayName": "x"}`)
+		testApply(`{"paths": ["values", "inners", "counts"]}`, `{"values": [1, 2], "inners": [{"a": 4}], "counts": {"k": 5}}`)
+		testApply(`"inner"`, `{"inner": {"a": 2, "b": "y", "child": {"a": 3, "b": "z"}}}`)
+		testApply(`"inner.a,inner.child.b"`, `{"inner": {"a": 2, "child": {"b": "z"}}}`)
+		testApply(`"inner.child,inner.child.b"`, `{"inner": {"child": {"a": 3, "b": "z"}}}`)
+		testApply(`"inner.child.b,inner.child"`, `{"inner": {"child": {"a": 3, "b": "z"}}}`)
+		testApply(`"choiceString,choiceInner"`, `{"choiceString": "c"}`)
+		// A selected message field is kept even if none of the selected sub-fields are set
+		testApply(`"inner.child.child"`, `{"inner": {"child": {}}}`)
+		// Fields not set in the message are not added
+		testApply(`"choiceInner.a"`, `{}`)
+	})
+
+	t.Run("apply with serialized FieldMask", func(t *testing.T) {
+		mask, err := proto.Marshal(&fieldmaskpb.FieldMask{Paths: []string{"id", "inner.b"}})
+		g.Expect(err).NotTo(HaveOccurred())
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, ?)", descriptorSetJson, typeName, message, mask).IsEqualToProto(p.JsonToDynamicMessage(typeName, `{"id": 1, "inner": {"b": "y"}}`).Interface())
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, _binary '')", descriptorSetJson, typeName, message).IsEqualToProto(p.JsonToDynamicMessage(typeName, `{}`).Interface())
+	})
+
+	t.Run("apply with JSON value", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, JSON_ARRAY('id'))", descriptorSetJson, typeName, message).IsEqualToProto(p.JsonToDynamicMessage(typeName, `{"id": 1}`).Interface())
+	})
+
+	t.Run("to json", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json_with_field_mask(?, ?, ?, ?)", descriptorSetJson, typeName, message, `"displayName,inner.child.a"`).IsEqualToJsonString(`{"displayName": "x", "inner": {"child": {"a": 3}}}`)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		target := p.JsonToProtobuf(typeName, `{"id": 1, "displayName": "x", "inner": {"a": 2, "b": "y"}, "values": [1], "inners": [{"a": 1}], "counts": {"k": 1, "l": 2}, "choiceString": "c"}`)
+		source := p.JsonToProtobuf(typeName, `{"id": 10, "inner": {"b": "w", "child": {"a": 5}}, "values": [2, 3], "inners": [{"b": "n"}], "counts": {"k": 3}, "choiceInner": {"a": 6}}`)
+		testMerge := func(mask string, expectedJson string) {
+			RunTestThatExpression(t, "pb_message_merge_with_field_mask(?, ?, ?, ?, ?)", descriptorSetJson, typeName, target, source, mask).IsEqualToProto(p.JsonToDynamicMessage(typeName, expectedJson).Interface())
+		}
+		testMerge(`""`, `{"id": 1, "displayName": "x", "inner": {"a": 2, "b": "y"}, "values": [1], "inners": [{"a": 1}], "counts": {"k": 1, "l": 2}, "choiceString": "c"}`)
+		// Scalar fields are replaced, and cleared if not set in source
+		testMerge(`"id,displayName"`, `{"id": 10, "inner": {"a": 2, "b": "y"}, "values": [1], "inners": [{"a": 1}], "counts": {"k": 1, "l": 2}, "choiceString": "c"}`)
+		// Repeated fields are appended, and maps are merged by key
+		testMerge(`"values,inners,counts"`, `{"id": 1, "displayName": "x", "inner": {"a": 2, "b": "y"}, "values": [1, 2, 3], "inners": [{"a": 1}, {"b": "n"}], "counts": {"k": 3, "l": 2}, "choiceString": "c"}`)
+		// Message fields at the end of a path are merged
+		testMerge(`"inner"`, `{"id": 1, "displayName": "x", "inner": {"a": 2, "b": "w", "child": {"a": 5}}, "values": [1], "inners": [{"a": 1}], "counts": {"k": 1, "l": 2}, "choiceString": "c"}`)
+		// Sub-paths replace only the selected sub-fields
+		testMerge(`"inner.a,inner.child.b"`, `{"id": 1, "displayName": "x", "inner": {"b": "y", "child": {}}, "values": [1], "inners": [{"a": 1}], "counts": {"k": 1, "l": 2}, "choiceString": "c"}`)
+		// Setting a oneof member clears the other members
+		testMerge(`"choiceInner"`, `{"id": 1, "displayName": "x", "inner": {"a": 2, "b": "y"}, "values": [1], "inners": [{"a": 1}], "counts": {"k": 1, "l": 2}, "choiceInner": {"a": 6}}`)
+		testMerge(`"choiceString"`, `{"id": 1, "displayName": "x", "inner": {"a": 2, "b": "y"}, "values": [1], "inners": [{"a": 1}], "counts": {"k": 1, "l": 2}}`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, ?)", descriptorSetJson, typeName, message, `"unknown"`).ToFailWithSignalException("45000", "pb_message_apply_field_mask: field `unknown` not found in message type `.Test`")
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, ?)", descriptorSetJson, typeName, message, `"inner.unknown"`).ToFailWithSignalException("45000", "pb_message_apply_field_mask: field `unknown` not found in message type `.Inner`")
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, ?)", descriptorSetJson, typeName, message, `"inners.a"`).ToFailWithSignalException("45000", "pb_message_apply_field_mask: field `inners` in path `inners.a` is not a singular message field")
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, ?)", descriptorSetJson, typeName, message, `"id.a"`).ToFailWithSignalException("45000", "pb_message_apply_field_mask: field `id` in path `id.a` is not a singular message field")
+		RunTestThatExpression(t, "pb_message_merge_with_field_mask(?, ?, ?, ?, ?)", descriptorSetJson, typeName, message, message, `123`).ToFailWithSignalException("45000", "pb_message_merge_with_field_mask: invalid field mask `123`")
+	})
+
+	t.Run("null", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, NULL, '\"id\"')", descriptorSetJson, typeName).IsNull()
+		RunTestThatExpression(t, "pb_message_apply_field_mask(?, ?, ?, NULL)", descriptorSetJson, typeName, message).IsNull()
+	})
+}
+
+func TestMessageFieldMaskDepth(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Node {
+			|    Node child = 1;
+			|    int32 value = 2;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	message, _ := buildNestedNode(100)
+
+	// A path has at most 99 fields
+	mask := `"` + strings.Repeat("child.", 98) + `child"`
+	RunTestThatExpression(t, "pb_message_apply_field_mask(?, '.Node', ?, ?)", descriptorSetJson, message, mask).IsEqualToBytes(message)
+	RunTestThatExpression(t, "pb_message_merge_with_field_mask(?, '.Node', _binary '', ?, ?)", descriptorSetJson, message, mask).IsEqualToBytes(message)
+
+	mask = `"` + strings.Repeat("child.", 99) + `child"`
+	RunTestThatExpression(t, "pb_message_apply_field_mask(?, '.Node', ?, ?)", descriptorSetJson, message, mask).ToFailWithMySQLError(45008, "45008", "pb_message_apply_field_mask: field mask path has more than 99 fields (PB_DEPTH_LIMIT_EXCEEDED)")
+	RunTestThatExpression(t, "pb_message_merge_with_field_mask(?, '.Node', _binary '', ?, ?)", descriptorSetJson, message, mask).ToFailWithMySQLError(45008, "45008", "pb_message_merge_with_field_mask: field mask path has more than 99 fields (PB_DEPTH_LIMIT_EXCEEDED)")
+
+	// max_sp_recursion_depth is raised for the nested messages and put back afterwards, also on errors
+	RunTestThatExpression(t, "pb_message_apply_field_mask(?, '.Node', _binary X'0A020A00', '\"child.child\"')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_apply_field_mask(?, '.Node', _binary X'0A020A00', '\"child.unknown\"')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_merge_with_field_mask(?, '.Node', _binary '', _binary X'0A020A00', '\"child.child\"')", descriptorSetJson).KeepsRecursionDepth()
+}
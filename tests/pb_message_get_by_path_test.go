@@ -0,0 +1,183 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	RunTestThatExpression(t, "_pb_util_parse_field_path('')").IsEqualToJsonString(`[]`)
+	RunTestThatExpression(t, "_pb_util_parse_field_path('order')").IsEqualToJsonString(`[{"f": "order"}]`)
+	RunTestThatExpression(t, "_pb_util_parse_field_path('order.items[2].price')").IsEqualToJsonString(`[{"f": "order"}, {"f": "items", "i": 2}, {"f": "price"}]`)
+	RunTestThatExpression(t, "_pb_util_parse_field_path('4.2[-1].1')").IsEqualToJsonString(`[{"f": "4"}, {"f": "2", "i": -1}, {"f": "1"}]`)
+	RunTestThatExpression(t, `_pb_util_parse_field_path('labels["env"]')`).IsEqualToJsonString(`[{"f": "labels", "k": "env"}]`)
+	RunTestThatExpression(t, `_pb_util_parse_field_path('labels[''a.b]'']')`).IsEqualToJsonString(`[{"f": "labels", "k": "a.b]"}]`)
+	RunTestThatExpression(t, `_pb_util_parse_field_path('labels["1"]')`).IsEqualToJsonString(`[{"f": "labels", "k": "1"}]`)
+	RunTestThatExpression(t, `_pb_util_parse_field_path('labels[env]')`).IsEqualToJsonString(`[{"f": "labels", "k": "env"}]`)
+
+	RunTestThatExpression(t, "_pb_util_parse_field_path('a..b')").ToFailWithSignalException("45000", "_pb_util_parse_field_path: empty field name")
+	RunTestThatExpression(t, "_pb_util_parse_field_path('a.')").ToFailWithSignalException("45000", "_pb_util_parse_field_path: unexpected character")
+	RunTestThatExpression(t, "_pb_util_parse_field_path('a[]')").ToFailWithSignalException("45000", "_pb_util_parse_field_path: invalid subscript")
+	RunTestThatExpression(t, "_pb_util_parse_field_path('a[0')").ToFailWithSignalException("45000", "_pb_util_parse_field_path: invalid subscript")
+	RunTestThatExpression(t, `_pb_util_parse_field_path('a["x')`).ToFailWithSignalException("45000", "_pb_util_parse_field_path: unterminated map key")
+	RunTestThatExpression(t, "_pb_util_parse_field_path('a[0]b')").ToFailWithSignalException("45000", "_pb_util_parse_field_path: unexpected character")
+	RunTestThatExpression(t, "_pb_util_parse_field_path('a-b')").ToFailWithSignalException("45000", "_pb_util_parse_field_path: unexpected character")
+}
+
+func TestMessageGetJsonByPath(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Order {
+			|    string id = 1;
+			|    repeated Item items = 2;
+			|    map<string, string> labels = 3;
+			|    map<int32, Item> items_by_id = 4;
+			|    Item primary_item = 5;
+			|    Status status = 6;
+			|}
+			|message Item {
+			|    string name = 1;
+			|    int64 price = 2;
+			|    repeated int32 quantities = 3;
+			|}
+			|message Test {
+			|    Order order = 1;
+			|}
+			|enum Status {
+			|    STATUS_UNSPECIFIED = 0;
+			|    STATUS_SHIPPED = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+	message := p.JsonToProtobuf(typeName, `{
+		"order": {
+			"id": "order-1",
+			"items": [
+				{"name": "apple", "price": "100", "quantities": [1, 2]},
+				{"name": "banana", "price": "200"},
+				{"name": "cherry", "price": "300", "quantities": [3]}
+			],
+			"labels": {"env": "prod", "team": "core"},
+			"itemsById": {"7": {"name": "grape", "price": "700"}},
+			"status": "STATUS_SHIPPED"
+		}
+	}`)
+
+	t.Run("whole message", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, '')", descriptorSetJson, typeName, message).IsEqualToJsonString(`{"order": {"id": "order-1", "items": [{"name": "apple", "price": "100", "quantities": [1, 2]}, {"name": "banana", "price": "200", "quantities": []}, {"name": "cherry", "price": "300", "quantities": [3]}], "labels": {"env": "prod", "team": "core"}, "itemsById": {"7": {"name": "grape", "price": "700", "quantities": []}}, "status": "STATUS_SHIPPED"}}`)
+	})
+
+	t.Run("singular fields", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.id')", descriptorSetJson, typeName, message).IsEqualToJsonString(`"order-1"`)
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.status')", descriptorSetJson, typeName, message).IsEqualToJsonString(`"STATUS_SHIPPED"`)
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.primary_item')", descriptorSetJson, typeName, message).IsNull()
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.primaryItem.price')", descriptorSetJson, typeName, message).IsEqualToJsonString(`"0"`)
+	})
+
+	t.Run("repeated fields", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.items[2].price')", descriptorSetJson, typeName, message).IsEqualToJsonString(`"300"`)
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.items[-3].name')", descriptorSetJson, typeName, message).IsEqualToJsonString(`"apple"`)
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.items[0].quantities[-1]')", descriptorSetJson, typeName, message).IsEqualToJsonString(`2`)
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.items[1]')", descriptorSetJson, typeName, message).IsEqualToJsonString(`{"name": "banana", "price": "200", "quantities": []}`)
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.items[3].name')", descriptorSetJson, typeName, message).IsNull()
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.items[-4]')", descriptorSetJson, typeName, message).IsNull()
+	})
+
+	t.Run("map fields", func(t *testing.T) {
+		RunTestThatExpression(t, `pb_message_get_json_by_path(?, ?, ?, 'order.labels["env"]')`, descriptorSetJson, typeName, message).IsEqualToJsonString(`"prod"`)
+		RunTestThatExpression(t, `pb_message_get_json_by_path(?, ?, ?, 'order.labels["missing"]')`, descriptorSetJson, typeName, message).IsNull()
+		RunTestThatExpression(t, `pb_message_get_json_by_path(?, ?, ?, 'order.items_by_id[7].name')`, descriptorSetJson, typeName, message).IsEqualToJsonString(`"grape"`)
+		RunTestThatExpression(t, `pb_message_get_json_by_path(?, ?, ?, 'order.itemsById["7"]')`, descriptorSetJson, typeName, message).IsEqualToJsonString(`{"name": "grape", "price": "700", "quantities": []}`)
+		RunTestThatExpression(t, `pb_message_get_json_by_path(?, ?, ?, 'order.itemsById[8].name')`, descriptorSetJson, typeName, message).IsNull()
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.unknown')", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_get_json_by_path: field `unknown` not found")
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.id[0]')", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_get_json_by_path: field `id` is not a repeated field")
+		RunTestThatExpression(t, `pb_message_get_json_by_path(?, ?, ?, 'order.items["x"]')`, descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_get_json_by_path: field `items` is not a map field")
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.items.name')", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_get_json_by_path: repeated field `items` requires an index")
+		RunTestThatExpression(t, "pb_message_get_json_by_path(?, ?, ?, 'order.id.length')", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_get_json_by_path: field `id` is not a message field")
+	})
+}
+
+func TestMessageGetByNumberPath(t *testing.T) {
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    Inner inner = 4;
+			|    map<string, string> labels = 5;
+			|    map<sint32, Inner> inners = 6;
+			|    map<bool, string> flags = 7;
+			|    map<sint64, string> offsets = 8;
+			|    map<uint64, string> ids = 9;
+			|}
+			|message Inner {
+			|    repeated Leaf leaves = 2;
+			|    repeated int32 numbers = 3;
+			|    double ratio = 7;
+			|}
+			|message Leaf {
+			|    string name = 1;
+			|    bool enabled = 2;
+			|}
+		`),
+	})
+
+	typeName := protoreflect.FullName(".Test")
+	message := p.JsonToProtobuf(typeName, `{
+		"inner": {
+			"leaves": [{"name": "a", "enabled": true}, {"name": "b"}],
+			"numbers": [10, -20, 30],
+			"ratio": 0.5
+		},
+		"labels": {"env": "prod", "true": "yes", "1": "one"},
+		"inners": {"-3": {"numbers": [42]}},
+		"flags": {"true": "on"},
+		"offsets": {"1": "plus one", "-1": "minus one"},
+		"ids": {"18446744073709551615": "max"}
+	}`)
+
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.2[0].1', 'string')", message).IsEqualToJsonString(`"a"`)
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.2[-1].1', 'string')", message).IsEqualToJsonString(`"b"`)
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.2[0].2', 'bool')", message).IsEqualToJsonString(`true`)
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.2[1].2', 'bool')", message).IsNull()
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.3[1]', 'int32')", message).IsEqualToJsonString(`-20`)
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.3[-1]', 'int32')", message).IsEqualToJsonString(`30`)
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.3[3]', 'int32')", message).IsNull()
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.7', 'double')", message).IsEqualToJsonString(`0.5`)
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.2[5].1', 'string')", message).IsNull()
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '9.1', 'string')", message).IsNull()
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.2[0]', 'message')", message).IsEqualToJsonString(`"CgFhEAE="`)
+
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '5["env"]', 'string')`, message).IsEqualToJsonString(`"prod"`)
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '5["dev"]', 'string')`, message).IsNull()
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '6[sint32:-3].3[0]', 'int32')`, message).IsEqualToJsonString(`42`)
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '5["true"]', 'string')`, message).IsEqualToJsonString(`"yes"`)
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '5["1"]', 'string')`, message).IsEqualToJsonString(`"one"`)
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '7[bool:true]', 'string')`, message).IsEqualToJsonString(`"on"`)
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '7[bool:false]', 'string')`, message).IsNull()
+
+	// Keys are decoded by the declared type only. sint64 -1 and int64 1 are both encoded as VARINT 1.
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '8[sint64:-1]', 'string')`, message).IsEqualToJsonString(`"minus one"`)
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '8[sint64:1]', 'string')`, message).IsEqualToJsonString(`"plus one"`)
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '8[sint64:2]', 'string')`, message).IsNull()
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '9[uint64:18446744073709551615]', 'string')`, message).IsEqualToJsonString(`"max"`)
+	RunTestThatExpression(t, `pb_message_get_by_number_path(?, '9[uint64:-1]', 'string')`, message).IsNull()
+
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, 'inner.1', 'string')", message).ToFailWithSignalException("45000", "pb_message_get_by_number_path: `inner` is not a field number")
+	RunTestThatExpression(t, "pb_message_get_by_number_path(?, '4.7', 'decimal')", message).ToFailWithSignalException("45000", "_pb_message_get_field_element_as_json: unsupported type name `decimal`")
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMessageGetField(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"proto2.proto": dedent.Pipe(`
+			|syntax = "proto2";
+			|message Proto2 {
+			|    optional int32 int32_field = 1 [default = 42];
+			|    optional int64 int64_field = 2 [default = -7];
+			|    optional string string_field = 3 [default = "hello"];
+			|    optional bytes bytes_field = 4 [default = "a\001\n"];
+			|    optional bool bool_field = 5 [default = true];
+			|    optional Color color = 6 [default = GREEN];
+			|    optional Color color_without_default = 7;
+			|    optional double double_field = 8 [default = inf];
+			|    optional uint32 uint32_field = 9;
+			|    optional Proto2 nested = 10;
+			|}
+			|enum Color {
+			|    RED = 1;
+			|    GREEN = 2;
+			|}
+		`),
+		"proto3.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Proto3 {
+			|    int32 int32_field = 1;
+			|    repeated int64 int64_list = 2;
+			|    map<string, int32> counts = 3;
+			|    Inner inner = 4;
+			|    optional string optional_string = 5;
+			|}
+			|message Inner {
+			|    string name = 1;
+			|    uint64 id = 2;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	t.Run("proto2 defaults", func(t *testing.T) {
+		typeName := protoreflect.FullName(".Proto2")
+		empty := p.JsonToProtobuf(typeName, `{}`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'int32_field')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`42`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'int64_field')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`"-7"`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'string_field')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`"hello"`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'bytes_field')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`"YQEK"`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'bool_field')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`true`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'color')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`"GREEN"`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'color_without_default')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`"RED"`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'double_field')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`"Infinity"`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'uint32_field')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`0`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'nested')", descriptorSetJson, typeName, empty).IsEqualToJsonString(`{}`)
+	})
+
+	t.Run("proto2 values", func(t *testing.T) {
+		typeName := protoreflect.FullName(".Proto2")
+		message := p.JsonToProtobuf(typeName, `{"int32Field": 1, "color": "RED", "nested": {"stringField": "x"}}`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'int32_field')", descriptorSetJson, typeName, message).IsEqualToJsonString(`1`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'int32Field')", descriptorSetJson, typeName, message).IsEqualToJsonString(`1`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'color')", descriptorSetJson, typeName, message).IsEqualToJsonString(`"RED"`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'nested')", descriptorSetJson, typeName, message).IsEqualToJsonString(`{"stringField": "x"}`)
+	})
+
+	t.Run("proto3", func(t *testing.T) {
+		typeName := protoreflect.FullName(".Proto3")
+		message := p.JsonToProtobuf(typeName, `{"int64List": ["1", "-2"], "counts": {"a": 1}, "inner": {"name": "n", "id": "3"}}`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'int32_field')", descriptorSetJson, typeName, message).IsEqualToJsonString(`0`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'int64List')", descriptorSetJson, typeName, message).IsEqualToJsonString(`["1", "-2"]`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'counts')", descriptorSetJson, typeName, message).IsEqualToJsonString(`{"a": 1}`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'inner')", descriptorSetJson, typeName, message).IsEqualToJsonString(`{"name": "n", "id": "3"}`)
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, ?, 'optional_string')", descriptorSetJson, typeName, message).IsEqualToJsonString(`""`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		typeName := protoreflect.FullName(".Proto3")
+		RunTestThatExpression(t, "pb_message_get_field(?, ?, _binary '', 'unknown')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_message_get_field: field `unknown` not found in message type `.Proto3`")
+		RunTestThatExpression(t, "pb_message_get_field(?, '.Unknown', _binary '', 'x')", descriptorSetJson).ToFailWithSignalException("45000", "pb_message_get_field: message type `.Unknown` not found")
+	})
+}
+
+func TestMessageSetField(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"proto2.proto": dedent.Pipe(`
+			|syntax = "proto2";
+			|message Proto2 {
+			|    repeated int32 unpacked = 1;
+			|    repeated int32 packed = 2 [packed = true];
+			|}
+		`),
+		"proto3.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Proto3 {
+			|    int32 int32_field = 1;
+			|    int64 int64_field = 2;
+			|    string string_field = 3;
+			|    bytes bytes_field = 4;
+			|    bool bool_field = 5;
+			|    Color color = 6;
+			|    double double_field = 7;
+			|    sint32 sint32_field = 8;
+			|    repeated int32 packed = 9;
+			|    repeated int32 unpacked = 10 [packed = false];
+			|    repeated Color colors = 11;
+			|    repeated string strings = 12;
+			|    map<string, int32> counts = 13;
+			|    map<int64, Color> colors_by_id = 14;
+			|    Proto3 nested = 15;
+			|    oneof choice {
+			|        string choice_string = 16;
+			|        int32 choice_int32 = 17;
+			|    }
+			|}
+			|enum Color {
+			|    COLOR_UNSPECIFIED = 0;
+			|    RED = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Proto3")
+	empty := p.JsonToProtobuf(typeName, `{}`)
+
+	test := func(fieldName string, jsonValue string, expectedJson string) {
+		expected := p.JsonToDynamicMessage(typeName, expectedJson).Interface()
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, ?, CAST(? AS JSON))", descriptorSetJson, typeName, empty, fieldName, jsonValue).IsEqualToProto(expected)
+	}
+
+	test("int32_field", `-5`, `{"int32Field": -5}`)
+	test("int64Field", `"-9223372036854775808"`, `{"int64Field": "-9223372036854775808"}`)
+	test("string_field", `"hello"`, `{"stringField": "hello"}`)
+	test("bytes_field", `"AAEC"`, `{"bytesField": "AAEC"}`)
+	test("bool_field", `true`, `{"boolField": true}`)
+	test("color", `"RED"`, `{"color": "RED"}`)
+	test("color", `1`, `{"color": "RED"}`)
+	test("double_field", `1.5`, `{"doubleField": 1.5}`)
+	test("sint32_field", `-3`, `{"sint32Field": -3}`)
+	test("packed", `[1, 2, 3]`, `{"packed": [1, 2, 3]}`)
+	test("colors", `["RED", 0]`, `{"colors": ["RED", "COLOR_UNSPECIFIED"]}`)
+	test("strings", `["a", "b"]`, `{"strings": ["a", "b"]}`)
+	test("counts", `{"a": 1, "b": 2}`, `{"counts": {"a": 1, "b": 2}}`)
+	test("colorsById", `{"-1": "RED"}`, `{"colorsById": {"-1": "RED"}}`)
+	test("nested", `"CAE="`, `{"nested": {"int32Field": 1}}`)
+
+	t.Run("replaces existing value", func(t *testing.T) {
+		message := p.JsonToProtobuf(typeName, `{"int32Field": 1, "packed": [1, 2], "counts": {"x": 1}}`)
+		expected := p.JsonToDynamicMessage(typeName, `{"int32Field": 2, "packed": [1, 2], "counts": {"x": 1}}`).Interface()
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'int32_field', CAST('2' AS JSON))", descriptorSetJson, typeName, message).IsEqualToProto(expected)
+		expected = p.JsonToDynamicMessage(typeName, `{"int32Field": 1, "packed": [3], "counts": {"x": 1}}`).Interface()
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'packed', CAST('[3]' AS JSON))", descriptorSetJson, typeName, message).IsEqualToProto(expected)
+		expected = p.JsonToDynamicMessage(typeName, `{"int32Field": 1, "packed": [1, 2], "counts": {"y": 2}}`).Interface()
+		RunTestThatExpression(t, `pb_message_set_field(?, ?, ?, 'counts', CAST('{"y": 2}' AS JSON))`, descriptorSetJson, typeName, message).IsEqualToProto(expected)
+	})
+
+	t.Run("null clears field", func(t *testing.T) {
+		message := p.JsonToProtobuf(typeName, `{"int32Field": 1, "stringField": "x"}`)
+		expected := p.JsonToDynamicMessage(typeName, `{"stringField": "x"}`).Interface()
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'int32_field', CAST('null' AS JSON))", descriptorSetJson, typeName, message).IsEqualToProto(expected)
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'int32_field', NULL)", descriptorSetJson, typeName, message).IsEqualToProto(expected)
+	})
+
+	t.Run("oneof", func(t *testing.T) {
+		message := p.JsonToProtobuf(typeName, `{"choiceString": "x"}`)
+		expected := p.JsonToDynamicMessage(typeName, `{"choiceInt32": 1}`).Interface()
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'choice_int32', CAST('1' AS JSON))", descriptorSetJson, typeName, message).IsEqualToProto(expected)
+	})
+
+	t.Run("packedness", func(t *testing.T) {
+		RunTestThatExpression(t, `JSON_EXTRACT(pb_message_to_wire_json(pb_message_set_field(?, ?, ?, 'packed', CAST('[1, 2]' AS JSON))), '$."9"[0].t')`, descriptorSetJson, typeName, empty).IsEqualToJsonString(`2`)
+		RunTestThatExpression(t, `JSON_EXTRACT(pb_message_to_wire_json(pb_message_set_field(?, ?, ?, 'unpacked', CAST('[1, 2]' AS JSON))), '$."10"[0].t')`, descriptorSetJson, typeName, empty).IsEqualToJsonString(`0`)
+		RunTestThatExpression(t, `JSON_EXTRACT(pb_message_to_wire_json(pb_message_set_field(?, '.Proto2', _binary '', 'unpacked', CAST('[1, 2]' AS JSON))), '$."1"[0].t')`, descriptorSetJson).IsEqualToJsonString(`0`)
+		RunTestThatExpression(t, `JSON_EXTRACT(pb_message_to_wire_json(pb_message_set_field(?, '.Proto2', _binary '', 'packed', CAST('[1, 2]' AS JSON))), '$."2"[0].t')`, descriptorSetJson).IsEqualToJsonString(`2`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'unknown', CAST('1' AS JSON))", descriptorSetJson, typeName, empty).ToFailWithSignalException("45000", "pb_message_set_field: field `unknown` not found")
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'packed', CAST('1' AS JSON))", descriptorSetJson, typeName, empty).ToFailWithSignalException("45000", "pb_message_set_field: expected a JSON array")
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'counts', CAST('[]' AS JSON))", descriptorSetJson, typeName, empty).ToFailWithSignalException("45000", "pb_message_set_field: expected a JSON object")
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'string_field', CAST('1' AS JSON))", descriptorSetJson, typeName, empty).ToFailWithSignalException("45000", "pb_message_set_field: expected a JSON string")
+		RunTestThatExpression(t, "pb_message_set_field(?, ?, ?, 'color', CAST('\"BLUE\"' AS JSON))", descriptorSetJson, typeName, empty).ToFailWithSignalException("45000", "_pb_enum_from_json: unknown value `BLUE`")
+	})
+
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMessageIndex(t *testing.T) {
+	// Fields are deliberately out of order so that the index entries have to be sorted
+	const message = "1A026162" + // 3: "ab"
+		"089601" + // 1: 150
+		"1501000000" + // 2: fixed32 1
+		"0805" + // 1: 5
+		"2203010203" + // 4: packed int32 [1, 2, 3]
+		"2A080700000000000000" + // 5: packed fixed64 [7]
+		"310000000000000440" // 6: double 2.5
+
+	index := fmt.Sprintf("pb_message_index(_binary X'%s')", message)
+
+	t.Run("format", func(t *testing.T) {
+		RunTestThatExpression(t, "HEX(pb_message_index(_binary X'0805'))").IsEqualToString("01" + "00000001" + "00000001" + "00" + "00000001" + "00000001" + "0805")
+		RunTestThatExpression(t, "HEX(pb_message_index(_binary X''))").IsEqualToString("0100000000")
+		RunTestThatExpression(t, fmt.Sprintf("HEX(pb_message_index_to_message(%s))", index)).IsEqualToString(message)
+	})
+
+	t.Run("singular fields", func(t *testing.T) {
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_int32_field(%s, 1, 0)", index)).IsEqualToInt(5)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_fixed32_field(%s, 2, 0)", index)).IsEqualToUint(1)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_string_field(%s, 3, '')", index)).IsEqualToString("ab")
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_double_field(%s, 6, 0)", index)).IsEqualToDouble(2.5)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_int32_field(%s, 7, 42)", index)).IsEqualToInt(42)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_has_string_field(%s, 3)", index)).IsTrue()
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_has_int32_field(%s, 7)", index)).IsFalse()
+	})
+
+	t.Run("repeated fields", func(t *testing.T) {
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_int32_field_count(%s, 1)", index)).IsEqualToInt(2)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_int32_field_element(%s, 1, 0)", index)).IsEqualToInt(150)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_int32_field_count(%s, 4)", index)).IsEqualToInt(3)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_int32_field_element(%s, 4, 2)", index)).IsEqualToInt(3)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_fixed64_field_count(%s, 5)", index)).IsEqualToInt(1)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_fixed64_field_element(%s, 5, 0)", index)).IsEqualToUint(7)
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_int32_field_as_json_array(%s, 1)", index)).IsEqualToJsonString("[150, 5]")
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_int32_field_as_json_array(%s, 4)", index)).IsEqualToJsonString("[1, 2, 3]")
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_string_field_as_json_array(%s, 3)", index)).IsEqualToJsonString(`["ab"]`)
+	})
+
+	t.Run("same values as message accessors", func(t *testing.T) {
+		for _, expression := range []string{
+			"get_repeated_int32_field_as_json_array(%s, 1)",
+			"get_repeated_int32_field_as_json_array(%s, 4)",
+			"get_repeated_fixed64_field_as_json_array(%s, 5)",
+			"get_repeated_double_field_as_json_array(%s, 6)",
+			"get_repeated_bytes_field_as_json_array(%s, 3)",
+		} {
+			expected := fmt.Sprintf("pb_message_"+expression, fmt.Sprintf("_binary X'%s'", message))
+			actual := fmt.Sprintf("pb_message_index_"+expression, index)
+			RunTestThatExpression(t, fmt.Sprintf("%s = %s", expected, actual)).IsTrue()
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_index(_binary X'08')").ToFailWithMySQLError(45001, "45001", "(PB_TRUNCATED_VARINT, offset 1, field 1)")
+		RunTestThatExpression(t, "pb_message_index_get_int32_field(_binary X'0805', 1, 0)").ToFailWithSignalException("45000", "invalid message index")
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_fixed32_field(%s, 1, 0)", index)).ToFailWithMySQLError(45005, "45005", "I32 value cannot be parsed from VARINT wire type. (PB_WIRE_TYPE_MISMATCH, offset 5, field 1)")
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_index_get_repeated_int32_field_element(%s, 1, 2)", index)).ToFailWithSignalException("45000", "repeated index out of range")
+	})
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/protorandom"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// haveNoDuplicateSingularFields matches serialized messages in which none of the singular fields occur more than once.
+func haveNoDuplicateSingularFields(descriptor protoreflect.MessageDescriptor) OmegaMatcher {
+	return WithTransform(func(b []byte) []protowire.Number {
+		counts := map[protowire.Number]int{}
+		for len(b) > 0 {
+			number, wireType, n := protowire.ConsumeTag(b)
+			if n < 0 {
+				return nil
+			}
+			b = b[n:]
+			n = protowire.ConsumeFieldValue(number, wireType, b)
+			if n < 0 {
+				return nil
+			}
+			b = b[n:]
+			counts[number]++
+		}
+		var duplicates []protowire.Number
+		for number, count := range counts {
+			field := descriptor.Fields().ByNumber(number)
+			if field != nil && field.Cardinality() != protoreflect.Repeated && count > 1 {
+				duplicates = append(duplicates, number)
+			}
+		}
+		return duplicates
+	}, BeEmpty())
+}
+
+func TestMessageMerge(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"proto3.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 int32_field = 1;
+			|    string string_field = 2;
+			|    optional int32 optional_int32_field = 3;
+			|    Inner inner = 4;
+			|    repeated int32 repeated_int32_field = 5;
+			|    repeated Inner repeated_inner = 6;
+			|    map<string, int32> string_map = 7;
+			|    map<int32, Inner> inner_map = 8;
+			|    oneof choice {
+			|        string choice_string = 9;
+			|        Inner choice_inner = 10;
+			|    }
+			|    double double_field = 11;
+			|    bytes bytes_field = 12;
+			|    map<bool, string> bool_map = 13;
+			|    EnumType enum_field = 14;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    string b = 2;
+			|    repeated int32 c = 3;
+			|}
+			|enum EnumType {
+			|    ENUM_TYPE_UNSPECIFIED = 0;
+			|    ENUM_TYPE_ONE = 1;
+			|}
+		`),
+		"proto2.proto": dedent.Pipe(`
+			|syntax = "proto2";
+			|message Proto2 {
+			|    optional int32 int32_field = 1;
+			|    optional string string_field = 2 [default = "x"];
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+	descriptor := p.GetMessageDescriptor(typeName)
+
+	testMerge := func(aJson string, bJson string, expectedJson string) {
+		a := p.JsonToProtobuf(typeName, aJson)
+		b := p.JsonToProtobuf(typeName, bJson)
+		RunTestThatExpression(t, "pb_message_merge(?, ?, ?, ?)", descriptorSetJson, typeName, a, b).IsEqualToProto(p.JsonToDynamicMessage(typeName, expectedJson).Interface())
+	}
+
+	t.Run("scalars", func(t *testing.T) {
+		testMerge(`{"int32Field": 1, "stringField": "a"}`, `{"int32Field": 2}`, `{"int32Field": 2, "stringField": "a"}`)
+		testMerge(`{"int32Field": 1}`, `{}`, `{"int32Field": 1}`)
+		testMerge(`{"optionalInt32Field": 1}`, `{"optionalInt32Field": 0}`, `{"optionalInt32Field": 0}`)
+	})
+
+	t.Run("zero values do not overwrite fields without presence", func(t *testing.T) {
+		a := p.JsonToProtobuf(typeName, `{"int32Field": 1, "stringField": "a"}`)
+		b := append(protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 0), protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), nil)...)
+		RunTestThatExpression(t, "pb_message_merge(?, ?, ?, ?)", descriptorSetJson, typeName, a, b).IsEqualToProto(p.JsonToDynamicMessage(typeName, `{"int32Field": 1, "stringField": "a"}`).Interface())
+	})
+
+	t.Run("proto2 scalars always overwrite", func(t *testing.T) {
+		a := p.JsonToProtobuf(".Proto2", `{"int32Field": 1, "stringField": "a"}`)
+		b := p.JsonToProtobuf(".Proto2", `{"int32Field": 0, "stringField": ""}`)
+		RunTestThatExpression(t, "pb_message_merge(?, '.Proto2', ?, ?)", descriptorSetJson, a, b).IsEqualToBytes(b)
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		testMerge(`{"inner": {"a": 1, "c": [1]}}`, `{"inner": {"b": "x", "c": [2]}}`, `{"inner": {"a": 1, "b": "x", "c": [1, 2]}}`)
+		testMerge(`{}`, `{"inner": {}}`, `{"inner": {}}`)
+	})
+
+	t.Run("repeated", func(t *testing.T) {
+		testMerge(`{"repeatedInt32Field": [1, 2], "repeatedInner": [{"a": 1}]}`, `{"repeatedInt32Field": [3], "repeatedInner": [{"a": 2}]}`, `{"repeatedInt32Field": [1, 2, 3], "repeatedInner": [{"a": 1}, {"a": 2}]}`)
+	})
+
+	t.Run("maps", func(t *testing.T) {
+		testMerge(`{"stringMap": {"a": 1, "b": 2}, "innerMap": {"1": {"a": 1}}}`, `{"stringMap": {"b": 3, "c": 4}, "innerMap": {"1": {"b": "x"}, "0": {}}}`, `{"stringMap": {"a": 1, "b": 3, "c": 4}, "innerMap": {"1": {"b": "x"}, "0": {}}}`)
+		testMerge(`{"boolMap": {"false": "a", "true": "b"}}`, `{"boolMap": {"false": "c"}}`, `{"boolMap": {"false": "c", "true": "b"}}`)
+	})
+
+	t.Run("oneofs", func(t *testing.T) {
+		testMerge(`{"choiceString": "a"}`, `{"choiceInner": {"a": 1}}`, `{"choiceInner": {"a": 1}}`)
+		testMerge(`{"choiceInner": {"a": 1}}`, `{"choiceString": "a"}`, `{"choiceString": "a"}`)
+		testMerge(`{"choiceInner": {"a": 1}}`, `{"choiceInner": {"b": "x"}}`, `{"choiceInner": {"a": 1, "b": "x"}}`)
+		testMerge(`{"choiceString": "a"}`, `{"int32Field": 1}`, `{"choiceString": "a", "int32Field": 1}`)
+	})
+
+	t.Run("duplicates are collapsed", func(t *testing.T) {
+		a := p.JsonToProtobuf(typeName, `{"int32Field": 1, "inner": {"a": 1}, "stringMap": {"k": 1}}`)
+		b := p.JsonToProtobuf(typeName, `{"int32Field": 2, "inner": {"b": "x"}, "stringMap": {"k": 2}}`)
+		concatenated := append(append([]byte{}, a...), b...)
+		RunTestThatExpression(t, "pb_message_merge(?, ?, ?, '')", descriptorSetJson, typeName, concatenated).IsEqualToProto(p.JsonToDynamicMessage(typeName, `{"int32Field": 2, "inner": {"a": 1, "b": "x"}, "stringMap": {"k": 2}}`).Interface())
+		assertThatExpressionTo[[]byte](t, haveNoDuplicateSingularFields(descriptor), "pb_message_merge(?, ?, ?, '')", descriptorSetJson, typeName, concatenated)
+		RunTestThatExpression(t, "pb_message_get_repeated_message_field_count(pb_message_merge(?, ?, ?, ''), 7)", descriptorSetJson, typeName, concatenated).IsEqualToInt(1)
+	})
+
+	t.Run("unknown fields are kept", func(t *testing.T) {
+		a := protowire.AppendVarint(protowire.AppendTag(nil, 100, protowire.VarintType), 1)
+		b := protowire.AppendVarint(protowire.AppendTag(nil, 100, protowire.VarintType), 2)
+		RunTestThatExpression(t, "pb_message_merge(?, ?, ?, ?)", descriptorSetJson, typeName, a, b).IsEqualToBytes(append(append([]byte{}, a...), b...))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_merge(?, '.Unknown', '', '')", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "pb_message_merge: message type `.Unknown` not found in descriptor set")
+		RunTestThatExpression(t, "pb_message_merge(?, ?, NULL, '')", descriptorSetJson, typeName).IsNull()
+	})
+}
+
+func TestRandomizedMessageMerge(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 int32_field = 1;
+			|    string string_field = 2;
+			|    optional int64 optional_int64_field = 3;
+			|    Inner inner = 4;
+			|    repeated sint32 repeated_sint32_field = 5;
+			|    repeated Inner repeated_inner = 6;
+			|    map<string, int32> string_map = 7;
+			|    map<int64, Inner> inner_map = 8;
+			|    oneof choice {
+			|        string choice_string = 9;
+			|        Inner choice_inner = 10;
+			|        fixed32 choice_fixed32 = 11;
+			|    }
+			|    double double_field = 12;
+			|    bool bool_field = 13;
+			|    map<bool, bytes> bool_map = 14;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    string b = 2;
+			|    repeated float c = 3;
+			|    Leaf leaf = 4;
+			|}
+			|message Leaf {
+			|    uint64 value = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+	descriptor := p.GetMessageDescriptor(typeName)
+
+	seed := time.Now().UnixNano()
+	t.Logf("Using seed = %d.", seed)
+	rng := rand.New(rand.NewSource(seed))
+	config := &protorandom.Config{AllowNan: proto.Bool(false)}
+
+	for i := 0; i < iterations; i++ {
+		a := protorandom.Message(rng, descriptor, config).Interface()
+		b := protorandom.Message(rng, descriptor, config).Interface()
+		expected := proto.Clone(a)
+		proto.Merge(expected, b)
+
+		RunTestThatExpression(t, "pb_message_merge(?, ?, ?, ?)", descriptorSetJson, typeName, a, b).IsEqualToProto(expected)
+		assertThatExpressionTo[[]byte](t, haveNoDuplicateSingularFields(descriptor), "pb_message_merge(?, ?, ?, ?)", descriptorSetJson, typeName, a, b)
+	}
+}
+
+func TestMessageMergeDepth(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Node {
+			|    Node child = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	message, _ := buildNestedNode(100)
+	RunTestThatExpression(t, "pb_message_merge(?, '.Node', ?, _binary '')", descriptorSetJson, message).IsEqualToBytes(message)
+	message, _ = buildNestedNode(101)
+	RunTestThatExpression(t, "pb_message_merge(?, '.Node', ?, _binary '')", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "pb_message_merge: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+	RunTestThatExpression(t, "pb_message_merge(?, '.Node', _binary '', ?)", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "pb_message_merge: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+
+	// max_sp_recursion_depth is raised for the nested messages and put back afterwards, also on errors
+	RunTestThatExpression(t, "pb_message_merge(?, '.Node', _binary X'0A020A00', _binary X'0A00')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_merge(?, '.Unknown', _binary X'0A020A00', _binary X'0A00')", descriptorSetJson).KeepsRecursionDepth()
+}
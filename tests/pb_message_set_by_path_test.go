@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMessageSetByPath(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Config {
+			|    string name = 1;
+			|    Retry retry = 2;
+			|    repeated Endpoint endpoints = 3;
+			|    map<string, Endpoint> endpoints_by_name = 4;
+			|    map<string, string> labels = 5;
+			|    repeated int32 ports = 6;
+			|}
+			|message Retry {
+			|    Backoff backoff = 1;
+			|    int32 attempts = 2;
+			|}
+			|message Backoff {
+			|    int64 max_ms = 1;
+			|    int64 min_ms = 2;
+			|}
+			|message Endpoint {
+			|    string host = 1;
+			|    repeated string tags = 2;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Config")
+	message := p.JsonToProtobuf(typeName, `{
+		"name": "svc",
+		"retry": {"attempts": 3, "backoff": {"minMs": "10"}},
+		"endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}],
+		"endpointsByName": {"primary": {"host": "p"}},
+		"labels": {"env": "prod"},
+		"ports": [80, 443]
+	}`)
+
+	testSet := func(path string, jsonValue string, expectedJson string) {
+		expected := p.JsonToDynamicMessage(typeName, expectedJson).Interface()
+		RunTestThatExpression(t, "pb_message_set_by_path(?, ?, ?, ?, CAST(? AS JSON))", descriptorSetJson, typeName, message, path, jsonValue).IsEqualToProto(expected)
+	}
+
+	testClear := func(path string, expectedJson string) {
+		expected := p.JsonToDynamicMessage(typeName, expectedJson).Interface()
+		RunTestThatExpression(t, "pb_message_clear_by_path(?, ?, ?, ?)", descriptorSetJson, typeName, message, path).IsEqualToProto(expected)
+	}
+
+	t.Run("set nested field", func(t *testing.T) {
+		testSet("retry.backoff.max_ms", `"5000"`, `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10", "maxMs": "5000"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}, "ports": [80, 443]}`)
+		testSet("retry.attempts", `5`, `{"name": "svc", "retry": {"attempts": 5, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}, "ports": [80, 443]}`)
+	})
+
+	t.Run("enclosing fields are rewritten in place", func(t *testing.T) {
+		// retry (field 2) stays the second field on the wire
+		RunTestThatExpression(t, `JSON_EXTRACT(pb_message_to_wire_json(pb_message_set_by_path(?, ?, ?, 'retry.backoff.max_ms', CAST('1' AS JSON))), '$."2"[0].i')`, descriptorSetJson, typeName, message).IsEqualToJsonString(`1`)
+		RunTestThatExpression(t, `JSON_EXTRACT(pb_message_to_wire_json(pb_message_set_by_path(?, ?, ?, 'endpoints[0].host', CAST('"c"' AS JSON))), '$."3"[0].i')`, descriptorSetJson, typeName, message).IsEqualToJsonString(`2`)
+	})
+
+	t.Run("create missing intermediate messages", func(t *testing.T) {
+		expected := p.JsonToDynamicMessage(typeName, `{"retry": {"backoff": {"maxMs": "100"}}}`).Interface()
+		RunTestThatExpression(t, "pb_message_set_by_path(?, ?, _binary '', 'retry.backoff.maxMs', CAST('100' AS JSON))", descriptorSetJson, typeName).IsEqualToProto(expected)
+		expected = p.JsonToDynamicMessage(typeName, `{"endpointsByName": {"new": {"tags": ["t"]}}}`).Interface()
+		RunTestThatExpression(t, `pb_message_set_by_path(?, ?, _binary '', 'endpoints_by_name["new"].tags', CAST('["t"]' AS JSON))`, descriptorSetJson, typeName).IsEqualToProto(expected)
+	})
+
+	t.Run("repeated indexes", func(t *testing.T) {
+		testSet("endpoints[1].host", `"c"`, `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "c", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}, "ports": [80, 443]}`)
+		testSet("endpoints[-1].tags[0]", `"z"`, `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["z", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}, "ports": [80, 443]}`)
+		testSet("ports[-1]", `8443`, `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}, "ports": [80, 8443]}`)
+		RunTestThatExpression(t, "pb_message_set_by_path(?, ?, ?, 'endpoints[2].host', CAST('\"c\"' AS JSON))", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_set_by_path: index out of range for field `endpoints`")
+	})
+
+	t.Run("map keys", func(t *testing.T) {
+		testSet(`labels["env"]`, `"dev"`, `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "dev"}, "ports": [80, 443]}`)
+		testSet(`labels["team"]`, `"core"`, `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod", "team": "core"}, "ports": [80, 443]}`)
+		testSet(`endpointsByName["primary"].host`, `"q"`, `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "q"}}, "labels": {"env": "prod"}, "ports": [80, 443]}`)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		testClear("retry.backoff", `{"name": "svc", "retry": {"attempts": 3}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}, "ports": [80, 443]}`)
+		testClear("endpoints[0]", `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}, "ports": [80, 443]}`)
+		testClear("endpoints[1].tags[-1]", `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}, "ports": [80, 443]}`)
+		testClear(`labels["env"]`, `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "ports": [80, 443]}`)
+		testClear("ports", `{"name": "svc", "retry": {"attempts": 3, "backoff": {"minMs": "10"}}, "endpoints": [{"host": "a"}, {"host": "b", "tags": ["x", "y"]}], "endpointsByName": {"primary": {"host": "p"}}, "labels": {"env": "prod"}}`)
+
+		// Clearing something that does not exist is a no-op and does not create intermediate messages
+		RunTestThatExpression(t, "pb_message_clear_by_path(?, ?, _binary '', 'retry.backoff.max_ms')", descriptorSetJson, typeName).IsEqualToBytes([]byte{})
+		RunTestThatExpression(t, "pb_message_clear_by_path(?, ?, ?, 'endpoints[5].host')", descriptorSetJson, typeName, message).IsEqualToBytes(message)
+		RunTestThatExpression(t, `pb_message_clear_by_path(?, ?, ?, 'labels["missing"]')`, descriptorSetJson, typeName, message).IsEqualToBytes(message)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_set_by_path(?, ?, ?, '', CAST('1' AS JSON))", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_set_by_path: path must not be empty")
+		RunTestThatExpression(t, "pb_message_set_by_path(?, ?, ?, 'retry.unknown', CAST('1' AS JSON))", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_set_by_path: field `unknown` not found in message type `.Retry`")
+		RunTestThatExpression(t, "pb_message_set_by_path(?, ?, ?, 'endpoints.host', CAST('1' AS JSON))", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_set_by_path: repeated field `endpoints` requires an index")
+		RunTestThatExpression(t, "pb_message_set_by_path(?, ?, ?, 'name.x', CAST('1' AS JSON))", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_set_by_path: field `name` is not a message field")
+		RunTestThatExpression(t, "pb_message_clear_by_path(?, ?, ?, 'name[0]')", descriptorSetJson, typeName, message).ToFailWithSignalException("45000", "pb_message_clear_by_path: field `name` is not a repeated field")
+	})
+}
+
+func TestMessageSetByPathDepth(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Node {
+			|    Node child = 1;
+			|    int32 value = 2;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The field is in a message at depth 100
+	path := strings.Repeat("child.", 99) + "value"
+	RunTestThatExpression(t, "pb_message_get_json_by_path(?, '.Node', pb_message_set_by_path(?, '.Node', _binary '', ?, CAST('1' AS JSON)), ?)", descriptorSetJson, descriptorSetJson, path, path).IsEqualToJsonString(`1`)
+	RunTestThatExpression(t, "pb_message_set_by_path(?, '.Node', _binary '', ?, CAST('1' AS JSON))", descriptorSetJson, "child."+path).ToFailWithMySQLError(45008, "45008", "pb_message_set_by_path: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+	message, _ := buildNestedNode(101)
+	RunTestThatExpression(t, "pb_message_clear_by_path(?, '.Node', ?, ?)", descriptorSetJson, message, "child."+path).ToFailWithMySQLError(45008, "45008", "pb_message_clear_by_path: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+
+	// max_sp_recursion_depth is raised for the nested messages and put back afterwards, also on errors
+	RunTestThatExpression(t, "pb_message_set_by_path(?, '.Node', _binary '', 'child.child.value', CAST('1' AS JSON))", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_set_by_path(?, '.Node', _binary '', 'child.child.unknown', CAST('1' AS JSON))", descriptorSetJson).KeepsRecursionDepth()
+}
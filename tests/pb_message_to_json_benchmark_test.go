@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// These benchmarks convert a wide message with increasing field counts and report ns/field, so that the conversion
+// cost of _pb_message_to_json can be compared before and after a change. No results are recorded in the repository.
+//
+//	make benchmark BENCH=BenchmarkMessageToJson
+//
+// For a per-routine breakdown, profile the benchmark with performance_schema after the functions are loaded:
+//
+//	make reload start-profiling
+//	go test ./tests -run '^$' -bench BenchmarkMessageToJson -args -database ...
+//	make stop-profiling  # or make flamegraph
+
+var benchmarkFieldCounts = []int{10, 100, 1000}
+
+// buildWideMessage returns a descriptor set JSON for a proto3 message `.Wide` with n int32 fields, numbered 1 to n,
+// together with a message in which every field is set.
+func buildWideMessage(b *testing.B, n int) (string, []byte) {
+	var fields []*descriptorpb.FieldDescriptorProto
+	var message []byte
+	for i := 1; i <= n; i++ {
+		fields = append(fields, &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(fmt.Sprintf("field_%d", i)),
+			Number:   proto.Int32(int32(i)),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+			JsonName: proto.String(fmt.Sprintf("field%d", i)),
+		})
+		message = protowire.AppendTag(message, protowire.Number(i), protowire.VarintType)
+		message = protowire.AppendVarint(message, uint64(i))
+	}
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:        proto.String("wide.proto"),
+			Syntax:      proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Wide"), Field: fields}},
+		}},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return descriptorSetJson, message
+}
+
+func BenchmarkMessageToJsonWideMessage(b *testing.B) {
+	for _, n := range benchmarkFieldCounts {
+		descriptorSetJson, message := buildWideMessage(b, n)
+		b.Run(fmt.Sprintf("fields=%d", n), func(b *testing.B) {
+			var result any
+			for i := 0; i < b.N; i++ {
+				if err := db.QueryRow("SELECT pb_message_to_json(?, '.Wide', ?)", descriptorSetJson, message).Scan(&result); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/float64(n), "ns/field")
+		})
+	}
+}
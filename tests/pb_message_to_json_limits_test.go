@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// buildNestedNode returns a `.Node` message in which `child` is nested depth-1 times, so that the top-level message
+// is at depth 1 and the innermost one at the given depth, together with its expected JSON.
+func buildNestedNode(depth int) ([]byte, string) {
+	var message []byte
+	for i := 1; i < depth; i++ {
+		message = protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), message)
+	}
+	return message, strings.Repeat(`{"child": `, depth-1) + "{}" + strings.Repeat("}", depth-1)
+}
+
+func TestMessageToJsonLimits(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Node {
+			|    Node child = 1;
+			|    repeated int32 values = 2;
+			|    map<string, Node> children = 3;
+			|    oneof choice {
+			|        Node a = 4;
+			|        Node b = 5;
+			|    }
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	t.Run("depth", func(t *testing.T) {
+		// 100 is also the deepest JSON document that MySQL can store
+		message, expected := buildNestedNode(100)
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', ?, NULL)", descriptorSetJson, message).IsEqualToJsonString(expected)
+
+		message, _ = buildNestedNode(101)
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Node', ?)", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "_pb_message_to_json: message nesting depth exceeds MaxDepth (100) (PB_DEPTH_LIMIT_EXCEEDED)")
+
+		message, expected = buildNestedNode(5)
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', ?, '{\"MaxDepth\": 5}')", descriptorSetJson, message).IsEqualToJsonString(expected)
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', ?, '{\"MaxDepth\": 4}')", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "message nesting depth exceeds MaxDepth (4) (PB_DEPTH_LIMIT_EXCEEDED)")
+	})
+
+	t.Run("output size", func(t *testing.T) {
+		message, expected := buildNestedNode(10)
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', ?, JSON_OBJECT('MaxOutputSize', LENGTH(pb_message_to_json_with_options(?, '.Node', ?, NULL))))", descriptorSetJson, message, descriptorSetJson, message).IsEqualToJsonString(expected)
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', ?, JSON_OBJECT('MaxOutputSize', LENGTH(pb_message_to_json_with_options(?, '.Node', ?, NULL)) - 1))", descriptorSetJson, message, descriptorSetJson, message).ToFailWithMySQLError(45009, "45009", "(PB_OUTPUT_SIZE_LIMIT_EXCEEDED)")
+	})
+
+	t.Run("elements", func(t *testing.T) {
+		const message = "12020102" + // values: [1, 2]
+			"1A08" + "0A0178" + "1203120103" // children: {"x": {values: [3]}}
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', _binary X'"+message+"', '{\"MaxElements\": 4}')", descriptorSetJson).IsEqualToJsonString(`{"values": [1, 2], "children": {"x": {"values": [3]}}}`)
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', _binary X'"+message+"', '{\"MaxElements\": 3}')", descriptorSetJson).ToFailWithMySQLError(45010, "45010", "(PB_ELEMENT_LIMIT_EXCEEDED)")
+	})
+
+	t.Run("oneof", func(t *testing.T) {
+		// a = {child: {}} followed by b = {}, so only b is emitted
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', _binary X'22020A002A00', NULL)", descriptorSetJson).IsEqualToJsonString(`{"b": {}}`)
+	})
+
+	t.Run("errors in nested messages", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Node', _binary X'0A030A0110')", descriptorSetJson).ToFailWithMySQLError(45001, "45001", "(PB_TRUNCATED_VARINT, offset 1, field 1.1.2)")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', _binary X'', '{\"MaxDepth\": \"10\"}')", descriptorSetJson).ToFailWithSignalException("45000", "pb_message_to_json_with_options: option `MaxDepth` must be a non-negative integer")
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, '.Node', _binary X'', '{\"MaxElements\": -1}')", descriptorSetJson).ToFailWithSignalException("45000", "pb_message_to_json_with_options: option `MaxElements` must be a non-negative integer")
+	})
+}
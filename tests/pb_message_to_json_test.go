@@ -116,11 +116,20 @@ func TestMessageToJsonSingularFields(t *testing.T) {
 	t.Run("float", func(t *testing.T) {
 		testMessageToJson(t, "float float_field = 1;", `{"floatField": 0}`)
 		testMessageToJson(t, "float float_field = 1;", `{"floatField": 3.5}`)
+		testMessageToJson(t, "float float_field = 1;", `{"floatField": 0.1}`)
+		testMessageToJson(t, "float float_field = 1;", `{"floatField": 1e-7}`)
+		testMessageToJson(t, "float float_field = 1;", `{"floatField": 3.4028235e+38}`)
+		testMessageToJson(t, "float float_field = 1;", `{"floatField": "NaN"}`)
+		testMessageToJson(t, "float float_field = 1;", `{"floatField": "-Infinity"}`)
 	})
 
 	t.Run("double", func(t *testing.T) {
 		testMessageToJson(t, "double double_field = 1;", `{"doubleField": 0}`)
 		testMessageToJson(t, "double double_field = 1;", `{"doubleField": 3.141592653589793}`)
+		testMessageToJson(t, "double double_field = 1;", `{"doubleField": 0.1}`)
+		testMessageToJson(t, "double double_field = 1;", `{"doubleField": 5e-324}`)
+		testMessageToJson(t, "double double_field = 1;", `{"doubleField": "NaN"}`)
+		testMessageToJson(t, "double double_field = 1;", `{"doubleField": "Infinity"}`)
 	})
 
 	t.Run("sint32", func(t *testing.T) {
@@ -218,12 +227,14 @@ func TestMessageToJsonRepeatedFields(t *testing.T) {
 		testMessageToJson(t, "repeated float repeated_float_field = 1;", `{"repeatedFloatField": []}`)
 		testMessageToJson(t, "repeated float repeated_float_field = 1;", `{"repeatedFloatField": [0]}`)
 		testMessageToJson(t, "repeated float repeated_float_field = 1;", `{"repeatedFloatField": [3.5, 0]}`)
+		testMessageToJson(t, "repeated float repeated_float_field = 1;", `{"repeatedFloatField": [0.1, "NaN", "Infinity", "-Infinity"]}`)
 	})
 
 	t.Run("repeated double", func(t *testing.T) {
 		testMessageToJson(t, "repeated double repeated_double_field = 1;", `{"repeatedDoubleField": []}`)
 		testMessageToJson(t, "repeated double repeated_double_field = 1;", `{"repeatedDoubleField": [0]}`)
 		testMessageToJson(t, "repeated double repeated_double_field = 1;", `{"repeatedDoubleField": [3.141592653589793, 0]}`)
+		testMessageToJson(t, "repeated double repeated_double_field = 1;", `{"repeatedDoubleField": [0.1, "NaN", "Infinity", "-Infinity"]}`)
 	})
 
 	t.Run("repeated sint32", func(t *testing.T) {
@@ -520,6 +531,49 @@ func TestMessageToJsonOneof(t *testing.T) {
 	})
 }
 
+func TestMessageToJsonWireOrder(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 a = 1;
+			|    repeated int32 b = 2;
+			|    oneof kind { int32 x = 3; string y = 4; }
+			|    map<string, int32> m = 5;
+			|    map<string, Test> n = 6;
+			|}
+		`),
+	})
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	test := func(t *testing.T, message string, expectedJson string) {
+		RunTestThatExpression(t, fmt.Sprintf("pb_message_to_json(?, '.Test', _binary X'%s')", message), descriptorSetJson).IsEqualToJsonString(expectedJson)
+	}
+
+	t.Run("fields out of order", func(t *testing.T) {
+		test(t, "1001"+"0805"+"1002", `{"a": 5, "b": [1, 2]}`)
+	})
+
+	t.Run("last oneof field wins", func(t *testing.T) {
+		test(t, "220161"+"1807", `{"x": 7}`)
+		test(t, "1807"+"220161", `{"y": "a"}`)
+	})
+
+	t.Run("last map entry wins", func(t *testing.T) {
+		test(t, "2A050A016B1001"+"2A050A016B1002", `{"m": {"k": 2}}`)
+		test(t, "32070A016B12020801"+"32070A016B12020802", `{"n": {"k": {"a": 2}}}`)
+		test(t, "32070A016B12020802"+"32070A016B12020801", `{"n": {"k": {"a": 1}}}`)
+		test(t, "320B0A016B1206320412020801"+"32070A016B12020802", `{"n": {"k": {"a": 2}}}`)
+	})
+
+	t.Run("unknown fields are skipped", func(t *testing.T) {
+		test(t, "4801"+"0805", `{"a": 5}`)
+	})
+}
+
 func TestMessageToJsonWellKnownTypes(t *testing.T) {
 	t.Run("Timestamp", func(t *testing.T) {
 		testMessageToJson(t, "google.protobuf.Timestamp timestamp_field = 1;", `{"timestampField": "1970-01-01T00:00:00Z"}`)
@@ -547,6 +601,7 @@ func TestMessageToJsonWellKnownTypes(t *testing.T) {
 		testMessageToJson(t, "google.protobuf.Value value_field = 1;", `{"valueField": {}}`)
 		testMessageToJson(t, "google.protobuf.Value value_field = 1;", `{"valueField": "string"}`)
 		testMessageToJson(t, "google.protobuf.Value value_field = 1;", `{"valueField": 123}`)
+		testMessageToJson(t, "google.protobuf.Value value_field = 1;", `{"valueField": -1.5}`)
 		testMessageToJson(t, "google.protobuf.Value value_field = 1;", `{"valueField": true}`)
 	})
 
@@ -559,12 +614,15 @@ func TestMessageToJsonWellKnownTypes(t *testing.T) {
 		testMessageToJson(t, "google.protobuf.DoubleValue double_value_field = 1;", `{}`)
 		testMessageToJson(t, "google.protobuf.DoubleValue double_value_field = 1;", `{"doubleValueField": 0}`)
 		testMessageToJson(t, "google.protobuf.DoubleValue double_value_field = 1;", `{"doubleValueField": 3.141592653589793}`)
+		testMessageToJson(t, "google.protobuf.DoubleValue double_value_field = 1;", `{"doubleValueField": "NaN"}`)
 	})
 
 	t.Run("FloatValue", func(t *testing.T) {
 		testMessageToJson(t, "google.protobuf.FloatValue float_value_field = 1;", `{}`)
 		testMessageToJson(t, "google.protobuf.FloatValue float_value_field = 1;", `{"floatValueField": 0}`)
 		testMessageToJson(t, "google.protobuf.FloatValue float_value_field = 1;", `{"floatValueField": 3.5}`)
+		testMessageToJson(t, "google.protobuf.FloatValue float_value_field = 1;", `{"floatValueField": 0.1}`)
+		testMessageToJson(t, "google.protobuf.FloatValue float_value_field = 1;", `{"floatValueField": "Infinity"}`)
 	})
 
 	t.Run("Int64Value", func(t *testing.T) {
@@ -637,3 +695,47 @@ func TestMessageToJsonNullInput(t *testing.T) {
 
 	RunTestThatExpression(t, "pb_message_to_json(?, ?, ?)", descriptorSetJson, typeName, nil).IsNull()
 }
+
+// MatchJSON would hide differences in number notation, so the text printed by MySQL is compared as is.
+func TestMessageToJsonFloatingPointText(t *testing.T) {
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|import "google/protobuf/struct.proto";
+			|message Test {
+			|    optional double d = 1;
+			|    optional float f = 2;
+			|    google.protobuf.Value v = 3;
+			|}
+		`),
+	})
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	NewWithT(t).Expect(err).NotTo(HaveOccurred())
+
+	toJsonText := func(message string) string {
+		return "CAST(pb_message_to_json(?, '.Test', _binary X'" + message + "') AS CHAR)"
+	}
+
+	t.Run("double", func(t *testing.T) {
+		RunTestThatExpression(t, toJsonText("090000000000000840"), descriptorSetJson).IsEqualToString(`{"d": 3}`)
+		RunTestThatExpression(t, toJsonText("099A9999999999B93F"), descriptorSetJson).IsEqualToString(`{"d": 0.1}`)
+		RunTestThatExpression(t, toJsonText("090000000000000080"), descriptorSetJson).IsEqualToString(`{"d": -0.0}`)
+		RunTestThatExpression(t, toJsonText("09003D9160E458E143"), descriptorSetJson).IsEqualToString(`{"d": 10000000000000000000}`)
+		RunTestThatExpression(t, toJsonText("09408CB5781DAF1544"), descriptorSetJson).IsEqualToString(`{"d": 1e20}`)
+		RunTestThatExpression(t, toJsonText("09000000000000F87F"), descriptorSetJson).IsEqualToString(`{"d": "NaN"}`)
+		RunTestThatExpression(t, toJsonText("09000000000000F0FF"), descriptorSetJson).IsEqualToString(`{"d": "-Infinity"}`)
+	})
+
+	t.Run("float", func(t *testing.T) {
+		RunTestThatExpression(t, toJsonText("15CDCCCC3D"), descriptorSetJson).IsEqualToString(`{"f": 0.1}`)
+		RunTestThatExpression(t, toJsonText("1500004040"), descriptorSetJson).IsEqualToString(`{"f": 3}`)
+		RunTestThatExpression(t, toJsonText("1500000080"), descriptorSetJson).IsEqualToString(`{"f": -0.0}`)
+		RunTestThatExpression(t, toJsonText("150000807F"), descriptorSetJson).IsEqualToString(`{"f": "Infinity"}`)
+	})
+
+	t.Run("Value", func(t *testing.T) {
+		RunTestThatExpression(t, toJsonText("1A0911000000000000F83F"), descriptorSetJson).IsEqualToString(`{"v": 1.5}`)
+		RunTestThatExpression(t, toJsonText("1A09110000000000000840"), descriptorSetJson).IsEqualToString(`{"v": 3}`)
+		RunTestThatExpression(t, toJsonText("1A0911000000000000F87F"), descriptorSetJson).ToFailWithMySQLError(45011, "45011", "_pb_wire_json_decode_wkt_value_as_json: number_value must be finite (PB_NON_FINITE_VALUE")
+	})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/protorandom"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMessageToJsonWithOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 int32_field = 1;
+			|    int64 int64_field = 2;
+			|    bytes bytes_field = 3;
+			|    repeated bytes repeated_bytes_field = 4;
+			|    map<string, bytes> bytes_map = 5;
+			|    EnumType enum_field = 6;
+			|    Inner inner = 7;
+			|    optional int32 optional_int32_field = 8;
+			|    repeated uint64 repeated_uint64_field = 9;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|}
+			|enum EnumType {
+			|    ENUM_TYPE_UNSPECIFIED = 0;
+			|    ENUM_TYPE_ONE = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+
+	testOptions := func(input string, options string, expectedJson string) {
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, ?, ?, ?)", descriptorSetJson, typeName, p.JsonToProtobuf(typeName, input), options).IsEqualToJsonString(expectedJson)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		testOptions(`{"int32Field": 1}`, `{}`, `{"int32Field": 1}`)
+		testOptions(`{}`, `{}`, `{}`)
+		testOptions(`{}`, `{"EmitDefaultValues": true}`, `{"int32Field": 0, "int64Field": "0", "bytesField": "", "repeatedBytesField": [], "bytesMap": {}, "enumField": "ENUM_TYPE_UNSPECIFIED", "repeatedUint64Field": []}`)
+		testOptions(`{}`, `{"EmitUnpopulated": true}`, `{"int32Field": 0, "int64Field": "0", "bytesField": "", "repeatedBytesField": [], "bytesMap": {}, "enumField": "ENUM_TYPE_UNSPECIFIED", "inner": null, "repeatedUint64Field": []}`)
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, ?, ?, NULL)", descriptorSetJson, typeName, p.JsonToProtobuf(typeName, `{"int32Field": 1}`)).IsEqualToJsonString(`{"int32Field": 1}`)
+	})
+
+	t.Run("UseProtoNames and UseEnumNumbers", func(t *testing.T) {
+		testOptions(`{"int32Field": 1, "enumField": "ENUM_TYPE_ONE", "inner": {"a": 1}}`, `{"UseProtoNames": true, "UseEnumNumbers": true}`, `{"int32_field": 1, "enum_field": 1, "inner": {"a": 1}}`)
+	})
+
+	t.Run("Int64AsNumber", func(t *testing.T) {
+		testOptions(`{"int64Field": "-5", "repeatedUint64Field": ["18446744073709551615"]}`, `{"Int64AsNumber": true}`, `{"int64Field": -5, "repeatedUint64Field": [18446744073709551615]}`)
+	})
+
+	t.Run("BytesEncoding", func(t *testing.T) {
+		input := `{"bytesField": "+/8=", "repeatedBytesField": ["+/8=", ""], "bytesMap": {"k": "+/8="}}`
+		testOptions(input, `{"BytesEncoding": "base64"}`, input)
+		testOptions(input, `{"BytesEncoding": "base64url"}`, `{"bytesField": "-_8=", "repeatedBytesField": ["-_8=", ""], "bytesMap": {"k": "-_8="}}`)
+		testOptions(input, `{"BytesEncoding": "hex"}`, `{"bytesField": "fbff", "repeatedBytesField": ["fbff", ""], "bytesMap": {"k": "fbff"}}`)
+	})
+
+	t.Run("EmitUnknownFields", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, ?, _binary X'a00601', '{\"EmitUnknownFields\": true}')", descriptorSetJson, typeName).IsEqualToJsonString(`{"@unknown": {"100": [{"i": 0, "n": 100, "t": 0, "v": 1}]}}`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, ?, '', '{\"UseJsonNames\": true}')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_message_to_json_with_options: unknown option `UseJsonNames`")
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, ?, '', '{\"UseProtoNames\": 1}')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_message_to_json_with_options: option `UseProtoNames` must be a boolean")
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, ?, '', '{\"BytesEncoding\": \"base32\"}')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_message_to_json_with_options: unsupported BytesEncoding \"base32\"")
+		RunTestThatExpression(t, "pb_message_to_json_with_options(?, ?, '', '[]')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_message_to_json_with_options: options must be a JSON object")
+	})
+}
+
+func TestRandomizedMessageToJsonWithOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"proto3.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    int32 int32_field = 1;
+			|    string string_field = 2;
+			|    optional int64 optional_int64_field = 3;
+			|    Inner inner = 4;
+			|    repeated sint32 repeated_sint32_field = 5;
+			|    repeated Inner repeated_inner = 6;
+			|    map<string, int32> string_map = 7;
+			|    map<int64, Inner> inner_map = 8;
+			|    oneof choice {
+			|        string choice_string = 9;
+			|        Inner choice_inner = 10;
+			|        fixed32 choice_fixed32 = 11;
+			|    }
+			|    double double_field = 12;
+			|    bool bool_field = 13;
+			|    map<bool, bytes> bool_map = 14;
+			|    repeated Color colors = 15;
+			|    uint64 uint64_field = 16;
+			|    Color color = 17;
+			|    bytes bytes_field = 18;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    string b = 2;
+			|    repeated float c = 3;
+			|}
+			|enum Color {
+			|    COLOR_UNSPECIFIED = 0;
+			|    RED = 1;
+			|    GREEN = 2;
+			|}
+		`),
+		"proto2.proto": dedent.Pipe(`
+			|syntax = "proto2";
+			|message Proto2 {
+			|    optional int32 int32_field = 1;
+			|    optional string string_field = 2;
+			|    optional Proto2Inner inner = 3;
+			|    repeated int64 repeated_int64_field = 4;
+			|    optional Proto2Enum enum_field = 5;
+			|}
+			|message Proto2Inner {
+			|    optional int32 a = 1;
+			|}
+			|enum Proto2Enum {
+			|    PROTO2_ENUM_ONE = 1;
+			|    PROTO2_ENUM_TWO = 2;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	seed := time.Now().UnixNano()
+	t.Logf("Using seed = %d.", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for _, typeName := range []protoreflect.FullName{".Test", ".Proto2"} {
+		descriptor := p.GetMessageDescriptor(typeName)
+		for i := 0; i < iterations; i++ {
+			message := protorandom.Message(rng, descriptor, nil).Interface()
+
+			for combination := 0; combination < 16; combination++ {
+				marshalOptions := protojson.MarshalOptions{
+					UseProtoNames:     combination&1 != 0,
+					EmitUnpopulated:   combination&2 != 0,
+					EmitDefaultValues: combination&4 != 0,
+					UseEnumNumbers:    combination&8 != 0,
+				}
+				options := fmt.Sprintf(`{"UseProtoNames": %t, "EmitUnpopulated": %t, "EmitDefaultValues": %t, "UseEnumNumbers": %t}`,
+					marshalOptions.UseProtoNames, marshalOptions.EmitUnpopulated, marshalOptions.EmitDefaultValues, marshalOptions.UseEnumNumbers)
+
+				expectedJson, err := marshalOptions.Marshal(message)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				RunTestThatExpression(t, "pb_message_to_json_with_options(?, ?, ?, ?)", descriptorSetJson, typeName, message, options).IsEqualToProtojsonString(string(expectedJson))
+			}
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/gomega/gproto"
+	"github.com/eiiches/mysql-protobuf-functions/internal/morefloat"
+	"github.com/eiiches/mysql-protobuf-functions/internal/protorandom"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func newTextTestSupport(t *testing.T, fieldDefinition string) (*testutils.ProtoTestSupport, string) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": fmt.Sprintf(dedent.Pipe(`
+			|syntax = "proto3";
+			|message Test {
+			|    %s
+			|}
+			|message MessageType {
+			|    int32 value = 1;
+			|}
+			|enum EnumType {
+			|    ENUM_TYPE_UNSPECIFIED = 0;
+			|    ENUM_TYPE_ONE = 1;
+			|}
+		`), fieldDefinition),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	return p, descriptorSetJson
+}
+
+// parseTextAs returns a matcher that parses the actual text with prototext and compares the result with expected.
+func parseTextAs(expected proto.Message) OmegaMatcher {
+	return WithTransform(func(text string) proto.Message {
+		actual := expected.ProtoReflect().New().Interface()
+		if err := prototext.Unmarshal([]byte(text), actual); err != nil {
+			return nil
+		}
+		return actual
+	}, gproto.EqualProto(expected).WithFloatEqualFn(morefloat.WithinMantissaThreshold(0, 2)))
+}
+
+func testMessageToText(t *testing.T, fieldDefinition string, input string, expectedText string, expectedSingleLineText string) {
+	g := NewWithT(t)
+
+	p, descriptorSetJson := newTextTestSupport(t, fieldDefinition)
+
+	typeName := protoreflect.FullName(".Test")
+	dynamicMessage := p.JsonToDynamicMessage(typeName, input)
+	serializedBinary := p.JsonToProtobuf(typeName, input)
+
+	parsed := dynamicMessage.New().Interface()
+	g.Expect(prototext.Unmarshal([]byte(expectedText), parsed)).To(Succeed())
+	g.Expect(proto.Equal(parsed, dynamicMessage.Interface())).To(BeTrue(), "Test case is invalid: expectedText should be parsed by prototext.Unmarshal into input.")
+
+	RunTestThatExpression(t, "pb_message_to_text(?, ?, ?)", descriptorSetJson, typeName, serializedBinary).IsEqualToString(expectedText)
+	RunTestThatExpression(t, "pb_message_to_single_line_text(?, ?, ?)", descriptorSetJson, typeName, serializedBinary).IsEqualToString(expectedSingleLineText)
+	RunTestThatExpression(t, "pb_text_to_message(?, ?, ?)", descriptorSetJson, typeName, expectedText).IsEqualToProto(dynamicMessage.Interface())
+	RunTestThatExpression(t, "pb_text_to_message(?, ?, ?)", descriptorSetJson, typeName, expectedSingleLineText).IsEqualToProto(dynamicMessage.Interface())
+}
+
+func TestMessageToText(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		testMessageToText(t, "int32 int32_field = 1;", `{}`, "", "")
+	})
+
+	t.Run("scalars", func(t *testing.T) {
+		testMessageToText(t, "int32 int32_field = 1; int64 int64_field = 2; uint64 uint64_field = 3; sint32 sint32_field = 4; bool bool_field = 5;",
+			`{"int32Field": -1, "int64Field": "-9223372036854775808", "uint64Field": "18446744073709551615", "sint32Field": -2, "boolField": true}`,
+			"int32_field: -1\nint64_field: -9223372036854775808\nuint64_field: 18446744073709551615\nsint32_field: -2\nbool_field: true\n",
+			"int32_field:-1 int64_field:-9223372036854775808 uint64_field:18446744073709551615 sint32_field:-2 bool_field:true")
+		testMessageToText(t, "fixed32 fixed32_field = 1; sfixed64 sfixed64_field = 2;",
+			`{"fixed32Field": 4294967295, "sfixed64Field": "-1"}`,
+			"fixed32_field: 4294967295\nsfixed64_field: -1\n",
+			"fixed32_field:4294967295 sfixed64_field:-1")
+	})
+
+	t.Run("default values are omitted", func(t *testing.T) {
+		testMessageToText(t, "int32 int32_field = 1; string string_field = 2; EnumType enum_field = 3; bool bool_field = 4;", `{}`, "", "")
+		testMessageToText(t, "optional int32 int32_field = 1; optional string string_field = 2;",
+			`{"int32Field": 0, "stringField": ""}`,
+			"int32_field: 0\nstring_field: \"\"\n",
+			`int32_field:0 string_field:""`)
+	})
+
+	t.Run("floating point", func(t *testing.T) {
+		testMessageToText(t, "double double_field = 1; float float_field = 2;",
+			`{"doubleField": 1.5, "floatField": -0.25}`,
+			"double_field: 1.5\nfloat_field: -0.25\n",
+			"double_field:1.5 float_field:-0.25")
+		testMessageToText(t, "repeated double double_field = 1; repeated float float_field = 2;",
+			`{"doubleField": ["Infinity", "-Infinity", "NaN"], "floatField": ["NaN", "Infinity"]}`,
+			"double_field: inf\ndouble_field: -inf\ndouble_field: nan\nfloat_field: nan\nfloat_field: inf\n",
+			"double_field:inf double_field:-inf double_field:nan float_field:nan float_field:inf")
+	})
+
+	t.Run("strings and bytes", func(t *testing.T) {
+		testMessageToText(t, "string string_field = 1;",
+			`{"stringField": "a\"b\\c\nd\te\u0001\u007f\u0085あ🎉'"}`,
+			"string_field: \"a\\\"b\\\\c\\nd\\te\\x01\\x7f\\u0085あ🎉'\"\n",
+			`string_field:"a\"b\\c\nd\te\x01\x7f\u0085あ🎉'"`)
+		testMessageToText(t, "bytes bytes_field = 1;",
+			`{"bytesField": "AP+AwqLjgYI="}`, // 00 ff 80 c2a2 e38182
+			"bytes_field: \"\\x00\\xff\\x80¢あ\"\n",
+			`bytes_field:"\x00\xff\x80¢あ"`)
+	})
+
+	t.Run("enums", func(t *testing.T) {
+		testMessageToText(t, "EnumType enum_field = 1; repeated EnumType repeated_enum_field = 2;",
+			`{"enumField": "ENUM_TYPE_ONE", "repeatedEnumField": ["ENUM_TYPE_UNSPECIFIED", 5]}`,
+			"enum_field: ENUM_TYPE_ONE\nrepeated_enum_field: ENUM_TYPE_UNSPECIFIED\nrepeated_enum_field: 5\n",
+			"enum_field:ENUM_TYPE_ONE repeated_enum_field:ENUM_TYPE_UNSPECIFIED repeated_enum_field:5")
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		testMessageToText(t, "MessageType message_field = 1; repeated MessageType repeated_message_field = 2; int32 after = 3;",
+			`{"messageField": {}, "repeatedMessageField": [{"value": 1}, {"value": 2}], "after": 3}`,
+			"message_field: {}\nrepeated_message_field: {\n  value: 1\n}\nrepeated_message_field: {\n  value: 2\n}\nafter: 3\n",
+			"message_field:{} repeated_message_field:{value:1} repeated_message_field:{value:2} after:3")
+		testMessageToText(t, "Test child = 1; int32 value = 2;",
+			`{"child": {"child": {"value": 1}}}`,
+			"child: {\n  child: {\n    value: 1\n  }\n}\n",
+			"child:{child:{value:1}}")
+	})
+
+	t.Run("occurrences of a singular message field are merged", func(t *testing.T) {
+		_, descriptorSetJson := newTextTestSupport(t, "Test child = 1; int32 value = 2; int32 other = 3;")
+		child1 := protowire.AppendVarint(protowire.AppendTag(nil, 2, protowire.VarintType), 1)
+		child2 := protowire.AppendVarint(protowire.AppendTag(nil, 3, protowire.VarintType), 2)
+		message := protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), child1)
+		message = protowire.AppendBytes(protowire.AppendTag(message, 1, protowire.BytesType), child2)
+		RunTestThatExpression(t, "pb_message_to_single_line_text(?, '.Test', ?)", descriptorSetJson, message).IsEqualToString("child:{value:1 other:2}")
+	})
+
+	t.Run("repeated", func(t *testing.T) {
+		testMessageToText(t, "repeated int32 int32_field = 1; repeated string string_field = 2;",
+			`{"int32Field": [1, 2, 3], "stringField": ["a", "b"]}`,
+			"int32_field: 1\nint32_field: 2\nint32_field: 3\nstring_field: \"a\"\nstring_field: \"b\"\n",
+			`int32_field:1 int32_field:2 int32_field:3 string_field:"a" string_field:"b"`)
+	})
+
+	t.Run("maps", func(t *testing.T) {
+		testMessageToText(t, "map<string, int32> map_field = 1;",
+			`{"mapField": {"a": 1}}`,
+			"map_field: {\n  key: \"a\"\n  value: 1\n}\n",
+			`map_field:{key:"a" value:1}`)
+		testMessageToText(t, "map<int32, MessageType> map_field = 1;",
+			`{"mapField": {"0": {}}}`,
+			"map_field: {\n  key: 0\n  value: {}\n}\n",
+			"map_field:{key:0 value:{}}")
+	})
+
+	t.Run("oneof", func(t *testing.T) {
+		testMessageToText(t, "int32 before = 1; oneof choice { string a = 2; int32 b = 3; } int32 after = 4;",
+			`{"before": 1, "b": 0, "after": 4}`,
+			"before: 1\nb: 0\nafter: 4\n",
+			"before:1 b:0 after:4")
+	})
+}
+
+func TestTextToMessage(t *testing.T) {
+	p, descriptorSetJson := newTextTestSupport(t, dedent.Pipe(`
+		|int32 int32_field = 1;
+		|uint64 uint64_field = 2;
+		|string string_field = 3;
+		|bytes bytes_field = 4;
+		|EnumType enum_field = 5;
+		|bool bool_field = 6;
+		|double double_field = 7;
+		|float float_field = 8;
+		|MessageType message_field = 9;
+		|repeated int32 repeated_int32_field = 10;
+		|repeated MessageType repeated_message_field = 11;
+		|map<string, int32> map_field = 12;
+	`))
+	typeName := protoreflect.FullName(".Test")
+
+	testParse := func(text string, expectedJson string) {
+		expected := p.JsonToDynamicMessage(typeName, expectedJson).Interface()
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, ?)", descriptorSetJson, typeName, text).IsEqualToProto(expected)
+	}
+
+	t.Run("syntax", func(t *testing.T) {
+		testParse("", `{}`)
+		testParse("  # comment only\n", `{}`)
+		testParse("int32_field: 1 # trailing comment\nstring_field: 'single'", `{"int32Field": 1, "stringField": "single"}`)
+		testParse("int32_field: 1, string_field: \"a\"; bool_field: true", `{"int32Field": 1, "stringField": "a", "boolField": true}`)
+		testParse("message_field { value: 1 }", `{"messageField": {"value": 1}}`)
+		testParse("message_field: < value: 1 >", `{"messageField": {"value": 1}}`)
+		testParse("repeated_int32_field: [1, 2] repeated_int32_field: 3", `{"repeatedInt32Field": [1, 2, 3]}`)
+		testParse("repeated_int32_field: []", `{}`)
+		testParse("repeated_message_field: [{value: 1}, {value: 2}]", `{"repeatedMessageField": [{"value": 1}, {"value": 2}]}`)
+		testParse(`map_field {key: "a" value: 1} map_field {key: "b" value: 2}`, `{"mapField": {"a": 1, "b": 2}}`)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		testParse("int32_field: 0x1F", `{"int32Field": 31}`)
+		testParse("int32_field: -0x1F", `{"int32Field": -31}`)
+		testParse("int32_field: 017", `{"int32Field": 15}`)
+		testParse("int32_field: - 5", `{"int32Field": -5}`)
+		testParse("uint64_field: 18446744073709551615", `{"uint64Field": "18446744073709551615"}`)
+		testParse(`string_field: "a" 'b' "c"`, `{"stringField": "abc"}`)
+		testParse(`string_field: "\a\b\f\n\r\t\v\\\'\"\?\101\x42é\U0001F389🎉"`, `{"stringField": "\u0007\b\f\n\r\t\u000b\\'\"?ABé🎉🎉"}`)
+		testParse(`bytes_field: "\000\377\x80"`, `{"bytesField": "AP+A"}`)
+		// Escape letters are case-sensitive
+		testParse(`string_field: "\N\A\X41"`, `{"stringField": "NAX41"}`)
+		testParse("enum_field: ENUM_TYPE_ONE", `{"enumField": "ENUM_TYPE_ONE"}`)
+		testParse("enum_field: 1", `{"enumField": "ENUM_TYPE_ONE"}`)
+		testParse("bool_field: t", `{"boolField": true}`)
+		testParse("bool_field: True", `{"boolField": true}`)
+		testParse("bool_field: 1", `{"boolField": true}`)
+		testParse("double_field: 1.5e3", `{"doubleField": 1500}`)
+		testParse("double_field: -inf", `{"doubleField": "-Infinity"}`)
+		testParse("double_field: Infinity", `{"doubleField": "Infinity"}`)
+		testParse("double_field: nan", `{"doubleField": "NaN"}`)
+		testParse("float_field: 2.5f", `{"floatField": 2.5}`)
+		testParse("float_field: -inf", `{"floatField": "-Infinity"}`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'unknown_field: 1')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: field `unknown_field` not found in message type `.Test`")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'int32_field 1')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: expected `:` after field `int32_field` at position 13")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'int32_field: \"1\"')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: invalid value `1` for field `int32_field` at position 14")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'int32_field: 1.5')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: invalid value `1.5` for field `int32_field`")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'bool_field: yes')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: invalid value `yes` for field `bool_field`")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'message_field { value: 1')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: unexpected end of input, expected `}`")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'string_field: \"abc')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: unterminated string at position 15")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'int32_field: 1 }')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: unexpected token `}` at position 16")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'int32_field: @')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: unexpected character `@` at position 14")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, '[ext.field]: 1')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "pb_text_to_message: extension and Any expansion syntax is not supported")
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, 'enum_field: ENUM_TYPE_TWO')", descriptorSetJson, typeName).ToFailWithSignalException("45000", "unknown value `ENUM_TYPE_TWO` for enum `.EnumType`")
+	})
+
+	t.Run("null input", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_text_to_message(?, ?, NULL)", descriptorSetJson, typeName).IsNull()
+		RunTestThatExpression(t, "pb_message_to_text(?, ?, NULL)", descriptorSetJson, typeName).IsNull()
+	})
+}
+
+func TestRandomizedMessageToText(t *testing.T) {
+	p, descriptorSetJson := newTextTestSupport(t, dedent.Pipe(`
+		|double double_field = 1;
+		|float float_field = 2;
+		|int64 int64_field = 3;
+		|uint64 uint64_field = 4;
+		|int32 int32_field = 5;
+		|fixed64 fixed64_field = 6;
+		|fixed32 fixed32_field = 7;
+		|bool bool_field = 8;
+		|string string_field = 9;
+		|MessageType message_field = 11;
+		|bytes bytes_field = 12;
+		|uint32 uint32_field = 13;
+		|EnumType enum_field = 14;
+		|sfixed32 sfixed32_field = 15;
+		|sfixed64 sfixed64_field = 16;
+		|sint32 sint32_field = 17;
+		|sint64 sint64_field = 18;
+		|optional int32 optional_int32_field = 19;
+		|repeated double repeated_double_field = 21;
+		|repeated float repeated_float_field = 22;
+		|repeated int64 repeated_int64_field = 23;
+		|repeated bool repeated_bool_field = 28;
+		|repeated string repeated_string_field = 29;
+		|repeated MessageType repeated_message_field = 31;
+		|repeated bytes repeated_bytes_field = 32;
+		|repeated EnumType repeated_enum_field = 34;
+		|repeated sint64 repeated_sint64_field = 38 [packed = false];
+		|map<string, int64> map_field = 40;
+		|map<bool, MessageType> message_map_field = 41;
+		|oneof choice {
+		|    string oneof_string_field = 50;
+		|    MessageType oneof_message_field = 51;
+		|}
+	`))
+	typeName := protoreflect.FullName(".Test")
+	descriptor := p.GetMessageDescriptor(typeName)
+
+	seed := time.Now().UnixNano()
+	t.Logf("Using seed = %d.", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for i := 0; i < iterations; i++ {
+		input := protorandom.Message(rng, descriptor, nil).Interface()
+		serializedBinary, err := proto.Marshal(input)
+		NewWithT(t).Expect(err).NotTo(HaveOccurred())
+
+		// The output of pb_message_to_text must be readable by prototext.Unmarshal
+		for _, function := range []string{"pb_message_to_text", "pb_message_to_single_line_text"} {
+			expression := fmt.Sprintf("%s(?, ?, ?)", function)
+			t.Run(fmt.Sprintf("%s/%d", function, i), func(t *testing.T) {
+				assertThatExpressionTo[string](t, parseTextAs(input), expression, descriptorSetJson, typeName, serializedBinary)
+			})
+		}
+
+		// The output of prototext.Marshal must be readable by pb_text_to_message
+		for _, multiline := range []bool{true, false} {
+			text, err := (prototext.MarshalOptions{Multiline: multiline}).Marshal(input)
+			NewWithT(t).Expect(err).NotTo(HaveOccurred())
+			RunTestThatExpression(t, "pb_text_to_message(?, ?, ?)", descriptorSetJson, typeName, string(text)).IsEqualOrCloseToProto(input, morefloat.WithinMantissaThreshold(0, 2))
+		}
+	}
+}
+
+func TestMessageToTextDepth(t *testing.T) {
+	_, descriptorSetJson := newTextTestSupport(t, "Test child = 1;")
+
+	message, _ := buildNestedNode(100)
+	RunTestThatExpression(t, "pb_message_to_single_line_text(?, '.Test', ?)", descriptorSetJson, message).IsEqualToString(strings.Repeat("child:{", 99) + strings.Repeat("}", 99))
+	RunTestThatExpression(t, "pb_message_to_text(?, '.Test', ?) IS NOT NULL", descriptorSetJson, message).IsTrue()
+	RunTestThatExpression(t, "pb_text_to_message(?, '.Test', ?)", descriptorSetJson, strings.Repeat("child {", 99)+strings.Repeat("}", 99)).IsEqualToBytes(message)
+
+	message, _ = buildNestedNode(101)
+	RunTestThatExpression(t, "pb_message_to_text(?, '.Test', ?)", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "pb_message_to_text: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+	RunTestThatExpression(t, "pb_message_to_single_line_text(?, '.Test', ?)", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "pb_message_to_single_line_text: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+	RunTestThatExpression(t, "pb_text_to_message(?, '.Test', ?)", descriptorSetJson, strings.Repeat("child {", 100)+strings.Repeat("}", 100)).ToFailWithMySQLError(45008, "45008", "pb_text_to_message: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+}
+
+func TestMessageToTextKeepsRecursionDepth(t *testing.T) {
+	_, descriptorSetJson := newTextTestSupport(t, "MessageType message_field = 1;")
+
+	// max_sp_recursion_depth is raised for the nested messages and put back afterwards, also on errors
+	RunTestThatExpression(t, "pb_message_to_text(?, '.Test', _binary X'0A020801')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_to_single_line_text(?, '.Test', _binary X'0A020801')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_to_single_line_text(?, '.Test', _binary X'0A020880')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_text_to_message(?, '.Test', 'message_field {value: 1}')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_text_to_message(?, '.Test', 'message_field {unknown: 1}')", descriptorSetJson).KeepsRecursionDepth()
+}
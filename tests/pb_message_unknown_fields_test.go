@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMessageUnknownFields(t *testing.T) {
+	g := NewWithT(t)
+
+	// Messages are written with the newer schema and read with the older one, which lacks some of the fields.
+	older := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|import "google/protobuf/timestamp.proto";
+			|message Test {
+			|    int32 id = 1;
+			|    Inner inner = 2;
+			|    repeated Inner inners = 3;
+			|    map<string, Inner> inner_map = 4;
+			|    google.protobuf.Timestamp created_at = 5;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|}
+		`),
+	})
+	newer := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|import "google/protobuf/timestamp.proto";
+			|message Test {
+			|    int32 id = 1;
+			|    Inner inner = 2;
+			|    repeated Inner inners = 3;
+			|    map<string, Inner> inner_map = 4;
+			|    google.protobuf.Timestamp created_at = 5;
+			|    int32 extra = 100;
+			|    string extra_string = 101;
+			|}
+			|message Inner {
+			|    int32 a = 1;
+			|    int32 b = 7;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(older.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	typeName := protoreflect.FullName(".Test")
+
+	t.Run("pb_message_to_json_with_unknown_fields", func(t *testing.T) {
+		message := newer.JsonToProtobuf(typeName, `{"id": 1, "extra": 5}`)
+		RunTestThatExpression(t, "pb_message_to_json_with_unknown_fields(?, ?, ?)", descriptorSetJson, typeName, message).IsEqualToJsonString(`{"id": 1, "@unknown": {"100": [{"i": 1, "n": 100, "t": 0, "v": 5}]}}`)
+		RunTestThatExpression(t, "pb_message_to_json(?, ?, ?)", descriptorSetJson, typeName, message).IsEqualToJsonString(`{"id": 1}`)
+
+		message = newer.JsonToProtobuf(typeName, `{"inner": {"a": 1, "b": 2}, "inners": [{"b": 3}], "innerMap": {"k": {"b": 4}}}`)
+		RunTestThatExpression(t, "pb_message_to_json_with_unknown_fields(?, ?, ?)", descriptorSetJson, typeName, message).IsEqualToJsonString(`{
+			"inner": {"a": 1, "@unknown": {"7": [{"i": 1, "n": 7, "t": 0, "v": 2}]}},
+			"inners": [{"@unknown": {"7": [{"i": 0, "n": 7, "t": 0, "v": 3}]}}],
+			"innerMap": {"k": {"@unknown": {"7": [{"i": 0, "n": 7, "t": 0, "v": 4}]}}}
+		}`)
+
+		RunTestThatExpression(t, "pb_message_to_json_with_unknown_fields(?, ?, ?)", descriptorSetJson, typeName, newer.JsonToProtobuf(typeName, `{"id": 1}`)).IsEqualToJsonString(`{"id": 1}`)
+		RunTestThatExpression(t, "pb_message_to_json_with_unknown_fields(?, ?, NULL)", descriptorSetJson, typeName).IsNull()
+	})
+
+	t.Run("pb_message_get_unknown_field_numbers", func(t *testing.T) {
+		message := newer.JsonToProtobuf(typeName, `{"extra": 1, "extraString": "x", "inner": {"b": 1}, "inners": [{}, {"b": 1}], "innerMap": {"k": {"b": 2}}, "createdAt": "2020-01-01T00:00:00Z"}`)
+		RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, ?, ?)", descriptorSetJson, typeName, message).IsEqualToJsonString(`[
+			{"path": "", "number": 100},
+			{"path": "", "number": 101},
+			{"path": "inner", "number": 7},
+			{"path": "inners[1]", "number": 7},
+			{"path": "inner_map[\"k\"]", "number": 7}
+		]`)
+		RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, ?, ?)", descriptorSetJson, typeName, newer.JsonToProtobuf(typeName, `{"id": 1, "inner": {"a": 1}}`)).IsEqualToJsonString(`[]`)
+		RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, ?, NULL)", descriptorSetJson, typeName).IsNull()
+	})
+
+	t.Run("pb_message_discard_unknown", func(t *testing.T) {
+		message := newer.JsonToProtobuf(typeName, `{"id": 1, "extra": 1, "inner": {"a": 1, "b": 1}, "inners": [{"b": 1}], "innerMap": {"k": {"a": 2, "b": 2}}, "createdAt": "2020-01-01T00:00:00Z"}`)
+		expected := newer.JsonToDynamicMessage(typeName, `{"id": 1, "inner": {"a": 1}, "inners": [{}], "innerMap": {"k": {"a": 2}}, "createdAt": "2020-01-01T00:00:00Z"}`).Interface()
+		RunTestThatExpression(t, "pb_message_discard_unknown(?, ?, ?)", descriptorSetJson, typeName, message).IsEqualToProto(expected)
+		RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, ?, pb_message_discard_unknown(?, ?, ?))", descriptorSetJson, typeName, descriptorSetJson, typeName, message).IsEqualToJsonString(`[]`)
+		RunTestThatExpression(t, "pb_message_discard_unknown(?, ?, NULL)", descriptorSetJson, typeName).IsNull()
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, '.Unknown', '')", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "pb_message_get_unknown_field_numbers: message type `.Unknown` not found in descriptor set")
+		RunTestThatExpression(t, "pb_message_discard_unknown(?, '.Unknown', '')", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "pb_message_discard_unknown: message type `.Unknown` not found in descriptor set")
+	})
+}
+
+func TestMessageUnknownFieldsDepth(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Node {
+			|    Node child = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	message, _ := buildNestedNode(100)
+	RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, '.Node', ?)", descriptorSetJson, message).IsEqualToJsonString(`[]`)
+	RunTestThatExpression(t, "pb_message_discard_unknown(?, '.Node', ?)", descriptorSetJson, message).IsEqualToBytes(message)
+	message, _ = buildNestedNode(101)
+	RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, '.Node', ?)", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "pb_message_get_unknown_field_numbers: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+	RunTestThatExpression(t, "pb_message_discard_unknown(?, '.Node', ?)", descriptorSetJson, message).ToFailWithMySQLError(45008, "45008", "pb_message_discard_unknown: message nesting depth exceeds 100 (PB_DEPTH_LIMIT_EXCEEDED)")
+
+	// max_sp_recursion_depth is raised for the nested messages and put back afterwards, also on errors
+	RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, '.Node', _binary X'0A020A00')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_get_unknown_field_numbers(?, '.Unknown', _binary X'0A020A00')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_discard_unknown(?, '.Node', _binary X'0A020A00')", descriptorSetJson).KeepsRecursionDepth()
+	RunTestThatExpression(t, "pb_message_discard_unknown(?, '.Unknown', _binary X'0A020A00')", descriptorSetJson).KeepsRecursionDepth()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMessageValidate(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"proto2.proto": dedent.Pipe(`
+			|syntax = "proto2";
+			|message Proto2 {
+			|    required int32 id = 1;
+			|    optional string name = 2;
+			|    optional Color color = 3;
+			|    repeated Color colors = 4;
+			|    optional Proto2 child = 5;
+			|    repeated Proto2 children = 6;
+			|    repeated fixed32 fixed32_list = 7;
+			|    map<string, Proto2> children_by_name = 8;
+			|}
+			|enum Color {
+			|    RED = 1;
+			|    GREEN = 2;
+			|}
+		`),
+		"proto3.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Proto3 {
+			|    string name = 1;
+			|    OpenColor color = 2;
+			|    repeated int64 values = 3;
+			|}
+			|enum OpenColor {
+			|    OPEN_COLOR_UNSPECIFIED = 0;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	proto2 := protoreflect.FullName(".Proto2")
+	proto3 := protoreflect.FullName(".Proto3")
+
+	t.Run("valid", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, p.JsonToProtobuf(proto2, `{"id": 1, "name": "あ", "color": "GREEN", "colors": ["RED"], "child": {"id": 2}, "children": [{"id": 3}], "fixed32List": [1, 2], "childrenByName": {"a": {"id": 4}}}`)).IsEqualToJson([]any{})
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto3, p.JsonToProtobuf(proto3, `{}`)).IsEqualToJson([]any{})
+		RunTestThatExpression(t, "pb_message_validate(?, ?, NULL)", descriptorSetJson, proto3).IsNull()
+	})
+
+	t.Run("malformed wire data", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_validate(?, ?, _binary X'08')", descriptorSetJson, proto2).IsEqualToJson([]any{
+			map[string]any{"path": "", "code": "MALFORMED_WIRE_DATA", "message": "Unexpected end of BLOB."},
+		})
+		RunTestThatExpression(t, "pb_message_validate(?, ?, _binary X'120541')", descriptorSetJson, proto2).IsEqualToJson([]any{
+			map[string]any{"path": "", "code": "MALFORMED_WIRE_DATA", "message": "Unexpected end of BLOB."},
+		})
+		// child = {id: <truncated>}
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+			protowire.AppendBytes(protowire.AppendTag(nil, 5, protowire.BytesType), []byte{0x08})...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "child", "code": "MALFORMED_WIRE_DATA", "message": "Unexpected end of BLOB."},
+		})
+		// fixed32_list packed with 3 bytes
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+			protowire.AppendBytes(protowire.AppendTag(nil, 7, protowire.BytesType), []byte{1, 2, 3})...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "fixed32_list", "code": "MALFORMED_WIRE_DATA", "message": "packed field `fixed32_list`: length 3 is not a multiple of 4"},
+		})
+	})
+
+	t.Run("wire type mismatch", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto3, protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1)).IsEqualToJson([]any{
+			map[string]any{"path": "name", "code": "WIRE_TYPE_MISMATCH", "message": "field `name` expects wire type LEN but got VARINT"},
+		})
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto3, append(
+			protowire.AppendVarint(protowire.AppendTag(nil, 3, protowire.VarintType), 1),
+			protowire.AppendFixed32(protowire.AppendTag(nil, 3, protowire.Fixed32Type), 1)...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "values[1]", "code": "WIRE_TYPE_MISMATCH", "message": "field `values` expects wire type VARINT but got I32"},
+		})
+	})
+
+	t.Run("invalid utf-8", func(t *testing.T) {
+		for _, invalid := range [][]byte{{0xff}, {0xc0, 0x80}, {0xed, 0xa0, 0x80}, {0xe3, 0x81}, {0xf4, 0x90, 0x80, 0x80}} {
+			RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto3, protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), invalid)).IsEqualToJson([]any{
+				map[string]any{"path": "name", "code": "INVALID_UTF8", "message": "string field `name` contains invalid UTF-8"},
+			})
+		}
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, p.JsonToProtobuf(proto2, `{"id": 1, "children": [{"id": 2}, {"id": 3, "name": "\u0000"}]}`)).IsEqualToJson([]any{})
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			p.JsonToProtobuf(proto2, `{"id": 1, "children": [{"id": 2}]}`),
+			protowire.AppendBytes(protowire.AppendTag(nil, 6, protowire.BytesType),
+				append(protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 3), protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), []byte{'a', 0xff})...))...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "children[1].name", "code": "INVALID_UTF8", "message": "string field `name` contains invalid UTF-8"},
+		})
+	})
+
+	t.Run("closed enums", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+			protowire.AppendVarint(protowire.AppendTag(nil, 3, protowire.VarintType), 3)...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "color", "code": "ENUM_VALUE_OUT_OF_RANGE", "message": "value 3 is not declared in enum `.Color`"},
+		})
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+			protowire.AppendBytes(protowire.AppendTag(nil, 4, protowire.BytesType), []byte{0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01})...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "colors[1]", "code": "ENUM_VALUE_OUT_OF_RANGE", "message": "value -1 is not declared in enum `.Color`"},
+		})
+		// Enums in proto3 are open
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto3, protowire.AppendVarint(protowire.AppendTag(nil, 2, protowire.VarintType), 5)).IsEqualToJson([]any{})
+	})
+
+	t.Run("unknown fields", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto3, protowire.AppendVarint(protowire.AppendTag(nil, 100, protowire.VarintType), 1)).IsEqualToJson([]any{
+			map[string]any{"path": "100", "code": "UNKNOWN_FIELD", "message": "field number 100 is not declared in message type `.Proto3`"},
+		})
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+			protowire.AppendBytes(protowire.AppendTag(nil, 5, protowire.BytesType), append(protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1), protowire.AppendFixed64(protowire.AppendTag(nil, 9, protowire.Fixed64Type), 1)...))...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "child.9", "code": "UNKNOWN_FIELD", "message": "field number 9 is not declared in message type `.Proto2`"},
+		})
+	})
+
+	t.Run("missing required fields", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_validate(?, ?, '')", descriptorSetJson, proto2).IsEqualToJson([]any{
+			map[string]any{"path": "id", "code": "MISSING_REQUIRED_FIELD", "message": "required field `id` is missing"},
+		})
+		// Occurrences of a singular message field are merged before checking required fields
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+			append(
+				protowire.AppendBytes(protowire.AppendTag(nil, 5, protowire.BytesType), nil),
+				protowire.AppendBytes(protowire.AppendTag(nil, 5, protowire.BytesType), protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1))...,
+			)...,
+		)).IsEqualToJson([]any{})
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+			protowire.AppendBytes(protowire.AppendTag(nil, 8, protowire.BytesType), append(
+				protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), "a"),
+				protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), nil)...,
+			))...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "children_by_name[0].value.id", "code": "MISSING_REQUIRED_FIELD", "message": "required field `id` is missing"},
+		})
+	})
+
+	t.Run("multiple problems", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_validate(?, ?, ?)", descriptorSetJson, proto2, append(
+			protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), []byte{0xff}),
+			protowire.AppendVarint(protowire.AppendTag(nil, 20, protowire.VarintType), 1)...,
+		)).IsEqualToJson([]any{
+			map[string]any{"path": "id", "code": "MISSING_REQUIRED_FIELD", "message": "required field `id` is missing"},
+			map[string]any{"path": "name", "code": "INVALID_UTF8", "message": "string field `name` contains invalid UTF-8"},
+			map[string]any{"path": "20", "code": "UNKNOWN_FIELD", "message": "field number 20 is not declared in message type `.Proto2`"},
+		})
+	})
+
+	t.Run("unknown message type", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_validate(?, '.Unknown', '')", descriptorSetJson).IsEqualToJson([]any{
+			map[string]any{"path": "", "code": "UNKNOWN_MESSAGE_TYPE", "message": "message type `.Unknown` not found in descriptor set"},
+		})
+	})
+}
+
+func TestMessageValidateDepth(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|message Node {
+			|    Node child = 1;
+			|}
+		`),
+	})
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	message, _ := buildNestedNode(100)
+	RunTestThatExpression(t, "pb_message_validate(?, '.Node', ?)", descriptorSetJson, message).IsEqualToJson([]any{})
+
+	message, _ = buildNestedNode(101)
+	RunTestThatExpression(t, "pb_message_validate(?, '.Node', ?)", descriptorSetJson, message).IsEqualToJson([]any{
+		map[string]any{"path": strings.TrimSuffix(strings.Repeat("child.", 100), "."), "code": "DEPTH_LIMIT_EXCEEDED", "message": "message nesting depth exceeds 100"},
+	})
+
+	// max_sp_recursion_depth is raised for the nested messages and put back afterwards
+	RunTestThatExpression(t, "pb_message_validate(?, '.Node', _binary X'0A020A00')", descriptorSetJson).KeepsRecursionDepth()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRepeatedFieldOperations(t *testing.T) {
+	// Runs the expression for both message and wire_json inputs and compares the hex-encoded message
+	test := func(t *testing.T, function string, message string, arguments string, expectedHex string) {
+		RunTestThatExpression(t, fmt.Sprintf("HEX(pb_message_%s(_binary X'%s', %s))", function, message, arguments)).IsEqualToString(expectedHex)
+		RunTestThatExpression(t, fmt.Sprintf("HEX(pb_wire_json_to_message(pb_wire_json_%s(pb_message_to_wire_json(_binary X'%s'), %s)))", function, message, arguments)).IsEqualToString(expectedHex)
+	}
+
+	const packedInt32s = "0A0403010201"       // 1: [3, 1, 2, 1] (packed)
+	const unpackedInt32s = "0803080108020801" // 1: [3, 1, 2, 1] (unpacked)
+
+	t.Run("slice", func(t *testing.T) {
+		test(t, "slice_repeated_int32_field", packedInt32s, "1, 1, 3", "0A020102")
+		test(t, "slice_repeated_int32_field", packedInt32s, "1, -2, NULL", "0A020201")
+		test(t, "slice_repeated_int32_field", packedInt32s, "1, 0, -1", "0A03030102")
+		test(t, "slice_repeated_int32_field", packedInt32s, "1, 2, 100", "0A020201")
+		test(t, "slice_repeated_int32_field", packedInt32s, "1, 3, 1", "")
+		test(t, "slice_repeated_int32_field", unpackedInt32s, "1, 1, 3", "08010802")
+		test(t, "slice_repeated_string_field", "120161120162120163", "2, -2, NULL", "120162120163")
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		test(t, "reverse_repeated_int32_field", packedInt32s, "1", "0A0401020103")
+		test(t, "reverse_repeated_int32_field", unpackedInt32s, "1", "0801080208010803")
+		test(t, "reverse_repeated_message_field", "1A020801"+"1A00", "3", "1A00"+"1A020801")
+		test(t, "reverse_repeated_int32_field", "", "1", "")
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		test(t, "sort_repeated_int32_field", packedInt32s, "1", "0A0401010203")
+		test(t, "sort_repeated_int32_field", unpackedInt32s, "1", "0801080108020803")
+		test(t, "sort_repeated_sint32_field", "08020801", "1", "08010802")                                                         // [1, -1]
+		test(t, "sort_repeated_uint64_field", "08FFFFFFFFFFFFFFFFFF010801", "1", "080108FFFFFFFFFFFFFFFFFF01")                     // [2^64-1, 1]
+		test(t, "sort_repeated_double_field", "0A100000000000000440000000000000F0BF", "1", "0A10000000000000F0BF0000000000000440") // [2.5, -1.0]
+		test(t, "sort_repeated_string_field", "120162120161120162", "2", "120161120162120162")
+		test(t, "sort_repeated_string_field", "120162120142120161"+"1202C3A1"+"120141", "2", "120141120142120161120162"+"1202C3A1") // ["b", "B", "a", "á", "A"]
+	})
+
+	t.Run("distinct", func(t *testing.T) {
+		test(t, "distinct_repeated_int32_field", packedInt32s, "1", "0A03030102")
+		test(t, "distinct_repeated_int32_field", unpackedInt32s, "1", "080308010802")
+		test(t, "distinct_repeated_string_field", "120162120161120162", "2", "120162120161")
+		test(t, "distinct_repeated_bytes_field", "120100120101120100", "2", "120100120101")
+	})
+
+	t.Run("field position is kept", func(t *testing.T) {
+		const interleavedInt32s = "0803" + "1805" + "0801" + "1806" + "08020801" // 1: [3, 1, 2, 1] interleaved with 3: [5, 6]
+		test(t, "slice_repeated_int32_field", interleavedInt32s, "1, 1, 3", "0801"+"1805"+"0802"+"1806")
+		test(t, "reverse_repeated_int32_field", interleavedInt32s, "1", "0801"+"1805"+"0802"+"1806"+"08010803")
+		test(t, "sort_repeated_int32_field", interleavedInt32s, "1", "0801"+"1805"+"0801"+"1806"+"08020803")
+		test(t, "distinct_repeated_int32_field", interleavedInt32s, "1", "0803"+"1805"+"0801"+"1806"+"0802")
+
+		const surroundedInt32s = "1805" + packedInt32s + "1806"
+		test(t, "slice_repeated_int32_field", surroundedInt32s, "1, 1, 3", "1805"+"0A020102"+"1806")
+		test(t, "slice_repeated_int32_field", surroundedInt32s, "1, 0, 0", "1805"+"1806")
+		test(t, "reverse_repeated_int32_field", surroundedInt32s, "1", "1805"+"0A0401020103"+"1806")
+		test(t, "sort_repeated_int32_field", surroundedInt32s, "1", "1805"+"0A0401010203"+"1806")
+		test(t, "distinct_repeated_int32_field", surroundedInt32s, "1", "1805"+"0A03030102"+"1806")
+		test(t, "reverse_repeated_string_field", "120161"+"1805"+"120162", "2", "120162"+"1805"+"120161")
+	})
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -385,12 +387,33 @@ func (this *ExpressionTestContext) IsEqualToJson(expectedJson interface{}) {
 	})
 }
 
-func (this *ExpressionTestContext) IsEqualOrCloseToJsonString(expectedJson string, equalOrCloseFn func(a, b float64) bool) {
-	this.RunFn(fmt.Sprintf("RunTestThatExpression(`%s`,%s).IsEqualOrCloseToJson(%s)", this.Expression, formatArguments(this.Args...), formatArguments(expectedJson)), func(t *testing.T) {
-		assertThatExpressionTo[string](t, gjson.EqualJson(expectedJson).WithFloatEqualFn(equalOrCloseFn), this.Expression, this.Args...)
+// IsEqualToProtojsonString compares the result with the output of protojson, number by number as text.
+// The protojson notation is first rewritten to the notation of the MySQL JSON type; see mysqlJsonNumberText.
+func (this *ExpressionTestContext) IsEqualToProtojsonString(expectedJson string) {
+	this.RunFn(fmt.Sprintf("RunTestThatExpression(`%s`,%s).IsEqualToProtojsonString(%s)", this.Expression, formatArguments(this.Args...), formatArguments(expectedJson)), func(t *testing.T) {
+		assertThatExpressionTo[string](t, gjson.EqualJson(expectedJson).WithNumberTextFn(mysqlJsonNumberText), this.Expression, this.Args...)
 	})
 }
 
+// mysqlJsonNumberText rewrites a number printed by protojson to how the MySQL JSON type prints the same value.
+// See the notation table of pb_message_to_json in docs/function-reference.md.
+func mysqlJsonNumberText(text string) string {
+	if text == "-0" {
+		return "-0.0"
+	}
+	if !strings.ContainsAny(text, ".eE") {
+		if _, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return text
+		}
+		if _, err := strconv.ParseUint(text, 10, 64); err == nil {
+			return text
+		}
+		// Integers beyond BIGINT UNSIGNED are stored as doubles
+		text = strconv.FormatFloat(lo.Must(strconv.ParseFloat(text, 64)), 'e', -1, 64)
+	}
+	return strings.Replace(text, "e+", "e", 1)
+}
+
 func (this *ExpressionTestContext) ToSucceed() {
 	this.RunFn(fmt.Sprintf("RunTestThatExpression(`%s`,%s).ToSucceed()", this.Expression, formatArguments(this.Args...)), func(t *testing.T) {
 		assertThatExpressionTo[interface{}](t, SatisfyAny(BeNil(), Not(BeNil())), this.Expression, this.Args...)
@@ -403,6 +426,38 @@ func (this *ExpressionTestContext) ToFailWithSignalException(state string, conta
 	})
 }
 
+func (this *ExpressionTestContext) ToFailWithMySQLError(number uint16, state string, containsMessage string) {
+	this.RunFn(fmt.Sprintf("RunTestThatExpression(`%s`,%s).ToFailWithMySQLError(%s)", this.Expression, formatArguments(this.Args...), formatArguments(number, state, containsMessage)), func(t *testing.T) {
+		assertThatExpressionToFailWith(t, gmysql.BeMySQLError(number, state, ContainSubstring(containsMessage)), this.Expression, this.Args...)
+	})
+}
+
+// KeepsRecursionDepth evaluates the expression, whether it succeeds or fails, and expects max_sp_recursion_depth of the
+// session to be the same as before.
+func (this *ExpressionTestContext) KeepsRecursionDepth() {
+	this.RunFn(fmt.Sprintf("RunTestThatExpression(`%s`,%s).KeepsRecursionDepth()", this.Expression, formatArguments(this.Args...)), func(t *testing.T) {
+		g := NewWithT(t)
+
+		// The session variable is read back with separate statements, so every statement must run on the same connection.
+		ctx := context.Background()
+		conn, err := db.Conn(ctx)
+		g.Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			g.Expect(conn.Close()).To(Succeed())
+		}()
+
+		_, err = conn.ExecContext(ctx, "SET @@SESSION.max_sp_recursion_depth = 3")
+		g.Expect(err).NotTo(HaveOccurred())
+
+		var result any
+		_ = conn.QueryRowContext(ctx, "SELECT "+this.Expression, marshalArgs(this.Args)...).Scan(&result)
+
+		var depth int
+		g.Expect(conn.QueryRowContext(ctx, "SELECT @@SESSION.max_sp_recursion_depth").Scan(&depth)).To(Succeed())
+		g.Expect(depth).To(Equal(3))
+	})
+}
+
 func (this *ExpressionTestContext) IsEqualToProto(expectedProto proto.Message) {
 	this.RunFn(fmt.Sprintf("RunTestThatExpression(`%s`,%s).IsEqualToProto(%s)", this.Expression, formatArguments(this.Args...), formatArguments(expectedProto)), func(t *testing.T) {
 		assertThatExpressionTo[[]byte](t, gproto.EqualProto(expectedProto), this.Expression, this.Args...)
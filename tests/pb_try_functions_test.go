@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+)
+
+func TestTryFunctions(t *testing.T) {
+	t.Run("getters", func(t *testing.T) {
+		RunTestThatExpression(t, "try_pb_message_get_int32_field(_binary X'0801', 1, 0)").IsEqualToInt(1)
+		RunTestThatExpression(t, "try_pb_message_get_int32_field(_binary X'08', 1, 0)").IsNull()
+		RunTestThatExpression(t, "try_pb_wire_json_get_int32_field(pb_message_to_wire_json(_binary X'0801'), 1, 0)").IsEqualToInt(1)
+		RunTestThatExpression(t, "try_pb_message_has_int32_field(_binary X'08', 1)").IsNull()
+		RunTestThatExpression(t, "try_pb_message_get_string_field(_binary X'0801', 1, '')").IsNull()
+		RunTestThatExpression(t, "try_pb_message_get_repeated_int32_field_element(_binary X'0801', 1, 0)").IsEqualToInt(1)
+		RunTestThatExpression(t, "try_pb_message_get_repeated_int32_field_count(_binary X'08010802', 1)").IsEqualToInt(2)
+		RunTestThatExpression(t, "try_pb_message_get_repeated_int32_field_count(_binary X'080108', 1)").IsNull()
+		RunTestThatExpression(t, "try_pb_message_get_repeated_int32_field_as_json_array(_binary X'080108', 1)").IsNull()
+	})
+
+	t.Run("errors other than malformed input are raised", func(t *testing.T) {
+		RunTestThatExpression(t, "try_pb_message_get_repeated_int32_field_element(_binary X'0801', 1, 1)").ToFailWithSignalException("45000", "repeated index out of range")
+	})
+
+	t.Run("pb_last_error", func(t *testing.T) {
+		RunTestThatExpression(t, "JSON_ARRAY(try_pb_message_get_int32_field(_binary X'08', 1, 0), @pb_last_error)").IsEqualToJsonString(`[null, "_pb_wire_read_varint_as_uint64: Unexpected end of BLOB. (PB_TRUNCATED_VARINT, offset 1, field 1)"]`)
+		RunTestThatExpression(t, "JSON_ARRAY(try_pb_message_get_int32_field(_binary X'08', 1, 0), try_pb_message_get_int32_field(_binary X'0801', 1, 0), @pb_last_error)").IsEqualToJsonString(`[null, 1, null]`)
+	})
+
+	t.Run("conversions", func(t *testing.T) {
+		g := NewWithT(t)
+
+		p := testutils.NewProtoTestSupport(t, map[string]string{
+			"main.proto": dedent.Pipe(`
+				|syntax = "proto3";
+				|import "google/protobuf/struct.proto";
+				|message Test {
+				|    int32 id = 1;
+				|    google.protobuf.Value value = 2;
+				|}
+				|message Node {
+				|    Node child = 1;
+				|}
+			`),
+		})
+		descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+		g.Expect(err).NotTo(HaveOccurred())
+
+		RunTestThatExpression(t, "try_pb_message_to_wire_json(_binary X'0801')").IsEqualToJsonString(`{"1": [{"i": 0, "n": 1, "t": 0, "v": 1}]}`)
+		RunTestThatExpression(t, "try_pb_message_to_wire_json(_binary X'08')").IsNull()
+		RunTestThatExpression(t, "try_pb_message_to_json(?, '.Test', _binary X'0801')", descriptorSetJson).IsEqualToJsonString(`{"id": 1}`)
+		RunTestThatExpression(t, "try_pb_message_to_json(?, '.Test', _binary X'08')", descriptorSetJson).IsNull()
+		// Limits and values without a JSON representation are caught like malformed input
+		message, _ := buildNestedNode(101)
+		RunTestThatExpression(t, "JSON_ARRAY(try_pb_message_to_json(?, '.Node', ?), @pb_last_error LIKE '%(PB_DEPTH_LIMIT_EXCEEDED)')", descriptorSetJson, message).IsEqualToJsonString(`[null, true]`)
+		RunTestThatExpression(t, "JSON_ARRAY(try_pb_message_to_json(?, '.Test', _binary X'120911000000000000F87F'), @pb_last_error LIKE '%(PB_NON_FINITE_VALUE%')", descriptorSetJson).IsEqualToJsonString(`[null, true]`)
+
+		// A message type missing from the schema is a caller error
+		RunTestThatExpression(t, "try_pb_message_to_json(?, '.Unknown', _binary X'')", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "(PB_TYPE_NOT_FOUND)")
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+)
+
+func TestTypeIndexLookups(t *testing.T) {
+	g := NewWithT(t)
+
+	// Versions of the same schema, with the field and enum value renamed
+	buildSchema := func(syntax string, fieldName string, enumValueName string) string {
+		label := ""
+		if syntax == "proto2" {
+			label = "optional "
+		}
+		p := testutils.NewProtoTestSupport(t, map[string]string{
+			"main.proto": dedent.Pipe(`
+				|syntax = "` + syntax + `";
+				|message Test {
+				|    ` + label + `int32 ` + fieldName + ` = 1;
+				|    ` + label + `Color color = 2;
+				|}
+				|enum Color {
+				|    COLOR_UNSPECIFIED = 0;
+				|    ` + enumValueName + ` = 1;
+				|}
+			`),
+		})
+		descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+		g.Expect(err).NotTo(HaveOccurred())
+		return descriptorSetJson
+	}
+	v1 := buildSchema("proto3", "old_name", "RED")
+	v2 := buildSchema("proto3", "new_name", "BLUE")
+
+	// Type index entries as built by earlier versions: [kind, file path, type path] only
+	withoutPrecomputed := func(descriptorSetJson string) string {
+		var descriptorSet []any
+		g.Expect(json.Unmarshal([]byte(descriptorSetJson), &descriptorSet)).To(Succeed())
+		for typeName, entry := range descriptorSet[2].(map[string]any) {
+			descriptorSet[2].(map[string]any)[typeName] = entry.([]any)[:3]
+		}
+		result, err := json.Marshal(descriptorSet)
+		g.Expect(err).NotTo(HaveOccurred())
+		return string(result)
+	}
+
+	const message = "_binary X'08011001'"
+
+	t.Run("schema versions", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+message+")", v1).IsEqualToJsonString(`{"oldName": 1, "color": "RED"}`)
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+message+")", v2).IsEqualToJsonString(`{"newName": 1, "color": "BLUE"}`)
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+message+")", v1).IsEqualToJsonString(`{"oldName": 1, "color": "RED"}`)
+	})
+
+	t.Run("precomputed enum values are used", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json(JSON_SET(?, '$[2].\".Color\"[4].n.\"1\"', 'CRIMSON'), '.Test', "+message+")", v1).IsEqualToJsonString(`{"oldName": 1, "color": "CRIMSON"}`)
+		RunTestThatExpression(t, "HEX(pb_message_set_field(JSON_SET(?, '$[2].\".Color\"[4].v.CRIMSON', 1), '.Test', _binary X'', 'color', '\"CRIMSON\"'))", v1).IsEqualToString("1001")
+	})
+
+	t.Run("precomputed syntax is used", func(t *testing.T) {
+		// proto2 does not omit fields set to their default values
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', _binary X'08001000')", v1).IsEqualToJsonString(`{}`)
+		RunTestThatExpression(t, "pb_message_to_json(JSON_SET(?, '$[2].\".Test\"[3]', 'proto2'), '.Test', _binary X'08001000')", v1).IsEqualToJsonString(`{"oldName": 0, "color": "COLOR_UNSPECIFIED"}`)
+	})
+
+	t.Run("type index entries without precomputed lookups", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+message+")", withoutPrecomputed(v1)).IsEqualToJsonString(`{"oldName": 1, "color": "RED"}`)
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+message+")", withoutPrecomputed(v2)).IsEqualToJsonString(`{"newName": 1, "color": "BLUE"}`)
+		RunTestThatExpression(t, "HEX(pb_message_set_field(?, '.Test', _binary X'', 'color', '\"BLUE\"'))", withoutPrecomputed(v2)).IsEqualToString("1001")
+		RunTestThatExpression(t, "pb_message_set_field(?, '.Test', _binary X'', 'color', '\"RED\"')", withoutPrecomputed(v2)).ToFailWithSignalException("45000", "_pb_enum_from_json: unknown value `RED` for enum `.Color`")
+
+		proto2 := buildSchema("proto2", "old_name", "RED")
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', _binary X'08001000')", withoutPrecomputed(proto2)).IsEqualToJsonString(`{"oldName": 0, "color": "COLOR_UNSPECIFIED"}`)
+	})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+)
+
+func TestUtf8Validation(t *testing.T) {
+	g := NewWithT(t)
+
+	buildSchema := func(syntax string, label string) string {
+		p := testutils.NewProtoTestSupport(t, map[string]string{
+			"main.proto": dedent.Pipe(`
+				|syntax = "` + syntax + `";
+				|message Test {
+				|    ` + label + ` string s = 1;
+				|    map<string, string> m = 2;
+				|    repeated string r = 3;
+				|}
+			`),
+		})
+		descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+		g.Expect(err).NotTo(HaveOccurred())
+		return descriptorSetJson
+	}
+	proto2 := buildSchema("proto2", "optional")
+	proto3 := buildSchema("proto3", "")
+
+	// s = "A\xFFB"
+	const invalidString = "_binary X'0A0341FF42'"
+	// m = {"\xFF": "x"}
+	const invalidMapKey = "_binary X'12060A01FF120178'"
+	// r = ["ok", "\xC3"]
+	const invalidRepeated = "_binary X'1A026F6B1A01C3'"
+
+	t.Run("getters pass through by default", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_get_string_field(_binary X'0A03414243', 1, '')").IsEqualToString("ABC")
+		RunTestThatExpression(t, "HEX(pb_message_get_string_field("+invalidString+", 1, ''))").IsEqualToString("41FF42")
+		RunTestThatExpression(t, "HEX(pb_wire_json_get_string_field(pb_message_to_wire_json("+invalidString+"), 1, ''))").IsEqualToString("41FF42")
+	})
+
+	t.Run("well-formed UTF-8", func(t *testing.T) {
+		RunTestThatExpression(t, "_pb_util_is_valid_utf8(_binary X'')").IsTrue()
+		RunTestThatExpression(t, "_pb_util_is_valid_utf8(_binary X'41E282ACF09F9880')").IsTrue()
+		RunTestThatExpression(t, "_pb_util_is_valid_utf8(_binary X'ED9FBF')").IsTrue()    // U+D7FF
+		RunTestThatExpression(t, "_pb_util_is_valid_utf8(_binary X'EDA080')").IsFalse()   // surrogate
+		RunTestThatExpression(t, "_pb_util_is_valid_utf8(_binary X'C0AF')").IsFalse()     // overlong
+		RunTestThatExpression(t, "_pb_util_is_valid_utf8(_binary X'F4908080')").IsFalse() // above U+10FFFF
+		RunTestThatExpression(t, "_pb_util_is_valid_utf8(_binary X'41E282')").IsFalse()   // truncated
+		// Long strings are checked without a per-byte loop or regular expression
+		RunTestThatExpression(t, "_pb_util_is_valid_utf8(REPEAT(_binary X'41E282AC', 1000000))").IsTrue()
+	})
+
+	t.Run("proto3 is strict", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+invalidString+")", proto3).ToFailWithMySQLError(45007, "45007", "(PB_INVALID_UTF8, field 1)")
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+invalidMapKey+")", proto3).ToFailWithMySQLError(45007, "45007", "(PB_INVALID_UTF8, field 2)")
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+invalidRepeated+")", proto3).ToFailWithMySQLError(45007, "45007", "(PB_INVALID_UTF8, field 3)")
+		RunTestThatExpression(t, "pb_message_get_field(?, '.Test', "+invalidString+", 's')", proto3).ToFailWithMySQLError(45007, "45007", "(PB_INVALID_UTF8, field 1)")
+	})
+
+	t.Run("proto2 replaces invalid sequences in JSON", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+invalidString+")", proto2).IsEqualToJsonString(`{"s": "A�B"}`)
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+invalidMapKey+")", proto2).IsEqualToJsonString(`{"m": {"�": "x"}}`)
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Test', "+invalidRepeated+")", proto2).IsEqualToJsonString(`{"r": ["ok", "�"]}`)
+		RunTestThatExpression(t, "pb_message_get_field(?, '.Test', "+invalidString+", 's')", proto2).IsEqualToJsonString(`"A�B"`)
+	})
+
+	t.Run("editions inherit utf8_validation from the file", func(t *testing.T) {
+		// utf8_validation can only be set on files and fields, so nested messages take it from the file
+		p := testutils.NewProtoTestSupport(t, map[string]string{
+			"main.proto": dedent.Pipe(`
+				|edition = "2023";
+				|option features.utf8_validation = NONE;
+				|message Outer {
+				|    message Inner {
+				|        string s = 1;
+				|        string v = 2 [features.utf8_validation = VERIFY];
+				|    }
+				|    Inner inner = 1;
+				|}
+			`),
+		})
+		editions, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+		NewWithT(t).Expect(err).NotTo(HaveOccurred())
+
+		// inner = {s: "A\xFFB"}, inner = {v: "A\xFFB"}
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Outer', _binary X'0A050A0341FF42')", editions).IsEqualToJsonString(`{"inner": {"s": "A�B"}}`)
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Outer.Inner', "+invalidString+")", editions).IsEqualToJsonString(`{"s": "A�B"}`)
+		RunTestThatExpression(t, "pb_message_to_json(?, '.Outer', _binary X'0A05120341FF42')", editions).ToFailWithMySQLError(45007, "45007", "(PB_INVALID_UTF8, field 1.2)")
+	})
+
+	t.Run("maximal subparts are replaced", func(t *testing.T) {
+		// Truncated 3-byte sequence, lone continuation byte, surrogate and overlong encoding
+		RunTestThatExpression(t, "HEX(_pb_util_replace_invalid_utf8(_binary X'41E282428042EDA08042C0AF'))").IsEqualToString("41EFBFBD42EFBFBD42EFBFBDEFBFBDEFBFBD42EFBFBDEFBFBD")
+		RunTestThatExpression(t, "HEX(_pb_util_replace_invalid_utf8(_binary X'E282ACF09F9880'))").IsEqualToString("E282ACF09F9880")
+	})
+
+	t.Run("pb_utf8_check", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_utf8_check(pb_message_get_bytes_field("+invalidString+", 1, ''), 'strict')").ToFailWithMySQLError(45007, "45007", "_pb_util_check_utf8: invalid UTF-8 in string field (PB_INVALID_UTF8)")
+		RunTestThatExpression(t, "pb_utf8_check(pb_message_get_bytes_field("+invalidString+", 1, ''), 'replace')").IsEqualToString("A�B")
+		RunTestThatExpression(t, "HEX(pb_utf8_check(pb_message_get_bytes_field("+invalidString+", 1, ''), 'passthrough'))").IsEqualToString("41FF42")
+		RunTestThatExpression(t, "pb_utf8_check(_binary X'41E282AC', 'strict')").IsEqualToString("A€")
+		RunTestThatExpression(t, "pb_utf8_check(NULL, 'strict')").IsNull()
+		RunTestThatExpression(t, "pb_utf8_check(_binary X'41', 'lenient')").ToFailWithSignalException("45000", "pb_utf8_check: validation_mode must be one of `strict`, `replace` or `passthrough`, but got `lenient`")
+	})
+}
@@ -134,3 +134,13 @@ func TestUtilBinAsUint32(t *testing.T) {
 	RunTestThatExpression(t, "_pb_util_bin_as_uint32(_binary X'80000000')").IsEqualToUint(2147483648)
 	RunTestThatExpression(t, "_pb_util_bin_as_uint32(_binary X'ffffffff')").IsEqualToUint(4294967295)
 }
+
+func TestUtilSnakeToLowerCamel(t *testing.T) {
+	RunTestThatExpression(t, "_pb_util_snake_to_lower_camel('foo')").IsEqualToString("foo")
+	RunTestThatExpression(t, "_pb_util_snake_to_lower_camel('foo_bar')").IsEqualToString("fooBar")
+	RunTestThatExpression(t, "_pb_util_snake_to_lower_camel('foo_bar_baz')").IsEqualToString("fooBarBaz")
+	RunTestThatExpression(t, "_pb_util_snake_to_lower_camel('foo__bar')").IsEqualToString("fooBar")
+	RunTestThatExpression(t, "_pb_util_snake_to_lower_camel('foo_1')").IsEqualToString("foo1")
+	RunTestThatExpression(t, "_pb_util_snake_to_lower_camel('_foo')").IsEqualToString("Foo")
+	RunTestThatExpression(t, "_pb_util_snake_to_lower_camel('fooBar')").IsEqualToString("fooBar")
+}
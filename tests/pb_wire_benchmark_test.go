@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The wire readers take a (buf, offset) pair instead of returning the remaining tail, so decoding a message
+// should take time proportional to its size. These benchmarks report ns/byte for increasing message sizes,
+// which stays roughly constant as long as the scaling is linear.
+//
+//	make benchmark BENCH=BenchmarkWire
+
+var benchmarkElementCounts = []int{1000, 10000, 100000}
+
+// buildRepeatedVarintMessage returns a message with n unpacked elements in field 1 followed by field 2 = 1.
+func buildRepeatedVarintMessage(n int) []byte {
+	var message []byte
+	for i := 0; i < n; i++ {
+		message = protowire.AppendTag(message, 1, protowire.VarintType)
+		message = protowire.AppendVarint(message, uint64(i))
+	}
+	message = protowire.AppendTag(message, 2, protowire.VarintType)
+	return protowire.AppendVarint(message, 1)
+}
+
+// buildPackedVarintMessage returns a message with n packed elements in field 1.
+func buildPackedVarintMessage(n int) []byte {
+	var packed []byte
+	for i := 0; i < n; i++ {
+		packed = protowire.AppendVarint(packed, uint64(i))
+	}
+	message := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(message, packed)
+}
+
+// buildRepeatedBytesMessage returns a message with n 100-byte elements in field 1 followed by field 2 = 1.
+func buildRepeatedBytesMessage(n int) []byte {
+	element := make([]byte, 100)
+	var message []byte
+	for i := 0; i < n; i++ {
+		message = protowire.AppendTag(message, 1, protowire.BytesType)
+		message = protowire.AppendBytes(message, element)
+	}
+	message = protowire.AppendTag(message, 2, protowire.VarintType)
+	return protowire.AppendVarint(message, 1)
+}
+
+func benchmarkExpression(b *testing.B, expression string, build func(n int) []byte) {
+	for _, n := range benchmarkElementCounts {
+		message := build(n)
+		b.Run(fmt.Sprintf("elements=%d", n), func(b *testing.B) {
+			b.SetBytes(int64(len(message)))
+			var result any
+			for i := 0; i < b.N; i++ {
+				if err := db.QueryRow("SELECT "+expression, message).Scan(&result); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/float64(len(message)), "ns/byte")
+		})
+	}
+}
+
+func BenchmarkWireGetFieldAfterRepeatedVarints(b *testing.B) {
+	benchmarkExpression(b, "pb_message_get_int32_field(?, 2, 0)", buildRepeatedVarintMessage)
+}
+
+func BenchmarkWireGetFieldAfterRepeatedBytes(b *testing.B) {
+	benchmarkExpression(b, "pb_message_get_int32_field(?, 2, 0)", buildRepeatedBytesMessage)
+}
+
+func BenchmarkWireGetRepeatedFieldElement(b *testing.B) {
+	benchmarkExpression(b, "pb_message_get_repeated_int32_field_element(?, 1, 0)", buildRepeatedVarintMessage)
+}
+
+func BenchmarkWireGetPackedRepeatedFieldCount(b *testing.B) {
+	benchmarkExpression(b, "pb_message_get_repeated_int32_field_count(?, 1)", buildPackedVarintMessage)
+}
+
+func BenchmarkWireMessageToWireJson(b *testing.B) {
+	benchmarkExpression(b, "JSON_LENGTH(pb_message_to_wire_json(?))", buildRepeatedBytesMessage)
+}
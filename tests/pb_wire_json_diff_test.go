@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/protorandom"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestWireJsonDiff(t *testing.T) {
+	oldMessage := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1)
+	oldMessage = protowire.AppendString(protowire.AppendTag(oldMessage, 2, protowire.BytesType), "a")
+
+	newMessage := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 2)
+	newMessage = protowire.AppendString(protowire.AppendTag(newMessage, 2, protowire.BytesType), "a")
+	newMessage = protowire.AppendString(protowire.AppendTag(newMessage, 2, protowire.BytesType), "b")
+	newMessage = protowire.AppendVarint(protowire.AppendTag(newMessage, 3, protowire.VarintType), 7)
+
+	t.Run("diff", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_wire_json_diff(pb_message_to_wire_json(?), pb_message_to_wire_json(?))", oldMessage, oldMessage).IsEqualToJsonString(`[]`)
+		RunTestThatExpression(t, "pb_wire_json_diff(pb_message_to_wire_json(?), pb_message_to_wire_json(?))", oldMessage, newMessage).IsEqualToJsonString(`[
+			{"path": "1[0]", "op": "replace", "old": {"t": 0, "v": 1}, "new": {"t": 0, "v": 2}},
+			{"path": "2[1]", "op": "add", "old": null, "new": {"t": 2, "v": "Yg=="}},
+			{"path": "3[0]", "op": "add", "old": null, "new": {"t": 0, "v": 7}}
+		]`)
+		RunTestThatExpression(t, "pb_wire_json_diff(pb_message_to_wire_json(?), pb_message_to_wire_json(?))", newMessage, oldMessage).IsEqualToJsonString(`[
+			{"path": "1[0]", "op": "replace", "old": {"t": 0, "v": 2}, "new": {"t": 0, "v": 1}},
+			{"path": "2[1]", "op": "remove", "old": {"t": 2, "v": "Yg=="}, "new": null},
+			{"path": "3[0]", "op": "remove", "old": {"t": 0, "v": 7}, "new": null}
+		]`)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_wire_json_to_message(pb_wire_json_patch(pb_message_to_wire_json(?), pb_wire_json_diff(pb_message_to_wire_json(?), pb_message_to_wire_json(?))))", oldMessage, oldMessage, newMessage).IsEqualToBytes(newMessage)
+		RunTestThatExpression(t, "pb_wire_json_to_message(pb_wire_json_patch(pb_message_to_wire_json(?), pb_wire_json_diff(pb_message_to_wire_json(?), pb_message_to_wire_json(?))))", newMessage, newMessage, oldMessage).IsEqualToBytes(oldMessage)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, `pb_wire_json_patch(pb_wire_json_new(), '[{"path": "a.b", "op": "remove"}]')`).ToFailWithSignalException("45000", "pb_wire_json_patch: invalid path `a.b`")
+		RunTestThatExpression(t, `pb_wire_json_patch(pb_wire_json_new(), '[{"path": "1[0]", "op": "remove"}]')`).ToFailWithSignalException("45000", "pb_wire_json_patch: cannot remove `1[0]`, field 1 has 0 occurrences")
+		RunTestThatExpression(t, `pb_wire_json_patch(pb_wire_json_new(), '[{"path": "1[1]", "op": "add", "new": {"t": 0, "v": 1}}]')`).ToFailWithSignalException("45000", "pb_wire_json_patch: cannot add `1[1]`, field 1 has 0 occurrences")
+		RunTestThatExpression(t, `pb_wire_json_patch(pb_wire_json_new(), '[{"path": "1[0]", "op": "move"}]')`).ToFailWithSignalException("45000", "pb_wire_json_patch: unsupported op `move`")
+	})
+
+	t.Run("null", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_wire_json_diff(NULL, pb_wire_json_new())").IsNull()
+		RunTestThatExpression(t, "pb_wire_json_patch(pb_wire_json_new(), NULL)").IsNull()
+	})
+}
+
+func TestRandomizedWireJsonDiff(t *testing.T) {
+	GivenFieldDefinitions(t, "repeated int32 a = 1; string b = 2; repeated MessageType c = 3; fixed64 d = 4; repeated string e = 5 ", func(messageType protoreflect.MessageType) {
+		seed := time.Now().UnixNano()
+		t.Logf("Using seed = %d.", seed)
+		rng := rand.New(rand.NewSource(seed))
+		for i := 0; i < iterations; i++ {
+			oldMessage := protorandom.Message(rng, messageType.Descriptor(), nil).Interface()
+			newMessage := protorandom.Message(rng, messageType.Descriptor(), nil).Interface()
+
+			RunTestThatExpression(t, "pb_wire_json_to_message(pb_wire_json_patch(pb_message_to_wire_json(?), pb_wire_json_diff(pb_message_to_wire_json(?), pb_message_to_wire_json(?))))", oldMessage, oldMessage, newMessage).IsEqualToProto(newMessage)
+		}
+	})
+}
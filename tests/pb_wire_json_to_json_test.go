@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/dedent"
+	"github.com/eiiches/mysql-protobuf-functions/internal/descriptorsetjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/protonumberjson"
+	"github.com/eiiches/mysql-protobuf-functions/internal/testutils"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestWireJsonToJson(t *testing.T) {
+	g := NewWithT(t)
+
+	p := testutils.NewProtoTestSupport(t, map[string]string{
+		"main.proto": dedent.Pipe(`
+			|syntax = "proto3";
+			|import "google/protobuf/timestamp.proto";
+			|message Test {
+			|    string name = 1;
+			|    Nested nested = 2;
+			|    repeated int32 values = 3;
+			|    map<string, Nested> nested_map = 4;
+			|    EnumType enum_field = 5;
+			|    google.protobuf.Timestamp timestamp = 6;
+			|    oneof choice {
+			|        int64 choice_int = 7;
+			|        string choice_string = 8;
+			|    }
+			|}
+			|message Nested {
+			|    int32 value = 1;
+			|    Nested child = 2;
+			|}
+			|enum EnumType {
+			|    ENUM_TYPE_UNSPECIFIED = 0;
+			|    ENUM_TYPE_ONE = 1;
+			|}
+		`),
+	})
+
+	typeName := protoreflect.FullName(".Test")
+
+	descriptorSetJson, err := descriptorsetjson.ToJson(p.GetFileDescriptorSet())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	input := `{"name": "a", "nested": {"value": 1, "child": {"value": 2}}, "values": [1, 2, 3], "nestedMap": {"k": {"value": 3}}, "enumField": "ENUM_TYPE_ONE", "timestamp": "2024-01-01T00:00:00Z", "choiceInt": "7"}`
+	dynamicMessage := p.JsonToDynamicMessage(typeName, input)
+	serializedBinary := p.JsonToProtobuf(typeName, input)
+
+	t.Run("json", func(t *testing.T) {
+		expectedJson, err := (&protojson.MarshalOptions{EmitDefaultValues: true}).Marshal(dynamicMessage.Interface())
+		g.Expect(err).NotTo(HaveOccurred())
+		RunTestThatExpression(t, "pb_wire_json_to_json(?, ?, pb_message_to_wire_json(?))", descriptorSetJson, typeName, serializedBinary).IsEqualToJsonString(string(expectedJson))
+		RunTestThatExpression(t, "pb_wire_json_to_json(?, ?, pb_message_to_wire_json_v2(?))", descriptorSetJson, typeName, serializedBinary).IsEqualToJsonString(string(expectedJson))
+	})
+
+	t.Run("number json", func(t *testing.T) {
+		expectedNumberJson, err := protonumberjson.Marshal(dynamicMessage.Interface())
+		g.Expect(err).NotTo(HaveOccurred())
+		RunTestThatExpression(t, "pb_wire_json_to_number_json(?, ?, pb_message_to_wire_json(?))", descriptorSetJson, typeName, serializedBinary).IsEqualToJsonString(string(expectedNumberJson))
+	})
+
+	t.Run("same as pb_message_to_json after edits", func(t *testing.T) {
+		// Setting choice_string replaces choice_int, because the last member of a oneof on the wire wins
+		const edit = "pb_wire_json_set_string_field(pb_wire_json_clear_field(pb_message_to_wire_json(?), 3), 8, 'b')"
+		RunTestThatExpression(t, "pb_wire_json_to_json(?, ?, "+edit+") = pb_message_to_json(?, ?, pb_wire_json_to_message("+edit+"))",
+			descriptorSetJson, typeName, serializedBinary, descriptorSetJson, typeName, serializedBinary).IsTrue()
+		RunTestThatExpression(t, "JSON_EXTRACT(pb_wire_json_to_json(?, ?, "+edit+"), '$.choiceString')", descriptorSetJson, typeName, serializedBinary).IsEqualToJsonString(`"b"`)
+	})
+
+	t.Run("null and empty", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_wire_json_to_json(?, ?, NULL)", descriptorSetJson, typeName).IsNull()
+		RunTestThatExpression(t, "pb_wire_json_to_json(?, ?, pb_message_to_wire_json(_binary X''))", descriptorSetJson, typeName).IsEqualToJsonString(`{"name": "", "values": [], "nestedMap": {}, "enumField": "ENUM_TYPE_UNSPECIFIED"}`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_wire_json_to_json(?, '.NoSuchType', pb_message_to_wire_json(_binary X''))", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "PB_TYPE_NOT_FOUND")
+		RunTestThatExpression(t, "try_pb_wire_json_to_json(?, '.NoSuchType', pb_message_to_wire_json(_binary X''))", descriptorSetJson).ToFailWithMySQLError(45006, "45006", "PB_TYPE_NOT_FOUND")
+	})
+}
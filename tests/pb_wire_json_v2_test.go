@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/eiiches/mysql-protobuf-functions/internal/protorandom"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestWireJsonV2(t *testing.T) {
+	message := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 150)
+	message = protowire.AppendString(protowire.AppendTag(message, 2, protowire.BytesType), "abc")
+	message = protowire.AppendFixed32(protowire.AppendTag(message, 3, protowire.Fixed32Type), 1)
+	message = protowire.AppendVarint(protowire.AppendTag(message, 1, protowire.VarintType), 1)
+
+	t.Run("layout", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_message_to_wire_json_v2(?)", message).IsEqualToJsonString(`[2, {"1": [[0, 150], [3, 1]], "2": [[1, "YWJj", 2]], "3": [[2, 1, 5]]}]`)
+		RunTestThatExpression(t, "pb_wire_json_to_v2(pb_wire_json_new())").IsEqualToJsonString(`[2, {}]`)
+		RunTestThatExpression(t, "JSON_ARRAY(pb_wire_json_get_version(pb_message_to_wire_json(?)), pb_wire_json_get_version(pb_message_to_wire_json_v2(?)))", message, message).IsEqualToJsonString(`[1, 2]`)
+	})
+
+	t.Run("conversion", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_wire_json_to_v1(pb_message_to_wire_json_v2(?)) = pb_message_to_wire_json(?)", message, message).IsTrue()
+		RunTestThatExpression(t, "pb_wire_json_to_v1(pb_message_to_wire_json(?)) = pb_message_to_wire_json(?)", message, message).IsTrue()
+		RunTestThatExpression(t, "pb_wire_json_to_v2(pb_message_to_wire_json_v2(?)) = pb_message_to_wire_json_v2(?)", message, message).IsTrue()
+		RunTestThatExpression(t, "pb_wire_json_to_message(pb_message_to_wire_json_v2(?))", message).IsEqualToBytes(message)
+		RunTestThatExpression(t, "pb_wire_json_to_v1(NULL)").IsNull()
+		RunTestThatExpression(t, "pb_wire_json_to_v2(NULL)").IsNull()
+		RunTestThatExpression(t, "pb_wire_json_to_v1('[3, {}]')").ToFailWithSignalException("45000", "pb_wire_json_to_v1: unsupported wire_json version `3`")
+	})
+
+	t.Run("getters", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_wire_json_get_int32_field(pb_message_to_wire_json_v2(?), 1, 0)", message).IsEqualToInt(1)
+		RunTestThatExpression(t, "pb_wire_json_get_string_field(pb_message_to_wire_json_v2(?), 2, '')", message).IsEqualToString("abc")
+		RunTestThatExpression(t, "pb_wire_json_get_fixed32_field(pb_message_to_wire_json_v2(?), 3, 0)", message).IsEqualToUint(1)
+		RunTestThatExpression(t, "pb_wire_json_has_string_field(pb_message_to_wire_json_v2(?), 4)", message).IsFalse()
+		RunTestThatExpression(t, "pb_wire_json_get_repeated_int32_field_count(pb_message_to_wire_json_v2(?), 1)", message).IsEqualToInt(2)
+		RunTestThatExpression(t, "pb_wire_json_get_repeated_int32_field_element(pb_message_to_wire_json_v2(?), 1, 0)", message).IsEqualToInt(150)
+		RunTestThatExpression(t, "pb_wire_json_get_repeated_int32_field_as_json_array(pb_message_to_wire_json_v2(?), 1)", message).IsEqualToJsonString(`[150, 1]`)
+	})
+
+	t.Run("setters", func(t *testing.T) {
+		RunTestThatExpression(t, "pb_wire_json_set_int32_field(pb_wire_json_to_v2(pb_wire_json_new()), 1, 150)").IsEqualToJsonString(`[2, {"1": [[0, 150]]}]`)
+		RunTestThatExpression(t, "pb_wire_json_set_string_field(pb_wire_json_to_v2(pb_wire_json_new()), 1, 'abc')").IsEqualToJsonString(`[2, {"1": [[0, "YWJj", 2]]}]`)
+		RunTestThatExpression(t, "pb_wire_json_add_repeated_int32_field_element(pb_wire_json_add_repeated_int32_field_element(pb_wire_json_to_v2(pb_wire_json_new()), 1, 1, TRUE), 1, 2, TRUE)").IsEqualToJsonString(`[2, {"1": [[0, "AQI=", 2]]}]`)
+		RunTestThatExpression(t, "pb_wire_json_add_all_repeated_int32_field_elements(pb_wire_json_to_v2(pb_wire_json_new()), 1, '[1, 2]', FALSE)").IsEqualToJsonString(`[2, {"1": [[0, 1], [1, 2]]}]`)
+		RunTestThatExpression(t, "pb_wire_json_clear_int32_field(pb_message_to_wire_json_v2(?), 1)", message).IsEqualToJsonString(`[2, {"2": [[1, "YWJj", 2]], "3": [[2, 1, 5]]}]`)
+	})
+
+	// Every edit must produce the same message as with version 1, and keep the version 2 layout
+	for _, edit := range []string{
+		"pb_wire_json_set_int32_field(%s, 4, -1)",
+		"pb_wire_json_add_repeated_int32_field_element(%s, 1, 7, FALSE)",
+		"pb_wire_json_add_repeated_int32_field_element(%s, 1, 7, TRUE)",
+		"pb_wire_json_add_all_repeated_fixed32_field_elements(%s, 3, '[5, 6]', TRUE)",
+		"pb_wire_json_insert_repeated_int32_field_element(%s, 1, 1, 7, FALSE)",
+		"pb_wire_json_set_repeated_int32_field_element(%s, 1, 1, 7)",
+		"pb_wire_json_remove_repeated_int32_field_element(%s, 1, 0)",
+		"pb_wire_json_set_repeated_int32_field(%s, 1, '[3, 2, 1]', TRUE)",
+		"pb_wire_json_sort_repeated_int32_field(%s, 1)",
+		"pb_wire_json_clear_repeated_string_field(%s, 2)",
+	} {
+		v1 := fmt.Sprintf(edit, "pb_message_to_wire_json(?)")
+		v2 := fmt.Sprintf(edit, "pb_message_to_wire_json_v2(?)")
+		t.Run(v2, func(t *testing.T) {
+			RunTestThatExpression(t, "pb_wire_json_get_version("+v2+")", message).IsEqualToInt(2)
+			RunTestThatExpression(t, "pb_wire_json_to_v1("+v2+") = "+v1, message, message).IsTrue()
+		})
+	}
+
+	t.Run("diff and patch", func(t *testing.T) {
+		other := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 2)
+		RunTestThatExpression(t, "pb_wire_json_diff(pb_message_to_wire_json(?), pb_message_to_wire_json_v2(?))", message, message).IsEqualToJsonString(`[]`)
+		RunTestThatExpression(t, "pb_wire_json_patch(pb_message_to_wire_json_v2(?), pb_wire_json_diff(pb_message_to_wire_json_v2(?), pb_message_to_wire_json(?)))", other, other, message).IsEqualToJsonString(`[2, {"1": [[0, 150], [1, 1]], "2": [[2, "YWJj", 2]], "3": [[3, 1, 5]]}]`)
+	})
+}
+
+func TestRandomizedWireJsonV2(t *testing.T) {
+	GivenFieldDefinitions(t, "repeated int32 a = 1; string b = 2; repeated MessageType c = 3; fixed64 d = 4; repeated float e = 5; repeated sint64 f = 6 [packed = false]", func(messageType protoreflect.MessageType) {
+		seed := time.Now().UnixNano()
+		t.Logf("Using seed = %d.", seed)
+		rng := rand.New(rand.NewSource(seed))
+		for i := 0; i < iterations; i++ {
+			message := protorandom.Message(rng, messageType.Descriptor(), nil).Interface()
+
+			RunTestThatExpression(t, "pb_wire_json_to_message(pb_message_to_wire_json_v2(?))", message).IsEqualToProto(message)
+			RunTestThatExpression(t, "pb_wire_json_to_v1(pb_message_to_wire_json_v2(?)) = pb_message_to_wire_json(?)", message, message).IsTrue()
+		}
+	})
+}
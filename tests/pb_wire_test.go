@@ -18,5 +18,11 @@ func TestWireGetWireTypeFromTag(t *testing.T) {
 
 func TestWireTypeName(t *testing.T) {
 	RunTestThatExpression(t, "_pb_wire_type_name(0)").IsEqualToString("VARINT")
-	RunTestThatExpression(t, "_pb_wire_type_name(10)").ToFailWithSignalException("45000", "_pb_wire_type_name: unsupported wire_type")
+	RunTestThatExpression(t, "_pb_wire_type_name(10)").ToFailWithMySQLError(45004, "45004", "_pb_wire_type_name: unsupported wire_type (10) (PB_UNSUPPORTED_WIRE_TYPE)")
+}
+
+func TestWireSkipLenFieldBeforeFixedWidthField(t *testing.T) {
+	// 1: "ab", 2: fixed32 1, 3: fixed64 2
+	RunTestThatExpression(t, "pb_message_get_fixed32_field(_binary X'0A0261621501000000190200000000000000', 2, 0)").IsEqualToUint(1)
+	RunTestThatExpression(t, "pb_message_get_fixed64_field(_binary X'0A0261621501000000190200000000000000', 3, 0)").IsEqualToUint(2)
 }